@@ -0,0 +1,198 @@
+// Package a2atest provides a mock A2A server for testing applications built on the client
+// package without a model: each message runs a scripted outcome, such as completing after a
+// number of streamed events, failing with an error code or asking for input.
+//
+//	srv := a2atest.NewServer(a2atest.WithOutcomes(a2atest.RequireInput("Which city?"), a2atest.Complete(3)))
+//	defer srv.Close()
+//	c := srv.Client()
+package a2atest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"a2a/client"
+	"a2a/models"
+	"a2a/server"
+)
+
+// Outcome scripts how the mock server runs the task of one message
+type Outcome struct {
+	// Events is how many artifact chunks are streamed before the task completes; the chunks
+	// join into the task's artifact
+	Events int
+	// Reply is the text of the task's artifact; empty echoes the text of the message
+	Reply string
+	// Delay is the wait before each event
+	Delay time.Duration
+	// ErrorCode fails the request with a JSON-RPC error of this code, with ErrorMessage,
+	// instead of completing the task
+	ErrorCode    models.ErrorCode
+	ErrorMessage string
+	// Prompt pauses the task awaiting input with this question instead of completing it
+	Prompt string
+}
+
+// Complete returns the outcome completing the task after streaming events artifact chunks
+func Complete(events int) Outcome {
+	return Outcome{Events: events}
+}
+
+// Fail returns the outcome failing the request with a JSON-RPC error of code
+func Fail(code models.ErrorCode, message string) Outcome {
+	return Outcome{ErrorCode: code, ErrorMessage: message}
+}
+
+// RequireInput returns the outcome pausing the task in the input-required state with prompt
+func RequireInput(prompt string) Outcome {
+	return Outcome{Prompt: prompt}
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithOutcomes runs the outcomes for the messages received, in order; the last one is repeated
+// once they are used up. Without outcomes, every task completes after one event.
+func WithOutcomes(outcomes ...Outcome) Option {
+	return func(s *Server) {
+		s.outcomes = append(s.outcomes, outcomes...)
+	}
+}
+
+// WithScript picks the outcome of each message with script, instead of WithOutcomes
+func WithScript(script func(message *models.Message) Outcome) Option {
+	return func(s *Server) {
+		s.script = script
+	}
+}
+
+// WithAgentCard serves card, with its URL set to the server's endpoint, instead of a card
+// declaring streaming
+func WithAgentCard(card models.AgentCard) Option {
+	return func(s *Server) {
+		s.card = card
+	}
+}
+
+// WithServerOptions configures the A2A server with opts, e.g. server.WithPushNotifications
+func WithServerOptions(opts ...server.Option) Option {
+	return func(s *Server) {
+		s.serverOpts = append(s.serverOpts, opts...)
+	}
+}
+
+// Server is a mock A2A server listening on a local address, serving the routes of
+// server.A2AServer.RegisterRoutes
+type Server struct {
+	*httptest.Server
+	// Endpoint is the URL of the JSON-RPC endpoint, the agent card's URL
+	Endpoint string
+	// Agent is the A2A server running the scripted tasks
+	Agent *server.A2AServer
+
+	card       models.AgentCard
+	serverOpts []server.Option
+	script     func(message *models.Message) Outcome
+
+	mu       sync.Mutex
+	outcomes []Outcome
+	messages []models.Message
+}
+
+// NewServer starts a mock A2A server; callers should call Close when finished
+func NewServer(opts ...Option) *Server {
+	streaming := true
+	s := &Server{card: models.AgentCard{
+		Name:         "Mock Agent",
+		Version:      "1.0.0",
+		Capabilities: models.AgentCapabilities{Streaming: &streaming},
+	}}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	s.Server = httptest.NewServer(mux)
+	s.Endpoint = s.URL + "/a2a"
+	s.card.URL = s.Endpoint
+	s.Agent = server.NewA2AServer(s.card, server.Streaming(s.run), s.serverOpts...)
+	s.Agent.RegisterRoutes(mux)
+	return s
+}
+
+// Client returns a client of the server's endpoint configured with opts
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	return client.NewClient(s.Endpoint, opts...)
+}
+
+// Messages returns the messages received, in order
+func (s *Server) Messages() []models.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.Message(nil), s.messages...)
+}
+
+// next records message and returns its outcome
+func (s *Server) next(message *models.Message) Outcome {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, *message)
+	switch {
+	case s.script != nil:
+		return s.script(message)
+	case len(s.outcomes) == 0:
+		return Complete(1)
+	case len(s.outcomes) == 1:
+		return s.outcomes[0]
+	}
+	outcome := s.outcomes[0]
+	s.outcomes = s.outcomes[1:]
+	return outcome
+}
+
+// run runs the task of message as scripted by its outcome
+func (s *Server) run(ctx context.Context, task *models.Task, message *models.Message, sink server.EventSink) (*models.Task, error) {
+	outcome := s.next(message)
+	if outcome.ErrorCode != 0 {
+		return task, models.NewA2AError(outcome.ErrorCode, outcome.ErrorMessage)
+	}
+	if outcome.Prompt != "" {
+		return server.InputRequired(task, outcome.Prompt), nil
+	}
+
+	reply := outcome.Reply
+	if reply == "" {
+		reply = message.Text()
+	}
+	for i, chunk := range split(reply, outcome.Events) {
+		select {
+		case <-time.After(outcome.Delay):
+		case <-ctx.Done():
+			return task, context.Cause(ctx)
+		}
+		index, appendChunk, lastChunk := 0, i > 0, i == outcome.Events-1
+		sink.Artifact(models.Artifact{
+			Parts:     []models.Part{models.TextPart{Type: "text", Text: chunk}},
+			Index:     &index,
+			Append:    &appendChunk,
+			LastChunk: &lastChunk,
+		})
+	}
+
+	task.Artifacts = []models.Artifact{{Parts: []models.Part{models.NewTextPart(reply)}}}
+	task.Status = models.TaskStatus{State: models.TaskStateCompleted}
+	return task, nil
+}
+
+// split splits text into n chunks of about the same number of characters
+func split(text string, n int) []string {
+	runes := []rune(text)
+	chunks := make([]string, n)
+	for i := range chunks {
+		chunks[i] = string(runes[i*len(runes)/n : (i+1)*len(runes)/n])
+	}
+	return chunks
+}
@@ -0,0 +1,82 @@
+package a2atest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// send returns the params of a user message with text for the task taskID
+func send(taskID, text string) models.MessageSendParams {
+	return models.MessageSendParams{ID: taskID, Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}}
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer(WithOutcomes(
+		RequireInput("Which language?"),
+		Outcome{Events: 3, Reply: "Hello world!"},
+		Fail(models.ErrorCodeUnsupportedOperation, "not today"),
+	))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	task, err := c.SendMessageUntilDone(ctx, send("task-1", "Bonjour"), func(ctx context.Context, task *models.Task) (*models.Message, error) {
+		prompt := task.Status.Message.Parts[0].(models.TextPart).Text
+		if prompt != "Which language?" {
+			t.Errorf("Expected the scripted prompt, got %q", prompt)
+		}
+		return &models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "English"}}}, nil
+	})
+	if err != nil || task.Status.State != models.TaskStateCompleted || task.Artifacts[0].Parts[0].(models.TextPart).Text != "Hello world!" {
+		t.Fatalf("Expected the task to complete after input, got %+v (%v)", task, err)
+	}
+
+	if _, err := c.SendMessageTyped(ctx, send("task-2", "Hola")); models.ErrorCodeOf(err) != models.ErrorCodeUnsupportedOperation {
+		t.Errorf("Expected the scripted error code, got %v", err)
+	}
+	// The last outcome is repeated
+	if _, err := c.SendMessageTyped(ctx, send("task-3", "Hola")); models.ErrorCodeOf(err) != models.ErrorCodeUnsupportedOperation {
+		t.Errorf("Expected the last outcome to repeat, got %v", err)
+	}
+
+	var texts []string
+	for _, message := range srv.Messages() {
+		texts = append(texts, message.Text())
+	}
+	if strings.Join(texts, ",") != "Bonjour,English,Hola,Hola" {
+		t.Errorf("Expected the messages received in order, got %q", texts)
+	}
+}
+
+func TestServer_Streaming(t *testing.T) {
+	srv := NewServer(WithScript(func(message *models.Message) Outcome {
+		return Complete(len(message.Text()))
+	}))
+	defer srv.Close()
+
+	events := make(chan interface{}, 16)
+	if err := srv.Client().SendMessageStreamingContext(context.Background(), send("stream", "abc"), events); err != nil {
+		t.Fatalf("Failed to stream: %v", err)
+	}
+	close(events)
+
+	var chunks []string
+	var final interface{}
+	for event := range events {
+		fields := event.(map[string]interface{})
+		if artifact, ok := fields["artifact"].(map[string]interface{}); ok {
+			part := artifact["parts"].([]interface{})[0].(map[string]interface{})
+			chunks = append(chunks, part["text"].(string))
+		}
+		final = fields["status"]
+	}
+	if strings.Join(chunks, "|") != "a|b|c" {
+		t.Errorf("Expected one chunk per character echoed, got %q", chunks)
+	}
+	if status, _ := final.(map[string]interface{}); status["state"] != string(models.TaskStateCompleted) {
+		t.Errorf("Expected the stream to end completed, got %v", final)
+	}
+}
@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commonWords are frequent short words of the Latin-script languages, which tell them apart
+// in all but the shortest texts
+var commonWords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "in", "it", "you", "that", "this", "with", "for", "was", "hello", "what"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "un", "du", "je", "vous", "nous", "pas", "que", "bonjour", "avec"},
+	"es": {"el", "la", "los", "las", "y", "es", "una", "un", "del", "que", "por", "con", "para", "hola", "está", "muy"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "ich", "sie", "mit", "auf", "zu", "hallo", "wir", "für"},
+}
+
+// Detect guesses the base language subtag of text from its script and, for Latin script, its
+// common words. It returns "" when it cannot tell.
+func Detect(text string) string {
+	var han, kana, hangul, latin int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case kana > 0:
+		// Japanese mixes kana with kanji
+		return "ja"
+	case hangul > 0 && hangul >= han:
+		return "ko"
+	case han > 0 && han >= latin:
+		return "zh"
+	case latin == 0:
+		return ""
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	best, bestScore, tied := "", 0, false
+	for _, code := range []string{"en", "fr", "es", "de"} {
+		score := 0
+		for _, word := range words {
+			for _, common := range commonWords[code] {
+				if word == common {
+					score++
+					break
+				}
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = code, score, false
+		case score == bestScore && score > 0:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,49 @@
+package translator
+
+import (
+	"fmt"
+
+	"a2a/models"
+)
+
+// statusMessages holds localized status texts keyed by base language subtag
+var statusMessages = map[string]map[string]string{
+	"en": {
+		"completed":       "Translation to %s completed.",
+		"failed":          "Translation failed.",
+		"noText":          "No text found in the message.",
+		"unknownLanguage": "The requested language is not supported.",
+	},
+	"zh": {
+		"completed":       "已完成翻译为%s。",
+		"failed":          "翻译失败。",
+		"noText":          "消息中没有找到文本。",
+		"unknownLanguage": "不支持所请求的语言。",
+	},
+	"fr": {
+		"completed":       "Traduction vers %s terminée.",
+		"failed":          "La traduction a échoué.",
+		"noText":          "Aucun texte trouvé dans le message.",
+		"unknownLanguage": "La langue demandée n'est pas prise en charge.",
+	},
+	"es": {
+		"completed":       "Traducción a %s completada.",
+		"failed":          "La traducción falló.",
+		"noText":          "No se encontró texto en el mensaje.",
+		"unknownLanguage": "El idioma solicitado no es compatible.",
+	},
+}
+
+// localizedStatus builds an agent status message in the caller's language, defaulting to English
+func localizedStatus(lang, key string, args ...interface{}) *models.Message {
+	catalog, ok := statusMessages[lang]
+	if !ok {
+		catalog = statusMessages["en"]
+	}
+	return &models.Message{
+		Role: "agent",
+		Parts: []models.Part{
+			models.NewTextPart(fmt.Sprintf(catalog[key], args...)),
+		},
+	}
+}
@@ -0,0 +1,497 @@
+// Package translator is a translation skill backed by a language model.
+//
+// A Translator detects the language of a message, from its script and common words or else by
+// asking a model, and translates it into the target language named by a data part or the
+// message metadata, the skill the message is addressed to, or the caller's locale. The
+// translation is returned as a text artifact. Skills declares a general translation skill and
+// one skill per language pair, all served by ServeTask. With a memory (see WithMemory), later
+// messages of a conversation may instead ask to revise the previous translation, such as "make
+// it more formal".
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"a2a/guardrail"
+	"a2a/memory"
+	"a2a/models"
+	"a2a/prompt"
+	"a2a/server"
+)
+
+// Keys of the data part fields and message metadata selecting languages
+const (
+	// TargetLanguageKey names the language to translate into, by code or name
+	TargetLanguageKey = "targetLanguage"
+	// SourceLanguageKey names the language of the text, skipping detection
+	SourceLanguageKey = "sourceLanguage"
+)
+
+// SkillID is the ID of the general translation skill; pair skills are SkillID-<source>-<target>
+const SkillID = "translate"
+
+// ArtifactName is the name of the artifact holding the translation
+const ArtifactName = "translation"
+
+// Names of the translator's prompt templates
+const (
+	// TranslatePrompt asks for the translation of {{.text}} from {{.source}}, the name of the
+	// source language, or else the empty string, to {{.target}}. {{.sourceCode}} and
+	// {{.targetCode}} are the languages' codes.
+	TranslatePrompt = "translate"
+	// DetectPrompt asks for the code of the language of {{.text}}, one of {{.languages}}
+	DetectPrompt = "detect"
+	// FollowUpPrompt asks to handle {{.text}}, a message following {{.history}}, the transcript
+	// of a conversation ending with a translation to {{.target}}: as a request to revise that
+	// translation, or else as text to translate
+	FollowUpPrompt = "followUp"
+)
+
+// DefaultPrompts are the prompt templates of a translator created without WithPrompts, which
+// prompt libraries override by name (see prompt.Library)
+var DefaultPrompts = map[string]string{
+	TranslatePrompt: "Please translate the following {{with .source}}{{.}} {{end}}text to {{.target}}: {{.text}}",
+	DetectPrompt:    "Identify the language of the following text. Reply with only its ISO 639-1 code, one of {{.languages}}: {{.text}}",
+	FollowUpPrompt: "This is a translation conversation so far:\n{{.history}}\nThe last agent message is a translation to {{.target}}. " +
+		"If the following message asks to change it, reply with only the revised translation; otherwise reply with only " +
+		"the translation of the message to {{.target}}: {{.text}}",
+}
+
+// Language is a language the translator detects and translates
+type Language struct {
+	// Code is the base language subtag, e.g. "fr"
+	Code string
+	// Name is the English name used in prompts, e.g. "French"
+	Name string
+}
+
+// DefaultLanguages are the languages of a translator created without WithLanguages
+var DefaultLanguages = []Language{
+	{Code: "en", Name: "English"},
+	{Code: "zh", Name: "Chinese"},
+	{Code: "fr", Name: "French"},
+	{Code: "es", Name: "Spanish"},
+	{Code: "ja", Name: "Japanese"},
+	{Code: "ko", Name: "Korean"},
+	{Code: "de", Name: "German"},
+}
+
+// Pair is a source and target language, by code, served as its own skill
+type Pair struct {
+	Source string
+	Target string
+}
+
+// Translator translates messages with a language model
+type Translator struct {
+	model     guardrail.GenerateFunc
+	languages []Language
+	pairs     []Pair
+	// defaultTarget is the target when neither the message, skill nor locale names one
+	defaultTarget string
+	// detector is asked for the source language when the heuristic fails; nil leaves it to
+	// the translation prompt
+	detector    guardrail.GenerateFunc
+	description string
+	prompts     *prompt.Library
+	// memory remembers the translations of each conversation; nil translates every message on
+	// its own
+	memory *memory.Memory
+}
+
+// Option configures a Translator
+type Option func(*Translator)
+
+// WithLanguages replaces the languages detected and translated
+func WithLanguages(languages ...Language) Option {
+	return func(t *Translator) {
+		t.languages = languages
+	}
+}
+
+// WithPairs declares a skill per language pair on the agent card, so that agents looking for
+// e.g. Chinese to English translation find it by skill
+func WithPairs(pairs ...Pair) Option {
+	return func(t *Translator) {
+		t.pairs = append(t.pairs, pairs...)
+	}
+}
+
+// WithDefaultTarget sets the target language of messages naming none whose caller's locale is
+// not a known language; the default is English
+func WithDefaultTarget(code string) Option {
+	return func(t *Translator) {
+		t.defaultTarget = code
+	}
+}
+
+// WithModelDetection asks detector, a model completing whole prompts, for the language of
+// text the heuristic cannot place, such as short Latin-script text without common words. It
+// is separate from the translation model, which may stream its completion to the caller.
+func WithModelDetection(detector guardrail.GenerateFunc) Option {
+	return func(t *Translator) {
+		t.detector = detector
+	}
+}
+
+// WithDescription sets the description of the general translation skill
+func WithDescription(description string) Option {
+	return func(t *Translator) {
+		t.description = description
+	}
+}
+
+// WithPrompts renders the translator's prompts from library, which should have DefaultPrompts as
+// its defaults. The templates of the skill a message is addressed to take precedence, and
+// see the message's metadata and data part fields besides the translator's variables.
+func WithPrompts(library *prompt.Library) Option {
+	return func(t *Translator) {
+		t.prompts = library
+	}
+}
+
+// WithMemory remembers the messages and translations of each conversation, by context ID, in
+// mem. A message following a translation, naming no source language, is handled with
+// FollowUpPrompt: it revises the previous translation when it asks to, and is otherwise
+// translated to the same language.
+func WithMemory(mem *memory.Memory) Option {
+	return func(t *Translator) {
+		t.memory = mem
+	}
+}
+
+// New returns a translator completing its prompts with model, which may be wrapped with
+// guardrail.Wrap; guardrail violations reject the task
+func New(model guardrail.GenerateFunc, opts ...Option) *Translator {
+	t := &Translator{
+		model:         model,
+		languages:     DefaultLanguages,
+		defaultTarget: "en",
+		description:   "Translate text between languages, detecting the source language",
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.prompts == nil {
+		t.prompts = defaultPrompts
+	}
+	return t
+}
+
+// defaultPrompts renders the prompts of translators created without WithPrompts
+var defaultPrompts = func() *prompt.Library {
+	library, err := prompt.New(DefaultPrompts)
+	if err != nil {
+		panic(err)
+	}
+	return library
+}()
+
+// language returns the known language with code or name, ignoring case
+func (t *Translator) language(codeOrName string) (Language, bool) {
+	codeOrName = strings.TrimSpace(codeOrName)
+	base, _, _ := strings.Cut(codeOrName, "-")
+	for _, language := range t.languages {
+		if strings.EqualFold(language.Code, base) || strings.EqualFold(language.Name, codeOrName) {
+			return language, true
+		}
+	}
+	return Language{}, false
+}
+
+// Skills returns the general translation skill followed by a skill per pair declared with
+// WithPairs, to add to the agent card with ServeTask as their handler
+func (t *Translator) Skills() []models.AgentSkill {
+	description := t.description
+	skills := []models.AgentSkill{{
+		ID:          SkillID,
+		Name:        "Text Translation",
+		Description: &description,
+		Tags:        []string{"translation", "nlp", "llm"},
+		InputModes:  []string{"text", "data"},
+		OutputModes: []string{"text"},
+	}}
+	for _, pair := range t.pairs {
+		source, _ := t.language(pair.Source)
+		target, _ := t.language(pair.Target)
+		description := fmt.Sprintf("Translate %s text to %s", source.Name, target.Name)
+		skills = append(skills, models.AgentSkill{
+			ID:          PairSkillID(pair),
+			Name:        fmt.Sprintf("%s to %s Translation", source.Name, target.Name),
+			Description: &description,
+			Tags:        []string{"translation", source.Code, target.Code},
+			InputModes:  []string{"text"},
+			OutputModes: []string{"text"},
+		})
+	}
+	return skills
+}
+
+// Validate checks that every pair declared with WithPairs names known languages
+func (t *Translator) Validate() error {
+	var errs []error
+	for _, pair := range t.pairs {
+		for _, code := range []string{pair.Source, pair.Target} {
+			if _, ok := t.language(code); !ok {
+				errs = append(errs, fmt.Errorf("pair %s names unknown language %q", PairSkillID(pair), code))
+			}
+		}
+		if pair.Source == pair.Target {
+			errs = append(errs, fmt.Errorf("pair %s translates a language into itself", PairSkillID(pair)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PairSkillID returns the ID of the skill translating pair
+func PairSkillID(pair Pair) string {
+	return SkillID + "-" + pair.Source + "-" + pair.Target
+}
+
+// pairOfSkill returns the pair translated by the skill with ID id
+func pairOfSkill(id string) (Pair, bool) {
+	rest, ok := strings.CutPrefix(id, SkillID+"-")
+	if !ok {
+		return Pair{}, false
+	}
+	source, target, ok := strings.Cut(rest, "-")
+	return Pair{Source: source, Target: target}, ok && source != "" && target != ""
+}
+
+// ServeTask is the translator's TaskHandler. It translates the text parts of message and
+// returns the translation as an artifact whose metadata names the source and target
+// languages, with a status message in the caller's language.
+func (t *Translator) ServeTask(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	lang := server.PrimaryLanguage(ctx, "en")
+	if _, ok := statusMessages[lang]; !ok {
+		lang = "en"
+	}
+
+	text := message.Text()
+	if strings.TrimSpace(text) == "" {
+		task.Status.State = models.TaskStateFailed
+		task.Status.Message = localizedStatus(lang, "noText")
+		return task, errors.New("no text found in message")
+	}
+
+	pair, _ := pairOfSkill(server.SkillFromContext(ctx))
+	previous := t.recall(ctx, task.ContextID, message)
+	target, err := t.target(ctx, message, pair, previous)
+	if err == nil {
+		var source Language
+		if previous == nil {
+			source, err = t.source(ctx, message, pair, text)
+		}
+		if err == nil {
+			result, err := t.translate(ctx, task, message, lang, text, source, target, previous)
+			if err == nil && result.Status.State == models.TaskStateCompleted {
+				t.remember(ctx, result, text, target)
+			}
+			return result, err
+		}
+	}
+	task.Status.State = models.TaskStateFailed
+	task.Status.Message = localizedStatus(lang, "unknownLanguage")
+	return task, err
+}
+
+// translate translates text from source, which is unknown when its code is empty, to target,
+// or handles it as a follow-up of the previous translation when there is one
+func (t *Translator) translate(ctx context.Context, task *models.Task, message *models.Message, lang, text string, source, target Language, previous *previousTranslation) (*models.Task, error) {
+	if source.Code == target.Code && previous == nil {
+		return t.complete(task, lang, text, source, target), nil
+	}
+
+	vars := prompt.MessageVars(message)
+	vars[prompt.TextVar] = text
+	vars["source"], vars["sourceCode"] = source.Name, source.Code
+	vars["target"], vars["targetCode"] = target.Name, target.Code
+	name := TranslatePrompt
+	if previous != nil {
+		name, vars["history"] = FollowUpPrompt, previous.history
+	}
+	request, err := t.prompts.Render(server.SkillFromContext(ctx), name, vars)
+	if err != nil {
+		task.Status.State = models.TaskStateFailed
+		task.Status.Message = localizedStatus(lang, "failed")
+		return task, err
+	}
+	translated, err := t.model(ctx, request)
+	if violation, ok := guardrail.AsViolation(err); ok {
+		log.Printf("Task %s rejected: %v", task.ID, violation)
+		return guardrail.Reject(task, violation), nil
+	}
+	if err != nil {
+		task.Status.State = models.TaskStateFailed
+		task.Status.Message = localizedStatus(lang, "failed")
+		return task, fmt.Errorf("translation failed: %w", err)
+	}
+	return t.complete(task, lang, strings.TrimSpace(translated), source, target), nil
+}
+
+// complete finishes task with translated as its translation artifact
+func (t *Translator) complete(task *models.Task, lang, translated string, source, target Language) *models.Task {
+	name := ArtifactName
+	metadata := map[string]interface{}{TargetLanguageKey: target.Code}
+	if source.Code != "" {
+		metadata[SourceLanguageKey] = source.Code
+	}
+	task.Status.State = models.TaskStateCompleted
+	task.Status.Message = localizedStatus(lang, "completed", target.Name)
+	task.Artifacts = append(task.Artifacts, models.Artifact{
+		Name:     &name,
+		Parts:    []models.Part{models.NewTextPart(translated)},
+		Metadata: metadata,
+	})
+	return task
+}
+
+// target returns the language to translate message into: the one a data part or the message
+// metadata names, else the pair's, else that of the previous translation, else the caller's
+// locale if known, else the default
+func (t *Translator) target(ctx context.Context, message *models.Message, pair Pair, previous *previousTranslation) (Language, error) {
+	if name, ok := requested(message, TargetLanguageKey); ok {
+		language, known := t.language(name)
+		if !known {
+			return Language{}, fmt.Errorf("unknown target language %q", name)
+		}
+		return language, nil
+	}
+	if language, ok := t.language(pair.Target); ok && pair.Target != "" {
+		return language, nil
+	}
+	if previous != nil {
+		return previous.target, nil
+	}
+	if language, ok := t.language(server.PrimaryLanguage(ctx, "")); ok {
+		return language, nil
+	}
+	if language, ok := t.language(t.defaultTarget); ok {
+		return language, nil
+	}
+	return Language{}, fmt.Errorf("unknown default target language %q", t.defaultTarget)
+}
+
+// source returns the language of text: the one a data part or the message metadata names,
+// else the pair's, else the detected one. A language that cannot be detected is returned
+// with an empty code, leaving it to the model.
+func (t *Translator) source(ctx context.Context, message *models.Message, pair Pair, text string) (Language, error) {
+	if name, ok := requested(message, SourceLanguageKey); ok {
+		language, known := t.language(name)
+		if !known {
+			return Language{}, fmt.Errorf("unknown source language %q", name)
+		}
+		return language, nil
+	}
+	if language, ok := t.language(pair.Source); ok && pair.Source != "" {
+		return language, nil
+	}
+	if language, ok := t.language(Detect(text)); ok {
+		return language, nil
+	}
+	if t.detector != nil {
+		if language, ok := t.detect(ctx, text); ok {
+			return language, nil
+		}
+	}
+	return Language{}, nil
+}
+
+// detect asks the detector for the language of text
+func (t *Translator) detect(ctx context.Context, text string) (Language, bool) {
+	names := make([]string, len(t.languages))
+	for i, language := range t.languages {
+		names[i] = language.Code
+	}
+	request, err := t.prompts.Render(server.SkillFromContext(ctx), DetectPrompt, prompt.Vars{
+		prompt.TextVar: text,
+		"languages":    strings.Join(names, ", "),
+	})
+	if err != nil {
+		return Language{}, false
+	}
+	reply, err := t.detector(ctx, request)
+	if err != nil {
+		return Language{}, false
+	}
+	return t.language(strings.Trim(strings.TrimSpace(reply), `."'`))
+}
+
+// previousTranslation is the conversation a message follows, ending with a translation
+type previousTranslation struct {
+	// history is the transcript of the conversation
+	history string
+	// target is the language of the last translation
+	target Language
+}
+
+// recall returns the conversation contextID when its last message is a translation that
+// message may follow up, or nil without a memory, such a conversation or when message names
+// its source language and so is text to translate
+func (t *Translator) recall(ctx context.Context, contextID string, message *models.Message) *previousTranslation {
+	if t.memory == nil || contextID == "" {
+		return nil
+	}
+	if _, ok := requested(message, SourceLanguageKey); ok {
+		return nil
+	}
+	conversation, err := t.memory.Recall(ctx, contextID)
+	if err != nil {
+		log.Printf("Failed to recall conversation %s: %v", contextID, err)
+		return nil
+	}
+	if len(conversation.Messages) == 0 {
+		return nil
+	}
+	last := conversation.Messages[len(conversation.Messages)-1]
+	code, _ := last.Metadata[TargetLanguageKey].(string)
+	target, ok := t.language(code)
+	if last.Role != "agent" || !ok {
+		return nil
+	}
+	return &previousTranslation{history: conversation.Transcript(), target: target}
+}
+
+// remember appends text and its translation to target, the last artifact of task, to the
+// conversation of task
+func (t *Translator) remember(ctx context.Context, task *models.Task, text string, target Language) {
+	if t.memory == nil || task.ContextID == "" || len(task.Artifacts) == 0 {
+		return
+	}
+	translation := task.Artifacts[len(task.Artifacts)-1]
+	err := t.memory.Append(ctx, task.ContextID,
+		models.Message{Role: "user", Parts: []models.Part{models.NewTextPart(text)}},
+		models.Message{
+			Role:     "agent",
+			Parts:    []models.Part{models.NewTextPart(models.Message{Parts: translation.Parts}.Text())},
+			Metadata: map[string]interface{}{TargetLanguageKey: target.Code},
+		})
+	if err != nil {
+		log.Printf("Failed to remember conversation %s: %v", task.ContextID, err)
+	}
+}
+
+// requested returns the string a data part of message or its metadata sets under key
+func requested(message *models.Message, key string) (string, bool) {
+	for _, part := range message.Parts {
+		dataPart, ok := part.(models.DataPart)
+		if !ok {
+			continue
+		}
+		data, ok := dataPart.Data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := data[key].(string); ok && value != "" {
+			return value, true
+		}
+	}
+	if value, ok := message.Metadata[key].(string); ok && value != "" {
+		return value, true
+	}
+	return "", false
+}
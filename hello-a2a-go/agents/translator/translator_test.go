@@ -0,0 +1,249 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"a2a/guardrail"
+	"a2a/memory"
+	"a2a/models"
+	"a2a/prompt"
+	"a2a/server"
+)
+
+// echoModel answers translation prompts with the prompt's instruction, so tests see which
+// languages were chosen
+func echoModel(ctx context.Context, prompt string) (string, error) {
+	instruction, _, _ := strings.Cut(prompt, ":")
+	return instruction, nil
+}
+
+func newMessage(parts ...models.Part) *models.Message {
+	return &models.Message{Role: "user", Parts: parts}
+}
+
+func text(s string) models.Part {
+	return models.TextPart{Type: "text", Text: s}
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		"今天天气真好，我们去公园散步。":                            "zh",
+		"今日はいい天気ですね":                                 "ja",
+		"안녕하세요, 반갑습니다":                               "ko",
+		"Hello, how are you today?":                  "en",
+		"Bonjour, je suis très content de vous voir": "fr",
+		"Hola, ¿cómo está usted? Muy bien":           "es",
+		"Ich bin nicht müde und wir gehen":           "de",
+		"Xyz":                                        "",
+		"1234":                                       "",
+	}
+	for input, want := range tests {
+		if got := Detect(input); got != want {
+			t.Errorf("Detect(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestServeTask(t *testing.T) {
+	translator := New(echoModel, WithModelDetection(func(ctx context.Context, prompt string) (string, error) {
+		return " de.\n", nil
+	}))
+	tests := []struct {
+		name    string
+		message *models.Message
+		want    string
+		source  string
+		target  string
+	}{
+		{name: "detected", message: newMessage(text("Bonjour le monde")),
+			want: "Please translate the following French text to English", source: "fr", target: "en"},
+		{name: "data part", message: newMessage(text("Hello there, what is this"), models.DataPart{Type: "data", Data: map[string]interface{}{TargetLanguageKey: "Japanese"}}),
+			want: "Please translate the following English text to Japanese", source: "en", target: "ja"},
+		{name: "metadata", message: &models.Message{Role: "user", Parts: []models.Part{text("你好")}, Metadata: map[string]interface{}{TargetLanguageKey: "fr-CA", SourceLanguageKey: "zh"}},
+			want: "Please translate the following Chinese text to French", source: "zh", target: "fr"},
+		{name: "model detection", message: newMessage(text("Guten Morgen")),
+			want: "Please translate the following German text to English", source: "de", target: "en"},
+		{name: "same language", message: newMessage(text("Hello, how are you?")),
+			want: "Hello, how are you?", source: "en", target: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := translator.ServeTask(context.Background(), &models.Task{ID: "t1"}, tt.message)
+			if err != nil || task.Status.State != models.TaskStateCompleted {
+				t.Fatalf("Expected the task to complete, got %+v (%v)", task.Status, err)
+			}
+			artifact := task.Artifacts[0]
+			if *artifact.Name != ArtifactName || artifact.Parts[0].(models.TextPart).Text != tt.want {
+				t.Errorf("Expected the translation %q, got %+v", tt.want, artifact)
+			}
+			if artifact.Metadata[SourceLanguageKey] != tt.source || artifact.Metadata[TargetLanguageKey] != tt.target {
+				t.Errorf("Expected %s to %s, got %v", tt.source, tt.target, artifact.Metadata)
+			}
+		})
+	}
+}
+
+func TestServeTask_Failures(t *testing.T) {
+	translator := New(echoModel)
+	task, err := translator.ServeTask(context.Background(), &models.Task{ID: "t1"}, newMessage(text("Hi"),
+		models.DataPart{Type: "data", Data: map[string]interface{}{TargetLanguageKey: "Klingon"}}))
+	if err == nil || task.Status.State != models.TaskStateFailed || !strings.Contains(err.Error(), `"Klingon"`) {
+		t.Errorf("Expected an unknown target to fail the task, got %+v (%v)", task.Status, err)
+	}
+
+	if task, err := translator.ServeTask(context.Background(), &models.Task{ID: "t2"}, newMessage()); err == nil || task.Status.State != models.TaskStateFailed {
+		t.Errorf("Expected a message without text to fail, got %+v", task.Status)
+	}
+
+	// Undetectable text is left to the model to recognize
+	task, _ = translator.ServeTask(context.Background(), &models.Task{ID: "t3"}, newMessage(text("Xyz")))
+	if got := task.Artifacts[0].Parts[0].(models.TextPart).Text; got != "Please translate the following text to English" {
+		t.Errorf("Expected a prompt without source language, got %q", got)
+	}
+
+	blocked := New(guardrail.Wrap(echoModel, guardrail.NewFilter([]string{"secret"})))
+	task, err = blocked.ServeTask(context.Background(), &models.Task{ID: "t4"}, newMessage(text("le plan secret")))
+	if err != nil || task.Status.State != models.TaskStateRejected {
+		t.Errorf("Expected a guardrail violation to reject the task, got %+v (%v)", task.Status, err)
+	}
+
+	failing := New(func(ctx context.Context, prompt string) (string, error) { return "", errors.New("model down") })
+	if task, err := failing.ServeTask(context.Background(), &models.Task{ID: "t5"}, newMessage(text("Bonjour le monde"))); err == nil || task.Status.State != models.TaskStateFailed {
+		t.Errorf("Expected a model failure to fail the task, got %+v", task.Status)
+	}
+}
+
+func TestWithMemory(t *testing.T) {
+	var prompts []string
+	model := func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return map[int]string{1: "Bonjour", 2: "Je vous salue"}[len(prompts)], nil
+	}
+	mem := memory.New(memory.NewMapStore(0))
+	translator := New(model, WithMemory(mem))
+	ctx := context.Background()
+
+	hello := newMessage(text("Hello there, how are you"), models.DataPart{Type: "data", Data: map[string]interface{}{TargetLanguageKey: "fr"}})
+	if _, err := translator.ServeTask(ctx, &models.Task{ID: "t1", ContextID: "chat"}, hello); err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	// The correction is in English, yet revises the French translation
+	task, err := translator.ServeTask(ctx, &models.Task{ID: "t2", ContextID: "chat"}, newMessage(text("make it more formal")))
+	if err != nil || task.Status.State != models.TaskStateCompleted {
+		t.Fatalf("Expected the follow-up to complete, got %+v (%v)", task.Status, err)
+	}
+	if got := task.Artifacts[0]; got.Parts[0].(models.TextPart).Text != "Je vous salue" || got.Metadata[TargetLanguageKey] != "fr" {
+		t.Errorf("Expected the revised French translation, got %+v", got)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[1], "user: Hello there, how are you\nagent: Bonjour\n") ||
+		!strings.HasSuffix(prompts[1], "to French: make it more formal") {
+		t.Errorf("Expected the follow-up prompt to hold the conversation, got %q", prompts)
+	}
+	if conversation, _ := mem.Recall(ctx, "chat"); len(conversation.Messages) != 4 {
+		t.Errorf("Expected both exchanges remembered, got %+v", conversation)
+	}
+
+	// Other conversations start afresh
+	task, _ = translator.ServeTask(ctx, &models.Task{ID: "t3", ContextID: "other"}, newMessage(text("Hello, how are you?")))
+	if got := task.Artifacts[0].Parts[0].(models.TextPart).Text; got != "Hello, how are you?" || len(prompts) != 2 {
+		t.Errorf("Expected English text to be kept in a new conversation, got %q", got)
+	}
+}
+
+func TestSkills(t *testing.T) {
+	translator := New(echoModel, WithPairs(Pair{Source: "zh", Target: "en"}, Pair{Source: "en", Target: "ja"}))
+	if err := translator.Validate(); err != nil {
+		t.Fatalf("Expected valid pairs, got %v", err)
+	}
+	if err := New(echoModel, WithPairs(Pair{Source: "xx", Target: "en"}, Pair{Source: "en", Target: "en"})).Validate(); err == nil {
+		t.Error("Expected unknown and identical languages to be invalid")
+	}
+
+	agent, err := server.NewAgent().Named("Translator").WithURL("http://localhost:8080/a2a").
+		WithSkill(translator.Skills()[0], translator.ServeTask).
+		WithSkill(translator.Skills()[1], translator.ServeTask).
+		WithSkill(translator.Skills()[2], translator.ServeTask).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build agent: %v", err)
+	}
+	var ids []string
+	for _, skill := range agent.AgentCard().Skills {
+		ids = append(ids, skill.ID)
+	}
+	if strings.Join(ids, ",") != "translate,translate-zh-en,translate-en-ja" {
+		t.Errorf("Expected a skill per pair, got %v", ids)
+	}
+	if name := agent.AgentCard().Skills[1].Name; name != "Chinese to English Translation" {
+		t.Errorf("Unexpected pair skill name %q", name)
+	}
+
+	// A message addressed to a pair skill is translated into the pair's target
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"p1","metadata":{"skillId":"translate-en-ja"},"message":{"role":"user","parts":[{"kind":"text","text":"Xyz"}]}}}`
+	w := httptest.NewRecorder()
+	agent.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	var response struct {
+		Result models.Task `json:"result"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if artifacts := response.Result.Artifacts; len(artifacts) != 1 ||
+		artifacts[0].Parts[0].(models.TextPart).Text != "Please translate the following English text to Japanese" {
+		t.Errorf("Expected an English to Japanese translation, got %+v", response.Result)
+	}
+}
+
+func TestWithPrompts(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "translate-zh-en"), 0o755)
+	os.WriteFile(filepath.Join(dir, "translate.tmpl"), []byte("Translate to {{.targetCode}} in a {{or .tone \"neutral\"}} tone: {{.text}}"), 0o644)
+	os.WriteFile(filepath.Join(dir, "translate-zh-en", "translate.tmpl"), []byte("Render this Chinese poem in English verse: {{.text}}"), 0o644)
+	library, err := prompt.New(DefaultPrompts, prompt.WithDir(dir))
+	if err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+	translator := New(echoModel, WithPrompts(library), WithPairs(Pair{Source: "zh", Target: "en"}))
+	agent, err := server.NewAgent().Named("Translator").WithURL("http://localhost:8080/a2a").
+		WithSkill(translator.Skills()[0], translator.ServeTask).
+		WithSkill(translator.Skills()[1], translator.ServeTask).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build agent: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		want   string
+	}{
+		{"default skill", `"message":{"role":"user","parts":[{"kind":"text","text":"Bonjour le monde"}],"metadata":{"tone":"playful"}}`,
+			"Translate to en in a playful tone"},
+		{"message variables are optional", `"message":{"role":"user","parts":[{"kind":"text","text":"Bonjour le monde"}]}`,
+			"Translate to en in a neutral tone"},
+		{"skill template", `"metadata":{"skillId":"translate-zh-en"},"message":{"role":"user","parts":[{"kind":"text","text":"床前明月光"}]}`,
+			"Render this Chinese poem in English verse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			agent.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"message/send","params":{`+tt.params+`}}`)))
+			var response struct {
+				Result models.Task `json:"result"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if artifacts := response.Result.Artifacts; len(artifacts) != 1 || artifacts[0].Parts[0].(models.TextPart).Text != tt.want {
+				t.Errorf("Expected the prompt %q, got %+v", tt.want, response.Result)
+			}
+		})
+	}
+}
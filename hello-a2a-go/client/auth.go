@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+
+	"a2a/models"
+)
+
+// TokenSource supplies the bearer token sent with each request, e.g. refreshing an OAuth 2.0
+// access token before it expires
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token implements TokenSource
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource always supplying token
+func StaticTokenSource(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithAuth authenticates every request, including event streams, their resumes and file
+// transfers, with a bearer token from ts, asked for a token per request
+func WithAuth(ts TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = ts
+	}
+}
+
+// GetExtendedAgentCard retrieves the authenticated extended agent card with
+// agent/getAuthenticatedExtendedCard, for agents whose card sets
+// supportsAuthenticatedExtendedCard
+func (c *Client) GetExtendedAgentCard(ctx context.Context) (*models.AgentCard, error) {
+	return call[models.AgentCard](ctx, c, newRequest("extended-card-request", "agent/getAuthenticatedExtendedCard", nil), "agent card")
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestWithAuth(t *testing.T) {
+	extended := models.AgentCard{Name: "Secured", Skills: []models.AgentSkill{{ID: "admin", Name: "Admin"}}}
+	agent := server.NewA2AServer(models.AgentCard{Name: "Secured"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}, server.WithBearerAuth(server.StaticToken("s3cret")), server.WithExtendedAgentCard(extended))
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+
+	params := models.MessageSendParams{
+		ID:      "secured",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+	}
+	var statusErr *statusError
+	if _, err := NewClient(ts.URL).SendMessage(params); !errors.As(err, &statusErr) || statusErr.code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %v", err)
+	}
+
+	var tokens atomic.Int32
+	client := NewClient(ts.URL, WithAuth(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		tokens.Add(1)
+		return "s3cret", nil
+	})))
+	if _, err := client.SendMessage(params); err != nil {
+		t.Fatalf("Expected the authenticated request to succeed, got %v", err)
+	}
+
+	// Streams carry the token too
+	events := make(chan interface{}, 10)
+	if err := client.SendMessageStreaming(params, events); err != nil {
+		t.Fatalf("Expected the authenticated stream to succeed, got %v", err)
+	}
+
+	card, err := client.GetExtendedAgentCard(context.Background())
+	if err != nil || len(card.Skills) != 1 || card.Skills[0].ID != "admin" {
+		t.Errorf("Expected the extended card, got %+v, %v", card, err)
+	}
+	if tokens.Load() != 3 {
+		t.Errorf("Expected a token per request, got %d", tokens.Load())
+	}
+
+	failing := NewClient(ts.URL, WithAuth(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return "", errors.New("refresh failed")
+	})))
+	if _, err := failing.SendMessage(params); err == nil {
+		t.Error("Expected a token source failure to fail the request")
+	}
+	if _, err := NewClient(ts.URL, WithAuth(StaticTokenSource("s3cret"))).GetExtendedAgentCard(context.Background()); err != nil {
+		t.Errorf("Expected a static token to authenticate, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"a2a/models"
+)
+
+// errNoResponse is the error of a batched request the agent sent no response to
+var errNoResponse = errors.New("no response to batched request")
+
+// BatchResponse is the response to one request of a batch sent with SendBatch
+type BatchResponse struct {
+	// ID is the ID of the request
+	ID interface{}
+	// Result is the undecoded result of the request, if it succeeded
+	Result json.RawMessage
+	// Err is the JSON-RPC error the request failed with, as a *models.A2AError
+	Err error
+}
+
+// Decode decodes the result of the request into v, or returns the error it failed with
+func (r BatchResponse) Decode(v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if err := models.DecodeJSON(r.Result, v); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+
+// SendBatch sends requests to the agent as one JSON-RPC batch, in a single HTTP round trip, and
+// returns their responses in the order of the requests. Requests without an ID are
+// notifications, which get an empty response. The returned error reports a batch that failed as
+// a whole; each request's own failure is in the Err of its response. Like other requests,
+// batches are retried according to the client's retry policy.
+func (c *Client) SendBatch(ctx context.Context, requests ...models.JSONRPCRequest) ([]BatchResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	ctx, _ = ensureRequestID(ctx)
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	var raw []Response
+	err = c.retry(ctx, func() error {
+		raw, err = c.postBatch(ctx, body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Responses may come in any order, so they are matched to requests by ID
+	byID := make(map[string]Response, len(raw))
+	for _, resp := range raw {
+		byID[batchKey(resp.ID)] = resp
+	}
+	responses := make([]BatchResponse, len(requests))
+	for i, req := range requests {
+		responses[i].ID = req.ID
+		if req.ID == nil {
+			continue
+		}
+		resp, ok := byID[batchKey(req.ID)]
+		switch {
+		case !ok:
+			responses[i].Err = errNoResponse
+		case resp.Error != nil:
+			responses[i].Err = models.ErrorFromJSONRPC(resp.Error)
+		default:
+			responses[i].Result = resp.Result
+		}
+	}
+	return responses, nil
+}
+
+// postBatch makes one attempt at posting a JSON-RPC batch body, returning its responses. A
+// single error response rejecting the whole batch is returned as its error.
+func (c *Client) postBatch(ctx context.Context, body []byte) ([]Response, error) {
+	httpResp, err := c.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	switch httpResp.StatusCode {
+	case http.StatusNoContent:
+		// The batch held only notifications
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, newStatusError(httpResp)
+	}
+
+	var payload json.RawMessage
+	if err := json.NewDecoder(httpResp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !bytes.HasPrefix(payload, []byte("[")) {
+		var single Response
+		if err := json.Unmarshal(payload, &single); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if single.Error == nil {
+			return nil, errors.New("agent answered a batch with a single response")
+		}
+		return nil, models.ErrorFromJSONRPC(single.Error)
+	}
+	var responses []Response
+	if err := json.Unmarshal(payload, &responses); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return responses, nil
+}
+
+// batchKey returns the JSON encoding of a request ID, which is the same for a request and its
+// response
+func batchKey(id interface{}) string {
+	key, _ := json.Marshal(id)
+	return string(key)
+}
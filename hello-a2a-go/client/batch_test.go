@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestSendBatch(t *testing.T) {
+	var posts int
+	mux := http.NewServeMux()
+	agent := server.NewA2AServer(models.AgentCard{Name: "Batch"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}, server.WithMaxBatchRequests(3))
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		agent.ServeHTTP(w, r)
+	}))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	message := models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}}
+	client := NewClient(ts.URL)
+	responses, err := client.SendBatch(context.Background(),
+		newRequest("send", "message/send", models.MessageSendParams{ID: "t1", Message: message}),
+		newRequest("get", "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "unknown"}}),
+		models.JSONRPCRequest{Method: "message/send", Params: models.MessageSendParams{ID: "t2", Message: message}},
+	)
+	if err != nil || len(responses) != 3 || posts != 1 {
+		t.Fatalf("Expected 3 responses from one post, got %+v from %d (%v)", responses, posts, err)
+	}
+
+	var task models.Task
+	if err := responses[0].Decode(&task); err != nil || responses[0].ID != "send" || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected a completed task for the first request, got %+v (%v)", task, err)
+	}
+	var a2aErr *models.A2AError
+	if err := responses[1].Decode(&task); !errors.As(err, &a2aErr) || a2aErr.Code != models.ErrorCodeTaskNotFound {
+		t.Errorf("Expected task not found for the second request, got %v", err)
+	}
+	if responses[2].Result != nil || responses[2].Err != nil {
+		t.Errorf("Expected an empty response for the notification, got %+v", responses[2])
+	}
+
+	// A batch rejected as a whole fails
+	requests := make([]models.JSONRPCRequest, 4)
+	for i := range requests {
+		requests[i] = newRequest("get", "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "t1"}})
+	}
+	if _, err := client.SendBatch(context.Background(), requests...); !errors.As(err, &a2aErr) || a2aErr.Code != models.ErrorCodeInvalidRequest {
+		t.Errorf("Expected an invalid request error for an oversized batch, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"a2a/models"
+)
+
+// cardKeysPath is where agents signing their card serve the keys verifying it (see
+// server.JWKSPath)
+const cardKeysPath = "/.well-known/jwks.json"
+
+// WithCardKeys pins the keys GetVerifiedAgentCard verifies agent cards with, instead of
+// fetching them from the agent
+func WithCardKeys(keys models.JSONWebKeySet) Option {
+	return func(c *Client) {
+		c.cardKeys = &keys
+	}
+}
+
+// GetVerifiedAgentCard retrieves the agent card from the well-known path on the host of the
+// client's URL and returns it once one of its signatures verifies, with the keys pinned with
+// WithCardKeys or else those the agent serves at /.well-known/jwks.json. A card without
+// signatures fails with models.ErrCardNotSigned, and one whose signatures do not verify with
+// an error wrapping models.ErrInvalidSignature. Keys fetched from the agent only prove the
+// card was not altered since it was signed by whoever serves them; pin keys to trust a signer.
+func (c *Client) GetVerifiedAgentCard(ctx context.Context) (*models.AgentCard, error) {
+	data, err := c.getWellKnown(ctx, "/.well-known/agent-card", "agent card")
+	if err != nil {
+		return nil, err
+	}
+	keys := c.cardKeys
+	if keys == nil {
+		raw, err := c.getWellKnown(ctx, cardKeysPath, "agent card keys")
+		if err != nil {
+			return nil, err
+		}
+		keys = new(models.JSONWebKeySet)
+		if err := json.Unmarshal(raw, keys); err != nil {
+			return nil, fmt.Errorf("failed to decode agent card keys: %w", err)
+		}
+	}
+	card, err := models.VerifyAgentCard(data, *keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify agent card: %w", err)
+	}
+	return card, nil
+}
+
+// getWellKnown gets the JSON document at path on the host of the client's URL, naming it what
+// in errors
+func (c *Client) getWellKnown(ctx context.Context, path, what string) ([]byte, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent URL: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", base.Scheme+"://"+base.Host+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", what, sendError(ctx, err))
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, newStatusError(httpResp)
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", what, err)
+	}
+	return data, nil
+}
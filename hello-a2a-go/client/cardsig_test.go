@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestGetVerifiedAgentCard(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		return task, nil
+	}
+	serve := func(opts ...server.Option) *httptest.Server {
+		mux := http.NewServeMux()
+		card := models.AgentCard{Name: "Signed", URL: "http://agent.example/a2a", Skills: []models.AgentSkill{{ID: "echo", Name: "Echo"}}}
+		server.NewA2AServer(card, handler, opts...).RegisterRoutes(mux)
+		return httptest.NewServer(mux)
+	}
+
+	signed := serve(server.WithCardSigningKey(key, "k1"))
+	defer signed.Close()
+	card, err := NewClient(signed.URL + "/a2a").GetVerifiedAgentCard(context.Background())
+	if err != nil || card.Name != "Signed" {
+		t.Fatalf("Expected the verified card, got %+v (%v)", card, err)
+	}
+
+	// Pinned keys are used instead of the agent's
+	jwk, _ := models.NewJSONWebKey("k1", pub)
+	pinned := NewClient(signed.URL, WithCardKeys(models.JSONWebKeySet{Keys: []models.JSONWebKey{jwk}}))
+	if _, err := pinned.GetVerifiedAgentCard(context.Background()); err != nil {
+		t.Errorf("Expected the card to verify with the pinned key, got %v", err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	other, _ := models.NewJSONWebKey("k1", otherPub)
+	pinned = NewClient(signed.URL, WithCardKeys(models.JSONWebKeySet{Keys: []models.JSONWebKey{other}}))
+	if _, err := pinned.GetVerifiedAgentCard(context.Background()); !errors.Is(err, models.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature with another pinned key, got %v", err)
+	}
+
+	unsigned := serve()
+	defer unsigned.Close()
+	client := NewClient(unsigned.URL, WithCardKeys(models.JSONWebKeySet{Keys: []models.JSONWebKey{jwk}}))
+	if _, err := client.GetVerifiedAgentCard(context.Background()); !errors.Is(err, models.ErrCardNotSigned) {
+		t.Errorf("Expected ErrCardNotSigned, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"a2a/models"
+)
+
+// ErrArtifactIncomplete is returned by an ArtifactReader closed before the last chunk of its
+// artifact arrived
+var ErrArtifactIncomplete = errors.New("artifact stream ended before its last chunk")
+
+// ArtifactReader reassembles a file artifact streamed in chunks, such as by
+// server.ArtifactWriter: the stream's events are passed to Add, and the file content of the
+// chunks of one artifact is read from it in order, with io.EOF after its last chunk. It is safe
+// to call Add and Read from different goroutines.
+type ArtifactReader struct {
+	index *int
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	buf      []byte
+	name     string
+	mimeType string
+	done     bool
+	err      error
+}
+
+// NewArtifactReader returns a reader of the artifact at index, or of the first artifact
+// streamed when index is negative
+func NewArtifactReader(index int) *ArtifactReader {
+	r := &ArtifactReader{}
+	if index >= 0 {
+		r.index = &index
+	}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// Add passes a stream event, as received from SendMessageStreaming, to the reader and reports
+// whether it was a chunk of the reader's artifact. Other events are ignored. A chunk that
+// starts the artifact again, without Append, discards the content not yet read.
+func (r *ArtifactReader) Add(event interface{}) (bool, error) {
+	artifact, ok, err := artifactOf(event)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.index == nil {
+		index := 0
+		if artifact.Index != nil {
+			index = *artifact.Index
+		}
+		r.index = &index
+	}
+	if (artifact.Index == nil && *r.index != 0) || (artifact.Index != nil && *artifact.Index != *r.index) {
+		return false, nil
+	}
+	if r.done || r.err != nil {
+		return true, nil
+	}
+
+	if artifact.Append == nil || !*artifact.Append {
+		r.buf = nil
+	}
+	for _, part := range artifact.Parts {
+		file, ok := part.(models.FilePart)
+		if !ok {
+			continue
+		}
+		r.name, r.mimeType = file.FileName, file.MimeType
+		content, ok := file.Content.(models.FileContentBytes)
+		if !ok {
+			r.err = fmt.Errorf("artifact chunk references its content by %s", file.Content.GetContentType())
+			break
+		}
+		r.buf = append(r.buf, content.Bytes...)
+	}
+	if artifact.LastChunk != nil && *artifact.LastChunk {
+		r.done = true
+	}
+	r.cond.Broadcast()
+	return true, nil
+}
+
+// Read implements io.Reader, blocking until content arrives, the last chunk has been read or the
+// reader is closed
+func (r *ArtifactReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.buf) == 0 && !r.done && r.err == nil {
+		r.cond.Wait()
+	}
+	if len(r.buf) > 0 {
+		n := copy(p, r.buf)
+		r.buf = r.buf[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// Close ends the artifact; reads fail with ErrArtifactIncomplete unless its last chunk arrived.
+// Call it once the stream has ended, so a reader waiting on a chunk that never comes returns.
+func (r *ArtifactReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.done && r.err == nil {
+		r.err = ErrArtifactIncomplete
+	}
+	r.cond.Broadcast()
+	return nil
+}
+
+// FileName returns the name of the file streamed, once its first chunk arrived
+func (r *ArtifactReader) FileName() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.name
+}
+
+// MimeType returns the MIME type of the file streamed, once its first chunk arrived
+func (r *ArtifactReader) MimeType() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mimeType
+}
+
+// artifactOf returns the artifact of an artifact update event, given as decoded from a stream
+// or as a models.TaskArtifactUpdateEvent, and false for other events
+func artifactOf(event interface{}) (models.Artifact, bool, error) {
+	switch event := event.(type) {
+	case models.TaskArtifactUpdateEvent:
+		return event.Artifact, true, nil
+	case *models.TaskArtifactUpdateEvent:
+		return event.Artifact, true, nil
+	case map[string]interface{}:
+		if _, ok := event["artifact"]; !ok {
+			return models.Artifact{}, false, nil
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return models.Artifact{}, false, err
+		}
+		var update models.TaskArtifactUpdateEvent
+		if err := models.DecodeJSON(data, &update); err != nil {
+			return models.Artifact{}, false, fmt.Errorf("failed to decode artifact update: %w", err)
+		}
+		return update.Artifact, true, nil
+	}
+	return models.Artifact{}, false, nil
+}
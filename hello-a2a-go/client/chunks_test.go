@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestArtifactReader(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	agent := server.NewA2AServer(models.AgentCard{Name: "Chunks"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		w := server.NewArtifactWriter(ctx, 0, "digits.bin", "application/octet-stream", 1024)
+		io.Copy(w, bytes.NewReader(content))
+		w.Close()
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}, server.WithCompression("gzip"))
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+
+	client := NewClient(ts.URL, WithCompression("gzip"))
+	events := make(chan interface{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreaming(models.MessageSendParams{ID: "chunked", Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Digits"}}}}, events)
+		close(events)
+	}()
+
+	reader := NewArtifactReader(-1)
+	go func() {
+		for event := range events {
+			if _, err := reader.Add(event); err != nil {
+				t.Errorf("Failed to add event: %v", err)
+			}
+		}
+		reader.Close()
+	}()
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read artifact: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if !bytes.Equal(got, content) || reader.FileName() != "digits.bin" || reader.MimeType() != "application/octet-stream" {
+		t.Errorf("Expected the %d bytes of digits.bin, got %d bytes of %q", len(content), len(got), reader.FileName())
+	}
+}
+
+func TestArtifactReader_Incomplete(t *testing.T) {
+	reader := NewArtifactReader(1)
+	chunk := func(index int, text string) models.TaskArtifactUpdateEvent {
+		appended := true
+		return models.TaskArtifactUpdateEvent{ID: "t", Artifact: models.Artifact{
+			Index:  &index,
+			Append: &appended,
+			Parts:  []models.Part{models.FilePart{Type: "file", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte(text)}}},
+		}}
+	}
+	if ok, _ := reader.Add(chunk(0, "other")); ok {
+		t.Error("Expected a chunk of another artifact to be ignored")
+	}
+	if ok, _ := reader.Add(map[string]interface{}{"id": "t", "status": map[string]interface{}{"state": "working"}}); ok {
+		t.Error("Expected a status event to be ignored")
+	}
+	if ok, err := reader.Add(chunk(1, "part")); !ok || err != nil {
+		t.Fatalf("Expected the chunk to be added, got %v, %v", ok, err)
+	}
+	reader.Close()
+	got, err := io.ReadAll(reader)
+	if string(got) != "part" || !errors.Is(err, ErrArtifactIncomplete) {
+		t.Errorf("Expected the partial content then ErrArtifactIncomplete, got %q, %v", got, err)
+	}
+}
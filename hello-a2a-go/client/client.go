@@ -2,33 +2,107 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
+	"a2a/clock"
 	"a2a/models"
 )
 
+// defaultTimeout bounds each request, including reading its response, and the wait for an
+// event stream's response
+const defaultTimeout = 60 * time.Second // Increased timeout for Ollama processing
+
+// defaultMaxEventBytes bounds the size of a single streamed event
+const defaultMaxEventBytes = 10 << 20
+
+// ErrEventTooLarge is returned when a streamed event exceeds the client's event size limit
+var ErrEventTooLarge = errors.New("stream event too large")
+
+// errRequestTimeout is returned when a request exceeds the client timeout
+var errRequestTimeout = errors.New("request timed out")
+
 // Client represents an A2A protocol client (v0.3.0 compliant)
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	// socketPath is the Unix domain socket of a unix:// base URL
+	socketPath string
+	timeout    time.Duration
+	clock      clock.Clock
+	// maxEventBytes bounds each streamed event; zero means unlimited
+	maxEventBytes int
+	// streamRetries is how many times a broken event stream is resumed
+	streamRetries int
+	// retryPolicy retries failed requests; random jitters its backoff
+	retryPolicy RetryPolicy
+	random      func() float64
+	// pollInterval is how often SendMessageUntilDone polls a task that is still working
+	pollInterval time.Duration
+
+	// headers are added to every request
+	headers       http.Header
+	tokenSource   TokenSource
+	replayHeaders bool
+	signingSecret []byte
+	// logger receives the client's log records; nil uses slog.Default
+	logger *slog.Logger
+	// interceptors intercept every call, the first outermost
+	interceptors []Interceptor
+	// discovery caches the agent card found by Discover for cardTTL
+	discovery discovery
+	cardTTL   time.Duration
+	// cardKeys verify agent cards in GetVerifiedAgentCard; nil fetches the agent's keys
+	cardKeys *models.JSONWebKeySet
+	// compression is the content encoding of request bodies; empty sends them uncompressed
+	compression string
+	// transcript records every call; nil records nothing
+	transcript *Transcript
 }
 
-// NewClient creates a new A2A client (v0.3.0 compliant)
-func NewClient(baseURL string) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Increased timeout for Ollama processing
-		},
+// NewClient creates a new A2A client (v0.3.0 compliant). A unix:// base URL connects to an
+// agent served on a Unix domain socket, e.g. "unix:///run/agent.sock/a2a".
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:       baseURL,
+		httpClient:    &http.Client{},
+		timeout:       defaultTimeout,
+		maxEventBytes: defaultMaxEventBytes,
+		streamRetries: defaultStreamRetries,
+		pollInterval:  defaultPollInterval,
+		cardTTL:       defaultCardTTL,
+		clock:         clock.Real,
+		random:        rand.Float64,
+		headers:       make(http.Header),
 	}
+	if strings.HasPrefix(baseURL, unixScheme) {
+		socketPath, httpPath := splitUnixURL(baseURL)
+		c.baseURL = "http://unix" + httpPath
+		c.socketPath = socketPath
+		c.httpClient.Transport = unixTransport(socketPath)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // SendMessage sends a message to the agent (A2A v0.3.0 compliant)
 func (c *Client) SendMessage(params models.MessageSendParams) (*models.JSONRPCResponse, error) {
+	return c.SendMessageContext(context.Background(), params)
+}
+
+// SendMessageContext is like SendMessage but sends the request with ctx: canceling ctx aborts
+// the request, and a trace context in ctx (see package trace) is propagated to the agent
+func (c *Client) SendMessageContext(ctx context.Context, params models.MessageSendParams) (*models.JSONRPCResponse, error) {
 	req := models.JSONRPCRequest{
 		JSONRPCMessage: models.JSONRPCMessage{
 			JSONRPC: "2.0",
@@ -41,12 +115,12 @@ func (c *Client) SendMessage(params models.MessageSendParams) (*models.JSONRPCRe
 	}
 
 	var resp models.JSONRPCResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
 	if resp.Error != nil {
-		return nil, fmt.Errorf("A2A error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, models.ErrorFromJSONRPC(resp.Error)
 	}
 
 	return &resp, nil
@@ -54,33 +128,76 @@ func (c *Client) SendMessage(params models.MessageSendParams) (*models.JSONRPCRe
 
 // SendTask sends a task message to the agent (backwards compatibility)
 func (c *Client) SendTask(params models.TaskSendParams) (*models.JSONRPCResponse, error) {
+	return c.SendTaskContext(context.Background(), params)
+}
+
+// SendTaskContext is like SendTask with a context (see SendMessageContext)
+func (c *Client) SendTaskContext(ctx context.Context, params models.TaskSendParams) (*models.JSONRPCResponse, error) {
 	// Convert TaskSendParams to MessageSendParams for compatibility
 	msgParams := models.MessageSendParams{
-		ID:      params.ID,
-		Message: params.Message,
-		Config:  nil, // TaskSendParams doesn't have config, set to nil
+		ID:       params.ID,
+		Message:  params.Message,
+		Config:   nil, // TaskSendParams doesn't have config, set to nil
+		Metadata: params.Metadata,
 	}
-	return c.SendMessage(msgParams)
+	return c.SendMessageContext(ctx, msgParams)
 }
 
-// GetTask retrieves the status of a task (backwards compatibility)
+// GetTask retrieves the status of a task (A2A v0.3.0 compliant)
 func (c *Client) GetTask(params models.TaskQueryParams) (*models.JSONRPCResponse, error) {
-	return c.ListMessages(params)
+	return c.GetTaskContext(context.Background(), params)
+}
+
+// GetTaskContext is like GetTask with a context (see SendMessageContext)
+func (c *Client) GetTaskContext(ctx context.Context, params models.TaskQueryParams) (*models.JSONRPCResponse, error) {
+	req := models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC: "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{
+				ID: params.ID + "-get-request",
+			},
+		},
+		Method: "tasks/get",
+		Params: params,
+	}
+
+	var resp models.JSONRPCResponse
+	if err := c.doRequest(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, models.ErrorFromJSONRPC(resp.Error)
+	}
+
+	return &resp, nil
 }
 
 // SendTaskStreaming sends a task message and streams the response (backwards compatibility)
 func (c *Client) SendTaskStreaming(params models.TaskSendParams, eventChan chan<- interface{}) error {
+	return c.SendTaskStreamingContext(context.Background(), params, eventChan)
+}
+
+// SendTaskStreamingContext is like SendTaskStreaming with a context (see SendMessageContext)
+func (c *Client) SendTaskStreamingContext(ctx context.Context, params models.TaskSendParams, eventChan chan<- interface{}) error {
 	// Convert TaskSendParams to MessageSendParams for compatibility
 	msgParams := models.MessageSendParams{
-		ID:      params.ID,
-		Message: params.Message,
-		Config:  nil, // TaskSendParams doesn't have config, set to nil
+		ID:       params.ID,
+		Message:  params.Message,
+		Config:   nil, // TaskSendParams doesn't have config, set to nil
+		Metadata: params.Metadata,
 	}
-	return c.SendMessageStreaming(msgParams, eventChan)
+	return c.SendMessageStreamingContext(ctx, msgParams, eventChan)
 }
 
-// ListMessages retrieves messages (A2A v0.3.0 compliant)
+// ListMessages retrieves the messages of the task params names (A2A v0.3.0 compliant); see
+// ListMessagesTyped for filters and pagination
 func (c *Client) ListMessages(params models.TaskQueryParams) (*models.JSONRPCResponse, error) {
+	return c.ListMessagesContext(context.Background(), params)
+}
+
+// ListMessagesContext is like ListMessages with a context (see SendMessageContext)
+func (c *Client) ListMessagesContext(ctx context.Context, params models.TaskQueryParams) (*models.JSONRPCResponse, error) {
 	req := models.JSONRPCRequest{
 		JSONRPCMessage: models.JSONRPCMessage{
 			JSONRPC: "2.0",
@@ -93,12 +210,12 @@ func (c *Client) ListMessages(params models.TaskQueryParams) (*models.JSONRPCRes
 	}
 
 	var resp models.JSONRPCResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
 	if resp.Error != nil {
-		return nil, fmt.Errorf("A2A error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, models.ErrorFromJSONRPC(resp.Error)
 	}
 
 	return &resp, nil
@@ -106,6 +223,11 @@ func (c *Client) ListMessages(params models.TaskQueryParams) (*models.JSONRPCRes
 
 // CancelTask cancels a task (A2A v0.3.0 compliant)
 func (c *Client) CancelTask(params models.TaskIDParams) (*models.JSONRPCResponse, error) {
+	return c.CancelTaskContext(context.Background(), params)
+}
+
+// CancelTaskContext is like CancelTask with a context (see SendMessageContext)
+func (c *Client) CancelTaskContext(ctx context.Context, params models.TaskIDParams) (*models.JSONRPCResponse, error) {
 	req := models.JSONRPCRequest{
 		JSONRPCMessage: models.JSONRPCMessage{
 			JSONRPC: "2.0",
@@ -113,17 +235,17 @@ func (c *Client) CancelTask(params models.TaskIDParams) (*models.JSONRPCResponse
 				ID: params.ID + "-cancel-request",
 			},
 		},
-		Method: "message/pending",
+		Method: "tasks/cancel",
 		Params: params,
 	}
 
 	var resp models.JSONRPCResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
 	if resp.Error != nil {
-		return nil, fmt.Errorf("A2A error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, models.ErrorFromJSONRPC(resp.Error)
 	}
 
 	return &resp, nil
@@ -131,6 +253,11 @@ func (c *Client) CancelTask(params models.TaskIDParams) (*models.JSONRPCResponse
 
 // SendMessageStreaming sends a message and streams the response (A2A v0.3.0 compliant)
 func (c *Client) SendMessageStreaming(params models.MessageSendParams, eventChan chan<- interface{}) error {
+	return c.SendMessageStreamingContext(context.Background(), params, eventChan)
+}
+
+// SendMessageStreamingContext is like SendMessageStreaming with a context (see SendMessageContext)
+func (c *Client) SendMessageStreamingContext(ctx context.Context, params models.MessageSendParams, eventChan chan<- interface{}) error {
 	req := models.JSONRPCRequest{
 		JSONRPCMessage: models.JSONRPCMessage{
 			JSONRPC: "2.0",
@@ -141,117 +268,410 @@ func (c *Client) SendMessageStreaming(params models.MessageSendParams, eventChan
 		Method: "message/stream",
 		Params: params,
 	}
+	return c.stream(ctx, req, "", eventChan)
+}
 
-	body, err := json.Marshal(req)
+// ResubscribeTask reattaches to the event stream of a task that is still running, for a client
+// that lost its stream, with tasks/resubscribe. The agent first sends the events after
+// lastEventID, the ID of the last event received, or every event of the stream when it is
+// empty; a task with no stream left gets one final event with its current status.
+func (c *Client) ResubscribeTask(ctx context.Context, params models.TaskQueryParams, lastEventID string, eventChan chan<- interface{}) error {
+	req := models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC: "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{
+				ID: params.ID + "-resubscribe-request",
+			},
+		},
+		Method: "tasks/resubscribe",
+		Params: params,
+	}
+	return c.stream(ctx, req, lastEventID, eventChan)
+}
+
+// stream sends the streaming request req through the client's interceptors, forwarding its
+// events to eventChan (see streamEvents)
+func (c *Client) stream(ctx context.Context, req models.JSONRPCRequest, lastEventID string, eventChan chan<- interface{}) (err error) {
+	// The span and request ID cover the whole stream, including resumes
+	ctx, span, req := startSpan(ctx, req)
+	ctx, _ = ensureRequestID(ctx)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	if err := c.checkStreaming(ctx); err != nil {
+		return err
+	}
+
+	call := &Request{JSONRPCRequest: req, Header: make(http.Header), Streaming: true}
+	_, err = c.intercept(ctx, call, func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, c.streamEvents(ctx, req, lastEventID, eventChan)
+	})
+	return err
+}
+
+// streamEvents sends the streaming call req, resuming after lastEventID if set, and forwards
+// its events to eventChan, resuming the stream when it breaks before its final event
+func (c *Client) streamEvents(ctx context.Context, req *Request, lastEventID string, eventChan chan<- interface{}) error {
+	ctx = withCallHeader(ctx, req.Header)
+	body, err := json.Marshal(req.JSONRPCRequest)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", c.baseURL, bytes.NewBuffer(body))
+	policy := c.policy(ctx)
+	resumes, attempts := 0, 1
+	// sequence is the highest sequence number forwarded, so that no resumed event repeats
+	var sequence int64
+	for {
+		reader, final, err := c.streamOnce(ctx, body, lastEventID, &sequence, eventChan)
+		if reader != nil && reader.lastID != "" {
+			lastEventID = reader.lastID
+		}
+		if err == nil && final {
+			return nil
+		}
+
+		var delay time.Duration
+		switch {
+		case errors.Is(err, errStreamInterrupted):
+			// Only a stream with event IDs can be resumed without rerunning the task
+			if lastEventID == "" {
+				return fmt.Errorf("%w: %w", io.ErrUnexpectedEOF, err)
+			}
+			if resumes >= c.streamRetries {
+				return fmt.Errorf("stream interrupted after %d retries: %w", resumes, err)
+			}
+			resumes++
+			delay = defaultRetryDelay
+			if policy.MaxAttempts > 1 {
+				delay = policy.backoff(resumes, c.random)
+			}
+			if reader != nil && reader.retry > 0 {
+				delay = reader.retry
+			}
+			c.log(ctx).Warn("resuming stream", slog.Int("attempt", resumes),
+				slog.String("last_event_id", lastEventID), slog.Duration("delay", delay))
+		case lastEventID == "" && attempts < policy.MaxAttempts && policy.retryable(err):
+			// The stream failed to start, so the request can be sent again
+			delay = policy.delay(attempts, err, c.random)
+			c.log(ctx).Warn("retrying request", slog.Int("attempt", attempts),
+				slog.Duration("delay", delay), slog.Any("error", err))
+			attempts++
+		default:
+			return err
+		}
+
+		select {
+		case <-c.clock.After(delay):
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// errStreamInterrupted is returned by streamOnce when a stream ends before its final event
+var errStreamInterrupted = errors.New("stream ended before the final event")
+
+// streamOnce posts a streaming request, resuming after lastEventID if set, and forwards
+// its events to eventChan, skipping those numbered at most *sequence, which it advances. It
+// reports whether the final event was received.
+func (c *Client) streamOnce(ctx context.Context, body []byte, lastEventID string, sequence *int64, eventChan chan<- interface{}) (*sseReader, bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", lastEventID)
+	}
+	if err := c.prepareRequest(httpReq, body); err != nil {
+		return nil, false, fmt.Errorf("failed to prepare request: %w", err)
+	}
 
-	httpResp, err := c.httpClient.Do(httpReq)
+	httpResp, err := c.sendStream(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		if lastEventID != "" && ctx.Err() == nil && !errors.Is(err, errRequestTimeout) {
+			// The agent may be restarting; keep resuming
+			return nil, false, fmt.Errorf("%w: %w", errStreamInterrupted, err)
+		}
+		return nil, false, fmt.Errorf("failed to send request: %w", sendError(ctx, err))
 	}
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+		return nil, false, newStatusError(httpResp)
 	}
 
-	decoder := json.NewDecoder(httpResp.Body)
+	reader := newSSEReader(httpResp.Body, c.maxEventBytes)
+	reader.lastID = lastEventID
 	for {
-		var event models.SendMessageStreamingResponse
-		if err := decoder.Decode(&event); err != nil {
-			if err == io.EOF {
-				break
+		raw, err := reader.next()
+		if err == io.EOF {
+			return reader, false, errStreamInterrupted
+		}
+		if err != nil {
+			if ctx := httpResp.Request.Context(); ctx.Err() != nil {
+				return reader, false, context.Cause(ctx)
 			}
-			return fmt.Errorf("failed to decode event: %w", err)
+			if errors.Is(err, ErrEventTooLarge) || errors.Is(err, errRequestTimeout) {
+				return reader, false, err
+			}
+			return reader, false, fmt.Errorf("%w: failed to read event: %w", errStreamInterrupted, err)
+		}
+
+		var event models.SendMessageStreamingResponse
+		if err := models.DecodeJSON(raw.data, &event); err != nil {
+			return reader, false, fmt.Errorf("failed to decode event: %w", err)
 		}
 
 		if event.Error != nil {
-			return fmt.Errorf("A2A error: %s (code: %d)", event.Error.Message, event.Error.Code)
+			return reader, false, models.ErrorFromJSONRPC(event.Error)
+		}
+
+		result, _ := event.Result.(map[string]interface{})
+		final := result["final"] == true
+		if seq := eventSequence(result); seq > 0 {
+			if seq <= *sequence {
+				// Replayed by the agent, which the client already has
+				if final {
+					return reader, true, nil
+				}
+				continue
+			}
+			*sequence = seq
 		}
 
 		select {
 		case eventChan <- event.Result:
-		case <-httpReq.Context().Done():
-			return httpReq.Context().Err()
+		case <-httpResp.Request.Context().Done():
+			return reader, false, context.Cause(httpResp.Request.Context())
+		}
+		if c.transcript != nil {
+			c.transcript.recordEvent(ctx, c.clock.Now(), event.Result)
+		}
+		if final {
+			return reader, true, nil
+		}
+	}
+}
+
+// doRequest performs the HTTP request and handles the response, decoding its result as a
+// *models.Task or, for agents answering message/send directly, a *models.Message
+func (c *Client) doRequest(ctx context.Context, req models.JSONRPCRequest, resp *models.JSONRPCResponse) error {
+	rawResp, err := c.doRawRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// Copy the basic fields
+	resp.JSONRPCMessage.JSONRPC = rawResp.JSONRPC
+	resp.JSONRPCMessage.JSONRPCMessageIdentifier.ID = rawResp.ID
+	resp.Error = rawResp.Error
+
+	// Decode a result by its kind, as a task unless it is a message
+	if len(rawResp.Result) > 0 {
+		var result models.SendMessageResult
+		if err := models.DecodeJSON(rawResp.Result, &result); err != nil {
+			return fmt.Errorf("failed to decode result: %w", err)
+		}
+		switch {
+		case result.Message != nil:
+			resp.Result = result.Message
+		case result.Task != nil:
+			resp.Result = result.Task
 		}
 	}
 
 	return nil
 }
 
-// doRequest performs the HTTP request and handles the response
-func (c *Client) doRequest(req interface{}, resp *models.JSONRPCResponse) error {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+// Response is a JSON-RPC response whose result is left undecoded, as interceptors see it
+type Response struct {
+	JSONRPC string               `json:"jsonrpc"`
+	ID      interface{}          `json:"id,omitempty"`
+	Result  json.RawMessage      `json:"result,omitempty"`
+	Error   *models.JSONRPCError `json:"error,omitempty"`
+}
+
+// doRawRequest sends req through the client's interceptors, returning the response with its
+// result undecoded
+func (c *Client) doRawRequest(ctx context.Context, req models.JSONRPCRequest) (resp *Response, err error) {
+	ctx, span, req := startSpan(ctx, req)
+	ctx, _ = ensureRequestID(ctx)
+	start := c.clock.Now()
+	defer func() {
+		span.RecordError(err)
+		span.End()
+		attrs := []slog.Attr{
+			slog.Duration("duration", c.clock.Now().Sub(start)),
+			slog.String("rpc_method", req.Method), slog.Any("rpc_id", req.ID),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		c.log(ctx).LogAttrs(ctx, slog.LevelDebug, "request", attrs...)
+	}()
+
+	resp, err = c.intercept(ctx, &Request{JSONRPCRequest: req, Header: make(http.Header)}, c.invoke)
+	if resp != nil && resp.Error != nil {
+		// A JSON-RPC error response is returned as a response, but fails the span
+		span.RecordError(models.ErrorFromJSONRPC(resp.Error))
 	}
+	return resp, err
+}
 
-	httpReq, err := http.NewRequest("POST", c.baseURL, bytes.NewBuffer(body))
+// invoke sends the call req, returning the response with its result undecoded. Failed
+// attempts are retried according to the client's retry policy; a JSON-RPC error response is
+// returned once its code is not retried or the attempts run out.
+func (c *Client) invoke(ctx context.Context, req *Request) (resp *Response, err error) {
+	ctx = withCallHeader(ctx, req.Header)
+	body, err := json.Marshal(req.JSONRPCRequest)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+	err = c.retry(ctx, func() error {
+		resp = nil
+		rawResp, err := c.postRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+		resp = rawResp
+		if rawResp.Error != nil {
+			return models.ErrorFromJSONRPC(rawResp.Error)
+		}
+		return nil
+	})
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
 
-	httpResp, err := c.httpClient.Do(httpReq)
+// postRequest makes one attempt at posting a JSON-RPC request body
+func (c *Client) postRequest(ctx context.Context, body []byte) (*Response, error) {
+	httpResp, err := c.post(ctx, body)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+		return nil, newStatusError(httpResp)
 	}
 
-	// First decode into a map to handle the Result field correctly
-	var rawResp struct {
-		JSONRPC string               `json:"jsonrpc"`
-		ID      interface{}          `json:"id,omitempty"`
-		Result  json.RawMessage      `json:"result,omitempty"`
-		Error   *models.JSONRPCError `json:"error,omitempty"`
+	var rawResp Response
+	if err := json.NewDecoder(httpResp.Body).Decode(&rawResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return &rawResp, nil
+}
 
-	if err := json.NewDecoder(httpResp.Body).Decode(&rawResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+// post posts body to the JSON-RPC endpoint, returning the response whatever its status
+func (c *Client) post(ctx context.Context, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Copy the basic fields
-	resp.JSONRPCMessage.JSONRPC = rawResp.JSONRPC
-	resp.JSONRPCMessage.JSONRPCMessageIdentifier.ID = rawResp.ID
-	resp.Error = rawResp.Error
+	httpReq.Header.Set("Content-Type", "application/json")
+	if err := c.prepareRequest(httpReq, body); err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
 
-	// If there's a result, try to decode it as a Task
-	if len(rawResp.Result) > 0 {
-		var task models.Task
-		if err := json.Unmarshal(rawResp.Result, &task); err != nil {
-			return fmt.Errorf("failed to decode task: %w", err)
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", sendError(ctx, err))
+	}
+	return httpResp, nil
+}
+
+// send performs httpReq, canceling it once the client timeout elapses; the timeout keeps
+// running until the response body is closed
+func (c *Client) send(httpReq *http.Request) (*http.Response, error) {
+	return c.do(httpReq, true)
+}
+
+// sendStream performs httpReq for an event stream. The client timeout only bounds the wait
+// for the response, so that a long stream is read until its final event, or until the
+// request's context is done.
+func (c *Client) sendStream(httpReq *http.Request) (*http.Response, error) {
+	return c.do(httpReq, false)
+}
+
+// do performs httpReq, canceling it once the client timeout elapses before its response
+// arrives or, when timeBody is set, before its response body is closed
+func (c *Client) do(httpReq *http.Request, timeBody bool) (*http.Response, error) {
+	if c.timeout <= 0 {
+		httpResp, err := c.httpClient.Do(httpReq)
+		if err == nil {
+			decodeResponse(httpResp)
 		}
-		resp.Result = &task
+		return httpResp, err
 	}
 
-	return nil
+	ctx, cancel := context.WithCancelCause(httpReq.Context())
+	timer := c.clock.AfterFunc(c.timeout, func() { cancel(errRequestTimeout) })
+	stop := func() {
+		timer.Stop()
+		cancel(nil)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		stop()
+		if errors.Is(context.Cause(ctx), errRequestTimeout) {
+			return nil, errRequestTimeout
+		}
+		return nil, err
+	}
+	if !timeBody {
+		timer.Stop()
+	}
+	decodeResponse(httpResp)
+	httpResp.Body = &timeoutBody{ReadCloser: httpResp.Body, ctx: ctx, stop: stop}
+	return httpResp, nil
+}
+
+// timeoutBody reports reads interrupted by the client timeout as errRequestTimeout and
+// stops the timeout when closed
+type timeoutBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	stop func()
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && errors.Is(context.Cause(b.ctx), errRequestTimeout) {
+		return n, errRequestTimeout
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	b.stop()
+	return b.ReadCloser.Close()
 }
 
 // GetAgentCard retrieves the agent card from the well-known endpoint (A2A v0.3.0 compliant)
 func (c *Client) GetAgentCard() (*models.AgentCard, error) {
-	httpReq, err := http.NewRequest("GET", c.baseURL+"/.well-known/agent-card", nil)
+	return c.GetAgentCardContext(context.Background())
+}
+
+// GetAgentCardContext is like GetAgentCard with a context (see SendMessageContext)
+func (c *Client) GetAgentCardContext(ctx context.Context) (*models.AgentCard, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/.well-known/agent-card", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Accept", "application/json")
 
-	httpResp, err := c.httpClient.Do(httpReq)
+	httpResp, err := c.send(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -1,12 +1,20 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"a2a/clock"
 	"a2a/models"
+	"a2a/trace"
 )
 
 func TestSendTask(t *testing.T) {
@@ -16,8 +24,8 @@ func TestSendTask(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if req.Method != "tasks/send" {
-			t.Errorf("expected method tasks/send, got %s", req.Method)
+		if req.Method != "message/send" {
+			t.Errorf("expected method message/send, got %s", req.Method)
 		}
 
 		task := &models.Task{
@@ -45,8 +53,9 @@ func TestSendTask(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{
-					Text: stringPtr("test message"),
+				models.TextPart{
+					Type: "text",
+					Text: "test message",
 				},
 			},
 		},
@@ -214,6 +223,9 @@ func TestErrorResponse(t *testing.T) {
 	if err.Error() != expectedError {
 		t.Errorf("expected error %q, got %q", expectedError, err.Error())
 	}
+	if code := models.ErrorCodeOf(err); code != -32000 {
+		t.Errorf("expected error code -32000, got %d", code)
+	}
 }
 
 func TestSendTaskStreaming(t *testing.T) {
@@ -232,27 +244,29 @@ func TestSendTaskStreaming(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if req.Method != "tasks/send" {
-			t.Errorf("expected method tasks/send, got %s", req.Method)
+		if req.Method != "message/stream" {
+			t.Errorf("expected method message/stream, got %s", req.Method)
 		}
 
 		// Set response headers for streaming
 		w.Header().Set("Content-Type", "application/json")
 		w.(http.Flusher).Flush()
 
-		// Send multiple events
-		events := []*models.Task{
-			{
+		// Send multiple events, the last of them final
+		final := true
+		events := []interface{}{
+			&models.Task{
 				ID: "123",
 				Status: models.TaskStatus{
 					State: models.TaskStateWorking,
 				},
 			},
-			{
+			models.TaskStatusUpdateEvent{
 				ID: "123",
 				Status: models.TaskStatus{
 					State: models.TaskStateCompleted,
 				},
+				Final: &final,
 			},
 		}
 
@@ -282,8 +296,9 @@ func TestSendTaskStreaming(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{
-					Text: stringPtr("test message"),
+				models.TextPart{
+					Type: "text",
+					Text: "test message",
 				},
 			},
 		},
@@ -301,10 +316,10 @@ func TestSendTaskStreaming(t *testing.T) {
 	// Collect and verify events
 	var events []models.Task
 	for event := range eventChan {
-		// The event should be a json.RawMessage that we need to unmarshal into a Task
-		rawMsg, ok := event.(json.RawMessage)
-		if !ok {
-			t.Fatal("expected event to be a json.RawMessage")
+		// The event is decoded generically, so round-trip it into a Task
+		rawMsg, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("failed to marshal event: %v", err)
 		}
 
 		var task models.Task
@@ -340,3 +355,237 @@ func TestSendTaskStreaming(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestClientOptions(t *testing.T) {
+	nonces := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-API-Key"); got != "secret" {
+			t.Errorf("expected API key header, got %q", got)
+		}
+		nonce := r.Header.Get(models.HeaderNonce)
+		if nonce == "" || nonces[nonce] {
+			t.Errorf("expected a fresh nonce, got %q", nonce)
+		}
+		nonces[nonce] = true
+		if r.Header.Get(models.HeaderTimestamp) == "" {
+			t.Error("expected a timestamp header")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{JSONRPC: "2.0"},
+			Result:         &models.Task{ID: "123"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAPIKey("secret"), WithReplayProtection())
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestClientExtensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(models.HeaderExtensions); got != "urn:a, urn:b" {
+			t.Errorf("expected the extensions header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{JSONRPC: "2.0"},
+			Result:         &models.Task{ID: "123"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithExtensions("urn:a", "urn:b"))
+	if _, err := client.GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientSigning(t *testing.T) {
+	secret := []byte("shared-secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(models.HeaderTimestamp)
+		nonce := r.Header.Get(models.HeaderNonce)
+		if !models.VerifyRequestSignature(secret, r.Method, models.SignedPath(r), timestamp, nonce, body, r.Header.Get(models.HeaderSignature)) {
+			t.Error("expected a valid request signature")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{JSONRPC: "2.0"},
+			Result:         &models.Task{ID: "123"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithSigningSecret(secret), WithReplayProtection())
+	if _, err := client.GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(server.URL, WithClock(fake), WithTimeout(time.Minute))
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}})
+		errc <- err
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	if err := <-errc; !errors.Is(err, errRequestTimeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if fake.Waiters() != 0 {
+		t.Errorf("expected timer to be released, %d pending", fake.Waiters())
+	}
+}
+
+func TestSendTaskStreaming_EventTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"working"}}}`+"\n")
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","text":"`+strings.Repeat("x", 2048)+`"}}`+"\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithMaxEventBytes(1024))
+	eventChan := make(chan interface{}, 10)
+	err := client.SendTaskStreaming(models.TaskSendParams{ID: "1"}, eventChan)
+	if !errors.Is(err, ErrEventTooLarge) {
+		t.Fatalf("expected ErrEventTooLarge, got %v", err)
+	}
+	if len(eventChan) != 1 {
+		t.Errorf("expected the event within the limit to be delivered, got %d events", len(eventChan))
+	}
+}
+
+func TestSendMessageContext_PropagatesTraceContext(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"completed"}}}`)
+	}))
+	defer server.Close()
+
+	ctx := trace.NewContext(context.Background(), trace.Context{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Baggage:     "tenant=acme",
+	})
+	client := NewClient(server.URL)
+	if _, err := client.SendMessageContext(ctx, models.MessageSendParams{ID: "1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("traceparent") != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" || got.Get("baggage") != "tenant=acme" {
+		t.Errorf("expected trace headers on the request, got %v", got)
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"working"}}}`+"\n")
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	client := NewClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetAgentCardContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to abort GetAgentCardContext, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	eventChan := make(chan interface{}, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreamingContext(ctx, models.MessageSendParams{ID: "1"}, eventChan)
+	}()
+	<-eventChan
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceling to stop the stream, got %v", err)
+	}
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+
+		if req.Method != "tasks/history" {
+			t.Errorf("expected method tasks/history, got %s", req.Method)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":"123","result":{"messageHistory":[{"role":"user","parts":[{"kind":"text","text":"Hello"}]}],`+
+			`"statusHistory":[{"state":"working","timestamp":"2025-01-01T00:00:00Z"},{"state":"completed","timestamp":"2025-01-01T00:01:00Z"}]}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	history, err := client.GetTaskHistory(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.MessageHistory) != 1 || history.MessageHistory[0].Role != "user" {
+		t.Errorf("expected the user message, got %+v", history.MessageHistory)
+	}
+	if len(history.StatusHistory) != 2 || history.StatusHistory[1].State != models.TaskStateCompleted || history.StatusHistory[1].Timestamp != "2025-01-01T00:01:00Z" {
+		t.Errorf("expected working then completed, got %+v", history.StatusHistory)
+	}
+}
+
+func TestResubscribeTask(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Method != "tasks/resubscribe" {
+			t.Errorf("expected method tasks/resubscribe, got %s", req.Method)
+		}
+		if got := r.Header.Get("Last-Event-ID"); got != "s1/1" {
+			t.Errorf("expected Last-Event-ID s1/1, got %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: s1/2\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"123\",\"status\":{\"state\":\"completed\"},\"final\":true}}\n\n")
+	}))
+	defer server.Close()
+
+	events := make(chan interface{}, 1)
+	err := NewClient(server.URL).ResubscribeTask(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}}, "s1/1", events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event, ok := (<-events).(map[string]interface{}); !ok || event["final"] != true {
+		t.Errorf("expected the final event, got %v", event)
+	}
+}
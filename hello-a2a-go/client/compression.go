@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"a2a/models"
+)
+
+// WithCompression compresses JSON-RPC request bodies in encoding, a registered content
+// encoding such as "gzip" (see models.RegisterContentEncoding), and asks for responses in any
+// registered encoding, for agents serving large artifacts with server.WithCompression. The
+// signature of WithSigningSecret covers the compressed body, as sent.
+func WithCompression(encoding string) Option {
+	return func(c *Client) {
+		c.compression = encoding
+	}
+}
+
+// compressBody compresses the JSON body of httpReq when compression is configured, returning
+// the body as sent
+func (c *Client) compressBody(httpReq *http.Request, body []byte) ([]byte, error) {
+	if c.compression == "" || body == nil || httpReq.Header.Get("Content-Type") != "application/json" {
+		return body, nil
+	}
+	codec, ok := models.LookupContentEncoding(c.compression)
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encoding %q", c.compression)
+	}
+	var buf bytes.Buffer
+	w, err := codec.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	compressed := buf.Bytes()
+	httpReq.Body = io.NopCloser(bytes.NewReader(compressed))
+	httpReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	httpReq.ContentLength = int64(len(compressed))
+	httpReq.Header.Set("Content-Encoding", c.compression)
+	httpReq.Header.Set("Accept-Encoding", strings.Join(models.ContentEncodings(), ", "))
+	return compressed, nil
+}
+
+// decodeResponse decompresses the body of a response in a registered content encoding. Go's
+// transport already decompresses gzip responses to requests that did not ask for an encoding.
+func decodeResponse(httpResp *http.Response) {
+	encoding := httpResp.Header.Get("Content-Encoding")
+	if encoding == "" {
+		return
+	}
+	codec, ok := models.LookupContentEncoding(encoding)
+	if !ok {
+		return
+	}
+	httpResp.Body = &decodingBody{codec: codec, body: httpResp.Body}
+	httpResp.Header.Del("Content-Encoding")
+	httpResp.Header.Del("Content-Length")
+	httpResp.ContentLength = -1
+	httpResp.Uncompressed = true
+}
+
+// decodingBody decompresses a response body, opening the decompressor on the first read so
+// that a stream does not wait for its first event before returning
+type decodingBody struct {
+	codec  models.ContentCodec
+	body   io.ReadCloser
+	reader io.ReadCloser
+	err    error
+}
+
+func (d *decodingBody) Read(p []byte) (int, error) {
+	if d.reader == nil && d.err == nil {
+		d.reader, d.err = d.codec.NewReader(d.body)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.reader.Read(p)
+}
+
+func (d *decodingBody) Close() error {
+	if d.reader != nil {
+		d.reader.Close()
+	}
+	return d.body.Close()
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestWithCompression(t *testing.T) {
+	secret := []byte("shared")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" || r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("expected a gzip request accepting gzip, got headers %v", r.Header)
+		}
+		// The signature covers the body as sent
+		body, _ := io.ReadAll(r.Body)
+		if !models.VerifyRequestSignature(secret, r.Method, models.SignedPath(r), r.Header.Get(models.HeaderTimestamp), r.Header.Get(models.HeaderNonce), body, r.Header.Get(models.HeaderSignature)) {
+			t.Error("expected the compressed body to be signed")
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to decompress request: %v", err)
+		}
+		var req models.JSONRPCRequest
+		if err := json.NewDecoder(zr).Decode(&req); err != nil || req.Method != "tasks/get" {
+			t.Errorf("expected tasks/get, got %+v (%v)", req, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		json.NewEncoder(zw).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": map[string]interface{}{"id": "123", "status": map[string]interface{}{"state": "completed"}}})
+		zw.Close()
+	}))
+	defer server.Close()
+
+	task, err := NewClient(server.URL, WithCompression("gzip"), WithSigningSecret(secret)).GetTaskTyped(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "123" || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("expected the decompressed task, got %+v", task)
+	}
+
+	if _, err := NewClient(server.URL, WithCompression("zstd")).GetTaskTyped(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "123"}}); err == nil {
+		t.Error("expected an unregistered encoding to fail")
+	}
+}
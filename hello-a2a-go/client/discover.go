@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// defaultCardTTL is how long a discovered agent card is used before it is revalidated
+const defaultCardTTL = 5 * time.Minute
+
+// ErrStreamingUnsupported is returned by streaming calls to an agent whose discovered card
+// declares that it does not stream
+var ErrStreamingUnsupported = errors.New("agent does not support streaming")
+
+// WithCardTTL sets how long the agent card found by Discover is used before it is revalidated,
+// unless its response sets a max-age; the default is 5 minutes
+func WithCardTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cardTTL = ttl
+	}
+}
+
+// discovery is the agent card found by Discover and the JSON-RPC endpoint it declares
+type discovery struct {
+	// refresh serializes fetching the card, so concurrent calls revalidate it once
+	refresh sync.Mutex
+
+	mu       sync.RWMutex
+	card     *models.AgentCard
+	etag     string
+	expires  time.Time
+	endpoint string
+}
+
+// Discover fetches the agent card from the well-known path on the host of the client's URL and
+// caches it, revalidating it with its ETag once it expires (see WithCardTTL). Later requests go
+// to the JSON-RPC endpoint the card declares, its URL or one of its additional interfaces, and
+// streaming calls fail with ErrStreamingUnsupported if the card says the agent does not stream.
+func (c *Client) Discover(ctx context.Context) (*models.AgentCard, error) {
+	d := &c.discovery
+	d.refresh.Lock()
+	defer d.refresh.Unlock()
+
+	d.mu.RLock()
+	card, etag, fresh := d.card, d.etag, c.clock.Now().Before(d.expires)
+	d.mu.RUnlock()
+	if card != nil && fresh {
+		return card, nil
+	}
+
+	fetched, etag, ttl, err := c.fetchCard(ctx, etag)
+	if err != nil {
+		return nil, err
+	}
+	if fetched == nil {
+		// Not modified
+		fetched = card
+	}
+	endpoint, err := jsonrpcEndpoint(fetched)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.card, d.etag, d.expires, d.endpoint = fetched, etag, c.clock.Now().Add(ttl), endpoint
+	return fetched, nil
+}
+
+// fetchCard gets the agent card with If-None-Match etag, returning a nil card when it is not
+// modified, along with its ETag and how long to cache it
+func (c *Client) fetchCard(ctx context.Context, etag string) (*models.AgentCard, string, time.Duration, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("invalid agent URL: %w", err)
+	}
+	cardURL := base.Scheme + "://" + base.Host + "/.well-known/agent-card"
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", cardURL, nil)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if etag != "" {
+		httpReq.Header.Set("If-None-Match", etag)
+	}
+
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to fetch agent card: %w", sendError(ctx, err))
+	}
+	defer httpResp.Body.Close()
+
+	ttl := c.cardTTL
+	if maxAge, ok := cacheMaxAge(httpResp.Header.Get("Cache-Control")); ok {
+		ttl = maxAge
+	}
+	switch httpResp.StatusCode {
+	case http.StatusNotModified:
+		if etag == "" {
+			return nil, "", 0, newStatusError(httpResp)
+		}
+		return nil, etag, ttl, nil
+	case http.StatusOK:
+	default:
+		return nil, "", 0, newStatusError(httpResp)
+	}
+
+	var card models.AgentCard
+	if err := json.NewDecoder(httpResp.Body).Decode(&card); err != nil {
+		return nil, "", 0, fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	return &card, httpResp.Header.Get("ETag"), ttl, nil
+}
+
+// cacheMaxAge returns the max-age of a Cache-Control header, zero for no-cache and no-store
+func cacheMaxAge(header string) (time.Duration, bool) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0, true
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// jsonrpcEndpoint returns the URL card declares for JSON-RPC, or "" to keep the client's URL
+// when the card names none
+func jsonrpcEndpoint(card *models.AgentCard) (string, error) {
+	if card.PreferredTransport == "" || card.PreferredTransport == models.TransportJSONRPC {
+		return card.URL, nil
+	}
+	for _, iface := range card.AdditionalInterfaces {
+		if iface.Transport == models.TransportJSONRPC {
+			return iface.URL, nil
+		}
+	}
+	return "", fmt.Errorf("agent %s declares no %s interface", card.Name, models.TransportJSONRPC)
+}
+
+// endpoint returns the URL JSON-RPC requests are posted to: the one declared by the discovered
+// card, or the client's URL
+func (c *Client) endpoint() string {
+	d := &c.discovery
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.endpoint != "" {
+		return d.endpoint
+	}
+	return c.baseURL
+}
+
+// checkStreaming returns ErrStreamingUnsupported when the discovered card says the agent does
+// not stream. An expired card is revalidated first, falling back to it when that fails.
+func (c *Client) checkStreaming(ctx context.Context) error {
+	d := &c.discovery
+	d.mu.RLock()
+	card, fresh := d.card, c.clock.Now().Before(d.expires)
+	d.mu.RUnlock()
+	if card == nil {
+		return nil
+	}
+	if !fresh {
+		if refreshed, err := c.Discover(ctx); err == nil {
+			card = refreshed
+		}
+	}
+	if streaming := card.Capabilities.Streaming; streaming != nil && !*streaming {
+		return ErrStreamingUnsupported
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestDiscover(t *testing.T) {
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	streaming := false
+	card := models.AgentCard{Name: "Discovered", URL: ts.URL + "/rpc", Capabilities: models.AgentCapabilities{Streaming: &streaming}}
+	agent := server.NewA2AServer(card, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	})
+	mux.Handle("POST /rpc", agent)
+	var fetches, notModified int
+	mux.HandleFunc("GET /.well-known/agent-card", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		sw := &statusRecorder{ResponseWriter: w}
+		agent.ServeAgentCard(sw, r)
+		if sw.status == http.StatusNotModified {
+			notModified++
+		}
+	})
+
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := NewClient(ts.URL, WithClock(fake), WithTimeout(0), WithCardTTL(time.Minute))
+	params := models.MessageSendParams{
+		ID:      "discovered",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+	}
+
+	// The client only knows the host; requests go to the endpoint the card declares
+	if _, err := client.SendMessage(params); err == nil {
+		t.Fatal("Expected the request to fail before discovery")
+	}
+	found, err := client.Discover(context.Background())
+	if err != nil || found.Name != "Discovered" {
+		t.Fatalf("Expected the agent card, got %+v (%v)", found, err)
+	}
+	if _, err := client.SendMessage(params); err != nil {
+		t.Errorf("Expected the request to reach the declared endpoint, got %v", err)
+	}
+
+	// Streaming is refused per the card
+	events := make(chan interface{}, 10)
+	if err := client.SendMessageStreaming(params, events); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Errorf("Expected ErrStreamingUnsupported, got %v", err)
+	}
+
+	// The card is cached for its TTL, then revalidated with its ETag
+	client.Discover(context.Background())
+	if fetches != 1 {
+		t.Errorf("Expected the cached card within its TTL, got %d fetches", fetches)
+	}
+	fake.Advance(time.Minute)
+	if _, err := client.Discover(context.Background()); err != nil || fetches != 2 || notModified != 1 {
+		t.Errorf("Expected a 304 revalidation, got %d fetches, %d not modified (%v)", fetches, notModified, err)
+	}
+}
+
+func TestDiscover_AdditionalInterface(t *testing.T) {
+	card := &models.AgentCard{
+		Name:                 "Multi",
+		URL:                  "https://agent.example/grpc",
+		PreferredTransport:   models.TransportGRPC,
+		AdditionalInterfaces: []models.AgentInterface{{URL: "https://agent.example/jsonrpc", Transport: models.TransportJSONRPC}},
+	}
+	if endpoint, err := jsonrpcEndpoint(card); err != nil || endpoint != "https://agent.example/jsonrpc" {
+		t.Errorf("Expected the JSON-RPC interface, got %q (%v)", endpoint, err)
+	}
+	card.AdditionalInterfaces = nil
+	if _, err := jsonrpcEndpoint(card); err == nil {
+		t.Error("Expected an error for a card without a JSON-RPC interface")
+	}
+}
+
+// statusRecorder records the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
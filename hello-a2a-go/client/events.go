@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"a2a/models"
+)
+
+// StreamEvent is an event of a message/stream or tasks/resubscribe stream, decoded by its kind.
+// Exactly one of its fields is set.
+type StreamEvent struct {
+	Status   *models.TaskStatusUpdateEvent
+	Artifact *models.TaskArtifactUpdateEvent
+	Message  *models.Message
+	Task     *models.Task
+}
+
+// Kind returns the kind of the event: models.KindStatusUpdate, models.KindArtifactUpdate,
+// models.KindMessage or models.KindTask
+func (e StreamEvent) Kind() string {
+	switch {
+	case e.Status != nil:
+		return models.KindStatusUpdate
+	case e.Artifact != nil:
+		return models.KindArtifactUpdate
+	case e.Message != nil:
+		return models.KindMessage
+	case e.Task != nil:
+		return models.KindTask
+	}
+	return ""
+}
+
+// TaskID returns the ID of the task the event belongs to
+func (e StreamEvent) TaskID() string {
+	switch {
+	case e.Status != nil:
+		return e.Status.ID
+	case e.Artifact != nil:
+		return e.Artifact.ID
+	case e.Message != nil:
+		return e.Message.TaskID
+	case e.Task != nil:
+		return e.Task.ID
+	}
+	return ""
+}
+
+// Final reports whether the event is the last of its stream. A message or task answering a
+// stream is its only event.
+func (e StreamEvent) Final() bool {
+	switch {
+	case e.Status != nil:
+		return e.Status.Final != nil && *e.Status.Final
+	case e.Artifact != nil:
+		return e.Artifact.Final != nil && *e.Artifact.Final
+	}
+	return e.Message != nil || e.Task != nil
+}
+
+// State returns the state of the task the event reports, or "" for artifact updates and messages
+func (e StreamEvent) State() models.TaskState {
+	switch {
+	case e.Status != nil:
+		return e.Status.Status.State
+	case e.Task != nil:
+		return e.Task.Status.State
+	}
+	return ""
+}
+
+// Text returns the text of the event: of its status message, its artifact's text parts or its
+// message
+func (e StreamEvent) Text() string {
+	switch {
+	case e.Status != nil && e.Status.Status.Message != nil:
+		return e.Status.Status.Message.Text()
+	case e.Artifact != nil:
+		return models.Message{Parts: e.Artifact.Artifact.Parts}.Text()
+	case e.Message != nil:
+		return e.Message.Text()
+	case e.Task != nil && e.Task.Status.Message != nil:
+		return e.Task.Status.Message.Text()
+	}
+	return ""
+}
+
+// DecodeStreamEvent decodes event, as delivered by SendMessageStreamingContext or
+// ResubscribeTask, by its kind. Events without a kind, from agents predating it, are told apart
+// by their fields.
+func DecodeStreamEvent(event interface{}) (StreamEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return StreamEvent{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+	var fields struct {
+		Kind     string          `json:"kind"`
+		Artifact json.RawMessage `json:"artifact"`
+		Role     string          `json:"role"`
+		Final    *bool           `json:"final"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return StreamEvent{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+	kind := fields.Kind
+	if kind == "" {
+		switch {
+		case fields.Artifact != nil:
+			kind = models.KindArtifactUpdate
+		case fields.Role != "":
+			kind = models.KindMessage
+		case fields.Final != nil:
+			kind = models.KindStatusUpdate
+		default:
+			kind = models.KindTask
+		}
+	}
+
+	var decoded StreamEvent
+	switch kind {
+	case models.KindStatusUpdate:
+		decoded.Status = &models.TaskStatusUpdateEvent{}
+		err = models.DecodeJSON(data, decoded.Status)
+	case models.KindArtifactUpdate:
+		decoded.Artifact = &models.TaskArtifactUpdateEvent{}
+		err = models.DecodeJSON(data, decoded.Artifact)
+	case models.KindMessage:
+		decoded.Message = &models.Message{}
+		err = models.DecodeJSON(data, decoded.Message)
+	case models.KindTask:
+		decoded.Task = &models.Task{}
+		err = models.DecodeJSON(data, decoded.Task)
+	default:
+		return StreamEvent{}, fmt.Errorf("unknown event kind: %s", kind)
+	}
+	if err != nil {
+		return StreamEvent{}, fmt.Errorf("failed to decode %s event: %w", kind, err)
+	}
+	return decoded, nil
+}
+
+// SendMessageStreamTyped sends a message with message/stream like SendMessageStreamingContext,
+// delivering its events to events decoded by kind. Options are applied as by SendMessageTyped.
+func (c *Client) SendMessageStreamTyped(ctx context.Context, params models.MessageSendParams, events chan<- StreamEvent, opts ...SendOption) error {
+	if len(opts) > 0 {
+		var config models.MessageSendConfiguration
+		if params.Config != nil {
+			config = *params.Config
+		}
+		for _, opt := range opts {
+			opt(&config)
+		}
+		params.Config = &config
+	}
+	return decodeEvents(ctx, events, func(ctx context.Context, raw chan<- interface{}) error {
+		return c.SendMessageStreamingContext(ctx, params, raw)
+	})
+}
+
+// ResubscribeTaskTyped reattaches to the event stream of a task like ResubscribeTask, delivering
+// its events to events decoded by kind
+func (c *Client) ResubscribeTaskTyped(ctx context.Context, params models.TaskQueryParams, lastEventID string, events chan<- StreamEvent) error {
+	return decodeEvents(ctx, events, func(ctx context.Context, raw chan<- interface{}) error {
+		return c.ResubscribeTask(ctx, params, lastEventID, raw)
+	})
+}
+
+// decodeEvents runs stream, decoding the events it sends into events. An event that cannot be
+// decoded ends the stream with its error.
+func decodeEvents(ctx context.Context, events chan<- StreamEvent, stream func(context.Context, chan<- interface{}) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	raw := make(chan interface{})
+	decoded := make(chan error, 1)
+	go func() {
+		var decodeErr error
+		for event := range raw {
+			if decodeErr != nil {
+				continue
+			}
+			typed, err := DecodeStreamEvent(event)
+			if err != nil {
+				decodeErr = err
+				cancel(err)
+				continue
+			}
+			select {
+			case events <- typed:
+			case <-ctx.Done():
+			}
+		}
+		decoded <- decodeErr
+	}()
+	err := stream(ctx, raw)
+	close(raw)
+	if decodeErr := <-decoded; decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
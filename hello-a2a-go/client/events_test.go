@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestDecodeStreamEvent(t *testing.T) {
+	final := true
+	tests := []struct {
+		name  string
+		event interface{}
+		kind  string
+		text  string
+	}{
+		{"status", models.TaskStatusUpdateEvent{ID: "t1", Status: models.TaskStatus{State: models.TaskStateWorking,
+			Message: &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart("Thinking")}}}}, models.KindStatusUpdate, "Thinking"},
+		{"artifact", models.TaskArtifactUpdateEvent{ID: "t1", Artifact: models.Artifact{Parts: []models.Part{models.NewTextPart("Bonjour")}}},
+			models.KindArtifactUpdate, "Bonjour"},
+		{"message", models.Message{Role: "agent", TaskID: "t1", Parts: []models.Part{models.NewTextPart("pong")}}, models.KindMessage, "pong"},
+		{"task", models.Task{ID: "t1", Status: models.TaskStatus{State: models.TaskStateCompleted}}, models.KindTask, ""},
+		// Events of agents predating kinds, as decoded from JSON
+		{"untyped status", map[string]interface{}{"id": "t1", "status": map[string]interface{}{"state": "completed"}, "final": true},
+			models.KindStatusUpdate, ""},
+		{"untyped artifact", map[string]interface{}{"id": "t1", "artifact": map[string]interface{}{"parts": []interface{}{
+			map[string]interface{}{"kind": "text", "text": "Hi"}}}}, models.KindArtifactUpdate, "Hi"},
+	}
+	for _, tt := range tests {
+		event, err := DecodeStreamEvent(tt.event)
+		if err != nil {
+			t.Errorf("%s: failed to decode: %v", tt.name, err)
+			continue
+		}
+		if event.Kind() != tt.kind || event.TaskID() != "t1" || event.Text() != tt.text {
+			t.Errorf("%s: expected a %s event of t1 with text %q, got %s of %q with %q", tt.name, tt.kind, tt.text, event.Kind(), event.TaskID(), event.Text())
+		}
+	}
+
+	event, _ := DecodeStreamEvent(models.TaskStatusUpdateEvent{ID: "t1", Status: models.TaskStatus{State: models.TaskStateCompleted}, Final: &final})
+	if !event.Final() || event.State() != models.TaskStateCompleted {
+		t.Errorf("Expected a final completed event, got %+v", event.Status)
+	}
+	if _, err := DecodeStreamEvent(map[string]interface{}{"kind": "unknown"}); err == nil {
+		t.Error("Expected an unknown kind to fail decoding")
+	}
+}
+
+func TestSendMessageStreamTyped(t *testing.T) {
+	final := true
+	results := []interface{}{
+		models.TaskStatusUpdateEvent{ID: "t1", Status: models.TaskStatus{State: models.TaskStateWorking}},
+		models.TaskArtifactUpdateEvent{ID: "t1", Artifact: models.Artifact{Parts: []models.Part{models.NewTextPart("Bonjour")}}},
+		models.TaskStatusUpdateEvent{ID: "t1", Status: models.TaskStatus{State: models.TaskStateCompleted}, Final: &final},
+	}
+	var config *models.MessageSendConfiguration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params models.MessageSendParams `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		config = req.Params.Config
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, result := range results {
+			data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "result": result})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+	}))
+	defer server.Close()
+
+	events := make(chan StreamEvent, len(results))
+	params := models.MessageSendParams{ID: "t1", Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}}}
+	if err := NewClient(server.URL).SendMessageStreamTyped(context.Background(), params, events, HistoryLength(0)); err != nil {
+		t.Fatalf("Failed to stream: %v", err)
+	}
+	close(events)
+	var kinds []string
+	for event := range events {
+		kinds = append(kinds, event.Kind())
+	}
+	if len(kinds) != 3 || kinds[0] != models.KindStatusUpdate || kinds[1] != models.KindArtifactUpdate || kinds[2] != models.KindStatusUpdate {
+		t.Errorf("Expected status, artifact and status events, got %v", kinds)
+	}
+	if config == nil || config.HistoryLength == nil || *config.HistoryLength != 0 {
+		t.Errorf("Expected the send options to be applied, got %+v", config)
+	}
+}
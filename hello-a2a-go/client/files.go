@@ -0,0 +1,150 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"a2a/models"
+)
+
+// UploadFile streams r to the agent's file store as a file of task taskID and returns a FilePart
+// referencing it by URI, to send in a message instead of inline bytes. The agent must serve files
+// (see server.WithFileStore) and hold the task.
+func (c *Client) UploadFile(taskID, name, mimeType string, r io.Reader) (*models.FilePart, error) {
+	return c.UploadFileContext(context.Background(), taskID, name, mimeType, r)
+}
+
+// UploadFileContext is like UploadFile with a context (see SendMessageContext). Uploads are not
+// retried, as r cannot be read twice.
+func (c *Client) UploadFileContext(ctx context.Context, taskID, name, mimeType string, r io.Reader) (*models.FilePart, error) {
+	// Signing covers the body, so a signing client buffers it
+	var body []byte
+	if c.signingSecret != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		body, r = data, bytes.NewReader(data)
+	}
+
+	target := c.baseURL + "/v1/tasks/" + url.PathEscape(taskID) + "/files?name=" + url.QueryEscape(name)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", target, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", mimeType)
+	if err := c.prepareRequest(httpReq, body); err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
+
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusCreated {
+		return nil, newStatusError(httpResp)
+	}
+
+	var part models.FilePart
+	if err := json.NewDecoder(httpResp.Body).Decode(&part); err != nil {
+		return nil, fmt.Errorf("failed to decode uploaded file: %w", err)
+	}
+	return &part, nil
+}
+
+// UploadArtifact streams r to the agent's artifact store under the SHA-256 digest of its
+// contents and returns a FilePart referencing it by URI, to send in a message instead of inline
+// bytes. The agent must serve artifacts (see server.WithArtifactStore). r is read twice, to hash
+// it and to send it; content the agent already has is stored once.
+func (c *Client) UploadArtifact(name, mimeType string, r io.ReadSeeker) (*models.FilePart, error) {
+	return c.UploadArtifactContext(context.Background(), name, mimeType, r)
+}
+
+// UploadArtifactContext is like UploadArtifact with a context (see SendMessageContext). Uploads
+// are not retried.
+func (c *Client) UploadArtifactContext(ctx context.Context, name, mimeType string, r io.ReadSeeker) (*models.FilePart, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, fmt.Errorf("failed to hash artifact: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind artifact: %w", err)
+	}
+	// Signing covers the body, so a signing client buffers it
+	var body []byte
+	var content io.Reader = r
+	if c.signingSecret != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact: %w", err)
+		}
+		body, content = data, bytes.NewReader(data)
+	}
+
+	target := c.baseURL + "/artifacts/" + hex.EncodeToString(hash.Sum(nil))
+	if name != "" {
+		target += "?name=" + url.QueryEscape(name)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "PUT", target, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", mimeType)
+	if err := c.prepareRequest(httpReq, body); err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
+
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// 200 OK answers content the agent already had
+	if httpResp.StatusCode != http.StatusCreated && httpResp.StatusCode != http.StatusOK {
+		return nil, newStatusError(httpResp)
+	}
+
+	var part models.FilePart
+	if err := json.NewDecoder(httpResp.Body).Decode(&part); err != nil {
+		return nil, fmt.Errorf("failed to decode uploaded artifact: %w", err)
+	}
+	return &part, nil
+}
+
+// DownloadFile returns the contents of a file referenced by URI, such as an artifact the agent
+// moved to its file store, authenticating as for other requests. The caller reads and closes it.
+func (c *Client) DownloadFile(uri string) (io.ReadCloser, error) {
+	return c.DownloadFileContext(context.Background(), uri)
+}
+
+// DownloadFileContext is like DownloadFile with a context (see SendMessageContext); the client
+// timeout bounds reading the whole file
+func (c *Client) DownloadFileContext(ctx context.Context, uri string) (io.ReadCloser, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.prepareRequest(httpReq, nil); err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
+
+	httpResp, err := c.send(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		httpResp.Body.Close()
+		return nil, newStatusError(httpResp)
+	}
+	return httpResp.Body, nil
+}
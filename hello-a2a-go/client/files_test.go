@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestUploadDownloadFile(t *testing.T) {
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	store, err := server.NewDirFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := server.NewA2AServer(models.AgentCard{Name: "Files", URL: ts.URL}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}, server.WithFileStore(store, 0))
+	agent.RegisterRoutes(mux)
+
+	client := NewClient(ts.URL)
+	if _, err := NewClient(ts.URL + "/a2a").SendMessage(models.MessageSendParams{
+		ID:      "task-1",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+	}); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	contents := strings.Repeat("large file ", 1000)
+	part, err := client.UploadFile("task-1", "big.txt", "text/plain", strings.NewReader(contents))
+	if err != nil {
+		t.Fatalf("Failed to upload file: %v", err)
+	}
+	uri, ok := part.Content.(models.FileContentURI)
+	if !ok || part.FileName != "big.txt" || part.MimeType != "text/plain" {
+		t.Fatalf("Expected a file part referencing the upload, got %+v", part)
+	}
+
+	body, err := client.DownloadFile(uri.URI)
+	if err != nil {
+		t.Fatalf("Failed to download file: %v", err)
+	}
+	defer body.Close()
+	if got, _ := io.ReadAll(body); string(got) != contents {
+		t.Errorf("Expected the uploaded contents back, got %d bytes", len(got))
+	}
+
+	if _, err := client.DownloadFile(ts.URL + "/v1/tasks/task-1/files/00ff"); err == nil {
+		t.Error("Expected an error for an unknown file")
+	}
+}
+
+func TestUploadArtifact(t *testing.T) {
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	store, err := server.NewDirBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := server.NewA2AServer(models.AgentCard{Name: "Artifacts", URL: ts.URL}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		return task, nil
+	}, server.WithArtifactStore(store, 0))
+	agent.RegisterRoutes(mux)
+
+	client := NewClient(ts.URL, WithSigningSecret([]byte("secret")))
+	contents := strings.Repeat("image ", 1000)
+	var uris []string
+	// Uploading the same content twice yields the same URI
+	for i := 0; i < 2; i++ {
+		part, err := client.UploadArtifact("image.bin", "application/octet-stream", strings.NewReader(contents))
+		if err != nil {
+			t.Fatalf("Failed to upload artifact: %v", err)
+		}
+		uris = append(uris, part.Content.(models.FileContentURI).URI)
+	}
+	if uris[0] != uris[1] || !strings.HasPrefix(uris[0], ts.URL+"/artifacts/") {
+		t.Fatalf("Expected one artifact URI, got %q", uris)
+	}
+
+	body, err := client.DownloadFile(uris[0])
+	if err != nil {
+		t.Fatalf("Failed to download artifact: %v", err)
+	}
+	defer body.Close()
+	if got, _ := io.ReadAll(body); string(got) != contents {
+		t.Errorf("Expected the uploaded contents back, got %d bytes", len(got))
+	}
+}
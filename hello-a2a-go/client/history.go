@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+
+	"a2a/models"
+)
+
+// GetTaskHistory returns the messages exchanged for a task and the statuses it passed through,
+// with timestamps; HistoryLength limits both to the most recent entries
+func (c *Client) GetTaskHistory(params models.TaskQueryParams) (*models.TaskHistory, error) {
+	return c.GetTaskHistoryContext(context.Background(), params)
+}
+
+// GetTaskHistoryContext is like GetTaskHistory with a context (see SendMessageContext)
+func (c *Client) GetTaskHistoryContext(ctx context.Context, params models.TaskQueryParams) (*models.TaskHistory, error) {
+	return call[models.TaskHistory](ctx, c, newRequest(params.ID+"-history-request", "tasks/history", params), "task history")
+}
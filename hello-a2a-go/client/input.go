@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"a2a/models"
+)
+
+// defaultPollInterval is how often SendMessageUntilDone polls a task that is still working
+const defaultPollInterval = time.Second
+
+// ErrNoInput is returned by an InputFunc to stop SendMessageUntilDone without answering, leaving
+// the task awaiting input
+var ErrNoInput = errors.New("no input")
+
+// InputFunc answers a task that requires input, whose status message is usually the agent's
+// question, with the next message to send
+type InputFunc func(ctx context.Context, task *models.Task) (*models.Message, error)
+
+// SendMessageUntilDone sends params and carries the task through to a terminal state: while the
+// agent requires input it sends the answers of input, and while the task is still working it
+// polls it with tasks/get. It returns the finished task, or the task awaiting input with the
+// error if input fails.
+func (c *Client) SendMessageUntilDone(ctx context.Context, params models.MessageSendParams, input InputFunc) (*models.Task, error) {
+	task, err := c.sendMessageTask(ctx, params)
+	for err == nil && !task.Status.State.IsTerminal() {
+		if task.Status.State != models.TaskStateInputRequired {
+			select {
+			case <-c.clock.After(c.pollInterval):
+			case <-ctx.Done():
+				return task, ctx.Err()
+			}
+			task, err = c.GetTaskTyped(ctx, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: task.ID}})
+			continue
+		}
+
+		message, inputErr := input(ctx, task)
+		if inputErr != nil {
+			return task, fmt.Errorf("failed to answer task %s: %w", task.ID, inputErr)
+		}
+		// Answers continue the task and its conversation
+		message.TaskID, message.ContextID = task.ID, task.ContextID
+		params.ID, params.Message = task.ID, *message
+		task, err = c.sendMessageTask(ctx, params)
+	}
+	return task, err
+}
+
+// sendMessageTask sends params with message/send and returns the task answered
+func (c *Client) sendMessageTask(ctx context.Context, params models.MessageSendParams) (*models.Task, error) {
+	result, err := c.SendMessageTyped(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if result.Task == nil {
+		return nil, errMessageResult
+	}
+	return result.Task, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestSendMessageUntilDone(t *testing.T) {
+	agent := server.NewA2AServer(models.AgentCard{Name: "Weather"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		if text := message.Parts[0].(models.TextPart).Text; text != "weather" {
+			task.Status = models.TaskStatus{State: models.TaskStateCompleted, Message: &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Sunny in " + text}}}}
+			return task, nil
+		}
+		return server.InputRequired(task, "Which city?"), nil
+	})
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+
+	var prompts []string
+	answer := func(ctx context.Context, task *models.Task) (*models.Message, error) {
+		prompts = append(prompts, task.Status.Message.Parts[0].(models.TextPart).Text)
+		return &models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Paris"}}}, nil
+	}
+	params := models.MessageSendParams{
+		ID:      "forecast",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "weather"}}},
+	}
+
+	task, err := NewClient(ts.URL).SendMessageUntilDone(context.Background(), params, answer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if task.Status.State != models.TaskStateCompleted || task.Status.Message.Parts[0].(models.TextPart).Text != "Sunny in Paris" {
+		t.Errorf("Expected the answered task to complete, got %+v", task.Status)
+	}
+	if len(prompts) != 1 || prompts[0] != "Which city?" {
+		t.Errorf("Expected one prompt, got %q", prompts)
+	}
+
+	// Declining to answer leaves the task awaiting input
+	decline := func(ctx context.Context, task *models.Task) (*models.Message, error) { return nil, ErrNoInput }
+	params.ID = "declined"
+	task, err = NewClient(ts.URL).SendMessageUntilDone(context.Background(), params, decline)
+	if !errors.Is(err, ErrNoInput) || task == nil || task.Status.State != models.TaskStateInputRequired {
+		t.Errorf("Expected ErrNoInput with the waiting task, got %v, %+v", err, task)
+	}
+}
+
+func TestSendMessageUntilDone_Polls(t *testing.T) {
+	var gets int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		state := models.TaskStateWorking
+		if req.Method == "tasks/get" {
+			if gets++; gets == 2 {
+				state = models.TaskStateCompleted
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"slow","status":{"state":%q}}}`, state)
+	}))
+	defer ts.Close()
+
+	params := models.MessageSendParams{ID: "slow", Message: models.Message{Role: "user", Parts: []models.Part{}}}
+	task, err := NewClient(ts.URL, WithPollInterval(time.Millisecond)).SendMessageUntilDone(context.Background(), params, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if task.Status.State != models.TaskStateCompleted || gets != 2 {
+		t.Errorf("Expected the task to complete after polling twice, got %s after %d polls", task.Status.State, gets)
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"a2a/models"
+)
+
+// Request is a JSON-RPC call as seen by interceptors, which may rewrite its method, params or
+// ID before it is sent
+type Request struct {
+	models.JSONRPCRequest
+	// Header is added to every HTTP request of the call, including its retries and the
+	// resumes of its stream, over the client's own headers
+	Header http.Header
+	// Streaming reports whether the call streams its events, like message/stream and
+	// tasks/resubscribe
+	Streaming bool
+}
+
+// Invoker sends a JSON-RPC call and returns its response. A JSON-RPC error response is
+// returned as a response, not an error. A streaming call returns once its stream ends, with a
+// nil response; its events go to the call's event channel.
+type Invoker func(ctx context.Context, req *Request) (*Response, error)
+
+// Interceptor intercepts every JSON-RPC call and streaming request of a client, like a gRPC
+// interceptor: it may change ctx and req, e.g. to add headers or rewrite params, before
+// calling invoke to send the call, and inspect or replace the response and error invoke
+// returns, e.g. to record metrics. Retries happen inside invoke; batches sent with SendBatch
+// are not intercepted.
+type Interceptor func(ctx context.Context, req *Request, invoke Invoker) (*Response, error)
+
+// WithInterceptor adds interceptor to the client's calls. Interceptors run in the order they
+// are added, so the first one added sees each call first and its response last.
+func WithInterceptor(interceptor Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptor)
+	}
+}
+
+// intercept sends req to invoke through the client's interceptors
+func (c *Client) intercept(ctx context.Context, req *Request, invoke Invoker) (*Response, error) {
+	if c.transcript != nil {
+		invoke = c.transcript.wrap(c.clock, c.headers, invoke)
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], invoke
+		invoke = func(ctx context.Context, req *Request) (*Response, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return invoke(ctx, req)
+}
+
+// callHeaderKey is the context key of the headers interceptors set on a call
+type callHeaderKey struct{}
+
+// withCallHeader returns a copy of ctx whose HTTP requests carry header
+func withCallHeader(ctx context.Context, header http.Header) context.Context {
+	if len(header) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, callHeaderKey{}, header)
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	var mu sync.Mutex
+	var methods, auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		methods = append(methods, req.Method)
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"completed"},"final":true}}`+"\n\n")
+			return
+		}
+		completed(w)
+	}))
+	defer server.Close()
+
+	var order []string
+	var streaming []bool
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, req *Request, invoke Invoker) (*Response, error) {
+			order = append(order, name+" "+req.Method)
+			resp, err := invoke(ctx, req)
+			order = append(order, name+" done")
+			return resp, err
+		}
+	}
+	client := NewClient(server.URL, WithBearerToken("static"),
+		WithInterceptor(record("outer")),
+		WithInterceptor(func(ctx context.Context, req *Request, invoke Invoker) (*Response, error) {
+			// Rewrite the method and authenticate the call
+			streaming = append(streaming, req.Streaming)
+			if req.Method == "tasks/get" {
+				req.Method = "tasks/get-v2"
+			}
+			req.Header.Set("Authorization", "Bearer intercepted")
+			return invoke(ctx, req)
+		}),
+		WithInterceptor(record("inner")),
+	)
+
+	// Interceptors run in the order they were added and see the rewritten call
+	task, err := client.GetTaskTyped(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}})
+	if err != nil || task.ID != "1" {
+		t.Fatalf("Expected the task, got %v, %v", task, err)
+	}
+	want := []string{"outer tasks/get", "inner tasks/get-v2", "inner done", "outer done"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected interceptors to run as %q, got %q", want, order)
+	}
+
+	// Streaming requests are intercepted too
+	eventChan := make(chan interface{}, 10)
+	if err := client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, eventChan); err != nil {
+		t.Fatalf("Expected the stream to succeed, got %v", err)
+	}
+	if len(streaming) != 2 || streaming[0] || !streaming[1] {
+		t.Errorf("Expected a unary then a streaming call, got %v", streaming)
+	}
+	if len(methods) != 2 || methods[0] != "tasks/get-v2" || methods[1] != "message/stream" {
+		t.Errorf("Expected the rewritten method to be sent, got %q", methods)
+	}
+	for _, got := range auth {
+		if got != "Bearer intercepted" {
+			t.Errorf("Expected the interceptor's header to override the client's, got %q", got)
+		}
+	}
+
+	// An interceptor may answer without sending the call
+	client = NewClient(server.URL, WithInterceptor(func(ctx context.Context, req *Request, invoke Invoker) (*Response, error) {
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: &models.JSONRPCError{Code: -32001, Message: "cached miss"}}, nil
+	}))
+	if _, err := client.GetTaskTyped(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}}); models.ErrorCodeOf(err) != -32001 {
+		t.Errorf("Expected the interceptor's error response, got %v", err)
+	}
+	if len(methods) != 2 {
+		t.Errorf("Expected no request to reach the agent, got %q", methods)
+	}
+}
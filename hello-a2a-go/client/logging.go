@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// WithLogger logs the client's retries and resumed streams to logger, and each request at
+// debug level, instead of to slog.Default. Records carry the request_id sent with the request.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// requestIDKey is the context key of the request ID set with WithRequestID
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx whose requests carry id in their X-Request-ID header,
+// e.g. the ID of the request an agent is serving (see server.RequestIDFromContext), so that
+// one ID follows a call across agents. Requests without one get a new ID, kept across their
+// retries and resumes.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// ensureRequestID returns ctx and the request ID it carries, adding a new one if it has none
+func ensureRequestID(ctx context.Context) (context.Context, string) {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return ctx, id
+	}
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	id := hex.EncodeToString(raw)
+	return WithRequestID(ctx, id), id
+}
+
+// log returns the client's logger with the request ID carried by ctx
+func (c *Client) log(ctx context.Context) *slog.Logger {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		logger = logger.With(slog.String("request_id", id))
+	}
+	return logger
+}
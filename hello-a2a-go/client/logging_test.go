@@ -0,0 +1,66 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestRequestIDAndLogger(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	responses := []func(http.ResponseWriter){unavailable, completed}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get(models.HeaderRequestID))
+		respond := responses[min(len(ids), len(responses))-1]
+		mu.Unlock()
+		respond(w)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(server.URL, WithRetryPolicy(fastRetries), WithLogger(logger))
+	params := models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}}
+
+	// A retried request keeps its generated ID, and each record carries it
+	if _, err := client.GetTaskContext(context.Background(), params); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("Expected one request ID across both attempts, got %q", ids)
+	}
+	var msgs []string
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("Expected JSON log records, got %q: %v", line, err)
+		}
+		if entry["request_id"] != ids[0] {
+			t.Errorf("Expected request_id %s, got %v", ids[0], entry)
+		}
+		msgs = append(msgs, entry["msg"].(string))
+	}
+	if len(msgs) != 2 || msgs[0] != "retrying request" || msgs[1] != "request" {
+		t.Errorf("Expected a retry and a request record, got %q", msgs)
+	}
+
+	// An ID set on the context is sent as is
+	mu.Lock()
+	ids, responses = nil, []func(http.ResponseWriter){completed}
+	mu.Unlock()
+	if _, err := client.GetTaskContext(WithRequestID(context.Background(), "upstream-1"), params); err != nil {
+		t.Fatalf("Expected the request to succeed, got %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "upstream-1" {
+		t.Errorf("Expected the request ID from the context, got %q", ids)
+	}
+}
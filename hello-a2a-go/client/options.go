@@ -0,0 +1,146 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+	"a2a/trace"
+)
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithAPIKey authenticates every request with key in the X-API-Key header
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.headers.Set("X-API-Key", key)
+	}
+}
+
+// WithBearerToken authenticates every request with token in the Authorization header
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithExtensions requests the protocol extensions with uris on every request, in the
+// X-A2A-Extensions header. The agent activates those its card declares and lists them in the
+// same header of its responses.
+func WithExtensions(uris ...string) Option {
+	return func(c *Client) {
+		c.headers.Set(models.HeaderExtensions, strings.Join(uris, ", "))
+	}
+}
+
+// WithReplayProtection adds a fresh nonce and the current timestamp to every request, for
+// servers that reject replayed authenticated requests
+func WithReplayProtection() Option {
+	return func(c *Client) {
+		c.replayHeaders = true
+	}
+}
+
+// WithSigningSecret signs every request body, timestamp and a fresh nonce with secret, for
+// servers protected by server.RequireSignature
+func WithSigningSecret(secret []byte) Option {
+	return func(c *Client) {
+		c.signingSecret = secret
+	}
+}
+
+// WithTimeout bounds each request, including reading its response; zero disables the
+// timeout. Event streams are only bounded until the agent starts answering, and then read
+// until their final event or until the call's context is done. The default is 60 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxEventBytes bounds the size of each streamed event, so a misbehaving agent cannot
+// make the client buffer an unbounded event; larger events fail the stream with
+// ErrEventTooLarge. Zero means unlimited; the default is 10 MiB.
+func WithMaxEventBytes(n int) Option {
+	return func(c *Client) {
+		c.maxEventBytes = n
+	}
+}
+
+// WithStreamRetries sets how many times a stream that breaks before its final event is
+// resumed from the last event ID it received; zero disables resuming. The default is 3.
+func WithStreamRetries(n int) Option {
+	return func(c *Client) {
+		c.streamRetries = n
+	}
+}
+
+// WithPollInterval sets how often SendMessageUntilDone polls a task that is still working; the
+// default is one second
+func WithPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = d
+	}
+}
+
+// WithClock sets the clock that times out requests and stamps replay-protected requests; the
+// default is clock.Real. Tests pass a *clock.Fake.
+func WithClock(clk clock.Clock) Option {
+	return func(c *Client) {
+		c.clock = clk
+	}
+}
+
+// prepareRequest compresses the JSON body of httpReq when configured to and adds the configured
+// authentication headers, the headers interceptors set on the call and the trace context of its
+// context to httpReq carrying body
+func (c *Client) prepareRequest(httpReq *http.Request, body []byte) error {
+	body, err := c.compressBody(httpReq, body)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.headers {
+		httpReq.Header[key] = values
+	}
+	trace.InjectContext(httpReq.Context(), httpReq.Header)
+	if id, ok := httpReq.Context().Value(requestIDKey{}).(string); ok && id != "" {
+		httpReq.Header.Set(models.HeaderRequestID, id)
+	}
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token(httpReq.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get token: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	if header, ok := httpReq.Context().Value(callHeaderKey{}).(http.Header); ok {
+		for key, values := range header {
+			httpReq.Header[key] = values
+		}
+	}
+	if !c.replayHeaders && c.signingSecret == nil {
+		return nil
+	}
+
+	timestamp := strconv.FormatInt(c.clock.Now().Unix(), 10)
+	httpReq.Header.Set(models.HeaderTimestamp, timestamp)
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	nonce := hex.EncodeToString(raw)
+	httpReq.Header.Set(models.HeaderNonce, nonce)
+
+	if c.signingSecret != nil {
+		httpReq.Header.Set(models.HeaderSignature, models.SignRequest(c.signingSecret, httpReq.Method, models.SignedPath(httpReq), timestamp, nonce, body))
+	}
+	return nil
+}
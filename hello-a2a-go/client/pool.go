@@ -0,0 +1,347 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// ErrCircuitOpen is returned for calls to an agent whose circuit breaker is open after repeated
+// failures
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Circuit breaker states reported by Pool.Health
+const (
+	// CircuitClosed lets calls through
+	CircuitClosed = "closed"
+	// CircuitOpen rejects calls with ErrCircuitOpen until the cooldown elapses
+	CircuitOpen = "open"
+	// CircuitHalfOpen lets one trial call through, closing the circuit if it succeeds
+	CircuitHalfOpen = "half-open"
+)
+
+// Pool holds the clients of many agents, keyed by URL, for orchestrators calling downstream
+// agents. The clients share one HTTP transport and its connections. Calls through the pool wait
+// for the agent's rate limit, and an agent failing repeatedly is skipped until its circuit
+// breaker lets a trial call through.
+type Pool struct {
+	clientOpts []Option
+	transport  http.RoundTripper
+	clock      clock.Clock
+
+	// rate is the calls per second allowed to each agent, up to burst at once; zero is unlimited
+	rate  float64
+	burst int
+	// failureThreshold consecutive failures open an agent's circuit for cooldown; zero
+	// disables the breaker
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu     sync.Mutex
+	agents map[string]*poolAgent
+}
+
+// PoolOption configures a Pool
+type PoolOption func(*Pool)
+
+// WithClientOptions configures every client of the pool with opts. A TLS configuration set
+// with WithTLSConfig applies to the shared transport.
+func WithClientOptions(opts ...Option) PoolOption {
+	return func(p *Pool) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
+}
+
+// WithRateLimit allows each agent perSecond calls per second, up to burst at once; calls over
+// the limit wait for their turn
+func WithRateLimit(perSecond float64, burst int) PoolOption {
+	return func(p *Pool) {
+		p.rate, p.burst = perSecond, max(burst, 1)
+	}
+}
+
+// WithCircuitBreaker opens an agent's circuit after failures consecutive failed calls,
+// rejecting calls with ErrCircuitOpen for cooldown before letting a trial call through. Only
+// transport errors, timeouts and 5xx or 429 responses count as failures, not A2A errors
+// answered by a healthy agent. The default is 5 failures and 30 seconds; zero failures
+// disables the breaker.
+func WithCircuitBreaker(failures int, cooldown time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.failureThreshold, p.cooldown = failures, cooldown
+	}
+}
+
+// WithPoolClock sets the clock of the rate limits and circuit breakers, and of the pool's
+// clients; the default is clock.Real. Tests pass a *clock.Fake.
+func WithPoolClock(clk clock.Clock) PoolOption {
+	return func(p *Pool) {
+		p.clock = clk
+	}
+}
+
+// NewPool creates an empty pool; clients are created on first use
+func NewPool(opts ...PoolOption) *Pool {
+	p := &Pool{
+		clock:            clock.Real,
+		failureThreshold: 5,
+		cooldown:         30 * time.Second,
+		agents:           make(map[string]*poolAgent),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// A client built with the pool's options yields the transport, with any TLS configuration
+	template := NewClient("", p.clientOpts...)
+	p.transport = template.httpClient.Transport
+	if p.transport == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = 16
+		p.transport = transport
+	}
+	return p
+}
+
+// poolAgent is the client of one agent with its rate limit and circuit breaker
+type poolAgent struct {
+	client  *Client
+	limiter *rateLimiter
+	breaker *circuitBreaker
+}
+
+// agent returns the pooled agent of url, creating it on first use
+func (p *Pool) agent(url string) *poolAgent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a := p.agents[url]; a != nil {
+		return a
+	}
+	opts := append([]Option{WithClock(p.clock)}, p.clientOpts...)
+	c := NewClient(url, opts...)
+	// Agents on Unix sockets keep their own transport
+	if !strings.HasPrefix(url, unixScheme) {
+		c.httpClient.Transport = p.transport
+	}
+	a := &poolAgent{client: c, breaker: &circuitBreaker{threshold: p.failureThreshold, cooldown: p.cooldown}}
+	if p.rate > 0 {
+		a.limiter = &rateLimiter{rate: p.rate, burst: float64(p.burst), tokens: float64(p.burst), last: p.clock.Now()}
+	}
+	p.agents[url] = a
+	return a
+}
+
+// Client returns the client of the agent at url. Calls made on it directly bypass the pool's
+// rate limit and circuit breaker; use Do for those.
+func (p *Pool) Client(url string) *Client {
+	return p.agent(url).client
+}
+
+// Do calls f with the client of the agent at url once the agent's rate limit allows, unless
+// its circuit is open, recording the outcome in the agent's circuit breaker
+func (p *Pool) Do(ctx context.Context, url string, f func(ctx context.Context, c *Client) error) error {
+	a := p.agent(url)
+	if !a.breaker.allow(p.clock.Now()) {
+		return ErrCircuitOpen
+	}
+	if a.limiter != nil {
+		if err := a.limiter.wait(ctx, p.clock); err != nil {
+			a.breaker.cancel()
+			return err
+		}
+	}
+	err := f(ctx, a.client)
+	a.breaker.record(p.clock.Now(), ctx.Err() == nil && unhealthy(err))
+	return err
+}
+
+// SendMessage sends params to the agent at url through the pool (see Do)
+func (p *Pool) SendMessage(ctx context.Context, url string, params models.MessageSendParams) (*models.SendMessageResult, error) {
+	var result *models.SendMessageResult
+	err := p.Do(ctx, url, func(ctx context.Context, c *Client) error {
+		var err error
+		result, err = c.SendMessageTyped(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+// AgentResult is the answer of one agent to a message sent with FanOut
+type AgentResult struct {
+	URL    string
+	Result *models.SendMessageResult
+	Err    error
+}
+
+// FanOut sends params to the agents at urls concurrently through the pool and returns their
+// answers in the order of urls, with the errors of the agents that failed joined, each naming
+// its agent
+func (p *Pool) FanOut(ctx context.Context, urls []string, params models.MessageSendParams) ([]AgentResult, error) {
+	results := make([]AgentResult, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := p.SendMessage(ctx, url, params)
+			results[i] = AgentResult{URL: url, Result: result, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("agent %s: %w", r.URL, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// AgentHealth reports the circuit breaker of a pooled agent
+type AgentHealth struct {
+	URL string `json:"url"`
+	// State is CircuitClosed, CircuitOpen or CircuitHalfOpen
+	State string `json:"state"`
+	// Failures is the number of consecutive failed calls
+	Failures int `json:"failures"`
+}
+
+// Health reports the circuit breakers of the agents called through the pool
+func (p *Pool) Health() []AgentHealth {
+	p.mu.Lock()
+	urls := make([]string, 0, len(p.agents))
+	for url := range p.agents {
+		urls = append(urls, url)
+	}
+	p.mu.Unlock()
+
+	now := p.clock.Now()
+	health := make([]AgentHealth, 0, len(urls))
+	for _, url := range urls {
+		state, failures := p.agent(url).breaker.state(now)
+		health = append(health, AgentHealth{URL: url, State: state, Failures: failures})
+	}
+	slices.SortFunc(health, func(a, b AgentHealth) int { return strings.Compare(a.URL, b.URL) })
+	return health
+}
+
+// unhealthy reports whether a call failing with err counts against the agent's health
+func unhealthy(err error) bool {
+	var transport *transportError
+	var status *statusError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &transport), errors.Is(err, errRequestTimeout):
+		return true
+	case errors.As(err, &status):
+		return status.code >= 500 || status.code == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// circuitBreaker tracks the consecutive failures of an agent, opening after threshold of them
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	// trial is set while the one call let through a half-open circuit is in flight
+	trial bool
+}
+
+// allow reports whether a call may go through at now, claiming the trial call of a half-open
+// circuit
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// cancel releases the trial call of a half-open circuit that was not made
+func (b *circuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record records the outcome of a call at now, opening the circuit for the cooldown when the
+// failures reach the threshold
+func (b *circuitBreaker) record(now time.Time, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// state returns the state of the circuit at now and its consecutive failures
+func (b *circuitBreaker) state(now time.Time) (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.threshold <= 0 || b.failures < b.threshold:
+		return CircuitClosed, b.failures
+	case now.Before(b.openUntil):
+		return CircuitOpen, b.failures
+	}
+	return CircuitHalfOpen, b.failures
+}
+
+// rateLimiter is a token bucket refilled at rate tokens per second, holding up to burst
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, waiting on clk until one is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context, clk clock.Clock) error {
+	l.mu.Lock()
+	now := clk.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// Tokens go negative to reserve a place in line for waiting calls
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-clk.After(delay):
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return context.Cause(ctx)
+	}
+}
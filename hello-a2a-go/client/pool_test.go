@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// poolParams is a message sent to pooled agents
+var poolParams = models.MessageSendParams{
+	ID:      "fan",
+	Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+}
+
+// failingServer answers every request with status, counting the requests in calls
+func failingServer(status int, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestPool_FanOut(t *testing.T) {
+	var taskMethod, messageMethod string
+	completed := resultServer(t, &models.Task{ID: "fan", Status: models.TaskStatus{State: models.TaskStateCompleted}}, &taskMethod)
+	defer completed.Close()
+	reply := resultServer(t, &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}, &messageMethod)
+	defer reply.Close()
+	var calls atomic.Int32
+	broken := failingServer(http.StatusInternalServerError, &calls)
+	defer broken.Close()
+
+	pool := NewPool()
+	results, err := pool.FanOut(context.Background(), []string{completed.URL, broken.URL, reply.URL}, poolParams)
+	if err == nil || !strings.Contains(err.Error(), broken.URL) {
+		t.Errorf("Expected the broken agent's error, got %v", err)
+	}
+	if len(results) != 3 || results[0].Result.Task == nil || results[1].Err == nil || results[2].Result.Message == nil {
+		t.Errorf("Expected a task, an error and a message in order, got %+v", results)
+	}
+	if pool.Client(completed.URL).httpClient.Transport != pool.Client(reply.URL).httpClient.Transport {
+		t.Error("Expected the pooled clients to share a transport")
+	}
+}
+
+func TestPool_CircuitBreaker(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var calls atomic.Int32
+	broken := failingServer(http.StatusServiceUnavailable, &calls)
+	defer broken.Close()
+
+	pool := NewPool(WithPoolClock(fake), WithCircuitBreaker(2, time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := pool.SendMessage(context.Background(), broken.URL, poolParams); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected call %d to fail at the agent, got %v", i+1, err)
+		}
+	}
+	if _, err := pool.SendMessage(context.Background(), broken.URL, poolParams); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen after 2 failures, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected the open circuit to skip the agent, got %d calls", calls.Load())
+	}
+	if health := pool.Health(); len(health) != 1 || health[0].State != CircuitOpen || health[0].Failures != 2 {
+		t.Errorf("Expected an open circuit, got %+v", health)
+	}
+
+	// After the cooldown a trial call goes through, and its failure reopens the circuit
+	fake.Advance(time.Minute)
+	if health := pool.Health(); health[0].State != CircuitHalfOpen {
+		t.Errorf("Expected a half-open circuit, got %+v", health)
+	}
+	pool.SendMessage(context.Background(), broken.URL, poolParams)
+	if _, err := pool.SendMessage(context.Background(), broken.URL, poolParams); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 3 {
+		t.Errorf("Expected one trial call before reopening, got %v after %d calls", err, calls.Load())
+	}
+
+	// A2A errors from a healthy agent do not count as failures
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":"1","error":{"code":-32001,"message":"Task not found"}}`))
+	}))
+	defer rejecting.Close()
+	for i := 0; i < 3; i++ {
+		if _, err := pool.SendMessage(context.Background(), rejecting.URL, poolParams); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected A2A errors to keep the circuit closed, got %v", err)
+		}
+	}
+}
+
+func TestPool_RateLimit(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var method string
+	agent := resultServer(t, &models.Task{ID: "fan", Status: models.TaskStatus{State: models.TaskStateCompleted}}, &method)
+	defer agent.Close()
+
+	pool := NewPool(WithPoolClock(fake), WithRateLimit(1, 1), WithClientOptions(WithTimeout(0)))
+	if _, err := pool.SendMessage(context.Background(), agent.URL, poolParams); err != nil {
+		t.Fatalf("Expected the first call within the burst, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.SendMessage(context.Background(), agent.URL, poolParams)
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the second call to wait for the rate limit, got %v", err)
+	default:
+	}
+	fake.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the second call after a second, got %v", err)
+	}
+
+	// A call canceled while waiting gives its place back
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.Do(ctx, agent.URL, func(context.Context, *Client) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled call to fail, got %v", err)
+	}
+}
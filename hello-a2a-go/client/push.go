@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// maxPushNotificationBytes bounds the body of a push notification read by ParsePushNotification
+const maxPushNotificationBytes = 10 << 20
+
+// ErrInvalidPushNotification is returned when a push notification is unsigned, stale or
+// carries a signature that does not match its token
+var ErrInvalidPushNotification = errors.New("invalid push notification")
+
+// SetPushNotificationConfig registers a webhook to receive the updates of an existing task
+// (A2A v0.3.0 compliant). A token in the config lets the webhook verify the agent's signature
+// with ParsePushNotification.
+func (c *Client) SetPushNotificationConfig(params models.TaskPushNotificationConfig) (*models.TaskPushNotificationConfig, error) {
+	return c.SetPushNotificationConfigContext(context.Background(), params)
+}
+
+// SetPushNotificationConfigContext is like SetPushNotificationConfig with a context (see
+// SendMessageContext)
+func (c *Client) SetPushNotificationConfigContext(ctx context.Context, params models.TaskPushNotificationConfig) (*models.TaskPushNotificationConfig, error) {
+	return c.pushNotificationConfig(ctx, "tasks/pushNotificationConfig/set", params.ID+"-push-set-request", params)
+}
+
+// GetPushNotificationConfig returns the webhook registered for a task (A2A v0.3.0 compliant)
+func (c *Client) GetPushNotificationConfig(params models.TaskIDParams) (*models.TaskPushNotificationConfig, error) {
+	return c.GetPushNotificationConfigContext(context.Background(), params)
+}
+
+// GetPushNotificationConfigContext is like GetPushNotificationConfig with a context (see
+// SendMessageContext)
+func (c *Client) GetPushNotificationConfigContext(ctx context.Context, params models.TaskIDParams) (*models.TaskPushNotificationConfig, error) {
+	return c.pushNotificationConfig(ctx, "tasks/pushNotificationConfig/get", params.ID+"-push-get-request", params)
+}
+
+// pushNotificationConfig calls a push notification config method returning the task's config
+func (c *Client) pushNotificationConfig(ctx context.Context, method, id string, params interface{}) (*models.TaskPushNotificationConfig, error) {
+	return call[models.TaskPushNotificationConfig](ctx, c, newRequest(id, method, params), "push notification config")
+}
+
+// ParsePushNotification verifies a push notification received by a webhook registered with
+// token, rejecting it unless its signature matches and its timestamp is within window of now,
+// and returns its event: a models.TaskStatusUpdateEvent or a models.TaskArtifactUpdateEvent
+func ParsePushNotification(r *http.Request, token string, window time.Duration) (interface{}, error) {
+	return parsePushNotification(r, token, window, clock.Real)
+}
+
+// ParsePushNotification is like the ParsePushNotification function, checking timestamps
+// against the client's clock (see WithClock)
+func (c *Client) ParsePushNotification(r *http.Request, token string, window time.Duration) (interface{}, error) {
+	return parsePushNotification(r, token, window, c.clock)
+}
+
+// parsePushNotification implements ParsePushNotification, reading the time from c
+func parsePushNotification(r *http.Request, token string, window time.Duration, c clock.Clock) (interface{}, error) {
+	timestamp := r.Header.Get(models.HeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing or invalid timestamp", ErrInvalidPushNotification)
+	}
+	if skew := c.Now().Sub(time.Unix(seconds, 0)); skew > window || skew < -window {
+		return nil, fmt.Errorf("%w: stale timestamp", ErrInvalidPushNotification)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushNotificationBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read push notification: %w", err)
+	}
+	if !models.VerifyRequestSignature([]byte(token), r.Method, models.SignedPath(r), timestamp, "", body, r.Header.Get(models.HeaderSignature)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidPushNotification)
+	}
+
+	var kind struct {
+		Artifact json.RawMessage `json:"artifact"`
+	}
+	if err := json.Unmarshal(body, &kind); err != nil {
+		return nil, fmt.Errorf("failed to decode push notification: %w", err)
+	}
+	if kind.Artifact != nil {
+		var event models.TaskArtifactUpdateEvent
+		if err := models.DecodeJSON(body, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode artifact update: %w", err)
+		}
+		return event, nil
+	}
+	var event models.TaskStatusUpdateEvent
+	if err := models.DecodeJSON(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode status update: %w", err)
+	}
+	return event, nil
+}
+
+// PushNotificationHandler returns a webhook handler that passes each verified push notification
+// event to handle (see ParsePushNotification) and rejects the rest with 401 Unauthorized
+func PushNotificationHandler(token string, window time.Duration, handle func(event interface{})) http.Handler {
+	return pushNotificationHandler(token, window, clock.Real, handle)
+}
+
+// PushNotificationHandler is like the PushNotificationHandler function, checking timestamps
+// against the client's clock (see WithClock)
+func (c *Client) PushNotificationHandler(token string, window time.Duration, handle func(event interface{})) http.Handler {
+	return pushNotificationHandler(token, window, c.clock, handle)
+}
+
+// pushNotificationHandler implements PushNotificationHandler, reading the time from c
+func pushNotificationHandler(token string, window time.Duration, c clock.Clock, handle func(event interface{})) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := parsePushNotification(r, token, window, c)
+		if errors.Is(err, ErrInvalidPushNotification) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handle(event)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestPushNotificationConfig(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		methods = append(methods, req.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{JSONRPC: "2.0"},
+			Result: models.TaskPushNotificationConfig{
+				ID:                     "123",
+				PushNotificationConfig: models.PushNotificationConfig{URL: "https://example.com/hook"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	config, err := client.SetPushNotificationConfig(models.TaskPushNotificationConfig{
+		ID:                     "123",
+		PushNotificationConfig: models.PushNotificationConfig{URL: "https://example.com/hook"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ID != "123" || config.PushNotificationConfig.URL != "https://example.com/hook" {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if _, err := client.GetPushNotificationConfig(models.TaskIDParams{ID: "123"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(methods, ",") != "tasks/pushNotificationConfig/set,tasks/pushNotificationConfig/get" {
+		t.Errorf("unexpected methods %v", methods)
+	}
+}
+
+// pushRequest builds a push notification of body signed with token at time at
+func pushRequest(token, body string, at time.Time) *http.Request {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	r := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	r.Header.Set(models.HeaderTimestamp, timestamp)
+	r.Header.Set(models.HeaderSignature, models.SignRequest([]byte(token), "POST", "/hook", timestamp, "", []byte(body)))
+	return r
+}
+
+func TestParsePushNotification(t *testing.T) {
+	status := `{"id":"123","status":{"state":"completed"},"final":true}`
+	event, err := ParsePushNotification(pushRequest("secret", status, time.Now()), "secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update, ok := event.(models.TaskStatusUpdateEvent); !ok || update.Status.State != models.TaskStateCompleted {
+		t.Errorf("expected a completed status update, got %#v", event)
+	}
+
+	artifact := `{"id":"123","artifact":{"parts":[{"kind":"text","text":"done"}]}}`
+	event, err = ParsePushNotification(pushRequest("secret", artifact, time.Now()), "secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update, ok := event.(models.TaskArtifactUpdateEvent); !ok || len(update.Artifact.Parts) != 1 {
+		t.Errorf("expected an artifact update, got %#v", event)
+	}
+
+	for name, r := range map[string]*http.Request{
+		"wrong token": pushRequest("other", status, time.Now()),
+		"stale":       pushRequest("secret", status, time.Now().Add(-time.Hour)),
+		"unsigned":    httptest.NewRequest("POST", "/hook", strings.NewReader(status)),
+	} {
+		if _, err := ParsePushNotification(r, "secret", time.Minute); !errors.Is(err, ErrInvalidPushNotification) {
+			t.Errorf("%s: expected ErrInvalidPushNotification, got %v", name, err)
+		}
+	}
+
+	// A client checks timestamps against its own clock
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewClient("http://agent.example", WithClock(fake))
+	if _, err := c.ParsePushNotification(pushRequest("secret", status, fake.Now()), "secret", time.Minute); err != nil {
+		t.Errorf("expected a notification fresh by the client clock, got %v", err)
+	}
+	if _, err := c.ParsePushNotification(pushRequest("secret", status, time.Now()), "secret", time.Minute); !errors.Is(err, ErrInvalidPushNotification) {
+		t.Errorf("expected a notification stale by the client clock, got %v", err)
+	}
+
+	var received []interface{}
+	handler := PushNotificationHandler("secret", time.Minute, func(event interface{}) { received = append(received, event) })
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, pushRequest("other", status, time.Now()))
+	if w.Code != http.StatusUnauthorized || len(received) != 0 {
+		t.Errorf("expected a forged notification to be rejected, got %d with %d events", w.Code, len(received))
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, pushRequest("secret", status, time.Now()))
+	if w.Code != http.StatusNoContent || len(received) != 1 {
+		t.Errorf("expected the notification to be accepted, got %d with %d events", w.Code, len(received))
+	}
+}
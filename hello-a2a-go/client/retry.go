@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"a2a/models"
+)
+
+// RetryPolicy configures how requests failing with transient errors are retried. The zero
+// value makes a single attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps each wait, including waits asked for with Retry-After
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry; values below 1 mean 2
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction of it, between 0 and 1, so that
+	// clients failing together do not retry together
+	Jitter float64
+	// RetryableStatusCodes are the HTTP statuses that are retried, honoring Retry-After
+	RetryableStatusCodes []int
+	// RetryableErrorCodes are the JSON-RPC error codes that are retried
+	RetryableErrorCodes []models.ErrorCode
+}
+
+// DefaultRetryPolicy makes up to 3 attempts with backoff from half a second, retrying network
+// errors, HTTP 429, 502, 503 and 504, and agents whose task queue is full or that rate limit
+// the caller. Internal errors are not retried, as the agent may have run the request already;
+// add ErrorCodeInternalError to RetryableErrorCodes to retry them too.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       500 * time.Millisecond,
+		MaxBackoff:           10 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryableErrorCodes:  []models.ErrorCode{models.ErrorCodeServerBusy, models.ErrorCodeRateLimited},
+	}
+}
+
+// WithRetryPolicy retries requests, and connecting and resuming event streams, according to
+// policy. Requests are not retried by default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// noRetryKey is the context key marking calls that must not be retried
+type noRetryKey struct{}
+
+// WithoutRetries returns a context for calls that make a single attempt whatever the client's
+// retry policy, e.g. for requests that must not run twice
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// statusError is returned for a response with an unexpected HTTP status
+type statusError struct {
+	code int
+	// retryAfter is the wait asked for by the Retry-After header, if any
+	retryAfter time.Duration
+	// rpcErr is the JSON-RPC error in the response body, if any, such as the rate limit error
+	// of an HTTP 429 response
+	rpcErr *models.A2AError
+}
+
+func (e *statusError) Error() string {
+	if e.rpcErr != nil {
+		return fmt.Sprintf("unexpected status code: %d: %s", e.code, e.rpcErr.Message)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
+// Unwrap returns the JSON-RPC error of the response, so models.ErrorCodeOf finds its code
+func (e *statusError) Unwrap() error {
+	if e.rpcErr == nil {
+		return nil
+	}
+	return e.rpcErr
+}
+
+// maxErrorBodyBytes bounds the body of an unexpected status read for a JSON-RPC error
+const maxErrorBodyBytes = 64 << 10
+
+// newStatusError returns the error of resp's unexpected status
+func newStatusError(resp *http.Response) *statusError {
+	err := &statusError{code: resp.StatusCode}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		err.retryAfter = time.Duration(seconds) * time.Second
+	}
+	var body models.JSONRPCResponse
+	if json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodyBytes)).Decode(&body) == nil && body.Error != nil {
+		err.rpcErr = models.ErrorFromJSONRPC(body.Error)
+	}
+	return err
+}
+
+// transportError is returned when a request could not be sent or its response not received
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+// sendError wraps an error of Client.send, marking network failures as transient; timeouts
+// and canceled contexts are not
+func sendError(ctx context.Context, err error) error {
+	if ctx.Err() != nil || errors.Is(err, errRequestTimeout) {
+		return err
+	}
+	return &transportError{err: err}
+}
+
+// retryable reports whether the policy retries a request failing with err
+func (p RetryPolicy) retryable(err error) bool {
+	var transport *transportError
+	var status *statusError
+	var a2aErr *models.A2AError
+	switch {
+	case errors.As(err, &transport):
+		return true
+	case errors.As(err, &status):
+		return slices.Contains(p.RetryableStatusCodes, status.code)
+	case errors.As(err, &a2aErr):
+		return slices.Contains(p.RetryableErrorCodes, a2aErr.Code)
+	}
+	return false
+}
+
+// backoff returns the wait before retry n, counting from 1, jittered with random, which
+// returns a number in [0, 1)
+func (p RetryPolicy) backoff(n int, random func() float64) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(n-1))
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*random()-1)
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	return time.Duration(delay)
+}
+
+// delay returns the wait before retry n of a request that failed with err, preferring a wait
+// asked for by the server
+func (p RetryPolicy) delay(n int, err error, random func() float64) time.Duration {
+	var status *statusError
+	if errors.As(err, &status) && status.retryAfter > 0 {
+		if p.MaxBackoff > 0 && status.retryAfter > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return status.retryAfter
+	}
+	return p.backoff(n, random)
+}
+
+// policy returns the retry policy of a call with ctx
+func (c *Client) policy(ctx context.Context) RetryPolicy {
+	if ctx.Value(noRetryKey{}) != nil {
+		return RetryPolicy{}
+	}
+	return c.retryPolicy
+}
+
+// retry calls attempt until it succeeds, fails with an error the policy does not retry or
+// the policy's attempts run out, waiting between attempts
+func (c *Client) retry(ctx context.Context, attempt func() error) error {
+	policy := c.policy(ctx)
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+		delay := policy.delay(n, err, c.random)
+		c.log(ctx).Warn("retrying request", slog.Int("attempt", n), slog.Duration("delay", delay), slog.Any("error", err))
+		select {
+		case <-c.clock.After(delay):
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
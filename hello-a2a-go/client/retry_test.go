@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// fastRetries retries quickly, without jitter
+var fastRetries = RetryPolicy{
+	MaxAttempts:          3,
+	InitialBackoff:       time.Millisecond,
+	RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	RetryableErrorCodes:  []models.ErrorCode{models.ErrorCodeInternalError},
+}
+
+// flakyServer answers each request with the next of responses, repeating the last
+func flakyServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		responses[min(n, len(responses))-1](w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func unavailable(w http.ResponseWriter) {
+	http.Error(w, "unavailable", http.StatusServiceUnavailable)
+}
+
+func badRequest(w http.ResponseWriter) {
+	http.Error(w, "bad request", http.StatusBadRequest)
+}
+
+func internalError(w http.ResponseWriter) {
+	fmt.Fprint(w, `{"jsonrpc":"2.0","id":"1","error":{"code":-32603,"message":"model unavailable"}}`)
+}
+
+func completed(w http.ResponseWriter) {
+	fmt.Fprint(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"completed"}}}`)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		ctx       context.Context
+		wantCalls int32
+		wantErr   bool
+	}{
+		{name: "unavailable", responses: []func(http.ResponseWriter){unavailable, unavailable, completed}, wantCalls: 3},
+		{name: "internal error", responses: []func(http.ResponseWriter){internalError, completed}, wantCalls: 2},
+		{name: "exhausted", responses: []func(http.ResponseWriter){unavailable}, wantCalls: 3, wantErr: true},
+		{name: "not retryable", responses: []func(http.ResponseWriter){badRequest, completed}, wantCalls: 1, wantErr: true},
+		{name: "disabled per call", responses: []func(http.ResponseWriter){unavailable, completed}, ctx: WithoutRetries(context.Background()), wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(t, tt.responses...)
+			client := NewClient(server.URL, WithRetryPolicy(fastRetries))
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			task, err := client.GetTaskContext(ctx, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}})
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, got)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %t, got %v", tt.wantErr, err)
+			}
+			if err == nil && task.Result.(*models.Task).Status.State != models.TaskStateCompleted {
+				t.Errorf("Expected the completed task, got %+v", task.Result)
+			}
+		})
+	}
+
+	// A JSON-RPC error is returned once the attempts run out
+	server, _ := flakyServer(t, internalError)
+	_, err := NewClient(server.URL, WithRetryPolicy(fastRetries)).GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}})
+	if models.ErrorCodeOf(err) != models.ErrorCodeInternalError {
+		t.Errorf("Expected the internal error, got %v", err)
+	}
+
+	// The default policy leaves internal errors to the caller, as the agent may have run the request
+	server, calls := flakyServer(t, internalError, completed)
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	if _, err := NewClient(server.URL, WithRetryPolicy(policy)).GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}}); models.ErrorCodeOf(err) != models.ErrorCodeInternalError || atomic.LoadInt32(calls) != 1 {
+		t.Errorf("Expected the internal error without retries, got %d attempts (%v)", atomic.LoadInt32(calls), err)
+	}
+
+	// Without a policy, a single attempt is made
+	server, calls = flakyServer(t, unavailable, completed)
+	if _, err := NewClient(server.URL).GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}}); err == nil || atomic.LoadInt32(calls) != 1 {
+		t.Errorf("Expected one failed attempt by default, got %d attempts (%v)", atomic.LoadInt32(calls), err)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2, Jitter: 0.5}
+	low, high := func() float64 { return 0 }, func() float64 { return 0.999999 }
+
+	tests := []struct {
+		n      int
+		random func() float64
+		err    error
+		want   time.Duration
+	}{
+		{n: 1, random: low, want: 500 * time.Millisecond},
+		{n: 2, random: low, want: time.Second},
+		{n: 2, random: high, want: 3 * time.Second},
+		{n: 5, random: low, want: 5 * time.Second},
+		{n: 1, random: low, err: &statusError{code: 503, retryAfter: 2 * time.Second}, want: 2 * time.Second},
+		{n: 1, random: low, err: &statusError{code: 503, retryAfter: time.Minute}, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.delay(tt.n, tt.err, tt.random); got.Round(time.Millisecond) != tt.want {
+			t.Errorf("delay(%d, %v) = %v, want %v", tt.n, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSendMessageStreaming_RetriesConnect(t *testing.T) {
+	server, calls := flakyServer(t, unavailable, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: s/1\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"completed\"},\"final\":true}}\n\n")
+	})
+	client := NewClient(server.URL, WithRetryPolicy(fastRetries))
+
+	events := make(chan interface{}, 4)
+	if err := client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, events); err != nil {
+		t.Fatalf("Expected the stream to connect on the second attempt, got %v", err)
+	}
+	if atomic.LoadInt32(calls) != 2 || len(events) != 1 {
+		t.Errorf("Expected 2 attempts and 1 event, got %d attempts and %d events", atomic.LoadInt32(calls), len(events))
+	}
+}
+
+func TestStatusErrorCarriesJSONRPCError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":"1","error":{"code":-32031,"message":"Rate limit exceeded, retry after 3s"}}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).GetTaskContext(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "t1"}})
+	if code := models.ErrorCodeOf(err); code != models.ErrorCodeRateLimited {
+		t.Errorf("Expected the rate limit error code, got %d (%v)", code, err)
+	}
+	var status *statusError
+	if !errors.As(err, &status) || status.code != http.StatusTooManyRequests || status.retryAfter != 3*time.Second {
+		t.Errorf("Expected a 429 status error waiting 3s, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"a2a/models"
+)
+
+// Session is a conversation with an agent. The first message sent through it starts a
+// conversation whose contextId the agent assigns; every later message carries that contextId, so
+// the agent sees them as one conversation. A Session is safe for concurrent use.
+type Session struct {
+	client *Client
+
+	mu        sync.Mutex
+	contextID string
+}
+
+// NewSession starts a conversation with the agent
+func (c *Client) NewSession() *Session {
+	return &Session{client: c}
+}
+
+// ResumeSession continues the conversation contextID, e.g. one a previous process started
+func (c *Client) ResumeSession(contextID string) *Session {
+	return &Session{client: c, contextID: contextID}
+}
+
+// ContextID returns the conversation's contextId, or "" before the agent has assigned one
+func (s *Session) ContextID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contextID
+}
+
+// Send sends message with message/send like SendMessageTyped, within the conversation. Messages
+// naming no task start a new task in the conversation.
+func (s *Session) Send(ctx context.Context, message models.Message) (*models.SendMessageResult, error) {
+	result, err := s.client.SendMessageTyped(ctx, s.params(message))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case result.Task != nil:
+		s.join(result.Task.ContextID)
+	case result.Message != nil:
+		s.join(result.Message.ContextID)
+	}
+	return result, nil
+}
+
+// Stream sends message with message/stream like SendMessageStreamingContext, within the
+// conversation. A new session learns its contextId from the streamed task once the stream ends.
+func (s *Session) Stream(ctx context.Context, message models.Message, events chan<- interface{}) error {
+	relay := make(chan interface{})
+	taskID := make(chan string, 1)
+	go func() {
+		var id string
+		for event := range relay {
+			if fields, ok := event.(map[string]interface{}); ok && id == "" {
+				id, _ = fields["id"].(string)
+			}
+			events <- event
+		}
+		taskID <- id
+	}()
+	err := s.client.SendMessageStreamingContext(ctx, s.params(message), relay)
+	close(relay)
+	id := <-taskID
+	if err != nil || s.ContextID() != "" || id == "" {
+		return err
+	}
+	task, err := s.client.GetTaskTyped(ctx, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: id}})
+	if err != nil {
+		return err
+	}
+	s.join(task.ContextID)
+	return nil
+}
+
+// History returns the tasks and messages of the conversation with contexts/get
+func (s *Session) History(ctx context.Context) (*models.Conversation, error) {
+	contextID := s.ContextID()
+	return call[models.Conversation](ctx, s.client, newRequest(contextID+"-context-request", "contexts/get", models.ContextQueryParams{ContextID: contextID}), "conversation")
+}
+
+// params returns the message/send params of message within the conversation
+func (s *Session) params(message models.Message) models.MessageSendParams {
+	if message.ContextID == "" {
+		message.ContextID = s.ContextID()
+	}
+	return models.MessageSendParams{ID: message.TaskID, Message: message}
+}
+
+// join adopts contextID as the conversation's contextId unless it already has one
+func (s *Session) join(contextID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.contextID == "" {
+		s.contextID = contextID
+	}
+}
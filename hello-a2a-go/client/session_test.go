@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// userText returns a user message holding text
+func userText(text string) models.Message {
+	return models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}
+}
+
+func TestSession(t *testing.T) {
+	agent := server.NewA2AServer(models.AgentCard{Name: "Echo"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status = models.TaskStatus{State: models.TaskStateCompleted, Message: &models.Message{Role: "agent", Parts: message.Parts}}
+		return task, nil
+	})
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+	c := NewClient(ts.URL)
+	ctx := context.Background()
+
+	session := c.NewSession()
+	first, err := session.Send(ctx, userText("Hello"))
+	if err != nil || first.Task == nil || first.Task.ContextID == "" || session.ContextID() != first.Task.ContextID {
+		t.Fatalf("Expected the session to adopt the agent's contextId, got %+v (%v)", first, err)
+	}
+	second, err := session.Send(ctx, userText("Again"))
+	if err != nil || second.Task.ContextID != session.ContextID() || second.Task.ID == first.Task.ID {
+		t.Fatalf("Expected a new task in the same conversation, got %+v (%v)", second, err)
+	}
+	events := make(chan interface{}, 16)
+	if err := session.Stream(ctx, userText("Streamed"), events); err != nil {
+		t.Fatalf("Failed to stream: %v", err)
+	}
+
+	history, err := session.History(ctx)
+	if err != nil || len(history.Tasks) != 3 || history.ContextID != session.ContextID() {
+		t.Fatalf("Expected the 3 tasks of the conversation, got %+v (%v)", history, err)
+	}
+	var texts []string
+	for _, message := range history.Messages {
+		if message.Role == "user" {
+			texts = append(texts, message.Parts[0].(models.TextPart).Text)
+		}
+	}
+	if len(texts) != 3 || texts[0] != "Hello" || texts[1] != "Again" || texts[2] != "Streamed" {
+		t.Errorf("Expected the user's messages in order, got %q", texts)
+	}
+
+	// A session starting with a stream learns its context from the streamed task
+	streamed := c.NewSession()
+	if err := streamed.Stream(ctx, userText("Hi"), make(chan interface{}, 16)); err != nil || streamed.ContextID() == "" || streamed.ContextID() == session.ContextID() {
+		t.Errorf("Expected a new conversation, got %q (%v)", streamed.ContextID(), err)
+	}
+	if _, err := c.ResumeSession("unknown").History(ctx); models.ErrorCodeOf(err) != models.ErrorCodeTaskNotFound {
+		t.Errorf("Expected an unknown context to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// defaultStreamRetries is how many times a broken event stream is resumed
+const defaultStreamRetries = 3
+
+// defaultRetryDelay is the wait before resuming a stream when the server sets no retry delay
+const defaultRetryDelay = time.Second
+
+// sseEvent is one event read from a stream
+type sseEvent struct {
+	id   string
+	data []byte
+}
+
+// sseReader reads Server-Sent Events. Lines holding a bare JSON value are read as events
+// without an ID, for agents that stream one JSON value per line such as stdio agents.
+type sseReader struct {
+	scanner  *bufio.Scanner
+	maxBytes int
+	// lastID is the ID of the last event read, sent as Last-Event-ID when resuming
+	lastID string
+	// retry is the reconnection delay requested by the server, if any
+	retry time.Duration
+}
+
+// newSSEReader reads events from r, bounding each to maxBytes when positive
+func newSSEReader(r io.Reader, maxBytes int) *sseReader {
+	scanner := bufio.NewScanner(r)
+	maxLine := math.MaxInt32
+	if maxBytes > 0 {
+		// Bounding the line bounds the memory per event; data lines carry a field prefix
+		maxLine = maxBytes + len("data: ") + 1
+	}
+	scanner.Buffer(make([]byte, 0, min(64*1024, maxLine)), maxLine)
+	return &sseReader{scanner: scanner, maxBytes: maxBytes}
+}
+
+// next returns the next event, or io.EOF at the end of the stream
+func (r *sseReader) next() (sseEvent, error) {
+	var data []byte
+	id, hasData := r.lastID, false
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			if hasData {
+				r.lastID = id
+				return sseEvent{id: id, data: data}, nil
+			}
+			continue
+		}
+		if line[0] == '{' {
+			if r.maxBytes > 0 && len(line) > r.maxBytes {
+				return sseEvent{}, fmt.Errorf("%w: limit is %d bytes", ErrEventTooLarge, r.maxBytes)
+			}
+			return sseEvent{data: append([]byte(nil), line...)}, nil
+		}
+		if line[0] == ':' {
+			// Comment, such as a keep-alive
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "data":
+			if hasData {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+			hasData = true
+			if r.maxBytes > 0 && len(data) > r.maxBytes {
+				return sseEvent{}, fmt.Errorf("%w: limit is %d bytes", ErrEventTooLarge, r.maxBytes)
+			}
+		case "id":
+			if !bytes.ContainsRune(value, 0) {
+				id = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(string(value)); err == nil {
+				r.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return sseEvent{}, fmt.Errorf("%w: limit is %d bytes", ErrEventTooLarge, r.maxBytes)
+		}
+		return sseEvent{}, err
+	}
+	if hasData {
+		// A final event without its blank line is incomplete and discarded, per the SSE spec
+		return sseEvent{}, io.ErrUnexpectedEOF
+	}
+	return sseEvent{}, io.EOF
+}
+
+// EventsDropped returns the number of events an agent dropped from a stream before event, a
+// decoded stream event, because the client read too slowly; zero when it dropped none. The
+// events are lost to the stream, so the client should fetch the task with GetTask.
+func EventsDropped(event interface{}) int {
+	result, _ := event.(map[string]interface{})
+	metadata, _ := result["metadata"].(map[string]interface{})
+	n, ok := metadata["eventsDropped"].(json.Number)
+	if !ok {
+		return 0
+	}
+	dropped, _ := n.Int64()
+	return int(dropped)
+}
+
+// eventSequence returns the sequence number of a decoded stream event, zero when it has none
+func eventSequence(result map[string]interface{}) int64 {
+	n, ok := result["sequence"].(json.Number)
+	if !ok {
+		return 0
+	}
+	seq, _ := n.Int64()
+	return seq
+}
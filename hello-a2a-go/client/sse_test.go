@@ -0,0 +1,185 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestSSEReader(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"retry: 250\n" +
+		"id: s/1\n" +
+		"data: {\"a\":\n" +
+		"data: 1}\n\n" +
+		"event: ignored\n" +
+		"data:{\"b\":2}\n\n" +
+		"{\"c\":3}\n"
+	reader := newSSEReader(strings.NewReader(stream), 0)
+
+	want := []sseEvent{
+		{id: "s/1", data: []byte("{\"a\":\n1}")},
+		{id: "s/1", data: []byte(`{"b":2}`)},
+		{data: []byte(`{"c":3}`)},
+	}
+	for _, w := range want {
+		got, err := reader.next()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.id != w.id || string(got.data) != string(w.data) {
+			t.Errorf("expected event %q %q, got %q %q", w.id, w.data, got.id, got.data)
+		}
+	}
+	if _, err := reader.next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if reader.lastID != "s/1" || reader.retry != 250*time.Millisecond {
+		t.Errorf("expected last ID s/1 and retry 250ms, got %q %v", reader.lastID, reader.retry)
+	}
+
+	reader = newSSEReader(strings.NewReader("data: "+strings.Repeat("x", 20)+"\n\n"), 10)
+	if _, err := reader.next(); !errors.Is(err, ErrEventTooLarge) {
+		t.Errorf("expected ErrEventTooLarge, got %v", err)
+	}
+}
+
+func TestSendMessageStreaming_Resumes(t *testing.T) {
+	var mu sync.Mutex
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		attempt := len(lastEventIDs)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch attempt {
+		case 1:
+			// Drop the connection after the first event
+			io.WriteString(w, "retry: 500\nid: s/1\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"working\"},\"final\":false}}\n\n")
+		default:
+			io.WriteString(w, "id: s/2\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"completed\"},\"final\":true}}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(server.URL, WithClock(fake), WithTimeout(0))
+	eventChan := make(chan interface{}, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, eventChan)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(500 * time.Millisecond)
+	if err := <-errc; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(eventChan) != 2 {
+		t.Errorf("expected both events, got %d", len(eventChan))
+	}
+	if len(lastEventIDs) != 2 || lastEventIDs[0] != "" || lastEventIDs[1] != "s/1" {
+		t.Errorf("expected the retry to resume after s/1, got %q", lastEventIDs)
+	}
+}
+
+func TestSendMessageStreaming_RetriesExhausted(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "id: s/1\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"final\":false}}\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithStreamRetries(0))
+	err := client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, make(chan interface{}, 10))
+	if !errors.Is(err, errStreamInterrupted) {
+		t.Errorf("expected errStreamInterrupted, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retries, got %d requests", calls)
+	}
+}
+
+func TestSendMessageStreaming_CutOffWithoutIDs(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"final\":false}}\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	err := client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, make(chan interface{}, 10))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a stream without event IDs not to be resumed, got %d requests", calls)
+	}
+}
+
+func TestSendMessageStreaming_SkipsReplayedEvents(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if attempts.Add(1) == 1 {
+			io.WriteString(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"working\"},\"final\":false,\"sequence\":1}}\n\n")
+			return
+		}
+		// An agent replaying an event the client already has
+		io.WriteString(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"working\"},\"final\":false,\"sequence\":1}}\n\n")
+		io.WriteString(w, "id: 2\ndata: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"completed\"},\"final\":true,\"sequence\":2}}\n\n")
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(server.URL, WithClock(fake), WithTimeout(0))
+	eventChan := make(chan interface{}, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, eventChan)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(defaultRetryDelay)
+	if err := <-errc; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(eventChan)
+	var sequences []interface{}
+	for event := range eventChan {
+		sequences = append(sequences, event.(map[string]interface{})["sequence"])
+	}
+	if len(sequences) != 2 || sequences[0] != json.Number("1") || sequences[1] != json.Number("2") {
+		t.Errorf("expected events 1 and 2 once each, got %v", sequences)
+	}
+}
+
+func TestEventsDropped(t *testing.T) {
+	var marker interface{}
+	data := `{"id":"1","status":{"state":"working"},"final":false,"metadata":{"eventsDropped":40}}`
+	if err := models.DecodeJSON([]byte(data), &marker); err != nil {
+		t.Fatal(err)
+	}
+	if n := EventsDropped(marker); n != 40 {
+		t.Errorf("expected 40 dropped events, got %d", n)
+	}
+	if n := EventsDropped(map[string]interface{}{"id": "1"}); n != 0 {
+		t.Errorf("expected no dropped events, got %d", n)
+	}
+}
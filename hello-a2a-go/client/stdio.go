@@ -0,0 +1,270 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// stdioBaseURL is the placeholder endpoint of clients talking to a subprocess agent
+const stdioBaseURL = "stdio://agent"
+
+// stdioStopTimeout is how long Close waits for the subprocess to exit before killing it
+const stdioStopTimeout = 5 * time.Second
+
+// errTransportClosed is returned for requests made after the client was closed
+var errTransportClosed = errors.New("stdio transport closed")
+
+// NewStdioClient returns a client for a local agent run as a subprocess speaking A2A over
+// its stdin and stdout (see server.ServeStdio), so no TCP port is opened. The subprocess is
+// started on the first request and restarted on the next request if it exits; its stderr is
+// passed through. Call Close to stop it.
+func NewStdioClient(command string, args []string, opts ...Option) *Client {
+	c := NewClient(stdioBaseURL, opts...)
+	c.httpClient.Transport = &stdioTransport{command: command, args: args}
+	return c
+}
+
+// Close releases resources held by the client's transport, stopping a stdio subprocess
+func (c *Client) Close() error {
+	if closer, ok := c.httpClient.Transport.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// stdioTransport is an http.RoundTripper that exchanges JSON-RPC lines with a subprocess,
+// routing response lines back to requests by JSON-RPC ID
+type stdioTransport struct {
+	command string
+	args    []string
+
+	mu     sync.Mutex
+	proc   *stdioProcess
+	closed bool
+}
+
+// stdioProcess is one run of the subprocess
+type stdioProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	// exited is closed once the subprocess stdout is exhausted and it has been reaped
+	exited chan struct{}
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]*stdioCall
+}
+
+// stdioCall is a request awaiting response lines
+type stdioCall struct {
+	body      *io.PipeWriter
+	streaming bool
+	// done is closed once the call is complete
+	done chan struct{}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *stdioTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+
+	var envelope struct {
+		ID interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.ID == nil {
+		return nil, errors.New("stdio transport requires JSON-RPC requests with an ID")
+	}
+	key := idKey(envelope.ID)
+
+	proc, err := t.process()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	call := &stdioCall{
+		body:      writer,
+		streaming: req.Header.Get("Accept") == "text/event-stream",
+		done:      make(chan struct{}),
+	}
+
+	proc.mu.Lock()
+	if _, busy := proc.pending[key]; busy {
+		proc.mu.Unlock()
+		return nil, fmt.Errorf("request ID %s is already in flight", key)
+	}
+	proc.pending[key] = call
+	proc.mu.Unlock()
+
+	proc.writeMu.Lock()
+	_, err = proc.stdin.Write(append(bytes.TrimSpace(body), '\n'))
+	proc.writeMu.Unlock()
+	if err != nil {
+		proc.finish(key, err)
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	// Abandon the call when the request is canceled
+	go func() {
+		select {
+		case <-req.Context().Done():
+			proc.finish(key, req.Context().Err())
+		case <-call.done:
+		}
+	}()
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       reader,
+		Request:    req,
+	}, nil
+}
+
+// Close stops the subprocess, closing its stdin and killing it if it does not exit promptly
+func (t *stdioTransport) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	proc := t.proc
+	t.mu.Unlock()
+
+	if proc == nil {
+		return nil
+	}
+	proc.stdin.Close()
+	select {
+	case <-proc.exited:
+	case <-time.After(stdioStopTimeout):
+		proc.cmd.Process.Kill()
+		<-proc.exited
+	}
+	return nil
+}
+
+// process returns the running subprocess, starting it if it is not running
+func (t *stdioTransport) process() (*stdioProcess, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, errTransportClosed
+	}
+	if t.proc != nil {
+		select {
+		case <-t.proc.exited:
+		default:
+			return t.proc, nil
+		}
+	}
+
+	cmd := exec.Command(t.command, t.args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start agent process: %w", err)
+	}
+
+	proc := &stdioProcess{
+		cmd:     cmd,
+		stdin:   stdin,
+		exited:  make(chan struct{}),
+		pending: make(map[string]*stdioCall),
+	}
+	go proc.readResponses(stdout)
+	t.proc = proc
+	return proc, nil
+}
+
+// readResponses routes response lines to pending calls until the subprocess exits
+func (p *stdioProcess) readResponses(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var response struct {
+			ID     interface{}     `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(line, &response); err != nil || response.ID == nil {
+			continue
+		}
+		key := idKey(response.ID)
+
+		p.mu.Lock()
+		call, ok := p.pending[key]
+		p.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		if _, err := call.body.Write(append(append([]byte(nil), line...), '\n')); err != nil {
+			// The caller stopped reading
+			p.finish(key, nil)
+			continue
+		}
+
+		var event struct {
+			Final bool `json:"final"`
+		}
+		json.Unmarshal(response.Result, &event)
+		if !call.streaming || len(response.Error) > 0 || event.Final {
+			p.finish(key, nil)
+		}
+	}
+
+	waitErr := p.cmd.Wait()
+	exitErr := fmt.Errorf("agent process exited: %v", waitErr)
+	p.mu.Lock()
+	for key, call := range p.pending {
+		call.body.CloseWithError(exitErr)
+		close(call.done)
+		delete(p.pending, key)
+	}
+	p.mu.Unlock()
+	close(p.exited)
+}
+
+// finish completes the call for key, failing its response body with err when set
+func (p *stdioProcess) finish(key string, err error) {
+	p.mu.Lock()
+	call, ok := p.pending[key]
+	delete(p.pending, key)
+	p.mu.Unlock()
+
+	if ok {
+		call.body.CloseWithError(err)
+		close(call.done)
+	}
+}
+
+// idKey normalizes a decoded JSON-RPC ID so requests and responses can be matched
+func idKey(id interface{}) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// TestHelperStdioAgent is not a real test: it runs an echo agent over stdio when invoked as
+// a subprocess by the stdio client tests
+func TestHelperStdioAgent(t *testing.T) {
+	if os.Getenv("A2A_STDIO_HELPER") != "1" {
+		t.Skip("helper process for stdio tests")
+	}
+
+	echo := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{Role: "agent", Parts: message.Parts}
+		return task, nil
+	}
+	srv := server.NewA2AServer(models.AgentCard{Name: "Echo"}, echo)
+	server.ServeStdio(context.Background(), srv, os.Stdin, os.Stdout)
+	os.Exit(0)
+}
+
+func TestStdioClient(t *testing.T) {
+	t.Setenv("A2A_STDIO_HELPER", "1")
+	client := NewStdioClient(os.Args[0], []string{"-test.run=^TestHelperStdioAgent$"})
+	defer client.Close()
+
+	message := models.Message{
+		Role:  "user",
+		Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+	}
+
+	resp, err := client.SendMessage(models.MessageSendParams{ID: "stdio-1", Message: message})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	task := resp.Result.(*models.Task)
+	if task.Status.State != models.TaskStateCompleted {
+		t.Errorf("expected completed task, got %s", task.Status.State)
+	}
+
+	events := make(chan interface{}, 10)
+	if err := client.SendMessageStreaming(models.MessageSendParams{ID: "stdio-2", Message: message}, events); err != nil {
+		t.Fatalf("unexpected streaming error: %v", err)
+	}
+	close(events)
+	count := 0
+	for range events {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 streaming events, got %d", count)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if _, err := client.SendMessage(models.MessageSendParams{ID: "stdio-3", Message: message}); err == nil {
+		t.Error("expected requests after Close to fail")
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// WithTLSConfig sets the TLS configuration of connections to the agent, e.g. to trust a private
+// CA or to present a client certificate to agents requiring mutual TLS (see LoadTLSConfig)
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		transport, ok := c.httpClient.Transport.(*http.Transport)
+		if !ok {
+			transport = http.DefaultTransport.(*http.Transport)
+		}
+		transport = transport.Clone()
+		transport.TLSClientConfig = cfg
+		c.httpClient.Transport = transport
+	}
+}
+
+// LoadTLSConfig returns a TLS configuration trusting the CA certificates in the PEM file caFile,
+// or the system roots when it is empty, and presenting the certificate chain and key in the PEM
+// files certFile and keyFile when they are set
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("failed to read CA certificates: no certificates found")
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
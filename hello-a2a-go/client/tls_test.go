@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to dir
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	agent := server.NewA2AServer(models.AgentCard{Name: "Secured"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	})
+	ts := httptest.NewUnstartedServer(agent)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600)
+	certFile, keyFile := writeClientCert(t, dir)
+
+	params := models.MessageSendParams{
+		ID:      "secured",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+	}
+
+	trustOnly, err := LoadTLSConfig(caFile, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(ts.URL, WithTLSConfig(trustOnly)).SendMessage(params); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+
+	mutual, err := LoadTLSConfig(caFile, certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewClient(ts.URL, WithTLSConfig(mutual)).SendMessage(params)
+	if err != nil || resp.Error != nil {
+		t.Fatalf("Expected the request over mutual TLS to succeed, got %+v, %v", resp, err)
+	}
+}
+
+func TestLoadTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	os.WriteFile(empty, nil, 0o600)
+
+	if _, err := LoadTLSConfig(filepath.Join(dir, "nope.pem"), "", ""); err == nil {
+		t.Error("Expected an error for a missing CA file")
+	}
+	if _, err := LoadTLSConfig(empty, "", ""); err == nil {
+		t.Error("Expected an error for a CA file without certificates")
+	}
+	if _, err := LoadTLSConfig("", empty, empty); err == nil {
+		t.Error("Expected an error for an invalid client certificate")
+	}
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+
+	"a2a/models"
+	"a2a/trace"
+)
+
+// startSpan starts the client span of a JSON-RPC request. The span's trace context goes out in
+// the request headers, and also in the metadata of message params for transports without
+// headers; the returned request carries it.
+func startSpan(ctx context.Context, rpc models.JSONRPCRequest) (context.Context, trace.Span, models.JSONRPCRequest) {
+	ctx, span := trace.Start(ctx, "a2a.client "+rpc.Method, trace.SpanKindClient,
+		slog.String("rpc.method", rpc.Method), slog.Any("rpc.id", rpc.ID))
+	if params, ok := rpc.Params.(models.MessageSendParams); ok {
+		params.Metadata = trace.InjectMetadata(ctx, params.Metadata)
+		rpc.Params = params
+	}
+	return ctx, span, rpc
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/trace"
+)
+
+func TestClientSpans(t *testing.T) {
+	rec := &trace.Recorder{}
+	trace.SetTracer(trace.NewTracer(rec.Export))
+	defer trace.SetTracer(nil)
+
+	var header string
+	var metadata map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("traceparent")
+		var req struct {
+			Params struct {
+				Metadata map[string]interface{} `json:"metadata"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		metadata = req.Params.Metadata
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","error":{"code":-32001,"message":"Task not found"}}`)
+	}))
+	defer server.Close()
+
+	ctx := trace.NewContext(context.Background(), trace.Context{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	NewClient(server.URL).SendMessageContext(ctx, models.MessageSendParams{ID: "1", Metadata: map[string]interface{}{"skill": "translate"}})
+
+	spans := rec.Spans()
+	if len(spans) != 1 || spans[0].Name != "a2a.client message/send" || spans[0].Kind != trace.SpanKindClient {
+		t.Fatalf("Expected a client span, got %+v", spans)
+	}
+	span := spans[0]
+	if span.ParentID != "00f067aa0ba902b7" || models.ErrorCodeOf(span.Err) != models.ErrorCodeTaskNotFound {
+		t.Errorf("Expected a failed child of the caller's span, got %+v", span)
+	}
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + span.SpanID + "-01"
+	if header != want || metadata["traceparent"] != want || metadata["skill"] != "translate" {
+		t.Errorf("Expected traceparent %s in the headers and metadata, got %q and %v", want, header, metadata)
+	}
+}
@@ -0,0 +1,437 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// Transcript entry types
+const (
+	// TranscriptRequest records a call as it was sent, after interceptors
+	TranscriptRequest = "request"
+	// TranscriptResponse records the JSON-RPC response to a call
+	TranscriptResponse = "response"
+	// TranscriptEvent records the result of one event forwarded from a streaming call
+	TranscriptEvent = "event"
+	// TranscriptError records a call that failed without a JSON-RPC response, or whose stream
+	// broke off
+	TranscriptError = "error"
+)
+
+// redacted replaces the values of redacted headers and fields
+const redacted = "REDACTED"
+
+// credentialHeaders are the headers every transcript redacts
+var credentialHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "X-API-Key", models.HeaderSignature,
+}
+
+// credentialFields are the JSON fields every transcript redacts, such as the token and
+// credentials of push notification configs
+var credentialFields = []string{"token", "credentials"}
+
+// TranscriptEntry is one line of a transcript
+type TranscriptEntry struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	// Call numbers the call the entry belongs to, from 1 in each transcript
+	Call   int64  `json:"call"`
+	Method string `json:"method,omitempty"`
+	// Streaming is set on the request of a streaming call
+	Streaming bool `json:"streaming,omitempty"`
+	// Header is the client's and the call's headers, on requests
+	Header http.Header `json:"header,omitempty"`
+	// Data is the JSON-RPC request or response, or the result of an event
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Redactor scrubs an entry before it is written, e.g. to remove secrets from message text.
+// Credential headers and the token and credentials fields are always redacted first.
+type Redactor func(entry *TranscriptEntry)
+
+// RedactHeaders returns a redactor hiding the values of the named headers
+func RedactHeaders(names ...string) Redactor {
+	return func(entry *TranscriptEntry) {
+		for _, name := range names {
+			if values := entry.Header.Values(name); len(values) > 0 {
+				entry.Header.Set(name, redacted)
+			}
+		}
+	}
+}
+
+// RedactFields returns a redactor hiding the values of the named JSON object fields, at any
+// depth of the entry's data
+func RedactFields(names ...string) Redactor {
+	return func(entry *TranscriptEntry) {
+		if len(entry.Data) == 0 {
+			return
+		}
+		var value interface{}
+		if err := models.DecodeJSON(entry.Data, &value); err != nil {
+			return
+		}
+		if redactFields(value, names) {
+			if data, err := json.Marshal(value); err == nil {
+				entry.Data = data
+			}
+		}
+	}
+}
+
+// redactFields replaces the values of the named fields in value, reporting whether any were
+func redactFields(value interface{}, names []string) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if containsFold(names, key) {
+				v[key] = redacted
+				changed = true
+			} else if redactFields(field, names) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactFields(item, names) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// containsFold reports whether names holds name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transcript records a client's calls, their responses and streamed events as JSONL, one
+// TranscriptEntry per line, for debugging and for replaying with ReplayTranscript
+type Transcript struct {
+	redactors []Redactor
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	calls  int64
+	err    error
+}
+
+// NewTranscript returns a transcript writing to w, scrubbing each entry with redactors
+func NewTranscript(w io.Writer, redactors ...Redactor) *Transcript {
+	base := []Redactor{RedactHeaders(credentialHeaders...), RedactFields(credentialFields...)}
+	return &Transcript{w: w, redactors: append(base, redactors...)}
+}
+
+// OpenTranscript opens the transcript file at path for appending
+func OpenTranscript(path string, redactors ...Redactor) (*Transcript, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	t := NewTranscript(file, redactors...)
+	t.closer = file
+	return t, nil
+}
+
+// Close closes the transcript's file, if it opened one, and returns the first error writing
+// the transcript
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closer != nil {
+		if err := t.closer.Close(); err != nil && t.err == nil {
+			t.err = err
+		}
+		t.closer = nil
+	}
+	return t.err
+}
+
+// WithTranscript records every call of the client, its response and its streamed events to
+// transcript. Batches sent with SendBatch are not recorded.
+func WithTranscript(transcript *Transcript) Option {
+	return func(c *Client) {
+		c.transcript = transcript
+	}
+}
+
+// transcriptCallKey is the context key of the number of the call being recorded
+type transcriptCallKey struct{}
+
+// wrap returns invoke recording each call it sends with headers, the client's headers, at the
+// times told by c, the client's clock
+func (t *Transcript) wrap(c clock.Clock, headers http.Header, invoke Invoker) Invoker {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		t.mu.Lock()
+		t.calls++
+		call := t.calls
+		t.mu.Unlock()
+
+		header := headers.Clone()
+		for key, values := range req.Header {
+			header[key] = values
+		}
+		data, _ := json.Marshal(req.JSONRPCRequest)
+		t.record(c.Now(), TranscriptEntry{Type: TranscriptRequest, Call: call, Method: req.Method,
+			Streaming: req.Streaming, Header: header, Data: data})
+
+		resp, err := invoke(context.WithValue(ctx, transcriptCallKey{}, call), req)
+		switch {
+		case err != nil:
+			t.record(c.Now(), TranscriptEntry{Type: TranscriptError, Call: call, Method: req.Method, Error: err.Error()})
+		case resp != nil:
+			data, _ := json.Marshal(resp)
+			t.record(c.Now(), TranscriptEntry{Type: TranscriptResponse, Call: call, Method: req.Method, Data: data})
+		}
+		return resp, err
+	}
+}
+
+// recordEvent records result, received at now, as an event of the call recorded in ctx
+func (t *Transcript) recordEvent(ctx context.Context, now time.Time, result interface{}) {
+	call, ok := ctx.Value(transcriptCallKey{}).(int64)
+	if !ok {
+		return
+	}
+	data, _ := json.Marshal(result)
+	t.record(now, TranscriptEntry{Type: TranscriptEvent, Call: call, Data: data})
+}
+
+// record redacts entry, made at now, and writes it as one line
+func (t *Transcript) record(now time.Time, entry TranscriptEntry) {
+	entry.Time = now.UTC()
+	for _, redact := range t.redactors {
+		redact(&entry)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := t.w.Write(line); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+// ReplayResult is the outcome of replaying one recorded call
+type ReplayResult struct {
+	Call   int64
+	Method string
+	// Differences describe where the replayed response or events differ from the recording
+	Differences []string
+	// Err is set when the call could not be replayed
+	Err error
+}
+
+// replayIgnored are the fields that differ between runs of the same call, ignored when
+// comparing replayed calls to the recording
+var replayIgnored = []string{"timestamp", "messageId", "contextId", "sequence"}
+
+// recordedCall is a call read from a transcript
+type recordedCall struct {
+	request  TranscriptEntry
+	response *TranscriptEntry
+	events   []json.RawMessage
+	err      string
+}
+
+// ReplayTranscript sends each call recorded in the transcript r to c, in order, and compares
+// the responses and streamed events with the recorded ones, ignoring timestamps, message and
+// context IDs, sequence numbers and the fields named by ignore. Recorded headers are not
+// replayed, so c supplies its own credentials; redacted fields are sent as recorded.
+func ReplayTranscript(ctx context.Context, r io.Reader, c *Client, ignore ...string) ([]ReplayResult, error) {
+	calls, err := readTranscript(r)
+	if err != nil {
+		return nil, err
+	}
+	ignore = append(ignore, replayIgnored...)
+
+	results := make([]ReplayResult, 0, len(calls))
+	for _, call := range calls {
+		result := ReplayResult{Call: call.request.Call, Method: call.request.Method}
+		var req models.JSONRPCRequest
+		if err := models.DecodeJSON(call.request.Data, &req); err != nil {
+			result.Err = fmt.Errorf("failed to decode request: %w", err)
+			results = append(results, result)
+			continue
+		}
+
+		if call.request.Streaming {
+			events, err := replayStream(ctx, c, req)
+			switch {
+			case err != nil && call.err == "":
+				result.Err = err
+			case err == nil && call.err != "":
+				result.Differences = append(result.Differences, fmt.Sprintf("recorded error %q, replayed none", call.err))
+			}
+			if len(events) != len(call.events) {
+				result.Differences = append(result.Differences,
+					fmt.Sprintf("recorded %d events, replayed %d", len(call.events), len(events)))
+			}
+			for i := 0; i < len(events) && i < len(call.events); i++ {
+				result.Differences = append(result.Differences,
+					compareJSON(fmt.Sprintf("events[%d]", i), call.events[i], events[i], ignore)...)
+			}
+		} else {
+			resp, err := c.doRawRequest(ctx, req)
+			switch {
+			case err != nil:
+				if call.response != nil {
+					result.Err = err
+				}
+			case call.response == nil:
+				result.Differences = append(result.Differences, fmt.Sprintf("recorded error %q, replayed a response", call.err))
+			default:
+				data, _ := json.Marshal(resp)
+				result.Differences = compareJSON("response", call.response.Data, data, ignore)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// readTranscript returns the calls recorded in r, in the order they were made
+func readTranscript(r io.Reader) ([]*recordedCall, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+
+	byNumber := make(map[int64]*recordedCall)
+	var calls []*recordedCall
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if entry.Type == TranscriptRequest {
+			call := &recordedCall{request: entry}
+			byNumber[entry.Call] = call
+			calls = append(calls, call)
+			continue
+		}
+		call, ok := byNumber[entry.Call]
+		if !ok {
+			return nil, fmt.Errorf("line %d: %s of unrecorded call %d", line, entry.Type, entry.Call)
+		}
+		switch entry.Type {
+		case TranscriptResponse:
+			call.response = &entry
+		case TranscriptEvent:
+			call.events = append(call.events, entry.Data)
+		case TranscriptError:
+			call.err = entry.Error
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].request.Call < calls[j].request.Call })
+	return calls, nil
+}
+
+// replayStream sends the streaming call req and returns the results of its events
+func replayStream(ctx context.Context, c *Client, req models.JSONRPCRequest) ([]json.RawMessage, error) {
+	events := make(chan interface{}, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.stream(ctx, req, "", events)
+		close(events)
+	}()
+
+	var results []json.RawMessage
+	for event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return results, errors.Join(err, <-done)
+		}
+		results = append(results, data)
+	}
+	return results, <-done
+}
+
+// compareJSON describes the differences between the JSON values recorded and replayed at
+// path, ignoring the fields named by ignore
+func compareJSON(path string, recorded, replayed json.RawMessage, ignore []string) []string {
+	var want, got interface{}
+	if err := models.DecodeJSON(recorded, &want); err != nil {
+		return []string{fmt.Sprintf("%s: unreadable recording: %v", path, err)}
+	}
+	if err := models.DecodeJSON(replayed, &got); err != nil {
+		return []string{fmt.Sprintf("%s: unreadable reply: %v", path, err)}
+	}
+	return diffValues(path, want, got, ignore)
+}
+
+// diffValues describes the differences between the decoded JSON values want and got
+func diffValues(path string, want, got interface{}, ignore []string) []string {
+	wantMap, wantIsMap := want.(map[string]interface{})
+	gotMap, gotIsMap := got.(map[string]interface{})
+	if wantIsMap && gotIsMap {
+		keys := make(map[string]bool)
+		for key := range wantMap {
+			keys[key] = true
+		}
+		for key := range gotMap {
+			keys[key] = true
+		}
+		names := make([]string, 0, len(keys))
+		for key := range keys {
+			if !containsFold(ignore, key) {
+				names = append(names, key)
+			}
+		}
+		sort.Strings(names)
+		var diffs []string
+		for _, key := range names {
+			diffs = append(diffs, diffValues(path+"."+key, wantMap[key], gotMap[key], ignore)...)
+		}
+		return diffs
+	}
+
+	wantList, wantIsList := want.([]interface{})
+	gotList, gotIsList := got.([]interface{})
+	if wantIsList && gotIsList && len(wantList) == len(gotList) {
+		var diffs []string
+		for i := range wantList {
+			diffs = append(diffs, diffValues(fmt.Sprintf("%s[%d]", path, i), wantList[i], gotList[i], ignore)...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(want, got) {
+		return nil
+	}
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	return []string{fmt.Sprintf("%s: recorded %s, replayed %s", path, wantJSON, gotJSON)}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// transcriptServer answers every call with a task in state, streaming a working and a final
+// event to streaming calls
+func transcriptServer(t *testing.T, state *atomic.Value) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		final := state.Load().(string)
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"working\",\"timestamp\":%q}}}\n\n", r.Header.Get(models.HeaderRequestID))
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"id\":\"1\",\"status\":{\"state\":%q},\"final\":true}}\n\n", final)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":%q}}}`, final)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestTranscript(t *testing.T) {
+	var state atomic.Value
+	state.Store("completed")
+	ts := transcriptServer(t, &state)
+
+	var buf bytes.Buffer
+	redactText := func(entry *TranscriptEntry) {
+		entry.Data = bytes.ReplaceAll(entry.Data, []byte("secret"), []byte("xxx"))
+	}
+	fake := clock.NewFake(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	c := NewClient(ts.URL, WithAPIKey("key-1"), WithClock(fake), WithTranscript(NewTranscript(&buf, redactText)))
+
+	token := "push-token"
+	if _, err := c.SendMessage(models.MessageSendParams{ID: "1", Message: models.Message{Role: "user",
+		Parts: []models.Part{models.TextPart{Type: "text", Text: "my secret"}}},
+		Config: &models.MessageSendConfiguration{PushNotifications: &models.PushNotificationConfig{URL: "http://example.com", Token: &token}}}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	events := make(chan interface{}, 4)
+	if err := c.SendMessageStreaming(models.MessageSendParams{ID: "1", Message: models.Message{Role: "user"}}, events); err != nil {
+		t.Fatalf("SendMessageStreaming failed: %v", err)
+	}
+
+	transcript := buf.String()
+	for _, secret := range []string{"key-1", "push-token", "my secret"} {
+		if strings.Contains(transcript, secret) {
+			t.Errorf("Expected %q to be redacted from %s", secret, transcript)
+		}
+	}
+	for _, want := range []string{`"type":"request","call":1,"method":"message/send"`, `"X-Api-Key":["REDACTED"]`,
+		`"type":"response","call":1`, `"call":2,"method":"message/stream","streaming":true`, `"type":"event","call":2`, `"time":"2025-01-02T03:04:05Z"`} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("Expected %s in %s", want, transcript)
+		}
+	}
+
+	// The working event's timestamp differs on every run and is ignored
+	target := NewClient(ts.URL)
+	results, err := ReplayTranscript(context.Background(), strings.NewReader(transcript), target)
+	if err != nil {
+		t.Fatalf("ReplayTranscript failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Method != "message/send" || results[1].Method != "message/stream" {
+		t.Fatalf("Expected both calls to be replayed, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil || len(result.Differences) > 0 {
+			t.Errorf("Expected call %d to match, got %+v", result.Call, result)
+		}
+	}
+
+	state.Store("failed")
+	results, _ = ReplayTranscript(context.Background(), strings.NewReader(transcript), target)
+	want := []string{
+		`response.result.status.state: recorded "completed", replayed "failed"`,
+		`events[1].status.state: recorded "completed", replayed "failed"`,
+	}
+	for i, result := range results {
+		if len(result.Differences) != 1 || result.Differences[0] != want[i] {
+			t.Errorf("Expected call %d to differ in its state, got %q", result.Call, result.Differences)
+		}
+	}
+}
+
+func TestReplayTranscript_Invalid(t *testing.T) {
+	c := NewClient("http://localhost")
+	if _, err := ReplayTranscript(context.Background(), strings.NewReader("{"), c); err == nil {
+		t.Error("Expected an unreadable transcript to fail")
+	}
+	if _, err := ReplayTranscript(context.Background(), strings.NewReader(`{"type":"event","call":3}`), c); err == nil {
+		t.Error("Expected an event of an unrecorded call to fail")
+	}
+	if results, err := ReplayTranscript(context.Background(), io.MultiReader(), c); err != nil || len(results) != 0 {
+		t.Errorf("Expected an empty transcript to replay nothing, got %v, %v", results, err)
+	}
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes the connections a client makes to its agent. Zero values keep the
+// defaults of net/http's DefaultTransport.
+type TransportOptions struct {
+	// MaxIdleConns bounds the idle connections kept across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds the idle connections kept per host, which net/http limits to 2;
+	// raise it for clients making many concurrent calls to one agent
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds the connections per host, idle or not; further requests wait
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for this long
+	IdleConnTimeout time.Duration
+	// DialTimeout bounds establishing a connection
+	DialTimeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes on connections; negative disables them
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout bounds waiting for the agent's response headers once a request is
+	// written, including the headers of event streams
+	ResponseHeaderTimeout time.Duration
+	// DisableHTTP2 speaks HTTP/1.1 to agents served over TLS, which otherwise negotiate HTTP/2
+	// and multiplex the client's calls over one connection
+	DisableHTTP2 bool
+}
+
+// WithTransport sends every request, event stream and file transfer with rt, e.g. an
+// instrumented or shared *http.Transport. It replaces the transport of a unix:// base URL and
+// of options applied before it.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = rt
+	}
+}
+
+// WithTransportOptions tunes the client's connection pooling, timeouts and HTTP/2 use. It
+// applies to a copy of the client's *http.Transport as configured by the options before it,
+// keeping e.g. its TLS configuration, and does not change transports of other types, such as
+// a stdio transport or a RoundTripper given to WithTransport.
+func WithTransportOptions(opts TransportOptions) Option {
+	return func(c *Client) {
+		var transport *http.Transport
+		switch current := c.httpClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = current.Clone()
+		default:
+			return
+		}
+		opts.apply(transport, c.socketPath)
+		c.httpClient.Transport = transport
+	}
+}
+
+// apply sets the options on transport, which dials the Unix domain socket at socketPath when
+// it is not empty
+func (o TransportOptions) apply(transport *http.Transport, socketPath string) {
+	if o.MaxIdleConns > 0 {
+		transport.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+	if o.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	}
+	if o.DialTimeout != 0 || o.KeepAlive != 0 {
+		// The defaults of DefaultTransport's dialer
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if o.DialTimeout > 0 {
+			dialer.Timeout = o.DialTimeout
+		}
+		if o.KeepAlive != 0 {
+			dialer.KeepAlive = o.KeepAlive
+		}
+		transport.DialContext = dialer.DialContext
+		if socketPath != "" {
+			transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socketPath)
+			}
+		}
+	}
+	if o.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		// A non-nil empty map turns off net/http's automatic HTTP/2 support
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestWithTransportOptions(t *testing.T) {
+	client := NewClient("http://agent.example.com", WithTransportOptions(TransportOptions{
+		MaxIdleConns:          50,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       20,
+		IdleConnTimeout:       time.Minute,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		DisableHTTP2:          true,
+	}))
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, got %T", client.httpClient.Transport)
+	}
+	if transport.MaxIdleConns != 50 || transport.MaxIdleConnsPerHost != 10 || transport.MaxConnsPerHost != 20 ||
+		transport.IdleConnTimeout != time.Minute || transport.TLSHandshakeTimeout != 5*time.Second ||
+		transport.ResponseHeaderTimeout != 30*time.Second {
+		t.Errorf("Expected the options applied, got %+v", transport)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("Expected HTTP/2 disabled")
+	}
+	if transport == http.DefaultTransport {
+		t.Error("Expected the default transport left unchanged")
+	}
+
+	// Options tune the transport configured before them and leave other round trippers alone
+	custom := &countingTransport{}
+	client = NewClient("http://agent.example.com", WithTransport(custom), WithTransportOptions(TransportOptions{MaxConnsPerHost: 1}))
+	if client.httpClient.Transport != custom {
+		t.Errorf("Expected the custom transport kept, got %T", client.httpClient.Transport)
+	}
+}
+
+// countingTransport is an http.RoundTripper counting its requests
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"1","status":{"state":"completed"}}}`)
+	}))
+	defer server.Close()
+
+	custom := &countingTransport{}
+	client := NewClient(server.URL, WithTransport(custom))
+	if _, err := client.GetTask(models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "1"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if custom.requests != 1 {
+		t.Errorf("Expected the request sent with the custom transport, got %d requests", custom.requests)
+	}
+}
+
+func TestWithTransportOptions_UnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "a2a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	client := NewClient("unix://"+path+"/a2a", WithTransportOptions(TransportOptions{DialTimeout: time.Second}))
+	conn, err := client.httpClient.Transport.(*http.Transport).DialContext(context.Background(), "tcp", "unix:80")
+	if err != nil {
+		t.Fatalf("Expected the tuned dialer to dial the socket, got %v", err)
+	}
+	conn.Close()
+}
+
+func TestSendMessageStreaming_OutlivesTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"working\"},\"final\":false}}\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		io.WriteString(w, "data: {\"jsonrpc\":\"2.0\",\"result\":{\"id\":\"1\",\"status\":{\"state\":\"completed\"},\"final\":true}}\n\n")
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(server.URL, WithClock(fake), WithTimeout(time.Minute))
+	eventChan := make(chan interface{}, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, eventChan)
+	}()
+
+	<-eventChan
+	if fake.Waiters() != 0 {
+		t.Errorf("Expected the timeout stopped once the stream started, %d pending", fake.Waiters())
+	}
+	fake.Advance(10 * time.Minute)
+	close(release)
+	if err := <-errc; err != nil {
+		t.Fatalf("Expected the stream read to its end, got %v", err)
+	}
+	if len(eventChan) != 1 {
+		t.Errorf("Expected the final event, got %d events", len(eventChan))
+	}
+}
+
+func TestSendMessageStreaming_TimesOutWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(server.URL, WithClock(fake), WithTimeout(time.Minute))
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.SendMessageStreaming(models.MessageSendParams{ID: "1"}, make(chan interface{}, 10))
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	if err := <-errc; !errors.Is(err, errRequestTimeout) {
+		t.Fatalf("Expected a stream the agent never answers to time out, got %v", err)
+	}
+}
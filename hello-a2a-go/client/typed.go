@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"a2a/models"
+)
+
+// errMessageResult is returned where a task was expected but the agent answered with a message
+var errMessageResult = errors.New("agent answered with a message instead of a task")
+
+// SendOption sets the configuration of a message sent with SendMessageTyped
+type SendOption func(*models.MessageSendConfiguration)
+
+// NonBlocking asks the agent to answer as soon as it accepts the task, usually submitted, instead
+// of once the task finishes; follow the task with WaitForCompletion
+func NonBlocking() SendOption {
+	return func(config *models.MessageSendConfiguration) {
+		blocking := false
+		config.Blocking = &blocking
+	}
+}
+
+// HistoryLength limits the history of the task answered to its n most recent messages
+func HistoryLength(n int) SendOption {
+	return func(config *models.MessageSendConfiguration) {
+		config.HistoryLength = &n
+	}
+}
+
+// PushTo registers webhook to receive the task's updates
+func PushTo(webhook models.PushNotificationConfig) SendOption {
+	return func(config *models.MessageSendConfiguration) {
+		config.PushNotifications = &webhook
+	}
+}
+
+// AcceptOutputModes lists the output MIME types the client accepts, most preferred first
+func AcceptOutputModes(modes ...string) SendOption {
+	return func(config *models.MessageSendConfiguration) {
+		config.AcceptedOutputModes = modes
+	}
+}
+
+// SendMessageTyped sends a message like SendMessageContext and returns the agent's answer: the
+// task the message created or continued, or a message the agent answered with directly. Options
+// are applied to a copy of the configuration of params.
+func (c *Client) SendMessageTyped(ctx context.Context, params models.MessageSendParams, opts ...SendOption) (*models.SendMessageResult, error) {
+	if len(opts) > 0 {
+		var config models.MessageSendConfiguration
+		if params.Config != nil {
+			config = *params.Config
+		}
+		for _, opt := range opts {
+			opt(&config)
+		}
+		params.Config = &config
+	}
+	return call[models.SendMessageResult](ctx, c, newRequest(params.ID+"-request", "message/send", params), "result")
+}
+
+// GetTaskTyped retrieves a task like GetTaskContext, returning the task itself
+func (c *Client) GetTaskTyped(ctx context.Context, params models.TaskQueryParams) (*models.Task, error) {
+	return call[models.Task](ctx, c, newRequest(params.ID+"-get-request", "tasks/get", params), "task")
+}
+
+// CancelTaskTyped cancels a task like CancelTaskContext, returning the canceled task
+func (c *Client) CancelTaskTyped(ctx context.Context, params models.TaskIDParams) (*models.Task, error) {
+	return call[models.Task](ctx, c, newRequest(params.ID+"-cancel-request", "tasks/cancel", params), "task")
+}
+
+// ListMessagesTyped lists a page of messages matching params; pass the NextPageToken of the
+// result as the PageToken of params for the next page
+func (c *Client) ListMessagesTyped(ctx context.Context, params models.MessageListParams) (*models.MessageListResult, error) {
+	return call[models.MessageListResult](ctx, c, newRequest(params.TaskID+"-list-request", "message/list", params), "messages")
+}
+
+// Call invokes a JSON-RPC method of the agent with params and decodes its result as a T, e.g.
+// for extension methods without a Client method:
+//
+//	forecast, err := client.Call[Forecast](ctx, c, "weather/forecast", params)
+func Call[T any](ctx context.Context, c *Client, method string, params interface{}) (*T, error) {
+	return call[T](ctx, c, newRequest(method+"-request", method, params), method+" result")
+}
+
+// newRequest returns a JSON-RPC request for method with params
+func newRequest(id, method string, params interface{}) models.JSONRPCRequest {
+	return models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id},
+		},
+		Method: method,
+		Params: params,
+	}
+}
+
+// call sends req and decodes its result as a T, naming the result what in decoding errors
+func call[T any](ctx context.Context, c *Client, req models.JSONRPCRequest, what string) (*T, error) {
+	resp, err := c.doRawRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, models.ErrorFromJSONRPC(resp.Error)
+	}
+
+	var result T
+	if err := models.DecodeJSON(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", what, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// resultServer answers every JSON-RPC request with result, recording the method called
+func resultServer(t *testing.T, result interface{}, method *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		*method = req.Method
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{JSONRPC: "2.0"},
+			Result:         result,
+		})
+	}))
+}
+
+func TestSendMessageTyped(t *testing.T) {
+	params := models.MessageSendParams{
+		ID:      "t1",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+	}
+	var method string
+
+	server := resultServer(t, &models.Task{ID: "t1", Status: models.TaskStatus{State: models.TaskStateCompleted}}, &method)
+	result, err := NewClient(server.URL).SendMessageTyped(context.Background(), params)
+	server.Close()
+	if err != nil || result.Task == nil || result.Task.Status.State != models.TaskStateCompleted || method != "message/send" {
+		t.Errorf("Expected a completed task from message/send, got %+v (%v) from %s", result, err, method)
+	}
+
+	reply := &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}
+	server = resultServer(t, reply, &method)
+	defer server.Close()
+	client := NewClient(server.URL)
+	result, err = client.SendMessageTyped(context.Background(), params)
+	if err != nil || result.Message == nil || result.Task != nil || result.Message.Parts[0].(models.TextPart).Text != "Hello" {
+		t.Errorf("Expected the agent's message, got %+v (%v)", result, err)
+	}
+
+	// Untyped callers see the message too, and task-only helpers refuse it
+	resp, err := client.SendMessageContext(context.Background(), params)
+	if _, ok := resp.Result.(*models.Message); err != nil || !ok {
+		t.Errorf("Expected SendMessageContext to return the message, got %T (%v)", resp.Result, err)
+	}
+	if _, err := client.sendMessageTask(context.Background(), params); !errors.Is(err, errMessageResult) {
+		t.Errorf("Expected errMessageResult, got %v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	type info struct {
+		Tasks int `json:"tasks"`
+	}
+	var method string
+	server := resultServer(t, map[string]int{"tasks": 3}, &method)
+	defer server.Close()
+
+	got, err := Call[info](context.Background(), NewClient(server.URL), "agent/introspect", nil)
+	if err != nil || got.Tasks != 3 || method != "agent/introspect" {
+		t.Errorf("Expected 3 tasks from agent/introspect, got %+v (%v) from %s", got, err, method)
+	}
+
+	task, err := NewClient(server.URL).GetTaskTyped(context.Background(), models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "t1"}})
+	if err != nil || task == nil || method != "tasks/get" {
+		t.Errorf("Expected a task from tasks/get, got %+v (%v) from %s", task, err, method)
+	}
+}
+
+func TestListMessagesTyped(t *testing.T) {
+	var method string
+	server := resultServer(t, models.MessageListResult{
+		Messages:      []models.Message{{Role: "user", TaskID: "t1", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}}},
+		NextPageToken: "next",
+	}, &method)
+	defer server.Close()
+
+	page, err := NewClient(server.URL).ListMessagesTyped(context.Background(), models.MessageListParams{TaskID: "t1", PageSize: 1})
+	if err != nil || len(page.Messages) != 1 || page.NextPageToken != "next" || method != "message/list" {
+		t.Errorf("Expected a page of messages from message/list, got %+v (%v) from %s", page, err, method)
+	}
+}
+
+func TestSendMessageTyped_Options(t *testing.T) {
+	release := make(chan struct{})
+	agent := server.NewA2AServer(models.AgentCard{Name: "Slow"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	})
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+
+	config := &models.MessageSendConfiguration{AcceptedOutputModes: []string{"text/plain"}}
+	params := models.MessageSendParams{
+		ID:      "background",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+		Config:  config,
+	}
+	c := NewClient(ts.URL)
+	result, err := c.SendMessageTyped(context.Background(), params, NonBlocking(), HistoryLength(0))
+	if err != nil || result.Task == nil || result.Task.Status.State.IsTerminal() || len(result.Task.History) != 0 {
+		t.Fatalf("Expected the unfinished task at once without history, got %+v (%v)", result, err)
+	}
+	if config.Blocking != nil || config.HistoryLength != nil {
+		t.Errorf("Expected the caller's configuration to be left alone, got %+v", config)
+	}
+
+	close(release)
+	task, err := c.WaitForCompletion(context.Background(), "background", PollOptions{NoStreaming: true})
+	if err != nil || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the task to complete in the background, got %+v (%v)", task, err)
+	}
+}
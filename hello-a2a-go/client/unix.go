@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// unixScheme prefixes base URLs of agents served on a Unix domain socket
+const unixScheme = "unix://"
+
+// splitUnixURL splits a unix:// URL into the socket path and the HTTP path. The socket path
+// ends with the first path segment ending in ".sock", so "unix:///run/agent.sock/a2a" names
+// socket /run/agent.sock and HTTP path /a2a. Without such a segment the whole URL names the
+// socket and requests go to "/".
+func splitUnixURL(rawURL string) (socketPath, httpPath string) {
+	path := strings.TrimPrefix(rawURL, unixScheme)
+	if i := strings.Index(path, ".sock/"); i >= 0 {
+		return path[:i+len(".sock")], path[i+len(".sock"):]
+	}
+	return path, "/"
+}
+
+// unixTransport returns a transport dialing the Unix domain socket at socketPath for every request
+func unixTransport(socketPath string) *http.Transport {
+	var dialer net.Dialer
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+}
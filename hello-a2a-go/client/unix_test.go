@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestSplitUnixURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		socketPath string
+		httpPath   string
+	}{
+		{"unix:///run/agent.sock", "/run/agent.sock", "/"},
+		{"unix:///run/agent.sock/a2a", "/run/agent.sock", "/a2a"},
+		{"unix:///run/agent", "/run/agent", "/"},
+	}
+	for _, tt := range tests {
+		socketPath, httpPath := splitUnixURL(tt.url)
+		if socketPath != tt.socketPath || httpPath != tt.httpPath {
+			t.Errorf("splitUnixURL(%q) = %q, %q; want %q, %q", tt.url, socketPath, httpPath, tt.socketPath, tt.httpPath)
+		}
+	}
+}
+
+func TestUnixClient(t *testing.T) {
+	dir, err := os.MkdirTemp("", "a2a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "agent.sock")
+
+	listener, err := server.ListenUnix(path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/a2a", server.NewA2AServer(models.AgentCard{Name: "Unix"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}))
+	go http.Serve(listener, mux)
+
+	client := NewClient("unix://" + path + "/a2a")
+	resp, err := client.SendMessage(models.MessageSendParams{
+		ID: "unix-1",
+		Message: models.Message{
+			Role:  "user",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := resp.Result.(*models.Task).Status.State; state != models.TaskStateCompleted {
+		t.Errorf("expected completed task, got %s", state)
+	}
+}
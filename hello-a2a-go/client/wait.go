@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"a2a/models"
+)
+
+// defaultMaxPollInterval caps the wait between the polls of WaitForCompletion
+const defaultMaxPollInterval = 30 * time.Second
+
+// ErrInputRequired is returned by WaitForCompletion with a task that awaits input, which would
+// otherwise be waited for forever
+var ErrInputRequired = errors.New("task requires input")
+
+// PollOptions configures how WaitForCompletion waits for a task. The zero value streams when
+// the agent can, and polls from the client's poll interval, doubling the wait up to 30 seconds.
+type PollOptions struct {
+	// Interval is the wait before the first poll (default: the client's, see WithPollInterval)
+	Interval time.Duration
+	// MaxInterval caps the wait as it grows (default 30s)
+	MaxInterval time.Duration
+	// Multiplier grows the wait after each poll; values below 1 mean 2
+	Multiplier float64
+	// HistoryLength limits the history of the task returned, as for tasks/get
+	HistoryLength *int
+	// NoStreaming polls even agents that stream
+	NoStreaming bool
+}
+
+// WaitForCompletion waits for the task taskID to reach a terminal state and returns it, with its
+// artifacts. It first follows the task's stream with tasks/resubscribe, unless the agent does not
+// stream, then polls it with tasks/get with backoff until it finishes. A task that requires input
+// is returned with ErrInputRequired; the context bounds the wait.
+func (c *Client) WaitForCompletion(ctx context.Context, taskID string, opts PollOptions) (*models.Task, error) {
+	params := models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: taskID}, HistoryLength: opts.HistoryLength}
+	if !opts.NoStreaming {
+		if err := c.awaitStream(ctx, params); err != nil {
+			if ctx.Err() != nil {
+				return nil, context.Cause(ctx)
+			}
+			if models.ErrorCodeOf(err) == models.ErrorCodeTaskNotFound {
+				return nil, err
+			}
+			c.log(ctx).Debug("polling task instead of streaming", slog.String("task_id", taskID), slog.Any("error", err))
+		}
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = c.pollInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+	multiplier := opts.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	for {
+		task, err := c.GetTaskTyped(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status.State.IsTerminal() {
+			return task, nil
+		}
+		if task.Status.State == models.TaskStateInputRequired {
+			return task, fmt.Errorf("%w: %s", ErrInputRequired, task.ID)
+		}
+
+		select {
+		case <-c.clock.After(interval):
+		case <-ctx.Done():
+			return task, context.Cause(ctx)
+		}
+		interval = min(time.Duration(float64(interval)*multiplier), maxInterval)
+	}
+}
+
+// awaitStream follows the stream of the task until its final event, discarding the events, as
+// the task is fetched once it ends
+func (c *Client) awaitStream(ctx context.Context, params models.TaskQueryParams) error {
+	events := make(chan interface{})
+	drained := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(drained)
+	}()
+	err := c.ResubscribeTask(ctx, params, "", events)
+	close(events)
+	<-drained
+	return err
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+	"a2a/server"
+)
+
+func TestWaitForCompletion_Streams(t *testing.T) {
+	release := make(chan struct{})
+	agent := server.NewA2AServer(models.AgentCard{Name: "Slow"}, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		<-release
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.TextPart{Type: "text", Text: "done"}}}}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	})
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+
+	// Start the task on a stream of its own, which the waiting client reattaches to
+	events := make(chan interface{}, 10)
+	c := NewClient(ts.URL)
+	go c.SendMessageStreaming(models.MessageSendParams{ID: "slow", Message: models.Message{Role: "user", Parts: []models.Part{}}}, events)
+	<-events
+
+	waited := make(chan *models.Task, 1)
+	go func() {
+		task, err := c.WaitForCompletion(context.Background(), "slow", PollOptions{Interval: time.Hour})
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		waited <- task
+	}()
+	close(release)
+	select {
+	case task := <-waited:
+		if task == nil || task.Status.State != models.TaskStateCompleted || len(task.Artifacts) != 1 {
+			t.Errorf("Expected the completed task with its artifact, got %+v", task)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the stream to end the wait without polling")
+	}
+}
+
+func TestWaitForCompletion_PollsWithBackoff(t *testing.T) {
+	var gets int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "tasks/get" {
+			t.Errorf("Expected only tasks/get, got %s", req.Method)
+		}
+		state := models.TaskStateWorking
+		if gets++; gets == 3 {
+			state = models.TaskStateCompleted
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"slow","status":{"state":%q}}}`, state)
+	}))
+	defer ts.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	c := NewClient(ts.URL, WithClock(fake), WithTimeout(0))
+	waited := make(chan error, 1)
+	go func() {
+		task, err := c.WaitForCompletion(context.Background(), "slow", PollOptions{Interval: time.Second, NoStreaming: true})
+		if err == nil && task.Status.State != models.TaskStateCompleted {
+			err = fmt.Errorf("task %s", task.Status.State)
+		}
+		waited <- err
+	}()
+
+	// The wait doubles after each poll
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second} {
+		fake.BlockUntil(1)
+		fake.Advance(wait - time.Millisecond)
+		if fake.Waiters() != 1 {
+			t.Fatalf("Expected the client to wait %s", wait)
+		}
+		fake.Advance(time.Millisecond)
+	}
+	if err := <-waited; err != nil || gets != 3 {
+		t.Errorf("Expected the task to complete on the third poll, got %v after %d polls", err, gets)
+	}
+}
+
+func TestWaitForCompletion_FallsBackToPolling(t *testing.T) {
+	var methods []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		methods = append(methods, req.Method)
+		if req.Method == "tasks/resubscribe" {
+			io.WriteString(w, `{"jsonrpc":"2.0","id":"1","error":{"code":-32004,"message":"Streaming is not supported"}}`)
+			return
+		}
+		io.WriteString(w, `{"jsonrpc":"2.0","id":"1","result":{"id":"asking","status":{"state":"input-required"}}}`)
+	}))
+	defer ts.Close()
+
+	task, err := NewClient(ts.URL).WaitForCompletion(context.Background(), "asking", PollOptions{})
+	if !errors.Is(err, ErrInputRequired) || task == nil || task.Status.State != models.TaskStateInputRequired {
+		t.Errorf("Expected ErrInputRequired with the waiting task, got %v, %+v", err, task)
+	}
+	if len(methods) != 2 || methods[1] != "tasks/get" {
+		t.Errorf("Expected tasks/get after the stream failed, got %v", methods)
+	}
+}
@@ -0,0 +1,166 @@
+// Package clock abstracts the passage of time so timeouts, timestamps and expiry can be
+// driven by a fake clock in tests instead of time.Sleep.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules work after a duration
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel that receives the time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+	// AfterFunc calls f once d has elapsed, unless the returned timer is stopped first
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending AfterFunc call
+type Timer interface {
+	// Stop prevents the call, reporting whether it was still pending
+	Stop() bool
+}
+
+// Real is the Clock backed by the time package
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Fake is a Clock that only moves when Advance or Set is called. Timers whose deadline is
+// reached fire synchronously, in deadline order, before Advance returns.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a pending After channel or AfterFunc call
+type fakeWaiter struct {
+	clock    *Fake
+	deadline time.Time
+	ch       chan time.Time
+	fn       func()
+}
+
+// NewFake returns a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now implements Clock
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After implements Clock
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	f.schedule(&fakeWaiter{clock: f, ch: ch}, d)
+	return ch
+}
+
+// AfterFunc implements Clock
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &fakeWaiter{clock: f, fn: fn}
+	f.schedule(w, d)
+	return w
+}
+
+// schedule registers w to fire after d, firing it at once when d is not positive
+func (f *Fake) schedule(w *fakeWaiter, d time.Duration) {
+	f.mu.Lock()
+	w.deadline = f.now.Add(d)
+	if d <= 0 {
+		now := f.now
+		f.mu.Unlock()
+		w.fire(now)
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+	f.mu.Unlock()
+}
+
+// Advance moves the clock forward by d, firing the timers that become due
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	now := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(now)
+}
+
+// Set moves the clock to now, firing the timers that become due. Moving it backwards fires
+// nothing.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	f.now = now
+	var due, pending []*fakeWaiter
+	for _, w := range f.waiters {
+		if w.deadline.After(now) {
+			pending = append(pending, w)
+		} else {
+			due = append(due, w)
+		}
+	}
+	f.waiters = pending
+	f.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].deadline.Before(due[j].deadline) })
+	for _, w := range due {
+		w.fire(now)
+	}
+}
+
+// Waiters returns the number of pending timers
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers are pending, so a test can advance the clock
+// once the code under test has started waiting on it
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// fire delivers the waiter's tick
+func (w *fakeWaiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+	w.ch <- now
+}
+
+// Stop implements Timer
+func (w *fakeWaiter) Stop() bool {
+	f := w.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
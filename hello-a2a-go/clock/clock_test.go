@@ -0,0 +1,59 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_Advance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	ch := f.After(time.Minute)
+	var fired []string
+	f.AfterFunc(2*time.Minute, func() { fired = append(fired, "second") })
+	f.AfterFunc(30*time.Second, func() { fired = append(fired, "first") })
+	stopped := f.AfterFunc(time.Minute, func() { fired = append(fired, "stopped") })
+	if !stopped.Stop() {
+		t.Error("Expected Stop to report a pending timer")
+	}
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("Expected After not to fire before its deadline")
+	default:
+	}
+
+	f.Advance(2 * time.Minute)
+	select {
+	case got := <-ch:
+		if want := start.Add(59*time.Second + 2*time.Minute); !got.Equal(want) {
+			t.Errorf("Expected tick at %v, got %v", want, got)
+		}
+	default:
+		t.Fatal("Expected After to fire")
+	}
+	if len(fired) != 2 || fired[0] != "first" || fired[1] != "second" {
+		t.Errorf("Expected timers to fire in deadline order, got %v", fired)
+	}
+	if stopped.Stop() {
+		t.Error("Expected Stop to report an already removed timer")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("Expected no pending timers, got %d", f.Waiters())
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Hour)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Hour)
+	<-done
+}
@@ -0,0 +1,290 @@
+// Command a2a talks to any A2A agent from the terminal: it shows an agent's card, sends or
+// streams a message, gets, waits for or cancels a task, printing results for people or as JSON.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"a2a/client"
+	"a2a/models"
+)
+
+const usage = `Usage: a2a <command> [flags] <url> [args]
+
+Commands:
+  card <url>                 show the agent card served at the agent's base URL
+  send <url> -text TEXT      send a message and print the resulting task or message
+  stream <url> -text TEXT    send a message and print the task's updates as they arrive
+  get-task <url> <task-id>   print a task
+  wait <url> <task-id>       wait for a task to finish and print it
+  cancel <url> <task-id>     cancel a task and print it
+  replay <url> <transcript>  replay the calls recorded in a transcript and report differences
+
+<url> is the agent's JSON-RPC endpoint, e.g. http://localhost:8080/a2a, except for card.
+Run a2a <command> -h for the flags of a command. A2A_BEARER_TOKEN, A2A_API_KEY,
+A2A_SHARED_SECRET and A2A_TLS_CA, A2A_TLS_CERT and A2A_TLS_KEY configure authentication as for
+cmd/client.
+A2A_TRANSCRIPT names a file every call, response and event is appended to as JSONL, with
+credentials redacted, for replaying against another agent.
+`
+
+// errUsage is returned for invalid command lines, after printing the usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "a2a:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run executes the command line args, writing results to out and usage to errOut
+func run(ctx context.Context, args []string, out, errOut io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(errOut, usage)
+		return errUsage
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("a2a "+command, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	timeout := fs.Duration("timeout", 0, "give up after this long (default: no limit)")
+	var text, taskID, contextID *string
+	var history *int
+	var interval *time.Duration
+	var ignore *string
+	wantArgs := 1
+	switch command {
+	case "card":
+	case "send", "stream":
+		text = fs.String("text", "", "text of the message (required)")
+		taskID = fs.String("task", "", "ID of the task to create or continue (default: a new random ID)")
+		contextID = fs.String("context", "", "conversation ID of the message")
+	case "get-task":
+		history = fs.Int("history", -1, "number of recent history messages to include (default: all)")
+		wantArgs = 2
+	case "wait":
+		history = fs.Int("history", -1, "number of recent history messages to include (default: all)")
+		interval = fs.Duration("interval", time.Second, "wait before the first poll of an agent that does not stream, doubling up to 30s")
+		wantArgs = 2
+	case "cancel":
+		wantArgs = 2
+	case "replay":
+		ignore = fs.String("ignore", "", "comma-separated further fields to ignore when comparing, e.g. id,text")
+		wantArgs = 2
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(out, usage)
+		return nil
+	default:
+		fmt.Fprintf(errOut, "Unknown command %q\n\n%s", command, usage)
+		return errUsage
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != wantArgs || (text != nil && *text == "") {
+		fmt.Fprint(errOut, usage)
+		return errUsage
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	var opts []client.Option
+	if path := os.Getenv("A2A_TRANSCRIPT"); path != "" && command != "replay" {
+		transcript, err := client.OpenTranscript(path)
+		if err != nil {
+			return err
+		}
+		defer transcript.Close()
+		opts = append(opts, client.WithTranscript(transcript))
+	}
+	c, err := newClient(positional[0], opts...)
+	if err != nil {
+		return err
+	}
+	p := &printer{w: out, json: *asJSON}
+
+	switch command {
+	case "card":
+		card, err := c.GetAgentCardContext(ctx)
+		if err != nil {
+			return err
+		}
+		return p.card(card)
+	case "send":
+		result, err := c.SendMessageTyped(ctx, newMessageParams(*text, *taskID, *contextID))
+		if err != nil {
+			return err
+		}
+		return p.result(result)
+	case "stream":
+		return stream(ctx, c, newMessageParams(*text, *taskID, *contextID), p)
+	case "get-task":
+		params := models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: positional[1]}}
+		if *history >= 0 {
+			params.HistoryLength = history
+		}
+		task, err := c.GetTaskTyped(ctx, params)
+		if err != nil {
+			return err
+		}
+		return p.task(task)
+	case "wait":
+		opts := client.PollOptions{Interval: *interval}
+		if *history >= 0 {
+			opts.HistoryLength = history
+		}
+		task, err := c.WaitForCompletion(ctx, positional[1], opts)
+		if err != nil && task == nil {
+			return err
+		}
+		if printErr := p.task(task); printErr != nil {
+			return printErr
+		}
+		return err
+	case "replay":
+		return replay(ctx, c, positional[1], *ignore, out)
+	default: // cancel
+		task, err := c.CancelTaskTyped(ctx, models.TaskIDParams{ID: positional[1]})
+		if err != nil {
+			return err
+		}
+		return p.task(task)
+	}
+}
+
+// parseInterspersed parses the flags of args, which may follow positional arguments as in
+// `a2a send <url> -text hi`, and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// stream sends params with message/stream, printing each event as it arrives
+func stream(ctx context.Context, c *client.Client, params models.MessageSendParams, p *printer) error {
+	events := make(chan interface{}, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.SendMessageStreamingContext(ctx, params, events)
+		close(events)
+	}()
+
+	var printErr error
+	for event := range events {
+		if printErr == nil {
+			printErr = p.event(event)
+		}
+	}
+	if err := <-done; err != nil {
+		return err
+	}
+	return printErr
+}
+
+// replay replays the transcript at path against c, printing each call that differs
+func replay(ctx context.Context, c *client.Client, path, ignore string, out io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var fields []string
+	if ignore != "" {
+		fields = strings.Split(ignore, ",")
+	}
+	results, err := client.ReplayTranscript(ctx, file, c, fields...)
+	if err != nil {
+		return fmt.Errorf("failed to replay %s: %w", path, err)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err == nil && len(result.Differences) == 0 {
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "call %d (%s):\n", result.Call, result.Method)
+		if result.Err != nil {
+			fmt.Fprintf(out, "  error: %v\n", result.Err)
+		}
+		for _, diff := range result.Differences {
+			fmt.Fprintf(out, "  %s\n", diff)
+		}
+	}
+	fmt.Fprintf(out, "%d of %d calls matched\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d calls differ from the transcript", failed)
+	}
+	return nil
+}
+
+// newClient returns a client for url with opts, authenticating as configured by the
+// environment
+func newClient(url string, opts ...client.Option) (*client.Client, error) {
+	opts = append([]client.Option{client.WithTimeout(5 * time.Minute)}, opts...)
+	if secret := os.Getenv("A2A_SHARED_SECRET"); secret != "" {
+		opts = append(opts, client.WithSigningSecret([]byte(secret)))
+	}
+	if token := os.Getenv("A2A_BEARER_TOKEN"); token != "" {
+		opts = append(opts, client.WithAuth(client.StaticTokenSource(token)))
+	}
+	if key := os.Getenv("A2A_API_KEY"); key != "" {
+		opts = append(opts, client.WithAPIKey(key))
+	}
+	if ca, cert, key := os.Getenv("A2A_TLS_CA"), os.Getenv("A2A_TLS_CERT"), os.Getenv("A2A_TLS_KEY"); ca != "" || cert != "" {
+		tlsConfig, err := client.LoadTLSConfig(ca, cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS configuration: %w", err)
+		}
+		opts = append(opts, client.WithTLSConfig(tlsConfig))
+	}
+	return client.NewClient(url, opts...), nil
+}
+
+// newMessageParams returns the parameters sending text as a user message to taskID, or to a
+// new task when taskID is empty
+func newMessageParams(text, taskID, contextID string) models.MessageSendParams {
+	if taskID == "" {
+		id := make([]byte, 8)
+		rand.Read(id)
+		taskID = "cli-" + hex.EncodeToString(id)
+	}
+	return models.MessageSendParams{
+		ID: taskID,
+		Message: models.Message{
+			Role:      "user",
+			ContextID: contextID,
+			Parts:     []models.Part{models.NewTextPart(text)},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// startAgent serves an agent that streams its answer, the message text upper-cased, in two
+// appended chunks, except to "wait", which leaves the task awaiting input
+func startAgent(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	description := "Shouts back"
+	card := models.AgentCard{
+		Name:         "Echo Agent",
+		Description:  &description,
+		Version:      "1.0.0",
+		Capabilities: models.AgentCapabilities{Streaming: boolPtr(true)},
+		Skills:       []models.AgentSkill{{ID: "echo", Name: "Echo"}},
+	}
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		if message.Parts[0].(models.TextPart).Text == "wait" {
+			task.Status.State = models.TaskStateInputRequired
+			return task, nil
+		}
+		text := strings.ToUpper(message.Parts[0].(models.TextPart).Text)
+		half := len(text) / 2
+		server.EmitArtifact(ctx, models.Artifact{Parts: []models.Part{models.TextPart{Type: "text", Text: text[:half]}}, LastChunk: boolPtr(false)})
+		server.EmitArtifact(ctx, models.Artifact{Parts: []models.Part{models.TextPart{Type: "text", Text: text[half:]}}, Append: boolPtr(true), LastChunk: boolPtr(true)})
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	mux := http.NewServeMux()
+	server.NewA2AServer(card, handler).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// runA2A runs the command line args and returns its output
+func runA2A(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var out, errOut strings.Builder
+	if err := run(context.Background(), args, &out, &errOut); err != nil {
+		t.Fatalf("a2a %s failed: %v\n%s", strings.Join(args, " "), err, errOut.String())
+	}
+	return out.String()
+}
+
+func TestCommands(t *testing.T) {
+	ts := startAgent(t)
+	endpoint := ts.URL + "/a2a"
+
+	if out := runA2A(t, "card", ts.URL); !strings.Contains(out, "Echo Agent 1.0.0\n  Shouts back\n") || !strings.Contains(out, "Capabilities: streaming\n") || !strings.Contains(out, "  Echo (echo)\n") {
+		t.Errorf("Unexpected card output:\n%s", out)
+	}
+
+	want := "Task t1: completed\nArtifact #0:\n  HELLO\nHistory:\n  user: hello\n"
+	if out := runA2A(t, "send", endpoint, "-text", "hello", "-task", "t1"); out != want {
+		t.Errorf("Expected send to print\n%s\ngot\n%s", want, out)
+	}
+
+	want = "Task t2: working\nArtifact #0: HELLO\nTask t2: completed\n"
+	if out := runA2A(t, "stream", endpoint, "-task", "t2", "--text", "hello"); out != want {
+		t.Errorf("Expected stream to print\n%s\ngot\n%s", want, out)
+	}
+
+	var task models.Task
+	if err := json.Unmarshal([]byte(runA2A(t, "get-task", "-json", endpoint, "t1", "-history", "0")), &task); err != nil || task.ID != "t1" || len(task.History) != 0 {
+		t.Errorf("Expected task t1 as JSON without history, got %+v (%v)", task, err)
+	}
+
+	if out := runA2A(t, "wait", endpoint, "t1", "-history", "0"); out != "Task t1: completed\nArtifact #0:\n  HELLO\n" {
+		t.Errorf("Unexpected wait output:\n%s", out)
+	}
+
+	runA2A(t, "send", endpoint, "-text", "wait", "-task", "t3")
+	if out := runA2A(t, "cancel", endpoint, "t3"); !strings.HasPrefix(out, "Task t3: canceled\n") {
+		t.Errorf("Unexpected cancel output:\n%s", out)
+	}
+
+	err := run(context.Background(), []string{"get-task", endpoint, "unknown"}, &strings.Builder{}, &strings.Builder{})
+	if models.ErrorCodeOf(err) != models.ErrorCodeTaskNotFound {
+		t.Errorf("Expected an unknown task not to be found, got %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"unknown"}, {"send", "http://localhost"}, {"get-task", "http://localhost"}} {
+		var out, errOut strings.Builder
+		if err := run(context.Background(), args, &out, &errOut); err != errUsage {
+			t.Errorf("Expected a usage error for %q, got %v", args, err)
+		}
+		if !strings.Contains(errOut.String(), "Usage: a2a") {
+			t.Errorf("Expected the usage for %q, got %q", args, errOut.String())
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	t.Setenv("A2A_TRANSCRIPT", path)
+	runA2A(t, "send", startAgent(t).URL+"/a2a", "-text", "hello", "-task", "r1")
+	runA2A(t, "stream", startAgent(t).URL+"/a2a", "-text", "hello", "-task", "r2")
+	t.Setenv("A2A_TRANSCRIPT", "")
+
+	if out := runA2A(t, "replay", startAgent(t).URL+"/a2a", path); out != "2 of 2 calls matched\n" {
+		t.Errorf("Expected the calls to match a new agent, got\n%s", out)
+	}
+
+	// A recording the agent no longer matches is reported
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.ReplaceAll(data, []byte("HELLO"), []byte("HOWDY")), 0o600)
+	var out strings.Builder
+	err := run(context.Background(), []string{"replay", startAgent(t).URL + "/a2a", path}, &out, &strings.Builder{})
+	if err == nil || !strings.Contains(out.String(), `recorded "HOWDY", replayed "HELLO"`) || !strings.HasSuffix(out.String(), "1 of 2 calls matched\n") {
+		t.Errorf("Expected the changed answers to be reported, got %v\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"a2a/models"
+)
+
+// printer writes results as indented JSON or as text for people
+type printer struct {
+	w    io.Writer
+	json bool
+	// chunking is set while an artifact's appended chunks are being printed on one line
+	chunking bool
+}
+
+// card prints an agent card
+func (p *printer) card(card *models.AgentCard) error {
+	if p.json {
+		return p.printJSON(card)
+	}
+	fmt.Fprintf(p.w, "%s %s\n", card.Name, card.Version)
+	if card.Description != nil {
+		fmt.Fprintf(p.w, "  %s\n", *card.Description)
+	}
+	fmt.Fprintf(p.w, "URL: %s\n", card.URL)
+	if card.ProtocolVersion != "" {
+		fmt.Fprintf(p.w, "Protocol: %s\n", card.ProtocolVersion)
+	}
+	var capabilities []string
+	for _, c := range []struct {
+		name    string
+		enabled *bool
+	}{
+		{"streaming", card.Capabilities.Streaming},
+		{"pushNotifications", card.Capabilities.PushNotifications},
+		{"stateTransitionHistory", card.Capabilities.StateTransitionHistory},
+	} {
+		if c.enabled != nil && *c.enabled {
+			capabilities = append(capabilities, c.name)
+		}
+	}
+	if len(capabilities) > 0 {
+		fmt.Fprintf(p.w, "Capabilities: %s\n", strings.Join(capabilities, ", "))
+	}
+	if len(card.Skills) > 0 {
+		fmt.Fprintln(p.w, "Skills:")
+		for _, skill := range card.Skills {
+			fmt.Fprintf(p.w, "  %s (%s)", skill.Name, skill.ID)
+			if skill.Description != nil {
+				fmt.Fprintf(p.w, ": %s", *skill.Description)
+			}
+			fmt.Fprintln(p.w)
+		}
+	}
+	return nil
+}
+
+// result prints the answer to message/send, a task or a message
+func (p *printer) result(result *models.SendMessageResult) error {
+	if p.json {
+		return p.printJSON(result)
+	}
+	if result.Message != nil {
+		fmt.Fprintf(p.w, "%s: %s\n", result.Message.Role, partsText(result.Message.Parts))
+		return nil
+	}
+	return p.task(result.Task)
+}
+
+// task prints a task with its status, artifacts and history
+func (p *printer) task(task *models.Task) error {
+	if p.json {
+		return p.printJSON(task)
+	}
+	fmt.Fprintf(p.w, "Task %s: %s\n", task.ID, task.Status.State)
+	if task.Status.Message != nil {
+		fmt.Fprintf(p.w, "  %s\n", partsText(task.Status.Message.Parts))
+	}
+	for i, artifact := range task.Artifacts {
+		fmt.Fprintf(p.w, "Artifact %s:\n  %s\n", artifactName(artifact, i), partsText(artifact.Parts))
+	}
+	if len(task.History) > 0 {
+		fmt.Fprintln(p.w, "History:")
+		for _, message := range task.History {
+			fmt.Fprintf(p.w, "  %s: %s\n", message.Role, partsText(message.Parts))
+		}
+	}
+	return nil
+}
+
+// event prints a streamed status or artifact update as it arrives. Appended artifact chunks
+// are printed on one line, so streamed tokens read as text.
+func (p *printer) event(event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// One event per line, as a stream of JSON values
+	if p.json {
+		_, err = fmt.Fprintf(p.w, "%s\n", data)
+		return err
+	}
+
+	if fields, ok := event.(map[string]interface{}); ok && fields["artifact"] != nil {
+		var update models.TaskArtifactUpdateEvent
+		if err := models.DecodeJSON(data, &update); err != nil {
+			return fmt.Errorf("failed to decode artifact update: %w", err)
+		}
+		artifact := update.Artifact
+		if !p.chunking || artifact.Append == nil || !*artifact.Append {
+			p.endChunks()
+			fmt.Fprintf(p.w, "Artifact %s: ", artifactName(artifact, 0))
+		}
+		fmt.Fprint(p.w, partsText(artifact.Parts))
+		p.chunking = true
+		if artifact.LastChunk == nil || *artifact.LastChunk {
+			p.endChunks()
+		}
+		return nil
+	}
+
+	var update models.TaskStatusUpdateEvent
+	if err := models.DecodeJSON(data, &update); err != nil {
+		return fmt.Errorf("failed to decode status update: %w", err)
+	}
+	p.endChunks()
+	fmt.Fprintf(p.w, "Task %s: %s", update.ID, update.Status.State)
+	if update.Status.Message != nil {
+		fmt.Fprintf(p.w, ": %s", partsText(update.Status.Message.Parts))
+	}
+	fmt.Fprintln(p.w)
+	return nil
+}
+
+// endChunks ends the line of an artifact's chunks
+func (p *printer) endChunks() {
+	if p.chunking {
+		fmt.Fprintln(p.w)
+		p.chunking = false
+	}
+}
+
+// printJSON writes v as indented JSON
+func (p *printer) printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(p.w, "%s\n", data)
+	return err
+}
+
+// artifactName returns the name of artifact, or its index when it has none
+func artifactName(artifact models.Artifact, i int) string {
+	if artifact.Name != nil {
+		return *artifact.Name
+	}
+	if artifact.Index != nil {
+		i = *artifact.Index
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+// partsText renders parts as text: text as is, files by name or URI and data as JSON
+func partsText(parts []models.Part) string {
+	var text []string
+	for _, part := range parts {
+		switch part := part.(type) {
+		case models.TextPart:
+			text = append(text, part.Text)
+		case models.FilePart:
+			name := part.FileName
+			if uri, ok := part.Content.(models.FileContentURI); ok {
+				name = uri.URI
+			}
+			text = append(text, fmt.Sprintf("[file %s %s]", name, part.MimeType))
+		case models.DataPart:
+			data, _ := json.Marshal(part.Data)
+			text = append(text, string(data))
+		}
+	}
+	return strings.Join(text, " ")
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"a2a/models"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// project describes the agent being scaffolded
+type project struct {
+	Name        string
+	Description string
+	Module      string
+	// A2APath is the a2a module directory relative to the project, for its replace directive
+	A2APath string
+	Skills  []skillStub
+}
+
+// skillStub is a skill whose handler is generated
+type skillStub struct {
+	ID   string
+	Name string
+	// Func is the name of the generated handler constructor
+	Func string
+}
+
+// newProject validates the generator inputs
+func newProject(name, description, module string, skillIDs []string) (*project, error) {
+	p := &project{Name: name, Description: description, Module: module}
+	if p.Description == "" {
+		p.Description = name + " built with the A2A protocol"
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range skillIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if slug(id) != id {
+			return nil, fmt.Errorf("skill ID %q must be lowercase letters, digits and hyphens", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate skill ID %q", id)
+		}
+		seen[id] = true
+		p.Skills = append(p.Skills, skillStub{ID: id, Name: titleWords(id), Func: "handle" + strings.ReplaceAll(titleWords(id), " ", "")})
+	}
+	if len(p.Skills) == 0 {
+		return nil, errors.New("at least one skill is required")
+	}
+	return p, nil
+}
+
+// card returns the agent card written to agent.json
+func (p *project) card() models.AgentCard {
+	streaming := true
+	card := models.AgentCard{
+		Name:               p.Name,
+		Description:        &p.Description,
+		URL:                "http://localhost:8080/a2a",
+		Version:            "0.1.0",
+		Capabilities:       models.AgentCapabilities{Streaming: &streaming},
+		DefaultInputModes:  []string{"text"},
+		DefaultOutputModes: []string{"text"},
+		ProtocolVersion:    models.ProtocolVersion,
+		PreferredTransport: models.TransportJSONRPC,
+	}
+	for _, s := range p.Skills {
+		description := "TODO: describe the " + s.ID + " skill"
+		card.Skills = append(card.Skills, models.AgentSkill{ID: s.ID, Name: s.Name, Description: &description})
+	}
+	return card
+}
+
+// generate writes the project into out, which must not exist or be empty, building against
+// the a2a module in a2aDir
+func (p *project) generate(out, a2aDir string) error {
+	if entries, err := os.ReadDir(out); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s is not empty", out)
+	}
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	absA2A, err := filepath.Abs(a2aDir)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(absOut, absA2A)
+	if err != nil {
+		return err
+	}
+	p.A2APath = filepath.ToSlash(rel)
+	if !strings.HasPrefix(p.A2APath, ".") {
+		p.A2APath = "./" + p.A2APath
+	}
+
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+
+	card, err := json.MarshalIndent(p.card(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, "agent.json"), append(card, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	names, err := templates.ReadDir("templates")
+	if err != nil {
+		return err
+	}
+	for _, entry := range names {
+		if err := p.render(out, entry.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render executes the template name into the file of the same name without .tmpl,
+// formatting Go sources
+func (p *project) render(out, name string) error {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	target := strings.TrimSuffix(name, ".tmpl")
+	content := buf.Bytes()
+	if strings.HasSuffix(target, ".go") {
+		if content, err = format.Source(content); err != nil {
+			return fmt.Errorf("failed to format %s: %w", target, err)
+		}
+	}
+	return os.WriteFile(filepath.Join(out, target), content, 0o644)
+}
+
+// findA2AModule walks up from the working directory to the root of the a2a module
+func findA2AModule() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			if strings.HasPrefix(string(data), "module a2a\n") {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("no go.mod declaring module a2a above the working directory")
+		}
+		dir = parent
+	}
+}
+
+// slug lowercases s and joins its words with hyphens
+func slug(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
+
+// titleWords turns a skill ID such as "weather-forecast" into "Weather Forecast"
+func titleWords(id string) string {
+	words := strings.Split(id, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestGenerate(t *testing.T) {
+	p, err := newProject("Weather Agent", "", "example.com/weather", []string{"forecast", "severe-alerts"})
+	if err != nil {
+		t.Fatalf("newProject failed: %v", err)
+	}
+	a2aDir, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "weather")
+	if err := p.generate(out, a2aDir); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	for _, name := range []string{"go.mod", "agent.json", "main.go", "skills.go", "provider.go", "skills_test.go", "Makefile", "README.md"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("Expected %s to be generated: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "agent.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var card models.AgentCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		t.Fatalf("Generated agent card is invalid: %v", err)
+	}
+	if card.Name != "Weather Agent" || len(card.Skills) != 2 || card.Skills[1].ID != "severe-alerts" {
+		t.Errorf("Unexpected agent card: %+v", card)
+	}
+
+	skills, _ := os.ReadFile(filepath.Join(out, "skills.go"))
+	if !strings.Contains(string(skills), "func handleSevereAlerts(") {
+		t.Errorf("Expected a handler for severe-alerts, got:\n%s", skills)
+	}
+	if !strings.Contains(string(skills), "server.EmitArtifact(") {
+		t.Errorf("Expected the handlers to stream their replies, got:\n%s", skills)
+	}
+
+	if err := p.generate(out, a2aDir); err == nil {
+		t.Error("Expected generating into a non-empty directory to fail")
+	}
+
+	if testing.Short() {
+		return
+	}
+	// The scaffold must build and pass its own tests against this module
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = out
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet failed on the generated project: %v\n%s", err, output)
+	}
+	cmd = exec.Command("go", "test", "./...")
+	cmd.Dir = out
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Generated tests failed: %v\n%s", err, output)
+	}
+}
+
+func TestNewProject_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		skills []string
+	}{
+		{"no skills", []string{" ", ""}},
+		{"invalid skill ID", []string{"Forecast Now"}},
+		{"duplicate skill ID", []string{"forecast", "forecast"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newProject("Agent", "", "agent", tt.skills); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Command a2agen scaffolds a new A2A agent project: an agent card, skill handler stubs streaming
+// replies from an Ollama provider, tests and a Makefile, ready to run with `make run`.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	name := flag.String("name", "", "agent name, e.g. \"Weather Agent\" (required)")
+	description := flag.String("description", "", "agent description")
+	skills := flag.String("skills", "chat", "comma-separated skill IDs, e.g. forecast,alerts")
+	out := flag.String("out", "", "directory to create the project in (default: the agent name as a slug)")
+	module := flag.String("module", "", "Go module path of the project (default: the directory name)")
+	a2aDir := flag.String("a2a", "", "path of the a2a module the project builds against (default: found from the working directory)")
+	flag.Parse()
+
+	if *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = slug(*name)
+	}
+	if *module == "" {
+		*module = filepath.Base(*out)
+	}
+	if *a2aDir == "" {
+		dir, err := findA2AModule()
+		if err != nil {
+			log.Fatal("Failed to locate the a2a module, pass -a2a: ", err)
+		}
+		*a2aDir = dir
+	}
+
+	p, err := newProject(*name, *description, *module, strings.Split(*skills, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := p.generate(*out, *a2aDir); err != nil {
+		log.Fatal("Failed to generate project: ", err)
+	}
+	log.Printf("Created %s in %s; run it with: cd %s && make run", p.Name, *out, *out)
+}
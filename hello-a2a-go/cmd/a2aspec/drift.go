@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// drift lists the properties of the spec's object definitions that the hand-written struct
+// of the same name lacks, as "Type.property" in order
+func drift(sp *spec, p *pkg) ([]string, error) {
+	var missing []string
+	for _, name := range sp.names() {
+		st, ok := p.structs[name]
+		def := sp.definitions[name]
+		if !ok || !def.isObject() {
+			continue
+		}
+		props, _, err := sp.properties(def)
+		if err != nil {
+			return nil, fmt.Errorf("definition %s: %w", name, err)
+		}
+		fields := p.jsonFields(st, 0)
+		for _, prop := range sortedKeys(props) {
+			if !fields[prop] {
+				missing = append(missing, name+"."+prop)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// jsonFields returns the JSON names of the fields of st, including those of the hand-written
+// structs it embeds
+func (p *pkg) jsonFields(st *ast.StructType, depth int) map[string]bool {
+	fields := make(map[string]bool)
+	for _, field := range st.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag, _, _ = strings.Cut(reflect.StructTag(raw).Get("json"), ",")
+		}
+		if tag == "-" {
+			continue
+		}
+		if len(field.Names) == 0 && tag == "" {
+			// An embedded struct's fields are promoted into the JSON object
+			if ident, ok := field.Type.(*ast.Ident); ok && p.structs[ident.Name] != nil && depth < 8 {
+				for name := range p.jsonFields(p.structs[ident.Name], depth+1) {
+					fields[name] = true
+				}
+			}
+			continue
+		}
+		if tag != "" {
+			fields[tag] = true
+			continue
+		}
+		for _, name := range field.Names {
+			fields[name.Name] = true
+		}
+	}
+	return fields
+}
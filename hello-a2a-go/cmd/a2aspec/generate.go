@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// initialisms are the words written in capitals in Go names
+var initialisms = map[string]bool{
+	"api": true, "http": true, "https": true, "id": true, "json": true, "jsonrpc": true, "jwt": true,
+	"jwks": true, "mime": true, "rpc": true, "sse": true, "tls": true, "uri": true, "url": true,
+}
+
+// goName turns a JSON name such as "contextId" or "input-required" into a Go name such as
+// "ContextID" or "InputRequired"
+func goName(name string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if lower := strings.ToLower(w); initialisms[lower] {
+			b.WriteString(strings.ToUpper(w))
+		} else {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// pkg describes the hand-written declarations of the package the types are generated into
+type pkg struct {
+	name string
+	// declared holds the top-level names declared by hand
+	declared map[string]bool
+	// structs holds the hand-written struct types
+	structs map[string]*ast.StructType
+}
+
+// loadPackage parses the Go files of dir, except tests and the generated file out
+func loadPackage(dir, out string) (*pkg, error) {
+	p := &pkg{declared: make(map[string]bool), structs: make(map[string]*ast.StructType)}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == filepath.Base(out) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		p.name = file.Name.Name
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					p.declared[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						p.declared[spec.Name.Name] = true
+						if st, ok := spec.Type.(*ast.StructType); ok {
+							p.structs[spec.Name.Name] = st
+						}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							p.declared[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// generator writes the Go declarations of a spec's definitions
+type generator struct {
+	spec *spec
+	pkg  *pkg
+	buf  bytes.Buffer
+}
+
+// generate returns the formatted Go source declaring the definitions of sp not declared by hand
+// in p, in package name
+func generate(sp *spec, p *pkg, name string) ([]byte, error) {
+	g := &generator{spec: sp, pkg: p}
+	fmt.Fprintf(&g.buf, "// Code generated by a2aspec from %s; DO NOT EDIT.\n\npackage %s\n", sp.source, name)
+	for _, def := range sp.names() {
+		if p.declared[def] {
+			continue
+		}
+		if err := g.declare(def, sp.definitions[def]); err != nil {
+			return nil, fmt.Errorf("definition %s: %w", def, err)
+		}
+	}
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go: %w", err)
+	}
+	return src, nil
+}
+
+// declare writes the declaration of the definition name
+func (g *generator) declare(name string, s *schema) error {
+	g.buf.WriteString("\n")
+	g.comment("", typeDoc(name, s.Description))
+	switch {
+	case s.isObject():
+		return g.declareStruct(name, s)
+	case len(s.union()) > 0:
+		alternatives, err := g.alternatives(s)
+		if err != nil {
+			return err
+		}
+		g.comment("", fmt.Sprintf("It is one of %s; the generator leaves decoding it to hand-written code.", alternatives))
+		fmt.Fprintf(&g.buf, "type %s interface{}\n", name)
+		return nil
+	case s.is("string") && len(s.Enum) > 0:
+		fmt.Fprintf(&g.buf, "type %s string\n\nconst (\n", name)
+		for _, value := range s.Enum {
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("enum value %v is not a string", value)
+			}
+			constant := name + goName(text)
+			if g.pkg.declared[constant] {
+				continue
+			}
+			fmt.Fprintf(&g.buf, "\t%s %s = %q\n", constant, name, text)
+		}
+		g.buf.WriteString(")\n")
+		return nil
+	}
+	typ, err := g.goType(s, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.buf, "type %s %s\n", name, typ)
+	return nil
+}
+
+// declareStruct writes an object definition as a struct
+func (g *generator) declareStruct(name string, s *schema) error {
+	props, required, err := g.spec.properties(s)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	for _, prop := range sortedKeys(props) {
+		typ, err := g.goType(props[prop], required[prop])
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		g.comment("\t", props[prop].Description)
+		fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", goName(prop), typ, tag)
+	}
+	g.buf.WriteString("}\n")
+	return nil
+}
+
+// goType returns the Go type of a value described by s: optional booleans, numbers and
+// objects are pointers, so that their zero values can be sent
+func (g *generator) goType(s *schema, required bool) (string, error) {
+	optional := func(typ string) string {
+		if required {
+			return typ
+		}
+		return "*" + typ
+	}
+	switch {
+	case s.Ref != "":
+		name, def, err := g.spec.resolve(s.Ref)
+		if err != nil {
+			return "", err
+		}
+		if def.isObject() || def.is("boolean") || def.is("integer") || def.is("number") {
+			return optional(name), nil
+		}
+		return name, nil
+	case len(s.union()) > 0 || len(s.AllOf) > 0:
+		return "interface{}", nil
+	case s.Const != nil:
+		return constType(s.Const), nil
+	case s.is("string"):
+		return "string", nil
+	case s.is("boolean"):
+		return optional("bool"), nil
+	case s.is("integer"):
+		return optional("int"), nil
+	case s.is("number"):
+		return optional("float64"), nil
+	case s.is("array"):
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+		item, err := g.goType(s.Items, true)
+		return "[]" + item, err
+	case s.is("object"):
+		var additional schema
+		if json.Unmarshal(s.AdditionalProperties, &additional) == nil && !reflect.DeepEqual(additional, schema{}) {
+			value, err := g.goType(&additional, true)
+			return "map[string]" + value, err
+		}
+		return "map[string]interface{}", nil
+	}
+	return "interface{}", nil
+}
+
+// alternatives names the alternatives of a union schema for its doc comment
+func (g *generator) alternatives(s *schema) (string, error) {
+	var names []string
+	for _, alternative := range s.union() {
+		if alternative.Ref == "" {
+			typ, err := g.goType(alternative, true)
+			if err != nil {
+				return "", err
+			}
+			names = append(names, typ)
+			continue
+		}
+		name, _, err := g.spec.resolve(alternative.Ref)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// comment writes text as a doc comment indented with indent, wrapped at 100 columns
+func (g *generator) comment(indent, text string) {
+	words := strings.Fields(text)
+	line := indent + "//"
+	for _, word := range words {
+		if len(line)+1+len(word) > 100 && line != indent+"//" {
+			g.buf.WriteString(line + "\n")
+			line = indent + "//"
+		}
+		line += " " + word
+	}
+	if line != indent+"//" {
+		g.buf.WriteString(line + "\n")
+	}
+}
+
+// typeDoc returns the doc comment of the type name described by description
+func typeDoc(name, description string) string {
+	first, rest, _ := strings.Cut(strings.TrimSpace(description), " ")
+	switch {
+	case first == "":
+		return name + " is generated from the schema definition of the same name."
+	case first == "A" || first == "An" || first == "The":
+		return name + " is " + strings.ToLower(first) + " " + rest
+	case strings.HasSuffix(first, "s") && unicode.IsUpper([]rune(first)[0]):
+		return name + " " + strings.ToLower(first[:1]) + first[1:] + " " + rest
+	}
+	return name + ": " + description
+}
+
+// constType returns the Go type of a const value
+func constType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64:
+		return "float64"
+	}
+	return "interface{}"
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// handWritten declares the fixture's Part union and part of its Task by hand, as the models
+// package does
+const handWritten = `package models
+
+// Part is a part of a message, decoded by hand
+type Part interface{ GetPartType() string }
+
+type TaskIDParams struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Task struct {
+	TaskIDParams
+	Status TaskStatus ` + "`json:\"status\"`" + `
+	Ignored string ` + "`json:\"-\"`" + `
+}
+
+const TaskStateWorking TaskState = "working"
+`
+
+// loadFixture loads the test schema and a package holding handWritten
+func loadFixture(t *testing.T) (*spec, *pkg, string) {
+	t.Helper()
+	sp, err := loadSpec("testdata/schema.json")
+	if err != nil {
+		t.Fatalf("loadSpec failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(handWritten), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "spec_gen.go")
+	p, err := loadPackage(dir, out)
+	if err != nil {
+		t.Fatalf("loadPackage failed: %v", err)
+	}
+	return sp, p, out
+}
+
+func TestGenerate(t *testing.T) {
+	sp, p, out := loadFixture(t)
+	src, err := generate(sp, p, p.name)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code := string(src)
+	// Compare declarations regardless of gofmt's alignment
+	flat := strings.Join(strings.Fields(code), " ")
+
+	// The generated file completes the hand-written package
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range []string{"models.go", "spec_gen.go"} {
+		file, err := parser.ParseFile(fset, filepath.Join(filepath.Dir(out), name), nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v\n%s", name, err, code)
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("models", fset, files, nil); err != nil {
+		t.Fatalf("Generated code does not type-check: %v\n%s", err, code)
+	}
+
+	for _, want := range []string{
+		"// Code generated by a2aspec from testdata/schema.json; DO NOT EDIT.",
+		"// TaskState represents the possible states of a Task.\ntype TaskState string",
+		`TaskStateInputRequired TaskState = "input-required"`,
+		"// TaskStatus is the status of a task at a point in time.",
+		"State TaskState `json:\"state\"`",
+		"Progress *float64 `json:\"progress,omitempty\"`",
+		"Labels []string `json:\"labels,omitempty\"`",
+		"Counts map[string]int `json:\"counts,omitempty\"`",
+		// allOf merges the properties of the base
+		"File FileWithUri `json:\"file\"`",
+		"Metadata map[string]interface{} `json:\"metadata,omitempty\"`",
+		"MIMEType string `json:\"mimeType,omitempty\"`",
+		"Bytes *int `json:\"bytes,omitempty\"`",
+		"type TransportProtocol string",
+	} {
+		if !strings.Contains(flat, strings.Join(strings.Fields(want), " ")) {
+			t.Errorf("Expected the generated code to contain %q, got:\n%s", want, code)
+		}
+	}
+	for _, skipped := range []string{"type Part ", "type Task struct", "TaskStateWorking"} {
+		if strings.Contains(code, skipped) {
+			t.Errorf("Expected %q to be left to the hand-written code", skipped)
+		}
+	}
+
+	// Regenerating ignores the previously generated file
+	if p, err = loadPackage(filepath.Dir(out), out); err != nil || p.declared["TaskStatus"] {
+		t.Errorf("Expected the generated file to be skipped when reading the package, got %v", err)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	sp, p, _ := loadFixture(t)
+	missing, err := drift(sp, p)
+	if err != nil {
+		t.Fatalf("drift failed: %v", err)
+	}
+	if got := strings.Join(missing, " "); got != "Task.contextId Task.kind" {
+		t.Errorf("Expected the properties Task lacks, got %q", got)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for name, want := range map[string]string{
+		"contextId":              "ContextID",
+		"input-required":         "InputRequired",
+		"mimeType":               "MIMEType",
+		"jsonrpc":                "JSONRPC",
+		"pushNotificationConfig": "PushNotificationConfig",
+		"HTTPAuthSecurityScheme": "HTTPAuthSecurityScheme",
+		"2fa":                    "X2fa",
+	} {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadSpec_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	os.WriteFile(path, []byte(`{"type": "object"}`), 0o644)
+	if _, err := loadSpec(path); err == nil || !strings.Contains(err.Error(), "no definitions") {
+		t.Errorf("Expected a schema without definitions to be rejected, got %v", err)
+	}
+	sp := &spec{source: "test", definitions: map[string]*schema{"A": {Ref: "#/definitions/B"}}}
+	if _, err := generate(sp, &pkg{declared: map[string]bool{}}, "models"); err == nil {
+		t.Error("Expected a dangling reference to fail")
+	}
+}
@@ -0,0 +1,67 @@
+// Command a2aspec generates Go types from the A2A specification's JSON schema, for use with
+// go generate. Definitions the package already declares by hand, such as unions with custom
+// JSON encoding, are skipped, so the generated file only adds what the hand-written models lack.
+// With -check it lists instead the schema properties the hand-written structs do not have.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	source := flag.String("schema", "", "JSON schema file or http(s) URL (required)")
+	out := flag.String("out", "spec_gen.go", "Go file to generate, in the package it belongs to")
+	pkgName := flag.String("package", "", "package name of the generated file (default: that of the Go files next to it)")
+	check := flag.Bool("check", false, "list the schema properties missing from hand-written types instead of generating")
+	flag.Parse()
+
+	if *source == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	sp, err := loadSpec(*source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p, err := loadPackage(filepath.Dir(*out), *out)
+	if err != nil {
+		log.Fatal("Failed to read the package: ", err)
+	}
+
+	if *check {
+		missing, err := drift(sp, p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, property := range missing {
+			fmt.Println(property)
+		}
+		if len(missing) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	name := *pkgName
+	if name == "" {
+		name = p.name
+	}
+	if name == "" {
+		dir, err := filepath.Abs(filepath.Dir(*out))
+		if err != nil {
+			log.Fatal(err)
+		}
+		name = filepath.Base(dir)
+	}
+	src, err := generate(sp, p, name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
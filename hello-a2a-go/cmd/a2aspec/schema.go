@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// schema is the part of a JSON Schema the generator reads
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        typeList           `json:"type"`
+	Description string             `json:"description"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *schema            `json:"items"`
+	// AdditionalProperties is a boolean or a schema
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                interface{}        `json:"const"`
+	AnyOf                []*schema          `json:"anyOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	AllOf                []*schema          `json:"allOf"`
+	Definitions          map[string]*schema `json:"definitions"`
+	Defs                 map[string]*schema `json:"$defs"`
+}
+
+// typeList is the type keyword, a type name or a list of them
+type typeList []string
+
+// UnmarshalJSON accepts "string" as well as ["string", "null"]
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = typeList{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = names
+	return nil
+}
+
+// is reports whether the schema's type, ignoring "null", is name
+func (s *schema) is(name string) bool {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t == name
+		}
+	}
+	return false
+}
+
+// union returns the alternatives of an anyOf or oneOf schema
+func (s *schema) union() []*schema {
+	return append(append([]*schema(nil), s.AnyOf...), s.OneOf...)
+}
+
+// spec is a loaded schema document
+type spec struct {
+	source      string
+	definitions map[string]*schema
+}
+
+// loadSpec reads the JSON Schema at source, a file path or an http(s) URL
+func loadSpec(source string) (*spec, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = fetch(source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	var root schema
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", source, err)
+	}
+	definitions := root.Definitions
+	if len(definitions) == 0 {
+		definitions = root.Defs
+	}
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("schema %s has no definitions", source)
+	}
+	return &spec{source: source, definitions: definitions}, nil
+}
+
+// fetch downloads url
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// names returns the definition names in order
+func (sp *spec) names() []string {
+	names := make([]string, 0, len(sp.definitions))
+	for name := range sp.definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve returns the name and schema of the definition ref points to
+func (sp *spec) resolve(ref string) (string, *schema, error) {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if !strings.HasPrefix(ref, "#/definitions/") && !strings.HasPrefix(ref, "#/$defs/") {
+		return "", nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	def := sp.definitions[name]
+	if def == nil {
+		return "", nil, fmt.Errorf("reference %q names no definition", ref)
+	}
+	return name, def, nil
+}
+
+// properties returns the properties and required property names of an object schema, merging
+// those of the schemas it combines with allOf
+func (sp *spec) properties(s *schema) (map[string]*schema, map[string]bool, error) {
+	props, required := make(map[string]*schema), make(map[string]bool)
+	var merge func(s *schema, depth int) error
+	merge = func(s *schema, depth int) error {
+		if depth > 32 {
+			return fmt.Errorf("allOf references nest too deeply")
+		}
+		if s.Ref != "" {
+			_, def, err := sp.resolve(s.Ref)
+			if err != nil {
+				return err
+			}
+			return merge(def, depth+1)
+		}
+		for _, part := range s.AllOf {
+			if err := merge(part, depth+1); err != nil {
+				return err
+			}
+		}
+		for name, prop := range s.Properties {
+			props[name] = prop
+		}
+		for _, name := range s.Required {
+			required[name] = true
+		}
+		return nil
+	}
+	err := merge(s, 0)
+	return props, required, err
+}
+
+// isObject reports whether s describes an object with properties, directly or through allOf
+func (s *schema) isObject() bool {
+	return len(s.Properties) > 0 || len(s.AllOf) > 0
+}
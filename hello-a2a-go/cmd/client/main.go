@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"time"
+	"os"
 
 	"a2a/client"
 	"a2a/models"
+	"a2a/registry"
+	"a2a/trace"
 )
 
 func stringPtr(s string) *string {
@@ -15,8 +19,51 @@ func stringPtr(s string) *string {
 }
 
 func main() {
-	// Create A2A client
-	a2aClient := client.NewClient("http://localhost:8080/a2a")
+	// Log a span per request, handler and model call when A2A_TRACE=log
+	if os.Getenv("A2A_TRACE") == "log" {
+		trace.SetTracer(trace.NewTracer(trace.LogSpans(nil)))
+	}
+
+	// Create A2A client, retrying transient failures such as a busy or restarting agent and
+	// signing requests when a shared secret is configured
+	opts := []client.Option{client.WithRetryPolicy(client.DefaultRetryPolicy())}
+	if secret := os.Getenv("A2A_SHARED_SECRET"); secret != "" {
+		opts = append(opts, client.WithSigningSecret([]byte(secret)))
+	}
+	if token := os.Getenv("A2A_BEARER_TOKEN"); token != "" {
+		opts = append(opts, client.WithAuth(client.StaticTokenSource(token)))
+	}
+	if key := os.Getenv("A2A_API_KEY"); key != "" {
+		opts = append(opts, client.WithAPIKey(key))
+	}
+	// Trust the CA certificates in A2A_TLS_CA and present A2A_TLS_CERT and A2A_TLS_KEY to agents
+	// requiring mutual TLS
+	if ca, cert, key := os.Getenv("A2A_TLS_CA"), os.Getenv("A2A_TLS_CERT"), os.Getenv("A2A_TLS_KEY"); ca != "" || cert != "" {
+		tlsConfig, err := client.LoadTLSConfig(ca, cert, key)
+		if err != nil {
+			log.Fatalf("Failed to load TLS configuration: %v", err)
+		}
+		opts = append(opts, client.WithTLSConfig(tlsConfig))
+	}
+	serverURL := "http://localhost:8080/a2a"
+	if url := os.Getenv("A2A_SERVER_URL"); url != "" {
+		serverURL = url // e.g. unix:///tmp/a2a.sock/a2a
+	} else if url := os.Getenv("A2A_REGISTRY_URL"); url != "" {
+		// Discover an agent with the translation skill
+		card, err := registry.NewRegistry(url).FindOne(context.Background(), "translation")
+		if err != nil {
+			log.Fatalf("Failed to discover a translation agent: %v", err)
+		}
+		serverURL = card.URL
+		log.Printf("Discovered %s at %s", card.Name, card.URL)
+	}
+	a2aClient := client.NewClient(serverURL, opts...)
+	// Send requests to the endpoint the agent card declares; without a card, to serverURL
+	if card, err := a2aClient.Discover(context.Background()); err != nil {
+		log.Printf("Failed to discover the agent, using %s: %v", serverURL, err)
+	} else {
+		log.Printf("Discovered %s at %s", card.Name, card.URL)
+	}
 
 	// Test messages in different languages
 	testMessages := []string{
@@ -28,63 +75,71 @@ func main() {
 	}
 
 	fmt.Println("=== A2A Translation Client Test ===")
-	fmt.Println("Testing translation using Ollama qwen3:8b model\n")
+	fmt.Println("Testing translation using Ollama qwen3:8b model")
+	fmt.Println()
 
+	// Send every translation in one JSON-RPC batch, a single HTTP round trip
+	requests := make([]models.JSONRPCRequest, len(testMessages))
 	for i, text := range testMessages {
 		taskID := fmt.Sprintf("translation-task-%d", i+1)
-
-		fmt.Printf("Test %d: Translating '%s'\n", i+1, text)
-
-		// Create message
-		message := models.Message{
-			Role: "user",
-			Parts: []models.Part{
-				models.TextPart{
-					Type: "text",
-					Text: text,
+		requests[i] = models.JSONRPCRequest{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: taskID + "-request"},
+			},
+			Method: "message/send",
+			Params: models.MessageSendParams{
+				ID: taskID,
+				Message: models.Message{
+					Role:  "user",
+					Parts: []models.Part{models.NewTextPart(text)},
 				},
 			},
 		}
+	}
 
-		// Send task
-		response, err := a2aClient.SendTask(models.TaskSendParams{
-			ID:      taskID,
-			Message: message,
-		})
-		if err != nil {
-			log.Printf("Failed to send task %s: %v\n", taskID, err)
-			continue
-		}
-
-		// Check if we got a task response
-		if response.Result == nil {
-			log.Printf("No result in response for task %s\n", taskID)
-			continue
-		}
+	responses, err := a2aClient.SendBatch(context.Background(), requests...)
+	if err != nil {
+		log.Fatalf("Failed to send translation batch: %v", err)
+	}
 
-		// Try to get the task as a Task object
-		taskData, err := json.Marshal(response.Result)
-		if err != nil {
-			log.Printf("Failed to marshal task result: %v\n", err)
-			continue
-		}
+	for i, response := range responses {
+		fmt.Printf("Test %d: Translating '%s'\n", i+1, testMessages[i])
 
 		var task models.Task
-		if err := json.Unmarshal(taskData, &task); err != nil {
-			log.Printf("Failed to unmarshal task: %v\n", err)
+		if err := response.Decode(&task); err != nil {
+			log.Printf("Failed to translate with request %v: %v\n", response.ID, err)
+			var a2aErr *models.A2AError
+			if errors.As(err, &a2aErr) {
+				if data, ok := a2aErr.Data.(map[string]interface{}); ok && data["hint"] != nil {
+					fmt.Printf("Hint: %v\n", data["hint"])
+				}
+			}
 			continue
 		}
 
 		fmt.Printf("Task Status: %s\n", task.Status.State)
 
 		if task.Status.State == models.TaskStateCompleted {
-			fmt.Printf("Translation completed successfully! (Check server logs for result)\n")
+			fmt.Printf("Translation completed successfully!\n")
+			for _, artifact := range task.Artifacts {
+				for _, part := range artifact.Parts {
+					if textPart, ok := part.(models.TextPart); ok {
+						fmt.Printf("Result: %s\n", textPart.Text)
+					}
+				}
+			}
 		} else if task.Status.State == models.TaskStateFailed {
 			fmt.Printf("Translation failed!\n")
+			if details, ok := task.Status.ErrorDetails(); ok {
+				fmt.Printf("Reason: %s (code %d)\n", details.Message, details.Code)
+				if details.Hint != "" {
+					fmt.Printf("Hint: %s\n", details.Hint)
+				}
+			}
 		}
 
 		fmt.Println("---")
-		time.Sleep(1 * time.Second) // Small delay between requests
 	}
 
 	// Test streaming functionality
@@ -92,13 +147,8 @@ func main() {
 
 	streamingTaskID := "streaming-translation-task"
 	streamingMessage := models.Message{
-		Role: "user",
-		Parts: []models.Part{
-			models.TextPart{
-				Type: "text",
-				Text: "请将这段中文翻译成英文：今天天气真好！",
-			},
-		},
+		Role:  "user",
+		Parts: []models.Part{models.NewTextPart("请将这段中文翻译成英文：今天天气真好！")},
 	}
 
 	// Create channel for streaming events
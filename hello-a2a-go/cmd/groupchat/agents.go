@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"a2a/client"
+	"a2a/models"
+	"a2a/server"
+)
+
+// specialist describes an agent taking one turn in the group chat
+type specialist struct {
+	skill models.AgentSkill
+	// instruction is prepended to the text the specialist is given
+	instruction string
+}
+
+// specialists take their turns in order, each given the previous turn's output
+var specialists = []specialist{
+	{
+		skill:       models.AgentSkill{ID: "translate", Name: "Translator", Tags: []string{"translation"}},
+		instruction: "Translate the following text to English. Reply with the translation only.",
+	},
+	{
+		skill:       models.AgentSkill{ID: "summarize", Name: "Summarizer", Tags: []string{"summarization"}},
+		instruction: "Summarize the following text in one or two sentences.",
+	},
+	{
+		skill:       models.AgentSkill{ID: "critique", Name: "Critic", Tags: []string{"review"}},
+		instruction: "Briefly critique the following summary: what is missing or unclear?",
+	},
+}
+
+// chunkThrottle coalesces token-by-token model output into readable transcript chunks
+var chunkThrottle = server.StreamThrottle{MaxEventsPerSecond: 20, MinChunkBytes: 16}
+
+// newSpecialist builds the agent serving spec with gen, advertised at url
+func newSpecialist(spec specialist, gen generator, url string, opts ...server.Option) (*server.Agent, error) {
+	return server.NewAgent().
+		Named(spec.skill.Name).
+		WithDescription(spec.instruction).
+		WithURL(url).
+		WithSkill(spec.skill, specialistHandler(spec, gen), server.WithThrottle(chunkThrottle)).
+		WithOptions(opts...).
+		Build()
+}
+
+// specialistHandler completes the instruction of spec for the message text, streaming the
+// output as an artifact named after the specialist
+func specialistHandler(spec specialist, gen generator) server.TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		input := message.Text()
+		if input == "" {
+			task.Status.State = models.TaskStateFailed
+			return task, errors.New("no text found in message")
+		}
+
+		var output strings.Builder
+		index, started := 0, false
+		err := gen(ctx, spec.instruction+"\n\n"+input, func(token string) {
+			output.WriteString(token)
+			server.EmitArtifact(ctx, models.Artifact{
+				Name:   &spec.skill.Name,
+				Parts:  []models.Part{models.NewTextPart(token)},
+				Index:  &index,
+				Append: boolPtr(started),
+			})
+			started = true
+		})
+		if err != nil {
+			task.Status.State = models.TaskStateFailed
+			return task, fmt.Errorf("%s failed: %w", spec.skill.ID, err)
+		}
+
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart(output.String())}}
+		return task, nil
+	}
+}
+
+// registry locates agents by the skills advertised on their agent cards
+type registry struct {
+	agents map[string]*client.Client
+}
+
+// discover fetches the agent cards served at baseURLs and indexes their skills
+func discover(baseURLs []string, opts ...client.Option) (*registry, error) {
+	r := &registry{agents: make(map[string]*client.Client)}
+	for _, baseURL := range baseURLs {
+		card, err := client.NewClient(baseURL, opts...).GetAgentCard()
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover agent at %s: %w", baseURL, err)
+		}
+		c := client.NewClient(card.URL, opts...)
+		for _, skill := range card.Skills {
+			r.agents[skill.ID] = c
+		}
+	}
+	return r, nil
+}
+
+// delegate sends text to the agent serving skillID as a task of the conversation contextID,
+// passing streamed output to emit, and returns the full output
+func (r *registry) delegate(ctx context.Context, skillID, taskID, contextID, text string, emit func(token string)) (string, error) {
+	agent, ok := r.agents[skillID]
+	if !ok {
+		return "", fmt.Errorf("no agent serves skill %s", skillID)
+	}
+
+	params := models.MessageSendParams{
+		ID: taskID,
+		Message: models.Message{
+			Role:      "user",
+			Parts:     []models.Part{models.NewTextPart(text)},
+			ContextID: contextID,
+		},
+		Metadata: map[string]interface{}{server.SkillMetadataKey: skillID},
+	}
+	var output strings.Builder
+	state, err := stream(ctx, agent, params, func(artifact models.Artifact) {
+		for _, part := range artifact.Parts {
+			if text, ok := part.(models.TextPart); ok {
+				output.WriteString(text.Text)
+				emit(text.Text)
+			}
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to delegate to %s: %w", skillID, err)
+	}
+	if state != models.TaskStateCompleted {
+		return "", fmt.Errorf("%s did not complete the task: %s", skillID, state)
+	}
+	return output.String(), nil
+}
+
+// streamUpdate is a streamed status or artifact update event
+type streamUpdate struct {
+	models.TaskArtifactUpdateEvent
+	Status *models.TaskStatus `json:"status"`
+}
+
+// stream sends params to agent with message/stream, passing each streamed artifact to
+// onArtifact, and returns the last task state reported
+func stream(ctx context.Context, agent *client.Client, params models.MessageSendParams, onArtifact func(models.Artifact)) (models.TaskState, error) {
+	events := make(chan interface{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- agent.SendMessageStreamingContext(ctx, params, events)
+		close(events)
+	}()
+
+	var state models.TaskState
+	for event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		var update streamUpdate
+		if err := json.Unmarshal(data, &update); err != nil {
+			continue
+		}
+		if update.Status != nil {
+			state = update.Status.State
+			continue
+		}
+		onArtifact(update.Artifact)
+	}
+	return state, <-errc
+}
+
+// hostHandler runs the group chat: each specialist in turn responds to the previous turn,
+// and their output is streamed as one artifact per turn named after the speaker
+func hostHandler(r *registry) server.TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		input := message.Text()
+		if input == "" {
+			task.Status.State = models.TaskStateFailed
+			return task, errors.New("no text found in message")
+		}
+
+		for i, spec := range specialists {
+			index, started := i, false
+			emit := func(token string) {
+				server.EmitArtifact(ctx, models.Artifact{
+					Name:   &spec.skill.Name,
+					Parts:  []models.Part{models.NewTextPart(token)},
+					Index:  &index,
+					Append: boolPtr(started),
+				})
+				started = true
+			}
+			output, err := r.delegate(ctx, spec.skill.ID, task.ID+"-"+spec.skill.ID, task.ContextID, input, emit)
+			if err != nil {
+				task.Status.State = models.TaskStateFailed
+				return task, err
+			}
+			// Close the turn so the transcript ends the speaker's line
+			server.EmitArtifact(ctx, models.Artifact{Name: &spec.skill.Name, Index: &index, Append: boolPtr(true), LastChunk: boolPtr(true)})
+			input = output
+		}
+
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart(input)}}
+		return task, nil
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
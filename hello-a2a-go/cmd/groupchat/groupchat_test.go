@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"a2a/client"
+	"a2a/models"
+)
+
+// echoGenerator answers with the first word of the instruction followed by the input, one
+// character per token
+func echoGenerator(ctx context.Context, prompt string, emit func(token string)) error {
+	instruction, input, _ := strings.Cut(prompt, "\n\n")
+	if strings.Contains(input, "fail") {
+		return errors.New("model unavailable")
+	}
+	for _, r := range strings.Fields(instruction)[0] + " " + input {
+		emit(string(r))
+	}
+	return nil
+}
+
+func TestGroupChat(t *testing.T) {
+	chat, err := startGroupChat(echoGenerator)
+	if err != nil {
+		t.Fatalf("Failed to start group chat: %v", err)
+	}
+	defer chat.Close()
+	host := client.NewClient(chat.URL)
+
+	var transcript strings.Builder
+	if err := runTranscript(context.Background(), host, "chat-1", "ctx-1", "hello world", &transcript); err != nil {
+		t.Fatalf("Group chat failed: %v", err)
+	}
+	want := "[Translator] Translate hello world\n" +
+		"[Summarizer] Summarize Translate hello world\n" +
+		"[Critic] Briefly Summarize Translate hello world\n"
+	if transcript.String() != want {
+		t.Errorf("Unexpected transcript:\n%s\nwant:\n%s", transcript.String(), want)
+	}
+
+	// Every delegated task joins the host's conversation
+	for i, spec := range specialists {
+		resp, err := http.Get(chat.specialistURLs[i] + "/v1/tasks/chat-1-" + spec.skill.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var task models.Task
+		err = json.NewDecoder(resp.Body).Decode(&task)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode %s task: %v", spec.skill.ID, err)
+		}
+		if task.ContextID != "ctx-1" || task.Status.State != models.TaskStateCompleted {
+			t.Errorf("Expected the %s task to complete in context ctx-1, got %+v", spec.skill.ID, task)
+		}
+	}
+
+	transcript.Reset()
+	if err := runTranscript(context.Background(), host, "chat-2", "ctx-2", "please fail", &transcript); err == nil {
+		t.Error("Expected a failing specialist to fail the group chat")
+	}
+}
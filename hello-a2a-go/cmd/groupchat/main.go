@@ -0,0 +1,161 @@
+// Command groupchat runs a group chat in which a host agent coordinates translator, summarizer
+// and critic agents over A2A, streaming the interleaved transcript to the terminal. All four
+// agents run in-process on loopback ports; the specialists are backed by the model selected with
+// the -llm flags or LLM_* environment variables, a local Ollama model by default.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"a2a/client"
+	"a2a/llm"
+	"a2a/models"
+	"a2a/server"
+)
+
+// groupChat is the running host and specialist agents
+type groupChat struct {
+	// URL is the host's JSON-RPC endpoint
+	URL string
+	// specialistURLs are the base URLs of the specialists, in turn order
+	specialistURLs []string
+	servers        []*http.Server
+}
+
+// startGroupChat serves the specialists, backed by gen, and the host that discovers them
+func startGroupChat(gen generator) (*groupChat, error) {
+	g := &groupChat{}
+	for _, spec := range specialists {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			g.Close()
+			return nil, fmt.Errorf("failed to listen: %w", err)
+		}
+		baseURL := "http://" + ln.Addr().String()
+		agent, err := newSpecialist(spec, gen, baseURL+"/a2a")
+		if err != nil {
+			ln.Close()
+			g.Close()
+			return nil, fmt.Errorf("failed to build %s: %w", spec.skill.Name, err)
+		}
+		g.serve(ln, agent.Mux())
+		g.specialistURLs = append(g.specialistURLs, baseURL)
+	}
+
+	reg, err := discover(g.specialistURLs)
+	if err != nil {
+		g.Close()
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		g.Close()
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	g.URL = "http://" + ln.Addr().String() + "/a2a"
+	host, err := server.NewAgent().
+		Named("Group Chat Host").
+		WithDescription("Coordinates a translator, a summarizer and a critic").
+		WithURL(g.URL).
+		WithSkill(models.AgentSkill{ID: "groupchat", Name: "Group Chat"}, hostHandler(reg)).
+		Build()
+	if err != nil {
+		ln.Close()
+		g.Close()
+		return nil, fmt.Errorf("failed to build host: %w", err)
+	}
+	g.serve(ln, host.Mux())
+	return g, nil
+}
+
+// serve serves handler on ln until Close
+func (g *groupChat) serve(ln net.Listener, handler http.Handler) {
+	srv := &http.Server{Handler: handler}
+	g.servers = append(g.servers, srv)
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("Agent server failed: %v", err)
+		}
+	}()
+}
+
+// Close stops every agent
+func (g *groupChat) Close() {
+	for _, srv := range g.servers {
+		srv.Close()
+	}
+}
+
+// runTranscript sends topic to the host as task taskID of the conversation contextID, writing
+// each speaker's streamed turn to w as a "[Speaker] text" line
+func runTranscript(ctx context.Context, host *client.Client, taskID, contextID, topic string, w io.Writer) error {
+	params := models.MessageSendParams{
+		ID: taskID,
+		Message: models.Message{
+			Role:      "user",
+			Parts:     []models.Part{models.NewTextPart(topic)},
+			ContextID: contextID,
+		},
+	}
+	speaker := -1
+	state, err := stream(ctx, host, params, func(artifact models.Artifact) {
+		if artifact.Index != nil && *artifact.Index != speaker {
+			speaker = *artifact.Index
+			name := "Agent"
+			if artifact.Name != nil {
+				name = *artifact.Name
+			}
+			fmt.Fprintf(w, "[%s] ", name)
+		}
+		for _, part := range artifact.Parts {
+			if text, ok := part.(models.TextPart); ok {
+				fmt.Fprint(w, text.Text)
+			}
+		}
+		if artifact.LastChunk != nil && *artifact.LastChunk {
+			fmt.Fprintln(w)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if state != models.TaskStateCompleted {
+		return fmt.Errorf("group chat did not complete: %s", state)
+	}
+	return nil
+}
+
+func main() {
+	llmConfig := llm.ConfigFromEnv()
+	llmConfig.RegisterFlags(flag.CommandLine)
+	contextID := flag.String("context", "groupchat", "conversation ID shared by every agent's tasks")
+	flag.Parse()
+
+	topic := strings.Join(flag.Args(), " ")
+	if topic == "" {
+		topic = "今天天气真好，我们去公园散步，顺便讨论一下周末的计划。"
+	}
+
+	provider, err := llm.New(llmConfig)
+	if err != nil {
+		log.Fatalf("Failed to configure LLM provider: %v", err)
+	}
+	chat, err := startGroupChat(providerGenerator(provider))
+	if err != nil {
+		log.Fatalf("Failed to start group chat: %v", err)
+	}
+	defer chat.Close()
+
+	fmt.Printf("[User] %s\n", topic)
+	if err := runTranscript(context.Background(), client.NewClient(chat.URL), "groupchat-task", *contextID, topic, os.Stdout); err != nil {
+		log.Fatalf("Group chat failed: %v", err)
+	}
+}
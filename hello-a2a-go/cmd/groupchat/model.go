@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"a2a/llm"
+	"a2a/server"
+)
+
+// generator completes prompt, passing each generated token to emit as it is produced
+type generator func(ctx context.Context, prompt string, emit func(token string)) error
+
+// providerGenerator streams completions from provider, reporting the tokens consumed for
+// usage accounting
+func providerGenerator(provider llm.Provider) generator {
+	return func(ctx context.Context, prompt string, emit func(token string)) error {
+		resp, err := provider.GenerateStream(ctx, llm.Request{Prompt: prompt}, emit)
+		if err != nil {
+			return err
+		}
+		server.ReportTokens(ctx, resp.Usage.Total())
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// replayedTask is a reconstructed task with its message history
+type replayedTask struct {
+	*models.Task
+	Messages []*models.Message `json:"messages"`
+}
+
+func main() {
+	journalPath := flag.String("journal", "a2a-journal.jsonl", "path of the journal; rotated files path.1, path.2, ... are replayed first")
+	taskID := flag.String("task", "", "only print the task with this ID")
+	flag.Parse()
+
+	files := server.JournalFiles(*journalPath)
+	if len(files) == 0 {
+		log.Fatalf("No journal found at %s", *journalPath)
+	}
+
+	ctx := context.Background()
+	store := server.NewMemoryTaskStore()
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", name, err)
+		}
+		applied, err := server.ReplayJournal(ctx, file, store)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Failed to replay %s: %v", name, err)
+		}
+		log.Printf("Replayed %d events from %s", applied, name)
+	}
+
+	var tasks []replayedTask
+	for _, task := range store.Tasks() {
+		if *taskID != "" && task.ID != *taskID {
+			continue
+		}
+		messages, _ := store.Messages(ctx, task.ID)
+		tasks = append(tasks, replayedTask{Task: task, Messages: messages})
+	}
+	log.Printf("Reconstructed %d tasks", len(tasks))
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(tasks); err != nil {
+		log.Fatal("Failed to write tasks:", err)
+	}
+}
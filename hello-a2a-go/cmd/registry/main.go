@@ -0,0 +1,20 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"a2a/registry"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "address to serve the registry on")
+	ttl := flag.Duration("ttl", registry.DefaultTTL, "how long a registration lasts without a heartbeat")
+	flag.Parse()
+
+	log.Printf("Serving agent registry on %s", *addr)
+	if err := http.ListenAndServe(*addr, registry.NewServer(registry.WithTTL(*ttl))); err != nil {
+		log.Fatal("Failed to start registry:", err)
+	}
+}
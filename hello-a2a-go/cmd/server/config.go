@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2a/llm"
+	"a2a/models"
+	"a2a/server"
+)
+
+// config is the server's configuration. Each source overrides the one before: defaults, a JSON
+// or YAML file named by -config or A2A_CONFIG, environment variables and flags.
+type config struct {
+	// Listen is the TCP address to serve on
+	Listen string `json:"listen" yaml:"listen"`
+	// PublicURL is the base URL clients reach the server at, published in the agent card;
+	// empty means localhost on the listen port
+	PublicURL string            `json:"publicUrl" yaml:"publicUrl"`
+	TLS       tlsSettings       `json:"tls" yaml:"tls"`
+	LLM       llmSettings       `json:"llm" yaml:"llm"`
+	Timeouts  timeoutSettings   `json:"timeouts" yaml:"timeouts"`
+	Retention retentionSettings `json:"retention" yaml:"retention"`
+	RateLimit rateLimitSettings `json:"rateLimit" yaml:"rateLimit"`
+	Agent     agentSettings     `json:"agent" yaml:"agent"`
+	Admin     adminSettings     `json:"admin" yaml:"admin"`
+	Prompts   promptSettings    `json:"prompts" yaml:"prompts"`
+	CORS      corsSettings      `json:"cors" yaml:"cors"`
+	Memory    memorySettings    `json:"memory" yaml:"memory"`
+}
+
+// tlsSettings are PEM files for serving HTTPS, requiring client certificates issued by
+// ClientCA when it is set
+type tlsSettings struct {
+	Cert     string `json:"cert" yaml:"cert"`
+	Key      string `json:"key" yaml:"key"`
+	ClientCA string `json:"clientCA" yaml:"clientCA"`
+}
+
+// llmSettings select the model backing the agent's skills (see llm.Config)
+type llmSettings struct {
+	Provider string `json:"provider" yaml:"provider"`
+	URL      string `json:"url" yaml:"url"`
+	Model    string `json:"model" yaml:"model"`
+	APIKey   string `json:"apiKey" yaml:"apiKey"`
+}
+
+// timeoutSettings bound the work done for a request; zero means no limit, or for KeepAlive
+// the server's default interval
+type timeoutSettings struct {
+	// Handler bounds each run of a task handler
+	Handler duration `json:"handler" yaml:"handler"`
+	// Request bounds the handler runs of message/send requests
+	Request duration `json:"request" yaml:"request"`
+	// StreamIdle bounds the time a streaming task goes without an event
+	StreamIdle duration `json:"streamIdle" yaml:"streamIdle"`
+	// Model bounds each model call
+	Model duration `json:"model" yaml:"model"`
+	// KeepAlive is how often idle SSE streams send a keep-alive comment
+	KeepAlive duration `json:"keepAlive" yaml:"keepAlive"`
+}
+
+// retentionSettings purge finished tasks (see server.WithRetention); a zero TaskTTL keeps them
+// forever
+type retentionSettings struct {
+	// TaskTTL is how long completed, failed and canceled tasks are kept
+	TaskTTL duration `json:"taskTTL" yaml:"taskTTL"`
+	// Interval is how often expired tasks are purged; zero means once a minute
+	Interval duration `json:"interval" yaml:"interval"`
+}
+
+// rateLimitSettings bound each caller's JSON-RPC requests (see server.WithRateLimit); a zero
+// PerSecond disables the limit
+type rateLimitSettings struct {
+	// PerSecond is the sustained requests per second allowed to each API key or IP address
+	PerSecond float64 `json:"perSecond" yaml:"perSecond"`
+	// Burst is the most requests a caller may make at once; zero means PerSecond rounded up
+	Burst int `json:"burst" yaml:"burst"`
+}
+
+// agentSettings fill the agent card; an empty description names the configured model
+type agentSettings struct {
+	Name            string `json:"name" yaml:"name"`
+	Description     string `json:"description" yaml:"description"`
+	Version         string `json:"version" yaml:"version"`
+	Organization    string `json:"organization" yaml:"organization"`
+	OrganizationURL string `json:"organizationUrl" yaml:"organizationUrl"`
+	// SigningKey is a PEM private key file signing the agent card; empty serves it unsigned
+	SigningKey string `json:"signingKey" yaml:"signingKey"`
+}
+
+// adminSettings configure the admin API (see server.WithAdmin), which takes the admin token of
+// A2A_ADMIN_TOKEN
+type adminSettings struct {
+	// Listen is the TCP address of the admin listener; empty disables it
+	Listen string `json:"listen" yaml:"listen"`
+}
+
+// promptSettings override the translator's prompt templates with the files of a directory (see
+// prompt.Library)
+type promptSettings struct {
+	// Dir holds <name>.tmpl and <skill>/<name>.tmpl templates; empty uses the built-in prompts
+	Dir string `json:"dir" yaml:"dir"`
+	// Reload is how often the templates are checked for changes; zero reloads them only on
+	// restart
+	Reload duration `json:"reload" yaml:"reload"`
+}
+
+// corsSettings let web pages of other origins call the agent (see server.WithCORS)
+type corsSettings struct {
+	// Origins is a comma-separated list of the origins allowed, "*" for any; "none" sends no
+	// CORS headers at all, and empty allows any origin only on streamed responses
+	Origins string `json:"origins" yaml:"origins"`
+	// MaxAge is how long browsers may cache a preflight result; zero leaves it to the browser
+	MaxAge duration `json:"maxAge" yaml:"maxAge"`
+}
+
+// memorySettings keep the translator's conversation memory (see memory.Memory), which lets
+// follow-up messages revise a translation
+type memorySettings struct {
+	// Dir holds a file per conversation; empty keeps the most recent conversations in memory
+	Dir string `json:"dir" yaml:"dir"`
+	// Budget is the tokens each conversation keeps before older messages are summarized; zero
+	// means memory.DefaultBudget
+	Budget int `json:"budget" yaml:"budget"`
+}
+
+// duration is a time.Duration written as a string such as "90s" in files, the environment and
+// flags
+type duration time.Duration
+
+// UnmarshalText parses a duration such as "90s"
+func (d *duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration like time.Duration
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String formats the duration like time.Duration
+func (d duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set parses a duration flag
+func (d *duration) Set(s string) error {
+	return d.UnmarshalText([]byte(s))
+}
+
+// decodeYAML decodes YAML config files, when built with the yaml tag (see config_yaml.go)
+var decodeYAML func(data []byte, v interface{}) error
+
+// defaultConfig returns the configuration used when nothing overrides it
+func defaultConfig() config {
+	return config{
+		Listen: ":8080",
+		Agent: agentSettings{
+			Name:         "Translation Agent",
+			Version:      "1.0.0",
+			Organization: "Local Development",
+		},
+	}
+}
+
+// loadConfig registers the configuration flags on fs, parses args and returns the configuration
+// from the defaults, the config file, the environment read with getenv and the flags set
+func loadConfig(fs *flag.FlagSet, args []string, getenv func(string) string) (*config, error) {
+	cfg := defaultConfig()
+	path := fs.String("config", "", "JSON or YAML config file (env A2A_CONFIG)")
+	cfg.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	// Flags are parsed into cfg first, so remember them to apply over the file and environment
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = f.Value.String() })
+
+	if *path == "" {
+		*path = getenv("A2A_CONFIG")
+	}
+	if *path != "" {
+		if err := cfg.readFile(*path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.applyEnv(getenv); err != nil {
+		return nil, err
+	}
+	for name, value := range set {
+		fs.Set(name, value)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// registerFlags adds flags setting the fields of c to fs
+func (c *config) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Listen, "listen", c.Listen, "TCP address to serve on (env A2A_LISTEN)")
+	fs.StringVar(&c.PublicURL, "public-url", c.PublicURL, "base URL published in the agent card (env A2A_PUBLIC_URL)")
+	fs.StringVar(&c.TLS.Cert, "tls-cert", c.TLS.Cert, "TLS certificate PEM file (env A2A_TLS_CERT)")
+	fs.StringVar(&c.TLS.Key, "tls-key", c.TLS.Key, "TLS private key PEM file (env A2A_TLS_KEY)")
+	fs.StringVar(&c.TLS.ClientCA, "tls-client-ca", c.TLS.ClientCA, "CA PEM file of required client certificates (env A2A_TLS_CLIENT_CA)")
+	fs.StringVar(&c.LLM.Provider, "llm", c.LLM.Provider, "LLM provider: ollama, openai or mock (env LLM_PROVIDER)")
+	fs.StringVar(&c.LLM.URL, "llm-url", c.LLM.URL, "LLM API base URL (env LLM_BASE_URL)")
+	fs.StringVar(&c.LLM.Model, "llm-model", c.LLM.Model, "LLM model (env LLM_MODEL)")
+	fs.Var(&c.Timeouts.Handler, "handler-timeout", "limit on each task handler run, e.g. 2m (env A2A_HANDLER_TIMEOUT)")
+	fs.Var(&c.Timeouts.Request, "request-timeout", "limit on the handler run of a message/send request, e.g. 30s (env A2A_REQUEST_TIMEOUT)")
+	fs.Var(&c.Timeouts.StreamIdle, "stream-idle-timeout", "limit on the time a stream goes without an event, e.g. 1m (env A2A_STREAM_IDLE_TIMEOUT)")
+	fs.Var(&c.Timeouts.Model, "model-timeout", "limit on each model call, e.g. 90s (env A2A_MODEL_TIMEOUT)")
+	fs.Var(&c.Timeouts.KeepAlive, "keep-alive", "interval of SSE keep-alive comments (env A2A_KEEPALIVE)")
+	fs.Var(&c.Retention.TaskTTL, "task-ttl", "how long finished tasks are kept, e.g. 24h (env A2A_TASK_TTL)")
+	fs.Float64Var(&c.RateLimit.PerSecond, "rate-limit", c.RateLimit.PerSecond, "requests per second allowed to each caller (env A2A_RATE_LIMIT)")
+	fs.IntVar(&c.RateLimit.Burst, "rate-burst", c.RateLimit.Burst, "requests a caller may make at once (env A2A_RATE_BURST)")
+	fs.StringVar(&c.Agent.Name, "agent-name", c.Agent.Name, "agent name in the agent card (env A2A_AGENT_NAME)")
+	fs.StringVar(&c.Agent.SigningKey, "card-signing-key", c.Agent.SigningKey, "PEM private key file signing the agent card (env A2A_CARD_SIGNING_KEY)")
+	fs.StringVar(&c.Prompts.Dir, "prompts", c.Prompts.Dir, "directory of prompt templates overriding the built-in ones (env A2A_PROMPTS_DIR)")
+	fs.Var(&c.Prompts.Reload, "prompts-reload", "how often prompt templates are checked for changes, e.g. 5s (env A2A_PROMPTS_RELOAD)")
+	fs.StringVar(&c.CORS.Origins, "cors-origins", c.CORS.Origins, `comma-separated origins of web pages allowed to call the agent, "*" for any or "none" (env A2A_CORS_ORIGINS)`)
+	fs.Var(&c.CORS.MaxAge, "cors-max-age", "how long browsers may cache preflight results, e.g. 10m (env A2A_CORS_MAX_AGE)")
+	fs.StringVar(&c.Memory.Dir, "memory-dir", c.Memory.Dir, "directory keeping conversation memory across restarts (env A2A_MEMORY_DIR)")
+	fs.IntVar(&c.Memory.Budget, "memory-budget", c.Memory.Budget, "tokens remembered of each conversation before summarizing (env A2A_MEMORY_BUDGET)")
+	fs.StringVar(&c.Admin.Listen, "admin-listen", c.Admin.Listen, "TCP address of the admin API, e.g. 127.0.0.1:9090 (env A2A_ADMIN_LISTEN)")
+}
+
+// readFile overrides c with the settings in the config file at path, YAML for .yaml and .yml
+// files and JSON otherwise, rejecting unknown settings
+func (c *config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		if decodeYAML == nil {
+			return fmt.Errorf("cannot read %s: YAML config files need a build with -tags yaml", path)
+		}
+		err = decodeYAML(data, c)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides c with the environment variables set, read with getenv
+func (c *config) applyEnv(getenv func(string) string) error {
+	for name, field := range map[string]*string{
+		"A2A_LISTEN":            &c.Listen,
+		"A2A_PUBLIC_URL":        &c.PublicURL,
+		"A2A_TLS_CERT":          &c.TLS.Cert,
+		"A2A_TLS_KEY":           &c.TLS.Key,
+		"A2A_TLS_CLIENT_CA":     &c.TLS.ClientCA,
+		"LLM_PROVIDER":          &c.LLM.Provider,
+		"LLM_BASE_URL":          &c.LLM.URL,
+		"LLM_MODEL":             &c.LLM.Model,
+		"OPENAI_API_KEY":        &c.LLM.APIKey,
+		"A2A_AGENT_NAME":        &c.Agent.Name,
+		"A2A_AGENT_DESCRIPTION": &c.Agent.Description,
+		"A2A_AGENT_VERSION":     &c.Agent.Version,
+		"A2A_CARD_SIGNING_KEY":  &c.Agent.SigningKey,
+		"A2A_ADMIN_LISTEN":      &c.Admin.Listen,
+		"A2A_PROMPTS_DIR":       &c.Prompts.Dir,
+		"A2A_CORS_ORIGINS":      &c.CORS.Origins,
+		"A2A_MEMORY_DIR":        &c.Memory.Dir,
+	} {
+		if value := getenv(name); value != "" {
+			*field = value
+		}
+	}
+	// LLM_API_KEY takes precedence over OPENAI_API_KEY, as in llm.ConfigFromEnv
+	if key := getenv("LLM_API_KEY"); key != "" {
+		c.LLM.APIKey = key
+	}
+
+	var errs []error
+	for _, d := range []struct {
+		name  string
+		field *duration
+	}{
+		{"A2A_HANDLER_TIMEOUT", &c.Timeouts.Handler},
+		{"A2A_REQUEST_TIMEOUT", &c.Timeouts.Request},
+		{"A2A_STREAM_IDLE_TIMEOUT", &c.Timeouts.StreamIdle},
+		{"A2A_MODEL_TIMEOUT", &c.Timeouts.Model},
+		{"A2A_KEEPALIVE", &c.Timeouts.KeepAlive},
+		{"A2A_TASK_TTL", &c.Retention.TaskTTL},
+		{"A2A_PROMPTS_RELOAD", &c.Prompts.Reload},
+		{"A2A_CORS_MAX_AGE", &c.CORS.MaxAge},
+	} {
+		if value := getenv(d.name); value != "" {
+			if err := d.field.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", d.name, err))
+			}
+		}
+	}
+	if value := getenv("A2A_RATE_LIMIT"); value != "" {
+		perSecond, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("A2A_RATE_LIMIT: %w", err))
+		}
+		c.RateLimit.PerSecond = perSecond
+	}
+	if value := getenv("A2A_RATE_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("A2A_RATE_BURST: %w", err))
+		}
+		c.RateLimit.Burst = burst
+	}
+	if value := getenv("A2A_MEMORY_BUDGET"); value != "" {
+		budget, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("A2A_MEMORY_BUDGET: %w", err))
+		}
+		c.Memory.Budget = budget
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate reports every invalid setting of c
+func (c *config) validate() error {
+	var errs []error
+	if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+		errs = append(errs, fmt.Errorf("listen address %q: %w", c.Listen, err))
+	}
+	if c.Admin.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Admin.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("admin listen address %q: %w", c.Admin.Listen, err))
+		}
+	}
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("public URL %q must be an absolute http or https URL", c.PublicURL))
+		}
+	}
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		errs = append(errs, errors.New("TLS needs both a certificate and a key"))
+	}
+	if c.TLS.ClientCA != "" && c.TLS.Cert == "" {
+		errs = append(errs, errors.New("a TLS client CA needs a certificate and a key to serve HTTPS"))
+	}
+	switch c.LLM.Provider {
+	case "", llm.KindOllama, llm.KindOpenAI, llm.KindMock:
+	default:
+		errs = append(errs, fmt.Errorf("unknown LLM provider %q", c.LLM.Provider))
+	}
+	for _, d := range []struct {
+		name  string
+		value duration
+	}{
+		{"handler timeout", c.Timeouts.Handler},
+		{"request timeout", c.Timeouts.Request},
+		{"stream idle timeout", c.Timeouts.StreamIdle},
+		{"model timeout", c.Timeouts.Model},
+		{"keep-alive interval", c.Timeouts.KeepAlive},
+		{"task TTL", c.Retention.TaskTTL},
+		{"retention interval", c.Retention.Interval},
+		{"prompt reload interval", c.Prompts.Reload},
+		{"CORS max age", c.CORS.MaxAge},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s %s is negative", d.name, d.value))
+		}
+	}
+	if c.RateLimit.PerSecond < 0 || c.RateLimit.Burst < 0 {
+		errs = append(errs, fmt.Errorf("rate limit %g per second with burst %d must not be negative", c.RateLimit.PerSecond, c.RateLimit.Burst))
+	}
+	if c.Memory.Budget < 0 {
+		errs = append(errs, fmt.Errorf("memory budget %d must not be negative", c.Memory.Budget))
+	}
+	if strings.TrimSpace(c.Agent.Name) == "" {
+		errs = append(errs, errors.New("agent name is required"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid server configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// scheme returns https when c serves TLS and http otherwise
+func (c *config) scheme() string {
+	if c.TLS.Cert != "" {
+		return "https"
+	}
+	return "http"
+}
+
+// baseURL returns the public URL, or localhost on the listen port
+func (c *config) baseURL() string {
+	if c.PublicURL != "" {
+		return strings.TrimSuffix(c.PublicURL, "/")
+	}
+	_, port, _ := net.SplitHostPort(c.Listen)
+	return c.scheme() + "://localhost:" + port
+}
+
+// retentionPolicy returns the task retention policy, or false when tasks are kept forever
+func (c *config) retentionPolicy() (server.RetentionPolicy, bool) {
+	ttl := time.Duration(c.Retention.TaskTTL)
+	if ttl == 0 {
+		return server.RetentionPolicy{}, false
+	}
+	return server.RetentionPolicy{
+		TTLs: map[models.TaskState]time.Duration{
+			models.TaskStateCompleted: ttl,
+			models.TaskStateFailed:    ttl,
+			models.TaskStateCanceled:  ttl,
+		},
+		Interval: time.Duration(c.Retention.Interval),
+	}, true
+}
+
+// rateLimit returns the per-caller rate limit, or false when requests are not limited
+func (c *config) rateLimit() (server.RateLimit, bool) {
+	if c.RateLimit.PerSecond == 0 {
+		return server.RateLimit{}, false
+	}
+	return server.RateLimit{Rate: c.RateLimit.PerSecond, Burst: c.RateLimit.Burst}, true
+}
+
+// corsOption returns the option setting the CORS policy, or false to keep the server's default
+func (c *config) corsOption() (server.Option, bool) {
+	switch origins := strings.TrimSpace(c.CORS.Origins); origins {
+	case "":
+		return nil, false
+	case "none":
+		return server.WithoutCORS(), true
+	default:
+		var allowed []string
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				allowed = append(allowed, origin)
+			}
+		}
+		return server.WithCORS(server.CORSConfig{AllowedOrigins: allowed, MaxAge: time.Duration(c.CORS.MaxAge)}), true
+	}
+}
+
+// redacted returns a copy of c without its secrets, for reporting
+func (c *config) redacted() config {
+	redacted := *c
+	if redacted.LLM.APIKey != "" {
+		redacted.LLM.APIKey = "REDACTED"
+	}
+	return redacted
+}
+
+// llmConfig returns the model provider configuration
+func (c *config) llmConfig() llm.Config {
+	return llm.Config{Kind: c.LLM.Provider, BaseURL: c.LLM.URL, Model: c.LLM.Model, APIKey: c.LLM.APIKey}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// env returns a getenv reading vars
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+// writeConfig writes a config file named name with content to a temporary directory
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), nil, env(nil))
+	if err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	if cfg.Listen != ":8080" || cfg.Agent.Name != "Translation Agent" || cfg.baseURL() != "http://localhost:8080" {
+		t.Errorf("Unexpected defaults %+v (base URL %s)", cfg, cfg.baseURL())
+	}
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfig(t, "server.json", `{
+		"listen": ":9000",
+		"tls": {"cert": "cert.pem", "key": "key.pem"},
+		"llm": {"provider": "openai", "model": "file-model"},
+		"timeouts": {"handler": "2m", "model": "30s"},
+		"agent": {"name": "File Agent", "version": "2.0.0"},
+		"prompts": {"dir": "prompts", "reload": "1m"}
+	}`)
+	args := []string{"-config", path, "-llm-model", "flag-model", "-model-timeout", "45s"}
+	vars := map[string]string{"LLM_MODEL": "env-model", "A2A_AGENT_NAME": "Env Agent", "A2A_MODEL_TIMEOUT": "1m", "A2A_PROMPTS_RELOAD": "5s"}
+
+	cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), args, env(vars))
+	if err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	// The file overrides the defaults, the environment the file and flags the environment
+	if cfg.Listen != ":9000" || cfg.LLM.Provider != "openai" || cfg.Agent.Version != "2.0.0" {
+		t.Errorf("Expected the file's settings, got %+v", cfg)
+	}
+	if cfg.Agent.Name != "Env Agent" || cfg.Prompts.Dir != "prompts" || time.Duration(cfg.Prompts.Reload) != 5*time.Second {
+		t.Errorf("Expected the environment's agent name and prompt reload interval, got %q and %+v", cfg.Agent.Name, cfg.Prompts)
+	}
+	if cfg.LLM.Model != "flag-model" || time.Duration(cfg.Timeouts.Model) != 45*time.Second {
+		t.Errorf("Expected the flags' model and timeout, got %q and %s", cfg.LLM.Model, cfg.Timeouts.Model)
+	}
+	if time.Duration(cfg.Timeouts.Handler) != 2*time.Minute || cfg.baseURL() != "https://localhost:9000" {
+		t.Errorf("Expected a 2m handler timeout at https://localhost:9000, got %s at %s", cfg.Timeouts.Handler, cfg.baseURL())
+	}
+
+	// A2A_CONFIG names the file when -config is not set
+	cfg, err = loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), nil, env(map[string]string{"A2A_CONFIG": path}))
+	if err != nil || cfg.Agent.Name != "File Agent" {
+		t.Errorf("Expected the file named by A2A_CONFIG, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		vars map[string]string
+		want []string
+	}{
+		{
+			name: "every invalid setting",
+			args: []string{"-listen", "8080", "-llm", "llama", "-tls-key", "key.pem", "-agent-name", " "},
+			vars: map[string]string{"A2A_PUBLIC_URL": "localhost:8080"},
+			want: []string{"listen address", "public URL", "certificate and a key", "unknown LLM provider", "agent name is required"},
+		},
+		{
+			name: "negative timeout",
+			args: []string{"-handler-timeout", "-1s"},
+			want: []string{"handler timeout -1s is negative"},
+		},
+		{
+			name: "bad environment duration",
+			vars: map[string]string{"A2A_KEEPALIVE": "soon"},
+			want: []string{"A2A_KEEPALIVE"},
+		},
+		{
+			name: "unknown setting",
+			file: `{"listen": ":8080", "port": 8080}`,
+			want: []string{`unknown field "port"`},
+		},
+		{
+			name: "bad rate limit",
+			args: []string{"-rate-burst", "-1"},
+			vars: map[string]string{"A2A_RATE_LIMIT": "fast"},
+			want: []string{"A2A_RATE_LIMIT"},
+		},
+		{
+			name: "bad memory budget",
+			vars: map[string]string{"A2A_MEMORY_BUDGET": "lots"},
+			want: []string{"A2A_MEMORY_BUDGET"},
+		},
+		{
+			name: "negative rate limit",
+			args: []string{"-rate-limit", "-2"},
+			want: []string{"must not be negative"},
+		},
+		{
+			name: "bad admin address",
+			vars: map[string]string{"A2A_ADMIN_LISTEN": "9090"},
+			want: []string{"admin listen address"},
+		},
+		{
+			name: "client CA without certificate",
+			vars: map[string]string{"A2A_TLS_CLIENT_CA": "ca.pem"},
+			want: []string{"client CA"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeConfig(t, "server.json", tt.file)}, args...)
+			}
+			_, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), args, env(tt.vars))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected the error to mention %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	path := writeConfig(t, "server.yaml", "listen: \":9000\"\n")
+	_, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), []string{"-config", path}, env(nil))
+	if decodeYAML == nil && (err == nil || !strings.Contains(err.Error(), "-tags yaml")) {
+		t.Errorf("Expected YAML to need the yaml build tag, got %v", err)
+	}
+	if decodeYAML != nil && err != nil {
+		t.Errorf("Expected the YAML file to load, got %v", err)
+	}
+}
+
+func TestLoadConfig_Retention(t *testing.T) {
+	cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), nil, env(nil))
+	if err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+	if _, ok := cfg.retentionPolicy(); ok {
+		t.Error("Expected tasks to be kept forever by default")
+	}
+
+	cfg, err = loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), nil, env(map[string]string{"A2A_TASK_TTL": "24h"}))
+	if err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	policy, ok := cfg.retentionPolicy()
+	if !ok || policy.TTLs[models.TaskStateCompleted] != 24*time.Hour || policy.TTLs[models.TaskStateFailed] != 24*time.Hour {
+		t.Errorf("Expected finished tasks kept for 24h, got %+v", policy)
+	}
+	if _, ok := policy.TTLs[models.TaskStateWorking]; ok {
+		t.Errorf("Expected working tasks to be kept, got %+v", policy)
+	}
+}
+
+func TestLoadConfig_RateLimit(t *testing.T) {
+	cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), []string{"-rate-burst", "10"}, env(map[string]string{"A2A_RATE_LIMIT": "2.5"}))
+	if err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	if limit, ok := cfg.rateLimit(); !ok || limit.Rate != 2.5 || limit.Burst != 10 {
+		t.Errorf("Expected 2.5 requests per second in bursts of 10, got %+v", limit)
+	}
+}
+
+func TestLoadConfig_CORS(t *testing.T) {
+	tests := []struct {
+		origins string
+		wantSet bool
+	}{
+		{"", false},
+		{"none", true},
+		{"https://app.example.com, https://admin.example.com", true},
+	}
+	for _, tt := range tests {
+		vars := map[string]string{"A2A_CORS_ORIGINS": tt.origins, "A2A_CORS_MAX_AGE": "10m"}
+		cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), nil, env(vars))
+		if err != nil {
+			t.Fatalf("Expected a valid configuration, got %v", err)
+		}
+		if time.Duration(cfg.CORS.MaxAge) != 10*time.Minute {
+			t.Errorf("Expected a 10m preflight max age, got %s", cfg.CORS.MaxAge)
+		}
+		if _, ok := cfg.corsOption(); ok != tt.wantSet {
+			t.Errorf("Origins %q: expected a CORS option %v, got %v", tt.origins, tt.wantSet, ok)
+		}
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg, err := loadConfig(flag.NewFlagSet("server", flag.ContinueOnError), []string{"-admin-listen", "127.0.0.1:9090"}, env(map[string]string{"LLM_API_KEY": "sk-secret"}))
+	if err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	if redacted := cfg.redacted(); redacted.LLM.APIKey == "sk-secret" || redacted.Admin.Listen != "127.0.0.1:9090" {
+		t.Errorf("Expected the API key redacted, got %+v", redacted)
+	}
+	if cfg.LLM.APIKey != "sk-secret" {
+		t.Error("Expected the configuration itself to keep its API key")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build yaml
+
+package main
+
+import (
+	"bytes"
+
+	// YAML config files
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	decodeYAML = func(data []byte, v interface{}) error {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		return dec.Decode(v)
+	}
+}
@@ -1,106 +1,211 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"cmp"
+	"context"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"a2a/agents/translator"
+	"a2a/guardrail"
+	"a2a/llm"
+	"a2a/memory"
 	"a2a/models"
+	"a2a/prompt"
+	"a2a/registry"
 	"a2a/server"
+	"a2a/trace"
 )
 
-// OllamaRequest represents the request structure for Ollama API
-type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-}
+// llmConfig selects the model provider, set from the configuration at startup
+var llmConfig llm.Config
 
-// OllamaResponse represents the response structure from Ollama API
-type OllamaResponse struct {
-	Model     string    `json:"model"`
-	CreatedAt time.Time `json:"created_at"`
-	Response  string    `json:"response"`
-	Done      bool      `json:"done"`
-}
+// modelTimeout bounds each model call when positive, set from the configuration at startup
+var modelTimeout time.Duration
 
-// callOllama makes a direct HTTP call to the local Ollama API
-func callOllama(prompt string) (string, error) {
-	reqBody := OllamaRequest{
-		Model:  "qwen3:8b",
-		Prompt: prompt,
-		Stream: false,
-	}
+// model is the provider backing the agent's skills, set from llmConfig at startup
+var model llm.Provider = llm.NewOllama("", "")
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
+// cardKeyID is the key ID of the agent card's signing key in the published key set
+const cardKeyID = "agent-card"
 
-	client := &http.Client{
-		Timeout: 2 * time.Minute,
-	}
+// modelMetadataKey is the message metadata key naming a model to use instead of the
+// configured one for that request
+const modelMetadataKey = "model"
 
-	resp, err := client.Post("http://localhost:11434/api/generate", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to call Ollama: %w", err)
+// requestedModelKey is the context key for the model a message asks for
+type requestedModelKey struct{}
+
+// withRequestedModel runs handler with the model named by the message's metadata, if any,
+// used by generate and generateStream in place of the configured model
+func withRequestedModel(handler server.TaskHandler) server.TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		if name, _ := message.Metadata[modelMetadataKey].(string); name != "" {
+			ctx = context.WithValue(ctx, requestedModelKey{}, name)
+		}
+		return handler(ctx, task, message)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Ollama API returned status: %d", resp.StatusCode)
+// requestModel returns req with the model the message asked for, if any
+func requestModel(ctx context.Context, req llm.Request) llm.Request {
+	if name, _ := ctx.Value(requestedModelKey{}).(string); name != "" {
+		req.Model = name
 	}
+	return req
+}
+
+// generate completes req with the configured provider through the configured guardrails,
+// reporting the tokens consumed for usage accounting
+func generate(ctx context.Context, req llm.Request) (string, error) {
+	req = requestModel(ctx, req)
+	return guardrail.Wrap(func(ctx context.Context, prompt string) (string, error) {
+		ctx, cancel := withTimeout(ctx, modelTimeout)
+		defer cancel()
+		resp, err := model.Generate(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		server.ReportTokens(ctx, resp.Usage.Total())
+		return resp.Text, nil
+	}, guardrails...)(ctx, req.Prompt)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// generateStream completes req like generate, passing each token to emit as the model
+// produces it. With guardrails configured it falls back to generate without calling emit,
+// as they judge the whole completion before any of it may be shown.
+func generateStream(ctx context.Context, req llm.Request, emit func(token string)) (string, error) {
+	if len(guardrails) > 0 {
+		return generate(ctx, req)
+	}
+	req = requestModel(ctx, req)
+	ctx, cancel := withTimeout(ctx, modelTimeout)
+	defer cancel()
+	resp, err := model.GenerateStream(ctx, req, emit)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
+	server.ReportTokens(ctx, resp.Usage.Total())
+	return resp.Text, nil
+}
 
-	var ollamaResp OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+// withTimeout returns ctx bounded by d when d is positive
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, d)
+}
+
+// guardrails check the prompts and completions of every model call
+var guardrails = guardrailsFromEnv()
 
-	return ollamaResp.Response, nil
+// translate calls the translation model. Clients of message/stream see the translation token
+// by token unless guardrails are configured.
+func translate(ctx context.Context, prompt string) (string, error) {
+	emit, finish := streamArtifact(ctx, "translation")
+	text, err := generateStream(ctx, llm.Request{Prompt: prompt}, emit)
+	if err == nil {
+		finish()
+	}
+	return text, err
 }
 
-// translationTaskHandler handles translation tasks using Ollama
-func translationTaskHandler(task *models.Task, message *models.Message) (*models.Task, error) {
-	// Extract text from message parts
-	var inputText string
-	for _, part := range message.Parts {
-		if textPart, ok := part.(models.TextPart); ok {
-			inputText += textPart.Text
+// streamArtifact returns functions streaming tokens to the task executing in ctx as appended
+// chunks of the artifact name, and flagging the last chunk once the completion ends. Each
+// token is held back until the next arrives, so that the last one can carry lastChunk.
+func streamArtifact(ctx context.Context, name string) (emit func(token string), finish func()) {
+	index, started := 0, false
+	var pending *string
+	send := func(token string, last bool) {
+		artifact := models.Artifact{
+			Name:   &name,
+			Parts:  []models.Part{models.NewTextPart(token)},
+			Index:  &index,
+			Append: boolPtr(started),
+		}
+		if last {
+			artifact.LastChunk = boolPtr(true)
+		}
+		server.EmitArtifact(ctx, artifact)
+		started = true
+	}
+	emit = func(token string) {
+		if pending != nil {
+			send(*pending, false)
+		}
+		pending = &token
+	}
+	finish = func() {
+		if pending != nil {
+			send(*pending, true)
+			pending = nil
 		}
 	}
+	return emit, finish
+}
 
-	if inputText == "" {
-		task.Status.State = models.TaskStateFailed
-		return task, fmt.Errorf("no text found in message")
+// guardrailsFromEnv builds a keyword filter from the comma-separated GUARDRAIL_BLOCKLIST
+func guardrailsFromEnv() []guardrail.Guardrail {
+	blocklist := os.Getenv("GUARDRAIL_BLOCKLIST")
+	if blocklist == "" {
+		return nil
 	}
+	return []guardrail.Guardrail{guardrail.NewFilter(strings.Split(blocklist, ","))}
+}
 
-	// Create translation prompt
-	prompt := fmt.Sprintf("Please translate the following text to English: %s", inputText)
+// quotaFromEnv reads the per-caller quota from QUOTA_TASKS_PER_DAY and QUOTA_TOKENS_PER_MONTH
+func quotaFromEnv() server.Quota {
+	tasks, _ := strconv.ParseInt(os.Getenv("QUOTA_TASKS_PER_DAY"), 10, 64)
+	tokens, _ := strconv.ParseInt(os.Getenv("QUOTA_TOKENS_PER_MONTH"), 10, 64)
+	return server.Quota{TasksPerDay: tasks, TokensPerMonth: tokens}
+}
 
-	// Call Ollama for translation
-	translatedText, err := callOllama(prompt)
-	if err != nil {
-		task.Status.State = models.TaskStateFailed
-		return task, fmt.Errorf("translation failed: %w", err)
+// workerPoolFromEnv returns the worker pool option, running A2A_WORKERS handlers at once (default
+// 4) with up to A2A_QUEUE_SIZE tasks waiting (default 64)
+func workerPoolFromEnv() server.Option {
+	workers, err := strconv.Atoi(os.Getenv("A2A_WORKERS"))
+	if err != nil || workers < 1 {
+		workers = 4
 	}
+	queueSize, err := strconv.Atoi(os.Getenv("A2A_QUEUE_SIZE"))
+	if err != nil || queueSize < 1 {
+		queueSize = 64
+	}
+	return server.WithWorkerPool(workers, queueSize)
+}
 
-	// Update task status to completed
-	task.Status.State = models.TaskStateCompleted
-
-	// Note: In this simplified version, we don't store the result in the task
-	// but it would be available through the task store for retrieval
-	log.Printf("Translation completed for task %s: %s -> %s", task.ID, inputText, translatedText)
+// skillConcurrencyFromEnv reads the per-skill limits of A2A_SKILL_CONCURRENCY, such as
+// "translate=2,summarize=1", skipping malformed entries
+func skillConcurrencyFromEnv() map[string]int {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(os.Getenv("A2A_SKILL_CONCURRENCY"), ",") {
+		skill, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		limit, err := strconv.Atoi(value)
+		if !ok || err != nil || skill == "" || limit < 1 {
+			continue
+		}
+		limits[skill] = limit
+	}
+	return limits
+}
 
-	return task, nil
+// translationPairs are declared as skills of their own, so that agents looking for one of these
+// translations find it by skill
+var translationPairs = []translator.Pair{
+	{Source: "zh", Target: "en"},
+	{Source: "fr", Target: "en"},
+	{Source: "es", Target: "en"},
+	{Source: "ja", Target: "en"},
+	{Source: "ko", Target: "en"},
+	{Source: "en", Target: "zh"},
 }
 
 func stringPtr(s string) *string {
@@ -108,55 +213,331 @@ func stringPtr(s string) *string {
 }
 
 func main() {
-	// Create agent card
-	agentCard := models.AgentCard{
-		Name:        "Translation Agent",
-		Description: stringPtr("A2A translation agent using Ollama qwen3:8b model"),
-		URL:         "http://localhost:8080",
-		Version:     "1.0.0",
-		Provider: &models.AgentProvider{
-			Organization: "Local Development",
-			URL:          stringPtr("http://localhost:8080"),
-		},
-		Capabilities: models.AgentCapabilities{
+	stdio := flag.Bool("stdio", false, "serve A2A over stdin/stdout instead of HTTP, for use as a subprocess agent")
+	unixSocket := flag.String("unix", "", "serve on this Unix domain socket instead of TCP")
+	cfg, err := loadConfig(flag.CommandLine, os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Log a span per request, handler and model call when A2A_TRACE=log
+	if os.Getenv("A2A_TRACE") == "log" {
+		trace.SetTracer(trace.NewTracer(trace.LogSpans(nil)))
+	}
+
+	llmConfig, modelTimeout = cfg.llmConfig(), time.Duration(cfg.Timeouts.Model)
+	if model, err = llm.New(llmConfig); err != nil {
+		log.Fatal("Failed to configure LLM provider:", err)
+	}
+	modelName := describeModel(llmConfig)
+
+	// Account usage per caller and enforce quotas
+	opts := []server.Option{
+		server.WithUsageStore(server.NewMemoryUsageStore()),
+		server.WithQuota(quotaFromEnv()),
+		// Post task updates to webhooks registered by clients
+		server.WithPushNotifications(nil),
+		// Serve request, task, streaming and model call metrics at /metrics
+		server.WithMetrics(),
+		// Serve OpenAI clients at /v1/chat/completions
+		server.WithChatCompletions(),
+		// Serve a page for trying the agent from a browser at /ui/
+		server.WithUI(),
+		// Run handlers on a bounded worker pool, rejecting tasks while its queue is full
+		workerPoolFromEnv(),
+		server.WithSkillConcurrency(skillConcurrencyFromEnv()),
+		// Compress responses, such as large file artifacts, for clients accepting gzip
+		server.WithCompression("gzip"),
+	}
+
+	// Require a bearer token when A2A_BEARER_TOKEN is set, or a JWT signed with A2A_JWT_SECRET
+	// or a key of A2A_JWKS_URL
+	switch {
+	case os.Getenv("A2A_BEARER_TOKEN") != "":
+		opts = append(opts, server.WithBearerAuth(server.StaticToken(os.Getenv("A2A_BEARER_TOKEN"))))
+	case os.Getenv("A2A_JWT_SECRET") != "":
+		opts = append(opts, server.WithBearerAuth(server.JWTVerifier(server.JWTConfig{Secret: []byte(os.Getenv("A2A_JWT_SECRET"))})))
+	case os.Getenv("A2A_JWKS_URL") != "":
+		opts = append(opts, server.WithBearerAuth(server.JWTVerifier(server.JWTConfig{JWKSURL: os.Getenv("A2A_JWKS_URL")})))
+	}
+
+	// Require API keys issued at /admin/keys, stored hashed with the tasks, when A2A_ADMIN_TOKEN
+	// is set; the key endpoints take the admin token as a bearer token
+	adminToken := os.Getenv("A2A_ADMIN_TOKEN")
+	if adminToken != "" {
+		opts = append(opts, server.WithAPIKeys())
+	}
+	// Serve the admin API on its own listener, taking the admin token too
+	if cfg.Admin.Listen != "" {
+		if adminToken == "" {
+			log.Fatal("The admin listener needs A2A_ADMIN_TOKEN")
+		}
+		opts = append(opts, server.WithAdmin(server.AdminConfig{Addr: cfg.Admin.Listen, Token: adminToken, Config: cfg.redacted()}))
+	}
+
+	// Serve HTTPS when configured with a certificate and key, requiring client certificates
+	// issued by the client CA when one is set too
+	if cfg.TLS.Cert != "" {
+		opts = append(opts, server.WithTLS(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.ClientCA))
+	}
+	if keepAlive := time.Duration(cfg.Timeouts.KeepAlive); keepAlive > 0 {
+		opts = append(opts, server.WithKeepAlive(keepAlive))
+	}
+	// Purge finished tasks once they have been kept for the task TTL
+	if policy, ok := cfg.retentionPolicy(); ok {
+		opts = append(opts, server.WithRetention(policy))
+	}
+	// Let web pages of the configured origins call the agent
+	if cors, ok := cfg.corsOption(); ok {
+		opts = append(opts, cors)
+	}
+	// Limit each API key or IP address to the configured request rate
+	if limit, ok := cfg.rateLimit(); ok {
+		opts = append(opts, server.WithRateLimit(limit))
+	}
+
+	// Journal task lifecycle events when A2A_JOURNAL names a file, rotating it at 64 MiB
+	if path := os.Getenv("A2A_JOURNAL"); path != "" {
+		journal, err := server.OpenJournal(path, 64<<20, 5)
+		if err != nil {
+			log.Fatal("Failed to open journal:", err)
+		}
+		defer journal.Close()
+		opts = append(opts, server.WithJournal(journal))
+	}
+
+	// Persist tasks in SQLite or PostgreSQL when A2A_STORE_DRIVER and A2A_STORE_DSN are set; the
+	// binary must be built with the driver's tag (see store_sqlite.go and store_postgres.go)
+	var auditStore server.AuditLog
+	if driver := os.Getenv("A2A_STORE_DRIVER"); driver != "" {
+		store, db, err := openTaskStore(driver, os.Getenv("A2A_STORE_DSN"))
+		if err != nil {
+			log.Fatal("Failed to open task store:", err)
+		}
+		defer db.Close()
+		opts = append(opts, server.WithTaskStore(store))
+		auditStore = store
+		log.Printf("Persisting tasks with %s", driver)
+
+		// Replicas sharing the database elect the one purging expired tasks, and stamp the tasks
+		// they process with A2A_INSTANCE_ID, the host name (a Kubernetes pod's name) with a random
+		// suffix by default
+		opts = append(opts, server.WithCluster(server.ClusterConfig{InstanceID: os.Getenv("A2A_INSTANCE_ID"), Locker: store}))
+	}
+
+	// Audit messages, task states, artifacts and authentication decisions to the JSONL file named
+	// by A2A_AUDIT_LOG, or to the task database for "store", with email addresses and file
+	// content redacted
+	if target := os.Getenv("A2A_AUDIT_LOG"); target != "" {
+		auditLog := auditStore
+		if target != "store" {
+			file, err := server.OpenAuditLog(target)
+			if err != nil {
+				log.Fatal("Failed to open audit log:", err)
+			}
+			defer file.Close()
+			auditLog = file
+		}
+		if auditLog == nil {
+			log.Fatal("A2A_AUDIT_LOG=store needs a task database, see A2A_STORE_DRIVER")
+		}
+		opts = append(opts, server.WithAudit(auditLog, server.AuditRedaction{
+			Patterns:  []*regexp.Regexp{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+			OmitFiles: true,
+		}))
+	}
+
+	// Serve task files from A2A_FILES_DIR, moving file artifacts over 1 MiB out of responses
+	if dir := os.Getenv("A2A_FILES_DIR"); dir != "" {
+		files, err := server.NewDirFileStore(dir)
+		if err != nil {
+			log.Fatal("Failed to open file store:", err)
+		}
+		opts = append(opts, server.WithFileStore(files, 1<<20), server.WithMaxFileBytes(512<<20))
+	}
+
+	// Serve content addressed artifacts from A2A_ARTIFACTS_DIR, which then takes file artifacts
+	// over 1 MiB instead of A2A_FILES_DIR
+	if dir := os.Getenv("A2A_ARTIFACTS_DIR"); dir != "" {
+		blobs, err := server.NewDirBlobStore(dir)
+		if err != nil {
+			log.Fatal("Failed to open artifact store:", err)
+		}
+		opts = append(opts, server.WithArtifactStore(blobs, 1<<20), server.WithMaxFileBytes(512<<20))
+	}
+
+	// Tasks fail once they run longer than their timeouts, so a hanging model does not hold a worker
+	opts = append(opts,
+		server.WithTaskTimeout(time.Duration(cfg.Timeouts.Handler)),
+		server.WithRequestTimeout(time.Duration(cfg.Timeouts.Request)),
+		server.WithStreamIdleTimeout(time.Duration(cfg.Timeouts.StreamIdle)))
+	// Sign the agent card, publishing its key at /.well-known/jwks.json
+	if cfg.Agent.SigningKey != "" {
+		key, err := server.LoadCardSigningKey(cfg.Agent.SigningKey)
+		if err != nil {
+			log.Fatal("Failed to load card signing key:", err)
+		}
+		opts = append(opts, server.WithCardSigningKey(key, cardKeyID))
+		log.Println("Signing the agent card")
+	}
+	baseURL := cfg.baseURL()
+	prompts, err := prompt.New(translator.DefaultPrompts, prompt.WithDir(cfg.Prompts.Dir))
+	if err != nil {
+		log.Fatal("Invalid prompt templates:", err)
+	}
+	if cfg.Prompts.Dir != "" {
+		log.Printf("Prompt templates: %s", strings.Join(prompts.Names(), ", "))
+		if reload := time.Duration(cfg.Prompts.Reload); reload > 0 {
+			go prompts.Watch(context.Background(), reload)
+		}
+	}
+	// Remember each conversation so that follow-up messages can revise a translation, the model
+	// summarizing what outgrows the memory budget
+	var conversations memory.Store = memory.NewMapStore(10000)
+	if cfg.Memory.Dir != "" {
+		if conversations, err = memory.NewDirStore(cfg.Memory.Dir); err != nil {
+			log.Fatal("Failed to open conversation memory:", err)
+		}
+	}
+	summarize := memory.ModelSummarizer(func(ctx context.Context, prompt string) (string, error) {
+		return generate(ctx, llm.Request{Prompt: prompt})
+	})
+	translation := translator.New(translate,
+		translator.WithPrompts(prompts),
+		translator.WithMemory(memory.New(conversations,
+			memory.WithBudget(cmp.Or(cfg.Memory.Budget, memory.DefaultBudget)), memory.WithSummarizer(summarize))),
+		translator.WithPairs(translationPairs...),
+		translator.WithModelDetection(func(ctx context.Context, prompt string) (string, error) {
+			return generate(ctx, llm.Request{Prompt: prompt})
+		}),
+		translator.WithDescription("Translate text using "+modelName))
+	if err := translation.Validate(); err != nil {
+		log.Fatal("Invalid translation skills:", err)
+	}
+	builder := server.NewAgent().
+		Named(cfg.Agent.Name).
+		WithDescription(cmp.Or(cfg.Agent.Description, "A2A translation agent using "+modelName)).
+		WithVersion(cfg.Agent.Version).
+		WithURL(baseURL + "/a2a").
+		WithProvider(models.AgentProvider{
+			Organization: cfg.Agent.Organization,
+			URL:          stringPtr(cmp.Or(cfg.Agent.OrganizationURL, baseURL)),
+		}).
+		WithCapabilities(models.AgentCapabilities{
 			Streaming:              boolPtr(true),
-			PushNotifications:      boolPtr(false),
+			PushNotifications:      boolPtr(true),
 			StateTransitionHistory: boolPtr(true),
-		},
-		Skills: []models.AgentSkill{
-			{
-				ID:          "translate",
-				Name:        "Text Translation",
-				Description: stringPtr("Translate text using Ollama qwen3:8b model"),
-				Tags:        []string{"translation", "nlp", "ollama"},
-			},
-		},
-	}
-
-	// Create server
-	srv := server.NewA2AServer(agentCard, translationTaskHandler)
-
-	log.Println("Starting A2A Translation Server on http://localhost:8080")
-	log.Println("Using Ollama qwen3:8b model for translations")
-
-	// Start HTTP server
-	mux := http.NewServeMux()
-
-	// Add agent card endpoint
-	mux.HandleFunc("/.well-known/agent-card", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(agentCard)
-	})
+		}).
+		WithOptions(opts...)
+	// The general translation skill comes first, serving requests that name no skill, followed
+	// by a skill per language pair
+	for _, skill := range translation.Skills() {
+		builder.WithSkill(skill, withRequestedModel(translation.ServeTask))
+	}
+	builder.
+		// The vision skill is served by a multimodal model
+		WithSkill(visionSkill, withRequestedModel(visionTaskHandler)).
+		// The transcription skill is backed by a Whisper-compatible speech-to-text service
+		WithSkill(transcriptionSkill, transcriptionTaskHandler(newTranscriberFromEnv()))
+
+	// Require signed requests when a shared secret is configured
+	if secret := os.Getenv("A2A_SHARED_SECRET"); secret != "" {
+		builder.WithAuth(server.RequireSignature([]byte(secret), 5*time.Minute))
+		log.Println("Requiring HMAC-signed requests")
+	}
+
+	srv, err := builder.Build()
+	if err != nil {
+		log.Fatal("Failed to build agent:", err)
+	}
+	srv.Use(server.Recover(), server.Logging(nil))
+	llm.SetObserver(srv.ObserveLLMCall)
+
+	// Announce the agent to the registry at A2A_REGISTRY_URL so peers find it by skill
+	if url := os.Getenv("A2A_REGISTRY_URL"); url != "" {
+		go registry.NewRegistry(url).KeepAlive(context.Background(), srv.AgentCard())
+	}
+
+	if *stdio {
+		log.Println("Serving A2A Translation Agent over stdio")
+		if err := server.ServeStdio(context.Background(), srv, os.Stdin, os.Stdout); err != nil {
+			log.Fatal("Failed to serve stdio:", err)
+		}
+		return
+	}
+
+	log.Println("Starting A2A Translation Server")
+	log.Printf("Using %s for translations", modelName)
+
+	// Make the model ready in the background, pulling it into Ollama if needed, and report
+	// its state at /readyz
+	status := newModelStatus()
+	go prepareModel(context.Background(), model, status)
 
-	// Add A2A endpoints
-	mux.HandleFunc("/a2a", srv.ServeHTTP)
-	mux.HandleFunc("/a2a/stream", srv.ServeHTTP)
+	mux := srv.Mux()
+	mux.Handle("GET /readyz", status)
 
-	if err := http.ListenAndServe(":8080", mux); err != nil {
+	// Add the usage report, which names every caller, the stored task counts per state, with
+	// the number of expired tasks purged, runtime introspection, the issuing, listing and
+	// revocation of API keys, and conversation export and import endpoints for migrating between
+	// deployments, when the admin token is set; usage counters are served with the metrics at
+	// /metrics
+	if adminToken != "" {
+		mux.Handle("GET /admin/usage", requireAdminToken(adminToken, srv.UsageHandler()))
+		mux.Handle("GET /admin/tasks", requireAdminToken(adminToken, srv.TaskListHandler()))
+		mux.Handle("GET /admin/introspect", requireAdminToken(adminToken, srv.IntrospectionHandler()))
+		keys := requireAdminToken(adminToken, srv.APIKeysHandler())
+		mux.Handle("GET /admin/keys", keys)
+		mux.Handle("POST /admin/keys", keys)
+		mux.Handle("DELETE /admin/keys/{id}", keys)
+		mux.Handle("GET /admin/conversations/{contextId}", requireAdminToken(adminToken, srv.ConversationExportHandler()))
+		mux.Handle("POST /admin/conversations", requireAdminToken(adminToken, srv.ConversationImportHandler()))
+	}
+
+	if *unixSocket != "" {
+		listener, err := server.ListenUnix(*unixSocket)
+		if err != nil {
+			log.Fatal("Failed to listen on Unix socket:", err)
+		}
+		log.Printf("Serving on Unix socket %s (client URL unix://%s/a2a)", *unixSocket, *unixSocket)
+		if err := http.Serve(listener, mux); err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+		return
+	}
+
+	log.Printf("Listening on %s, serving %s/a2a", cfg.Listen, baseURL)
+	if err := srv.ListenAndServe(cfg.Listen); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
 
+// requireAdminToken guards h with an "Authorization: Bearer" header carrying token
+func requireAdminToken(token string, h http.Handler) http.Handler {
+	verify := server.StaticToken(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, err := verify(r.Context(), got); !ok || err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// describeModel names the provider and model of cfg for the agent card and logs
+func describeModel(cfg llm.Config) string {
+	switch cfg.Kind {
+	case llm.KindMock:
+		return "a mock model"
+	case llm.KindOpenAI:
+		return fmt.Sprintf("OpenAI-compatible %s model", cmp.Or(cfg.Model, llm.DefaultOpenAIModel))
+	default:
+		return fmt.Sprintf("Ollama %s model", cmp.Or(cfg.Model, llm.DefaultOllamaModel))
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a/llm"
+)
+
+// modelRetryInterval is how long prepareModel waits before checking an unreachable Ollama again
+const modelRetryInterval = 5 * time.Second
+
+// Model states reported by /readyz
+const (
+	modelChecking = "checking"
+	modelPulling  = "pulling"
+	modelReady    = "ready"
+	modelError    = "error"
+)
+
+// modelState is the state of the configured model, as served at /readyz
+type modelState struct {
+	Model    string            `json:"model,omitempty"`
+	State    string            `json:"state"`
+	Progress *llm.PullProgress `json:"progress,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// modelStatus tracks whether the configured model can serve requests
+type modelStatus struct {
+	mu    sync.Mutex
+	state modelState
+}
+
+// newModelStatus returns the status of a model not checked yet
+func newModelStatus() *modelStatus {
+	return &modelStatus{state: modelState{State: modelChecking}}
+}
+
+// set records the model's state and, while pulling, the download's progress
+func (m *modelStatus) set(state string, progress *llm.PullProgress, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.State, m.state.Progress, m.state.Error = state, progress, ""
+	if err != nil {
+		m.state.Error = err.Error()
+	}
+}
+
+// ServeHTTP serves the status as JSON, with 503 Service Unavailable until the model is ready
+func (m *modelStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if m.state.State != modelReady {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(m.state)
+}
+
+// prepareModel makes the model of provider ready, pulling an Ollama model that is not yet
+// installed and logging the download's progress. An unreachable Ollama is checked again every
+// modelRetryInterval until ctx is done.
+func prepareModel(ctx context.Context, provider llm.Provider, status *modelStatus) {
+	ollama, ok := provider.(*llm.Ollama)
+	if !ok {
+		status.set(modelReady, nil, nil)
+		return
+	}
+	status.mu.Lock()
+	status.state.Model = ollama.Model()
+	status.mu.Unlock()
+	for {
+		status.set(modelChecking, nil, nil)
+		logged := ""
+		err := ollama.EnsureModel(ctx, func(progress llm.PullProgress) {
+			status.set(modelPulling, &progress, nil)
+			// Log each step, and downloads in steps of 10%
+			line := progress.Status
+			if progress.Total > 0 {
+				line = progress.Status + " " + percent(progress.Completed, progress.Total, 10)
+			}
+			if line != logged {
+				log.Printf("Pulling %s: %s", ollama.Model(), line)
+				logged = line
+			}
+		})
+		if err == nil {
+			status.set(modelReady, nil, nil)
+			return
+		}
+		log.Printf("Model %s is not ready: %v", ollama.Model(), err)
+		status.set(modelError, nil, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(modelRetryInterval):
+		}
+	}
+}
+
+// percent returns completed as a percentage of total, rounded down to a multiple of step
+func percent(completed, total, step int64) string {
+	p := completed * 100 / total / step * step
+	return strconv.FormatInt(p, 10) + "%"
+}
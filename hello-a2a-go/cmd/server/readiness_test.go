@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/llm"
+)
+
+func TestPrepareModel(t *testing.T) {
+	pulled := make(chan struct{})
+	release := make(chan struct{})
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			io.WriteString(w, `{"models":[]}`)
+		case "/api/pull":
+			io.WriteString(w, `{"status":"pulling abc","total":200,"completed":50}`+"\n")
+			w.(http.Flusher).Flush()
+			close(pulled)
+			<-release
+			io.WriteString(w, `{"status":"success"}`+"\n")
+		}
+	}))
+	defer ollama.Close()
+
+	status := newModelStatus()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prepareModel(context.Background(), llm.NewOllama(ollama.URL, "qwen3:8b"), status)
+	}()
+
+	readyz := func() (int, modelState) {
+		w := httptest.NewRecorder()
+		status.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		var got modelState
+		json.NewDecoder(w.Body).Decode(&got)
+		return w.Code, got
+	}
+
+	<-pulled
+	// The progress callback runs once the update is decoded, after the flush
+	for {
+		if code, got := readyz(); got.State == modelPulling {
+			if code != http.StatusServiceUnavailable || got.Model != "qwen3:8b" || got.Progress.Completed != 50 {
+				t.Errorf("Expected 503 with the pull progress, got %d %+v", code, got)
+			}
+			break
+		}
+	}
+	close(release)
+	<-done
+	if code, got := readyz(); code != http.StatusOK || got.State != modelReady || got.Progress != nil {
+		t.Errorf("Expected 200 once the model is ready, got %d %+v", code, got)
+	}
+
+	// Other providers are ready at once
+	status = newModelStatus()
+	prepareModel(context.Background(), &llm.Mock{}, status)
+	if code, _ := readyz(); code != http.StatusOK {
+		t.Errorf("Expected the mock provider to be ready, got %d", code)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"a2a/server"
+)
+
+// openTaskStore opens the database dsn with a registered database/sql driver and migrates a
+// task store in it
+func openTaskStore(driver, dsn string) (*server.SQLTaskStore, *sql.DB, error) {
+	dialect, ok := server.DialectOf(driver)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported driver %q", driver)
+	}
+	if !slices.Contains(sql.Drivers(), driver) {
+		return nil, nil, fmt.Errorf("driver %q is not compiled in; build with -tags %s", driver, dialect)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := server.NewSQLTaskStore(context.Background(), db, dialect)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return store, db, nil
+}
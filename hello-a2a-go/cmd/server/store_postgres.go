@@ -0,0 +1,6 @@
+//go:build postgres
+
+package main
+
+// The pgx PostgreSQL driver, registered as "pgx"; add it with go get github.com/jackc/pgx/v5
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package main
+
+// The pure-Go SQLite driver, registered as "sqlite"; add it with go get modernc.org/sqlite
+import _ "modernc.org/sqlite"
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+
+	"a2a/models"
+	"a2a/server"
+	"a2a/trace"
+)
+
+// maxAudioBytes bounds audio accepted by the transcription skill (25 MiB, the OpenAI limit)
+const maxAudioBytes = 25 << 20
+
+// transcriptionSkill transcribes audio file parts to text
+var transcriptionSkill = models.AgentSkill{
+	ID:          "transcribe",
+	Name:        "Audio Transcription",
+	Description: stringPtr("Transcribe speech in audio files using a Whisper-compatible speech-to-text backend"),
+	Tags:        []string{"audio", "speech-to-text", "transcription"},
+	InputModes:  []string{"audio/wav", "audio/mpeg", "audio/ogg", "audio/flac", "audio/webm", "audio/mp4"},
+	OutputModes: []string{"text/plain"},
+}
+
+// Transcriber converts speech audio into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, fileName, mimeType string, audio []byte) (string, error)
+}
+
+// whisperTranscriber calls an OpenAI-compatible /v1/audio/transcriptions endpoint, as served
+// by OpenAI, whisper.cpp's server, faster-whisper-server or LocalAI
+type whisperTranscriber struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+// newTranscriberFromEnv configures the speech-to-text backend from STT_URL, STT_MODEL and STT_API_KEY
+func newTranscriberFromEnv() Transcriber {
+	url := os.Getenv("STT_URL")
+	if url == "" {
+		url = "http://localhost:8000/v1/audio/transcriptions"
+	}
+	model := os.Getenv("STT_MODEL")
+	if model == "" {
+		model = "whisper-1"
+	}
+	return &whisperTranscriber{
+		url:    url,
+		model:  model,
+		apiKey: os.Getenv("STT_API_KEY"),
+		client: &http.Client{Timeout: 5 * time.Minute, Transport: &trace.Transport{}},
+	}
+}
+
+// Transcribe uploads audio as multipart form data and returns the transcribed text
+func (t *whisperTranscriber) Transcribe(ctx context.Context, fileName, mimeType string, audio []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("model", t.model); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := form.WriteField("response_format", "json"); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if fileName == "" {
+		fileName = "audio"
+	}
+	file, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if _, err := file.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call speech-to-text backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("speech-to-text backend returned status %d: %s", resp.StatusCode, detail)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode transcription: %w", err)
+	}
+	return result.Text, nil
+}
+
+// transcriptArtifactName names the artifacts holding transcripts
+const transcriptArtifactName = "transcript"
+
+// transcriptionTaskHandler returns a task handler transcribing every audio part of a message
+// with transcriber, returning one transcript artifact per audio file
+func transcriptionTaskHandler(transcriber Transcriber) func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		var transcripts []models.Artifact
+		for _, part := range message.Parts {
+			filePart, ok := part.(models.FilePart)
+			if !ok {
+				continue
+			}
+
+			mimeType, err := models.ValidateAudioPart(filePart, maxAudioBytes)
+			if err != nil {
+				task.Status.State = models.TaskStateFailed
+				return task, fmt.Errorf("invalid audio %q: %w", filePart.FileName, err)
+			}
+
+			audio := filePart.Content.(models.FileContentBytes).Bytes
+			text, err := transcriber.Transcribe(ctx, filePart.FileName, mimeType, audio)
+			if err != nil {
+				task.Status.State = models.TaskStateFailed
+				return task, fmt.Errorf("transcription failed: %w", err)
+			}
+			server.LoggerFromContext(ctx).Info("transcribed audio",
+				slog.String("file", filePart.FileName), slog.String("mime_type", mimeType), slog.Int("bytes", len(audio)))
+
+			name, index := transcriptArtifactName, len(transcripts)
+			transcript := models.Artifact{
+				Name:  &name,
+				Parts: []models.Part{models.NewTextPart(text)},
+				Index: &index,
+			}
+			if filePart.FileName != "" {
+				transcript.Metadata = map[string]interface{}{"fileName": filePart.FileName}
+			}
+			transcripts = append(transcripts, transcript)
+		}
+
+		if len(transcripts) == 0 {
+			task.Status.State = models.TaskStateFailed
+			return task, fmt.Errorf("no audio found in message")
+		}
+
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = append(task.Artifacts, transcripts...)
+		return task, nil
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"a2a/guardrail"
+	"a2a/llm"
+	"a2a/models"
+	"a2a/server"
+)
+
+// visionImageLimits bounds images passed to the vision model; larger images are downscaled
+var visionImageLimits = models.ImageLimits{
+	MaxBytes:  4 << 20,
+	MaxWidth:  1024,
+	MaxHeight: 1024,
+}
+
+// visionSkill describes images using a multimodal model
+var visionSkill = models.AgentSkill{
+	ID:          "describe-image",
+	Name:        "Image Description",
+	Description: stringPtr("Describe or answer questions about images using a multimodal model"),
+	Tags:        []string{"vision", "image", "llm"},
+	InputModes:  []string{"image/png", "image/jpeg", "image/gif", "text/plain"},
+	OutputModes: []string{"text/plain"},
+}
+
+// visionModel returns the multimodal model, configurable with VISION_MODEL (or the older
+// OLLAMA_VISION_MODEL). Without one, Ollama uses qwen2.5vl:7b and other providers their
+// configured model.
+func visionModel() string {
+	if model := cmp.Or(os.Getenv("VISION_MODEL"), os.Getenv("OLLAMA_VISION_MODEL")); model != "" {
+		return model
+	}
+	if llmConfig.Kind == "" || llmConfig.Kind == llm.KindOllama {
+		return "qwen2.5vl:7b"
+	}
+	return ""
+}
+
+// visionArtifactName names the artifact holding the vision model's answer
+const visionArtifactName = "description"
+
+// visionTaskHandler passes the image parts of a message, with any text as the question,
+// to a multimodal model and returns its answer as an artifact
+func visionTaskHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	var question []string
+	var images [][]byte
+	for _, part := range message.Parts {
+		switch p := part.(type) {
+		case models.TextPart:
+			question = append(question, p.Text)
+		case models.FilePart:
+			fitted, info, err := models.FitImagePart(p, visionImageLimits)
+			if err != nil {
+				task.Status.State = models.TaskStateFailed
+				return task, fmt.Errorf("invalid image %q: %w", p.FileName, err)
+			}
+			server.LoggerFromContext(ctx).Info("fitted image", slog.String("file", p.FileName), slog.String("mime_type", info.MimeType),
+				slog.Int("width", info.Width), slog.Int("height", info.Height), slog.Int("bytes", info.Size))
+			images = append(images, fitted.Content.(models.FileContentBytes).Bytes)
+		}
+	}
+
+	if len(images) == 0 {
+		task.Status.State = models.TaskStateFailed
+		return task, fmt.Errorf("no image found in message")
+	}
+
+	prompt := strings.Join(question, "\n")
+	if prompt == "" {
+		prompt = "Describe this image."
+	}
+
+	answer, err := generate(ctx, llm.Request{Model: visionModel(), Prompt: prompt, Images: images})
+	if violation, ok := guardrail.AsViolation(err); ok {
+		return guardrail.Reject(task, violation), nil
+	}
+	if err != nil {
+		task.Status.State = models.TaskStateFailed
+		return task, fmt.Errorf("vision model failed: %w", err)
+	}
+
+	task.Status.State = models.TaskStateCompleted
+	name := visionArtifactName
+	task.Artifacts = append(task.Artifacts, models.Artifact{
+		Name:  &name,
+		Parts: []models.Part{models.NewTextPart(answer)},
+	})
+	return task, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"a2a/guardrail"
+	"a2a/llm"
+	"a2a/models"
+)
+
+// countingProvider is an llm.Provider answering every request with text and counting calls
+type countingProvider struct {
+	text  string
+	calls int
+}
+
+func (p *countingProvider) Generate(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	p.calls++
+	return &llm.Response{Text: p.text}, nil
+}
+
+func (p *countingProvider) GenerateStream(ctx context.Context, req llm.Request, emit func(token string)) (*llm.Response, error) {
+	emit(p.text)
+	return p.Generate(ctx, req)
+}
+
+func TestVisionTaskHandler_Guardrails(t *testing.T) {
+	provider := &countingProvider{text: "A red square."}
+	savedModel, savedGuardrails := model, guardrails
+	model, guardrails = provider, []guardrail.Guardrail{guardrail.NewFilter([]string{"forbidden"})}
+	defer func() { model, guardrails = savedModel, savedGuardrails }()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	message := func(question string) *models.Message {
+		return &models.Message{Role: "user", Parts: []models.Part{
+			models.NewTextPart(question),
+			models.FilePart{Type: "file", FileName: "square.png", MimeType: "image/png", Content: models.FileContentBytes{Bytes: buf.Bytes()}},
+		}}
+	}
+
+	task, err := visionTaskHandler(context.Background(), &models.Task{ID: "vision-1"}, message("Describe the forbidden shape"))
+	if err != nil {
+		t.Fatalf("Expected a rejected task, got error %v", err)
+	}
+	if task.Status.State != models.TaskStateRejected || provider.calls != 0 {
+		t.Errorf("Expected the blocked prompt rejected before reaching the model, got %s after %d calls", task.Status.State, provider.calls)
+	}
+
+	task, err = visionTaskHandler(context.Background(), &models.Task{ID: "vision-2"}, message("Describe the shape"))
+	if err != nil || task.Status.State != models.TaskStateCompleted || provider.calls != 1 {
+		t.Fatalf("Expected an allowed prompt answered, got %v (%v) after %d calls", task.Status.State, err, provider.calls)
+	}
+	if len(task.Artifacts) != 1 || *task.Artifacts[0].Name != visionArtifactName || task.Artifacts[0].Parts[0].(models.TextPart).Text != "A red square." {
+		t.Errorf("Expected the answer as a %s artifact, got %+v", visionArtifactName, task.Artifacts)
+	}
+}
@@ -0,0 +1,133 @@
+// Package guardrail checks LLM prompts and completions against content policies.
+//
+// Handlers wrap their provider call with Wrap so every prompt is checked before it
+// reaches the model and every completion before it reaches the caller. A failed check
+// surfaces as a *Violation, which Reject turns into a task in the rejected state.
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"a2a/models"
+)
+
+// Stage identifies which side of a provider call a check ran on
+type Stage string
+
+const (
+	StageInput  Stage = "input"
+	StageOutput Stage = "output"
+)
+
+// Guardrail checks text sent to and received from an LLM provider.
+// Implementations return a *Violation to block the text.
+type Guardrail interface {
+	CheckInput(ctx context.Context, text string) error
+	CheckOutput(ctx context.Context, text string) error
+}
+
+// Violation describes text blocked by a guardrail
+type Violation struct {
+	Stage  Stage
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("guardrail blocked %s: %s", v.Stage, v.Reason)
+}
+
+// GenerateFunc calls an LLM provider with prompt and returns the completion
+type GenerateFunc func(ctx context.Context, prompt string) (string, error)
+
+// Wrap returns a GenerateFunc that runs every guardrail's CheckInput on the prompt before
+// calling generate and CheckOutput on the completion afterwards
+func Wrap(generate GenerateFunc, guardrails ...Guardrail) GenerateFunc {
+	return func(ctx context.Context, prompt string) (string, error) {
+		for _, g := range guardrails {
+			if err := g.CheckInput(ctx, prompt); err != nil {
+				return "", err
+			}
+		}
+
+		completion, err := generate(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+
+		for _, g := range guardrails {
+			if err := g.CheckOutput(ctx, completion); err != nil {
+				return "", err
+			}
+		}
+		return completion, nil
+	}
+}
+
+// Reject moves task into the rejected state with an agent message explaining why
+func Reject(task *models.Task, violation *Violation) *models.Task {
+	task.Status.State = models.TaskStateRejected
+	task.Status.Message = &models.Message{
+		Role:  "agent",
+		Parts: []models.Part{models.TextPart{Type: "text", Text: violation.Error()}},
+	}
+	return task
+}
+
+// AsViolation reports whether err is or wraps a *Violation and returns it
+func AsViolation(err error) (*Violation, bool) {
+	var violation *Violation
+	ok := errors.As(err, &violation)
+	return violation, ok
+}
+
+// Filter is a built-in Guardrail blocking text that contains any keyword (case-insensitive)
+// or matches any pattern
+type Filter struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	// CheckOutputs also applies the filter to completions; inputs are always checked
+	CheckOutputs bool
+}
+
+// NewFilter creates a Filter blocking keywords and patterns in both prompts and completions
+func NewFilter(keywords []string, patterns ...*regexp.Regexp) *Filter {
+	lowered := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			lowered = append(lowered, strings.ToLower(k))
+		}
+	}
+	return &Filter{keywords: lowered, patterns: patterns, CheckOutputs: true}
+}
+
+// CheckInput blocks prompts containing a filtered keyword or pattern
+func (f *Filter) CheckInput(ctx context.Context, text string) error {
+	return f.check(StageInput, text)
+}
+
+// CheckOutput blocks completions containing a filtered keyword or pattern
+func (f *Filter) CheckOutput(ctx context.Context, text string) error {
+	if !f.CheckOutputs {
+		return nil
+	}
+	return f.check(StageOutput, text)
+}
+
+func (f *Filter) check(stage Stage, text string) error {
+	lowered := strings.ToLower(text)
+	for _, keyword := range f.keywords {
+		if strings.Contains(lowered, keyword) {
+			return &Violation{Stage: stage, Reason: fmt.Sprintf("contains blocked term %q", keyword)}
+		}
+	}
+	for _, pattern := range f.patterns {
+		if pattern.MatchString(text) {
+			return &Violation{Stage: stage, Reason: fmt.Sprintf("matches blocked pattern %s", pattern)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestWrap(t *testing.T) {
+	calls := 0
+	generate := func(ctx context.Context, prompt string) (string, error) {
+		calls++
+		if prompt == "leak" {
+			return "the card number is 4111-1111-1111-1111", nil
+		}
+		return "fine", nil
+	}
+
+	filter := NewFilter([]string{" Forbidden "}, regexp.MustCompile(`\d{4}-\d{4}-\d{4}-\d{4}`))
+	guarded := Wrap(generate, filter)
+
+	if out, err := guarded(context.Background(), "hello"); err != nil || out != "fine" {
+		t.Errorf("Expected clean completion, got %q (%v)", out, err)
+	}
+
+	_, err := guarded(context.Background(), "this is FORBIDDEN text")
+	violation, ok := AsViolation(err)
+	if !ok || violation.Stage != StageInput {
+		t.Fatalf("Expected input violation, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected blocked prompt not to reach the provider, got %d calls", calls)
+	}
+
+	_, err = guarded(context.Background(), "leak")
+	if violation, ok := AsViolation(err); !ok || violation.Stage != StageOutput {
+		t.Fatalf("Expected output violation, got %v", err)
+	}
+
+	filter.CheckOutputs = false
+	if _, err := guarded(context.Background(), "leak"); err != nil {
+		t.Errorf("Expected output checks to be disabled, got %v", err)
+	}
+}
+
+func TestReject(t *testing.T) {
+	wrapped := errors.Join(errors.New("context"), &Violation{Stage: StageInput, Reason: "contains blocked term"})
+	violation, ok := AsViolation(wrapped)
+	if !ok {
+		t.Fatal("Expected wrapped violation to be found")
+	}
+
+	task := Reject(&models.Task{ID: "t1"}, violation)
+	if task.Status.State != models.TaskStateRejected {
+		t.Errorf("Expected rejected state, got %s", task.Status.State)
+	}
+	text := task.Status.Message.Parts[0].(models.TextPart).Text
+	if text != "guardrail blocked input: contains blocked term" {
+		t.Errorf("Unexpected explanation %q", text)
+	}
+}
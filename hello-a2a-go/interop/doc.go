@@ -0,0 +1,5 @@
+// Package interop holds the A2A conformance tests of the Go client. The same suite runs against a
+// server replaying wire payloads written from the A2A specification, against this module's
+// server and, when configured, against the Python and Java samples, so that protocol drift
+// between the language samples fails a test. See README.md.
+package interop
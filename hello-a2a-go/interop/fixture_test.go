@@ -0,0 +1,188 @@
+package interop
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// fixtures are the spec wire payloads the fixture server answers with; $TASK_ID stands for the
+// ID of the task
+//
+//go:embed testdata
+var fixtures embed.FS
+
+// fixtureServer is an A2A server that knows nothing of this module's models: it answers with
+// the payloads in testdata, picking the outcome of message/send from the message's text as
+// scripted (see scriptedText), and "reply" with a message instead of a task
+type fixtureServer struct {
+	mu    sync.Mutex
+	tasks map[string]json.RawMessage
+	next  int
+}
+
+// newFixtureServer returns a fixture server without tasks
+func newFixtureServer() *fixtureServer {
+	return &fixtureServer{tasks: make(map[string]json.RawMessage)}
+}
+
+// fixtureRequest is a JSON-RPC request as read by the fixture server
+type fixtureRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// fixtureSendParams are the params of message/send and message/stream the fixture server reads
+type fixtureSendParams struct {
+	ID      string `json:"id"`
+	Message struct {
+		TaskID string `json:"taskId"`
+		Parts  []struct {
+			Text string `json:"text"`
+		} `json:"parts"`
+	} `json:"message"`
+}
+
+// ServeHTTP implements http.Handler
+func (f *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req fixtureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFixtureError(w, nil, -32700, "Parse error")
+		return
+	}
+	if req.ID == nil {
+		req.ID = json.RawMessage("null")
+	}
+
+	switch req.Method {
+	case "message/send", "message/stream":
+		var params fixtureSendParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.Message.Parts) == 0 {
+			writeFixtureError(w, req.ID, -32602, "Invalid params")
+			return
+		}
+		taskID := params.Message.TaskID
+		if taskID == "" {
+			taskID = params.ID
+		}
+		if taskID == "" {
+			f.mu.Lock()
+			f.next++
+			taskID = fmt.Sprintf("fixture-task-%d", f.next)
+			f.mu.Unlock()
+		}
+		if req.Method == "message/stream" {
+			f.stream(w, req.ID, taskID)
+			return
+		}
+		f.send(w, req.ID, taskID, params.Message.Parts[0].Text)
+	case "tasks/get", "tasks/cancel":
+		var params struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.ID == "" {
+			writeFixtureError(w, req.ID, -32602, "Invalid params")
+			return
+		}
+		f.mu.Lock()
+		task, ok := f.tasks[params.ID]
+		if ok && req.Method == "tasks/cancel" {
+			task, ok = cancelFixture(task)
+			if !ok {
+				f.mu.Unlock()
+				writeFixtureError(w, req.ID, -32002, "Task cannot be canceled")
+				return
+			}
+			f.tasks[params.ID] = task
+		}
+		f.mu.Unlock()
+		if task == nil {
+			writeFixtureError(w, req.ID, -32001, "Task not found")
+			return
+		}
+		writeFixtureResult(w, req.ID, task)
+	default:
+		writeFixtureError(w, req.ID, -32601, "Method not found")
+	}
+}
+
+// send answers message/send with the fixture scripted by text
+func (f *fixtureServer) send(w http.ResponseWriter, id json.RawMessage, taskID, text string) {
+	name := "task_completed.json"
+	switch text {
+	case scriptedInput:
+		name = "task_input_required.json"
+	case scriptedFail:
+		name = "task_failed.json"
+	case "reply":
+		name = "message_reply.json"
+	}
+	result := fixture(name, taskID)
+	if name != "message_reply.json" {
+		f.mu.Lock()
+		f.tasks[taskID] = result
+		f.mu.Unlock()
+	}
+	writeFixtureResult(w, id, result)
+}
+
+// stream answers message/stream with the events of stream.jsonl as Server-Sent Events
+func (f *fixtureServer) stream(w http.ResponseWriter, id json.RawMessage, taskID string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	scanner := bufio.NewScanner(bytes.NewReader(fixture("stream.jsonl", taskID)))
+	for n := 1; scanner.Scan(); n++ {
+		event, _ := json.Marshal(map[string]json.RawMessage{"jsonrpc": json.RawMessage(`"2.0"`), "id": id, "result": scanner.Bytes()})
+		fmt.Fprintf(w, "id: %d\ndata: %s\n\n", n, event)
+	}
+	f.mu.Lock()
+	f.tasks[taskID] = fixture("task_completed.json", taskID)
+	f.mu.Unlock()
+}
+
+// fixture returns the payload in testdata/name for the task taskID
+func fixture(name, taskID string) json.RawMessage {
+	data, err := fixtures.ReadFile("testdata/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return json.RawMessage(strings.ReplaceAll(string(data), "$TASK_ID", taskID))
+}
+
+// cancelFixture returns task canceled, or false when it is already finished
+func cancelFixture(task json.RawMessage) (json.RawMessage, bool) {
+	var fields map[string]interface{}
+	json.Unmarshal(task, &fields)
+	status, _ := fields["status"].(map[string]interface{})
+	switch status["state"] {
+	case "completed", "canceled", "failed", "rejected":
+		return nil, false
+	}
+	fields["status"] = map[string]interface{}{"state": "canceled"}
+	canceled, _ := json.Marshal(fields)
+	return canceled, true
+}
+
+// writeFixtureResult writes a JSON-RPC response with result
+func writeFixtureResult(w http.ResponseWriter, id, result json.RawMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]json.RawMessage{"jsonrpc": json.RawMessage(`"2.0"`), "id": id, "result": result})
+}
+
+// writeFixtureError writes a JSON-RPC error response
+func writeFixtureError(w http.ResponseWriter, id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+}
@@ -0,0 +1,335 @@
+package interop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/client"
+	"a2a/models"
+	"a2a/server"
+)
+
+// Message texts scripting the outcome of a task on the fixture server and this module's server;
+// any other text completes the task with an artifact holding a part of each kind
+const (
+	scriptedInput = "input"
+	scriptedFail  = "fail"
+)
+
+// suiteTimeout bounds each request of the suite, leaving time for agents backed by a model
+const suiteTimeout = 2 * time.Minute
+
+// runSuite runs the conformance suite against the JSON-RPC endpoint url. Scripted servers are
+// also checked for the task states and parts scripted by the message text; other servers only
+// for behavior that does not depend on the agent.
+func runSuite(t *testing.T, url string, scripted bool) {
+	c := client.NewClient(url)
+	ctx, cancel := context.WithTimeout(context.Background(), suiteTimeout)
+	t.Cleanup(cancel)
+	prefix := fmt.Sprintf("interop-%d", time.Now().UnixNano())
+
+	t.Run("message/send", func(t *testing.T) {
+		result, err := c.SendMessageTyped(ctx, sendParams(prefix+"-send", "Hello"))
+		if err != nil {
+			t.Fatalf("message/send failed: %v", err)
+		}
+		if result.Message != nil {
+			checkParts(t, result.Message.Parts)
+			return
+		}
+		checkTask(t, result.Task)
+		if result.Task.Status.State.IsTerminal() || result.Task.Status.State == models.TaskStateInputRequired {
+			got, err := c.GetTaskTyped(ctx, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: result.Task.ID}})
+			if err != nil || got.ID != result.Task.ID || got.Status.State != result.Task.Status.State {
+				t.Errorf("Expected tasks/get to return the task sent, got %+v (%v)", got, err)
+			}
+		}
+	})
+
+	t.Run("message/stream", func(t *testing.T) {
+		events := make(chan interface{}, 64)
+		var err error
+		go func() {
+			err = c.SendMessageStreamingContext(ctx, sendParams(prefix+"-stream", "Hello"), events)
+			close(events)
+		}()
+		var last map[string]interface{}
+		n := 0
+		for event := range events {
+			fields, ok := event.(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected a JSON object event, got %T", event)
+			}
+			last = fields
+			n++
+		}
+		if err != nil {
+			t.Fatalf("message/stream failed: %v", err)
+		}
+		if n == 0 || last["final"] != true {
+			t.Fatalf("Expected the stream to end with a final event, got %d events ending with %v", n, last)
+		}
+		status, _ := last["status"].(map[string]interface{})
+		if state := models.TaskState(fmt.Sprint(status["state"])); !validState(state) {
+			t.Errorf("Expected the final event to carry a task state, got %q", state)
+		}
+	})
+
+	t.Run("error codes", func(t *testing.T) {
+		_, err := c.GetTaskTyped(ctx, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: prefix + "-unknown"}})
+		checkCode(t, "tasks/get of an unknown task", err, models.ErrorCodeTaskNotFound)
+		_, err = client.Call[models.Task](ctx, c, "interop/unknown", nil)
+		checkCode(t, "an unknown method", err, models.ErrorCodeMethodNotFound)
+		_, err = client.Call[models.Task](ctx, c, "tasks/get", "not an object")
+		checkCode(t, "invalid params", err, models.ErrorCodeInvalidParams)
+
+		resp, err := http.Post(url, "application/json", strings.NewReader(`{"jsonrpc": "2.0", "method": `))
+		if err != nil {
+			t.Fatalf("Failed to post invalid JSON: %v", err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Error *models.JSONRPCError `json:"error"`
+		}
+		if err := models.DecodeJSON(readAll(t, resp), &body); err != nil || body.Error == nil || body.Error.Code != int(models.ErrorCodeParseError) {
+			t.Errorf("Expected a parse error for invalid JSON, got %+v (%v)", body.Error, err)
+		}
+	})
+
+	if !scripted {
+		return
+	}
+
+	t.Run("task states", func(t *testing.T) {
+		result, err := c.SendMessageTyped(ctx, sendParams(prefix+"-input", scriptedInput))
+		if err != nil || result.Task == nil || result.Task.Status.State != models.TaskStateInputRequired || result.Task.Status.Message == nil {
+			t.Fatalf("Expected an input-required task asking a question, got %+v (%v)", result, err)
+		}
+		canceled, err := c.CancelTaskTyped(ctx, models.TaskIDParams{ID: result.Task.ID})
+		if err != nil || canceled.Status.State != models.TaskStateCanceled {
+			t.Errorf("Expected the task awaiting input to be canceled, got %+v (%v)", canceled, err)
+		}
+
+		result, err = c.SendMessageTyped(ctx, sendParams(prefix+"-fail", scriptedFail))
+		if err != nil || result.Task == nil || result.Task.Status.State != models.TaskStateFailed || result.Task.Status.Message == nil {
+			t.Errorf("Expected a failed task explaining why, got %+v (%v)", result, err)
+		}
+	})
+
+	t.Run("part polymorphism", func(t *testing.T) {
+		result, err := c.SendMessageTyped(ctx, sendParams(prefix+"-parts", "complete"))
+		if err != nil || result.Task == nil || len(result.Task.Artifacts) == 0 {
+			t.Fatalf("Expected a task with an artifact, got %+v (%v)", result, err)
+		}
+		kinds := make(map[string]bool)
+		for _, part := range result.Task.Artifacts[0].Parts {
+			switch part := part.(type) {
+			case models.TextPart:
+				kinds["text"] = part.Text == "Hello world!"
+			case models.FilePart:
+				switch content := part.Content.(type) {
+				case models.FileContentBytes:
+					kinds["bytes"] = string(content.Bytes) == "Hello world!" && part.FileName == "hello.txt" && part.MimeType == "text/plain"
+				case models.FileContentURI:
+					kinds["uri"] = content.URI == "https://example.com/hello.png" && part.MimeType == "image/png"
+				}
+			case models.DataPart:
+				data, _ := part.Data.(map[string]interface{})
+				kinds["data"] = data["language"] == "en"
+			}
+		}
+		for _, kind := range []string{"text", "bytes", "uri", "data"} {
+			if !kinds[kind] {
+				t.Errorf("Expected the %s part of the artifact, got %+v", kind, result.Task.Artifacts[0].Parts)
+			}
+		}
+	})
+}
+
+// sendParams returns the params of a user message with text for the task taskID
+func sendParams(taskID, text string) models.MessageSendParams {
+	return models.MessageSendParams{
+		ID: taskID,
+		Message: models.Message{
+			Role:      "user",
+			MessageID: taskID + "-message",
+			Parts:     []models.Part{models.TextPart{Type: "text", Text: text}},
+		},
+	}
+}
+
+// validState reports whether state is one of the task states of the specification
+func validState(state models.TaskState) bool {
+	switch state {
+	case models.TaskStateSubmitted, models.TaskStateWorking, models.TaskStateInputRequired, models.TaskStateCompleted,
+		models.TaskStateCanceled, models.TaskStateFailed, models.TaskStateRejected, models.TaskStateUnknown:
+		return true
+	}
+	return false
+}
+
+// checkTask checks that task has an ID, a valid state and parts of known kinds
+func checkTask(t *testing.T, task *models.Task) {
+	t.Helper()
+	if task.ID == "" || !validState(task.Status.State) {
+		t.Errorf("Expected a task with an ID and a valid state, got %+v", task)
+	}
+	if task.Status.Message != nil {
+		checkParts(t, task.Status.Message.Parts)
+	}
+	for _, artifact := range task.Artifacts {
+		checkParts(t, artifact.Parts)
+	}
+	for _, message := range task.History {
+		checkParts(t, message.Parts)
+	}
+}
+
+// checkParts checks that parts are text, file and data parts, file parts with content
+func checkParts(t *testing.T, parts []models.Part) {
+	t.Helper()
+	for _, part := range parts {
+		switch part := part.(type) {
+		case models.TextPart, models.DataPart:
+		case models.FilePart:
+			if part.Content == nil {
+				t.Errorf("Expected a file part with bytes or a URI, got %+v", part)
+			}
+		default:
+			t.Errorf("Unexpected part %T", part)
+		}
+	}
+}
+
+// checkCode checks that err is an A2A error with code
+func checkCode(t *testing.T, what string, err error, code models.ErrorCode) {
+	t.Helper()
+	if got := models.ErrorCodeOf(err); got != code {
+		t.Errorf("Expected error code %d for %s, got %v", code, what, err)
+	}
+}
+
+// readAll returns the body of resp
+func readAll(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return body.Bytes()
+}
+
+// scriptedHandler runs the tasks of this module's server as scripted by the message text
+func scriptedHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	text := ""
+	if part, ok := message.Parts[0].(models.TextPart); ok {
+		text = part.Text
+	}
+	agentMessage := func(text string) *models.Message {
+		return &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}
+	}
+	switch text {
+	case scriptedInput:
+		task.Status = models.TaskStatus{State: models.TaskStateInputRequired, Message: agentMessage("Which language should I translate to?")}
+	case scriptedFail:
+		task.Status = models.TaskStatus{State: models.TaskStateFailed, Message: agentMessage("The model is unavailable")}
+	default:
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{
+			models.TextPart{Type: "text", Text: "Hello world!"},
+			models.FilePart{Type: "file", FileName: "hello.txt", MimeType: "text/plain", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte("Hello world!")}},
+			models.FilePart{Type: "file", FileName: "hello.png", MimeType: "image/png", Content: models.FileContentURI{Type: "uri", URI: "https://example.com/hello.png"}},
+			models.DataPart{Type: "data", Data: map[string]interface{}{"language": "en", "confidence": 0.98}},
+		}}}
+	}
+	return task, nil
+}
+
+func TestFixtureServer(t *testing.T) {
+	ts := httptest.NewServer(newFixtureServer())
+	defer ts.Close()
+	runSuite(t, ts.URL, true)
+
+	// Agents may answer message/send with a message instead of a task
+	result, err := client.NewClient(ts.URL).SendMessageTyped(context.Background(), sendParams("reply", "reply"))
+	if err != nil || result.Message == nil || result.Message.Parts[0].(models.TextPart).Text != "Hello! How can I help?" {
+		t.Errorf("Expected the agent's message, got %+v (%v)", result, err)
+	}
+}
+
+func TestGoServer(t *testing.T) {
+	agent := server.NewA2AServer(models.AgentCard{Name: "Interop", Capabilities: models.AgentCapabilities{Streaming: boolPtr(true)}}, scriptedHandler)
+	ts := httptest.NewServer(agent)
+	defer ts.Close()
+	runSuite(t, ts.URL, true)
+}
+
+// TestExternalServers runs the suite against the JSON-RPC endpoints listed, comma separated, in
+// A2A_INTEROP_URLS, such as the Python and Java sample servers
+func TestExternalServers(t *testing.T) {
+	urls := os.Getenv("A2A_INTEROP_URLS")
+	if urls == "" {
+		t.Skip("A2A_INTEROP_URLS is not set")
+	}
+	for _, url := range strings.Split(urls, ",") {
+		url = strings.TrimSpace(url)
+		t.Run(url, func(t *testing.T) {
+			runSuite(t, url, false)
+		})
+	}
+}
+
+// TestPythonServer starts the hello-a2a-python agent image named by A2A_INTEROP_PYTHON_IMAGE
+// with docker, serving on the container port A2A_INTEROP_PYTHON_PORT (10000 by default), and
+// runs the suite against it
+func TestPythonServer(t *testing.T) {
+	image := os.Getenv("A2A_INTEROP_PYTHON_IMAGE")
+	if image == "" {
+		t.Skip("A2A_INTEROP_PYTHON_IMAGE is not set")
+	}
+	port := os.Getenv("A2A_INTEROP_PYTHON_PORT")
+	if port == "" {
+		port = "10000"
+	}
+
+	out, err := exec.Command("docker", "run", "-d", "--rm", "-p", "127.0.0.1::"+port, image).Output()
+	if err != nil {
+		t.Fatalf("Failed to start %s: %v", image, err)
+	}
+	container := strings.TrimSpace(string(out))
+	t.Cleanup(func() {
+		exec.Command("docker", "rm", "-f", container).Run()
+	})
+	out, err = exec.Command("docker", "port", container, port).Output()
+	if err != nil {
+		t.Fatalf("Failed to find the port of %s: %v", image, err)
+	}
+	url := "http://" + strings.TrimSpace(strings.Split(string(out), "\n")[0])
+
+	// Wait for the agent to accept connections
+	deadline := time.Now().Add(time.Minute)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not start: %v", image, err)
+		}
+		time.Sleep(time.Second)
+	}
+	runSuite(t, url, false)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
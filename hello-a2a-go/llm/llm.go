@@ -0,0 +1,180 @@
+// Package llm abstracts the language model backing an agent.
+//
+// A Provider generates completions either whole or token by token. Ollama, OpenAI-compatible
+// endpoints (OpenAI, vLLM, LM Studio) and a Mock for tests and offline runs are provided;
+// New selects one from a Config, which ConfigFromEnv and RegisterFlags fill from the
+// environment and command line.
+package llm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"a2a/clock"
+	"a2a/trace"
+)
+
+// Provider kinds accepted by New
+const (
+	KindOllama = "ollama"
+	KindOpenAI = "openai"
+	KindMock   = "mock"
+)
+
+// Request is a prompt for a model
+type Request struct {
+	// Model overrides the provider's default model when set
+	Model  string
+	Prompt string
+	// Images are raw image bytes for multimodal models
+	Images [][]byte
+}
+
+// Usage counts the tokens a request consumed
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// Total returns the prompt and completion tokens together
+func (u Usage) Total() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Response is a completed generation
+type Response struct {
+	Text  string
+	Usage Usage
+}
+
+// Provider generates completions from a language model
+type Provider interface {
+	// Generate returns the whole completion of req
+	Generate(ctx context.Context, req Request) (*Response, error)
+	// GenerateStream passes each token of the completion of req to emit as it is produced and
+	// returns the whole completion once the model finishes
+	GenerateStream(ctx context.Context, req Request, emit func(token string)) (*Response, error)
+}
+
+// Config selects and configures a Provider
+type Config struct {
+	// Kind is KindOllama, KindOpenAI or KindMock; empty means KindOllama
+	Kind string
+	// BaseURL is the API endpoint; empty means the provider's usual local or public endpoint
+	BaseURL string
+	// Model is the default model; empty means the provider's default
+	Model string
+	// APIKey authenticates requests to OpenAI-compatible endpoints
+	APIKey string
+}
+
+// ConfigFromEnv reads a Config from LLM_PROVIDER, LLM_BASE_URL, LLM_MODEL and LLM_API_KEY,
+// falling back to OPENAI_API_KEY for the key
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Kind:    os.Getenv("LLM_PROVIDER"),
+		BaseURL: os.Getenv("LLM_BASE_URL"),
+		Model:   os.Getenv("LLM_MODEL"),
+		APIKey:  os.Getenv("LLM_API_KEY"),
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	return cfg
+}
+
+// RegisterFlags adds -llm, -llm-url and -llm-model flags to fs, defaulting to the values
+// already in c, so flags override the environment
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Kind, "llm", c.Kind, "LLM provider: ollama, openai or mock (env LLM_PROVIDER)")
+	fs.StringVar(&c.BaseURL, "llm-url", c.BaseURL, "LLM API base URL (env LLM_BASE_URL)")
+	fs.StringVar(&c.Model, "llm-model", c.Model, "LLM model (env LLM_MODEL)")
+}
+
+// New returns the Provider described by cfg
+func New(cfg Config) (Provider, error) {
+	switch cfg.Kind {
+	case "", KindOllama:
+		return NewOllama(cfg.BaseURL, cfg.Model), nil
+	case KindOpenAI:
+		return NewOpenAI(cfg.BaseURL, cfg.Model, cfg.APIKey), nil
+	case KindMock:
+		return &Mock{}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Kind)
+	}
+}
+
+// httpClient propagates the caller's trace context so model calls join the request's trace.
+// It has no timeout of its own; callers bound generations with their context.
+var httpClient = &http.Client{Transport: &trace.Transport{}}
+
+// traceGeneration runs generate, a call to the model of a provider, in a client span recording
+// the model and the tokens consumed, so traces show the model's share of a request's latency
+func traceGeneration(ctx context.Context, provider, model string, req Request, stream bool, generate func(ctx context.Context) (*Response, error)) (*Response, error) {
+	if req.Model != "" {
+		model = req.Model
+	}
+	ctx, span := trace.Start(ctx, "llm.generate", trace.SpanKindClient,
+		slog.String("llm.provider", provider), slog.String("llm.model", model), slog.Bool("llm.stream", stream))
+	defer span.End()
+
+	timer := clock.Real
+	if c := callClock.Load(); c != nil {
+		timer = *c
+	}
+	start := timer.Now()
+	resp, err := generate(ctx)
+	span.RecordError(err)
+	call := Call{Provider: provider, Model: model, Stream: stream, Duration: timer.Now().Sub(start), Err: err}
+	if resp != nil {
+		span.SetAttributes(slog.Int64("llm.prompt_tokens", resp.Usage.PromptTokens), slog.Int64("llm.completion_tokens", resp.Usage.CompletionTokens))
+		call.Usage = resp.Usage
+	}
+	if observe := observer.Load(); observe != nil {
+		(*observe)(call)
+	}
+	return resp, err
+}
+
+// Call is a finished call to a model, as passed to the observer set with SetObserver
+type Call struct {
+	Provider string
+	Model    string
+	Stream   bool
+	Duration time.Duration
+	Usage    Usage
+	Err      error
+}
+
+// observer holds the function set with SetObserver
+var observer atomic.Pointer[func(Call)]
+
+// SetObserver sets a function called after every call the Ollama and OpenAI providers make to
+// their model, e.g. to export latency metrics; nil, the default, observes nothing
+func SetObserver(observe func(Call)) {
+	if observe == nil {
+		observer.Store(nil)
+		return
+	}
+	observer.Store(&observe)
+}
+
+// callClock holds the clock set with SetClock
+var callClock atomic.Pointer[clock.Clock]
+
+// SetClock sets the clock timing the calls passed to the observer; nil, the default, is
+// clock.Real
+func SetClock(c clock.Clock) {
+	if c == nil {
+		callClock.Store(nil)
+		return
+	}
+	callClock.Store(&c)
+}
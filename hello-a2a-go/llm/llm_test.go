@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for kind, want := range map[string]string{
+		"":         "*llm.Ollama",
+		KindOllama: "*llm.Ollama",
+		KindOpenAI: "*llm.OpenAI",
+		KindMock:   "*llm.Mock",
+	} {
+		provider, err := New(Config{Kind: kind})
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", kind, err)
+		}
+		if got := fmt.Sprintf("%T", provider); got != want {
+			t.Errorf("Expected %q to select %s, got %s", kind, want, got)
+		}
+	}
+	if _, err := New(Config{Kind: "gemini"}); err == nil {
+		t.Error("Expected an unknown provider to fail")
+	}
+}
+
+func TestConfigFlagsOverrideEnv(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "openai")
+	t.Setenv("LLM_MODEL", "gpt-4o")
+	t.Setenv("LLM_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+
+	cfg := ConfigFromEnv()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-llm-model", "local-model", "-llm-url", "http://localhost:1234/v1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Kind: "openai", BaseURL: "http://localhost:1234/v1", Model: "local-model", APIKey: "sk-test"}
+	if cfg != want {
+		t.Errorf("Expected %+v, got %+v", want, cfg)
+	}
+}
+
+func TestMock(t *testing.T) {
+	var tokens []string
+	resp, err := (&Mock{}).GenerateStream(context.Background(), Request{Prompt: "hello mock world"}, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "hello mock world" || len(tokens) != 3 || tokens[2] != "world" {
+		t.Errorf("Expected the prompt echoed word by word, got %q %q", resp.Text, tokens)
+	}
+	if resp.Usage.Total() != 6 {
+		t.Errorf("Expected 6 tokens, got %+v", resp.Usage)
+	}
+
+	failing := &Mock{Reply: func(string) (string, error) { return "", errors.New("offline") }}
+	if _, err := failing.Generate(context.Background(), Request{Prompt: "hi"}); err == nil {
+		t.Error("Expected the reply error")
+	}
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Mock is a Provider that answers without a model, for tests and for running agents offline
+type Mock struct {
+	// Reply returns the completion of prompt; nil echoes the prompt
+	Reply func(prompt string) (string, error)
+}
+
+// Generate returns the reply to req
+func (m *Mock) Generate(ctx context.Context, req Request) (*Response, error) {
+	return m.GenerateStream(ctx, req, func(string) {})
+}
+
+// GenerateStream emits the reply to req word by word, counting one token per word
+func (m *Mock) GenerateStream(ctx context.Context, req Request, emit func(token string)) (*Response, error) {
+	text := req.Prompt
+	if m.Reply != nil {
+		var err error
+		if text, err = m.Reply(req.Prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	var completion int64
+	for _, token := range strings.SplitAfter(text, " ") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if token != "" {
+			emit(token)
+			completion++
+		}
+	}
+	usage := Usage{PromptTokens: int64(len(strings.Fields(req.Prompt))), CompletionTokens: completion}
+	return &Response{Text: text, Usage: usage}, nil
+}
@@ -0,0 +1,246 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Ollama defaults
+const (
+	DefaultOllamaURL   = "http://localhost:11434"
+	DefaultOllamaModel = "qwen3:8b"
+)
+
+// Ollama generates completions with the Ollama generate API
+type Ollama struct {
+	baseURL string
+	model   string
+}
+
+// NewOllama returns a provider for the Ollama API at baseURL serving model, defaulting to
+// DefaultOllamaURL and DefaultOllamaModel
+func NewOllama(baseURL, model string) *Ollama {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	return &Ollama{baseURL: strings.TrimSuffix(baseURL, "/"), model: model}
+}
+
+// ollamaRequest is the body of an Ollama generate request
+type ollamaRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	// Images holds base64-encoded images for multimodal models
+	Images []string `json:"images,omitempty"`
+}
+
+// ollamaChunk is an Ollama generate response, or one line of a streamed one
+type ollamaChunk struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
+
+	PromptEvalCount int64 `json:"prompt_eval_count"`
+	EvalCount       int64 `json:"eval_count"`
+}
+
+// Generate returns the whole completion of req
+func (o *Ollama) Generate(ctx context.Context, req Request) (*Response, error) {
+	return traceGeneration(ctx, KindOllama, o.model, req, false, func(ctx context.Context) (*Response, error) {
+		return o.generate(ctx, req, nil)
+	})
+}
+
+// GenerateStream passes each token of the completion of req to emit as Ollama streams it
+func (o *Ollama) GenerateStream(ctx context.Context, req Request, emit func(token string)) (*Response, error) {
+	return traceGeneration(ctx, KindOllama, o.model, req, true, func(ctx context.Context) (*Response, error) {
+		return o.generate(ctx, req, emit)
+	})
+}
+
+// generate calls the generate API, streaming when emit is set
+func (o *Ollama) generate(ctx context.Context, req Request, emit func(token string)) (*Response, error) {
+	body := ollamaRequest{Model: o.model, Prompt: req.Prompt, Stream: emit != nil}
+	if req.Model != "" {
+		body.Model = req.Model
+	}
+	for _, image := range req.Images {
+		body.Images = append(body.Images, base64.StdEncoding.EncodeToString(image))
+	}
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/api/generate", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API returned status: %d", resp.StatusCode)
+	}
+
+	// A streamed response is one chunk per line; an unstreamed one is a single chunk
+	var text strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaChunk
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("Ollama response ended before completion")
+			}
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama error: %s", chunk.Error)
+		}
+		if chunk.Response != "" {
+			text.WriteString(chunk.Response)
+			if emit != nil {
+				emit(chunk.Response)
+			}
+		}
+		if chunk.Done || emit == nil {
+			return &Response{
+				Text:  text.String(),
+				Usage: Usage{PromptTokens: chunk.PromptEvalCount, CompletionTokens: chunk.EvalCount},
+			}, nil
+		}
+	}
+}
+
+// Model returns the provider's default model
+func (o *Ollama) Model() string {
+	return o.model
+}
+
+// ListModels returns the names of the models installed in Ollama, from /api/tags
+func (o *Ollama) ListModels(ctx context.Context) ([]string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API returned status: %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode models: %w", err)
+	}
+	names := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+// PullProgress is a progress update of Pull
+type PullProgress struct {
+	// Status describes the step, e.g. "pulling manifest" or "success"
+	Status string `json:"status"`
+	// Digest names the layer being downloaded, if any
+	Digest string `json:"digest,omitempty"`
+	// Total and Completed are the bytes of the layer to download and downloaded
+	Total     int64 `json:"total,omitempty"`
+	Completed int64 `json:"completed,omitempty"`
+}
+
+// Pull downloads model with /api/pull, passing each progress update to progress if set
+func (o *Ollama) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	jsonData, err := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/api/pull", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API returned status: %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var update struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&update); err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("Ollama pull ended before completion")
+			}
+			return fmt.Errorf("failed to decode progress: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("Ollama error: %s", update.Error)
+		}
+		if progress != nil {
+			progress(update.PullProgress)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+	}
+}
+
+// EnsureModel pulls the provider's default model unless Ollama already has it, reporting the
+// download to progress like Pull
+func (o *Ollama) EnsureModel(ctx context.Context, progress func(PullProgress)) error {
+	names, err := o.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if sameModel(name, o.model) {
+			return nil
+		}
+	}
+	return o.Pull(ctx, o.model, progress)
+}
+
+// sameModel reports whether two Ollama model names are the same, a name without a tag
+// meaning the "latest" tag
+func sameModel(a, b string) bool {
+	withTag := func(name string) string {
+		if !strings.Contains(name, ":") {
+			return name + ":latest"
+		}
+		return name
+	}
+	return withTag(a) == withTag(b)
+}
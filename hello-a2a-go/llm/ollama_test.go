@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/trace"
+)
+
+func TestOllama(t *testing.T) {
+	var got ollamaRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Stream {
+			io.WriteString(w, `{"response":"Bon","done":false}`+"\n")
+			io.WriteString(w, `{"response":"jour","done":false}`+"\n")
+			io.WriteString(w, `{"response":"","done":true,"prompt_eval_count":5,"eval_count":2}`+"\n")
+			return
+		}
+		io.WriteString(w, `{"response":"Bonjour","done":true,"prompt_eval_count":5,"eval_count":2}`)
+	}))
+	defer ts.Close()
+	provider := NewOllama(ts.URL+"/", "")
+
+	resp, err := provider.Generate(context.Background(), Request{Prompt: "Hello", Images: [][]byte{{1, 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Bonjour" || resp.Usage.Total() != 7 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if got.Model != DefaultOllamaModel || got.Prompt != "Hello" || len(got.Images) != 1 || got.Images[0] != "AQI=" {
+		t.Errorf("Unexpected request %+v", got)
+	}
+
+	var tokens []string
+	resp, err = provider.GenerateStream(context.Background(), Request{Model: "llama3", Prompt: "Hello"}, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Bonjour" || len(tokens) != 2 || resp.Usage.CompletionTokens != 2 {
+		t.Errorf("Unexpected streamed response %+v with tokens %q", resp, tokens)
+	}
+	if got.Model != "llama3" {
+		t.Errorf("Expected the request model to override the default, got %s", got.Model)
+	}
+}
+
+func TestOllamaError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"error":"model not found"}`)
+	}))
+	defer ts.Close()
+
+	if _, err := NewOllama(ts.URL, "missing").Generate(context.Background(), Request{Prompt: "Hello"}); err == nil || err.Error() != "Ollama error: model not found" {
+		t.Errorf("Expected the Ollama error, got %v", err)
+	}
+}
+
+func TestOllama_Span(t *testing.T) {
+	rec := &trace.Recorder{}
+	trace.SetTracer(trace.NewTracer(rec.Export))
+	defer trace.SetTracer(nil)
+
+	var traceParent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+		io.WriteString(w, `{"response":"Bonjour","done":true,"prompt_eval_count":5,"eval_count":2}`)
+	}))
+	defer ts.Close()
+
+	if _, err := NewOllama(ts.URL, "").Generate(context.Background(), Request{Model: "llama3", Prompt: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	spans := rec.Spans()
+	if len(spans) != 1 || spans[0].Name != "llm.generate" {
+		t.Fatalf("Expected an llm.generate span, got %+v", spans)
+	}
+	attrs := map[string]string{}
+	for _, attr := range spans[0].Attrs {
+		attrs[attr.Key] = attr.Value.String()
+	}
+	if attrs["llm.provider"] != "ollama" || attrs["llm.model"] != "llama3" || attrs["llm.completion_tokens"] != "2" {
+		t.Errorf("Expected the provider, model and usage, got %v", attrs)
+	}
+	if !strings.Contains(traceParent, spans[0].SpanID) {
+		t.Errorf("Expected the model call to name the span as parent, got %q", traceParent)
+	}
+}
+
+func TestOllama_Observer(t *testing.T) {
+	var calls []Call
+	SetObserver(func(call Call) { calls = append(calls, call) })
+	defer SetObserver(nil)
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	defer SetClock(nil)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.Advance(3 * time.Second)
+		io.WriteString(w, `{"response":"Bonjour","done":true,"prompt_eval_count":5,"eval_count":2}`)
+	}))
+	defer ts.Close()
+
+	if _, err := NewOllama(ts.URL, "llama3").Generate(context.Background(), Request{Prompt: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 observed call, got %d", len(calls))
+	}
+	if call := calls[0]; call.Provider != "ollama" || call.Model != "llama3" || call.Stream || call.Usage.CompletionTokens != 2 || call.Err != nil ||
+		call.Duration != 3*time.Second {
+		t.Errorf("Unexpected call %+v", call)
+	}
+}
+
+func TestOllama_EnsureModel(t *testing.T) {
+	installed := []string{"llama3:latest"}
+	pulls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			var models []map[string]string
+			for _, name := range installed {
+				models = append(models, map[string]string{"name": name})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": models})
+		case "/api/pull":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			pulls++
+			if body["model"] == "missing" {
+				io.WriteString(w, `{"error":"pull model manifest: file does not exist"}`+"\n")
+				return
+			}
+			io.WriteString(w, `{"status":"pulling manifest"}`+"\n")
+			io.WriteString(w, `{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":50}`+"\n")
+			io.WriteString(w, `{"status":"success"}`+"\n")
+			installed = append(installed, body["model"].(string))
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	// A model without a tag is the latest one, which is installed
+	if err := NewOllama(ts.URL, "llama3").EnsureModel(ctx, nil); err != nil || pulls != 0 {
+		t.Fatalf("Expected the installed model not to be pulled, got %d pulls (%v)", pulls, err)
+	}
+
+	var progress []PullProgress
+	if err := NewOllama(ts.URL, "qwen3:8b").EnsureModel(ctx, func(p PullProgress) { progress = append(progress, p) }); err != nil {
+		t.Fatal(err)
+	}
+	if pulls != 1 || len(progress) != 3 || progress[1].Completed != 50 || progress[2].Status != "success" {
+		t.Errorf("Expected the pull progress, got %+v after %d pulls", progress, pulls)
+	}
+	if names, err := NewOllama(ts.URL, "").ListModels(ctx); err != nil || len(names) != 2 || names[1] != "qwen3:8b" {
+		t.Errorf("Expected the pulled model to be listed, got %q (%v)", names, err)
+	}
+
+	if err := NewOllama(ts.URL, "missing").EnsureModel(ctx, nil); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Expected the pull error, got %v", err)
+	}
+}
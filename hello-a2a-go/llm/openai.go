@@ -0,0 +1,229 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI defaults
+const (
+	DefaultOpenAIURL   = "https://api.openai.com/v1"
+	DefaultOpenAIModel = "gpt-4o-mini"
+)
+
+// OpenAI generates completions with an OpenAI-compatible chat completions API, as served by
+// OpenAI, vLLM and LM Studio
+type OpenAI struct {
+	baseURL string
+	model   string
+	apiKey  string
+}
+
+// NewOpenAI returns a provider for the chat completions API under baseURL serving model,
+// defaulting to DefaultOpenAIURL and DefaultOpenAIModel. An empty apiKey sends no
+// Authorization header, for local servers.
+func NewOpenAI(baseURL, model, apiKey string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAI{baseURL: strings.TrimSuffix(baseURL, "/"), model: model, apiKey: apiKey}
+}
+
+// openAIRequest is the body of a chat completions request
+type openAIRequest struct {
+	Model         string          `json:"model"`
+	Messages      []openAIMessage `json:"messages"`
+	Stream        bool            `json:"stream,omitempty"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+}
+
+// openAIMessage is a chat message whose content is a string or a list of content parts
+type openAIMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// openAIContentPart is a text or image part of a multimodal message
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+// openAIResponse is a chat completion, or one event of a streamed one
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// usage returns the token counts of r, if it reports any
+func (r *openAIResponse) usage() (Usage, bool) {
+	if r.Usage == nil {
+		return Usage{}, false
+	}
+	return Usage{PromptTokens: r.Usage.PromptTokens, CompletionTokens: r.Usage.CompletionTokens}, true
+}
+
+// Generate returns the whole completion of req
+func (o *OpenAI) Generate(ctx context.Context, req Request) (*Response, error) {
+	return traceGeneration(ctx, KindOpenAI, o.model, req, false, func(ctx context.Context) (*Response, error) {
+		return o.generate(ctx, req)
+	})
+}
+
+// generate requests an unstreamed completion of req
+func (o *OpenAI) generate(ctx context.Context, req Request) (*Response, error) {
+	resp, err := o.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var completion openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("OpenAI response has no choices")
+	}
+	usage, _ := completion.usage()
+	return &Response{Text: completion.Choices[0].Message.Content, Usage: usage}, nil
+}
+
+// GenerateStream passes each token of the completion of req to emit as the server streams it
+func (o *OpenAI) GenerateStream(ctx context.Context, req Request, emit func(token string)) (*Response, error) {
+	return traceGeneration(ctx, KindOpenAI, o.model, req, true, func(ctx context.Context) (*Response, error) {
+		return o.generateStream(ctx, req, emit)
+	})
+}
+
+// generateStream requests a streamed completion of req, passing each token to emit
+func (o *OpenAI) generateStream(ctx context.Context, req Request, emit func(token string)) (*Response, error) {
+	resp, err := o.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result Response
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			result.Text = text.String()
+			return &result, nil
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("OpenAI error: %s", chunk.Error.Message)
+		}
+		if usage, ok := chunk.usage(); ok {
+			result.Usage = usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				emit(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event: %w", err)
+	}
+	return nil, errors.New("OpenAI stream ended before completion")
+}
+
+// post sends req to the chat completions endpoint, returning a response with status 200
+func (o *OpenAI) post(ctx context.Context, req Request, stream bool) (*http.Response, error) {
+	body := openAIRequest{Model: o.model, Stream: stream}
+	if req.Model != "" {
+		body.Model = req.Model
+	}
+	if stream {
+		body.StreamOptions = &struct {
+			IncludeUsage bool `json:"include_usage"`
+		}{IncludeUsage: true}
+	}
+	body.Messages = []openAIMessage{{Role: "user", Content: messageContent(req)}}
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OpenAI API: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var failure openAIResponse
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure) == nil && failure.Error != nil {
+			return nil, fmt.Errorf("OpenAI API returned status %d: %s", resp.StatusCode, failure.Error.Message)
+		}
+		return nil, fmt.Errorf("OpenAI API returned status: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// messageContent is the prompt as plain text, or as text and image parts when req has images
+func messageContent(req Request) interface{} {
+	if len(req.Images) == 0 {
+		return req.Prompt
+	}
+	parts := []openAIContentPart{{Type: "text", Text: req.Prompt}}
+	for _, image := range req.Images {
+		part := openAIContentPart{Type: "image_url", ImageURL: &struct {
+			URL string `json:"url"`
+		}{}}
+		part.ImageURL.URL = "data:" + http.DetectContentType(image) + ";base64," + base64.StdEncoding.EncodeToString(image)
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAI(t *testing.T) {
+	var got map[string]interface{}
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["stream"] == true {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Bon\"}}]}\n\n")
+			io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"jour\"}}]}\n\n")
+			io.WriteString(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2}}\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+			return
+		}
+		io.WriteString(w, `{"choices":[{"message":{"content":"Bonjour"}}],"usage":{"prompt_tokens":5,"completion_tokens":2}}`)
+	}))
+	defer ts.Close()
+	provider := NewOpenAI(ts.URL+"/v1", "", "sk-test")
+
+	resp, err := provider.Generate(context.Background(), Request{Prompt: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Bonjour" || resp.Usage.Total() != 7 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if auth != "Bearer sk-test" || got["model"] != DefaultOpenAIModel {
+		t.Errorf("Unexpected request %v with Authorization %q", got, auth)
+	}
+
+	var tokens []string
+	resp, err = provider.GenerateStream(context.Background(), Request{Prompt: "Hello", Images: [][]byte{[]byte("\x89PNG\r\n\x1a\n")}}, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Bonjour" || len(tokens) != 2 || resp.Usage.Total() != 7 {
+		t.Errorf("Unexpected streamed response %+v with tokens %q", resp, tokens)
+	}
+	content := got["messages"].([]interface{})[0].(map[string]interface{})["content"].([]interface{})
+	image := content[1].(map[string]interface{})["image_url"].(map[string]interface{})["url"].(string)
+	if !strings.HasPrefix(image, "data:image/png;base64,") {
+		t.Errorf("Expected the image as a data URL, got %q", image)
+	}
+}
+
+func TestOpenAIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	defer ts.Close()
+
+	_, err := NewOpenAI(ts.URL, "gpt-4o", "").Generate(context.Background(), Request{Prompt: "Hello"})
+	if err == nil || !strings.Contains(err.Error(), "401: invalid api key") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package memory remembers conversations for stateful agents.
+//
+// A Memory keeps, for each context ID, the recent messages of the conversation and a summary of
+// the older ones, within a token budget: once the messages outgrow it, the oldest are folded
+// into the summary by a Summarizer, or dropped without one. Handlers Recall a conversation to
+// build their prompts and Append each exchange to it. Conversations are kept in a Store, in
+// memory or in a directory, or in any store of your own.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// DefaultBudget is the token budget of a memory created without WithBudget
+const DefaultBudget = 2000
+
+// Conversation is what is remembered of the conversation with a context ID
+type Conversation struct {
+	ContextID string `json:"contextId"`
+	// Summary condenses the messages older than Messages
+	Summary string `json:"summary,omitempty"`
+	// Messages are the recent messages, oldest first
+	Messages  []models.Message `json:"messages,omitempty"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// Transcript returns the conversation as text for a prompt: its summary, then each message as
+// "role: text" on its own line
+func (c *Conversation) Transcript() string {
+	var b strings.Builder
+	if c.Summary != "" {
+		fmt.Fprintf(&b, "Summary: %s\n", c.Summary)
+	}
+	for _, message := range c.Messages {
+		fmt.Fprintf(&b, "%s: %s\n", message.Role, message.Text())
+	}
+	return b.String()
+}
+
+// Summarizer condenses messages, following the conversation summary, into a new summary
+type Summarizer func(ctx context.Context, summary string, messages []models.Message) (string, error)
+
+// ModelSummarizer returns a Summarizer asking generate, a model completing whole prompts, for
+// the summary
+func ModelSummarizer(generate func(ctx context.Context, prompt string) (string, error)) Summarizer {
+	return func(ctx context.Context, summary string, messages []models.Message) (string, error) {
+		conversation := Conversation{Summary: summary, Messages: messages}
+		reply, err := generate(ctx, "Summarize the following conversation in a few sentences, keeping the facts, "+
+			"names, languages and preferences needed to continue it. Reply with only the summary.\n\n"+conversation.Transcript())
+		return strings.TrimSpace(reply), err
+	}
+}
+
+// EstimateTokens estimates the tokens text takes in a prompt, at four characters a token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Memory remembers conversations by context ID, safe for concurrent use
+type Memory struct {
+	store     Store
+	budget    int
+	summarize Summarizer
+	count     func(string) int
+	clock     clock.Clock
+
+	mu sync.Mutex
+	// locks serialize the appends to each conversation
+	locks map[string]*conversationLock
+}
+
+// conversationLock is the lock of a conversation and the number of callers holding or awaiting it
+type conversationLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Option configures a Memory
+type Option func(*Memory)
+
+// WithBudget sets the tokens the summary and messages of a conversation may take together
+func WithBudget(tokens int) Option {
+	return func(m *Memory) {
+		m.budget = tokens
+	}
+}
+
+// WithSummarizer folds the messages outgrowing the budget into the summary with summarize;
+// without it, they are forgotten
+func WithSummarizer(summarize Summarizer) Option {
+	return func(m *Memory) {
+		m.summarize = summarize
+	}
+}
+
+// WithTokenCounter counts the tokens of text with count instead of EstimateTokens
+func WithTokenCounter(count func(text string) int) Option {
+	return func(m *Memory) {
+		m.count = count
+	}
+}
+
+// WithClock sets the clock stamping conversations; the default is the real clock
+func WithClock(c clock.Clock) Option {
+	return func(m *Memory) {
+		m.clock = c
+	}
+}
+
+// New returns a memory keeping conversations in store
+func New(store Store, opts ...Option) *Memory {
+	m := &Memory{
+		store:  store,
+		budget: DefaultBudget,
+		count:  EstimateTokens,
+		clock:  clock.Real,
+		locks:  make(map[string]*conversationLock),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Recall returns the conversation with contextID, empty if nothing is remembered of it
+func (m *Memory) Recall(ctx context.Context, contextID string) (*Conversation, error) {
+	conversation, err := m.store.Load(ctx, contextID)
+	if errors.Is(err, ErrNotFound) {
+		return &Conversation{ContextID: contextID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+// Append adds messages to the conversation with contextID, folding the oldest into its summary
+// when they outgrow the budget. The most recent message is always kept. If the summarizer
+// fails, the messages are still saved and folded on a later append.
+func (m *Memory) Append(ctx context.Context, contextID string, messages ...models.Message) error {
+	if contextID == "" {
+		return errors.New("context ID is required")
+	}
+	unlock := m.lock(contextID)
+	defer unlock()
+
+	conversation, err := m.Recall(ctx, contextID)
+	if err != nil {
+		return err
+	}
+	conversation.Messages = append(conversation.Messages, messages...)
+	conversation.UpdatedAt = m.clock.Now().UTC()
+	compactErr := m.compact(ctx, conversation)
+	if err := m.store.Save(ctx, conversation); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return compactErr
+}
+
+// Forget deletes what is remembered of the conversation with contextID
+func (m *Memory) Forget(ctx context.Context, contextID string) error {
+	unlock := m.lock(contextID)
+	defer unlock()
+	if err := m.store.Delete(ctx, contextID); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	return nil
+}
+
+// compact folds the oldest messages of conversation into its summary until it fits the budget
+func (m *Memory) compact(ctx context.Context, conversation *Conversation) error {
+	if m.budget <= 0 {
+		return nil
+	}
+	tokens := m.count(conversation.Summary)
+	for _, message := range conversation.Messages {
+		tokens += m.count(message.Text())
+	}
+	folded := 0
+	for tokens > m.budget && folded < len(conversation.Messages)-1 {
+		tokens -= m.count(conversation.Messages[folded].Text())
+		folded++
+	}
+	if folded == 0 {
+		return nil
+	}
+	if m.summarize != nil {
+		summary, err := m.summarize(ctx, conversation.Summary, conversation.Messages[:folded])
+		if err != nil {
+			return fmt.Errorf("failed to summarize conversation: %w", err)
+		}
+		conversation.Summary = summary
+	}
+	conversation.Messages = slices.Clone(conversation.Messages[folded:])
+	return nil
+}
+
+// lock locks the conversation with contextID, returning its unlock function
+func (m *Memory) lock(contextID string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[contextID]
+	if !ok {
+		l = &conversationLock{}
+		m.locks[contextID] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, contextID)
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func message(role, text string) models.Message {
+	return models.Message{Role: role, Parts: []models.Part{models.NewTextPart(text)}}
+}
+
+// words counts a token per word, so budgets are easy to follow
+func words(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestMemory_Recall(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := New(NewMapStore(0), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+
+	conversation, err := m.Recall(ctx, "chat")
+	if err != nil || conversation.ContextID != "chat" || len(conversation.Messages) != 0 {
+		t.Fatalf("Expected an empty conversation, got %+v (%v)", conversation, err)
+	}
+	if err := m.Append(ctx, "chat", message("user", "Hello"), message("agent", "Bonjour")); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	conversation, err = m.Recall(ctx, "chat")
+	if err != nil || len(conversation.Messages) != 2 || !conversation.UpdatedAt.Equal(now) {
+		t.Fatalf("Expected the exchange, got %+v (%v)", conversation, err)
+	}
+	if got := conversation.Transcript(); got != "user: Hello\nagent: Bonjour\n" {
+		t.Errorf("Unexpected transcript %q", got)
+	}
+
+	if err := m.Forget(ctx, "chat"); err != nil {
+		t.Fatalf("Failed to forget: %v", err)
+	}
+	if conversation, _ := m.Recall(ctx, "chat"); len(conversation.Messages) != 0 {
+		t.Errorf("Expected the conversation forgotten, got %+v", conversation)
+	}
+	if err := m.Append(ctx, "", message("user", "Hello")); err == nil {
+		t.Error("Expected a context ID to be required")
+	}
+}
+
+func TestMemory_Budget(t *testing.T) {
+	var folded [][]models.Message
+	summarize := func(ctx context.Context, summary string, messages []models.Message) (string, error) {
+		folded = append(folded, messages)
+		return "greeted", nil
+	}
+	m := New(NewMapStore(0), WithBudget(3), WithTokenCounter(words), WithSummarizer(summarize))
+	ctx := context.Background()
+
+	m.Append(ctx, "chat", message("user", "good morning"), message("agent", "bonjour"))
+	m.Append(ctx, "chat", message("user", "make it formal"))
+	conversation, _ := m.Recall(ctx, "chat")
+	// Only the three words of the last message fit, so the exchange before is summarized
+	if conversation.Summary != "greeted" || len(conversation.Messages) != 1 || conversation.Messages[0].Text() != "make it formal" {
+		t.Errorf("Expected the first exchange summarized, got %+v", conversation)
+	}
+	if len(folded) != 1 || len(folded[0]) != 2 {
+		t.Errorf("Expected both earlier messages summarized at once, got %v", folded)
+	}
+
+	// The last message is kept even when it alone outgrows the budget
+	m.Append(ctx, "chat", message("user", "one two three four five six"))
+	if conversation, _ := m.Recall(ctx, "chat"); len(conversation.Messages) != 1 {
+		t.Errorf("Expected the last message kept, got %+v", conversation)
+	}
+}
+
+func TestMemory_SummarizerFails(t *testing.T) {
+	failing := func(ctx context.Context, summary string, messages []models.Message) (string, error) {
+		return "", errors.New("model down")
+	}
+	m := New(NewMapStore(0), WithBudget(1), WithTokenCounter(words), WithSummarizer(failing))
+	ctx := context.Background()
+
+	if err := m.Append(ctx, "chat", message("user", "hello"), message("agent", "bonjour")); err == nil {
+		t.Error("Expected the summarizer's error")
+	}
+	if conversation, _ := m.Recall(ctx, "chat"); len(conversation.Messages) != 2 {
+		t.Errorf("Expected the messages kept to summarize later, got %+v", conversation)
+	}
+
+	// Without a summarizer, messages beyond the budget are forgotten
+	m = New(NewMapStore(0), WithBudget(1), WithTokenCounter(words))
+	if err := m.Append(ctx, "chat", message("user", "hello"), message("agent", "bonjour")); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if conversation, _ := m.Recall(ctx, "chat"); conversation.Summary != "" || len(conversation.Messages) != 1 {
+		t.Errorf("Expected only the last message, got %+v", conversation)
+	}
+}
+
+func TestModelSummarizer(t *testing.T) {
+	var prompt string
+	summarize := ModelSummarizer(func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return " The user wants French. \n", nil
+	})
+	summary, err := summarize(context.Background(), "Greeted.", []models.Message{message("user", "to French please")})
+	if err != nil || summary != "The user wants French." {
+		t.Errorf("Expected the trimmed summary, got %q (%v)", summary, err)
+	}
+	if !strings.Contains(prompt, "Summary: Greeted.\nuser: to French please") {
+		t.Errorf("Expected the prompt to hold the conversation, got %q", prompt)
+	}
+}
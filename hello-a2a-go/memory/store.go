@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"a2a/models"
+)
+
+// ErrNotFound is returned by a Store loading or deleting a conversation it does not hold
+var ErrNotFound = errors.New("conversation not found")
+
+// Store persists conversations by context ID. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the conversation with contextID, or ErrNotFound
+	Load(ctx context.Context, contextID string) (*Conversation, error)
+	// Save stores conversation, replacing the one with its context ID
+	Save(ctx context.Context, conversation *Conversation) error
+	// Delete removes the conversation with contextID, or returns ErrNotFound
+	Delete(ctx context.Context, contextID string) error
+}
+
+// MapStore is a Store keeping conversations in memory, for a single process
+type MapStore struct {
+	capacity int
+
+	mu            sync.Mutex
+	conversations map[string]*Conversation
+}
+
+// NewMapStore returns an empty MapStore holding up to capacity conversations, forgetting the
+// least recently updated beyond it; zero means no limit
+func NewMapStore(capacity int) *MapStore {
+	return &MapStore{capacity: capacity, conversations: make(map[string]*Conversation)}
+}
+
+// Load implements Store
+func (s *MapStore) Load(ctx context.Context, contextID string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation, ok := s.conversations[contextID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(conversation), nil
+}
+
+// Save implements Store
+func (s *MapStore) Save(ctx context.Context, conversation *Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[conversation.ContextID]; !ok && s.capacity > 0 && len(s.conversations) >= s.capacity {
+		var oldest *Conversation
+		for _, c := range s.conversations {
+			if oldest == nil || c.UpdatedAt.Before(oldest.UpdatedAt) {
+				oldest = c
+			}
+		}
+		delete(s.conversations, oldest.ContextID)
+	}
+	s.conversations[conversation.ContextID] = clone(conversation)
+	return nil
+}
+
+// Delete implements Store
+func (s *MapStore) Delete(ctx context.Context, contextID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[contextID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.conversations, contextID)
+	return nil
+}
+
+// clone returns a copy of conversation sharing no slice with it
+func clone(conversation *Conversation) *Conversation {
+	c := *conversation
+	c.Messages = append([]models.Message(nil), conversation.Messages...)
+	return &c
+}
+
+// DirStore is a Store keeping each conversation in a JSON file of a directory, so that
+// conversations survive restarts
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a DirStore keeping conversations in dir, creating it if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create conversation store: %w", err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// path returns the file of the conversation with contextID, named by its hash as context IDs
+// are chosen by clients
+func (s *DirStore) path(contextID string) string {
+	sum := sha256.Sum256([]byte(contextID))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load implements Store
+func (s *DirStore) Load(ctx context.Context, contextID string) (*Conversation, error) {
+	data, err := os.ReadFile(s.path(contextID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var conversation Conversation
+	if err := models.DecodeJSON(data, &conversation); err != nil {
+		return nil, fmt.Errorf("invalid conversation file: %w", err)
+	}
+	return &conversation, nil
+}
+
+// Save implements Store. The conversation is written to a temporary file and renamed into
+// place, so it is never read partially written.
+func (s *DirStore) Save(ctx context.Context, conversation *Conversation) error {
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "conversation-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(conversation.ContextID))
+}
+
+// Delete implements Store
+func (s *DirStore) Delete(ctx context.Context, contextID string) error {
+	err := os.Remove(s.path(contextID))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
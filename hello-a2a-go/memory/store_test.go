@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testStore checks the Store contract against store
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := store.Load(ctx, "chat"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	saved := &Conversation{ContextID: "chat", Summary: "greeted", Messages: nil, UpdatedAt: time.Unix(100, 0).UTC()}
+	saved.Messages = append(saved.Messages, message("user", "Hello"), message("agent", "Bonjour"))
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	saved.Messages[0] = message("user", "changed")
+
+	loaded, err := store.Load(ctx, "chat")
+	if err != nil || loaded.Summary != "greeted" || len(loaded.Messages) != 2 || loaded.Messages[0].Text() != "Hello" ||
+		loaded.Messages[1].Role != "agent" || !loaded.UpdatedAt.Equal(saved.UpdatedAt) {
+		t.Fatalf("Expected the saved conversation, got %+v (%v)", loaded, err)
+	}
+	if err := store.Delete(ctx, "chat"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if err := store.Delete(ctx, "chat"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting again, got %v", err)
+	}
+}
+
+func TestMapStore(t *testing.T) {
+	testStore(t, NewMapStore(0))
+
+	store := NewMapStore(2)
+	ctx := context.Background()
+	for i, id := range []string{"old", "newer", "newest"} {
+		store.Save(ctx, &Conversation{ContextID: id, UpdatedAt: time.Unix(int64(i), 0)})
+	}
+	if _, err := store.Load(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the least recently updated conversation forgotten, got %v", err)
+	}
+	if _, err := store.Load(ctx, "newest"); err != nil {
+		t.Errorf("Expected the newest conversation kept, got %v", err)
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	// Context IDs cannot escape the directory
+	if err := store.Save(context.Background(), &Conversation{ContextID: "../escape"}); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if _, err := store.Load(context.Background(), "../escape"); err != nil {
+		t.Errorf("Expected the conversation in the store, got %v", err)
+	}
+}
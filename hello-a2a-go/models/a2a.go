@@ -15,6 +15,7 @@ const (
 	TaskStateCompleted     TaskState = "completed"
 	TaskStateCanceled      TaskState = "canceled"
 	TaskStateFailed        TaskState = "failed"
+	TaskStateRejected      TaskState = "rejected"
 	TaskStateUnknown       TaskState = "unknown"
 )
 
@@ -34,6 +35,21 @@ type AgentCapabilities struct {
 	PushNotifications *bool `json:"pushNotifications,omitempty"`
 	// StateTransitionHistory indicates if the agent supports providing state transition history
 	StateTransitionHistory *bool `json:"stateTransitionHistory,omitempty"`
+	// Extensions lists the protocol extensions the agent supports
+	Extensions []AgentExtension `json:"extensions,omitempty"`
+}
+
+// AgentExtension declares a protocol extension the agent supports, which clients activate by
+// requesting its URI in the X-A2A-Extensions header
+type AgentExtension struct {
+	// URI identifies the extension
+	URI string `json:"uri"`
+	// Description is an optional description of how the agent uses the extension
+	Description *string `json:"description,omitempty"`
+	// Required indicates that clients must activate the extension to interact with the agent
+	Required bool `json:"required,omitempty"`
+	// Params is optional extension-specific configuration
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 // AgentProvider represents the provider or organization behind an agent
@@ -60,6 +76,10 @@ type AgentSkill struct {
 	InputModes []string `json:"inputModes,omitempty"`
 	// OutputModes is an optional list of output modes supported by this skill
 	OutputModes []string `json:"outputModes,omitempty"`
+	// InputSchema is an optional JSON Schema the data parts of messages to this skill must match
+	InputSchema JSONSchema `json:"inputSchema,omitempty"`
+	// OutputSchema is an optional JSON Schema describing the data parts this skill returns
+	OutputSchema JSONSchema `json:"outputSchema,omitempty"`
 }
 
 // AgentCard represents the metadata card for an agent
@@ -86,12 +106,53 @@ type AgentCard struct {
 	DefaultOutputModes []string `json:"defaultOutputModes,omitempty"`
 	// Skills is the list of specific skills offered by the agent
 	Skills []AgentSkill `json:"skills"`
+	// ProtocolVersion is the A2A protocol version the agent supports, e.g. "0.3.0"
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// PreferredTransport is the transport spoken at URL; empty means TransportJSONRPC
+	PreferredTransport string `json:"preferredTransport,omitempty"`
+	// AdditionalInterfaces lists further URLs and transports the agent is served at
+	AdditionalInterfaces []AgentInterface `json:"additionalInterfaces,omitempty"`
+	// SecuritySchemes declares the authentication schemes by name
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	// Security lists alternative requirements, each mapping scheme names to required scopes
+	Security []map[string][]string `json:"security,omitempty"`
+	// SupportsAuthenticatedExtendedCard indicates that authenticated callers can fetch a more
+	// detailed card
+	SupportsAuthenticatedExtendedCard *bool `json:"supportsAuthenticatedExtendedCard,omitempty"`
+	// Signatures are JSON Web Signatures of the card (see SignAgentCard)
+	Signatures []AgentCardSignature `json:"signatures,omitempty"`
 }
 
 // Message represents a message in the A2A protocol
 type Message struct {
-	Role  string `json:"role"`  // "user" or "agent"
+	Role  string `json:"role"` // "user" or "agent"
 	Parts []Part `json:"parts"`
+	// MessageID identifies the message; servers assign one to messages sent without
+	MessageID string `json:"messageId,omitempty"`
+	// TaskID names the task the message belongs to or continues
+	TaskID string `json:"taskId,omitempty"`
+	// ContextID optionally names the conversation the message continues
+	ContextID string `json:"contextId,omitempty"`
+	// Kind is always KindMessage on the wire; it is filled in when empty
+	Kind string `json:"kind,omitempty"`
+	// Metadata is optional metadata associated with the message
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// ReferenceTaskIDs names other tasks the message refers to for context
+	ReferenceTaskIDs []string `json:"referenceTaskIds,omitempty"`
+	// Extensions lists the URIs of the protocol extensions the message uses
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// KindMessage is the kind of a Message
+const KindMessage = "message"
+
+// MarshalJSON implements custom JSON marshaling for Message, setting its kind
+func (m Message) MarshalJSON() ([]byte, error) {
+	type Alias Message
+	if m.Kind == "" {
+		m.Kind = KindMessage
+	}
+	return json.Marshal(Alias(m))
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for Message to handle Part interface
@@ -108,14 +169,24 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	m.Parts = make([]Part, len(aux.Parts))
-	for i, partData := range aux.Parts {
+	parts, err := unmarshalParts(aux.Parts)
+	if err != nil {
+		return err
+	}
+	m.Parts = parts
+	return nil
+}
+
+// unmarshalParts decodes parts by their kind
+func unmarshalParts(raw []json.RawMessage) ([]Part, error) {
+	parts := make([]Part, len(raw))
+	for i, partData := range raw {
 		// First, extract the kind field to determine the type
 		var partType struct {
 			Kind string `json:"kind"`
 		}
 		if err := json.Unmarshal(partData, &partType); err != nil {
-			return err
+			return nil, err
 		}
 
 		// Unmarshal to the appropriate concrete type based on kind
@@ -123,27 +194,27 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 		case "text":
 			var textPart TextPart
 			if err := json.Unmarshal(partData, &textPart); err != nil {
-				return err
+				return nil, err
 			}
-			m.Parts[i] = textPart
+			parts[i] = textPart
 		case "file":
 			var filePart FilePart
 			if err := json.Unmarshal(partData, &filePart); err != nil {
-				return err
+				return nil, err
 			}
-			m.Parts[i] = filePart
+			parts[i] = filePart
 		case "data":
 			var dataPart DataPart
 			if err := json.Unmarshal(partData, &dataPart); err != nil {
-				return err
+				return nil, err
 			}
-			m.Parts[i] = dataPart
+			parts[i] = dataPart
 		default:
-			return fmt.Errorf("unknown part kind: %s", partType.Kind)
+			return nil, fmt.Errorf("unknown part kind: %s", partType.Kind)
 		}
 	}
 
-	return nil
+	return parts, nil
 }
 
 // Part represents a part of a message (text, file, or data)
@@ -173,6 +244,53 @@ func (p FilePart) GetPartType() string {
 	return "file"
 }
 
+// UnmarshalJSON implements custom JSON unmarshaling for FilePart to handle the FileContent interface.
+// Besides this package's "content" field it accepts the A2A spec's "file" object, which carries the
+// name and MIME type next to either "bytes" or "uri".
+func (p *FilePart) UnmarshalJSON(data []byte) error {
+	type Alias FilePart
+	aux := &struct {
+		Content json.RawMessage `json:"content"`
+		File    json.RawMessage `json:"file"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.Content = nil
+	raw := aux.Content
+	if isNullJSON(raw) && !isNullJSON(aux.File) {
+		raw = aux.File
+		var file struct {
+			Name     string `json:"name"`
+			MimeType string `json:"mimeType"`
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return err
+		}
+		if p.FileName == "" {
+			p.FileName = file.Name
+		}
+		if p.MimeType == "" {
+			p.MimeType = file.MimeType
+		}
+	}
+	if isNullJSON(raw) {
+		return nil
+	}
+
+	content, err := unmarshalFileContent(raw)
+	if err != nil {
+		return err
+	}
+	p.Content = content
+	return nil
+}
+
 // DataPart represents structured data part
 type DataPart struct {
 	Type string      `json:"kind"` // "data"
@@ -183,6 +301,12 @@ func (p DataPart) GetPartType() string {
 	return "data"
 }
 
+// UnmarshalJSON decodes numbers in the payload as json.Number so large integers are preserved
+func (p *DataPart) UnmarshalJSON(data []byte) error {
+	type Alias DataPart
+	return DecodeJSON(data, (*Alias)(p))
+}
+
 // FileContent represents file content (can be bytes or URI)
 type FileContent interface {
 	GetContentType() string
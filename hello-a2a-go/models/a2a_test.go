@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMessage_JSON(t *testing.T) {
+	message := Message{
+		Role:             "user",
+		Parts:            []Part{TextPart{Type: "text", Text: "Hello"}},
+		MessageID:        "m1",
+		TaskID:           "t1",
+		ContextID:        "c1",
+		Metadata:         map[string]interface{}{"channel": "chat"},
+		ReferenceTaskIDs: []string{"t0"},
+		Extensions:       []string{"https://example.com/ext/v1"},
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"kind":"message"`, `"messageId":"m1"`, `"taskId":"t1"`, `"contextId":"c1"`, `"referenceTaskIds":["t0"]`, `"extensions":["https://example.com/ext/v1"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in %s", want, data)
+		}
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.MessageID != "m1" || decoded.TaskID != "t1" || decoded.Kind != KindMessage || decoded.Metadata["channel"] != "chat" || decoded.Parts[0].(TextPart).Text != "Hello" {
+		t.Errorf("Unexpected round trip %+v", decoded)
+	}
+
+	// Optional fields are omitted
+	data, _ = json.Marshal(Message{Role: "agent", Parts: []Part{}})
+	if string(data) != `{"role":"agent","parts":[],"kind":"message"}` {
+		t.Errorf("Unexpected minimal message %s", data)
+	}
+}
+
+func TestMessageSendParams_Configuration(t *testing.T) {
+	data := `{"message":{"role":"user","parts":[]},"configuration":{"blocking":false,"historyLength":2,"acceptedOutputModes":["text/plain"],"pushNotificationConfig":{"url":"https://example.com/hook"}}}`
+	var params MessageSendParams
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		t.Fatal(err)
+	}
+	config := params.Config
+	if config == nil || config.Blocking == nil || *config.Blocking || config.HistoryLength == nil || *config.HistoryLength != 2 {
+		t.Fatalf("Expected a non-blocking configuration keeping 2 messages, got %+v", config)
+	}
+	if config.PushNotifications == nil || config.PushNotifications.URL != "https://example.com/hook" || len(config.AcceptedOutputModes) != 1 {
+		t.Errorf("Expected the spec's pushNotificationConfig to be accepted, got %+v", config)
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotAudio is returned when a file part does not carry supported inline audio
+	ErrNotAudio = errors.New("file part is not supported audio")
+	// ErrAudioTooLarge is returned when audio exceeds the configured size limit
+	ErrAudioTooLarge = errors.New("audio exceeds size limit")
+)
+
+// audioSignatures maps leading byte signatures to audio MIME types
+var audioSignatures = []struct {
+	offset    int
+	signature []byte
+	mimeType  string
+}{
+	{0, []byte("fLaC"), "audio/flac"},
+	{0, []byte("OggS"), "audio/ogg"},
+	{0, []byte("ID3"), "audio/mpeg"},
+	{0, []byte{0x1A, 0x45, 0xDF, 0xA3}, "audio/webm"},
+	{4, []byte("ftypM4A"), "audio/mp4"},
+}
+
+// SniffAudioType detects the MIME type of audio from its content
+func SniffAudioType(data []byte) (string, error) {
+	if len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")) {
+		return "audio/wav", nil
+	}
+	for _, sig := range audioSignatures {
+		if len(data) >= sig.offset+len(sig.signature) && bytes.Equal(data[sig.offset:sig.offset+len(sig.signature)], sig.signature) {
+			return sig.mimeType, nil
+		}
+	}
+	// MPEG audio frames without an ID3 tag start with an 11-bit frame sync
+	if len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 {
+		return "audio/mpeg", nil
+	}
+	return "", ErrNotAudio
+}
+
+// IsAudioMimeType reports whether mimeType names an audio format
+func IsAudioMimeType(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(mimeType), "audio/")
+}
+
+// NewAudioPart builds an inline audio FilePart, sniffing its MIME type from data
+func NewAudioPart(fileName string, data []byte) (FilePart, error) {
+	mimeType, err := SniffAudioType(data)
+	if err != nil {
+		return FilePart{}, err
+	}
+	return FilePart{
+		Type:     "file",
+		FileName: fileName,
+		MimeType: mimeType,
+		Content:  FileContentBytes{Type: "bytes", Bytes: data},
+	}, nil
+}
+
+// ValidateAudioPart checks that part carries inline audio of at most maxBytes (0 means
+// unlimited) and returns its sniffed MIME type. A declared MIME type must be an audio type.
+func ValidateAudioPart(part FilePart, maxBytes int) (string, error) {
+	content, ok := part.Content.(FileContentBytes)
+	if !ok {
+		return "", fmt.Errorf("%w: content is not inline bytes", ErrNotAudio)
+	}
+	if part.MimeType != "" && !IsAudioMimeType(part.MimeType) {
+		return "", fmt.Errorf("%w: declared %s", ErrNotAudio, part.MimeType)
+	}
+
+	mimeType, err := SniffAudioType(content.Bytes)
+	if err != nil {
+		return "", err
+	}
+	if maxBytes > 0 && len(content.Bytes) > maxBytes {
+		return mimeType, fmt.Errorf("%w: %d bytes exceeds %d", ErrAudioTooLarge, len(content.Bytes), maxBytes)
+	}
+	return mimeType, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSniffAudioType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "wav", data: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), want: "audio/wav"},
+		{name: "mp3 id3", data: []byte("ID3\x04\x00"), want: "audio/mpeg"},
+		{name: "mp3 frame", data: []byte{0xFF, 0xFB, 0x90, 0x00}, want: "audio/mpeg"},
+		{name: "ogg", data: []byte("OggS\x00\x02"), want: "audio/ogg"},
+		{name: "flac", data: []byte("fLaC\x00\x00"), want: "audio/flac"},
+		{name: "m4a", data: []byte("\x00\x00\x00\x20ftypM4A "), want: "audio/mp4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SniffAudioType(tt.data)
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %s, got %s (%v)", tt.want, got, err)
+			}
+		})
+	}
+
+	if _, err := SniffAudioType([]byte("hello")); !errors.Is(err, ErrNotAudio) {
+		t.Errorf("Expected ErrNotAudio for text, got %v", err)
+	}
+}
+
+func TestValidateAudioPart(t *testing.T) {
+	part, err := NewAudioPart("clip.wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "))
+	if err != nil {
+		t.Fatalf("Failed to create audio part: %v", err)
+	}
+
+	if mimeType, err := ValidateAudioPart(part, 0); err != nil || mimeType != "audio/wav" {
+		t.Errorf("Expected valid audio/wav, got %s (%v)", mimeType, err)
+	}
+	if _, err := ValidateAudioPart(part, 4); !errors.Is(err, ErrAudioTooLarge) {
+		t.Errorf("Expected ErrAudioTooLarge, got %v", err)
+	}
+
+	part.MimeType = "image/png"
+	if _, err := ValidateAudioPart(part, 0); !errors.Is(err, ErrNotAudio) {
+		t.Errorf("Expected ErrNotAudio for non-audio MIME type, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ProtocolVersion is the A2A protocol version implemented by this module
+const ProtocolVersion = "0.3.0"
+
+// Transport protocols an agent interface can be served over
+const (
+	TransportJSONRPC  = "JSONRPC"
+	TransportGRPC     = "GRPC"
+	TransportHTTPJSON = "HTTP+JSON"
+)
+
+// Security scheme types
+const (
+	SecuritySchemeAPIKey        = "apiKey"
+	SecuritySchemeHTTP          = "http"
+	SecuritySchemeOAuth2        = "oauth2"
+	SecuritySchemeOpenIDConnect = "openIdConnect"
+	SecuritySchemeMutualTLS     = "mutualTLS"
+)
+
+// AgentInterface is an additional URL and transport an agent is served at
+type AgentInterface struct {
+	// URL is the endpoint of the interface
+	URL string `json:"url"`
+	// Transport is the protocol spoken at URL, e.g. TransportJSONRPC
+	Transport string `json:"transport"`
+}
+
+// SecurityScheme describes how to authenticate with an agent, following the OpenAPI security
+// scheme object. Type selects which of the other fields apply.
+type SecurityScheme struct {
+	// Type is one of the SecurityScheme* constants
+	Type string `json:"type"`
+	// Description optionally describes the scheme
+	Description string `json:"description,omitempty"`
+	// Name is the header, query or cookie parameter carrying an API key
+	Name string `json:"name,omitempty"`
+	// In is where an API key is sent: "header", "query" or "cookie"
+	In string `json:"in,omitempty"`
+	// Scheme is the HTTP authorization scheme, e.g. "bearer"
+	Scheme string `json:"scheme,omitempty"`
+	// BearerFormat hints at the format of bearer tokens, e.g. "JWT"
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	// Flows are the OAuth 2.0 flows supported
+	Flows *OAuthFlows `json:"flows,omitempty"`
+	// OpenIDConnectURL is the OpenID Connect discovery URL
+	OpenIDConnectURL string `json:"openIdConnectUrl,omitempty"`
+}
+
+// OAuthFlows lists the OAuth 2.0 flows supported by an oauth2 security scheme
+type OAuthFlows struct {
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+}
+
+// OAuthFlow configures one OAuth 2.0 flow
+type OAuthFlow struct {
+	AuthorizationURL string `json:"authorizationUrl,omitempty"`
+	TokenURL         string `json:"tokenUrl,omitempty"`
+	RefreshURL       string `json:"refreshUrl,omitempty"`
+	// Scopes maps each available scope to its description
+	Scopes map[string]string `json:"scopes"`
+}
+
+// protocolVersionPattern matches a major.minor or major.minor.patch version
+var protocolVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// Validate checks the protocol version, transports and security declarations of the card,
+// reporting every problem found
+func (c *AgentCard) Validate() error {
+	var errs []error
+	if c.ProtocolVersion != "" && !protocolVersionPattern.MatchString(c.ProtocolVersion) {
+		errs = append(errs, fmt.Errorf("invalid protocol version %q", c.ProtocolVersion))
+	}
+
+	transport := c.PreferredTransport
+	if transport == "" {
+		transport = TransportJSONRPC
+	}
+	if c.URL != "" {
+		errs = append(errs, validateInterface("agent URL", AgentInterface{URL: c.URL, Transport: transport})...)
+	}
+	for i, iface := range c.AdditionalInterfaces {
+		errs = append(errs, validateInterface(fmt.Sprintf("additional interface %d", i), iface)...)
+	}
+
+	names := make([]string, 0, len(c.SecuritySchemes))
+	for name := range c.SecuritySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := c.SecuritySchemes[name].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("security scheme %q: %w", name, err))
+		}
+	}
+	for i, requirement := range c.Security {
+		for name := range requirement {
+			if _, ok := c.SecuritySchemes[name]; !ok {
+				errs = append(errs, fmt.Errorf("security requirement %d names undeclared scheme %q", i, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateInterface checks that iface names a known transport and, for HTTP-based transports,
+// an absolute HTTP(S) URL
+func validateInterface(what string, iface AgentInterface) []error {
+	switch iface.Transport {
+	case TransportJSONRPC, TransportHTTPJSON:
+		return validateAbsoluteURL(what, iface.URL)
+	case TransportGRPC:
+		if iface.URL == "" {
+			return []error{fmt.Errorf("%s has no URL", what)}
+		}
+	default:
+		return []error{fmt.Errorf("%s has unknown transport %q", what, iface.Transport)}
+	}
+	return nil
+}
+
+// validate checks that the fields required by the scheme's type are set
+func (s SecurityScheme) validate() error {
+	switch s.Type {
+	case SecuritySchemeAPIKey:
+		if s.Name == "" {
+			return errors.New("apiKey scheme has no name")
+		}
+		if s.In != "header" && s.In != "query" && s.In != "cookie" {
+			return fmt.Errorf("apiKey scheme has invalid location %q", s.In)
+		}
+	case SecuritySchemeHTTP:
+		if s.Scheme == "" {
+			return errors.New("http scheme has no scheme")
+		}
+	case SecuritySchemeOAuth2:
+		if s.Flows == nil || (s.Flows.AuthorizationCode == nil && s.Flows.ClientCredentials == nil &&
+			s.Flows.Implicit == nil && s.Flows.Password == nil) {
+			return errors.New("oauth2 scheme has no flows")
+		}
+	case SecuritySchemeOpenIDConnect:
+		if s.OpenIDConnectURL == "" {
+			return errors.New("openIdConnect scheme has no openIdConnectUrl")
+		}
+	case SecuritySchemeMutualTLS:
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+	return nil
+}
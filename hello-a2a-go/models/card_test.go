@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// specCard is an agent card using every v0.3.0 field, shaped like the Java dice agent's card
+const specCard = `{
+  "name": "Dice Agent",
+  "description": "Rolls an N-sided dice",
+  "url": "localhost:11000",
+  "version": "1.0.0",
+  "documentationUrl": "http://example.com/docs",
+  "capabilities": {"streaming": true, "pushNotifications": false, "stateTransitionHistory": false},
+  "defaultInputModes": ["text"],
+  "defaultOutputModes": ["text"],
+  "skills": [{"id": "dice_roller", "name": "Roll dice", "tags": ["dice"]}],
+  "protocolVersion": "0.3.0",
+  "preferredTransport": "GRPC",
+  "additionalInterfaces": [
+    {"url": "localhost:11000", "transport": "GRPC"},
+    {"url": "http://localhost:11000", "transport": "JSONRPC"}
+  ],
+  "securitySchemes": {
+    "bearer": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+    "apiKey": {"type": "apiKey", "name": "X-API-Key", "in": "header"},
+    "oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "https://auth.example.com/token", "scopes": {"dice:roll": "Roll dice"}}}},
+    "oidc": {"type": "openIdConnect", "openIdConnectUrl": "https://auth.example.com/.well-known/openid-configuration"}
+  },
+  "security": [{"bearer": []}, {"oauth": ["dice:roll"]}],
+  "supportsAuthenticatedExtendedCard": true
+}`
+
+func TestAgentCardRoundTrip(t *testing.T) {
+	var card AgentCard
+	if err := json.Unmarshal([]byte(specCard), &card); err != nil {
+		t.Fatalf("Failed to decode card: %v", err)
+	}
+	if err := card.Validate(); err != nil {
+		t.Errorf("Expected a valid card, got %v", err)
+	}
+
+	encoded, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got interface{}
+	json.Unmarshal([]byte(specCard), &want)
+	json.Unmarshal(encoded, &got)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Card did not round-trip:\n%s", encoded)
+	}
+}
+
+func TestAgentCardValidate(t *testing.T) {
+	card := AgentCard{
+		Name:                 "Agent",
+		URL:                  "localhost:8080",
+		ProtocolVersion:      "v3",
+		AdditionalInterfaces: []AgentInterface{{URL: "http://localhost:8080", Transport: "SOAP"}},
+		SecuritySchemes: map[string]SecurityScheme{
+			"key":  {Type: SecuritySchemeAPIKey, Name: "X-API-Key", In: "body"},
+			"auth": {Type: SecuritySchemeOAuth2},
+		},
+		Security: []map[string][]string{{"missing": nil}},
+	}
+	err := card.Validate()
+	if err == nil {
+		t.Fatal("Expected an invalid card")
+	}
+	for _, want := range []string{
+		`invalid protocol version "v3"`,
+		`agent URL "localhost:8080" is not an absolute HTTP(S) URL`,
+		`additional interface 0 has unknown transport "SOAP"`,
+		`security scheme "auth": oauth2 scheme has no flows`,
+		`security scheme "key": apiKey scheme has invalid location "body"`,
+		`names undeclared scheme "missing"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error %q in %v", want, err)
+		}
+	}
+}
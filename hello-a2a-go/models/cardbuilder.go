@@ -0,0 +1,214 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// AgentCardBuilder assembles an AgentCard without taking the address of every optional field.
+// Problems are reported together by Build.
+type AgentCardBuilder struct {
+	card AgentCard
+}
+
+// NewAgentCardBuilder starts building a card at version 1.0.0, served over JSON-RPC at the
+// protocol version this module implements
+func NewAgentCardBuilder() *AgentCardBuilder {
+	return &AgentCardBuilder{
+		card: AgentCard{
+			Version:            "1.0.0",
+			ProtocolVersion:    ProtocolVersion,
+			PreferredTransport: TransportJSONRPC,
+		},
+	}
+}
+
+// Name sets the agent name
+func (b *AgentCardBuilder) Name(name string) *AgentCardBuilder {
+	b.card.Name = name
+	return b
+}
+
+// Description sets the agent description
+func (b *AgentCardBuilder) Description(description string) *AgentCardBuilder {
+	b.card.Description = &description
+	return b
+}
+
+// URL sets the endpoint the agent is served at
+func (b *AgentCardBuilder) URL(url string) *AgentCardBuilder {
+	b.card.URL = url
+	return b
+}
+
+// Version sets the agent version
+func (b *AgentCardBuilder) Version(version string) *AgentCardBuilder {
+	b.card.Version = version
+	return b
+}
+
+// Provider sets the organization providing the agent and, unless empty, its URL
+func (b *AgentCardBuilder) Provider(organization, url string) *AgentCardBuilder {
+	provider := AgentProvider{Organization: organization}
+	if url != "" {
+		provider.URL = &url
+	}
+	b.card.Provider = &provider
+	return b
+}
+
+// DocumentationURL sets the URL of the agent's documentation
+func (b *AgentCardBuilder) DocumentationURL(url string) *AgentCardBuilder {
+	b.card.DocumentationURL = &url
+	return b
+}
+
+// Streaming sets whether the agent streams responses
+func (b *AgentCardBuilder) Streaming(enabled bool) *AgentCardBuilder {
+	b.card.Capabilities.Streaming = &enabled
+	return b
+}
+
+// PushNotifications sets whether the agent sends push notifications
+func (b *AgentCardBuilder) PushNotifications(enabled bool) *AgentCardBuilder {
+	b.card.Capabilities.PushNotifications = &enabled
+	return b
+}
+
+// StateTransitionHistory sets whether the agent keeps the history of task states
+func (b *AgentCardBuilder) StateTransitionHistory(enabled bool) *AgentCardBuilder {
+	b.card.Capabilities.StateTransitionHistory = &enabled
+	return b
+}
+
+// AddExtension declares a protocol extension the agent supports
+func (b *AgentCardBuilder) AddExtension(extension AgentExtension) *AgentCardBuilder {
+	b.card.Capabilities.Extensions = append(b.card.Capabilities.Extensions, extension)
+	return b
+}
+
+// DefaultInputModes sets the input modes of skills that declare none
+func (b *AgentCardBuilder) DefaultInputModes(modes ...string) *AgentCardBuilder {
+	b.card.DefaultInputModes = modes
+	return b
+}
+
+// DefaultOutputModes sets the output modes of skills that declare none
+func (b *AgentCardBuilder) DefaultOutputModes(modes ...string) *AgentCardBuilder {
+	b.card.DefaultOutputModes = modes
+	return b
+}
+
+// AddSkill adds a skill to the card
+func (b *AgentCardBuilder) AddSkill(skill AgentSkill) *AgentCardBuilder {
+	b.card.Skills = append(b.card.Skills, skill)
+	return b
+}
+
+// ProtocolVersion sets the A2A protocol version the agent supports
+func (b *AgentCardBuilder) ProtocolVersion(version string) *AgentCardBuilder {
+	b.card.ProtocolVersion = version
+	return b
+}
+
+// PreferredTransport sets the transport spoken at the agent URL
+func (b *AgentCardBuilder) PreferredTransport(transport string) *AgentCardBuilder {
+	b.card.PreferredTransport = transport
+	return b
+}
+
+// AddInterface advertises a further URL and transport the agent is served at
+func (b *AgentCardBuilder) AddInterface(url, transport string) *AgentCardBuilder {
+	b.card.AdditionalInterfaces = append(b.card.AdditionalInterfaces, AgentInterface{URL: url, Transport: transport})
+	return b
+}
+
+// SecurityScheme declares an authentication scheme under name and requires callers to satisfy
+// it with scopes. Schemes declared this way are alternatives.
+func (b *AgentCardBuilder) SecurityScheme(name string, scheme SecurityScheme, scopes ...string) *AgentCardBuilder {
+	if b.card.SecuritySchemes == nil {
+		b.card.SecuritySchemes = make(map[string]SecurityScheme)
+	}
+	b.card.SecuritySchemes[name] = scheme
+	if scopes == nil {
+		scopes = []string{}
+	}
+	b.card.Security = append(b.card.Security, map[string][]string{name: scopes})
+	return b
+}
+
+// Build checks that the name, URL, version and at least one skill are set, that every URL is
+// absolute and that skill IDs are present and unique, along with everything AgentCard.Validate
+// checks, and returns the card
+func (b *AgentCardBuilder) Build() (AgentCard, error) {
+	card := b.card
+	card.Skills = slices.Clone(card.Skills)
+	card.AdditionalInterfaces = slices.Clone(card.AdditionalInterfaces)
+	card.Capabilities.Extensions = slices.Clone(card.Capabilities.Extensions)
+	var errs []error
+	if card.Name == "" {
+		errs = append(errs, errors.New("agent name is required"))
+	}
+	if card.URL == "" {
+		errs = append(errs, errors.New("agent URL is required"))
+	}
+	if card.Version == "" {
+		errs = append(errs, errors.New("agent version is required"))
+	}
+	if card.Provider != nil {
+		if card.Provider.Organization == "" {
+			errs = append(errs, errors.New("provider organization is required"))
+		}
+		if card.Provider.URL != nil {
+			errs = append(errs, validateAbsoluteURL("provider URL", *card.Provider.URL)...)
+		}
+	}
+	if card.DocumentationURL != nil {
+		errs = append(errs, validateAbsoluteURL("documentation URL", *card.DocumentationURL)...)
+	}
+
+	if len(card.Skills) == 0 {
+		errs = append(errs, errors.New("at least one skill is required"))
+	}
+	seen := make(map[string]bool)
+	for i, skill := range card.Skills {
+		switch {
+		case skill.ID == "":
+			errs = append(errs, fmt.Errorf("skill %d (%q) has no ID", i, skill.Name))
+		case seen[skill.ID]:
+			errs = append(errs, fmt.Errorf("duplicate skill %q", skill.ID))
+		case skill.Name == "":
+			errs = append(errs, fmt.Errorf("skill %q has no name", skill.ID))
+		}
+		seen[skill.ID] = true
+	}
+	declared := make(map[string]bool)
+	for i, extension := range card.Capabilities.Extensions {
+		switch {
+		case extension.URI == "":
+			errs = append(errs, fmt.Errorf("extension %d has no URI", i))
+		case declared[extension.URI]:
+			errs = append(errs, fmt.Errorf("duplicate extension %q", extension.URI))
+		}
+		declared[extension.URI] = true
+	}
+
+	if err := card.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return AgentCard{}, fmt.Errorf("invalid agent card: %w", errors.Join(errs...))
+	}
+	return card, nil
+}
+
+// validateAbsoluteURL checks that raw is an absolute HTTP(S) URL
+func validateAbsoluteURL(what, raw string) []error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return []error{fmt.Errorf("%s %q is not an absolute HTTP(S) URL", what, raw)}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAgentCardBuilder(t *testing.T) {
+	card, err := NewAgentCardBuilder().
+		Name("Dice Agent").
+		Description("Rolls an N-sided dice").
+		URL("http://localhost:11000").
+		Provider("Example", "https://example.com").
+		Streaming(true).
+		DefaultInputModes("text").
+		AddSkill(AgentSkill{ID: "dice_roller", Name: "Roll dice"}).
+		AddExtension(AgentExtension{URI: "https://example.com/ext/seed/v1", Params: map[string]interface{}{"max": 100}}).
+		SecurityScheme("bearer", SecurityScheme{Type: SecuritySchemeHTTP, Scheme: "bearer"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Expected a valid card, got %v", err)
+	}
+	if card.Name != "Dice Agent" || *card.Description != "Rolls an N-sided dice" || *card.Provider.URL != "https://example.com" {
+		t.Errorf("Expected the configured fields, got %+v", card)
+	}
+	if !*card.Capabilities.Streaming || card.Capabilities.PushNotifications != nil {
+		t.Errorf("Expected only streaming to be set, got %+v", card.Capabilities)
+	}
+	if len(card.Capabilities.Extensions) != 1 || card.Capabilities.Extensions[0].URI != "https://example.com/ext/seed/v1" {
+		t.Errorf("Expected the extension, got %+v", card.Capabilities.Extensions)
+	}
+	if card.Version != "1.0.0" || card.ProtocolVersion != ProtocolVersion || len(card.Skills) != 1 {
+		t.Errorf("Expected the defaults and one skill, got %+v", card)
+	}
+}
+
+func TestAgentCardBuilder_Invalid(t *testing.T) {
+	_, err := NewAgentCardBuilder().
+		Version("").
+		DocumentationURL("/docs").
+		Provider("", "").
+		AddSkill(AgentSkill{ID: "echo", Name: "Echo"}).
+		AddSkill(AgentSkill{ID: "echo", Name: "Echo again"}).
+		AddSkill(AgentSkill{Name: "Anonymous"}).
+		AddInterface("example.com", TransportHTTPJSON).
+		AddExtension(AgentExtension{URI: "urn:ext"}).
+		AddExtension(AgentExtension{URI: "urn:ext"}).
+		AddExtension(AgentExtension{}).
+		Build()
+	if err == nil {
+		t.Fatal("Expected an invalid card")
+	}
+	for _, want := range []string{
+		"agent name is required",
+		"agent URL is required",
+		"agent version is required",
+		"provider organization is required",
+		`documentation URL "/docs" is not an absolute HTTP(S) URL`,
+		`duplicate skill "echo"`,
+		`skill 2 ("Anonymous") has no ID`,
+		`additional interface 0 "example.com" is not an absolute HTTP(S) URL`,
+		`duplicate extension "urn:ext"`,
+		`extension 2 has no URI`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+
+	if _, err := NewAgentCardBuilder().Name("Agent").URL("http://localhost").Build(); err == nil ||
+		!strings.Contains(err.Error(), "at least one skill is required") {
+		t.Errorf("Expected a card without skills to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrCardNotSigned is returned when verifying an agent card without signatures
+var ErrCardNotSigned = errors.New("agent card is not signed")
+
+// AgentCardSignature is a detached JSON Web Signature (RFC 7515) of an agent card. The
+// payload it signs is the card's canonical JSON (see CanonicalAgentCard), left out of the JWS.
+type AgentCardSignature struct {
+	// Protected is the base64url-encoded protected header, naming the alg and kid
+	Protected string `json:"protected"`
+	// Signature is the base64url-encoded signature
+	Signature string `json:"signature"`
+	// Header is the optional unprotected header
+	Header map[string]interface{} `json:"header,omitempty"`
+}
+
+// jwsHeader is the protected header of an agent card signature
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	// JKU is the URL of the JWKS holding the key
+	JKU string `json:"jku,omitempty"`
+}
+
+// CanonicalAgentCard returns the canonical JSON of the agent card encoded in data, which is
+// what its signatures sign: the card without its signatures field, with object keys sorted,
+// no insignificant whitespace and no HTML escaping. Fields this package does not know about
+// are kept, so that a card is verified as it was served.
+func CanonicalAgentCard(data []byte) ([]byte, error) {
+	var card map[string]interface{}
+	if err := DecodeJSON(data, &card); err != nil {
+		return nil, fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	delete(card, "signatures")
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(card); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// SignAgentCard returns card with a signature made with key added to its signatures. The
+// protected header names kid, the ID of the key in the JWKS served at jwksURL, which may be
+// empty. RSA, ECDSA and Ed25519 keys are supported (see NewJSONWebKey).
+func SignAgentCard(card AgentCard, key crypto.Signer, kid, jwksURL string) (AgentCard, error) {
+	alg, err := signingAlgorithm(key.Public())
+	if err != nil {
+		return card, err
+	}
+	header, err := json.Marshal(jwsHeader{Alg: alg, Kid: kid, Typ: "JOSE", JKU: jwksURL})
+	if err != nil {
+		return card, err
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return card, err
+	}
+	payload, err := CanonicalAgentCard(data)
+	if err != nil {
+		return card, err
+	}
+
+	protected := base64.RawURLEncoding.EncodeToString(header)
+	signature, err := signJWS(alg, key, signingInput(protected, payload))
+	if err != nil {
+		return card, fmt.Errorf("failed to sign agent card: %w", err)
+	}
+	card.Signatures = append(append([]AgentCardSignature(nil), card.Signatures...), AgentCardSignature{
+		Protected: protected,
+		Signature: base64.RawURLEncoding.EncodeToString(signature),
+	})
+	return card, nil
+}
+
+// VerifyAgentCard decodes the agent card encoded in data once one of its signatures verifies
+// with a key of keys. It returns ErrCardNotSigned for a card without signatures and an error
+// wrapping ErrInvalidSignature when none verifies.
+func VerifyAgentCard(data []byte, keys JSONWebKeySet) (*AgentCard, error) {
+	var card AgentCard
+	if err := DecodeJSON(data, &card); err != nil {
+		return nil, fmt.Errorf("failed to decode agent card: %w", err)
+	}
+	if len(card.Signatures) == 0 {
+		return nil, ErrCardNotSigned
+	}
+	payload, err := CanonicalAgentCard(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, sig := range card.Signatures {
+		if err := verifyCardSignature(sig, payload, keys); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return &card, nil
+	}
+	return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, errors.Join(errs...))
+}
+
+// verifyCardSignature checks one signature of the canonical card payload
+func verifyCardSignature(sig AgentCardSignature, payload []byte, keys JSONWebKeySet) error {
+	raw, err := base64.RawURLEncoding.DecodeString(sig.Protected)
+	if err != nil {
+		return fmt.Errorf("invalid protected header: %w", err)
+	}
+	var header jwsHeader
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return fmt.Errorf("invalid protected header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	jwk, ok := keys.Key(header.Kid)
+	if !ok {
+		return fmt.Errorf("unknown key %q", header.Kid)
+	}
+	if jwk.Alg != "" && jwk.Alg != header.Alg {
+		return fmt.Errorf("key %q is not for algorithm %q", header.Kid, header.Alg)
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return err
+	}
+	return VerifyJWSSignature(header.Alg, key, signingInput(sig.Protected, payload), signature)
+}
+
+// signingInput returns the JWS signing input of a protected header and payload
+func signingInput(protected string, payload []byte) []byte {
+	return []byte(protected + "." + base64.RawURLEncoding.EncodeToString(payload))
+}
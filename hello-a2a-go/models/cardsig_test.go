@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignAgentCard(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	card := AgentCard{Name: "Signed <Agent>", URL: "https://agent.example/a2a", Version: "1.0.0", Skills: []AgentSkill{{ID: "echo", Name: "Echo"}}}
+
+	for _, key := range []crypto.Signer{ecKey, rsaKey, edKey} {
+		jwk, err := NewJSONWebKey("k1", key.Public())
+		if err != nil {
+			t.Fatalf("Expected a JWK for %T, got %v", key, err)
+		}
+		keys := JSONWebKeySet{Keys: []JSONWebKey{jwk}}
+		signed, err := SignAgentCard(card, key, "k1", "https://agent.example/.well-known/jwks.json")
+		if err != nil {
+			t.Fatalf("Expected %T to sign the card, got %v", key, err)
+		}
+		data, _ := json.Marshal(signed)
+
+		verified, err := VerifyAgentCard(data, keys)
+		if err != nil || verified.Name != card.Name {
+			t.Errorf("Expected the %s signature to verify, got %v", jwk.Alg, err)
+		}
+
+		// The JSON's layout does not matter, but fields unknown to this package are signed too
+		var fields map[string]interface{}
+		json.Unmarshal(data, &fields)
+		indented, _ := json.MarshalIndent(fields, "", "  ")
+		if _, err := VerifyAgentCard(indented, keys); err != nil {
+			t.Errorf("Expected the reformatted card to verify, got %v", err)
+		}
+		fields["x-extra"] = "added"
+		extended, _ := json.Marshal(fields)
+		if _, err := VerifyAgentCard(extended, keys); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected an added field to fail with ErrInvalidSignature, got %v", err)
+		}
+		payload, _ := CanonicalAgentCard(indented)
+		if strings.Contains(string(payload), "\n") || !strings.Contains(string(payload), `"name":"Signed <Agent>"`) {
+			t.Errorf("Expected compact canonical JSON without HTML escaping, got %s", payload)
+		}
+
+		tampered := strings.Replace(string(data), `"echo"`, `"admin"`, 1)
+		if _, err := VerifyAgentCard([]byte(tampered), keys); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected a tampered card to fail with ErrInvalidSignature, got %v", err)
+		}
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := NewJSONWebKey("k1", otherKey.Public())
+	signed, _ := SignAgentCard(card, ecKey, "k1", "")
+	data, _ := json.Marshal(signed)
+	if _, err := VerifyAgentCard(data, JSONWebKeySet{Keys: []JSONWebKey{other}}); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a signature by another key to fail, got %v", err)
+	}
+	if _, err := VerifyAgentCard(data, JSONWebKeySet{}); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a signature by an unknown key to fail, got %v", err)
+	}
+	unsigned, _ := json.Marshal(card)
+	if _, err := VerifyAgentCard(unsigned, JSONWebKeySet{}); !errors.Is(err, ErrCardNotSigned) {
+		t.Errorf("Expected ErrCardNotSigned, got %v", err)
+	}
+}
+
+func TestJSONWebKey_PublicKey(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	jwk, err := NewJSONWebKey("ec", ecKey.Public())
+	if err != nil || jwk.Kty != "EC" || jwk.Crv != "P-384" || jwk.Alg != "ES384" {
+		t.Fatalf("Expected a P-384 JWK, got %+v (%v)", jwk, err)
+	}
+	pub, err := jwk.PublicKey()
+	if err != nil || !ecKey.PublicKey.Equal(pub) {
+		t.Errorf("Expected the key back, got %v (%v)", pub, err)
+	}
+	if _, err := (JSONWebKey{Kty: "oct"}).PublicKey(); err == nil {
+		t.Error("Expected symmetric keys to be rejected")
+	}
+}
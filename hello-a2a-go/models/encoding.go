@@ -0,0 +1,59 @@
+package models
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ContentCodec compresses and decompresses HTTP bodies in one content encoding
+type ContentCodec struct {
+	// NewWriter returns a writer compressing to w; streamed responses are flushed through it
+	// when it has a Flush() error method, as *gzip.Writer does
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing r
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	contentCodecsMu sync.RWMutex
+	// contentCodecs are the registered content encodings by name
+	contentCodecs = map[string]ContentCodec{
+		"gzip": {
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+			NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		},
+	}
+)
+
+// RegisterContentEncoding registers the codec of a content encoding, replacing any registered
+// under name. gzip is built in; the standard library has no zstd, so programs wanting it
+// register a codec backed by a package such as github.com/klauspost/compress/zstd.
+func RegisterContentEncoding(name string, codec ContentCodec) {
+	contentCodecsMu.Lock()
+	defer contentCodecsMu.Unlock()
+	contentCodecs[strings.ToLower(name)] = codec
+}
+
+// LookupContentEncoding returns the codec of a registered content encoding, whose name is
+// matched case-insensitively
+func LookupContentEncoding(name string) (ContentCodec, bool) {
+	contentCodecsMu.RLock()
+	defer contentCodecsMu.RUnlock()
+	codec, ok := contentCodecs[strings.ToLower(strings.TrimSpace(name))]
+	return codec, ok
+}
+
+// ContentEncodings returns the names of the registered content encodings, sorted
+func ContentEncodings() []string {
+	contentCodecsMu.RLock()
+	defer contentCodecsMu.RUnlock()
+	names := make([]string, 0, len(contentCodecs))
+	for name := range contentCodecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
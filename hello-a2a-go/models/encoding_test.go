@@ -0,0 +1,44 @@
+package models
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+// nopCodec is a content codec passing bodies through unchanged
+var nopCodec = ContentCodec{
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+	NewReader: func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil },
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestContentEncodings(t *testing.T) {
+	codec, ok := LookupContentEncoding(" GZIP")
+	if !ok {
+		t.Fatal("Expected gzip to be built in")
+	}
+	var compressed bytes.Buffer
+	w, _ := codec.NewWriter(&compressed)
+	w.Write([]byte(`{"jsonrpc":"2.0"}`))
+	w.Close()
+	r, err := codec.NewReader(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != `{"jsonrpc":"2.0"}` {
+		t.Errorf("Expected the body back, got %q", data)
+	}
+
+	if _, ok := LookupContentEncoding("zstd"); ok {
+		t.Error("Expected zstd to need registering")
+	}
+	RegisterContentEncoding("x-test", nopCodec)
+	if _, ok := LookupContentEncoding("X-Test"); !ok || !slices.Contains(ContentEncodings(), "x-test") {
+		t.Errorf("Expected x-test to be registered, got %q", ContentEncodings())
+	}
+}
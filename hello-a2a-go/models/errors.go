@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NewA2AError returns an error with code and message
+func NewA2AError(code ErrorCode, message string) *A2AError {
+	return &A2AError{JSONRPCError: JSONRPCError{Message: message}, Code: code}
+}
+
+// NewParseError reports a request body that is not valid JSON
+func NewParseError(detail string) *A2AError {
+	return NewA2AError(ErrorCodeParseError, "Invalid JSON: "+detail)
+}
+
+// NewInvalidRequestError reports a request that is not a valid JSON-RPC request
+func NewInvalidRequestError(message string) *A2AError {
+	return NewA2AError(ErrorCodeInvalidRequest, message)
+}
+
+// NewMethodNotFoundError reports a request for an unknown method
+func NewMethodNotFoundError(method string) *A2AError {
+	return NewA2AError(ErrorCodeMethodNotFound, "Method not found: "+method)
+}
+
+// NewInvalidParamsError reports parameters that do not match the method
+func NewInvalidParamsError(message string) *A2AError {
+	return NewA2AError(ErrorCodeInvalidParams, message)
+}
+
+// NewInvalidDataError reports data parts that do not match a skill's input schema, listing the
+// violations in the error's data
+func NewInvalidDataError(violations []SchemaViolation) *A2AError {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.String()
+	}
+	err := NewInvalidParamsError("Data does not match the skill's input schema: " + strings.Join(messages, "; "))
+	err.Data = map[string]interface{}{"violations": violations}
+	return err
+}
+
+// NewInternalError reports a failure of the server
+func NewInternalError(message string) *A2AError {
+	return NewA2AError(ErrorCodeInternalError, message)
+}
+
+// NewTaskNotFoundError reports an unknown or expired task
+func NewTaskNotFoundError(taskID string) *A2AError {
+	return NewA2AError(ErrorCodeTaskNotFound, "Task not found: "+taskID)
+}
+
+// NewTaskNotCancelableError reports a task that can no longer be canceled
+func NewTaskNotCancelableError(taskID string, state TaskState) *A2AError {
+	return NewA2AError(ErrorCodeTaskNotCancelable, fmt.Sprintf("Task %s is %s and cannot be canceled", taskID, state))
+}
+
+// NewPushNotificationNotSupportedError reports an agent without push notifications
+func NewPushNotificationNotSupportedError() *A2AError {
+	return NewA2AError(ErrorCodePushNotificationNotSupported, "Push notifications are not supported")
+}
+
+// NewUnsupportedOperationError reports an operation the agent does not support
+func NewUnsupportedOperationError(message string) *A2AError {
+	return NewA2AError(ErrorCodeUnsupportedOperation, message)
+}
+
+// NewContentTypeNotSupportedError reports message content the agent cannot accept
+func NewContentTypeNotSupportedError(message string) *A2AError {
+	return NewA2AError(ErrorCodeContentTypeNotSupported, message)
+}
+
+// NewInvalidAgentResponseError reports an agent response that does not follow the protocol
+func NewInvalidAgentResponseError(message string) *A2AError {
+	return NewA2AError(ErrorCodeInvalidAgentResponse, message)
+}
+
+// Error formats the error as "A2A error: <message> (code: <code>)"
+func (e *A2AError) Error() string {
+	return fmt.Sprintf("A2A error: %s (code: %d)", e.Message, e.Code)
+}
+
+// JSONRPC returns the error as sent in a JSON-RPC response
+func (e *A2AError) JSONRPC() *JSONRPCError {
+	return &JSONRPCError{Code: int(e.Code), Message: e.Message, Data: e.Data}
+}
+
+// ErrorFromJSONRPC returns the A2A error of a JSON-RPC error response
+func ErrorFromJSONRPC(e *JSONRPCError) *A2AError {
+	return &A2AError{JSONRPCError: *e, Code: ErrorCode(e.Code)}
+}
+
+// ErrorCodeOf returns the code of the A2AError in err's chain, or zero when there is none
+func ErrorCodeOf(err error) ErrorCode {
+	var a2aErr *A2AError
+	if errors.As(err, &a2aErr) {
+		return a2aErr.Code
+	}
+	return 0
+}
+
+// TaskError describes why a task failed. Failed tasks carry it in a data part of their status
+// message, under "error", next to a text part stating it for people.
+type TaskError struct {
+	// Code is the A2A or JSON-RPC error code of the failure
+	Code ErrorCode `json:"code"`
+	// Message states the reason for the failure
+	Message string `json:"message"`
+	// Hint suggests how to fix the request, when known
+	Hint string `json:"hint,omitempty"`
+}
+
+// NewErrorMessage builds the agent message describing e: its text, followed by the hint when
+// there is one, and a data part carrying e
+func NewErrorMessage(e TaskError) *Message {
+	text := e.Message
+	if e.Hint != "" {
+		text += " (" + e.Hint + ")"
+	}
+	return &Message{Role: "agent", Parts: []Part{NewTextPart(text), NewDataPart(map[string]interface{}{"error": e})}}
+}
+
+// ErrorDetails returns the TaskError the status message carries, reporting false when it
+// carries none
+func (s TaskStatus) ErrorDetails() (TaskError, bool) {
+	if s.Message == nil {
+		return TaskError{}, false
+	}
+	for _, part := range s.Message.Parts {
+		data, ok := part.(DataPart)
+		if !ok {
+			continue
+		}
+		var details struct {
+			Error *TaskError `json:"error"`
+		}
+		if (Message{Parts: []Part{data}}).Data(&details) == nil && details.Error != nil {
+			return *details.Error, true
+		}
+	}
+	return TaskError{}, false
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestA2AError(t *testing.T) {
+	tests := []struct {
+		err  *A2AError
+		code ErrorCode
+	}{
+		{NewParseError("unexpected EOF"), -32700},
+		{NewInvalidRequestError("bad"), -32600},
+		{NewMethodNotFoundError("tasks/unknown"), -32601},
+		{NewInvalidParamsError("bad"), -32602},
+		{NewInternalError("bad"), -32603},
+		{NewTaskNotFoundError("t1"), -32001},
+		{NewTaskNotCancelableError("t1", TaskStateCompleted), -32002},
+		{NewPushNotificationNotSupportedError(), -32003},
+		{NewUnsupportedOperationError("bad"), -32004},
+		{NewContentTypeNotSupportedError("image/bmp"), -32005},
+		{NewInvalidAgentResponseError("bad"), -32006},
+	}
+	for _, tt := range tests {
+		if tt.err.Code != tt.code {
+			t.Errorf("Expected code %d for %q, got %d", tt.code, tt.err.Message, tt.err.Code)
+		}
+	}
+
+	err := NewTaskNotFoundError("t1")
+	if want := "A2A error: Task not found: t1 (code: -32001)"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+	if code := ErrorCodeOf(fmt.Errorf("get failed: %w", err)); code != ErrorCodeTaskNotFound {
+		t.Errorf("Expected ErrorCodeOf to find the wrapped code, got %d", code)
+	}
+	if code := ErrorCodeOf(fmt.Errorf("plain")); code != 0 {
+		t.Errorf("Expected no code for a plain error, got %d", code)
+	}
+
+	data, _ := json.Marshal(err.JSONRPC())
+	var decoded JSONRPCError
+	json.Unmarshal(data, &decoded)
+	if back := ErrorFromJSONRPC(&decoded); back.Code != err.Code || back.Message != err.Message {
+		t.Errorf("Expected %+v to round-trip, got %+v", err, back)
+	}
+}
+
+func TestTaskErrorDetails(t *testing.T) {
+	want := TaskError{Code: ErrorCodeInvalidParams, Message: "Invalid params: bad", Hint: "send text"}
+	status := TaskStatus{State: TaskStateFailed, Message: NewErrorMessage(want)}
+	if text := status.Message.Text(); text != "Invalid params: bad (send text)" {
+		t.Errorf("Expected the hint after the reason, got %q", text)
+	}
+
+	// The details survive the wire
+	data, _ := json.Marshal(status)
+	var decoded TaskStatus
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if got, ok := decoded.ErrorDetails(); !ok || got != want {
+		t.Errorf("Expected %+v, got %+v (%v)", want, got, ok)
+	}
+
+	if _, ok := (TaskStatus{State: TaskStateFailed}).ErrorDetails(); ok {
+		t.Error("Expected no details without a status message")
+	}
+	plain := TaskStatus{Message: &Message{Role: "agent", Parts: []Part{NewTextPart("oops"), NewDataPart(map[string]interface{}{"other": 1})}}}
+	if _, ok := plain.ErrorDetails(); ok {
+		t.Error("Expected no details in a message without them")
+	}
+}
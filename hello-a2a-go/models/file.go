@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// unmarshalFileContent decodes file content by its type, or, for content following the A2A
+// spec without a type, by whether it has "bytes" or "uri"
+func unmarshalFileContent(data []byte) (FileContent, error) {
+	var probe struct {
+		Type  string          `json:"type"`
+		Bytes json.RawMessage `json:"bytes"`
+		URI   json.RawMessage `json:"uri"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	kind := probe.Type
+	if kind == "" {
+		switch {
+		case probe.Bytes != nil && probe.URI != nil:
+			return nil, fmt.Errorf("file content has both bytes and uri")
+		case probe.Bytes != nil:
+			kind = "bytes"
+		case probe.URI != nil:
+			kind = "uri"
+		}
+	}
+
+	switch kind {
+	case "bytes":
+		var content FileContentBytes
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		content.Type = "bytes"
+		return content, nil
+	case "uri":
+		var content FileContentURI
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, err
+		}
+		content.Type = "uri"
+		return content, nil
+	}
+	return nil, fmt.Errorf("unknown file content type: %s", kind)
+}
+
+// UnmarshalJSON decodes the base64 bytes of file content, accepting them with or without
+// padding; they are encoded padded, as the A2A spec requires
+func (c *FileContentBytes) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Type  string  `json:"type"`
+		Bytes *string `json:"bytes"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Type, c.Bytes = aux.Type, nil
+	if aux.Bytes == nil {
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*aux.Bytes)
+	if err != nil {
+		if decoded, err = base64.RawStdEncoding.DecodeString(*aux.Bytes); err != nil {
+			return fmt.Errorf("invalid base64 file bytes: %w", err)
+		}
+	}
+	c.Bytes = decoded
+	return nil
+}
+
+// isNullJSON reports whether a raw JSON value is absent or null
+func isNullJSON(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
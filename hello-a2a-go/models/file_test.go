@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilePart_UnmarshalSpecFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    FileContent
+		wantErr bool
+	}{
+		{
+			name: "spec bytes",
+			data: `{"kind":"file","file":{"name":"a.txt","mimeType":"text/plain","bytes":"aGk="}}`,
+			want: FileContentBytes{Type: "bytes", Bytes: []byte("hi")},
+		},
+		{
+			name: "unpadded bytes",
+			data: `{"kind":"file","fileName":"a.txt","mimeType":"text/plain","content":{"type":"bytes","bytes":"aGk"}}`,
+			want: FileContentBytes{Type: "bytes", Bytes: []byte("hi")},
+		},
+		{
+			name: "spec uri",
+			data: `{"kind":"file","file":{"name":"a.txt","mimeType":"text/plain","uri":"https://example.com/a.txt"}}`,
+			want: FileContentURI{Type: "uri", URI: "https://example.com/a.txt"},
+		},
+		{name: "bytes and uri", data: `{"kind":"file","file":{"bytes":"aGk=","uri":"https://example.com/a.txt"}}`, wantErr: true},
+		{name: "invalid base64", data: `{"kind":"file","file":{"bytes":"!!"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var part FilePart
+			err := json.Unmarshal([]byte(tt.data), &part)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %t, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if part.FileName != "a.txt" || part.MimeType != "text/plain" {
+				t.Errorf("Expected the name and MIME type, got %q %q", part.FileName, part.MimeType)
+			}
+			got, _ := json.Marshal(part.Content)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("Expected content %s, got %s", want, got)
+			}
+		})
+	}
+
+	// Bytes are encoded as padded base64
+	encoded, _ := json.Marshal(FileContentBytes{Type: "bytes", Bytes: []byte("hi")})
+	if string(encoded) != `{"type":"bytes","bytes":"aGk="}` {
+		t.Errorf("Expected padded base64, got %s", encoded)
+	}
+}
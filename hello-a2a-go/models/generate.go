@@ -0,0 +1,12 @@
+package models
+
+// The A2A types not declared by hand in this package are generated into spec_gen.go from the
+// JSON schema of the specification release named by ProtocolVersion. Hand-written types, such
+// as the Part and FileContent unions with their custom JSON encoding and the types carrying
+// legacy fields, are kept as they are. After a spec release, update the schema URL below and
+// ProtocolVersion, run go generate, and list the properties the hand-written types still lack
+// with:
+//
+//	go run ../cmd/a2aspec -schema <schema URL> -check
+
+//go:generate go run ../cmd/a2aspec -schema https://raw.githubusercontent.com/a2aproject/A2A/v0.3.0/specification/json/a2a.json -out spec_gen.go
@@ -0,0 +1,23 @@
+package models
+
+// HTTP headers carrying request authentication metadata between A2A clients and servers
+const (
+	// HeaderNonce carries a unique, single-use value identifying the request
+	HeaderNonce = "X-A2A-Nonce"
+	// HeaderTimestamp carries the request creation time in Unix seconds
+	HeaderTimestamp = "X-A2A-Timestamp"
+	// HeaderSignature carries the request's HMAC signature (see SignRequest)
+	HeaderSignature = "X-A2A-Signature"
+)
+
+// HTTP headers correlating requests, responses and log records across A2A clients and servers
+const (
+	// HeaderRequestID carries the ID of a request, echoed by the server in its response
+	HeaderRequestID = "X-Request-ID"
+	// HeaderTaskID carries the ID of the task a response concerns
+	HeaderTaskID = "X-Task-ID"
+)
+
+// HeaderExtensions carries the comma-separated URIs of the protocol extensions a client requests,
+// and a server's response the URIs of those it activated
+const HeaderExtensions = "X-A2A-Extensions"
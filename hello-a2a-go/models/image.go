@@ -0,0 +1,205 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoding for image parts
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ImageLimits bounds the images accepted in FileParts; zero values disable a limit
+type ImageLimits struct {
+	// MaxBytes is the maximum encoded image size
+	MaxBytes int
+	// MaxWidth is the maximum image width in pixels
+	MaxWidth int
+	// MaxHeight is the maximum image height in pixels
+	MaxHeight int
+}
+
+// ImageInfo describes a validated image
+type ImageInfo struct {
+	MimeType string
+	Width    int
+	Height   int
+	Size     int
+}
+
+var (
+	// ErrNotImage is returned when a file part does not carry a supported inline image
+	ErrNotImage = errors.New("file part is not a supported image")
+	// ErrImageTooLarge is returned when an image exceeds the configured limits
+	ErrImageTooLarge = errors.New("image exceeds limits")
+)
+
+// MaxDecodePixels bounds the pixels of the images DownscaleImage decodes. Compressed images can
+// declare dimensions far beyond their size, so larger ones are refused before being decoded.
+const MaxDecodePixels = 50_000_000
+
+// supportedImageTypes lists the image MIME types that can be decoded
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// SniffImageType detects the MIME type of an image from its content
+func SniffImageType(data []byte) (string, error) {
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return "", fmt.Errorf("%w: detected %s", ErrNotImage, mimeType)
+	}
+	return mimeType, nil
+}
+
+// NewImagePart builds an inline image FilePart, sniffing its MIME type from data
+func NewImagePart(fileName string, data []byte) (FilePart, error) {
+	mimeType, err := SniffImageType(data)
+	if err != nil {
+		return FilePart{}, err
+	}
+	return FilePart{
+		Type:     "file",
+		FileName: fileName,
+		MimeType: mimeType,
+		Content:  FileContentBytes{Type: "bytes", Bytes: data},
+	}, nil
+}
+
+// imageBytes returns the inline content of an image FilePart
+func imageBytes(part FilePart) ([]byte, error) {
+	content, ok := part.Content.(FileContentBytes)
+	if !ok {
+		return nil, fmt.Errorf("%w: content is not inline bytes", ErrNotImage)
+	}
+	return content.Bytes, nil
+}
+
+// ValidateImagePart checks that part carries an inline image within limits. The MIME type
+// is sniffed from the content and must match the declared one when it is set.
+func ValidateImagePart(part FilePart, limits ImageLimits) (ImageInfo, error) {
+	data, err := imageBytes(part)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	mimeType, err := SniffImageType(data)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	if part.MimeType != "" && part.MimeType != mimeType {
+		return ImageInfo{}, fmt.Errorf("%w: declared %s but content is %s", ErrNotImage, part.MimeType, mimeType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	info := ImageInfo{MimeType: mimeType, Width: config.Width, Height: config.Height, Size: len(data)}
+	if limits.MaxBytes > 0 && info.Size > limits.MaxBytes {
+		return info, fmt.Errorf("%w: %d bytes exceeds %d", ErrImageTooLarge, info.Size, limits.MaxBytes)
+	}
+	if (limits.MaxWidth > 0 && info.Width > limits.MaxWidth) || (limits.MaxHeight > 0 && info.Height > limits.MaxHeight) {
+		return info, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrImageTooLarge, info.Width, info.Height, limits.MaxWidth, limits.MaxHeight)
+	}
+	return info, nil
+}
+
+// FitImagePart validates part against limits, first downscaling images whose dimensions
+// exceed MaxWidth/MaxHeight instead of rejecting them
+func FitImagePart(part FilePart, limits ImageLimits) (FilePart, ImageInfo, error) {
+	info, err := ValidateImagePart(part, ImageLimits{})
+	if err != nil {
+		return part, info, err
+	}
+
+	if (limits.MaxWidth > 0 && info.Width > limits.MaxWidth) || (limits.MaxHeight > 0 && info.Height > limits.MaxHeight) {
+		data, _ := imageBytes(part)
+		scaled, mimeType, err := DownscaleImage(data, limits.MaxWidth, limits.MaxHeight)
+		if err != nil {
+			return part, info, err
+		}
+		part.MimeType = mimeType
+		part.Content = FileContentBytes{Type: "bytes", Bytes: scaled}
+	}
+
+	info, err = ValidateImagePart(part, limits)
+	return part, info, err
+}
+
+// DownscaleImage shrinks an image to fit within maxWidth x maxHeight, preserving its aspect
+// ratio, and returns the re-encoded image with its MIME type. JPEG images stay JPEG; other
+// formats are encoded as PNG. Images already within bounds are returned unchanged; images of
+// more than MaxDecodePixels pixels are refused with ErrImageTooLarge.
+func DownscaleImage(data []byte, maxWidth, maxHeight int) ([]byte, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if int64(config.Width)*int64(config.Height) > MaxDecodePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, config.Width, config.Height, MaxDecodePixels)
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1.0 {
+		mimeType, err := SniffImageType(data)
+		return data, mimeType, err
+	}
+
+	dstWidth := max(1, int(float64(width)*scale))
+	dstHeight := max(1, int(float64(height)*scale))
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	// Box filter: each destination pixel averages the source pixels it covers
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode image: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, "", fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), "image/png", nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNG encodes a solid-color PNG of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImagePartValidation(t *testing.T) {
+	part, err := NewImagePart("photo.png", testPNG(t, 40, 20))
+	if err != nil {
+		t.Fatalf("Failed to create image part: %v", err)
+	}
+	if part.MimeType != "image/png" {
+		t.Errorf("Expected image/png, got %s", part.MimeType)
+	}
+
+	info, err := ValidateImagePart(part, ImageLimits{MaxWidth: 100, MaxHeight: 100})
+	if err != nil {
+		t.Fatalf("Expected valid image, got %v", err)
+	}
+	if info.Width != 40 || info.Height != 20 {
+		t.Errorf("Expected 40x20, got %dx%d", info.Width, info.Height)
+	}
+
+	if _, err := ValidateImagePart(part, ImageLimits{MaxWidth: 10}); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge, got %v", err)
+	}
+
+	part.MimeType = "image/jpeg"
+	if _, err := ValidateImagePart(part, ImageLimits{}); !errors.Is(err, ErrNotImage) {
+		t.Errorf("Expected ErrNotImage for mismatched MIME type, got %v", err)
+	}
+
+	if _, err := NewImagePart("notes.txt", []byte("plain text")); !errors.Is(err, ErrNotImage) {
+		t.Errorf("Expected ErrNotImage for text, got %v", err)
+	}
+}
+
+func TestFitImagePartDownscales(t *testing.T) {
+	part, err := NewImagePart("photo.png", testPNG(t, 40, 20))
+	if err != nil {
+		t.Fatalf("Failed to create image part: %v", err)
+	}
+
+	fitted, info, err := FitImagePart(part, ImageLimits{MaxWidth: 10, MaxHeight: 10})
+	if err != nil {
+		t.Fatalf("Expected downscaled image, got %v", err)
+	}
+	if info.Width != 10 || info.Height != 5 {
+		t.Errorf("Expected 10x5, got %dx%d", info.Width, info.Height)
+	}
+
+	img, err := png.Decode(bytes.NewReader(fitted.Content.(FileContentBytes).Bytes))
+	if err != nil {
+		t.Fatalf("Failed to decode downscaled image: %v", err)
+	}
+	if r, g, b, _ := img.At(3, 3).RGBA(); r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+		t.Errorf("Expected color to be preserved, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestFitImagePartRefusesHugeImages(t *testing.T) {
+	// A tiny PNG declaring 100000x100000 pixels in its header, with the header's CRC fixed up
+	data := testPNG(t, 1, 1)
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	part, err := NewImagePart("bomb.png", data)
+	if err != nil {
+		t.Fatalf("Failed to create image part: %v", err)
+	}
+
+	if _, _, err := FitImagePart(part, ImageLimits{MaxWidth: 10, MaxHeight: 10}); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge before decoding, got %v", err)
+	}
+}
+
+func TestFilePartRoundTrip(t *testing.T) {
+	data := testPNG(t, 2, 2)
+	original := Message{
+		Role: "user",
+		Parts: []Part{
+			FilePart{Type: "file", FileName: "a.png", MimeType: "image/png", Content: FileContentBytes{Type: "bytes", Bytes: data}},
+			FilePart{Type: "file", FileName: "b.png", MimeType: "image/png", Content: FileContentURI{Type: "uri", URI: "https://example.com/b.png"}},
+		},
+	}
+
+	encoded, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+
+	bytesPart := decoded.Parts[0].(FilePart)
+	if content, ok := bytesPart.Content.(FileContentBytes); !ok || !bytes.Equal(content.Bytes, data) {
+		t.Errorf("Expected inline bytes to round-trip, got %+v", bytesPart.Content)
+	}
+	uriPart := decoded.Parts[1].(FilePart)
+	if content, ok := uriPart.Content.(FileContentURI); !ok || content.URI != "https://example.com/b.png" {
+		t.Errorf("Expected URI content to round-trip, got %+v", uriPart.Content)
+	}
+}
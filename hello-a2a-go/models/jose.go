@@ -0,0 +1,223 @@
+package models
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInvalidSignature is returned when a JWS signature does not verify
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// JSONWebKey is a public key in JWK form (RFC 7517); RSA, EC and OKP (Ed25519) keys are
+// supported
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// N and E are the modulus and exponent of an RSA key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Crv names the curve of an EC or OKP key, whose coordinates are X and, for EC keys, Y
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys, as served at a jwks_uri
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Key returns the key kid of the set; an empty kid names the only key of a set holding one
+func (s JSONWebKeySet) Key(kid string) (JSONWebKey, bool) {
+	if kid == "" && len(s.Keys) == 1 {
+		return s.Keys[0], true
+	}
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+	return JSONWebKey{}, false
+}
+
+// curves maps JWK curve names to elliptic curves
+var curves = map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+
+// NewJSONWebKey returns the JWK of public key pub with key ID kid, naming the algorithm its
+// private key signs agent cards with (see SignAgentCard)
+func NewJSONWebKey(kid string, pub crypto.PublicKey) (JSONWebKey, error) {
+	alg, err := signingAlgorithm(pub)
+	if err != nil {
+		return JSONWebKey{}, err
+	}
+	key := JSONWebKey{Kid: kid, Alg: alg, Use: "sig"}
+	encode := base64.RawURLEncoding.EncodeToString
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		key.Kty, key.N, key.E = "RSA", encode(pub.N.Bytes()), encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		key.Kty, key.Crv = "EC", pub.Curve.Params().Name
+		key.X, key.Y = encode(pub.X.FillBytes(make([]byte, size))), encode(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		key.Kty, key.Crv, key.X = "OKP", "Ed25519", encode(pub)
+	}
+	return key, nil
+}
+
+// PublicKey decodes the key as an *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, errN := decode(k.N)
+		e, errE := decode(k.E)
+		if errN != nil || errE != nil {
+			return nil, fmt.Errorf("invalid RSA key %q", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curve := curves[k.Crv]
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		if curve == nil || errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid EC key %q", k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := decode(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid OKP key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// signingAlgorithm returns the JWS algorithm signing with the private key of pub: RS256 for
+// RSA keys, ES256, ES384 or ES512 for ECDSA keys by curve, and EdDSA for Ed25519 keys
+func signingAlgorithm(pub crypto.PublicKey) (string, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		case elliptic.P521():
+			return "ES512", nil
+		}
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	}
+	return "", fmt.Errorf("unsupported signing key %T", pub)
+}
+
+// algorithmHash returns the hash of an RS* or ES* algorithm by its size suffix
+func algorithmHash(alg string) (crypto.Hash, error) {
+	if len(alg) == 5 {
+		switch alg[2:] {
+		case "256":
+			return crypto.SHA256, nil
+		case "384":
+			return crypto.SHA384, nil
+		case "512":
+			return crypto.SHA512, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// digest hashes signed with hashType
+func digest(hashType crypto.Hash, signed []byte) []byte {
+	switch hashType {
+	case crypto.SHA384:
+		sum := sha512.Sum384(signed)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(signed)
+		return sum[:]
+	}
+	sum := sha256.Sum256(signed)
+	return sum[:]
+}
+
+// signJWS signs the JWS signing input signed with key using algorithm alg, encoding ECDSA
+// signatures as the fixed-size concatenation of r and s
+func signJWS(alg string, key crypto.Signer, signed []byte) ([]byte, error) {
+	if alg == "EdDSA" {
+		return key.Sign(rand.Reader, signed, crypto.Hash(0))
+	}
+	hashType, err := algorithmHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.Sign(rand.Reader, digest(hashType, signed), hashType)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return signature, nil
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &rs); err != nil {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	return append(rs.R.FillBytes(make([]byte, size)), rs.S.FillBytes(make([]byte, size))...), nil
+}
+
+// VerifyJWSSignature checks the signature of the JWS signing input signed, made with
+// algorithm alg (RS256, RS384, RS512, ES256, ES384, ES512 or EdDSA) by the private key of
+// key. It returns ErrInvalidSignature for a signature that does not verify.
+func VerifyJWSSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	if key, ok := key.(ed25519.PublicKey); ok && alg == "EdDSA" {
+		if !ed25519.Verify(key, signed, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	hashType, err := algorithmHash(alg)
+	if err != nil {
+		return err
+	}
+	sum := digest(hashType, signed)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg[:2] != "RS" {
+			break
+		}
+		if err := rsa.VerifyPKCS1v15(key, hashType, sum, signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(signature) != 2*size {
+			break
+		}
+		r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, sum, r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q does not match its key", alg)
+}
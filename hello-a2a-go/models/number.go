@@ -0,0 +1,80 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// maxExactFloat is the largest magnitude below which every integer is exact in a float64
+const maxExactFloat = 1 << 53
+
+// DecodeJSON decodes data into v like json.Unmarshal, except that numbers stored in untyped
+// values, such as metadata maps and DataPart payloads, are decoded as json.Number so large
+// integers like IDs and timestamps keep every digit
+func DecodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+// Int64Value converts a decoded JSON number to an int64. It accepts json.Number, the float64
+// produced by json.Unmarshal when it is an integer small enough to be exact, and Go integer
+// types, and reports false for anything else, including numbers that would lose precision.
+func Int64Value(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		return i, err == nil
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > maxExactFloat {
+			return 0, false
+		}
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Float64Value converts a decoded JSON number to a float64, accepting json.Number, float64
+// and Go integer types
+func Float64Value(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// MetadataInt64 returns the integer stored under key in metadata (see Int64Value)
+func MetadataInt64(metadata map[string]interface{}, key string) (int64, bool) {
+	return Int64Value(metadata[key])
+}
+
+// MetadataFloat64 returns the number stored under key in metadata (see Float64Value)
+func MetadataFloat64(metadata map[string]interface{}, key string) (float64, bool) {
+	return Float64Value(metadata[key])
+}
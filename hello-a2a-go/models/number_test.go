@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeJSON_PreservesLargeIntegers(t *testing.T) {
+	data := []byte(`{"id":"t1","message":{"role":"user","parts":[{"kind":"data","data":{"orderId":9007199254740993}}]},"metadata":{"requestedAt":1717171717171717171,"ratio":0.5}}`)
+
+	var params TaskSendParams
+	if err := DecodeJSON(data, &params); err != nil {
+		t.Fatalf("DecodeJSON failed: %v", err)
+	}
+
+	if got, ok := MetadataInt64(params.Metadata, "requestedAt"); !ok || got != 1717171717171717171 {
+		t.Errorf("Expected requestedAt 1717171717171717171, got %d (ok=%v)", got, ok)
+	}
+	if got, ok := MetadataFloat64(params.Metadata, "ratio"); !ok || got != 0.5 {
+		t.Errorf("Expected ratio 0.5, got %v (ok=%v)", got, ok)
+	}
+	if _, ok := MetadataInt64(params.Metadata, "ratio"); ok {
+		t.Error("Expected a fractional number not to convert to int64")
+	}
+
+	// DataPart payloads keep their digits even when decoded with json.Unmarshal
+	var message Message
+	if err := json.Unmarshal([]byte(`{"role":"user","parts":[{"kind":"data","data":{"orderId":9007199254740993}}]}`), &message); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	payload := message.Parts[0].(DataPart).Data.(map[string]interface{})
+	if got, ok := Int64Value(payload["orderId"]); !ok || got != 9007199254740993 {
+		t.Errorf("Expected orderId 9007199254740993, got %d (ok=%v)", got, ok)
+	}
+
+	if err := DecodeJSON([]byte(`{"id":"t1"} {}`), &params); err == nil {
+		t.Error("Expected trailing data to be rejected")
+	}
+}
+
+func TestInt64Value(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  int64
+		ok    bool
+	}{
+		{"json number", json.Number("42"), 42, true},
+		{"exact float", float64(42), 42, true},
+		{"fractional float", 4.2, 0, false},
+		{"float beyond exact range", float64(1 << 60), 0, false},
+		{"int", 7, 7, true},
+		{"string", "42", 0, false},
+		{"missing", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Int64Value(tt.value)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Int64Value(%v) = %d, %v; want %d, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoDataPart is returned by Message.Data when the message has no data part
+var ErrNoDataPart = errors.New("message has no data part")
+
+// NewTextPart builds a text part
+func NewTextPart(text string) TextPart {
+	return TextPart{Type: "text", Text: text}
+}
+
+// NewFilePartFromPath builds an inline file part from the file at path, named by its base
+// name. Its MIME type comes from the file extension, or else is sniffed from its content.
+func NewFilePartFromPath(path string) (FilePart, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FilePart{}, fmt.Errorf("reading file part: %w", err)
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	// Drop parameters such as "; charset=utf-8", which are not part of the media type
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return FilePart{
+		Type:     "file",
+		FileName: filepath.Base(path),
+		MimeType: strings.TrimSpace(mimeType),
+		Content:  FileContentBytes{Type: "bytes", Bytes: data},
+	}, nil
+}
+
+// NewDataPart builds a data part carrying v, which must marshal to JSON
+func NewDataPart(v interface{}) DataPart {
+	return DataPart{Type: "data", Data: v}
+}
+
+// Text joins the text parts of the message
+func (m Message) Text() string {
+	var b strings.Builder
+	for _, part := range m.Parts {
+		if text, ok := part.(TextPart); ok {
+			b.WriteString(text.Text)
+		}
+	}
+	return b.String()
+}
+
+// Files returns the file parts of the message
+func (m Message) Files() []FilePart {
+	var files []FilePart
+	for _, part := range m.Parts {
+		if file, ok := part.(FilePart); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Data decodes the first data part of the message into dst, as json.Unmarshal would, so that
+// dst may be a struct, a map or any other JSON target. It returns ErrNoDataPart when the
+// message has none.
+func (m Message) Data(dst interface{}) error {
+	for _, part := range m.Parts {
+		data, ok := part.(DataPart)
+		if !ok {
+			continue
+		}
+		encoded, err := json.Marshal(data.Data)
+		if err != nil {
+			return fmt.Errorf("encoding data part: %w", err)
+		}
+		if err := json.Unmarshal(encoded, dst); err != nil {
+			return fmt.Errorf("decoding data part: %w", err)
+		}
+		return nil
+	}
+	return ErrNoDataPart
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFilePartFromPath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "notes.txt", data: "hello", want: "text/plain"},
+		{name: "report.json", data: `{"ok":true}`, want: "application/json"},
+		{name: "unknown", data: "\x89PNG\r\n\x1a\n", want: "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			part, err := NewFilePartFromPath(path)
+			if err != nil {
+				t.Fatalf("Failed to create file part: %v", err)
+			}
+			if part.Type != "file" || part.FileName != tt.name || part.MimeType != tt.want {
+				t.Errorf("Expected file %s of type %s, got %+v", tt.name, tt.want, part)
+			}
+			if content, ok := part.Content.(FileContentBytes); !ok || string(content.Bytes) != tt.data {
+				t.Errorf("Expected the file's bytes inline, got %+v", part.Content)
+			}
+		})
+	}
+
+	if _, err := NewFilePartFromPath(filepath.Join(dir, "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestMessageHelpers(t *testing.T) {
+	file := FilePart{Type: "file", FileName: "a.txt", Content: FileContentURI{Type: "uri", URI: "https://example.com/a.txt"}}
+	message := Message{Role: "user", Parts: []Part{
+		NewTextPart("Translate "),
+		file,
+		NewDataPart(map[string]interface{}{"targetLanguage": "fr", "count": 2}),
+		NewTextPart("this"),
+		NewDataPart("ignored"),
+	}}
+
+	// Round trip so that the data part holds decoded JSON, as received
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received Message
+	if err := json.Unmarshal(encoded, &received); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+
+	for _, m := range []Message{message, received} {
+		if got := m.Text(); got != "Translate this" {
+			t.Errorf("Expected the joined text, got %q", got)
+		}
+		if files := m.Files(); len(files) != 1 || files[0].FileName != "a.txt" {
+			t.Errorf("Expected the file part, got %+v", files)
+		}
+		var options struct {
+			TargetLanguage string `json:"targetLanguage"`
+			Count          int    `json:"count"`
+		}
+		if err := m.Data(&options); err != nil || options.TargetLanguage != "fr" || options.Count != 2 {
+			t.Errorf("Expected the first data part decoded, got %+v (%v)", options, err)
+		}
+	}
+
+	var dst map[string]interface{}
+	if err := (Message{Parts: []Part{NewTextPart("hi")}}).Data(&dst); !errors.Is(err, ErrNoDataPart) {
+		t.Errorf("Expected ErrNoDataPart, got %v", err)
+	}
+}
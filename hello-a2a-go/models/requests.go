@@ -2,15 +2,64 @@ package models
 
 // MessageSendParams represents parameters for sending a message (A2A v0.3.0)
 type MessageSendParams struct {
-	ID      string  `json:"id"`
-	Message Message `json:"message"`
-	Config  *MessageSendConfiguration `json:"config,omitempty"`
+	ID       string                    `json:"id"`
+	Message  Message                   `json:"message"`
+	Config   *MessageSendConfiguration `json:"config,omitempty"`
+	Metadata map[string]interface{}    `json:"metadata,omitempty"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for MessageSendParams. Besides this
+// package's "config" field it accepts the A2A spec's "configuration" object.
+func (p *MessageSendParams) UnmarshalJSON(data []byte) error {
+	type Alias MessageSendParams
+	aux := &struct {
+		Configuration *MessageSendConfiguration `json:"configuration"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := DecodeJSON(data, aux); err != nil {
+		return err
+	}
+	if p.Config == nil {
+		p.Config = aux.Configuration
+	}
+	return nil
 }
 
 // MessageSendConfiguration represents configuration for message sending
 type MessageSendConfiguration struct {
-	Streaming         *bool                     `json:"streaming,omitempty"`
-	PushNotifications *PushNotificationConfig   `json:"pushNotifications,omitempty"`
+	Streaming         *bool                   `json:"streaming,omitempty"`
+	PushNotifications *PushNotificationConfig `json:"pushNotifications,omitempty"`
+	// AcceptedOutputModes lists the output MIME types the client accepts, most preferred
+	// first; wildcards such as "text/*" are allowed. Empty accepts any mode.
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// Blocking false asks message/send to answer as soon as the task is accepted, usually
+	// submitted, rather than once it finishes; nil waits
+	Blocking *bool `json:"blocking,omitempty"`
+	// HistoryLength limits the history of the task answered to its most recent messages
+	HistoryLength *int `json:"historyLength,omitempty"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for MessageSendConfiguration. Besides this
+// package's "pushNotifications" field it accepts the A2A spec's "pushNotificationConfig".
+func (c *MessageSendConfiguration) UnmarshalJSON(data []byte) error {
+	type Alias MessageSendConfiguration
+	aux := &struct {
+		PushNotificationConfig *PushNotificationConfig `json:"pushNotificationConfig"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+
+	if err := DecodeJSON(data, aux); err != nil {
+		return err
+	}
+	if c.PushNotifications == nil {
+		c.PushNotifications = aux.PushNotificationConfig
+	}
+	return nil
 }
 
 // Legacy TaskSendParams for backwards compatibility
@@ -27,6 +76,11 @@ type TaskSendParams struct {
 	PushNotification *PushNotificationConfig `json:"pushNotification,omitempty"`
 	// HistoryLength is an optional parameter to specify how much message history to include
 	HistoryLength *int `json:"historyLength,omitempty"`
+	// AcceptedOutputModes lists the output MIME types the client accepts, most preferred first
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// Blocking false answers with the task as soon as it is accepted instead of once it
+	// finishes; nil waits
+	Blocking *bool `json:"blocking,omitempty"`
 	// Metadata is optional metadata associated with sending this message
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -46,6 +100,28 @@ type TaskQueryParams struct {
 	HistoryLength *int `json:"historyLength,omitempty"`
 }
 
+// MessageListParams filters and pages the messages listed by message/list. Messages match
+// every filter set; tasks are listed in ID order and their messages oldest first.
+type MessageListParams struct {
+	// TaskID limits the listing to the messages of one task
+	TaskID string `json:"taskId,omitempty"`
+	// ID is an alias of TaskID kept for clients sending TaskQueryParams
+	ID string `json:"id,omitempty"`
+	// ContextID limits the listing to the tasks of one conversation
+	ContextID string `json:"contextId,omitempty"`
+	// State limits the listing to tasks in this state
+	State TaskState `json:"state,omitempty"`
+	// Since and Until bound, as RFC 3339 times, when the tasks last changed status; Until is
+	// exclusive
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+	// PageSize is the maximum number of messages returned; the server applies a default and
+	// a maximum
+	PageSize int `json:"pageSize,omitempty"`
+	// PageToken continues a listing from the NextPageToken of the previous page
+	PageToken string `json:"pageToken,omitempty"`
+}
+
 // PushNotificationConfig represents the configuration for push notifications
 type PushNotificationConfig struct {
 	// URL is the endpoint where the agent should send notifications
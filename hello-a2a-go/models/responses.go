@@ -1,21 +1,37 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // ErrorCode represents the error codes used in the A2A protocol
 type ErrorCode int
 
+// JSON-RPC error codes, and the A2A error codes of the A2A v0.3.0 specification
 const (
-	ErrorCodeParseError                   ErrorCode = -32700
-	ErrorCodeInvalidRequest               ErrorCode = -32600
-	ErrorCodeMethodNotFound               ErrorCode = -32601
-	ErrorCodeInvalidParams                ErrorCode = -32602
-	ErrorCodeInternalError                ErrorCode = -32603
-	ErrorCodeTaskNotFound                 ErrorCode = -32000
-	ErrorCodeTaskNotCancelable            ErrorCode = -32001
-	ErrorCodePushNotificationNotSupported ErrorCode = -32002
-	ErrorCodeUnsupportedOperation         ErrorCode = -32003
+	ErrorCodeParseError                             ErrorCode = -32700
+	ErrorCodeInvalidRequest                         ErrorCode = -32600
+	ErrorCodeMethodNotFound                         ErrorCode = -32601
+	ErrorCodeInvalidParams                          ErrorCode = -32602
+	ErrorCodeInternalError                          ErrorCode = -32603
+	ErrorCodeTaskNotFound                           ErrorCode = -32001
+	ErrorCodeTaskNotCancelable                      ErrorCode = -32002
+	ErrorCodePushNotificationNotSupported           ErrorCode = -32003
+	ErrorCodeUnsupportedOperation                   ErrorCode = -32004
+	ErrorCodeContentTypeNotSupported                ErrorCode = -32005
+	ErrorCodeInvalidAgentResponse                   ErrorCode = -32006
+	ErrorCodeAuthenticatedExtendedCardNotConfigured ErrorCode = -32007
+	// ErrorCodeQuotaExceeded is an extension reporting an exhausted caller quota
+	ErrorCodeQuotaExceeded ErrorCode = -32029
+	// ErrorCodeServerBusy is an extension reporting that the agent's task queue is full
+	ErrorCodeServerBusy ErrorCode = -32030
+	// ErrorCodeRateLimited is an extension reporting a caller over its request rate limit
+	ErrorCodeRateLimited ErrorCode = -32031
 )
 
-// A2AError represents an error in the A2A protocol
+// A2AError represents an error in the A2A protocol. It implements error, so handlers and
+// clients can return it and callers can branch on its Code with errors.As or ErrorCodeOf.
 type A2AError struct {
 	JSONRPCError
 	Code ErrorCode `json:"code"`
@@ -78,3 +94,43 @@ type SendMessageStreamingResponse struct {
 	// Result contains the streaming update result
 	Result interface{} `json:"result,omitempty"`
 }
+
+// SendMessageResult is the result of message/send, discriminated on its kind: the task the
+// message created or continued, or a Message the agent answered with directly. Exactly one of
+// Task and Message is set. Results without a kind, from agents predating it, are tasks.
+type SendMessageResult struct {
+	Task    *Task
+	Message *Message
+}
+
+// MarshalJSON encodes the task or message of r
+func (r SendMessageResult) MarshalJSON() ([]byte, error) {
+	if r.Message != nil {
+		return json.Marshal(r.Message)
+	}
+	return json.Marshal(r.Task)
+}
+
+// UnmarshalJSON decodes a task or a message by its kind; null leaves r unchanged
+func (r *SendMessageResult) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var kind struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return err
+	}
+
+	*r = SendMessageResult{}
+	switch kind.Kind {
+	case KindMessage:
+		r.Message = &Message{}
+		return DecodeJSON(data, r.Message)
+	case KindTask, "":
+		r.Task = &Task{}
+		return DecodeJSON(data, r.Task)
+	}
+	return fmt.Errorf("unknown result kind: %s", kind.Kind)
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSendMessageResult(t *testing.T) {
+	data, err := json.Marshal(SendMessageResult{Task: &Task{ID: "t1"}})
+	if err != nil || !strings.Contains(string(data), `"kind":"task"`) {
+		t.Fatalf("Expected the task to be encoded with its kind, got %s (%v)", data, err)
+	}
+	var result SendMessageResult
+	if err := json.Unmarshal(data, &result); err != nil || result.Task == nil || result.Task.ID != "t1" || result.Message != nil {
+		t.Errorf("Expected the task to round-trip, got %+v (%v)", result, err)
+	}
+
+	data, _ = json.Marshal(SendMessageResult{Message: &Message{Role: "agent", Parts: []Part{TextPart{Type: "text", Text: "Hi"}}}})
+	result = SendMessageResult{}
+	if err := json.Unmarshal(data, &result); err != nil || result.Message == nil || result.Task != nil || result.Message.Parts[0].(TextPart).Text != "Hi" {
+		t.Errorf("Expected the message to round-trip, got %+v (%v)", result, err)
+	}
+
+	result = SendMessageResult{}
+	if err := json.Unmarshal([]byte(`{"id":"t2","status":{"state":"completed"}}`), &result); err != nil || result.Task == nil || result.Task.ID != "t2" {
+		t.Errorf("Expected a result without a kind to decode as a task, got %+v (%v)", result, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"kind":"artifact"}`), &result); err == nil {
+		t.Error("Expected an unknown kind to fail")
+	}
+}
@@ -0,0 +1,297 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONSchema is a JSON Schema describing the data parts a skill accepts or returns. Validate
+// checks the keywords that describe plain JSON data: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf and not; other keywords,
+// such as title, description and format, are ignored.
+type JSONSchema map[string]interface{}
+
+// SchemaViolation is a value that does not match a JSON Schema
+type SchemaViolation struct {
+	// Pointer is the JSON Pointer (RFC 6901) of the value, "" for the whole document
+	Pointer string `json:"pointer"`
+	// Message describes how the value violates the schema
+	Message string `json:"message"`
+}
+
+// String formats the violation as "<pointer>: <message>"
+func (v SchemaViolation) String() string {
+	return v.Pointer + ": " + v.Message
+}
+
+// Validate returns the violations of schema by value. The schema and value are first converted
+// to their JSON form, so both may be built from Go types such as []string or nested JSONSchema
+// values; an empty schema accepts any value.
+func (s JSONSchema) Validate(value interface{}) []SchemaViolation {
+	var schema map[string]interface{}
+	if err := roundTripJSON(s, &schema); err != nil {
+		return []SchemaViolation{{Message: "schema is not JSON: " + err.Error()}}
+	}
+	var generic interface{}
+	if err := roundTripJSON(value, &generic); err != nil {
+		return []SchemaViolation{{Message: "value is not JSON: " + err.Error()}}
+	}
+	return validateSchema(schema, generic, "")
+}
+
+// roundTripJSON decodes the JSON encoding of v into dst
+func roundTripJSON(v interface{}, dst interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return DecodeJSON(data, dst)
+}
+
+// validateSchema returns the violations of schema by value at pointer
+func validateSchema(schema map[string]interface{}, value interface{}, pointer string) []SchemaViolation {
+	var violations []SchemaViolation
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesType(value, types) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		// Further keywords would only repeat the type mismatch
+		return violations
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsJSON(enum, value) {
+		fail("must be one of %s", compactJSON(enum))
+	}
+	if constant, ok := schema["const"]; ok && !equalJSON(constant, value) {
+		fail("must be %s", compactJSON(constant))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		violations = append(violations, validateObject(schema, value, pointer)...)
+	case []interface{}:
+		if n, ok := schemaInt(schema["minItems"]); ok && len(value) < n {
+			fail("must have at least %d items", n)
+		}
+		if n, ok := schemaInt(schema["maxItems"]); ok && len(value) > n {
+			fail("must have at most %d items", n)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				violations = append(violations, validateSchema(items, item, pointer+"/"+strconv.Itoa(i))...)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if n, ok := schemaInt(schema["minLength"]); ok && length < n {
+			fail("must be at least %d characters", n)
+		}
+		if n, ok := schemaInt(schema["maxLength"]); ok && length > n {
+			fail("must be at most %d characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil {
+				fail("schema pattern %q is invalid", pattern)
+			} else if !re.MatchString(value) {
+				fail("must match pattern %q", pattern)
+			}
+		}
+	case json.Number:
+		n, _ := value.Float64()
+		if limit, ok := schemaNumber(schema["minimum"]); ok && n < limit {
+			fail("must be at least %v", limit)
+		}
+		if limit, ok := schemaNumber(schema["maximum"]); ok && n > limit {
+			fail("must be at most %v", limit)
+		}
+		if limit, ok := schemaNumber(schema["exclusiveMinimum"]); ok && n <= limit {
+			fail("must be greater than %v", limit)
+		}
+		if limit, ok := schemaNumber(schema["exclusiveMaximum"]); ok && n >= limit {
+			fail("must be less than %v", limit)
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				violations = append(violations, validateSchema(sub, value, pointer)...)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && countMatches(anyOf, value, pointer) == 0 {
+		fail("must match at least one schema of anyOf")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if n := countMatches(oneOf, value, pointer); n != 1 {
+			fail("must match exactly one schema of oneOf, matched %d", n)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(validateSchema(not, value, pointer)) == 0 {
+		fail("must not match the schema of not")
+	}
+	return violations
+}
+
+// validateObject returns the violations of the object keywords of schema by object at pointer,
+// checking properties in sorted order so violations are reported deterministically
+func validateObject(schema map[string]interface{}, object map[string]interface{}, pointer string) []SchemaViolation {
+	var violations []SchemaViolation
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, present := object[name]; !present {
+					violations = append(violations, SchemaViolation{Pointer: pointer + "/" + escapePointer(name), Message: "is required"})
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		at := pointer + "/" + escapePointer(name)
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			violations = append(violations, validateSchema(property, object[name], at)...)
+			continue
+		}
+		if _, declared := properties[name]; declared {
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, SchemaViolation{Pointer: at, Message: "is not allowed"})
+			}
+		case map[string]interface{}:
+			violations = append(violations, validateSchema(additional, object[name], at)...)
+		}
+	}
+	return violations
+}
+
+// countMatches returns how many of schemas value matches
+func countMatches(schemas []interface{}, value interface{}, pointer string) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub, ok := sub.(map[string]interface{}); ok && len(validateSchema(sub, value, pointer)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// schemaTypes returns the types named by the type keyword, a string or an array of strings
+func schemaTypes(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var types []string
+		for _, t := range v {
+			if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesType reports whether value is of one of the JSON Schema types
+func matchesType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if f, err := value.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaNumber converts a numeric keyword of a schema, decoded with or without UseNumber
+func schemaNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// schemaInt converts a non-negative integer keyword of a schema
+func schemaInt(v interface{}) (int, bool) {
+	f, ok := schemaNumber(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// containsJSON reports whether values holds a value equal to value
+func containsJSON(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equalJSON(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON reports whether a and b encode to the same JSON, comparing numbers by value
+func equalJSON(a, b interface{}) bool {
+	if x, ok := schemaNumber(a); ok {
+		y, ok := schemaNumber(b)
+		return ok && x == y
+	}
+	return compactJSON(a) == compactJSON(b)
+}
+
+// compactJSON returns v encoded as JSON, for messages and comparisons
+func compactJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// escapePointer escapes a property name as a JSON Pointer reference token
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["text", "target"],
+		"properties": {
+			"text": {"type": "string", "minLength": 1},
+			"target": {"enum": ["en", "fr", "zh"]},
+			"priority": {"type": "integer", "minimum": 1, "maximum": 5},
+			"glossary": {"type": "array", "maxItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}},
+			"a/b": {"type": "boolean"}
+		},
+		"additionalProperties": false
+	}`), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []SchemaViolation
+	}{
+		{
+			name:  "valid",
+			value: `{"text": "Hello", "target": "fr", "priority": 3, "glossary": ["hi"]}`,
+		},
+		{
+			name:  "wrong type",
+			value: `["Hello"]`,
+			want:  []SchemaViolation{{Pointer: "", Message: "expected object, got array"}},
+		},
+		{
+			name:  "missing and invalid properties",
+			value: `{"text": "", "priority": 2.5, "glossary": ["ok", "Bad", "no"], "a/b": true, "extra": 1}`,
+			want: []SchemaViolation{
+				{Pointer: "/target", Message: "is required"},
+				{Pointer: "/extra", Message: "is not allowed"},
+				{Pointer: "/glossary", Message: "must have at most 2 items"},
+				{Pointer: "/glossary/1", Message: `must match pattern "^[a-z]+$"`},
+				{Pointer: "/priority", Message: "expected integer, got number"},
+				{Pointer: "/text", Message: "must be at least 1 characters"},
+			},
+		},
+		{
+			name:  "escaped pointer",
+			value: `{"text": "Hi", "target": "de", "a/b": "yes"}`,
+			want: []SchemaViolation{
+				{Pointer: "/a~1b", Message: "expected boolean, got string"},
+				{Pointer: "/target", Message: `must be one of ["en","fr","zh"]`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := DecodeJSON([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			if got := schema.Validate(value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaValidate_GoValues(t *testing.T) {
+	// Schemas and values built in Go are validated in their JSON form
+	schema := JSONSchema{
+		"type":     "object",
+		"required": []string{"count"},
+		"properties": map[string]JSONSchema{
+			"count": {"type": "integer", "exclusiveMinimum": 0},
+		},
+		"oneOf": []JSONSchema{{"required": []string{"count"}}, {"required": []string{"total"}}},
+	}
+	if got := schema.Validate(struct {
+		Count int `json:"count"`
+	}{3}); len(got) != 0 {
+		t.Errorf("Expected a valid struct, got %v", got)
+	}
+	got := schema.Validate(map[string]int{"count": 0, "total": 1})
+	want := []SchemaViolation{
+		{Pointer: "/count", Message: "must be greater than 0"},
+		{Pointer: "", Message: "must match exactly one schema of oneOf, matched 2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := (JSONSchema{}).Validate("anything"); len(got) != 0 {
+		t.Errorf("Expected an empty schema to accept any value, got %v", got)
+	}
+}
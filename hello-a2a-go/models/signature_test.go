@@ -0,0 +1,56 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignRequest(t *testing.T) {
+	secret := []byte("shared-secret")
+	body := []byte(`{"jsonrpc":"2.0","method":"message/send"}`)
+	signature := SignRequest(secret, "POST", "/a2a", "1700000000", "abc", body)
+
+	if !VerifyRequestSignature(secret, "POST", "/a2a", "1700000000", "abc", body, signature) {
+		t.Fatal("Expected signature to verify")
+	}
+
+	tampered := []struct {
+		name      string
+		secret    []byte
+		method    string
+		path      string
+		timestamp string
+		nonce     string
+		body      []byte
+	}{
+		{"secret", []byte("other"), "POST", "/a2a", "1700000000", "abc", body},
+		{"method", secret, "PUT", "/a2a", "1700000000", "abc", body},
+		{"path", secret, "POST", "/admin/keys", "1700000000", "abc", body},
+		{"timestamp", secret, "POST", "/a2a", "1700000001", "abc", body},
+		{"nonce", secret, "POST", "/a2a", "1700000000", "abd", body},
+		{"body", secret, "POST", "/a2a", "1700000000", "abc", []byte(`{"jsonrpc":"2.0","method":"tasks/cancel"}`)},
+	}
+	for _, tt := range tampered {
+		if VerifyRequestSignature(tt.secret, tt.method, tt.path, tt.timestamp, tt.nonce, tt.body, signature) {
+			t.Errorf("Expected signature with tampered %s to be rejected", tt.name)
+		}
+	}
+}
+
+func TestSignedPath(t *testing.T) {
+	outgoing, err := http.NewRequest("POST", "http://agent.example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SignedPath(outgoing); got != "/" {
+		t.Errorf("Expected / for an outgoing request without a path, got %q", got)
+	}
+
+	// The path as received is signed, even once a handler strips a prefix from r.URL
+	incoming := httptest.NewRequest("POST", "/agents/a2a?x=1", nil)
+	incoming.URL.Path = "/a2a"
+	if got := SignedPath(incoming); got != "/agents/a2a" {
+		t.Errorf("Expected the received path, got %q", got)
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // FileContentBase represents the base structure for file content
 type FileContentBase struct {
 	// Name is the optional name of the file
@@ -26,21 +28,106 @@ type Artifact struct {
 	LastChunk *bool `json:"lastChunk,omitempty"`
 }
 
+// UnmarshalJSON decodes the parts of an artifact by their kind
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	type Alias Artifact
+	aux := &struct {
+		Parts []json.RawMessage `json:"parts"`
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	parts, err := unmarshalParts(aux.Parts)
+	if err != nil {
+		return err
+	}
+	a.Parts = parts
+	return nil
+}
+
+// IsTerminal reports whether tasks in state s are finished and accept no further messages
+func (s TaskState) IsTerminal() bool {
+	switch s {
+	case TaskStateCompleted, TaskStateCanceled, TaskStateFailed, TaskStateRejected:
+		return true
+	}
+	return false
+}
+
 // TaskStatus represents the status of a task
 type TaskStatus struct {
 	State TaskState `json:"state"`
+	// Message is an optional agent message describing the current status
+	Message *Message `json:"message,omitempty"`
+	// Timestamp is when the status was recorded, in RFC 3339 format
+	Timestamp string `json:"timestamp,omitempty"`
 }
 
 // Task represents an A2A task
 type Task struct {
-	ID     string     `json:"id"`
-	Status TaskStatus `json:"status"`
+	ID string `json:"id"`
+	// ContextID groups the tasks of one conversation
+	ContextID string `json:"contextId,omitempty"`
+	// SessionID is the legacy session identifier the task was sent with
+	SessionID *string    `json:"sessionId,omitempty"`
+	Status    TaskStatus `json:"status"`
+	// Artifacts are the outputs produced by the agent
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// History is the messages exchanged for the task, oldest first
+	History []Message `json:"history,omitempty"`
+	// Metadata is optional metadata associated with the task
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Kind is always KindTask on the wire; it is filled in when empty
+	Kind string `json:"kind,omitempty"`
+}
+
+// KindTask is the kind of a Task
+const KindTask = "task"
+
+// MarshalJSON implements custom JSON marshaling for Task, setting its kind
+func (t Task) MarshalJSON() ([]byte, error) {
+	type Alias Task
+	if t.Kind == "" {
+		t.Kind = KindTask
+	}
+	return json.Marshal(Alias(t))
 }
 
 // TaskHistory represents the history of a task
 type TaskHistory struct {
 	// MessageHistory is the list of messages in chronological order
 	MessageHistory []Message `json:"messageHistory,omitempty"`
+	// StatusHistory is the statuses the task passed through, oldest first, each stamped with
+	// the time it was entered
+	StatusHistory []TaskStatus `json:"statusHistory,omitempty"`
+}
+
+// Conversation is the tasks of one conversation and the messages exchanged in them, as returned
+// by contexts/get
+type Conversation struct {
+	ContextID string `json:"contextId"`
+	// Tasks are the tasks of the conversation in the order they were created
+	Tasks []Task `json:"tasks"`
+	// Messages are the messages of the tasks, task by task in the same order
+	Messages []Message `json:"messages"`
+}
+
+// ContextQueryParams names the conversation contexts/get returns
+type ContextQueryParams struct {
+	ContextID string `json:"contextId"`
+}
+
+// MessageListResult is a page of messages listed by message/list
+type MessageListResult struct {
+	// Messages are the messages of the page, each naming its task and conversation
+	Messages []Message `json:"messages"`
+	// NextPageToken continues the listing; it is empty on the last page
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // TaskStatusUpdateEvent represents an event for task status updates
@@ -53,6 +140,23 @@ type TaskStatusUpdateEvent struct {
 	Final *bool `json:"final,omitempty"`
 	// Metadata is optional metadata associated with this update event
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Sequence numbers the events of a task from 1, increasing monotonically across its
+	// streams, so a client can order them and skip those it has seen
+	Sequence int64 `json:"sequence,omitempty"`
+	// Kind is always KindStatusUpdate on the wire; it is filled in when empty
+	Kind string `json:"kind,omitempty"`
+}
+
+// KindStatusUpdate is the kind of a TaskStatusUpdateEvent
+const KindStatusUpdate = "status-update"
+
+// MarshalJSON implements custom JSON marshaling for TaskStatusUpdateEvent, setting its kind
+func (e TaskStatusUpdateEvent) MarshalJSON() ([]byte, error) {
+	type Alias TaskStatusUpdateEvent
+	if e.Kind == "" {
+		e.Kind = KindStatusUpdate
+	}
+	return json.Marshal(Alias(e))
 }
 
 // TaskArtifactUpdateEvent represents an event for task artifact updates
@@ -65,4 +169,21 @@ type TaskArtifactUpdateEvent struct {
 	Final *bool `json:"final,omitempty"`
 	// Metadata is optional metadata associated with this update event
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Sequence is the number of the event among those of its task (see
+	// TaskStatusUpdateEvent.Sequence)
+	Sequence int64 `json:"sequence,omitempty"`
+	// Kind is always KindArtifactUpdate on the wire; it is filled in when empty
+	Kind string `json:"kind,omitempty"`
+}
+
+// KindArtifactUpdate is the kind of a TaskArtifactUpdateEvent
+const KindArtifactUpdate = "artifact-update"
+
+// MarshalJSON implements custom JSON marshaling for TaskArtifactUpdateEvent, setting its kind
+func (e TaskArtifactUpdateEvent) MarshalJSON() ([]byte, error) {
+	type Alias TaskArtifactUpdateEvent
+	if e.Kind == "" {
+		e.Kind = KindArtifactUpdate
+	}
+	return json.Marshal(Alias(e))
 }
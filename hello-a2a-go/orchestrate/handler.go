@@ -0,0 +1,69 @@
+package orchestrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// Handler returns a task handler running the pipeline for the task's message, with the tasks
+// of its steps named after the task. The agents' status updates stream as working updates
+// naming the step, and the last step's artifacts as the task's own, so a pipeline served by an
+// agent can be a step of another pipeline. The task completes with the last step's artifacts
+// and message, or fails with the failing step's error.
+func (p *Pipeline) Handler() server.TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		if len(p.Steps) == 0 {
+			task.Status.State = models.TaskStateFailed
+			return task, errors.New("pipeline has no steps")
+		}
+		last := len(p.Steps) - 1
+		results, err := p.run(ctx, task.ID, *message, func(event Event) {
+			switch {
+			case event.Status != nil:
+				server.EmitStatus(ctx, models.TaskStatus{State: models.TaskStateWorking, Message: progressMessage(event)})
+			case event.Artifact != nil && event.Index == last:
+				server.EmitArtifact(ctx, *event.Artifact)
+			}
+		})
+		if err != nil {
+			task.Status = models.TaskStatus{
+				State:   models.TaskStateFailed,
+				Message: &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart(err.Error())}},
+			}
+			return task, err
+		}
+
+		final := results[len(results)-1]
+		task.Artifacts = final.Artifacts
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = final.Message
+		if task.Status.Message == nil {
+			task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart(final.Text())}}
+		}
+		return task, nil
+	}
+}
+
+// progressMessage describes a step's status update as progress of the pipeline, e.g.
+// "translate (1/3): working", with the step in its metadata
+func progressMessage(event Event) *models.Message {
+	text := fmt.Sprintf("%s (%d/%d): %s", event.Step, event.Index+1, event.Steps, event.Status.State)
+	if event.Status.Message != nil {
+		if status := event.Status.Message.Text(); status != "" {
+			text += ": " + status
+		}
+	}
+	return &models.Message{
+		Role:  "agent",
+		Parts: []models.Part{models.NewTextPart(text)},
+		Metadata: map[string]interface{}{
+			"step":      event.Step,
+			"stepIndex": event.Index,
+			"steps":     event.Steps,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package orchestrate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"a2a/models"
+)
+
+// Input is what a step's message is built from
+type Input struct {
+	// Message is the message the pipeline was started with
+	Message models.Message
+	// Results are the results of the steps run so far, in order
+	Results []StepResult
+}
+
+// Last returns the result of the previous step, or a zero result for the first step
+func (in Input) Last() StepResult {
+	if len(in.Results) == 0 {
+		return StepResult{}
+	}
+	return in.Results[len(in.Results)-1]
+}
+
+// Result returns the result of the step named name, or a zero result when it has not run
+func (in Input) Result(name string) StepResult {
+	for _, result := range in.Results {
+		if result.Step == name {
+			return result
+		}
+	}
+	return StepResult{}
+}
+
+// InputMapper builds the message a step sends
+type InputMapper func(in Input) (models.Message, error)
+
+// ForwardText sends the text of the previous step's result, or the pipeline's message as is
+// for the first step
+func ForwardText(in Input) (models.Message, error) {
+	if len(in.Results) == 0 {
+		return in.Message, nil
+	}
+	return models.Message{Role: "user", Parts: []models.Part{models.NewTextPart(in.Last().Text())}}, nil
+}
+
+// ForwardParts sends the parts of the previous step's artifacts, such as files and data, or
+// the pipeline's message as is for the first step
+func ForwardParts(in Input) (models.Message, error) {
+	if len(in.Results) == 0 {
+		return in.Message, nil
+	}
+	var parts []models.Part
+	for _, artifact := range in.Last().Artifacts {
+		parts = append(parts, artifact.Parts...)
+	}
+	if len(parts) == 0 {
+		return models.Message{}, fmt.Errorf("step %s produced no artifacts", in.Last().Step)
+	}
+	return models.Message{Role: "user", Parts: parts}, nil
+}
+
+// TextTemplate sends the text of a text/template executed with the step's Input, e.g.
+// `Summarize: {{(.Result "translate").Text}}` or `{{.Message.Text}}`. It panics when text is
+// not a valid template, like template.Must.
+func TextTemplate(text string) InputMapper {
+	tmpl := template.Must(template.New("input").Option("missingkey=error").Parse(text))
+	return func(in Input) (models.Message, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, in); err != nil {
+			return models.Message{}, err
+		}
+		return models.Message{Role: "user", Parts: []models.Part{models.NewTextPart(b.String())}}, nil
+	}
+}
@@ -0,0 +1,233 @@
+// Package orchestrate chains A2A agents into pipelines. Each step sends a message, built from
+// the pipeline's message and the results of the steps before it, to a skill of an agent and
+// streams its progress. A pipeline is itself served as a skill (see Pipeline.Handler), so
+// pipelines compose.
+package orchestrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"a2a/client"
+	"a2a/models"
+	"a2a/server"
+)
+
+// Step is a call to an agent within a pipeline
+type Step struct {
+	// Name identifies the step's result; it defaults to the skill, or else "step-N" counting
+	// from 1
+	Name string
+	// AgentURL is the JSON-RPC endpoint of the agent, as on its agent card
+	AgentURL string
+	// Skill is the skill the message is addressed to; empty leaves the choice to the agent
+	Skill string
+	// Input builds the message sent; nil uses ForwardText
+	Input InputMapper
+}
+
+// Pipeline is a sequence of steps, each run once the one before it completed
+type Pipeline struct {
+	// ID, Name and Description describe the pipeline as a skill (see Skill)
+	ID          string
+	Name        string
+	Description string
+	Steps       []Step
+	// ClientOptions configure the clients calling the steps' agents, e.g. with credentials
+	ClientOptions []client.Option
+}
+
+// StepResult is the outcome of a step
+type StepResult struct {
+	// Step is the step's name
+	Step string
+	// TaskID is the ID of the task the step ran as on its agent
+	TaskID string
+	// State is the last state the agent reported for the task
+	State models.TaskState
+	// Artifacts are the artifacts the agent streamed, their chunks joined
+	Artifacts []models.Artifact
+	// Message is the message of the last status the agent reported, if any
+	Message *models.Message
+}
+
+// Text joins the text parts of the result's artifacts, or of its message when the artifacts
+// have none
+func (r StepResult) Text() string {
+	var b strings.Builder
+	for _, artifact := range r.Artifacts {
+		for _, part := range artifact.Parts {
+			if text, ok := part.(models.TextPart); ok {
+				b.WriteString(text.Text)
+			}
+		}
+	}
+	if b.Len() == 0 && r.Message != nil {
+		return r.Message.Text()
+	}
+	return b.String()
+}
+
+// Event is a status or artifact update streamed by the agent of a step
+type Event struct {
+	// Step is the name of the step and Index its position among the Steps of the pipeline
+	Step  string
+	Index int
+	Steps int
+	// Status is set for status updates and Artifact for artifact updates
+	Status   *models.TaskStatus
+	Artifact *models.Artifact
+}
+
+// StepError is returned for a step that failed or did not complete
+type StepError struct {
+	Step  string
+	State models.TaskState
+	// Err is the error calling the agent, or nil when the task ended in another state than
+	// completed
+	Err error
+}
+
+func (e *StepError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("step %s failed: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("step %s did not complete: %s", e.Step, e.State)
+}
+
+func (e *StepError) Unwrap() error { return e.Err }
+
+// Skill returns the skill serving the pipeline with Handler
+func (p *Pipeline) Skill() models.AgentSkill {
+	skill := models.AgentSkill{ID: p.ID, Name: p.Name}
+	if p.Description != "" {
+		skill.Description = &p.Description
+	}
+	return skill
+}
+
+// Run runs the steps in order, starting from message, passing the events they stream to
+// onEvent, which may be nil. It returns the results of the steps run, which end with the
+// failing step's on a *StepError.
+func (p *Pipeline) Run(ctx context.Context, message models.Message, onEvent func(Event)) ([]StepResult, error) {
+	runID := make([]byte, 8)
+	rand.Read(runID)
+	return p.run(ctx, hex.EncodeToString(runID), message, onEvent)
+}
+
+// run runs the pipeline with the tasks of its steps named after runID
+func (p *Pipeline) run(ctx context.Context, runID string, message models.Message, onEvent func(Event)) ([]StepResult, error) {
+	if onEvent == nil {
+		onEvent = func(Event) {}
+	}
+	in := Input{Message: message}
+	for i, step := range p.Steps {
+		name := step.name(i)
+		mapper := step.Input
+		if mapper == nil {
+			mapper = ForwardText
+		}
+		stepMessage, err := mapper(in)
+		if err != nil {
+			return in.Results, &StepError{Step: name, Err: fmt.Errorf("failed to build input: %w", err)}
+		}
+		if stepMessage.Role == "" {
+			stepMessage.Role = "user"
+		}
+
+		result := StepResult{Step: name, TaskID: runID + "-" + name}
+		err = p.runStep(ctx, step, &result, stepMessage, func(event Event) {
+			event.Step, event.Index, event.Steps = name, i, len(p.Steps)
+			onEvent(event)
+		})
+		in.Results = append(in.Results, result)
+		if err != nil {
+			return in.Results, &StepError{Step: name, State: result.State, Err: err}
+		}
+		if result.State != models.TaskStateCompleted {
+			return in.Results, &StepError{Step: name, State: result.State}
+		}
+	}
+	return in.Results, nil
+}
+
+// name returns the name of the step at index i
+func (s Step) name(i int) string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.Skill != "":
+		return s.Skill
+	}
+	return fmt.Sprintf("step-%d", i+1)
+}
+
+// streamUpdate is a streamed status or artifact update event
+type streamUpdate struct {
+	Status   *models.TaskStatus `json:"status"`
+	Artifact *models.Artifact   `json:"artifact"`
+}
+
+// runStep sends message to the step's agent with message/stream, collecting what it streams
+// into result
+func (p *Pipeline) runStep(ctx context.Context, step Step, result *StepResult, message models.Message, onEvent func(Event)) error {
+	params := models.MessageSendParams{ID: result.TaskID, Message: message}
+	if step.Skill != "" {
+		params.Metadata = map[string]interface{}{server.SkillMetadataKey: step.Skill}
+	}
+	agent := client.NewClient(step.AgentURL, p.ClientOptions...)
+
+	events := make(chan interface{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- agent.SendMessageStreamingContext(ctx, params, events)
+		close(events)
+	}()
+	for event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		var update streamUpdate
+		if err := models.DecodeJSON(data, &update); err != nil {
+			continue
+		}
+		switch {
+		case update.Status != nil:
+			result.State = update.Status.State
+			if update.Status.Message != nil {
+				result.Message = update.Status.Message
+			}
+			onEvent(Event{Status: update.Status})
+		case update.Artifact != nil:
+			result.add(*update.Artifact)
+			onEvent(Event{Artifact: update.Artifact})
+		}
+	}
+	return <-errc
+}
+
+// add adds a streamed artifact chunk, appending it to the artifact with its index when it is
+// marked as an append
+func (r *StepResult) add(chunk models.Artifact) {
+	chunk.Parts = slices.Clone(chunk.Parts)
+	for i, artifact := range r.Artifacts {
+		if chunk.Index == nil || artifact.Index == nil || *artifact.Index != *chunk.Index {
+			continue
+		}
+		if chunk.Append != nil && *chunk.Append {
+			artifact.Parts = append(artifact.Parts, chunk.Parts...)
+			artifact.LastChunk = chunk.LastChunk
+			r.Artifacts[i] = artifact
+		} else {
+			r.Artifacts[i] = chunk
+		}
+		return
+	}
+	r.Artifacts = append(r.Artifacts, chunk)
+}
@@ -0,0 +1,162 @@
+package orchestrate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+	"a2a/server"
+)
+
+// serveAgent serves the agent with the skills of skills and returns its JSON-RPC endpoint
+func serveAgent(t *testing.T, skills map[string]server.TaskHandler) string {
+	t.Helper()
+	var mux http.Handler
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { mux.ServeHTTP(w, r) }))
+	t.Cleanup(ts.Close)
+
+	builder := server.NewAgent().Named("Test Agent").WithURL(ts.URL + "/a2a")
+	for id, handler := range skills {
+		builder.WithSkill(models.AgentSkill{ID: id, Name: id}, handler)
+	}
+	agent, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Failed to build agent: %v", err)
+	}
+	mux = agent.Mux()
+	return ts.URL + "/a2a"
+}
+
+// upper streams the message text upper-cased, one word per chunk
+func upper(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	index := 0
+	for i, word := range strings.SplitAfter(strings.ToUpper(message.Text()), " ") {
+		appending := i > 0
+		server.EmitArtifact(ctx, models.Artifact{Parts: []models.Part{models.NewTextPart(word)}, Index: &index, Append: &appending})
+	}
+	task.Status.State = models.TaskStateCompleted
+	return task, nil
+}
+
+// exclaim completes with the message text followed by "!" as its status message
+func exclaim(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	task.Status.State = models.TaskStateCompleted
+	task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart(message.Text() + "!")}}
+	return task, nil
+}
+
+func broken(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	task.Status.State = models.TaskStateFailed
+	return task, errors.New("broken")
+}
+
+func TestPipeline_Run(t *testing.T) {
+	url := serveAgent(t, map[string]server.TaskHandler{"upper": upper, "exclaim": exclaim, "broken": broken})
+	pipeline := &Pipeline{Steps: []Step{
+		{AgentURL: url, Skill: "upper"},
+		{Name: "shout", AgentURL: url, Skill: "exclaim", Input: TextTemplate(`{{.Last.Text}} (was {{.Message.Text}})`)},
+	}}
+
+	var events []Event
+	results, err := pipeline.Run(context.Background(), models.Message{Parts: []models.Part{models.NewTextPart("hello world")}}, func(event Event) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("Pipeline failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Step != "upper" || results[0].Text() != "HELLO WORLD" || len(results[0].Artifacts) != 1 {
+		t.Fatalf("Expected the upper step to join its chunks into one artifact, got %+v", results)
+	}
+	if got := results[1]; got.Step != "shout" || got.State != models.TaskStateCompleted || got.Text() != "HELLO WORLD (was hello world)!" {
+		t.Errorf("Unexpected result of the templated step: %+v", got)
+	}
+
+	var chunks, statuses int
+	for _, event := range events {
+		if event.Steps != 2 || (event.Index == 0) != (event.Step == "upper") {
+			t.Errorf("Unexpected event position %+v", event)
+		}
+		if event.Artifact != nil {
+			chunks++
+		}
+		if event.Status != nil {
+			statuses++
+		}
+	}
+	if chunks == 0 || statuses < 2 {
+		t.Errorf("Expected streamed chunks and statuses of both steps, got %d and %d", chunks, statuses)
+	}
+
+	failing := &Pipeline{Steps: []Step{{AgentURL: url, Skill: "upper"}, {AgentURL: url, Skill: "broken"}, {AgentURL: url, Skill: "exclaim"}}}
+	results, err = failing.Run(context.Background(), models.Message{Parts: []models.Part{models.NewTextPart("hi")}}, nil)
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "broken" || stepErr.State != models.TaskStateFailed {
+		t.Fatalf("Expected the broken step to fail the pipeline, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected the steps run up to the failing one, got %d", len(results))
+	}
+}
+
+func TestPipeline_Compose(t *testing.T) {
+	url := serveAgent(t, map[string]server.TaskHandler{"upper": upper, "exclaim": exclaim})
+	inner := &Pipeline{ID: "shout", Name: "Shout", Steps: []Step{
+		{AgentURL: url, Skill: "exclaim"},
+		{AgentURL: url, Skill: "upper"},
+	}}
+	pipelineURL := serveAgent(t, map[string]server.TaskHandler{inner.Skill().ID: inner.Handler()})
+
+	outer := &Pipeline{Steps: []Step{
+		{Name: "inner", AgentURL: pipelineURL, Skill: "shout"},
+		{AgentURL: url, Skill: "exclaim"},
+	}}
+	var progress []string
+	results, err := outer.Run(context.Background(), models.Message{Parts: []models.Part{models.NewTextPart("hi there")}}, func(event Event) {
+		if event.Step == "inner" && event.Status != nil && event.Status.Message != nil {
+			progress = append(progress, event.Status.Message.Text())
+		}
+	})
+	if err != nil {
+		t.Fatalf("Pipeline failed: %v", err)
+	}
+	if got := results[0].Text(); got != "HI THERE!" {
+		t.Errorf("Expected the inner pipeline's last artifact, got %q", got)
+	}
+	if got := results[1].Text(); got != "HI THERE!!" {
+		t.Errorf("Expected the outer pipeline to continue from it, got %q", got)
+	}
+	joined := strings.Join(progress, "\n")
+	if !strings.Contains(joined, "exclaim (1/2)") || !strings.Contains(joined, "upper (2/2)") {
+		t.Errorf("Expected the inner steps' progress, got:\n%s", joined)
+	}
+}
+
+func TestInputMappers(t *testing.T) {
+	in := Input{
+		Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("start")}},
+		Results: []StepResult{{Step: "scan", Artifacts: []models.Artifact{{Parts: []models.Part{models.NewDataPart(map[string]interface{}{"ok": true})}}}}},
+	}
+	message, err := ForwardParts(in)
+	if err != nil || len(message.Parts) != 1 {
+		t.Fatalf("Expected the data part forwarded, got %+v, %v", message, err)
+	}
+	if _, err := ForwardParts(Input{Results: []StepResult{{Step: "empty"}}}); err == nil {
+		t.Error("Expected an error forwarding a step without artifacts")
+	}
+	if message, _ := ForwardText(Input{Message: in.Message}); message.Text() != "start" {
+		t.Errorf("Expected the first step to get the pipeline's message, got %+v", message)
+	}
+	if _, err := TextTemplate(`{{.Missing}}`)(in); err == nil {
+		t.Error("Expected an error for an unknown template field")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected an invalid template to panic")
+		}
+	}()
+	TextTemplate(`{{`)
+}
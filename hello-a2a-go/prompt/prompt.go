@@ -0,0 +1,269 @@
+// Package prompt renders LLM prompts from named templates, so an agent's behavior can be tuned
+// without recompiling it.
+//
+// A Library holds text/template templates by name: defaults compiled into the agent, which
+// template files in a directory override. <dir>/<name>.tmpl replaces the template name for
+// every skill, and <dir>/<skill>/<name>.tmpl for the skill with that ID only. Templates see
+// the variables of the message being handled (see MessageVars) and those the handler adds, and
+// Watch reloads the files as they change.
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// Ext is the extension of template files
+const Ext = ".tmpl"
+
+// TextVar is the variable holding the text of the message
+const TextVar = "text"
+
+// ErrNotFound is returned when rendering a template that does not exist
+var ErrNotFound = errors.New("prompt template not found")
+
+// Vars are the variables templates are executed with, e.g. {{.text}}. Missing variables are
+// false, so optional ones can be tested with {{if .name}} or {{with .name}}.
+type Vars map[string]interface{}
+
+// MessageVars returns the variables of message: the fields of its metadata, overridden by
+// those of its data parts, and its text parts joined under TextVar
+func MessageVars(message *models.Message) Vars {
+	vars := Vars{}
+	for key, value := range message.Metadata {
+		vars[key] = value
+	}
+	for _, part := range message.Parts {
+		dataPart, ok := part.(models.DataPart)
+		if !ok {
+			continue
+		}
+		if data, ok := dataPart.Data.(map[string]interface{}); ok {
+			for key, value := range data {
+				vars[key] = value
+			}
+		}
+	}
+	vars[TextVar] = message.Text()
+	return vars
+}
+
+// Library is a set of named prompt templates, safe for concurrent use
+type Library struct {
+	defaults map[string]string
+	dir      string
+	clock    clock.Clock
+
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+	// version identifies the files the templates were read from, to notice changes
+	version string
+}
+
+// Option configures a Library
+type Option func(*Library)
+
+// WithDir reads templates from the files in dir, overriding the defaults (see Reload)
+func WithDir(dir string) Option {
+	return func(l *Library) {
+		l.dir = dir
+	}
+}
+
+// WithClock sets the clock timing Watch; the default is the real clock
+func WithClock(c clock.Clock) Option {
+	return func(l *Library) {
+		l.clock = c
+	}
+}
+
+// New returns a library of the templates defaults, by name, and of the files in the directory
+// set with WithDir. Names of skill specific templates are <skill>/<name>.
+func New(defaults map[string]string, opts ...Option) (*Library, error) {
+	l := &Library{defaults: defaults, clock: clock.Real}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reload rereads the templates of the library's directory. The current templates are kept
+// when a file cannot be read or parsed.
+func (l *Library) Reload() error {
+	version, err := l.scan()
+	if err != nil {
+		return err
+	}
+	templates := make(map[string]*template.Template, len(l.defaults))
+	var errs []error
+	for name, text := range l.defaults {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("default template %s: %w", name, err))
+			continue
+		}
+		templates[name] = tmpl
+	}
+	if l.dir != "" {
+		err := filepath.WalkDir(l.dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != Ext {
+				return err
+			}
+			name, err := l.nameOf(path)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			tmpl, err := template.New(name).Parse(string(data))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("template %s: %w", path, err))
+				return nil
+			}
+			templates[name] = tmpl
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read prompt templates: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	l.mu.Lock()
+	l.templates = templates
+	l.version = version
+	l.mu.Unlock()
+	return nil
+}
+
+// nameOf returns the name of the template file at path: name for <dir>/<name>.tmpl and
+// skill/name for <dir>/<skill>/<name>.tmpl
+func (l *Library) nameOf(path string) (string, error) {
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.ToSlash(strings.TrimSuffix(rel, Ext))
+	if strings.Count(name, "/") > 1 {
+		return "", fmt.Errorf("template %s: templates are <name>%s or <skill>/<name>%s", path, Ext, Ext)
+	}
+	return name, nil
+}
+
+// scan returns the version of the library's directory: the names, sizes and modification
+// times of its template files
+func (l *Library) scan() (string, error) {
+	if l.dir == "" {
+		return "", nil
+	}
+	var files []string
+	err := filepath.WalkDir(l.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != Ext {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, fmt.Sprintf("%s %d %d", path, info.Size(), info.ModTime().UnixNano()))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt templates: %w", err)
+	}
+	sort.Strings(files)
+	return strings.Join(files, "\n"), nil
+}
+
+// Watch checks the library's directory for changed template files every interval, reloading
+// them until ctx is done. Templates that fail to load are logged, and the previous ones kept
+// until the files are fixed.
+func (l *Library) Watch(ctx context.Context, interval time.Duration) {
+	if l.dir == "" {
+		return
+	}
+	var failed string
+	for {
+		select {
+		case <-l.clock.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		version, err := l.scan()
+		if err != nil {
+			log.Printf("Failed to check prompt templates: %v", err)
+			continue
+		}
+		l.mu.RLock()
+		changed := version != l.version && version != failed
+		l.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := l.Reload(); err != nil {
+			log.Printf("Keeping the previous prompt templates: %v", err)
+			failed = version
+			continue
+		}
+		log.Printf("Reloaded prompt templates from %s", l.dir)
+	}
+}
+
+// Lookup returns the template name for skill: <skill>/<name> if the library has it, else name
+func (l *Library) Lookup(skill, name string) (*template.Template, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if skill != "" {
+		if tmpl, ok := l.templates[skill+"/"+name]; ok {
+			return tmpl, true
+		}
+	}
+	tmpl, ok := l.templates[name]
+	return tmpl, ok
+}
+
+// Render executes the template name for skill (see Lookup) with vars
+func (l *Library) Render(skill, name string, vars Vars) (string, error) {
+	tmpl, ok := l.Lookup(skill, name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// Names returns the names of the library's templates, sorted
+func (l *Library) Names() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
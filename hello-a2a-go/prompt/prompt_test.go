@@ -0,0 +1,146 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+var defaults = map[string]string{
+	"greet": "Greet {{with .name}}{{.}}{{else}}everyone{{end}}: {{.text}}",
+}
+
+func writeTemplate(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMessageVars(t *testing.T) {
+	message := &models.Message{
+		Role: "user",
+		Parts: []models.Part{
+			models.TextPart{Type: "text", Text: "Hello"},
+			models.DataPart{Type: "data", Data: map[string]interface{}{"name": "Ada"}},
+		},
+		Metadata: map[string]interface{}{"name": "Bob", "tone": "formal"},
+	}
+	vars := MessageVars(message)
+	if vars[TextVar] != "Hello" || vars["name"] != "Ada" || vars["tone"] != "formal" {
+		t.Errorf("Expected the text, data parts over metadata, got %v", vars)
+	}
+}
+
+func TestLibrary_Render(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "farewell.tmpl"), "Bye {{.name}}")
+	writeTemplate(t, filepath.Join(dir, "formal", "greet.tmpl"), "Dear {{.name}}, {{.text}}")
+	writeTemplate(t, filepath.Join(dir, "notes.txt"), "{{ignored")
+	library, err := New(defaults, WithDir(dir))
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	tests := []struct {
+		skill, name string
+		vars        Vars
+		want        string
+	}{
+		{"", "greet", Vars{"text": "hi"}, "Greet everyone: hi"},
+		{"casual", "greet", Vars{"text": "hi", "name": "Ada"}, "Greet Ada: hi"},
+		{"formal", "greet", Vars{"text": "hi", "name": "Ada"}, "Dear Ada, hi"},
+		{"formal", "farewell", Vars{"name": "Ada"}, "Bye Ada"},
+	}
+	for _, tt := range tests {
+		got, err := library.Render(tt.skill, tt.name, tt.vars)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%q, %q) = %q, %v, want %q", tt.skill, tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := library.Render("", "missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if names := library.Names(); len(names) != 3 || names[0] != "farewell" || names[1] != "formal/greet" {
+		t.Errorf("Expected the defaults and the files, got %v", names)
+	}
+}
+
+func TestLibrary_InvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "greet.tmpl"), "Hi {{.name")
+	if _, err := New(defaults, WithDir(dir)); err == nil {
+		t.Error("Expected an invalid template to fail loading")
+	}
+	writeTemplate(t, filepath.Join(dir, "greet.tmpl"), "Hi")
+	writeTemplate(t, filepath.Join(dir, "a", "b", "greet.tmpl"), "Hi")
+	if _, err := New(defaults, WithDir(dir)); err == nil {
+		t.Error("Expected a nested template to fail loading")
+	}
+}
+
+func TestLibrary_Watch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "greet.tmpl")
+	writeTemplate(t, path, "Hi {{.text}}")
+	fake := clock.NewFake(time.Unix(0, 0))
+	library, err := New(defaults, WithDir(dir), WithClock(fake))
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		library.Watch(ctx, time.Second)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// tick advances the clock past the next check, returning once it is done
+	tick := func() {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+		fake.BlockUntil(1)
+	}
+	render := func() string {
+		got, _ := library.Render("", "greet", Vars{"text": "there"})
+		return got
+	}
+
+	writeTemplate(t, path, "Hello {{.text}}!")
+	tick()
+	if got := render(); got != "Hello there!" {
+		t.Errorf("Expected the changed template, got %q", got)
+	}
+
+	// A broken template keeps the last good one until it is fixed
+	writeTemplate(t, path, "Hello {{.text")
+	tick()
+	if got := render(); got != "Hello there!" {
+		t.Errorf("Expected the previous template, got %q", got)
+	}
+	writeTemplate(t, path, "Howdy {{.text}}")
+	tick()
+	if got := render(); got != "Howdy there" {
+		t.Errorf("Expected the fixed template, got %q", got)
+	}
+
+	// Removing the file falls back to the default
+	os.Remove(path)
+	tick()
+	if got := render(); got != "Greet everyone: there" {
+		t.Errorf("Expected the default template, got %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+// Package registry lets agents in a multi-agent setup discover each other: agents register
+// their cards with a registry Server and keep them alive with heartbeats, and callers find
+// peers by skill instead of hardcoding their URLs.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// ErrNotRegistered is returned for a heartbeat or deregistration of a registration the
+// registry does not know, e.g. one that lapsed or a registry that restarted
+var ErrNotRegistered = errors.New("agent not registered")
+
+// ErrNoAgent is returned by FindOne when no live agent has the skill
+var ErrNoAgent = errors.New("no agent found")
+
+// Registry is a client of a registry Server
+type Registry struct {
+	baseURL    string
+	httpClient *http.Client
+	clock      clock.Clock
+}
+
+// ClientOption configures optional Registry behavior
+type ClientOption func(*Registry)
+
+// WithClientClock sets the clock timing the heartbeats of KeepAlive; the default is clock.Real
+func WithClientClock(c clock.Clock) ClientOption {
+	return func(r *Registry) {
+		r.clock = c
+	}
+}
+
+// NewRegistry creates a client of the registry at baseURL
+func NewRegistry(baseURL string, opts ...ClientOption) *Registry {
+	r := &Registry{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		clock:      clock.Real,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register registers card, returning the registration to renew with Heartbeat
+func (r *Registry) Register(card models.AgentCard) (*Entry, error) {
+	return r.RegisterContext(context.Background(), card)
+}
+
+// RegisterContext is like Register with a context
+func (r *Registry) RegisterContext(ctx context.Context, card models.AgentCard) (*Entry, error) {
+	body, err := json.Marshal(card)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode agent card: %w", err)
+	}
+	var entry Entry
+	if err := r.do(ctx, "POST", "/agents", body, http.StatusCreated, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Heartbeat renews the registration id, returning ErrNotRegistered if it lapsed
+func (r *Registry) Heartbeat(ctx context.Context, id string) (*Entry, error) {
+	var entry Entry
+	if err := r.do(ctx, "PUT", "/agents/"+url.PathEscape(id)+"/heartbeat", nil, http.StatusOK, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Deregister removes the registration id
+func (r *Registry) Deregister(ctx context.Context, id string) error {
+	return r.do(ctx, "DELETE", "/agents/"+url.PathEscape(id), nil, http.StatusNoContent, nil)
+}
+
+// Find returns the cards of the live agents with a skill tagged or identified by skill, or
+// of every live agent when skill is empty
+func (r *Registry) Find(skill string) ([]models.AgentCard, error) {
+	return r.FindContext(context.Background(), skill)
+}
+
+// FindContext is like Find with a context
+func (r *Registry) FindContext(ctx context.Context, skill string) ([]models.AgentCard, error) {
+	var entries []Entry
+	if err := r.do(ctx, "GET", "/agents?skill="+url.QueryEscape(skill), nil, http.StatusOK, &entries); err != nil {
+		return nil, err
+	}
+	cards := make([]models.AgentCard, len(entries))
+	for i, entry := range entries {
+		cards[i] = entry.Card
+	}
+	return cards, nil
+}
+
+// FindOne returns the card of the first live agent with skill, or ErrNoAgent
+func (r *Registry) FindOne(ctx context.Context, skill string) (*models.AgentCard, error) {
+	cards, err := r.FindContext(ctx, skill)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("%w with skill %q", ErrNoAgent, skill)
+	}
+	return &cards[0], nil
+}
+
+// KeepAlive registers card and renews the registration at a third of the registry's TTL until
+// ctx is done, registering again if the registry lost it, then deregisters. Failures are logged
+// and retried at the next heartbeat, so an agent can start before its registry.
+func (r *Registry) KeepAlive(ctx context.Context, card models.AgentCard) {
+	var entry *Entry
+	interval := DefaultTTL / 3
+	for {
+		var err error
+		if entry == nil {
+			entry, err = r.RegisterContext(ctx, card)
+		} else if entry, err = r.Heartbeat(ctx, entry.ID); errors.Is(err, ErrNotRegistered) {
+			entry, err = r.RegisterContext(ctx, card)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to register %s with %s: %v", card.Name, r.baseURL, err)
+		}
+		if entry != nil && entry.TTL() > 0 {
+			interval = entry.TTL() / 3
+		}
+
+		select {
+		case <-r.clock.After(interval):
+		case <-ctx.Done():
+			if entry != nil {
+				// The registration's context is done, so deregister with a fresh one
+				deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				r.Deregister(deregisterCtx, entry.ID)
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+// do sends a request to the registry and decodes the response into v unless v is nil
+func (r *Registry) do(ctx context.Context, method, path string, body []byte, wantStatus int, v interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := r.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusNotFound && (method == "PUT" || method == "DELETE") {
+		return ErrNotRegistered
+	}
+	if httpResp.StatusCode != wantStatus {
+		return fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+)
+
+func TestRegistry(t *testing.T) {
+	ts := httptest.NewServer(NewServer())
+	defer ts.Close()
+	registry := NewRegistry(ts.URL)
+	ctx := context.Background()
+
+	entry, err := registry.Register(translator)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	card, err := registry.FindOne(ctx, "translation")
+	if err != nil || card.URL != translator.URL {
+		t.Fatalf("Expected to find the translator, got %+v (%v)", card, err)
+	}
+	if _, err := registry.FindOne(ctx, "vision"); !errors.Is(err, ErrNoAgent) {
+		t.Errorf("Expected ErrNoAgent, got %v", err)
+	}
+
+	if _, err := registry.Heartbeat(ctx, entry.ID); err != nil {
+		t.Errorf("Failed to renew: %v", err)
+	}
+	if err := registry.Deregister(ctx, entry.ID); err != nil {
+		t.Errorf("Failed to deregister: %v", err)
+	}
+	if _, err := registry.Heartbeat(ctx, entry.ID); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("Expected ErrNotRegistered, got %v", err)
+	}
+}
+
+func TestRegistry_KeepAlive(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(WithTTL(30*time.Second), WithClock(fake))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	registry := NewRegistry(ts.URL, WithClientClock(fake))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		registry.KeepAlive(ctx, translator)
+		close(done)
+	}()
+
+	// Outlive the TTL several times, losing the registration once in between
+	for i := 0; i < 10; i++ {
+		fake.BlockUntil(1)
+		if i == 5 {
+			entries := server.Find("translation")
+			if len(entries) != 1 {
+				t.Fatalf("Expected the agent registered, got %d entries", len(entries))
+			}
+			server.Deregister(entries[0].ID)
+		}
+		fake.Advance(10 * time.Second)
+	}
+	fake.BlockUntil(1)
+	if len(server.Find("translation")) != 1 {
+		t.Fatal("Expected heartbeats to keep the agent registered")
+	}
+
+	cancel()
+	<-done
+	if len(server.Find("")) != 0 {
+		t.Error("Expected the agent to deregister when stopped")
+	}
+}
@@ -0,0 +1,237 @@
+package registry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// DefaultTTL is how long a registration lasts without a heartbeat
+const DefaultTTL = 30 * time.Second
+
+// Entry is a registered agent
+type Entry struct {
+	// ID names the registration for heartbeats and deregistration
+	ID   string           `json:"id"`
+	Card models.AgentCard `json:"card"`
+	// ExpiresAt is when the registration lapses unless renewed with a heartbeat
+	ExpiresAt time.Time `json:"expiresAt"`
+	// TTLMillis is the registry's TTL, which heartbeats should be well within
+	TTLMillis int64 `json:"ttlMillis"`
+}
+
+// TTL returns the registry's TTL
+func (e Entry) TTL() time.Duration {
+	return time.Duration(e.TTLMillis) * time.Millisecond
+}
+
+// Server is an in-memory registry of agent cards, served over HTTP:
+//
+//	POST   /agents                 register the agent card in the body, answering its Entry
+//	PUT    /agents/{id}/heartbeat  renew a registration
+//	DELETE /agents/{id}            deregister an agent
+//	GET    /agents?skill=tag       list the live agents, optionally those with a skill
+//
+// Registrations lapse after the TTL unless renewed.
+type Server struct {
+	ttl   time.Duration
+	clock clock.Clock
+	mux   *http.ServeMux
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// Option configures optional Server behavior
+type Option func(*Server)
+
+// WithTTL sets how long registrations last without a heartbeat; the default is DefaultTTL
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.ttl = ttl
+	}
+}
+
+// WithClock sets the clock registrations expire by; the default is clock.Real
+func WithClock(c clock.Clock) Option {
+	return func(s *Server) {
+		s.clock = c
+	}
+}
+
+// NewServer creates an empty registry
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		ttl:     DefaultTTL,
+		clock:   clock.Real,
+		entries: make(map[string]*Entry),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST /agents", s.serveRegister)
+	s.mux.HandleFunc("PUT /agents/{id}/heartbeat", s.serveHeartbeat)
+	s.mux.HandleFunc("DELETE /agents/{id}", s.serveDeregister)
+	s.mux.HandleFunc("GET /agents", s.serveFind)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Register adds card to the registry, or renews the registration of an agent with the same
+// URL, e.g. one that restarted
+func (s *Server) Register(card models.AgentCard) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge()
+
+	entry := s.byURL(card.URL)
+	if entry == nil {
+		id := make([]byte, 16)
+		rand.Read(id)
+		entry = &Entry{ID: hex.EncodeToString(id), TTLMillis: s.ttl.Milliseconds()}
+		s.entries[entry.ID] = entry
+	}
+	entry.Card = card
+	entry.ExpiresAt = s.clock.Now().Add(s.ttl)
+	return *entry
+}
+
+// Heartbeat renews a registration, reporting false if it is unknown or has lapsed
+func (s *Server) Heartbeat(id string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return Entry{}, false
+	}
+	entry.ExpiresAt = s.clock.Now().Add(s.ttl)
+	return *entry, true
+}
+
+// Deregister removes a registration, reporting false if it is unknown
+func (s *Server) Deregister(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[id]
+	delete(s.entries, id)
+	return ok
+}
+
+// Find returns the live agents with a skill tagged or identified by skill, or every live agent
+// when skill is empty, ordered by name
+func (s *Server) Find(skill string) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge()
+
+	entries := []Entry{}
+	for _, entry := range s.entries {
+		if skill == "" || hasSkill(entry.Card, skill) {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Card.Name != entries[j].Card.Name {
+			return entries[i].Card.Name < entries[j].Card.Name
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// purge drops lapsed registrations; the caller holds s.mu
+func (s *Server) purge() {
+	now := s.clock.Now()
+	for id, entry := range s.entries {
+		if !now.Before(entry.ExpiresAt) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+// byURL returns the registration of the agent at url; the caller holds s.mu
+func (s *Server) byURL(url string) *Entry {
+	for _, entry := range s.entries {
+		if entry.Card.URL == url {
+			return entry
+		}
+	}
+	return nil
+}
+
+// hasSkill reports whether card has a skill with ID skill or tagged skill, ignoring case
+func hasSkill(card models.AgentCard, skill string) bool {
+	for _, s := range card.Skills {
+		if strings.EqualFold(s.ID, skill) {
+			return true
+		}
+		for _, tag := range s.Tags {
+			if strings.EqualFold(tag, skill) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) serveRegister(w http.ResponseWriter, r *http.Request) {
+	var card models.AgentCard
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&card); err != nil {
+		http.Error(w, "Invalid agent card: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if card.Name == "" || card.URL == "" {
+		http.Error(w, "Agent card needs a name and URL", http.StatusBadRequest)
+		return
+	}
+	if err := card.Validate(); err != nil {
+		http.Error(w, "Invalid agent card: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.Register(card))
+}
+
+func (s *Server) serveHeartbeat(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.Heartbeat(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Registration not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (s *Server) serveDeregister(w http.ResponseWriter, r *http.Request) {
+	if !s.Deregister(r.PathValue("id")) {
+		http.Error(w, "Registration not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveFind(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Find(r.URL.Query().Get("skill")))
+}
+
+// writeJSON writes v as a JSON response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// translator is the card of an agent with a translation skill
+var translator = models.AgentCard{
+	Name: "Translator",
+	URL:  "http://translator.example/a2a",
+	Skills: []models.AgentSkill{
+		{ID: "translate", Name: "Text Translation", Tags: []string{"translation", "nlp"}},
+	},
+}
+
+func TestServer_TTL(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := NewServer(WithTTL(30*time.Second), WithClock(fake))
+
+	entry := s.Register(translator)
+	speller := models.AgentCard{Name: "Speller", URL: "http://speller.example/a2a", Skills: []models.AgentSkill{{ID: "spell", Tags: []string{"nlp"}}}}
+	s.Register(speller)
+
+	tests := []struct {
+		skill string
+		want  string
+	}{
+		{"translation", "Translator"},
+		{"TRANSLATE", "Translator"},
+		{"nlp", "Speller,Translator"},
+		{"", "Speller,Translator"},
+		{"vision", ""},
+	}
+	for _, tt := range tests {
+		if got := names(s.Find(tt.skill)); got != tt.want {
+			t.Errorf("Find(%q) = %q, want %q", tt.skill, got, tt.want)
+		}
+	}
+
+	// Heartbeats keep a registration alive past the TTL; others lapse
+	fake.Advance(20 * time.Second)
+	if _, ok := s.Heartbeat(entry.ID); !ok {
+		t.Fatal("Expected the heartbeat to renew the registration")
+	}
+	fake.Advance(20 * time.Second)
+	if got := names(s.Find("")); got != "Translator" {
+		t.Errorf("Expected only the renewed agent, got %q", got)
+	}
+
+	// Registering the same URL again keeps the registration
+	if again := s.Register(translator); again.ID != entry.ID {
+		t.Errorf("Expected re-registration to keep ID %s, got %s", entry.ID, again.ID)
+	}
+
+	fake.Advance(30 * time.Second)
+	if _, ok := s.Heartbeat(entry.ID); ok {
+		t.Error("Expected a lapsed registration to be unknown")
+	}
+}
+
+func TestServer_HTTP(t *testing.T) {
+	s := NewServer()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	card, _ := json.Marshal(translator)
+	w := do("POST", "/agents", string(card))
+	var entry Entry
+	if err := json.NewDecoder(w.Body).Decode(&entry); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("Expected the registration, got %d (%v)", w.Code, err)
+	}
+	if entry.ID == "" || entry.TTL() != DefaultTTL {
+		t.Errorf("Expected an ID and the default TTL, got %+v", entry)
+	}
+
+	var entries []Entry
+	w = do("GET", "/agents?skill=translation", "")
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil || names(entries) != "Translator" {
+		t.Errorf("Expected the translator, got %+v (%v)", entries, err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"heartbeat", "PUT", "/agents/" + entry.ID + "/heartbeat", "", http.StatusOK},
+		{"no URL", "POST", "/agents", `{"name":"Nowhere"}`, http.StatusBadRequest},
+		{"invalid card", "POST", "/agents", `{"name":"Bad","url":"http://bad.example","protocolVersion":"one"}`, http.StatusBadRequest},
+		{"deregister", "DELETE", "/agents/" + entry.ID, "", http.StatusNoContent},
+		{"heartbeat after deregister", "PUT", "/agents/" + entry.ID + "/heartbeat", "", http.StatusNotFound},
+		{"deregister twice", "DELETE", "/agents/" + entry.ID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path, tt.body); w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.wantStatus, w.Code, w.Body.String())
+		}
+	}
+
+	if w := do("GET", "/agents", ""); strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("Expected an empty list, got %s", w.Body.String())
+	}
+}
+
+// names joins the agent names of entries
+func names(entries []Entry) string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Card.Name)
+	}
+	return strings.Join(names, ",")
+}
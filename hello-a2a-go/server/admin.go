@@ -0,0 +1,293 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"a2a/models"
+)
+
+// AdminConfig configures the admin API (see WithAdmin)
+type AdminConfig struct {
+	// Addr is the TCP address of the admin listener, e.g. "127.0.0.1:9090"; empty serves the
+	// admin API only where AdminHandler is mounted
+	Addr string
+	// Token is the bearer token admin requests must carry; no request is accepted without one
+	Token string
+	// Config is the application's configuration, reported at /admin/config next to the
+	// server's settings; it is encoded as JSON, so leave secrets out
+	Config interface{}
+}
+
+// WithAdmin serves the admin API on a listener of its own at config.Addr, started with the
+// server by Start and Agent.ListenAndServe, and with AdminHandler. Every request needs an
+// "Authorization: Bearer" header carrying config.Token, separate from the tokens of
+// WithBearerAuth and the API keys of clients.
+func WithAdmin(config AdminConfig) Option {
+	return func(s *A2AServer) {
+		s.admin = &config
+	}
+}
+
+// ActiveTask describes a task with a running handler or messages waiting for a worker
+type ActiveTask struct {
+	ID        string           `json:"id"`
+	ContextID string           `json:"contextId,omitempty"`
+	State     models.TaskState `json:"state,omitempty"`
+	// Skill is the skill the running message names
+	Skill string `json:"skill,omitempty"`
+	// Running is set while the task's handler runs, since Started
+	Running bool       `json:"running"`
+	Started *time.Time `json:"started,omitempty"`
+	// Queued is the number of the task's messages waiting for a worker
+	Queued int `json:"queued,omitempty"`
+}
+
+// Settings are the effective settings of a server
+type Settings struct {
+	// Store is the task store backend
+	Store  string `json:"store"`
+	Limits Limits `json:"limits"`
+	// RequestTimeout, TaskTimeout and StreamIdleTimeout bound handler runs and streams, as
+	// durations such as "30s"; they are absent when unlimited
+	RequestTimeout    string `json:"requestTimeout,omitempty"`
+	TaskTimeout       string `json:"taskTimeout,omitempty"`
+	StreamIdleTimeout string `json:"streamIdleTimeout,omitempty"`
+	KeepAlive         string `json:"keepAlive,omitempty"`
+	// Workers and QueueSize size the worker pool; they are absent when handlers run on the
+	// requests' goroutines
+	Workers   int `json:"workers,omitempty"`
+	QueueSize int `json:"queueSize,omitempty"`
+	// RetentionTTLs maps task states to how long tasks are kept in them
+	RetentionTTLs map[models.TaskState]string `json:"retentionTTLs,omitempty"`
+	// RetryAttempts is the number of times a handler runs before its task is dead-lettered
+	RetryAttempts int `json:"retryAttempts,omitempty"`
+	// RateLimit and RateBurst bound each caller's requests per second
+	RateLimit   float64  `json:"rateLimit,omitempty"`
+	RateBurst   int      `json:"rateBurst,omitempty"`
+	Compression []string `json:"compression,omitempty"`
+	// Features lists the optional features enabled, such as "pushNotifications"
+	Features []string `json:"features"`
+}
+
+// ActiveTasks returns the tasks whose handlers are running or whose messages wait for a
+// worker, ordered by ID
+func (s *A2AServer) ActiveTasks(ctx context.Context) []ActiveTask {
+	active := make(map[string]*ActiveTask)
+	s.runningMu.Lock()
+	for taskID, run := range s.running {
+		started := run.started
+		active[taskID] = &ActiveTask{ID: taskID, Skill: run.skill, Running: true, Started: &started}
+	}
+	s.runningMu.Unlock()
+	if s.pool != nil {
+		for taskID, queued := range s.pool.queuedTasks() {
+			if active[taskID] == nil {
+				active[taskID] = &ActiveTask{ID: taskID}
+			}
+			active[taskID].Queued = queued
+		}
+	}
+
+	tasks := make([]ActiveTask, 0, len(active))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, taskID := range sortedKeys(active) {
+		task := active[taskID]
+		if stored, err := s.store.Get(ctx, taskID); err == nil {
+			task.ContextID, task.State = stored.ContextID, stored.Status.State
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks
+}
+
+// PoolStats reports the load of the worker pool, or false when handlers run without one
+func (s *A2AServer) PoolStats() (PoolStats, bool) {
+	if s.pool == nil {
+		return PoolStats{}, false
+	}
+	return s.pool.stats(), true
+}
+
+// Webhooks describes the webhooks registered for push notifications, ordered by task ID
+func (s *A2AServer) Webhooks() []WebhookInfo {
+	if s.push == nil {
+		return []WebhookInfo{}
+	}
+	return s.push.list()
+}
+
+// Settings reports the server's effective settings
+func (s *A2AServer) Settings() Settings {
+	settings := Settings{
+		Store:       s.store.Backend(),
+		Limits:      s.limits,
+		Compression: s.compression,
+		Features:    s.features(),
+	}
+	if s.timeouts.request > 0 {
+		settings.RequestTimeout = s.timeouts.request.String()
+	}
+	if s.timeouts.task > 0 {
+		settings.TaskTimeout = s.timeouts.task.String()
+	}
+	if s.timeouts.idle > 0 {
+		settings.StreamIdleTimeout = s.timeouts.idle.String()
+	}
+	if s.keepAlive > 0 {
+		settings.KeepAlive = s.keepAlive.String()
+	}
+	if s.pool != nil {
+		settings.Workers, settings.QueueSize = s.pool.workers, s.pool.size
+	}
+	if s.retention != nil {
+		settings.RetentionTTLs = make(map[models.TaskState]string, len(s.retention.policy.TTLs))
+		for state, ttl := range s.retention.policy.TTLs {
+			settings.RetentionTTLs[state] = ttl.String()
+		}
+	}
+	if s.retry != nil {
+		settings.RetryAttempts = s.retry.policy.MaxAttempts
+	}
+	if s.rateLimit != nil {
+		settings.RateLimit, settings.RateBurst = s.rateLimit.limit.Rate, s.rateLimit.limit.Burst
+	}
+	return settings
+}
+
+// features lists the optional features the server was configured with
+func (s *A2AServer) features() []string {
+	features := []string{}
+	for _, feature := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"pushNotifications", s.push != nil},
+		{"metrics", s.metrics != nil},
+		{"usage", s.usage != nil},
+		{"quotas", s.quotas != nil},
+		{"rateLimit", s.rateLimit != nil},
+		{"replayProtection", s.replay != nil},
+		{"journal", s.journal != nil},
+		{"chaos", s.chaos != nil},
+		{"bearerAuth", s.bearerAuth != nil},
+		{"apiKeys", s.apiKeys},
+		{"extendedCard", s.extendedCard != nil},
+		{"cardSigning", s.cardSigner != nil},
+		{"tls", s.tls != nil},
+		{"files", s.files != nil},
+		{"artifacts", s.blobs != nil},
+		{"uriFetching", s.fetch != nil},
+		{"retry", s.retry != nil},
+		{"retention", s.retention != nil},
+		{"chatCompletions", s.chatCompletions},
+		{"ui", s.ui},
+		{"audit", s.audit != nil},
+		{"cluster", s.cluster != nil},
+		{"cors", s.cors != nil},
+	} {
+		if feature.enabled {
+			features = append(features, feature.name)
+		}
+	}
+	return features
+}
+
+// AdminHandler serves the admin API configured with WithAdmin, for mounting on a custom mux;
+// it answers 404 when the server has no admin API:
+//
+//	GET  /admin/tasks                    the number of stored tasks per state (see TaskList)
+//	GET  /admin/tasks/active             tasks with running handlers or queued messages
+//	GET  /admin/pool                     worker pool queue depth and utilization
+//	GET  /admin/webhooks                 registered push notification webhooks
+//	GET  /admin/config                   the effective settings and application configuration
+//	GET  /admin/introspect               skills, handlers, store and limits as served (see Introspect)
+//	POST /admin/tasks/{id}/cancel        cancel a task without waiting for its handler (see ForceCancel)
+//	GET  /admin/audit                    audit records by type, taskId, caller, since, until and limit (see WithAudit)
+//	GET  /admin/dead-letters             tasks whose handler failed for good (see DeadLettersHandler)
+//	POST /admin/dead-letters/{id}/retry  run a dead-lettered task again
+func (s *A2AServer) AdminHandler() http.Handler {
+	if s.admin == nil {
+		return http.NotFoundHandler()
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /admin/tasks", s.TaskListHandler())
+	mux.HandleFunc("GET /admin/tasks/active", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.ActiveTasks(r.Context()))
+	})
+	mux.HandleFunc("GET /admin/pool", func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := s.PoolStats()
+		if !ok {
+			http.Error(w, "Worker pool is not enabled", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, stats)
+	})
+	mux.HandleFunc("GET /admin/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.Webhooks())
+	})
+	mux.HandleFunc("GET /admin/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, struct {
+			Server Settings    `json:"server"`
+			Config interface{} `json:"config,omitempty"`
+		}{s.Settings(), s.admin.Config})
+	})
+	mux.Handle("GET /admin/introspect", s.IntrospectionHandler())
+	mux.HandleFunc("GET /admin/audit", s.serveAuditRecords)
+	deadLetters := s.DeadLettersHandler()
+	mux.Handle("GET /admin/dead-letters", deadLetters)
+	mux.Handle("POST /admin/dead-letters/{id}/retry", deadLetters)
+	mux.HandleFunc("POST /admin/tasks/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		task, err := s.ForceCancel(r.Context(), r.PathValue("id"))
+		var a2aErr *models.A2AError
+		switch {
+		case errors.Is(err, ErrTaskNotFound):
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		case errors.As(err, &a2aErr):
+			http.Error(w, a2aErr.Message, http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.log(r.Context(), task.ID).Warn("task force-canceled by an admin")
+		writeJSON(w, task)
+	})
+	verify := StaticToken(s.admin.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if _, err := verify(r.Context(), token); !ok || s.admin.Token == "" || err != nil {
+			s.auditAuth(r, "admin", "admin token required")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		s.auditAuth(r, "admin", "")
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// serve serves handler on addr, and the admin API on its own listener when WithAdmin names an
+// address, returning the error of the first listener to stop
+func (s *A2AServer) serve(addr string, handler http.Handler) error {
+	defer s.Close()
+	if s.admin == nil || s.admin.Addr == "" {
+		return s.listenAndServe(addr, handler)
+	}
+	errs := make(chan error, 2)
+	go func() { errs <- s.listenAndServe(s.admin.Addr, s.AdminHandler()) }()
+	go func() { errs <- s.listenAndServe(addr, handler) }()
+	return <-errs
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"a2a/models"
+)
+
+const testAdminToken = "admin-secret"
+
+// adminRequest calls the admin API of server with the admin token, decoding the JSON response
+// into dst when it is not nil
+func adminRequest(t *testing.T, server *A2AServer, method, path string, dst interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	server.AdminHandler().ServeHTTP(w, req)
+	if dst != nil && w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(dst); err != nil {
+			t.Fatalf("Failed to decode %s: %v", path, err)
+		}
+	}
+	return w
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithAdmin(AdminConfig{Token: testAdminToken}))
+	for _, header := range []string{"", "Bearer wrong", "Basic " + testAdminToken} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for %q, got %d", header, w.Code)
+		}
+	}
+
+	// Without a token no request is accepted
+	open := NewA2AServer(mockAgentCard, mockTaskHandler, WithAdmin(AdminConfig{}))
+	req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	open.AdminHandler().ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without an admin token, got %d", w.Code)
+	}
+
+	if w := adminRequest(t, NewA2AServer(mockAgentCard, mockTaskHandler), http.MethodGet, "/admin/config", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without WithAdmin, got %d", w.Code)
+	}
+}
+
+func TestAdmin_TasksAndPool(t *testing.T) {
+	started, release := make(chan string, 2), make(chan struct{})
+	var peak atomic.Int32
+	server := NewA2AServer(mockAgentCard, gatedHandler(started, release, &peak),
+		WithWorkerPool(1, 4), WithAdmin(AdminConfig{Token: testAdminToken}))
+
+	running := sendAsync(t, server, "running", "a")
+	<-started
+	queued := sendAsync(t, server, "queued", "b")
+	waitForState(t, server, "queued", models.TaskStateSubmitted)
+
+	var active []ActiveTask
+	adminRequest(t, server, http.MethodGet, "/admin/tasks/active", &active)
+	if len(active) != 2 || active[0].ID != "queued" || active[0].Queued != 1 || active[0].State != models.TaskStateSubmitted {
+		t.Fatalf("Expected the queued task first, got %+v", active)
+	}
+	if got := active[1]; got.ID != "running" || !got.Running || got.Started == nil || got.State != models.TaskStateWorking {
+		t.Errorf("Expected the running task, got %+v", got)
+	}
+
+	var list TaskList
+	adminRequest(t, server, http.MethodGet, "/admin/tasks", &list)
+	if list.Total != 2 || list.States[models.TaskStateWorking] != 1 || list.States[models.TaskStateSubmitted] != 1 {
+		t.Errorf("Expected a working and a submitted task, got %+v", list)
+	}
+
+	var stats PoolStats
+	adminRequest(t, server, http.MethodGet, "/admin/pool", &stats)
+	if !reflect.DeepEqual(stats, PoolStats{Workers: 1, Busy: 1, Queued: 1, QueueSize: 4, Utilization: 1}) {
+		t.Errorf("Unexpected pool stats %+v", stats)
+	}
+
+	// The handler ignores its context, yet the task is canceled at once
+	var task models.Task
+	if w := adminRequest(t, server, http.MethodPost, "/admin/tasks/running/cancel", &task); w.Code != http.StatusOK || task.Status.State != models.TaskStateCanceled {
+		t.Fatalf("Expected the task force-canceled, got %d %+v", w.Code, task.Status)
+	}
+	if w := adminRequest(t, server, http.MethodPost, "/admin/tasks/unknown/cancel", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown task, got %d", w.Code)
+	}
+
+	close(release)
+	<-running
+	<-queued
+	waitForState(t, server, "running", models.TaskStateCanceled)
+
+	if w := adminRequest(t, NewA2AServer(mockAgentCard, mockTaskHandler, WithAdmin(AdminConfig{Token: testAdminToken})), http.MethodGet, "/admin/pool", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a worker pool, got %d", w.Code)
+	}
+}
+
+func TestAdmin_WebhooksAndConfig(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler,
+		WithPushNotifications(nil), WithCompression("gzip"),
+		WithAdmin(AdminConfig{Token: testAdminToken, Config: map[string]string{"listen": ":8080"}}))
+	token := "webhook-secret"
+	server.push.set("t1", models.PushNotificationConfig{
+		URL:            "https://example.com/hook",
+		Token:          &token,
+		Authentication: &models.AgentAuthentication{Schemes: []string{"Bearer"}, Credentials: &token},
+	})
+
+	w := adminRequest(t, server, http.MethodGet, "/admin/webhooks", nil)
+	if strings.Contains(w.Body.String(), token) {
+		t.Errorf("Expected the webhook's secrets withheld, got %s", w.Body)
+	}
+	var webhooks []WebhookInfo
+	json.Unmarshal(w.Body.Bytes(), &webhooks)
+	if len(webhooks) != 1 || webhooks[0].TaskID != "t1" || !webhooks[0].Signed || webhooks[0].Schemes[0] != "Bearer" {
+		t.Errorf("Unexpected webhooks %+v", webhooks)
+	}
+
+	var config struct {
+		Server Settings          `json:"server"`
+		Config map[string]string `json:"config"`
+	}
+	adminRequest(t, server, http.MethodGet, "/admin/config", &config)
+	if config.Config["listen"] != ":8080" || config.Server.Store != "memory" || config.Server.KeepAlive != defaultKeepAlive.String() {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if strings.Join(config.Server.Features, ",") != "pushNotifications" || config.Server.Compression[0] != "gzip" {
+		t.Errorf("Expected the enabled features, got %+v", config.Server)
+	}
+}
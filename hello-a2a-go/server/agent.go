@@ -0,0 +1,197 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"a2a/models"
+)
+
+// AgentBuilder assembles an agent card, its skills, storage and middleware into a runnable
+// Agent. Configuration problems are reported together by Build.
+type AgentBuilder struct {
+	card           models.AgentCard
+	skills         []builderSkill
+	defaultHandler TaskHandler
+	opts           []Option
+	middleware     []func(http.Handler) http.Handler
+}
+
+// builderSkill is a skill added with WithSkill
+type builderSkill struct {
+	skill   models.AgentSkill
+	handler TaskHandler
+	opts    []SkillOption
+}
+
+// Agent is an A2AServer together with the HTTP routes serving it. Like A2AServer it handles
+// JSON-RPC requests directly, e.g. for ServeStdio; Mux and ListenAndServe serve every route.
+type Agent struct {
+	*A2AServer
+	mux *http.ServeMux
+}
+
+// NewAgent starts building an agent. The card defaults to version 1.0.0 of an agent speaking
+// JSON-RPC with streaming enabled, at the protocol version this module implements.
+func NewAgent() *AgentBuilder {
+	streaming := true
+	return &AgentBuilder{
+		card: models.AgentCard{
+			Version:            "1.0.0",
+			Capabilities:       models.AgentCapabilities{Streaming: &streaming},
+			ProtocolVersion:    models.ProtocolVersion,
+			PreferredTransport: models.TransportJSONRPC,
+		},
+	}
+}
+
+// Named sets the agent name
+func (b *AgentBuilder) Named(name string) *AgentBuilder {
+	b.card.Name = name
+	return b
+}
+
+// WithDescription sets the agent description
+func (b *AgentBuilder) WithDescription(description string) *AgentBuilder {
+	b.card.Description = &description
+	return b
+}
+
+// WithURL sets the endpoint advertised on the agent card
+func (b *AgentBuilder) WithURL(url string) *AgentBuilder {
+	b.card.URL = url
+	return b
+}
+
+// WithVersion sets the agent version
+func (b *AgentBuilder) WithVersion(version string) *AgentBuilder {
+	b.card.Version = version
+	return b
+}
+
+// WithProvider sets the organization providing the agent
+func (b *AgentBuilder) WithProvider(provider models.AgentProvider) *AgentBuilder {
+	b.card.Provider = &provider
+	return b
+}
+
+// WithCapabilities replaces the capabilities advertised on the agent card
+func (b *AgentBuilder) WithCapabilities(capabilities models.AgentCapabilities) *AgentBuilder {
+	b.card.Capabilities = capabilities
+	return b
+}
+
+// WithInterface advertises a further URL and transport the agent is served at
+func (b *AgentBuilder) WithInterface(url, transport string) *AgentBuilder {
+	b.card.AdditionalInterfaces = append(b.card.AdditionalInterfaces, models.AgentInterface{URL: url, Transport: transport})
+	return b
+}
+
+// WithSecurityScheme declares an authentication scheme on the card under name and requires
+// callers to satisfy it with scopes. Schemes declared this way are alternatives.
+func (b *AgentBuilder) WithSecurityScheme(name string, scheme models.SecurityScheme, scopes ...string) *AgentBuilder {
+	if b.card.SecuritySchemes == nil {
+		b.card.SecuritySchemes = make(map[string]models.SecurityScheme)
+	}
+	b.card.SecuritySchemes[name] = scheme
+	if scopes == nil {
+		scopes = []string{}
+	}
+	b.card.Security = append(b.card.Security, map[string][]string{name: scopes})
+	return b
+}
+
+// WithSkill adds skill to the card and routes requests naming it to handler, or to the default
+// handler when handler is nil. Unless WithDefaultHandler is used, the first skill's handler is
+// the default, serving requests that name no skill.
+func (b *AgentBuilder) WithSkill(skill models.AgentSkill, handler TaskHandler, opts ...SkillOption) *AgentBuilder {
+	b.skills = append(b.skills, builderSkill{skill: skill, handler: handler, opts: opts})
+	return b
+}
+
+// WithDefaultHandler serves requests that name no skill, or a skill without its own handler
+func (b *AgentBuilder) WithDefaultHandler(handler TaskHandler) *AgentBuilder {
+	b.defaultHandler = handler
+	return b
+}
+
+// WithStore persists tasks in store (see WithTaskStore)
+func (b *AgentBuilder) WithStore(store TaskStore) *AgentBuilder {
+	b.opts = append(b.opts, WithTaskStore(store))
+	return b
+}
+
+// WithAuth guards every endpoint except the agent card with middleware, such as
+// RequireSignature. Middleware added first runs first.
+func (b *AgentBuilder) WithAuth(middleware func(http.Handler) http.Handler) *AgentBuilder {
+	b.middleware = append(b.middleware, middleware)
+	return b
+}
+
+// WithOptions applies further server options, such as WithQuota or WithJournal
+func (b *AgentBuilder) WithOptions(opts ...Option) *AgentBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build validates the configuration and returns the agent with its routes registered
+func (b *AgentBuilder) Build() (*Agent, error) {
+	var errs []error
+	if b.card.Name == "" {
+		errs = append(errs, errors.New("agent name is required"))
+	}
+	handler := b.defaultHandler
+	if len(b.skills) == 0 {
+		errs = append(errs, errors.New("at least one skill is required"))
+	} else if handler == nil {
+		handler = b.skills[0].handler
+	}
+	seen := make(map[string]bool)
+	for _, s := range b.skills {
+		switch {
+		case s.skill.ID == "":
+			errs = append(errs, fmt.Errorf("skill %q has no ID", s.skill.Name))
+		case seen[s.skill.ID]:
+			errs = append(errs, fmt.Errorf("duplicate skill %q", s.skill.ID))
+		case s.handler == nil && handler == nil:
+			errs = append(errs, fmt.Errorf("skill %q has no handler and no default handler is set", s.skill.ID))
+		}
+		seen[s.skill.ID] = true
+	}
+	for i, m := range b.middleware {
+		if m == nil {
+			errs = append(errs, fmt.Errorf("auth middleware %d is nil", i))
+		}
+	}
+	if err := b.card.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid agent configuration: %w", errors.Join(errs...))
+	}
+
+	card := b.card
+	card.Skills = nil
+	srv := NewA2AServer(card, handler, b.opts...)
+	for _, s := range b.skills {
+		if err := srv.AddSkill(s.skill, s.handler, s.opts...); err != nil {
+			return nil, fmt.Errorf("failed to add skill %s: %w", s.skill.ID, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux, b.middleware...)
+	return &Agent{A2AServer: srv, mux: mux}, nil
+}
+
+// Mux returns the agent's routes, for mounting further endpoints such as UsageHandler
+func (a *Agent) Mux() *http.ServeMux {
+	return a.mux
+}
+
+// ListenAndServe serves the agent's routes on addr, over HTTPS when configured with WithTLS,
+// and the admin API on its own address when configured with WithAdmin
+func (a *Agent) ListenAndServe(addr string) error {
+	return a.serve(addr, a.mux)
+}
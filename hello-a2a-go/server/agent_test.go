@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestAgentBuilder_Build(t *testing.T) {
+	echo := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "echo"}}}
+		return task, nil
+	}
+	store := NewMemoryTaskStore()
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-API-Key") != "secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	agent, err := NewAgent().
+		Named("Echo Agent").
+		WithDescription("Echoes messages").
+		WithProvider(models.AgentProvider{Organization: "Tests"}).
+		WithSkill(models.AgentSkill{ID: "echo", Name: "Echo"}, echo).
+		WithSkill(models.AgentSkill{ID: "default", Name: "Default"}, nil).
+		WithStore(store).
+		WithAuth(guard).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	card := agent.AgentCard()
+	if card.Name != "Echo Agent" || card.Provider == nil || card.Provider.Organization != "Tests" || len(card.Skills) != 2 {
+		t.Errorf("Unexpected agent card: %+v", card)
+	}
+	if card.ProtocolVersion != models.ProtocolVersion || card.PreferredTransport != models.TransportJSONRPC {
+		t.Errorf("Expected the card to declare protocol %s over JSONRPC, got %q over %q", models.ProtocolVersion, card.ProtocolVersion, card.PreferredTransport)
+	}
+
+	w := httptest.NewRecorder()
+	agent.Mux().ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/agent-card", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected public agent card, got status %d", w.Code)
+	}
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"built","message":{"role":"user","parts":[{"kind":"text","text":"hi"}]}}}`
+	w = httptest.NewRecorder()
+	agent.Mux().ServeHTTP(w, httptest.NewRequest("POST", "/a2a", strings.NewReader(body)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected auth middleware to reject the request, got status %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/a2a", strings.NewReader(body))
+	req.Header.Set("X-API-Key", "secret")
+	w = httptest.NewRecorder()
+	agent.Mux().ServeHTTP(w, req)
+	var response struct {
+		Result models.Task `json:"result"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Result.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the first skill's handler to serve unnamed requests, got %s", response.Result.Status.State)
+	}
+	if _, err := store.Get(context.Background(), "built"); err != nil {
+		t.Errorf("Expected the task in the configured store: %v", err)
+	}
+}
+
+func TestAgentBuilder_Validation(t *testing.T) {
+	handler := mockTaskHandler
+	tests := []struct {
+		name    string
+		builder *AgentBuilder
+		want    []string
+	}{
+		{
+			name:    "missing name and skills",
+			builder: NewAgent(),
+			want:    []string{"agent name is required", "at least one skill is required"},
+		},
+		{
+			name: "invalid skills",
+			builder: NewAgent().Named("Agent").
+				WithSkill(models.AgentSkill{Name: "Nameless"}, handler).
+				WithSkill(models.AgentSkill{ID: "a"}, handler).
+				WithSkill(models.AgentSkill{ID: "a"}, handler),
+			want: []string{`skill "Nameless" has no ID`, `duplicate skill "a"`},
+		},
+		{
+			name:    "no handler to serve a skill",
+			builder: NewAgent().Named("Agent").WithSkill(models.AgentSkill{ID: "b"}, nil),
+			want:    []string{`skill "b" has no handler`},
+		},
+		{
+			name: "invalid card",
+			builder: NewAgent().Named("Agent").WithSkill(models.AgentSkill{ID: "a"}, handler).
+				WithURL("localhost").
+				WithSecurityScheme("token", models.SecurityScheme{Type: models.SecuritySchemeHTTP}),
+			want: []string{`agent URL "localhost" is not an absolute HTTP(S) URL`, `security scheme "token": http scheme has no scheme`},
+		},
+		{
+			name:    "nil auth middleware",
+			builder: NewAgent().Named("Agent").WithSkill(models.AgentSkill{ID: "a"}, handler).WithAuth(nil),
+			want:    []string{"auth middleware 0 is nil"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil {
+				t.Fatal("Expected a validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
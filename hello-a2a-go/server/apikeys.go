@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrAPIKeyNotFound is returned by a KeyStore for unknown API keys
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// apiKeyPrefix starts every issued API key, so that leaked keys are easy to recognize
+const apiKeyPrefix = "a2a_"
+
+// APIKey is an issued API key as stored: its hash, never the key itself, and its scopes
+type APIKey struct {
+	// ID identifies the key; it is the fingerprint CallerFromRequest attributes its usage to
+	ID string `json:"id"`
+	// Name describes the key's holder
+	Name string `json:"name,omitempty"`
+	// Hash is the hex SHA-256 digest of the key
+	Hash string `json:"hash"`
+	// Methods lists the JSON-RPC methods the key may call; empty allows every method
+	Methods []string `json:"methods,omitempty"`
+	// Skills lists the skills the key may call; empty allows every skill
+	Skills    []string   `json:"skills,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Allows reports whether the key may call method, addressing skill when skill is not empty
+func (k APIKey) Allows(method, skill string) bool {
+	if len(k.Methods) > 0 && !slices.Contains(k.Methods, method) {
+		return false
+	}
+	return k.allowsSkill(skill)
+}
+
+// allowsSkill reports whether the key may address messages to skill
+func (k APIKey) allowsSkill(skill string) bool {
+	return skill == "" || len(k.Skills) == 0 || slices.Contains(k.Skills, skill)
+}
+
+// KeyStore persists API keys by the hash of the key. MemoryTaskStore and SQLTaskStore
+// implement it, keeping keys next to tasks. Implementations must be safe for concurrent use.
+type KeyStore interface {
+	// SaveAPIKey creates or replaces a key
+	SaveAPIKey(ctx context.Context, key APIKey) error
+	// APIKeyByHash returns the key whose hash is hash, or ErrAPIKeyNotFound
+	APIKeyByHash(ctx context.Context, hash string) (APIKey, error)
+	// ListAPIKeys returns every key, revoked or not, ordered by ID
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	// RevokeAPIKey marks the key with id revoked at at, or returns ErrAPIKeyNotFound
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) error
+}
+
+// WithAPIKeys requires an X-API-Key header carrying a key issued with IssueAPIKey on every
+// endpoint except the public agent card and metrics, and enforces the key's scopes on each
+// JSON-RPC method and skill called (see RequireAPIKey). Keys are kept in the task store, which
+// must implement KeyStore; every request is refused when it does not.
+func WithAPIKeys() Option {
+	return func(s *A2AServer) {
+		s.apiKeys = true
+	}
+}
+
+// apiKeyContextKey is the context key for the API key a request is authenticated with
+type apiKeyContextKey struct{}
+
+// apiKeyID returns the ID of the API key secret, a fingerprint of it
+func apiKeyID(secret string) string {
+	return fingerprint(secret)
+}
+
+// APIKeyFromContext returns the API key the request was authenticated with by RequireAPIKey
+func APIKeyFromContext(ctx context.Context) (APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(APIKey)
+	return key, ok
+}
+
+// hashAPIKey returns the hex SHA-256 digest a key is stored under
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// keyStore returns the store API keys are kept in, or an error when the task store keeps none
+func (s *A2AServer) keyStore() (KeyStore, error) {
+	if s.keys == nil {
+		return nil, fmt.Errorf("task store %s does not store API keys", s.store.Backend())
+	}
+	return s.keys, nil
+}
+
+// IssueAPIKey creates a key limited to the methods and skills of scope, which must be
+// supported by the server, and returns the key, which only its hash is stored for, with its
+// stored form. Only the ID, hash and creation time of scope are set by the server.
+func (s *A2AServer) IssueAPIKey(ctx context.Context, scope APIKey) (string, APIKey, error) {
+	keys, err := s.keyStore()
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	for _, method := range scope.Methods {
+		if !slices.Contains(supportedMethods, method) {
+			return "", APIKey{}, fmt.Errorf("unknown method %q", method)
+		}
+	}
+	card := s.AgentCard()
+	for _, skill := range scope.Skills {
+		if !slices.ContainsFunc(card.Skills, func(declared models.AgentSkill) bool { return declared.ID == skill }) {
+			return "", APIKey{}, fmt.Errorf("%w: %s", errUnknownSkill, skill)
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", APIKey{}, err
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	key := APIKey{
+		ID:        apiKeyID(secret),
+		Name:      scope.Name,
+		Hash:      hashAPIKey(secret),
+		Methods:   scope.Methods,
+		Skills:    scope.Skills,
+		CreatedAt: s.clock.Now().UTC(),
+	}
+	if err := keys.SaveAPIKey(ctx, key); err != nil {
+		return "", APIKey{}, fmt.Errorf("failed to save API key: %w", err)
+	}
+	return secret, key, nil
+}
+
+// RevokeAPIKey revokes the key with id, refusing its requests from then on
+func (s *A2AServer) RevokeAPIKey(ctx context.Context, id string) error {
+	keys, err := s.keyStore()
+	if err != nil {
+		return err
+	}
+	return keys.RevokeAPIKey(ctx, id, s.clock.Now().UTC())
+}
+
+// APIKeys returns the issued keys, revoked or not, ordered by ID
+func (s *A2AServer) APIKeys(ctx context.Context) ([]APIKey, error) {
+	keys, err := s.keyStore()
+	if err != nil {
+		return nil, err
+	}
+	return keys.ListAPIKeys(ctx)
+}
+
+// RequireAPIKey returns middleware authenticating requests by their X-API-Key header against
+// the keys issued with IssueAPIKey. Each JSON-RPC call of a request, batched or not, must be
+// to a method the key allows, and each message must be addressed to a skill it allows, where
+// a message naming no skill addresses the card's first skill, which serves it by default.
+// Requests other than JSON-RPC calls, such as task and file downloads, need a key allowing
+// every method. Missing, unknown and revoked keys are answered with 401 Unauthorized and
+// calls outside the key's scopes with 403 Forbidden. Skills are checked again once each
+// message is decoded (see allowSkill), covering the requests whose calls cannot be read here,
+// such as chat completions and bodies too large to buffer.
+func (s *A2AServer) RequireAPIKey() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys, err := s.keyStore()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			secret := r.Header.Get("X-API-Key")
+			if secret == "" {
+				s.auditAuth(r, "apiKey", "missing API key")
+				http.Error(w, "missing API key", http.StatusUnauthorized)
+				return
+			}
+			key, err := keys.APIKeyByHash(r.Context(), hashAPIKey(secret))
+			if errors.Is(err, ErrAPIKeyNotFound) || (err == nil && key.RevokedAt != nil) {
+				s.auditAuth(r, "apiKey", "invalid API key")
+				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			calls, ok := peekScopedCalls(r)
+			if !ok && len(key.Methods) > 0 {
+				s.auditAuth(r, "apiKey", "API key may only call JSON-RPC methods")
+				http.Error(w, "API key may only call JSON-RPC methods", http.StatusForbidden)
+				return
+			}
+			for _, call := range calls {
+				skill := call.skill()
+				if skill == "" && call.Params.Message != nil {
+					skill = s.defaultSkillID()
+				}
+				if !key.Allows(call.Method, skill) {
+					message := "API key may not call " + call.Method
+					if skill != "" && key.Allows(call.Method, "") {
+						message += " with skill " + skill
+					}
+					s.auditAuth(r, "apiKey", message)
+					http.Error(w, message, http.StatusForbidden)
+					return
+				}
+			}
+			s.auditAuth(r, "apiKey", "")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+		})
+	}
+}
+
+// defaultSkillID returns the ID of the card's first skill, or "" when it declares none
+func (s *A2AServer) defaultSkillID() string {
+	s.skillsMu.RLock()
+	defer s.skillsMu.RUnlock()
+	if len(s.agentCard.Skills) == 0 {
+		return ""
+	}
+	return s.agentCard.Skills[0].ID
+}
+
+// allowSkill answers with an error and reports false when the API key of r does not allow the
+// skill the message of params is addressed to
+func (s *A2AServer) allowSkill(w http.ResponseWriter, r *http.Request, id interface{}, params models.TaskSendParams) bool {
+	key, ok := APIKeyFromContext(r.Context())
+	if !ok {
+		return true
+	}
+	skill := s.requestSkill(r.Context(), params)
+	if key.allowsSkill(skill) {
+		return true
+	}
+	message := "API key may not call skill " + skill
+	s.auditAuth(r, "apiKey", message)
+	s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, message)
+	return false
+}
+
+// scopedCall is a JSON-RPC call as far as its API key scopes are concerned
+type scopedCall struct {
+	Method string `json:"method"`
+	Params struct {
+		Metadata map[string]interface{} `json:"metadata"`
+		Message  *models.Message        `json:"message"`
+	} `json:"params"`
+}
+
+// skill returns the skill the call's message is addressed to, or ""
+func (c scopedCall) skill() string {
+	if skillID, _ := c.Params.Metadata[SkillMetadataKey].(string); skillID != "" {
+		return skillID
+	}
+	if c.Params.Message == nil {
+		return ""
+	}
+	return skillOf(context.Background(), c.Params.Message)
+}
+
+// peekScopedCalls reads the JSON-RPC calls of r, a single call or a batch, leaving its body
+// intact for the next handler. It reports false for requests that are not JSON-RPC calls.
+func peekScopedCalls(r *http.Request) ([]scopedCall, bool) {
+	if r.Body == nil || r.Method != http.MethodPost {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBytes))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil {
+		return nil, false
+	}
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		var calls []scopedCall
+		if json.Unmarshal(body, &calls) != nil {
+			return nil, false
+		}
+		return calls, !slices.ContainsFunc(calls, func(call scopedCall) bool { return call.Method == "" })
+	}
+	var call scopedCall
+	if json.Unmarshal(body, &call) != nil || call.Method == "" {
+		return nil, false
+	}
+	return []scopedCall{call}, true
+}
+
+// APIKeysHandler serves the management of API keys for mounting on admin routes, which must
+// be protected by other means:
+//
+//	GET    /admin/keys       list the issued keys
+//	POST   /admin/keys       issue a key for the APIKey posted, answering with {"key": ..., "apiKey": ...}
+//	DELETE /admin/keys/{id}  revoke a key
+func (s *A2AServer) APIKeysHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			keys, err := s.APIKeys(r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(keys)
+		case http.MethodPost:
+			var scope APIKey
+			if err := json.NewDecoder(r.Body).Decode(&scope); err != nil {
+				http.Error(w, fmt.Sprintf("invalid key request: %v", err), http.StatusBadRequest)
+				return
+			}
+			secret, key, err := s.IssueAPIKey(r.Context(), scope)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.log(r.Context(), "").Info("issued API key", slog.String("key", key.ID), slog.String("name", key.Name))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(struct {
+				Key    string `json:"key"`
+				APIKey APIKey `json:"apiKey"`
+			}{secret, key})
+		case http.MethodDelete:
+			err := s.RevokeAPIKey(r.Context(), r.PathValue("id"))
+			if errors.Is(err, ErrAPIKeyNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			s.log(r.Context(), "").Info("revoked API key", slog.String("key", r.PathValue("id")))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
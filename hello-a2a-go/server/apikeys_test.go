@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// testKeyStore checks the KeyStore contract against store, which must hold no keys
+func testKeyStore(t *testing.T, store KeyStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.APIKeyByHash(ctx, "missing"); err != ErrAPIKeyNotFound {
+		t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+	}
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, key := range []APIKey{
+		{ID: "b", Name: "translator", Hash: "hash-b", Skills: []string{"translate"}, CreatedAt: created},
+		{ID: "a", Hash: "hash-a", Methods: []string{"tasks/get"}, CreatedAt: created},
+	} {
+		if err := store.SaveAPIKey(ctx, key); err != nil {
+			t.Fatalf("Failed to save key: %v", err)
+		}
+	}
+
+	key, err := store.APIKeyByHash(ctx, "hash-b")
+	if err != nil || key.ID != "b" || key.Name != "translator" || len(key.Skills) != 1 || !key.CreatedAt.Equal(created) {
+		t.Errorf("Expected key b, got %+v (%v)", key, err)
+	}
+	if err := store.RevokeAPIKey(ctx, "b", created.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to revoke key: %v", err)
+	}
+	if err := store.RevokeAPIKey(ctx, "missing", created); err != ErrAPIKeyNotFound {
+		t.Errorf("Expected ErrAPIKeyNotFound revoking an unknown key, got %v", err)
+	}
+
+	keys, err := store.ListAPIKeys(ctx)
+	if err != nil || len(keys) != 2 || keys[0].ID != "a" || keys[1].ID != "b" {
+		t.Fatalf("Expected keys a and b, got %+v (%v)", keys, err)
+	}
+	if keys[0].RevokedAt != nil || keys[1].RevokedAt == nil || !keys[1].RevokedAt.Equal(created.Add(time.Hour)) {
+		t.Errorf("Expected only key b revoked, got %+v", keys)
+	}
+}
+
+func TestMemoryTaskStore_APIKeys(t *testing.T) {
+	testKeyStore(t, NewMemoryTaskStore())
+}
+
+func TestAPIKeyAllows(t *testing.T) {
+	key := APIKey{Methods: []string{"message/send", "tasks/get"}, Skills: []string{"translate"}}
+	tests := []struct {
+		method, skill string
+		want          bool
+	}{
+		{"message/send", "translate", true},
+		{"message/send", "summarize", false},
+		{"tasks/get", "", true},
+		{"tasks/cancel", "", false},
+	}
+	for _, tt := range tests {
+		if got := key.Allows(tt.method, tt.skill); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.method, tt.skill, got, tt.want)
+		}
+	}
+	if !(APIKey{}).Allows("tasks/cancel", "summarize") {
+		t.Error("Expected a key without scopes to allow everything")
+	}
+}
+
+// keyedServer returns a server requiring API keys, serving the skills translate, its
+// default, and summarize
+func keyedServer(opts ...Option) *A2AServer {
+	card := mockAgentCard
+	card.Skills = []models.AgentSkill{{ID: "translate", Name: "Translate"}, {ID: "summarize", Name: "Summarize"}}
+	return NewA2AServer(card, mockTaskHandler, append([]Option{WithAPIKeys()}, opts...)...)
+}
+
+// postWithKey posts body to server with key as its API key, returning the response
+func postWithKey(server http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+// sendBody is a message/send request addressed to skill, or to none when skill is empty
+func sendBody(id, skill string) string {
+	metadata := ""
+	if skill != "" {
+		metadata = `,"metadata":{"skillId":"` + skill + `"}`
+	}
+	return `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"` + id +
+		`","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}` + metadata + `}}`
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	server := keyedServer()
+	ctx := context.Background()
+	full, _, err := server.IssueAPIKey(ctx, APIKey{Name: "admin"})
+	if err != nil {
+		t.Fatalf("Failed to issue key: %v", err)
+	}
+	translator, stored, err := server.IssueAPIKey(ctx, APIKey{Name: "translator", Methods: []string{"message/send", "tasks/get"}, Skills: []string{"translate"}})
+	if err != nil {
+		t.Fatalf("Failed to issue key: %v", err)
+	}
+	if !strings.HasPrefix(translator, apiKeyPrefix) || stored.Hash != hashAPIKey(translator) || strings.Contains(stored.Hash, translator) {
+		t.Errorf("Expected only the key's hash stored, got %+v", stored)
+	}
+	if stored.ID != fingerprint(translator) || CallerForAPIKey(translator) != "key:"+stored.ID {
+		t.Errorf("Expected the key's ID to match its caller identity, got %s", stored.ID)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		body string
+		want int
+	}{
+		{"missing key", "", sendBody("t1", ""), http.StatusUnauthorized},
+		{"unknown key", "a2a_unknown", sendBody("t1", ""), http.StatusUnauthorized},
+		{"full key", full, sendBody("t1", "summarize"), http.StatusOK},
+		{"allowed skill", translator, sendBody("t2", "translate"), http.StatusOK},
+		{"default skill", translator, sendBody("t3", ""), http.StatusOK},
+		{"other skill", translator, sendBody("t4", "summarize"), http.StatusForbidden},
+		{"allowed method", translator, `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"t2"}}`, http.StatusOK},
+		{"other method", translator, `{"jsonrpc":"2.0","id":1,"method":"tasks/cancel","params":{"id":"t2"}}`, http.StatusForbidden},
+		{"batch with other skill", translator, "[" + sendBody("t5", "translate") + "," + sendBody("t6", "summarize") + "]", http.StatusForbidden},
+		{"batch", translator, "[" + sendBody("t7", "translate") + "," + sendBody("t8", "") + "]", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postWithKey(server, tt.key, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body)
+			}
+		})
+	}
+
+	// A key limited to methods may not use the other endpoints
+	req := httptest.NewRequest("GET", "/v1/tasks/t2", nil)
+	req.SetPathValue("id", "t2")
+	req.Header.Set("X-API-Key", translator)
+	w := httptest.NewRecorder()
+	server.taskHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected a method-limited key refused a task download, got %d", w.Code)
+	}
+
+	if err := server.RevokeAPIKey(ctx, stored.ID); err != nil {
+		t.Fatalf("Failed to revoke key: %v", err)
+	}
+	if w := postWithKey(server, translator, sendBody("t9", "translate")); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a revoked key refused, got %d", w.Code)
+	}
+}
+
+// Skill scopes hold for requests whose calls the middleware cannot read
+func TestRequireAPIKey_SkillAfterDecoding(t *testing.T) {
+	server := keyedServer(WithChatCompletions())
+	translator, _, err := server.IssueAPIKey(context.Background(), APIKey{Name: "translator", Skills: []string{"translate"}})
+	if err != nil {
+		t.Fatalf("Failed to issue key: %v", err)
+	}
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	padded := strings.Replace(sendBody("t1", "summarize"), `"jsonrpc"`, `"padding":"`+strings.Repeat(" ", maxPeekBytes)+`","jsonrpc"`, 1)
+	w := postWithKey(server, translator, padded)
+	var resp models.JSONRPCResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+		t.Errorf("Expected a body too large to peek refused, got %d: %.200s", w.Code, w.Body)
+	}
+	if _, err := server.store.Get(context.Background(), "t1"); err == nil {
+		t.Error("Expected no task created for the refused skill")
+	}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"summarize","messages":[{"role":"user","content":"Hello"}]}`))
+	req.Header.Set("X-API-Key", translator)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code == http.StatusOK {
+		t.Errorf("Expected a chat completion with another skill refused, got %d: %s", w.Code, w.Body)
+	}
+	req = httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"translate","messages":[{"role":"user","content":"Hello"}]}`))
+	req.Header.Set("X-API-Key", translator)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected a chat completion with the allowed skill, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestRequireAPIKey_Context(t *testing.T) {
+	var got APIKey
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		got, _ = APIKeyFromContext(ctx)
+		return mockTaskHandler(ctx, task, message)
+	}
+	card := mockAgentCard
+	server := NewA2AServer(card, handler, WithAPIKeys())
+	key, stored, err := server.IssueAPIKey(context.Background(), APIKey{Name: "ci"})
+	if err != nil {
+		t.Fatalf("Failed to issue key: %v", err)
+	}
+	postWithKey(server, key, sendBody("t1", ""))
+	if got.ID != stored.ID || got.Name != "ci" {
+		t.Errorf("Expected the handler to see key %s, got %+v", stored.ID, got)
+	}
+}
+
+func TestIssueAPIKey_Validation(t *testing.T) {
+	server := keyedServer()
+	if _, _, err := server.IssueAPIKey(context.Background(), APIKey{Skills: []string{"unknown"}}); err == nil {
+		t.Error("Expected an unknown skill refused")
+	}
+	if _, _, err := server.IssueAPIKey(context.Background(), APIKey{Methods: []string{"tasks/delete"}}); err == nil {
+		t.Error("Expected an unknown method refused")
+	}
+}
+
+// storeWithoutKeys is a TaskStore that keeps no API keys
+type storeWithoutKeys struct {
+	TaskStore
+}
+
+func TestRequireAPIKey_StoreWithoutKeys(t *testing.T) {
+	server := keyedServer(WithTaskStore(storeWithoutKeys{NewMemoryTaskStore()}))
+	if _, _, err := server.IssueAPIKey(context.Background(), APIKey{}); err == nil {
+		t.Error("Expected issuing a key to fail without a key store")
+	}
+	if w := postWithKey(server, "a2a_key", sendBody("t1", "")); w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected requests refused without a key store, got %d", w.Code)
+	}
+}
+
+func TestAPIKeysHandler(t *testing.T) {
+	server := keyedServer()
+	mux := http.NewServeMux()
+	mux.Handle("GET /admin/keys", server.APIKeysHandler())
+	mux.Handle("POST /admin/keys", server.APIKeysHandler())
+	mux.Handle("DELETE /admin/keys/{id}", server.APIKeysHandler())
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/keys", strings.NewReader(`{"name":"bot","skills":["translate"]}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected the key issued, got %d: %s", w.Code, w.Body)
+	}
+	var issued struct {
+		Key    string `json:"key"`
+		APIKey APIKey `json:"apiKey"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&issued); err != nil || issued.Key == "" || issued.APIKey.Name != "bot" {
+		t.Fatalf("Expected the issued key, got %+v (%v)", issued, err)
+	}
+	if w := postWithKey(server, issued.Key, sendBody("t1", "translate")); w.Code != http.StatusOK {
+		t.Errorf("Expected the issued key accepted, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/keys", strings.NewReader(`{"skills":["unknown"]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown skill refused, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/keys/"+issued.APIKey.ID, nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected the key revoked, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/keys/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown key not found, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/admin/keys", nil))
+	var keys []APIKey
+	if err := json.NewDecoder(w.Body).Decode(&keys); err != nil || len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("Expected the revoked key listed, got %+v (%v)", keys, err)
+	}
+	if strings.Contains(w.Body.String(), issued.Key) {
+		t.Error("Expected the key itself never listed")
+	}
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"a2a/models"
+)
+
+var (
+	// ErrBlobNotFound is returned by a BlobStore for unknown blobs
+	ErrBlobNotFound = errors.New("blob not found")
+	// ErrDigestMismatch is returned by a BlobStore when content does not hash to the ID it was
+	// stored under
+	ErrDigestMismatch = errors.New("content does not match digest")
+)
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	// ID is the hex encoded SHA-256 digest of the blob's content
+	ID       string    `json:"id"`
+	MimeType string    `json:"mimeType"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+}
+
+// BlobStore keeps artifact content addressed by its SHA-256 digest, so identical content is
+// stored once and a blob never changes. Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Put stores the contents of r, described by info, under their digest and returns info with
+	// the blob's ID and size. If info.ID is set, content hashing to another digest is rejected
+	// with ErrDigestMismatch. Content that is already stored keeps its original info.
+	Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error)
+	// Open returns the contents of a blob, or ErrBlobNotFound
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, BlobInfo, error)
+}
+
+// WithArtifactStore serves the blobs of store at /artifacts/{id} (see RegisterRoutes). Clients
+// upload files there to reference them by URI, and file artifacts with more than inlineLimit
+// bytes of inline content are moved there and replaced by their URI, instead of to the store
+// of WithFileStore; a zero inlineLimit keeps artifacts inline.
+func WithArtifactStore(store BlobStore, inlineLimit int) Option {
+	return func(s *A2AServer) {
+		s.blobs = store
+		s.blobInlineLimit = inlineLimit
+	}
+}
+
+// DirBlobStore is a BlobStore keeping blobs in a directory, in subdirectories named by the
+// first two characters of their IDs
+type DirBlobStore struct {
+	dir string
+}
+
+// NewDirBlobStore creates a blob store in dir, creating the directory if needed
+func NewDirBlobStore(dir string) (*DirBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create blob store: %w", err)
+	}
+	return &DirBlobStore{dir: dir}, nil
+}
+
+// path returns the path of the blob id
+func (d *DirBlobStore) path(id string) string {
+	return filepath.Join(d.dir, id[:2], id)
+}
+
+// Put implements BlobStore. Content is written to a temporary file while it is hashed and
+// renamed into place, so a blob is never seen partially written.
+func (d *DirBlobStore) Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error) {
+	if info.ID != "" && !validDigest(info.ID) {
+		return BlobInfo{}, ErrDigestMismatch
+	}
+	tmp, err := os.CreateTemp(d.dir, "upload-*")
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	info.Size, err = io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if info.ID != "" && info.ID != digest {
+		return BlobInfo{}, ErrDigestMismatch
+	}
+	info.ID = digest
+
+	if _, existing, err := d.Open(ctx, digest); err == nil {
+		return existing, nil
+	}
+	path := d.path(digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	// The info is written first, so a blob that can be opened always has it
+	meta, _ := json.Marshal(info)
+	if err := os.WriteFile(path+".json", meta, 0o600); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to write blob info: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+	return info, nil
+}
+
+// Open implements BlobStore
+func (d *DirBlobStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, BlobInfo, error) {
+	// IDs are digests, which also keeps them from escaping the directory
+	if !validDigest(id) {
+		return nil, BlobInfo{}, ErrBlobNotFound
+	}
+	path := d.path(id)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, BlobInfo{}, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, BlobInfo{}, fmt.Errorf("failed to open blob: %w", err)
+	}
+	meta, err := os.ReadFile(path + ".json")
+	if err != nil {
+		file.Close()
+		return nil, BlobInfo{}, fmt.Errorf("failed to read blob info: %w", err)
+	}
+	var info BlobInfo
+	if err := json.Unmarshal(meta, &info); err != nil {
+		file.Close()
+		return nil, BlobInfo{}, fmt.Errorf("failed to decode blob info: %w", err)
+	}
+	return file, info, nil
+}
+
+// validDigest reports whether id is a hex encoded SHA-256 digest in lower case
+func validDigest(id string) bool {
+	if len(id) != 2*sha256.Size {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if !('0' <= id[i] && id[i] <= '9' || 'a' <= id[i] && id[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// offloadArtifact moves the inline content of a file artifact part to the blob store,
+// returning the part with the blob's URL
+func (s *A2AServer) offloadArtifact(ctx context.Context, filePart models.FilePart, content []byte) (models.FilePart, error) {
+	info, err := s.blobs.Put(ctx, BlobInfo{MimeType: filePart.MimeType, Created: s.clock.Now().UTC()}, bytes.NewReader(content))
+	if err != nil {
+		return filePart, fmt.Errorf("failed to offload artifact file: %w", err)
+	}
+	filePart.Content = models.FileContentURI{Type: "uri", URI: s.artifactURL(info.ID, filePart.FileName)}
+	return filePart, nil
+}
+
+// artifactURL returns the URL the blob id is downloaded from, on the host of the agent card's
+// URL, naming the download name when set
+func (s *A2AServer) artifactURL(id, name string) string {
+	path := "/artifacts/" + id
+	if name != "" {
+		path += "?" + url.Values{"name": {name}}.Encode()
+	}
+	return s.serverURL(path)
+}
+
+// serveArtifacts uploads the blob named by the id path parameter on PUT and downloads it on GET
+func (s *A2AServer) serveArtifacts(w http.ResponseWriter, r *http.Request) {
+	if s.blobs == nil {
+		http.Error(w, "Artifact storage is not enabled", http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodPut {
+		s.uploadArtifact(w, r)
+		return
+	}
+
+	blob, info, err := s.blobs.Open(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrBlobNotFound) {
+		http.Error(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	// Blobs never change, so their digest is a strong ETag and they may be cached for good
+	setDownloadHeaders(w, info.MimeType, r.URL.Query().Get("name"))
+	w.Header().Set("ETag", `"`+info.ID+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(w, r, "", info.Created, blob)
+}
+
+// uploadArtifact streams the request body into the blob store under the digest named by the id
+// path parameter and answers with a FilePart referencing the blob by URI: 201 Created for new
+// content and 200 OK for content already stored. The part is named by the name query parameter
+// and typed by the Content-Type header.
+func (s *A2AServer) uploadArtifact(w http.ResponseWriter, r *http.Request) {
+	body := io.Reader(r.Body)
+	if s.limits.MaxFileBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.limits.MaxFileBytes)
+	}
+	mimeType := r.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	id := r.PathValue("id")
+	status := http.StatusCreated
+	if existing, _, err := s.blobs.Open(r.Context(), id); err == nil {
+		existing.Close()
+		status = http.StatusOK
+	}
+	info, err := s.blobs.Put(r.Context(), BlobInfo{ID: id, MimeType: mimeType, Created: s.clock.Now().UTC()}, body)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, fmt.Sprintf("Artifact exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, ErrDigestMismatch):
+		http.Error(w, "Content does not match the SHA-256 digest "+id, http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.FilePart{
+		Type:     "file",
+		FileName: r.URL.Query().Get("name"),
+		MimeType: info.MimeType,
+		Content:  models.FileContentURI{Type: "uri", URI: s.artifactURL(info.ID, r.URL.Query().Get("name"))},
+	})
+}
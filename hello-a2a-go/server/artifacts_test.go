@@ -0,0 +1,136 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// digest returns the blob ID of content
+func digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArtifactStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := NewDirFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := mockAgentCard
+	card.URL = "http://agent.example/a2a"
+	server := NewA2AServer(card, reportHandler, WithFileStore(files, 4), WithArtifactStore(store, 16), WithMaxFileBytes(1024))
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	do := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	path := func(uri string) string { return strings.TrimPrefix(uri, "http://agent.example") }
+
+	// Large inline artifacts are moved to the artifact store rather than the file store
+	report := strings.Repeat("a,b\n", 16)
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "report",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Report"}}},
+	})
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	uri, ok := task.Artifacts[0].Parts[0].(models.FilePart).Content.(models.FileContentURI)
+	if want := "http://agent.example/artifacts/" + digest(report) + "?name=report.csv"; !ok || uri.URI != want {
+		t.Fatalf("Expected the large artifact at %s, got %+v", want, task.Artifacts[0].Parts[0])
+	}
+	if _, ok := task.Artifacts[0].Parts[1].(models.FilePart).Content.(models.FileContentBytes); !ok {
+		t.Errorf("Expected the small artifact to stay inline, got %+v", task.Artifacts[0].Parts[1])
+	}
+
+	w := do(httptest.NewRequest("GET", path(uri.URI), nil))
+	if w.Code != http.StatusOK || w.Body.String() != report || w.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("Expected the artifact contents, got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+	if w.Header().Get("Content-Disposition") != `attachment; filename=report.csv` || w.Header().Get("ETag") != `"`+digest(report)+`"` {
+		t.Errorf("Expected the name and digest ETag, got %v", w.Header())
+	}
+	r := httptest.NewRequest("GET", path(uri.URI), nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	if w := do(r); w.Code != http.StatusNotModified {
+		t.Errorf("Expected a matching ETag to answer 304, got %d", w.Code)
+	}
+
+	// Uploads are stored under their digest, once
+	id := digest("notes")
+	for _, want := range []int{http.StatusCreated, http.StatusOK} {
+		r = httptest.NewRequest("PUT", "/artifacts/"+id+"?name=notes.txt", strings.NewReader("notes"))
+		r.Header.Set("Content-Type", "text/plain")
+		w = do(r)
+		var part models.FilePart
+		if err := json.NewDecoder(w.Body).Decode(&part); err != nil || w.Code != want {
+			t.Fatalf("Expected status %d with a file part, got %d (%v)", want, w.Code, err)
+		}
+		if part.FileName != "notes.txt" || part.MimeType != "text/plain" || part.Content.(models.FileContentURI).URI != "http://agent.example/artifacts/"+id+"?name=notes.txt" {
+			t.Errorf("Expected the uploaded artifact, got %+v", part)
+		}
+	}
+	// SVG could run scripts in the server's origin, so it is downloaded as bytes
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"/>`
+	r = httptest.NewRequest("PUT", "/artifacts/"+digest(svg), strings.NewReader(svg))
+	r.Header.Set("Content-Type", "image/svg+xml")
+	if w := do(r); w.Code != http.StatusCreated {
+		t.Fatalf("Expected the SVG stored, got %d", w.Code)
+	}
+	w = do(httptest.NewRequest("GET", "/artifacts/"+digest(svg), nil))
+	if w.Header().Get("Content-Type") != "application/octet-stream" || w.Header().Get("Content-Disposition") != "attachment" ||
+		w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected an SVG served as an attachment of bytes, got %v", w.Header())
+	}
+	if blobs, _ := filepath.Glob(filepath.Join(dir, id[:2], id+"*")); len(blobs) != 2 {
+		t.Errorf("Expected one blob with its info, got %v", blobs)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "upload-*")); len(leftovers) != 0 {
+		t.Errorf("Expected no temporary files, got %v", leftovers)
+	}
+
+	tests := []struct {
+		name       string
+		r          *http.Request
+		wantStatus int
+	}{
+		{"digest mismatch", httptest.NewRequest("PUT", "/artifacts/"+id, strings.NewReader("other")), http.StatusBadRequest},
+		{"invalid digest", httptest.NewRequest("PUT", "/artifacts/notes", strings.NewReader("notes")), http.StatusBadRequest},
+		{"too large", httptest.NewRequest("PUT", "/artifacts/"+digest(strings.Repeat("x", 2048)), strings.NewReader(strings.Repeat("x", 2048))), http.StatusRequestEntityTooLarge},
+		{"unknown artifact", httptest.NewRequest("GET", "/artifacts/"+digest("missing"), nil), http.StatusNotFound},
+		{"path traversal", httptest.NewRequest("GET", "/artifacts/..%2F..%2Fsecret", nil), http.StatusNotFound},
+		{"wrong method", httptest.NewRequest("DELETE", "/artifacts/"+id, nil), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if w := do(tt.r); w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, digest("other")[:2])); !os.IsNotExist(err) {
+		t.Errorf("Expected rejected content not to be stored, got %v", err)
+	}
+
+	// Without an artifact store the endpoints are not found
+	plain := http.NewServeMux()
+	NewA2AServer(mockAgentCard, reportHandler).RegisterRoutes(plain)
+	w = httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest("PUT", "/artifacts/"+id, strings.NewReader("notes")))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an artifact store, got %d", w.Code)
+	}
+}
@@ -0,0 +1,413 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// AuditType is the type of an audit record
+type AuditType string
+
+// Audit record types
+const (
+	// AuditMessageReceived records a message received for a task
+	AuditMessageReceived AuditType = "message.received"
+	// AuditTaskState records a task entering a state, including its first
+	AuditTaskState AuditType = "task.state"
+	// AuditArtifact records an artifact or artifact chunk a task produced
+	AuditArtifact AuditType = "task.artifact"
+	// AuditAuthDecision records a request accepted or refused by authentication
+	AuditAuthDecision AuditType = "auth.decision"
+)
+
+// Authentication decisions of audit records
+const (
+	AuditAllow = "allow"
+	AuditDeny  = "deny"
+)
+
+// AuditRecord is one entry of the audit log
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	Type AuditType `json:"type"`
+	// Caller identifies the principal of the request (see WithCallerFunc), when known
+	Caller    string `json:"caller,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+	ContextID string `json:"contextId,omitempty"`
+	// Skill is the skill a received message is addressed to
+	Skill string `json:"skill,omitempty"`
+	// State and PreviousState are the states a task entered and left, for task.state records
+	State         models.TaskState `json:"state,omitempty"`
+	PreviousState models.TaskState `json:"previousState,omitempty"`
+	// Message is the message received, and Artifact the artifact produced, after redaction
+	Message  *models.Message  `json:"message,omitempty"`
+	Artifact *models.Artifact `json:"artifact,omitempty"`
+	// Scheme, Decision and Reason describe an authentication decision: the scheme checked,
+	// such as "bearer" or "apiKey", AuditAllow or AuditDeny, and why a request was refused
+	Scheme   string `json:"scheme,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Path is the request path of an authentication decision
+	Path string `json:"path,omitempty"`
+}
+
+// AuditQuery selects audit records; zero fields select every record
+type AuditQuery struct {
+	Type   AuditType
+	TaskID string
+	Caller string
+	// Since and Until bound the records' time, inclusive and exclusive
+	Since time.Time
+	Until time.Time
+	// Limit is the most records returned, oldest first
+	Limit int
+}
+
+// matches reports whether q selects record
+func (q AuditQuery) matches(record AuditRecord) bool {
+	return (q.Type == "" || record.Type == q.Type) &&
+		(q.TaskID == "" || record.TaskID == q.TaskID) &&
+		(q.Caller == "" || record.Caller == q.Caller) &&
+		(q.Since.IsZero() || !record.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || record.Time.Before(q.Until))
+}
+
+// AuditLog is an append-only record of an agent's interactions. FileAuditLog and SQLTaskStore
+// implement it. Implementations must be safe for concurrent use.
+type AuditLog interface {
+	// AppendAudit adds record to the log
+	AppendAudit(ctx context.Context, record AuditRecord) error
+	// QueryAudit returns the records selected by query in the order they were appended
+	QueryAudit(ctx context.Context, query AuditQuery) ([]AuditRecord, error)
+}
+
+// AuditRedaction removes sensitive content from the messages and artifacts of audit records
+// before they are written. The zero value records them as sent.
+type AuditRedaction struct {
+	// Patterns are replaced in the text parts and the string values of data parts
+	Patterns []*regexp.Regexp
+	// Replacement replaces redacted content (default "[redacted]")
+	Replacement string
+	// OmitContent replaces the text of every part and drops data and file content, keeping
+	// their kinds, file names and MIME types
+	OmitContent bool
+	// OmitFiles drops the bytes and URIs of file parts
+	OmitFiles bool
+	// MetadataKeys are removed from the metadata of messages and artifacts
+	MetadataKeys []string
+}
+
+// WithAudit writes every message received, task state change, artifact produced and
+// authentication decision of the bearer token, API key, replay and admin token checks to
+// log, with message and artifact content redacted by redaction. Records that cannot be
+// written are logged rather than failing the request.
+func WithAudit(log AuditLog, redaction AuditRedaction) Option {
+	return func(s *A2AServer) {
+		if redaction.Replacement == "" {
+			redaction.Replacement = "[redacted]"
+		}
+		s.audit = &auditor{log: log, redaction: redaction}
+	}
+}
+
+// auditor writes the server's audit records
+type auditor struct {
+	log       AuditLog
+	redaction AuditRedaction
+}
+
+// auditCallerKey is the context key of the caller of the request a context belongs to
+type auditCallerKey struct{}
+
+// withAuditCaller records the caller of r in its context for the audit records of its tasks
+func (s *A2AServer) withAuditCaller(r *http.Request) *http.Request {
+	if s.audit == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), auditCallerKey{}, s.caller(r)))
+}
+
+// record stamps and writes record, logging failures
+func (s *A2AServer) record(ctx context.Context, record AuditRecord) {
+	record.Time = s.clock.Now().UTC()
+	if record.Caller == "" {
+		record.Caller, _ = ctx.Value(auditCallerKey{}).(string)
+	}
+	if record.RequestID == "" {
+		record.RequestID = RequestIDFromContext(ctx)
+	}
+	if err := s.audit.log.AppendAudit(ctx, record); err != nil {
+		s.log(ctx, record.TaskID).Error("failed to write audit record",
+			slog.String("type", string(record.Type)), slog.Any("error", err))
+	}
+}
+
+// auditMessage records message received for task
+func (s *A2AServer) auditMessage(ctx context.Context, task *models.Task, message *models.Message) {
+	if s.audit == nil {
+		return
+	}
+	redacted := *message
+	redacted.Parts = s.audit.redaction.parts(message.Parts)
+	redacted.Metadata = s.audit.redaction.metadata(message.Metadata)
+	s.record(ctx, AuditRecord{
+		Type:      AuditMessageReceived,
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		Skill:     skillOf(ctx, message),
+		Message:   &redacted,
+	})
+}
+
+// auditState records task entering its state from previous
+func (s *A2AServer) auditState(ctx context.Context, task *models.Task, previous models.TaskState) {
+	if s.audit == nil {
+		return
+	}
+	s.record(ctx, AuditRecord{
+		Type:          AuditTaskState,
+		TaskID:        task.ID,
+		ContextID:     task.ContextID,
+		State:         task.Status.State,
+		PreviousState: previous,
+	})
+}
+
+// auditArtifact records artifact produced by the task taskID
+func (s *A2AServer) auditArtifact(ctx context.Context, taskID string, artifact models.Artifact) {
+	if s.audit == nil {
+		return
+	}
+	artifact.Parts = s.audit.redaction.parts(artifact.Parts)
+	artifact.Metadata = s.audit.redaction.metadata(artifact.Metadata)
+	s.record(ctx, AuditRecord{Type: AuditArtifact, TaskID: taskID, Artifact: &artifact})
+}
+
+// auditAuth records the decision of the authentication scheme on r; reason is empty for
+// accepted requests
+func (s *A2AServer) auditAuth(r *http.Request, scheme, reason string) {
+	if s.audit == nil {
+		return
+	}
+	decision := AuditAllow
+	if reason != "" {
+		decision = AuditDeny
+	}
+	s.record(r.Context(), AuditRecord{
+		Type:     AuditAuthDecision,
+		Caller:   s.caller(r),
+		Scheme:   scheme,
+		Decision: decision,
+		Reason:   reason,
+		Path:     r.URL.Path,
+	})
+}
+
+// parts returns a redacted copy of parts
+func (a AuditRedaction) parts(parts []models.Part) []models.Part {
+	redacted := make([]models.Part, len(parts))
+	for i, part := range parts {
+		switch p := part.(type) {
+		case models.TextPart:
+			if a.OmitContent {
+				p.Text = a.Replacement
+			} else {
+				p.Text = a.text(p.Text)
+			}
+			part = p
+		case models.DataPart:
+			if a.OmitContent {
+				p.Data = nil
+			} else {
+				p.Data = a.value(p.Data)
+			}
+			part = p
+		case models.FilePart:
+			if a.OmitContent || a.OmitFiles {
+				p.Content = nil
+			}
+			part = p
+		}
+		redacted[i] = part
+	}
+	return redacted
+}
+
+// text replaces the matches of the redaction's patterns in text
+func (a AuditRedaction) text(text string) string {
+	for _, pattern := range a.Patterns {
+		text = pattern.ReplaceAllString(text, a.Replacement)
+	}
+	return text
+}
+
+// value returns a copy of the JSON value v with its strings redacted
+func (a AuditRedaction) value(v interface{}) interface{} {
+	if len(a.Patterns) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case string:
+		return a.text(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			redacted[key] = a.value(value)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, value := range v {
+			redacted[i] = a.value(value)
+		}
+		return redacted
+	}
+	return v
+}
+
+// metadata returns metadata without the redaction's metadata keys
+func (a AuditRedaction) metadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(a.MetadataKeys) == 0 || metadata == nil {
+		return metadata
+	}
+	redacted := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		if !slices.Contains(a.MetadataKeys, key) {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// AuditRecords returns the audit records selected by query (see WithAudit)
+func (s *A2AServer) AuditRecords(ctx context.Context, query AuditQuery) ([]AuditRecord, error) {
+	if s.audit == nil {
+		return nil, fmt.Errorf("audit log is not enabled")
+	}
+	return s.audit.log.QueryAudit(ctx, query)
+}
+
+// serveAuditRecords writes the audit records selected by the type, taskId, caller, since,
+// until and limit query parameters as JSON; since and until are RFC 3339 times
+func (s *A2AServer) serveAuditRecords(w http.ResponseWriter, r *http.Request) {
+	if s.audit == nil {
+		http.Error(w, "Audit log is not enabled", http.StatusNotFound)
+		return
+	}
+	params := r.URL.Query()
+	query := AuditQuery{Type: AuditType(params.Get("type")), TaskID: params.Get("taskId"), Caller: params.Get("caller")}
+	for name, field := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, "invalid "+name+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			*field = t
+		}
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+	records, err := s.audit.log.QueryAudit(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []AuditRecord{}
+	}
+	writeJSON(w, records)
+}
+
+// FileAuditLog is an AuditLog appending records to a JSONL file, one per line. The file is
+// never rotated or rewritten; queries read it from the start.
+type FileAuditLog struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if needed
+func OpenAuditLog(path string) (*FileAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditLog{path: path, file: file}, nil
+}
+
+// AppendAudit implements AuditLog, syncing each record to disk
+func (l *FileAuditLog) AppendAudit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// QueryAudit implements AuditLog
+func (l *FileAuditLog) QueryAudit(ctx context.Context, query AuditQuery) ([]AuditRecord, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	var records []AuditRecord
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		if !query.matches(record) {
+			continue
+		}
+		records = append(records, record)
+		if query.Limit > 0 && len(records) == query.Limit {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return records, scanner.Err()
+}
+
+// Close closes the audit log file
+func (l *FileAuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// testAuditLog checks the AuditLog contract against log, which must be empty
+func testAuditLog(t *testing.T, log AuditLog) {
+	t.Helper()
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []AuditRecord{
+		{Time: start, Type: AuditAuthDecision, Caller: "key:1", Scheme: "apiKey", Decision: AuditAllow},
+		{Time: start.Add(time.Second), Type: AuditMessageReceived, Caller: "key:1", TaskID: "t1",
+			Message: &models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}},
+		{Time: start.Add(2 * time.Second), Type: AuditTaskState, TaskID: "t1", State: models.TaskStateCompleted},
+		{Time: start.Add(3 * time.Second), Type: AuditAuthDecision, Caller: "ip:10.0.0.1", Scheme: "apiKey", Decision: AuditDeny, Reason: "missing API key"},
+	}
+	for _, record := range records {
+		if err := log.AppendAudit(ctx, record); err != nil {
+			t.Fatalf("Failed to append audit record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query AuditQuery
+		want  []int
+	}{
+		{"all", AuditQuery{}, []int{0, 1, 2, 3}},
+		{"type", AuditQuery{Type: AuditAuthDecision}, []int{0, 3}},
+		{"task", AuditQuery{TaskID: "t1"}, []int{1, 2}},
+		{"caller", AuditQuery{Caller: "key:1"}, []int{0, 1}},
+		{"time", AuditQuery{Since: start.Add(time.Second), Until: start.Add(3 * time.Second)}, []int{1, 2}},
+		{"limit", AuditQuery{Limit: 3}, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		got, err := log.QueryAudit(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("%s: failed to query audit records: %v", tt.name, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %d records, got %+v", tt.name, len(tt.want), got)
+			continue
+		}
+		for i, want := range tt.want {
+			if got[i].Type != records[want].Type || !got[i].Time.Equal(records[want].Time) || got[i].Reason != records[want].Reason {
+				t.Errorf("%s: expected record %d to be %+v, got %+v", tt.name, i, records[want], got[i])
+			}
+		}
+	}
+	got, _ := log.QueryAudit(ctx, AuditQuery{Type: AuditMessageReceived})
+	if len(got) != 1 || got[0].Message == nil || got[0].Message.Text() != "Hello" {
+		t.Errorf("Expected the message to be recorded, got %+v", got)
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	testAuditLog(t, log)
+	log.Close()
+
+	// Reopening appends to the records already written
+	log, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	defer log.Close()
+	log.AppendAudit(context.Background(), AuditRecord{Type: AuditTaskState, TaskID: "t2"})
+	if records, err := log.QueryAudit(context.Background(), AuditQuery{}); err != nil || len(records) != 5 {
+		t.Errorf("Expected 5 records after reopening, got %d (%v)", len(records), err)
+	}
+}
+
+func TestAuditRedaction(t *testing.T) {
+	redaction := AuditRedaction{Patterns: []*regexp.Regexp{emailPattern}, Replacement: "[email]", OmitFiles: true}
+	parts := []models.Part{
+		models.TextPart{Type: "text", Text: "Write to ada@example.com"},
+		models.DataPart{Type: "data", Data: map[string]interface{}{"to": []interface{}{"bob@example.com"}, "n": 1}},
+		models.FilePart{Type: "file", FileName: "cv.pdf", MimeType: "application/pdf", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte("%PDF")}},
+	}
+	redacted := redaction.parts(parts)
+	if text := redacted[0].(models.TextPart).Text; text != "Write to [email]" {
+		t.Errorf("Expected the email to be redacted, got %q", text)
+	}
+	data := redacted[1].(models.DataPart).Data.(map[string]interface{})
+	if data["to"].([]interface{})[0] != "[email]" || data["n"] != 1 {
+		t.Errorf("Expected nested strings to be redacted, got %v", data)
+	}
+	if file := redacted[2].(models.FilePart); file.Content != nil || file.FileName != "cv.pdf" {
+		t.Errorf("Expected the file content to be dropped, got %+v", file)
+	}
+	if parts[0].(models.TextPart).Text != "Write to ada@example.com" || parts[1].(models.DataPart).Data.(map[string]interface{})["to"].([]interface{})[0] != "bob@example.com" {
+		t.Error("Expected the original parts to be left alone")
+	}
+
+	omitted := AuditRedaction{OmitContent: true, Replacement: "[redacted]", MetadataKeys: []string{"ssn"}}
+	redacted = omitted.parts(parts)
+	if redacted[0].(models.TextPart).Text != "[redacted]" || redacted[1].(models.DataPart).Data != nil {
+		t.Errorf("Expected all content to be omitted, got %+v", redacted)
+	}
+	if metadata := omitted.metadata(map[string]interface{}{"ssn": "123", "lang": "en"}); len(metadata) != 1 || metadata["lang"] != "en" {
+		t.Errorf("Expected the ssn metadata to be removed, got %v", metadata)
+	}
+}
+
+func TestA2AServer_Audit(t *testing.T) {
+	log, err := OpenAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer log.Close()
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.NewTextPart("Reply to ada@example.com")}}}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler,
+		WithAudit(log, AuditRedaction{Patterns: []*regexp.Regexp{emailPattern}, MetadataKeys: []string{"secret"}}),
+		WithAdmin(AdminConfig{Token: testAdminToken}))
+
+	doRPC(t, server, "message/send", models.MessageSendParams{ID: "audited", Message: models.Message{
+		Role:     "user",
+		Parts:    []models.Part{models.NewTextPart("My email is ada@example.com")},
+		Metadata: map[string]interface{}{"secret": "s3cr3t", "lang": "en"},
+	}})
+	w := httptest.NewRecorder()
+	server.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected the audit log to need the admin token, got %d", w.Code)
+	}
+
+	var records []AuditRecord
+	adminRequest(t, server, http.MethodGet, "/admin/audit?taskId=audited", &records)
+	var types []AuditType
+	for _, record := range records {
+		types = append(types, record.Type)
+	}
+	want := []AuditType{AuditMessageReceived, AuditTaskState, AuditTaskState, AuditArtifact}
+	if len(types) != len(want) {
+		t.Fatalf("Expected records %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("Expected records %v, got %v", want, types)
+		}
+	}
+	message := records[0].Message
+	if message.Text() != "My email is [redacted]" || message.Metadata["secret"] != nil || message.Metadata["lang"] != "en" {
+		t.Errorf("Expected the message to be redacted, got %+v", message)
+	}
+	if records[0].Caller == "" || records[2].State != models.TaskStateCompleted || records[2].PreviousState != models.TaskStateWorking {
+		t.Errorf("Expected the caller and the completion to be recorded, got %+v and %+v", records[0], records[2])
+	}
+	if text := records[3].Artifact.Parts[0].(models.TextPart).Text; text != "Reply to [redacted]" {
+		t.Errorf("Expected the artifact to be redacted, got %q", text)
+	}
+
+	// The refused request and the accepted ones, including this one
+	var decisions []AuditRecord
+	adminRequest(t, server, http.MethodGet, "/admin/audit?type=auth.decision", &decisions)
+	if len(decisions) != 3 || decisions[0].Decision != AuditDeny || decisions[0].Scheme != "admin" || decisions[1].Decision != AuditAllow {
+		t.Errorf("Expected the refused and accepted admin requests, got %+v", decisions)
+	}
+	if w := adminRequest(t, server, http.MethodGet, "/admin/audit?since=yesterday", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid time to be refused, got %d", w.Code)
+	}
+}
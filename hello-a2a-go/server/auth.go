@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"a2a/models"
+)
+
+// ExtendedCardMethod is the JSON-RPC method returning the authenticated extended agent card
+const ExtendedCardMethod = "agent/getAuthenticatedExtendedCard"
+
+// bearerSchemeName names the security scheme WithBearerAuth declares on cards without one
+const bearerSchemeName = "bearer"
+
+// WithBearerAuth requires an "Authorization: Bearer" token accepted by verify, such as
+// StaticToken or JWTVerifier, on every endpoint except the public agent card and metrics. Cards
+// that declare no bearer scheme get an HTTP bearer scheme requiring no scopes; declare one with
+// AgentBuilder.WithSecurityScheme to require scopes instead (see RequireBearer).
+func WithBearerAuth(verify TokenVerifier) Option {
+	return func(s *A2AServer) {
+		s.bearerAuth = verify
+	}
+}
+
+// StaticToken returns a TokenVerifier accepting only token, granting it scopes
+func StaticToken(token string, scopes ...string) TokenVerifier {
+	return func(ctx context.Context, got string) ([]string, error) {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, errors.New("unknown token")
+		}
+		return scopes, nil
+	}
+}
+
+// WithExtendedAgentCard serves card to authenticated callers with the
+// agent/getAuthenticatedExtendedCard method and at GET /agent/authenticatedExtendedCard, e.g. to
+// list skills withheld from the public card, which then advertises
+// supportsAuthenticatedExtendedCard
+func WithExtendedAgentCard(card models.AgentCard) Option {
+	return func(s *A2AServer) {
+		s.extendedCard = &card
+	}
+}
+
+// requireBearerAuth declares a bearer scheme on the card unless it has one, and guards the
+// server's endpoints with the verifier of WithBearerAuth
+func (s *A2AServer) requireBearerAuth() {
+	if len(bearerRequirements(s.agentCard)) == 0 {
+		schemes := make(map[string]models.SecurityScheme, len(s.agentCard.SecuritySchemes)+1)
+		for name, scheme := range s.agentCard.SecuritySchemes {
+			schemes[name] = scheme
+		}
+		schemes[bearerSchemeName] = models.SecurityScheme{Type: models.SecuritySchemeHTTP, Scheme: "bearer"}
+		s.agentCard.SecuritySchemes = schemes
+		s.agentCard.Security = append(append([]map[string][]string(nil), s.agentCard.Security...), map[string][]string{bearerSchemeName: {}})
+	}
+	s.Use(s.RequireBearer(s.bearerAuth))
+}
+
+// serveExtendedCard writes the authenticated extended agent card as JSON
+func (s *A2AServer) serveExtendedCard(w http.ResponseWriter, r *http.Request) {
+	if s.extendedCard == nil {
+		http.Error(w, "Authenticated extended card is not configured", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.extendedCard)
+}
+
+// handleExtendedCard answers the agent/getAuthenticatedExtendedCard method
+func (s *A2AServer) handleExtendedCard(w http.ResponseWriter, req *models.JSONRPCRequest) {
+	if s.extendedCard == nil {
+		s.sendA2AError(w, req.ID, models.NewA2AError(models.ErrorCodeAuthenticatedExtendedCardNotConfigured, "Authenticated extended card is not configured"))
+		return
+	}
+	s.sendResponseWithID(w, req.ID, s.extendedCard)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestWithBearerAuth(t *testing.T) {
+	extended := mockAgentCard
+	extended.Skills = append(extended.Skills, models.AgentSkill{ID: "admin", Name: "Admin"})
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithBearerAuth(StaticToken("s3cret")), WithExtendedAgentCard(extended))
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// The public card stays public and declares the bearer scheme and the extended card
+	w := do("GET", "/.well-known/agent-card", "", "")
+	var card models.AgentCard
+	json.NewDecoder(w.Body).Decode(&card)
+	if w.Code != http.StatusOK || card.SecuritySchemes["bearer"].Scheme != "bearer" || len(card.Security) != 1 {
+		t.Errorf("Expected the public card to declare a bearer scheme, got %d %+v", w.Code, card)
+	}
+	if card.SupportsAuthenticatedExtendedCard == nil || !*card.SupportsAuthenticatedExtendedCard || len(card.Skills) != 1 {
+		t.Errorf("Expected the public card to advertise the extended card without its skills, got %+v", card)
+	}
+
+	send := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"t1","message":{"role":"user","parts":[{"kind":"text","text":"Hi"}]}}}`
+	if w := do("POST", "/a2a", send, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+	if w := do("POST", "/a2a", send, "guessed"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", w.Code)
+	}
+	if w := do("POST", "/a2a", send, "s3cret"); w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"error"`) {
+		t.Errorf("Expected the authenticated request to succeed, got %d %s", w.Code, w.Body)
+	}
+
+	if w := do("GET", "/agent/authenticatedExtendedCard", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the extended card to require a token, got %d", w.Code)
+	}
+	w = do("GET", "/agent/authenticatedExtendedCard", "", "s3cret")
+	card = models.AgentCard{}
+	json.NewDecoder(w.Body).Decode(&card)
+	if w.Code != http.StatusOK || len(card.Skills) != 2 {
+		t.Errorf("Expected the extended card, got %d %+v", w.Code, card)
+	}
+
+	w = do("POST", "/a2a", `{"jsonrpc":"2.0","id":2,"method":"agent/getAuthenticatedExtendedCard"}`, "s3cret")
+	var response models.JSONRPCResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	card = models.AgentCard{}
+	decodeResult(t, response.Result, &card)
+	if response.Error != nil || len(card.Skills) != 2 {
+		t.Errorf("Expected the extended card over JSON-RPC, got %+v", response)
+	}
+}
+
+func TestExtendedCard_NotConfigured(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	if card := server.AgentCard(); card.SupportsAuthenticatedExtendedCard != nil {
+		t.Errorf("Expected no extended card to be advertised, got %v", *card.SupportsAuthenticatedExtendedCard)
+	}
+	response := doRPC(t, server, ExtendedCardMethod, nil)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeAuthenticatedExtendedCardNotConfigured) {
+		t.Errorf("Expected error %d, got %+v", models.ErrorCodeAuthenticatedExtendedCardNotConfigured, response.Error)
+	}
+}
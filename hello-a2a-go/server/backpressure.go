@@ -0,0 +1,211 @@
+package server
+
+import (
+	"time"
+
+	"a2a/models"
+)
+
+// BackpressurePolicy is what a stream does with a subscriber that falls behind
+type BackpressurePolicy string
+
+const (
+	// BackpressureDropOldest skips the oldest events a slow subscriber has not read, and sends it
+	// a marker event counting them in their place
+	BackpressureDropOldest BackpressurePolicy = "drop-oldest"
+	// BackpressureDisconnect ends the stream of a slow subscriber with a final marker event
+	BackpressureDisconnect BackpressurePolicy = "disconnect"
+	// BackpressureBlock holds up the task's next event until slow subscribers catch up, for at
+	// most the timeout, then disconnects those still behind as BackpressureDisconnect does
+	BackpressureBlock BackpressurePolicy = "block"
+)
+
+// defaultBlockTimeout bounds how long BackpressureBlock holds up a handler
+const defaultBlockTimeout = 5 * time.Second
+
+// eventsDroppedKey is the metadata key of a marker event, holding the number of events its
+// subscriber missed
+const eventsDroppedKey = "eventsDropped"
+
+// StreamBackpressure bounds the events each subscriber of a stream may have unsent, so that a
+// client reading slowly neither holds up the task nor falls arbitrarily far behind. The zero
+// value leaves subscribers unbounded.
+type StreamBackpressure struct {
+	// Buffer is the number of events a subscriber may have unsent (0 means unbounded)
+	Buffer int
+	// Policy handles a subscriber whose buffer overflows (BackpressureDropOldest by default)
+	Policy BackpressurePolicy
+	// BlockTimeout is how long BackpressureBlock waits for a slow subscriber (5s by default)
+	BlockTimeout time.Duration
+}
+
+// WithStreamBackpressure bounds the events buffered for each stream subscriber. A subscriber
+// that misses events receives a status update event whose metadata counts them under
+// "eventsDropped", telling it to fetch the task with tasks/get; the marker is final when the
+// subscriber is disconnected. Dropped events are counted by policy in
+// a2a_stream_events_dropped_total.
+func WithStreamBackpressure(backpressure StreamBackpressure) Option {
+	return func(s *A2AServer) {
+		if backpressure.Policy == "" {
+			backpressure.Policy = BackpressureDropOldest
+		}
+		if backpressure.BlockTimeout <= 0 {
+			backpressure.BlockTimeout = defaultBlockTimeout
+		}
+		s.backpressure = backpressure
+	}
+}
+
+// streamSubscriber is a client reading a stream. Its fields are guarded by the stream's mutex.
+type streamSubscriber struct {
+	// next is the number of events of the stream sent to the subscriber or dropped
+	next int
+	// evicted is set when the subscriber is to be disconnected
+	evicted bool
+}
+
+// streamBatch is what a subscriber reads from a stream at once
+type streamBatch struct {
+	// events are the events to send, the first numbered start+1
+	events []interface{}
+	start  int
+	// dropped is the number of events skipped before the batch
+	dropped int
+	// evicted is set when the subscriber is disconnected instead of sent more events
+	evicted bool
+	// status is the last status the stream published before the batch
+	status  models.TaskStatus
+	done    bool
+	changed <-chan struct{}
+}
+
+// subscribe registers a subscriber that has received the first next events of the stream
+func (t *taskStream) subscribe(next int) *streamSubscriber {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sub := &streamSubscriber{next: min(next, len(t.events))}
+	t.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe forgets sub, releasing a publisher waiting for it
+func (t *taskStream) unsubscribe(sub *streamSubscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.subscribers, sub)
+	t.drain()
+}
+
+// read returns the events sub is to be sent next, applying the stream's backpressure policy
+// to a subscriber that has fallen behind
+func (t *taskStream) read(sub *streamSubscriber) streamBatch {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lag := len(t.events) - sub.next
+	if limit := t.backpressure.Buffer; limit > 0 && lag > limit && !sub.evicted {
+		if t.backpressure.Policy == BackpressureDropOldest {
+			sub.next += lag - limit
+			batch := t.batchLocked(sub)
+			batch.dropped = lag - limit
+			return batch
+		}
+		sub.evicted = true
+	}
+	if sub.evicted {
+		batch := streamBatch{
+			start:   sub.next,
+			dropped: lag,
+			evicted: true,
+			status:  t.statusLocked(len(t.events)),
+			done:    t.done,
+			changed: t.changed,
+		}
+		sub.next = len(t.events)
+		return batch
+	}
+	return t.batchLocked(sub)
+}
+
+// batchLocked returns the events after sub's position, with t.mu held
+func (t *taskStream) batchLocked(sub *streamSubscriber) streamBatch {
+	return streamBatch{
+		events:  t.events[sub.next:],
+		start:   sub.next,
+		status:  t.statusLocked(sub.next),
+		done:    t.done,
+		changed: t.changed,
+	}
+}
+
+// statusLocked returns the status of the last status update among the first n events, with
+// t.mu held
+func (t *taskStream) statusLocked(n int) models.TaskStatus {
+	for i := n - 1; i >= 0; i-- {
+		if update, ok := t.events[i].(models.TaskStatusUpdateEvent); ok {
+			return update.Status
+		}
+	}
+	return models.TaskStatus{State: models.TaskStateWorking}
+}
+
+// sent records that sub was sent its next event
+func (t *taskStream) sent(sub *streamSubscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sub.next++
+	t.drain()
+}
+
+// drain wakes a publisher waiting for subscribers, with t.mu held
+func (t *taskStream) drain() {
+	close(t.drained)
+	t.drained = make(chan struct{})
+}
+
+// awaitSubscribers waits, with t.mu held, until every subscriber has room for another event
+// under BackpressureBlock. Subscribers still without room after the timeout are evicted.
+func (t *taskStream) awaitSubscribers() {
+	limit := t.backpressure.Buffer
+	if t.backpressure.Policy != BackpressureBlock || limit <= 0 {
+		return
+	}
+	var timeout <-chan time.Time
+	for {
+		var full []*streamSubscriber
+		for sub := range t.subscribers {
+			if !sub.evicted && len(t.events)-sub.next >= limit {
+				full = append(full, sub)
+			}
+		}
+		if len(full) == 0 {
+			return
+		}
+		if timeout == nil {
+			timeout = t.clock.After(t.backpressure.BlockTimeout)
+		}
+		drained := t.drained
+		t.mu.Unlock()
+		select {
+		case <-drained:
+			t.mu.Lock()
+		case <-timeout:
+			t.mu.Lock()
+			for _, sub := range full {
+				if len(t.events)-sub.next >= limit {
+					sub.evicted = true
+				}
+			}
+			return
+		}
+	}
+}
+
+// droppedEvent returns the marker event telling a subscriber of the batch that it missed events
+func (t *taskStream) droppedEvent(batch streamBatch) models.TaskStatusUpdateEvent {
+	return models.TaskStatusUpdateEvent{
+		ID:       t.taskID,
+		Status:   batch.status,
+		Final:    boolPtr(batch.evicted),
+		Metadata: map[string]interface{}{eventsDroppedKey: batch.dropped},
+	}
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// boundedStream returns a stream whose subscribers may have buffer events unsent
+func boundedStream(fake *clock.Fake, buffer int, policy BackpressurePolicy) *taskStream {
+	stream := newTaskStream("task-1")
+	stream.clock = fake
+	stream.backpressure = StreamBackpressure{Buffer: buffer, Policy: policy, BlockTimeout: time.Second}
+	return stream
+}
+
+// publishStatuses publishes n working status updates to stream
+func publishStatuses(stream *taskStream, n int) {
+	for i := 0; i < n; i++ {
+		stream.publish(models.TaskStatusUpdateEvent{ID: "task-1", Status: models.TaskStatus{State: models.TaskStateWorking}})
+	}
+}
+
+func TestBackpressure_DropOldest(t *testing.T) {
+	stream := boundedStream(clock.NewFake(time.Unix(0, 0)), 2, BackpressureDropOldest)
+	sub := stream.subscribe(0)
+	publishStatuses(stream, 5)
+
+	batch := stream.read(sub)
+	if batch.dropped != 3 || batch.start != 3 || len(batch.events) != 2 || batch.evicted {
+		t.Fatalf("Expected the 3 oldest events dropped and 2 left, got %+v", batch)
+	}
+	marker := stream.droppedEvent(batch)
+	if *marker.Final || marker.Metadata[eventsDroppedKey] != 3 || marker.Status.State != models.TaskStateWorking {
+		t.Errorf("Expected a non-final marker counting 3 events, got %+v", marker)
+	}
+	stream.sent(sub)
+	stream.sent(sub)
+	if batch := stream.read(sub); batch.dropped != 0 || len(batch.events) != 0 {
+		t.Errorf("Expected a subscriber that caught up to read nothing, got %+v", batch)
+	}
+}
+
+func TestBackpressure_Disconnect(t *testing.T) {
+	stream := boundedStream(clock.NewFake(time.Unix(0, 0)), 2, BackpressureDisconnect)
+	sub := stream.subscribe(0)
+	publishStatuses(stream, 2)
+	if batch := stream.read(sub); batch.dropped != 0 || len(batch.events) != 2 {
+		t.Fatalf("Expected a full buffer to be read whole, got %+v", batch)
+	}
+	publishStatuses(stream, 1)
+
+	batch := stream.read(sub)
+	if !batch.evicted || batch.dropped != 3 || len(batch.events) != 0 {
+		t.Fatalf("Expected the subscriber evicted after 3 unsent events, got %+v", batch)
+	}
+	if marker := stream.droppedEvent(batch); !*marker.Final {
+		t.Errorf("Expected the marker of an evicted subscriber to be final, got %+v", marker)
+	}
+}
+
+func TestBackpressure_Block(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	stream := boundedStream(fake, 1, BackpressureBlock)
+	sub := stream.subscribe(0)
+	publishStatuses(stream, 1)
+
+	published := make(chan struct{})
+	go func() {
+		publishStatuses(stream, 1)
+		close(published)
+	}()
+	fake.BlockUntil(1)
+	select {
+	case <-published:
+		t.Fatal("Expected the publisher to wait for the slow subscriber")
+	default:
+	}
+	stream.sent(sub)
+	<-published
+
+	// A subscriber that does not catch up in time is evicted
+	go publishStatuses(stream, 1)
+	// The first wait's timer is still pending
+	fake.BlockUntil(2)
+	fake.Advance(time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if batch := stream.read(sub); batch.evicted {
+			if batch.dropped != 2 {
+				t.Errorf("Expected the unsent and the new event dropped, got %+v", batch)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the subscriber to be evicted after the timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// gatedRecorder records a response, closing writing on its first write and holding it up until
+// gate is closed
+type gatedRecorder struct {
+	*httptest.ResponseRecorder
+	writing chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func (g *gatedRecorder) Write(p []byte) (int, error) {
+	g.once.Do(func() {
+		close(g.writing)
+		<-g.gate
+	})
+	return g.ResponseRecorder.Write(p)
+}
+
+func TestA2AServer_SlowStreamSubscriber(t *testing.T) {
+	w := &gatedRecorder{ResponseRecorder: httptest.NewRecorder(), writing: make(chan struct{}), gate: make(chan struct{})}
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		// Work once the client is stuck reading the first event
+		<-w.writing
+		for i := 0; i < 5; i++ {
+			EmitArtifact(ctx, models.Artifact{Parts: []models.Part{models.NewTextPart("chunk")}})
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithMetrics(),
+		WithStreamBackpressure(StreamBackpressure{Buffer: 1}))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"slow","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]}}}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	served := make(chan struct{})
+	go func() {
+		server.ServeHTTP(w, req)
+		close(served)
+	}()
+
+	// The task finishes while the client has yet to read the first event
+	<-w.writing
+	for server.runningStream("slow") != nil {
+		time.Sleep(time.Millisecond)
+	}
+	close(w.gate)
+	<-served
+
+	var results []models.TaskStatusUpdateEvent
+	for _, data := range sseData(t, w.Body.String()) {
+		var resp struct {
+			Result models.TaskStatusUpdateEvent `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			t.Fatalf("Failed to decode event %s: %v", data, err)
+		}
+		results = append(results, resp.Result)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected the first event, a marker and the final event, got %+v", results)
+	}
+	if dropped := results[1].Metadata[eventsDroppedKey]; dropped != float64(5) || *results[1].Final {
+		t.Errorf("Expected a marker counting the 5 dropped artifacts, got %+v", results[1])
+	}
+	if last := results[2]; !*last.Final || last.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the final event to be kept, got %+v", last)
+	}
+
+	metrics := httptest.NewRecorder()
+	server.MetricsHandler().ServeHTTP(metrics, httptest.NewRequest("GET", "/metrics", nil))
+	if want := `a2a_stream_events_dropped_total{policy="drop-oldest"} 5`; !strings.Contains(metrics.Body.String(), want) {
+		t.Errorf("Expected metrics to contain %q, got:\n%s", want, metrics.Body.String())
+	}
+	if metrics.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", metrics.Code)
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"a2a/models"
+)
+
+// isBatch reports whether the JSON body starts with an array, i.e. holds a JSON-RPC batch
+func isBatch(body *bufio.Reader) bool {
+	for {
+		b, err := body.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			body.Discard(1)
+		default:
+			return b[0] == '['
+		}
+	}
+}
+
+// serveBatch serves a JSON-RPC batch: each request is dispatched in order and the responses are
+// returned as an array in the same order. Notifications, requests without an id, get no
+// response; a batch of only notifications is answered with 204 No Content. Streaming methods
+// cannot be batched and are answered with an invalid request error.
+func (s *A2AServer) serveBatch(w http.ResponseWriter, r *http.Request, body *bufio.Reader) {
+	var batch []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		s.sendDecodeError(w, err)
+		return
+	}
+	if len(batch) == 0 {
+		s.sendA2AError(w, nil, models.NewInvalidRequestError("Empty batch"))
+		return
+	}
+	if max := s.limits.MaxBatchRequests; max > 0 && len(batch) > max {
+		s.sendA2AError(w, nil, models.NewInvalidRequestError(fmt.Sprintf("Batch exceeds %d requests", max)))
+		return
+	}
+
+	// Requests are served without streaming, whatever the batch was sent with
+	single := r.Clone(r.Context())
+	single.Header.Del("Accept")
+
+	responses := make([]json.RawMessage, 0, len(batch))
+	for _, raw := range batch {
+		if response := s.serveBatched(single, raw); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// serveBatched serves one request of a batch, returning its response, or nil for a notification
+func (s *A2AServer) serveBatched(r *http.Request, raw json.RawMessage) json.RawMessage {
+	var req models.JSONRPCRequest
+	var fields map[string]json.RawMessage
+	if err := models.DecodeJSON(raw, &req); err != nil || json.Unmarshal(raw, &fields) != nil {
+		return errorResponse(nil, models.NewInvalidRequestError("Invalid request in batch"))
+	}
+	if req.Method == "message/stream" || req.Method == ResubscribeMethod {
+		return errorResponse(req.ID, models.NewInvalidRequestError(req.Method+" cannot be batched"))
+	}
+
+	out := &batchWriter{header: make(http.Header)}
+	s.dispatch(out, r, req)
+	if _, ok := fields["id"]; !ok {
+		return nil
+	}
+	response := bytes.TrimSpace(out.body.Bytes())
+	if !json.Valid(response) {
+		return errorResponse(req.ID, models.NewInternalError("Invalid response"))
+	}
+	return response
+}
+
+// errorResponse encodes err as the JSON-RPC error response to the request id
+func errorResponse(id interface{}, err *models.A2AError) json.RawMessage {
+	response, _ := json.Marshal(models.JSONRPCResponse{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id},
+		},
+		Error: err.JSONRPC(),
+	})
+	return response
+}
+
+// batchWriter is an http.ResponseWriter capturing the response to one request of a batch
+type batchWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter
+func (b *batchWriter) Header() http.Header {
+	return b.header
+}
+
+// Write implements http.ResponseWriter
+func (b *batchWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// WriteHeader implements http.ResponseWriter; the status of a batched response is not reported
+func (b *batchWriter) WriteHeader(int) {}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// doBatch posts body to server and returns the response
+func doBatch(t *testing.T, server *A2AServer, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestA2AServer_Batch(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithMaxBatchRequests(4))
+	message := `{"role":"user","parts":[{"kind":"text","text":"Hi"}]}`
+
+	w := doBatch(t, server, ` [
+		{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"task-1","message":`+message+`}},
+		{"jsonrpc":"2.0","method":"message/send","params":{"id":"task-2","message":`+message+`}},
+		{"jsonrpc":"2.0","id":"b","method":"message/stream","params":{"id":"task-3","message":`+message+`}},
+		{"jsonrpc":"2.0","id":"c","method":"tasks/get","params":{"id":"task-2"}}
+	]`)
+	var responses []models.JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&responses); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses without the notification's, got %+v", responses)
+	}
+
+	// Requests are served in order, without streaming, and keep their IDs
+	var task models.Task
+	decodeResult(t, responses[0].Result, &task)
+	if responses[0].ID != float64(1) || task.ID != "task-1" || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected task-1 completed for request 1, got %v: %+v", responses[0].ID, task)
+	}
+	if responses[1].ID != "b" || responses[1].Error == nil || responses[1].Error.Code != int(models.ErrorCodeInvalidRequest) {
+		t.Errorf("Expected message/stream to be rejected, got %+v", responses[1])
+	}
+	decodeResult(t, responses[2].Result, &task)
+	if responses[2].ID != "c" || task.ID != "task-2" {
+		t.Errorf("Expected the notification to have created task-2, got %v: %+v", responses[2].ID, task)
+	}
+
+	// Invalid elements are answered in place
+	w = doBatch(t, server, `[1, {"jsonrpc":"2.0","id":"d","method":"tasks/get","params":{"id":"task-1"}}]`)
+	responses = nil
+	if err := json.NewDecoder(w.Body).Decode(&responses); err != nil || len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %+v (%v)", responses, err)
+	}
+	if responses[0].ID != nil || responses[0].Error == nil || responses[0].Error.Code != int(models.ErrorCodeInvalidRequest) || responses[1].Error != nil {
+		t.Errorf("Expected an invalid request error for the first element only, got %+v", responses)
+	}
+
+	// Notifications alone get no content
+	if w := doBatch(t, server, `[{"jsonrpc":"2.0","method":"tasks/get","params":{"id":"task-1"}}]`); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("Expected 204 for a batch of notifications, got %d %q", w.Code, w.Body)
+	}
+
+	// Empty and oversized batches are rejected as a whole
+	for _, body := range []string{`[]`, `[{}, {}, {}, {}, {}]`} {
+		var response models.JSONRPCResponse
+		if err := json.NewDecoder(doBatch(t, server, body).Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response to %s: %v", body, err)
+		}
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidRequest) {
+			t.Errorf("Expected an invalid request error for %s, got %+v", body, response)
+		}
+	}
+}
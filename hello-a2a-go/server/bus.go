@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// EventType is the type of a task lifecycle event published on the server's event bus
+type EventType string
+
+// Task lifecycle event types
+const (
+	// EventTaskCreated is published when a task is first saved
+	EventTaskCreated EventType = "task.created"
+	// EventTaskStateChanged is published when a task is saved in a state other than its last,
+	// including its first
+	EventTaskStateChanged EventType = "task.state"
+	// EventTaskArtifact is published for each artifact or artifact chunk a task produces
+	EventTaskArtifact EventType = "task.artifact"
+	// EventTaskCompleted is published when a task is saved in a terminal state, such as
+	// completed, failed or canceled, after its state change
+	EventTaskCompleted EventType = "task.completed"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind before events are dropped
+const eventBufferSize = 64
+
+// Event is a task lifecycle event, as received by the subscribers of A2AServer.Subscribe.
+// Subscribers must not modify the task or artifact it carries.
+type Event struct {
+	Type   EventType
+	TaskID string
+	Time   time.Time
+	// Task is the task as saved, for all but artifact events
+	Task *models.Task
+	// State is the state the task entered and PreviousState the state it left, empty for new
+	// tasks, for all but artifact events
+	State         models.TaskState
+	PreviousState models.TaskState
+	// Artifact is the artifact or chunk produced, for artifact events
+	Artifact *models.Artifact
+}
+
+// EventFilter selects the events a subscriber receives; nil selects every event
+type EventFilter func(Event) bool
+
+// EventTypes selects events of the given types
+func EventTypes(types ...EventType) EventFilter {
+	return func(event Event) bool {
+		return slices.Contains(types, event.Type)
+	}
+}
+
+// TaskEvents selects the events of the task with ID taskID
+func TaskEvents(taskID string) EventFilter {
+	return func(event Event) bool {
+		return event.TaskID == taskID
+	}
+}
+
+// Subscribe returns a channel receiving the task lifecycle events selected by filter until ctx
+// is done, when it is closed. Events are delivered in order without blocking the server: a
+// subscriber more than 64 events behind misses events, which are logged as dropped, so
+// consumers such as metrics exporters, webhooks and audit logs should receive promptly and
+// do slow work elsewhere.
+func (s *A2AServer) Subscribe(ctx context.Context, filter EventFilter) <-chan Event {
+	sub := &subscriber{filter: filter, events: make(chan Event, eventBufferSize)}
+	s.bus.mu.Lock()
+	if s.bus.subscribers == nil {
+		s.bus.subscribers = make(map[*subscriber]struct{})
+	}
+	s.bus.subscribers[sub] = struct{}{}
+	s.bus.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+		delete(s.bus.subscribers, sub)
+		close(sub.events)
+	})
+	return sub.events
+}
+
+// eventBus fans task lifecycle events out to in-process subscribers, tracking the last saved
+// state of each unfinished task to tell state changes apart
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	// states holds the last saved state of each task not yet in a terminal state
+	states map[string]models.TaskState
+}
+
+// subscriber is a channel subscribed to the bus and the filter of its events
+type subscriber struct {
+	filter EventFilter
+	events chan Event
+}
+
+// publishSaved publishes the created, state change and completion events of saving task
+func (s *A2AServer) publishSaved(ctx context.Context, task *models.Task) {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	state := task.Status.State
+	previous, known := s.bus.states[task.ID]
+	if state.IsTerminal() {
+		delete(s.bus.states, task.ID)
+	} else {
+		if s.bus.states == nil {
+			s.bus.states = make(map[string]models.TaskState)
+		}
+		s.bus.states[task.ID] = state
+	}
+	if known && previous == state {
+		return
+	}
+	s.auditState(ctx, task, previous)
+
+	snapshot := *task
+	event := Event{TaskID: task.ID, Time: s.clock.Now(), Task: &snapshot, State: state, PreviousState: previous}
+	if !known {
+		event.Type = EventTaskCreated
+		s.deliver(ctx, event)
+	}
+	event.Type = EventTaskStateChanged
+	s.deliver(ctx, event)
+	if state.IsTerminal() {
+		event.Type = EventTaskCompleted
+		s.deliver(ctx, event)
+	}
+}
+
+// publishArtifact publishes an artifact event for artifact of the task taskID
+func (s *A2AServer) publishArtifact(ctx context.Context, taskID string, artifact models.Artifact) {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.auditArtifact(ctx, taskID, artifact)
+	s.deliver(ctx, Event{Type: EventTaskArtifact, TaskID: taskID, Time: s.clock.Now(), Artifact: &artifact})
+}
+
+// deliver sends event to the subscribers whose filter selects it, dropping it for those whose
+// buffer is full. Callers hold s.bus.mu, so that events are delivered in the order published.
+func (s *A2AServer) deliver(ctx context.Context, event Event) {
+	for sub := range s.bus.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.log(ctx, event.TaskID).Warn("dropped event for slow subscriber", slog.String("event", string(event.Type)))
+		}
+	}
+}
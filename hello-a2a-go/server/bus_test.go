@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// drain returns the events buffered in events
+func drain(events <-chan Event) []string {
+	var got []string
+	for {
+		select {
+		case event := <-events:
+			description := string(event.Type)
+			switch {
+			case event.Artifact != nil:
+				description += " " + event.Artifact.Parts[0].(models.TextPart).Text
+			default:
+				description += fmt.Sprintf(" %s>%s", event.PreviousState, event.State)
+			}
+			got = append(got, description)
+		default:
+			return got
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.TextPart{Type: "text", Text: "done"}}}}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+	ctx, cancel := context.WithCancel(context.Background())
+	all := server.Subscribe(ctx, nil)
+	finished := server.Subscribe(context.Background(), EventTypes(EventTaskCompleted))
+	other := server.Subscribe(context.Background(), TaskEvents("other"))
+
+	doRPC(t, server, "message/send", models.MessageSendParams{ID: "bus", Message: models.Message{Role: "user",
+		Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}})
+
+	want := "task.created >working|task.state >working|task.state working>completed|task.completed working>completed|task.artifact done"
+	if got := strings.Join(drain(all), "|"); got != want {
+		t.Errorf("Expected the task's lifecycle\n%s\ngot\n%s", want, got)
+	}
+	if got := drain(finished); len(got) != 1 {
+		t.Errorf("Expected only the completion, got %q", got)
+	}
+	if got := drain(other); len(got) != 0 {
+		t.Errorf("Expected no events of another task, got %q", got)
+	}
+
+	cancel()
+	if _, ok := <-all; ok {
+		t.Error("Expected the channel to close once the subscription ends")
+	}
+}
+
+func TestSubscribe_StreamingArtifacts(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, Streaming(countdown))
+	artifacts := server.Subscribe(context.Background(), EventTypes(EventTaskArtifact))
+	// A subscriber that never receives falls behind without blocking the task
+	server.Subscribe(context.Background(), nil)
+
+	for i := 0; i < 30; i++ {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"countdown-%d","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]}}}`, i)
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if i == 0 {
+			if got := strings.Join(drain(artifacts), "|"); got != "task.artifact three |task.artifact two |task.artifact one" {
+				t.Errorf("Expected the streamed chunks, got %q", got)
+			}
+		}
+	}
+}
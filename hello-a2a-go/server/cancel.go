@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrTaskCanceled is the cause of a handler's context when a client cancels its task with
+// tasks/cancel; handlers should stop work and return promptly once the context is done
+var ErrTaskCanceled = errors.New("task canceled")
+
+// runningTask is a task whose handler is running
+type runningTask struct {
+	cancel context.CancelCauseFunc
+	// skill is the skill the run's message names, and started when the handler started
+	skill   string
+	started time.Time
+	// done is closed once the handler returns
+	done chan struct{}
+}
+
+// startRun registers the handler run of taskID, returning its context, canceled by
+// cancelRun, and a function to call once the handler returns
+func (s *A2AServer) startRun(ctx context.Context, taskID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	run := &runningTask{cancel: cancel, skill: SkillFromContext(ctx), started: s.clock.Now(), done: make(chan struct{})}
+
+	s.runningMu.Lock()
+	s.running[taskID] = run
+	s.runningMu.Unlock()
+
+	return ctx, func() {
+		s.runningMu.Lock()
+		if s.running[taskID] == run {
+			delete(s.running, taskID)
+		}
+		s.runningMu.Unlock()
+		cancel(nil)
+		close(run.done)
+	}
+}
+
+// cancelRun cancels the running handler of taskID with cause, returning a channel closed once
+// it has returned, or nil when no handler is running for the task
+func (s *A2AServer) cancelRun(taskID string, cause error) <-chan struct{} {
+	s.runningMu.Lock()
+	run := s.running[taskID]
+	s.runningMu.Unlock()
+	if run == nil {
+		return nil
+	}
+	run.cancel(cause)
+	return run.done
+}
+
+// cancelTask cancels the task taskID, waiting for its handler to return if it is running,
+// and returns the task in the canceled state. Tasks that already completed, failed or were
+// rejected cannot be canceled.
+func (s *A2AServer) cancelTask(ctx context.Context, taskID string) (*models.Task, error) {
+	if done := s.cancelRun(taskID, ErrTaskCanceled); done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+	return s.markCanceled(ctx, taskID)
+}
+
+// ForceCancel cancels the task taskID without waiting for its handler to return, for handlers
+// that ignore their context: the task is canceled at once, and the handler's result is stored
+// as canceled whenever it returns
+func (s *A2AServer) ForceCancel(ctx context.Context, taskID string) (*models.Task, error) {
+	s.cancelRun(taskID, ErrTaskCanceled)
+	return s.markCanceled(ctx, taskID)
+}
+
+// markCanceled saves the task taskID as canceled, notifying its webhook, and returns it. It
+// returns a task not cancelable error for tasks in another terminal state.
+func (s *A2AServer) markCanceled(ctx context.Context, taskID string) (*models.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.store.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status.State == models.TaskStateCanceled {
+		// The canceled handler's result is already stored
+		return task, nil
+	}
+	if task.Status.State.IsTerminal() {
+		return nil, models.NewTaskNotCancelableError(taskID, task.Status.State)
+	}
+	task.Status.State = models.TaskStateCanceled
+	if err := s.saveTask(ctx, task); err != nil {
+		return nil, err
+	}
+	s.notify(task.ID, models.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: boolPtr(true)})
+	return task, nil
+}
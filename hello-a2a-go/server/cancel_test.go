@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// blockingHandler signals started and blocks until its context is canceled, recording the cause
+func blockingHandler(started chan<- struct{}, cause chan<- error) TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return task, ctx.Err()
+	}
+}
+
+func TestCancelRunningTask(t *testing.T) {
+	started, cause := make(chan struct{}), make(chan error, 1)
+	server := NewA2AServer(mockAgentCard, blockingHandler(started, cause))
+
+	sent := make(chan models.JSONRPCResponse)
+	go func() {
+		sent <- doRPC(t, server, "message/send", models.MessageSendParams{
+			ID:      "long",
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+		})
+	}()
+	<-started
+
+	response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "long"})
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if response.Error != nil || task.Status.State != models.TaskStateCanceled {
+		t.Errorf("Expected the canceled task, got %+v (%v)", task, response.Error)
+	}
+	if err := <-cause; !errors.Is(err, ErrTaskCanceled) {
+		t.Errorf("Expected the handler's context to be canceled with ErrTaskCanceled, got %v", err)
+	}
+
+	response = <-sent
+	decodeResult(t, response.Result, &task)
+	if response.Error != nil || task.Status.State != models.TaskStateCanceled {
+		t.Errorf("Expected message/send to return the canceled task, got %+v (%v)", task, response.Error)
+	}
+
+	if response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "unknown"}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected an unknown task not to be found, got %+v", response)
+	}
+}
+
+func TestCancelFinishedTask(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "done",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+	})
+
+	response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "done"})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotCancelable) {
+		t.Errorf("Expected a completed task not to be cancelable, got %+v", response)
+	}
+	stored, err := server.store.Get(context.Background(), "done")
+	if err != nil || stored.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the task to stay completed, got %+v (%v)", stored, err)
+	}
+}
+
+func TestCancelStreamingTask(t *testing.T) {
+	started, cause := make(chan struct{}), make(chan error, 1)
+	server := NewA2AServer(mockAgentCard, blockingHandler(started, cause))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "streamed", "")
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	readFrame(t, reader)
+	<-started
+
+	if response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "streamed"}); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	if frame := readFrame(t, reader); !strings.Contains(frame, `"state":"canceled"`) || !strings.Contains(frame, `"final":true`) {
+		t.Errorf("Expected a final canceled event, got %q", frame)
+	}
+
+	stored, err := server.store.Get(context.Background(), "streamed")
+	if err != nil || stored.Status.State != models.TaskStateCanceled {
+		t.Errorf("Expected the stored task to be canceled, got %+v (%v)", stored, err)
+	}
+}
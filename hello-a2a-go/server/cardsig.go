@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"a2a/models"
+)
+
+// JWKSPath is where RegisterRoutes serves the public key verifying the agent card's signature
+// (see WithCardSigningKey)
+const JWKSPath = "/.well-known/jwks.json"
+
+// WithCardSigningKey signs the agent card served at /.well-known/agent-card with key, an RSA,
+// ECDSA or Ed25519 private key, and serves its public key as a JWKS at JWKSPath under the key
+// ID kid. Clients check the signature with client.GetVerifiedAgentCard before trusting the
+// card's capabilities.
+func WithCardSigningKey(key crypto.Signer, kid string) Option {
+	return func(s *A2AServer) {
+		s.cardSigner = &cardSigner{key: key, kid: kid}
+	}
+}
+
+// LoadCardSigningKey reads a PEM private key for WithCardSigningKey from path, in PKCS #8,
+// PKCS #1 (RSA) or SEC 1 (EC) form, failing for keys that cannot sign agent cards
+func LoadCardSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM key in %s", path)
+	}
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key %T", key)
+	}
+	if _, err := models.NewJSONWebKey("", signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// cardSigner signs the served agent card, signing it again only once the card changes
+type cardSigner struct {
+	key crypto.Signer
+	kid string
+
+	mu sync.Mutex
+	// unsigned is the JSON of the card last signed, whose signed JSON is signed
+	unsigned []byte
+	signed   []byte
+}
+
+// sign returns the JSON of card, whose unsigned JSON is unsigned, with a signature added
+func (c *cardSigner) sign(card models.AgentCard, unsigned []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bytes.Equal(unsigned, c.unsigned) {
+		return c.signed, nil
+	}
+	signedCard, err := models.SignAgentCard(card, c.key, c.kid, jwksURL(card.URL))
+	if err != nil {
+		return nil, err
+	}
+	signed, err := json.Marshal(signedCard)
+	if err != nil {
+		return nil, err
+	}
+	c.unsigned, c.signed = unsigned, signed
+	return signed, nil
+}
+
+// jwksURL returns the URL of the JWKS served next to the agent served at cardURL, or "" when
+// cardURL is not absolute
+func jwksURL(cardURL string) string {
+	u, err := url.Parse(cardURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + JWKSPath
+}
+
+// ServeJWKS writes the key set verifying the agent card's signature, for mounting at
+// JWKSPath; it answers 404 Not Found unless the card is signed (see WithCardSigningKey)
+func (s *A2AServer) ServeJWKS(w http.ResponseWriter, r *http.Request) {
+	if s.cardSigner == nil {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := models.NewJSONWebKey(s.cardSigner.kid, s.cardSigner.key.Public())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.JSONWebKeySet{Keys: []models.JSONWebKey{key}})
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestWithCardSigningKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	card := mockAgentCard
+	card.URL = "https://agent.example/a2a"
+	server := NewA2AServer(card, mockTaskHandler, WithCardSigningKey(key, "card-1"))
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	w := get(JWKSPath)
+	var keys models.JSONWebKeySet
+	if err := json.NewDecoder(w.Body).Decode(&keys); err != nil || len(keys.Keys) != 1 || keys.Keys[0].Kid != "card-1" {
+		t.Fatalf("Expected the signing key at %s, got %d %+v (%v)", JWKSPath, w.Code, keys, err)
+	}
+
+	first := get("/.well-known/agent-card")
+	verified, err := models.VerifyAgentCard(first.Body.Bytes(), keys)
+	if err != nil || verified.Name != card.Name {
+		t.Fatalf("Expected the served card to verify, got %v", err)
+	}
+	var header struct {
+		Kid string `json:"kid"`
+		JKU string `json:"jku"`
+	}
+	decodeSegment(verified.Signatures[0].Protected, &header)
+	if header.Kid != "card-1" || header.JKU != "https://agent.example"+JWKSPath {
+		t.Errorf("Expected the key ID and JWKS URL in the header, got %+v", header)
+	}
+
+	// The card is signed once until it changes
+	if second := get("/.well-known/agent-card"); second.Body.String() != first.Body.String() {
+		t.Error("Expected an unchanged card to keep its signature")
+	}
+	server.AddSkill(models.AgentSkill{ID: "added", Name: "Added"}, nil)
+	changed := get("/.well-known/agent-card")
+	if changed.Body.String() == first.Body.String() {
+		t.Error("Expected a changed card to be signed again")
+	}
+	if _, err := models.VerifyAgentCard(changed.Body.Bytes(), keys); err != nil {
+		t.Errorf("Expected the changed card to verify, got %v", err)
+	}
+
+	// Unsigned servers serve no keys
+	mux = http.NewServeMux()
+	NewA2AServer(card, mockTaskHandler).RegisterRoutes(mux)
+	if w := get(JWKSPath); w.Code != http.StatusNotFound {
+		t.Errorf("Expected no JWKS without a signing key, got %d", w.Code)
+	}
+}
+
+func TestLoadCardSigningKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(key)
+	path := filepath.Join(t.TempDir(), "card-key.pem")
+	os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)
+
+	signer, err := LoadCardSigningKey(path)
+	if err != nil || !key.PublicKey.Equal(signer.Public()) {
+		t.Fatalf("Expected the EC key, got %v", err)
+	}
+	os.WriteFile(path, []byte("not a key"), 0o600)
+	if _, err := LoadCardSigningKey(path); err == nil {
+		t.Error("Expected a file without a PEM key to fail")
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// ChaosConfig configures fault injection. Rates are probabilities between 0 and 1; faults are
+// drawn from a random source seeded with Seed, so a sequence of requests sees the same faults
+// on every run.
+type ChaosConfig struct {
+	Seed int64
+	// Latency delays every request, plus a random duration up to LatencyJitter
+	Latency       time.Duration
+	LatencyJitter time.Duration
+	// ErrorRate is the probability a request is answered with a JSON-RPC error drawn from
+	// ErrorCodes (default internal error) without reaching the handler
+	ErrorRate  float64
+	ErrorCodes []models.ErrorCode
+	// UnavailableRate is the probability a request is answered with HTTP 503 and Retry-After
+	UnavailableRate float64
+	// DropStreamRate is the probability a streaming response is cut off, aborting the
+	// connection after DropAfterEvents events
+	DropStreamRate  float64
+	DropAfterEvents int
+	// MalformedEventRate is the probability each streamed event is replaced by invalid JSON
+	MalformedEventRate float64
+	// Clock times injected latency; the default is the server clock, or clock.Real for the
+	// Chaos middleware
+	Clock clock.Clock
+}
+
+// errStreamDropped is returned to the handler when chaos cuts its stream
+var errStreamDropped = errors.New("chaos: stream dropped")
+
+// malformedEvent replaces events chosen for corruption
+const malformedEvent = "{\"jsonrpc\":\"2.0\",\"result\":{\"id\":\n"
+
+// Chaos returns middleware injecting the faults described by config, for exercising client
+// retries, resubscription and circuit breakers in tests
+func Chaos(config ChaosConfig) func(http.Handler) http.Handler {
+	injector := newChaosInjector(config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			injector.serve(w, r, next)
+		})
+	}
+}
+
+// WithChaos injects the faults described by config into every request the server handles
+func WithChaos(config ChaosConfig) Option {
+	return func(s *A2AServer) {
+		s.chaos = newChaosInjector(config)
+	}
+}
+
+// chaosInjector draws faults from a seeded random source
+type chaosInjector struct {
+	config ChaosConfig
+	clock  clock.Clock
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newChaosInjector(config ChaosConfig) *chaosInjector {
+	c := &chaosInjector{config: config, clock: config.Clock, rng: rand.New(rand.NewSource(config.Seed))}
+	if c.clock == nil {
+		c.clock = clock.Real
+	}
+	return c
+}
+
+// chaosPlan holds the faults drawn for one request
+type chaosPlan struct {
+	delay       time.Duration
+	unavailable bool
+	errorCode   *models.ErrorCode
+	dropStream  bool
+	malformed   func() bool
+}
+
+// plan draws the faults for one request
+func (c *chaosInjector) plan() chaosPlan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := chaosPlan{delay: c.config.Latency}
+	if c.config.LatencyJitter > 0 {
+		p.delay += time.Duration(c.rng.Int63n(int64(c.config.LatencyJitter)))
+	}
+	p.unavailable = c.rng.Float64() < c.config.UnavailableRate
+	if c.rng.Float64() < c.config.ErrorRate {
+		code := models.ErrorCodeInternalError
+		if len(c.config.ErrorCodes) > 0 {
+			code = c.config.ErrorCodes[c.rng.Intn(len(c.config.ErrorCodes))]
+		}
+		p.errorCode = &code
+	}
+	p.dropStream = c.rng.Float64() < c.config.DropStreamRate
+	p.malformed = func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.rng.Float64() < c.config.MalformedEventRate
+	}
+	return p
+}
+
+// serve applies the faults drawn for r around next
+func (c *chaosInjector) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	p := c.plan()
+
+	if p.delay > 0 {
+		select {
+		case <-c.clock.After(p.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if p.unavailable {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "chaos: service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if p.errorCode != nil {
+		var req struct {
+			ID interface{} `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: req.ID},
+			},
+			Error: &models.JSONRPCError{Code: int(*p.errorCode), Message: "chaos: injected error"},
+		})
+		return
+	}
+
+	cw := &chaosWriter{ResponseWriter: w, plan: p, dropAfter: c.config.DropAfterEvents}
+	next.ServeHTTP(cw, r)
+	if cw.dropped {
+		// Abort the connection so the client sees the stream end abruptly
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// chaosWriter corrupts or cuts off streamed events
+type chaosWriter struct {
+	http.ResponseWriter
+	plan      chaosPlan
+	dropAfter int
+	events    int
+	dropped   bool
+}
+
+// streaming reports whether the response is an event stream, framed as SSE or JSON lines
+func (w *chaosWriter) streaming() bool {
+	contentType := w.Header().Get("Content-Type")
+	return strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "application/x-ndjson")
+}
+
+func (w *chaosWriter) Write(p []byte) (int, error) {
+	if !w.streaming() {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.dropped {
+		return 0, errStreamDropped
+	}
+	if bytes.HasPrefix(p, []byte(":")) {
+		// SSE comments such as keep-alives are not events
+		return w.ResponseWriter.Write(p)
+	}
+	if w.plan.dropStream && w.events >= w.dropAfter {
+		w.dropped = true
+		return 0, errStreamDropped
+	}
+
+	w.events++
+	if w.plan.malformed() {
+		corrupt := malformedEvent
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			corrupt = "data: " + malformedEvent + "\n"
+		}
+		if _, err := io.WriteString(w.ResponseWriter, corrupt); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher when the underlying writer does
+func (w *chaosWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
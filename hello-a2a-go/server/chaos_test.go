@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// chaosRequest posts a JSON-RPC request to url and returns the status and response lines
+func chaosRequest(t *testing.T, url, method string) (int, []string) {
+	t.Helper()
+
+	body, _ := json.Marshal(models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "chaos"},
+		},
+		Method: method,
+		Params: models.MessageSendParams{
+			ID: "chaos-task",
+			Message: models.Message{
+				Role:      "user",
+				Parts:     []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+				ContextID: "chaos",
+			},
+		},
+	})
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return resp.StatusCode, lines
+}
+
+func TestChaos_Deterministic(t *testing.T) {
+	config := ChaosConfig{
+		Seed:       42,
+		ErrorRate:  0.5,
+		ErrorCodes: []models.ErrorCode{models.ErrorCodeInternalError, models.ErrorCodeTaskNotFound},
+	}
+
+	outcomes := func() []string {
+		fake := clock.NewFake(time.Unix(0, 0))
+		ts := httptest.NewServer(NewA2AServer(mockAgentCard, mockTaskHandler, WithChaos(config), WithClock(fake)))
+		defer ts.Close()
+
+		var results []string
+		for i := 0; i < 10; i++ {
+			_, lines := chaosRequest(t, ts.URL, "message/send")
+			results = append(results, lines[0])
+		}
+		return results
+	}
+
+	first, second := outcomes(), outcomes()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Request %d differs between runs with the same seed", i)
+		}
+		var response models.JSONRPCResponse
+		json.Unmarshal([]byte(first[i]), &response)
+		if response.Error != nil {
+			failures++
+		}
+	}
+	if failures == 0 || failures == 10 {
+		t.Errorf("Expected some but not all requests to fail, got %d failures", failures)
+	}
+}
+
+func TestChaos_Faults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ChaosConfig
+		method string
+		check  func(t *testing.T, status int, lines []string)
+	}{
+		{
+			name:   "unavailable",
+			config: ChaosConfig{UnavailableRate: 1},
+			method: "message/send",
+			check: func(t *testing.T, status int, lines []string) {
+				if status != http.StatusServiceUnavailable {
+					t.Errorf("Expected status 503, got %d", status)
+				}
+			},
+		},
+		{
+			name:   "dropped stream",
+			config: ChaosConfig{DropStreamRate: 1, DropAfterEvents: 1},
+			method: "message/stream",
+			check: func(t *testing.T, status int, lines []string) {
+				if len(lines) != 1 {
+					t.Errorf("Expected the stream to be cut after one event, got %d lines", len(lines))
+				}
+			},
+		},
+		{
+			name:   "malformed events",
+			config: ChaosConfig{MalformedEventRate: 1},
+			method: "message/stream",
+			check: func(t *testing.T, status int, lines []string) {
+				for _, line := range lines {
+					if json.Valid([]byte(line)) {
+						t.Errorf("Expected every event to be malformed, got %s", line)
+					}
+				}
+			},
+		},
+		{
+			name:   "latency",
+			config: ChaosConfig{Latency: 20 * time.Millisecond},
+			method: "message/send",
+			check: func(t *testing.T, status int, lines []string) {
+				if status != http.StatusOK || len(lines) != 1 {
+					t.Errorf("Expected a normal response, got status %d with %d lines", status, len(lines))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(Chaos(tt.config)(NewA2AServer(mockAgentCard, mockTaskHandler)))
+			defer ts.Close()
+
+			start := time.Now()
+			status, lines := chaosRequest(t, ts.URL, tt.method)
+			if elapsed := time.Since(start); elapsed < tt.config.Latency {
+				t.Errorf("Expected at least %v latency, got %v", tt.config.Latency, elapsed)
+			}
+			tt.check(t, status, lines)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"a2a/models"
+)
+
+// DefaultArtifactChunkBytes is the chunk size of an ArtifactWriter created with none
+const DefaultArtifactChunkBytes = 64 << 10
+
+// errArtifactWriterClosed is returned when writing to a closed ArtifactWriter
+var errArtifactWriterClosed = errors.New("artifact writer is closed")
+
+// ArtifactWriter streams a file artifact of the task executing in a context as it is written,
+// in TaskArtifactUpdateEvents each carrying a file part of at most a chunk size of content:
+// every chunk has the artifact's Index, those after the first set Append, and the last, sent by
+// Close, sets LastChunk. client.ArtifactReader reassembles the content. Chunks are emitted with
+// EmitArtifact, so a skill's StreamThrottle may coalesce them, and they are dropped outside
+// message/stream requests; handlers should then also return the artifact in the task.
+type ArtifactWriter struct {
+	ctx       context.Context
+	index     int
+	name      string
+	mimeType  string
+	chunkSize int
+
+	buf    []byte
+	sent   bool
+	closed bool
+}
+
+// NewArtifactWriter returns a writer streaming the artifact at index of the task executing in
+// ctx, a file named name of type mimeType, in chunks of chunkSize bytes, or
+// DefaultArtifactChunkBytes when chunkSize is not positive
+func NewArtifactWriter(ctx context.Context, index int, name, mimeType string, chunkSize int) *ArtifactWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultArtifactChunkBytes
+	}
+	return &ArtifactWriter{ctx: ctx, index: index, name: name, mimeType: mimeType, chunkSize: chunkSize}
+}
+
+// Write implements io.Writer, emitting each chunk as soon as it is full
+func (w *ArtifactWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errArtifactWriterClosed
+	}
+	n := len(p)
+	for len(p) > 0 {
+		take := min(w.chunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:take]...)
+		p = p[take:]
+		if len(w.buf) == w.chunkSize {
+			w.emit(false)
+		}
+	}
+	return n, nil
+}
+
+// Close emits the rest of the content as the last chunk, which is empty when the content
+// filled its chunks exactly
+func (w *ArtifactWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.emit(true)
+	w.closed = true
+	return nil
+}
+
+// emit sends the buffered content as a chunk
+func (w *ArtifactWriter) emit(last bool) {
+	index := w.index
+	artifact := models.Artifact{
+		Parts: []models.Part{models.FilePart{
+			Type:     "file",
+			FileName: w.name,
+			MimeType: w.mimeType,
+			Content:  models.FileContentBytes{Type: "bytes", Bytes: w.buf},
+		}},
+		Index:     &index,
+		Append:    boolPtr(w.sent),
+		LastChunk: boolPtr(last),
+	}
+	if w.name != "" {
+		artifact.Name = &w.name
+	}
+	EmitArtifact(w.ctx, artifact)
+	w.buf = nil
+	w.sent = true
+}
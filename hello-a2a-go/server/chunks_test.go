@@ -0,0 +1,57 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestArtifactWriter(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		w := NewArtifactWriter(ctx, 2, "greeting.txt", "text/plain", 4)
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+		w.Close()
+		if _, err := w.Write([]byte("!")); err == nil {
+			t.Error("Expected writing after Close to fail")
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	ts := httptest.NewServer(NewA2AServer(mockAgentCard, handler))
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "chunked", "")
+	defer resp.Body.Close()
+	var body strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&body)
+
+	var chunks []string
+	for _, data := range sseData(t, body.String()) {
+		var event struct {
+			Result models.TaskArtifactUpdateEvent `json:"result"`
+		}
+		json.Unmarshal([]byte(data), &event)
+		artifact := event.Result.Artifact
+		if len(artifact.Parts) == 0 {
+			continue
+		}
+		file := artifact.Parts[0].(models.FilePart)
+		if *artifact.Index != 2 || *artifact.Name != "greeting.txt" || file.MimeType != "text/plain" {
+			t.Errorf("Expected chunks of artifact 2, got %+v", artifact)
+		}
+		chunk := string(file.Content.(models.FileContentBytes).Bytes)
+		if *artifact.Append != (len(chunks) > 0) || *artifact.LastChunk != (chunk == "rld") {
+			t.Errorf("Expected the bookkeeping of chunk %d, got %+v", len(chunks), artifact)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if strings.Join(chunks, "|") != "hell|o wo|rld" {
+		t.Errorf("Expected bounded chunks, got %q", chunks)
+	}
+}
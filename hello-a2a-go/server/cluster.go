@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"a2a/models"
+)
+
+// InstanceMetadataKey is the task metadata entry naming the server instance that last changed
+// the task (see WithCluster)
+const InstanceMetadataKey = "instanceId"
+
+// LeaderLease is the name of the lease held by the replica running the background jobs
+const LeaderLease = "a2a-leader"
+
+// defaultLeaseTTL is how long leadership lasts without renewal when the config sets no TTL
+const defaultLeaseTTL = 30 * time.Second
+
+// Locker grants named leases, shared by the replicas of an agent, that expire unless renewed.
+// SQLTaskStore and MemoryLocker are Lockers.
+type Locker interface {
+	// AcquireLease grants holder the lease name until now+ttl if the lease is free, expired or
+	// already held by holder, and reports whether holder holds it
+	AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error)
+}
+
+// ClusterConfig configures a server running as one of several replicas sharing a task store
+type ClusterConfig struct {
+	// InstanceID names this replica, such as its Kubernetes pod name; empty means the host name
+	// with a random suffix, so that replicas on one host or without a host name differ
+	InstanceID string
+	// Locker elects the replica that purges expired tasks (see WithRetention); nil leaves every
+	// replica purging them
+	Locker Locker
+	// LeaseTTL is how long leadership lasts unless the leader renews it, which it does every
+	// third of it; zero means 30 seconds
+	LeaseTTL time.Duration
+}
+
+// WithCluster runs the server as one replica of a cluster. Tasks it saves are stamped with its
+// instance ID under the InstanceMetadataKey metadata entry, so operators can tell which replica
+// processed what, and the replicas elect a leader through config.Locker, the only one running
+// the task reaper. Push notifications are queued in memory and retried by the replica running
+// the task, so they need no leader.
+func WithCluster(config ClusterConfig) Option {
+	return func(s *A2AServer) {
+		if config.LeaseTTL <= 0 {
+			config.LeaseTTL = defaultLeaseTTL
+		}
+		if config.InstanceID == "" {
+			config.InstanceID = defaultInstanceID()
+		}
+		s.cluster = &cluster{config: config}
+	}
+}
+
+// defaultInstanceID returns the host name followed by a random suffix
+func defaultInstanceID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "a2a"
+	}
+	return host + "-" + hex.EncodeToString(suffix)
+}
+
+// cluster is the replica state of a server
+type cluster struct {
+	config ClusterConfig
+	// leader is set while this replica holds the leader lease
+	leader atomic.Bool
+}
+
+// ClusterInfo describes the replica a server runs as
+type ClusterInfo struct {
+	InstanceID string `json:"instanceId"`
+	// Leader reports whether the replica runs the background jobs
+	Leader bool `json:"leader"`
+}
+
+// leading reports whether this server runs the background jobs: always when it is not part of
+// a cluster or its replicas elect no leader
+func (s *A2AServer) leading() bool {
+	return s.cluster == nil || s.cluster.config.Locker == nil || s.cluster.leader.Load()
+}
+
+// startElection acquires or renews the leader lease now and every third of its TTL, until the
+// server is closed
+func (s *A2AServer) startElection() {
+	if s.stopped() {
+		return
+	}
+	s.elect(context.Background())
+	s.clock.AfterFunc(s.cluster.config.LeaseTTL/3, s.startElection)
+}
+
+// elect tries to acquire or renew the leader lease, logging changes of leadership. A failure
+// to reach the locker gives leadership up, as another replica may take the lease once it
+// expires.
+func (s *A2AServer) elect(ctx context.Context) {
+	c := s.cluster
+	held, err := c.config.Locker.AcquireLease(ctx, LeaderLease, c.config.InstanceID, s.clock.Now(), c.config.LeaseTTL)
+	if err != nil {
+		s.log(ctx, "").Error("failed to acquire the leader lease", slog.Any("error", err))
+		held = false
+	}
+	if was := c.leader.Swap(held); was != held {
+		s.log(ctx, "").Info("leadership changed", slog.String("instance", c.config.InstanceID), slog.Bool("leader", held))
+	}
+}
+
+// stampInstance records the server's instance ID in the metadata of task
+func (s *A2AServer) stampInstance(task *models.Task) {
+	if s.cluster == nil || s.cluster.config.InstanceID == "" {
+		return
+	}
+	if task.Metadata == nil {
+		task.Metadata = make(map[string]interface{})
+	}
+	task.Metadata[InstanceMetadataKey] = s.cluster.config.InstanceID
+}
+
+// clusterInfo returns the replica the server runs as, or nil outside a cluster
+func (s *A2AServer) clusterInfo() *ClusterInfo {
+	if s.cluster == nil {
+		return nil
+	}
+	return &ClusterInfo{InstanceID: s.cluster.config.InstanceID, Leader: s.leading()}
+}
+
+// MemoryLocker is a Locker for the replicas of one process, such as tests
+type MemoryLocker struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+// memoryLease is a lease granted by a MemoryLocker
+type memoryLease struct {
+	holder  string
+	expires time.Time
+}
+
+// NewMemoryLocker returns a locker with no leases granted
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{leases: make(map[string]memoryLease)}
+}
+
+// AcquireLease implements Locker
+func (l *MemoryLocker) AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease, ok := l.leases[name]; ok && lease.holder != holder && now.Before(lease.expires) {
+		return false, nil
+	}
+	l.leases[name] = memoryLease{holder: holder, expires: now.Add(ttl)}
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// testLocker checks the Locker contract against locker, which must have no leases granted
+func testLocker(t *testing.T, locker Locker) {
+	t.Helper()
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		name   string
+		holder string
+		at     time.Duration
+		want   bool
+	}{
+		{"free", "a", 0, true},
+		{"held", "b", 10 * time.Second, false},
+		{"renewed", "a", 20 * time.Second, true},
+		{"still held", "b", 40 * time.Second, false},
+		{"expired", "b", 50 * time.Second, true},
+		{"taken over", "a", 60 * time.Second, false},
+	}
+	for _, step := range steps {
+		held, err := locker.AcquireLease(ctx, "reaper", step.holder, start.Add(step.at), 30*time.Second)
+		if err != nil {
+			t.Fatalf("%s: failed to acquire lease: %v", step.name, err)
+		}
+		if held != step.want {
+			t.Errorf("%s: expected %s to hold the lease: %v, got %v", step.name, step.holder, step.want, held)
+		}
+	}
+	if held, err := locker.AcquireLease(ctx, "other", "a", start.Add(60*time.Second), time.Minute); err != nil || !held {
+		t.Errorf("Expected leases to be independent, got %v (%v)", held, err)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	testLocker(t, NewMemoryLocker())
+}
+
+func TestWithCluster(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store, locker := NewMemoryTaskStore(), NewMemoryLocker()
+	replica := func(id string) *A2AServer {
+		return NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake), WithTaskStore(store),
+			WithCluster(ClusterConfig{InstanceID: id, Locker: locker}),
+			WithRetention(RetentionPolicy{
+				TTLs:     map[models.TaskState]time.Duration{models.TaskStateCompleted: time.Hour},
+				Interval: time.Minute,
+			}))
+	}
+	a, b := replica("pod-a"), replica("pod-b")
+
+	if info := a.Introspect(context.Background()).Cluster; info == nil || info.InstanceID != "pod-a" || !info.Leader {
+		t.Errorf("Expected the first replica to lead, got %+v", info)
+	}
+	if info := b.Introspect(context.Background()).Cluster; info == nil || info.Leader {
+		t.Errorf("Expected the second replica to follow, got %+v", info)
+	}
+
+	// The task records the replica that processed it
+	var task models.Task
+	decodeResult(t, doRPC(t, b, "tasks/send", models.TaskSendParams{
+		ID:      "stamped",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}},
+	}).Result, &task)
+	if task.Metadata[InstanceMetadataKey] != "pod-b" {
+		t.Errorf("Expected the task to be stamped with pod-b, got %v", task.Metadata)
+	}
+
+	// Only the leader purges the expired task
+	for i := 0; i < 61; i++ {
+		fake.Advance(time.Minute)
+	}
+	listA, _ := a.TaskList(context.Background())
+	listB, _ := b.TaskList(context.Background())
+	if listA.Total != 0 || listA.Purged != 1 || listB.Purged != 0 {
+		t.Errorf("Expected the leader to purge the task, got %+v and %+v", listA, listB)
+	}
+}
+
+func TestWithCluster_Close(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	locker := NewMemoryLocker()
+	replica := func() *A2AServer {
+		return NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake), WithCluster(ClusterConfig{Locker: locker}))
+	}
+	a, b := replica(), replica()
+	infoA, infoB := a.Introspect(context.Background()).Cluster, b.Introspect(context.Background()).Cluster
+	if infoA.InstanceID == infoB.InstanceID || !strings.Contains(infoA.InstanceID, "-") {
+		t.Errorf("Expected distinct default instance IDs, got %q and %q", infoA.InstanceID, infoB.InstanceID)
+	}
+
+	// A closed leader stops renewing its lease, which the other replica takes over once expired
+	a.Close()
+	if a.Introspect(context.Background()).Cluster.Leader {
+		t.Error("Expected the closed replica to give leadership up")
+	}
+	fake.Advance(defaultLeaseTTL + time.Second)
+	if a.Introspect(context.Background()).Cluster.Leader || !b.Introspect(context.Background()).Cluster.Leader {
+		t.Error("Expected the other replica to take the lease over")
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"a2a/models"
+)
+
+// WithCompression compresses responses in the first of encodings, registered content encodings
+// such as "gzip" (see models.RegisterContentEncoding), that the client accepts, including
+// event streams, which are flushed event by event. Requests compressed in any registered
+// encoding are decompressed whether or not it is set, before WithMaxRequestBytes applies.
+func WithCompression(encodings ...string) Option {
+	return func(s *A2AServer) {
+		s.compression = encodings
+	}
+}
+
+// withCompression decompresses the body of requests to next and compresses its responses
+func (s *A2AServer) withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if encoding := r.Header.Get("Content-Encoding"); encoding != "" && r.Body != nil {
+			codec, ok := models.LookupContentEncoding(encoding)
+			if !ok {
+				http.Error(w, "unsupported content encoding "+strconv.Quote(encoding), http.StatusUnsupportedMediaType)
+				return
+			}
+			r.Body = &decodingBody{codec: codec, body: r.Body}
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+		}
+
+		// Ranges are of the uncompressed content, so ranged downloads are served as they are
+		encoding := negotiateEncoding(s.compression, r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		codec, _ := models.LookupContentEncoding(encoding)
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, codec: codec}
+		defer cw.close()
+		w.Header().Add("Vary", "Accept-Encoding")
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the first of encodings that is registered and acceptable per the
+// Accept-Encoding header accept, or "" to send responses uncompressed
+func negotiateEncoding(encodings []string, accept string) string {
+	if len(encodings) == 0 || accept == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	for _, item := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	for _, encoding := range encodings {
+		q, ok := qualities[strings.ToLower(encoding)]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if _, registered := models.LookupContentEncoding(encoding); ok && q > 0 && registered {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// decodingBody decompresses a request body, opening the decompressor on the first read
+type decodingBody struct {
+	codec  models.ContentCodec
+	body   io.ReadCloser
+	reader io.ReadCloser
+	err    error
+}
+
+func (d *decodingBody) Read(p []byte) (int, error) {
+	if d.reader == nil && d.err == nil {
+		d.reader, d.err = d.codec.NewReader(d.body)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.reader.Read(p)
+}
+
+func (d *decodingBody) Close() error {
+	if d.reader != nil {
+		d.reader.Close()
+	}
+	return d.body.Close()
+}
+
+// compressWriter compresses a response, unless its handler already encoded it or it has no
+// body
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	codec    models.ContentCodec
+	// writer compresses to the response once its header is written; nil sends it as it is
+	writer      io.WriteCloser
+	wroteHeader bool
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		status != http.StatusPartialContent && h.Get("Content-Encoding") == "" {
+		if writer, err := w.codec.NewWriter(w.ResponseWriter); err == nil {
+			w.writer = writer
+			h.Set("Content-Encoding", w.encoding)
+			h.Del("Content-Length")
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.writer.Write(p)
+}
+
+// Flush implements http.Flusher, flushing the compressed output written so far
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close ends the compressed output
+func (w *compressWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
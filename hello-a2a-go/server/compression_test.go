@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// gzipBytes compresses data with gzip
+func gzipBytes(data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		encodings []string
+		accept    string
+		want      string
+	}{
+		{encodings: []string{"gzip"}, accept: "gzip, deflate", want: "gzip"},
+		{encodings: []string{"gzip"}, accept: "GZIP;q=0.5", want: "gzip"},
+		{encodings: []string{"gzip"}, accept: "*", want: "gzip"},
+		{encodings: []string{"gzip"}, accept: "gzip;q=0", want: ""},
+		{encodings: []string{"gzip"}, accept: "br", want: ""},
+		{encodings: []string{"zstd", "gzip"}, accept: "zstd, gzip", want: "gzip"},
+		{encodings: nil, accept: "gzip", want: ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.encodings, tt.accept); got != tt.want {
+			t.Errorf("negotiateEncoding(%q, %q) = %q, want %q", tt.encodings, tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestA2AServer_Compression(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithCompression("gzip"), WithMaxRequestBytes(1<<10))
+	send := func(body []byte, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"zipped","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	w := send(gzipBytes(request), "gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Expected a gzip response, got headers %v", w.Header())
+	}
+	body, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to decompress response: %v", err)
+	}
+	var response models.JSONRPCResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil || response.Error != nil {
+		t.Fatalf("Expected the compressed request to succeed, got %+v (%v)", response, err)
+	}
+
+	if w := send([]byte(request), "br"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected an unknown encoding to be rejected, got %d", w.Code)
+	}
+
+	// The request size limit applies to the decompressed body
+	large := strings.Replace(request, "Hello", strings.Repeat("a", 4<<10), 1)
+	w = send(gzipBytes(large), "gzip")
+	body, _ = gzip.NewReader(w.Body)
+	response = models.JSONRPCResponse{}
+	json.NewDecoder(body).Decode(&response)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidRequest) {
+		t.Errorf("Expected the decompressed body to exceed the limit, got %+v", response)
+	}
+}
+
+func TestA2AServer_CompressedStream(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	ts := httptest.NewServer(NewA2AServer(mockAgentCard, handler, WithCompression("gzip")))
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"zipped","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	req, _ := http.NewRequest("POST", ts.URL, strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a gzip stream, got headers %v", resp.Header)
+	}
+
+	// Each event is flushed through the compressor while the task works
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to decompress stream: %v", err)
+	}
+	reader := bufio.NewReader(zr)
+	if first := readFrame(t, reader); !strings.Contains(first, `"working"`) {
+		t.Errorf("Expected the working event, got %q", first)
+	}
+	close(release)
+	rest, _ := io.ReadAll(reader)
+	if !strings.Contains(string(rest), `"completed"`) {
+		t.Errorf("Expected the final event, got %q", rest)
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"a2a/models"
+)
+
+// ContextGetMethod is the JSON-RPC method returning the tasks and messages of a conversation
+const ContextGetMethod = "contexts/get"
+
+// ConversationBundleVersion is the format version of exported conversations
+const ConversationBundleVersion = 1
+
+// maxBundleBytes bounds the conversation bundles ConversationImportHandler reads
+const maxBundleBytes = 64 << 20
+
+// Conversation errors
+var (
+	// ErrConversationNotFound is returned when exporting a context with no tasks
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrTaskExists is returned when an imported task ID is already in use
+	ErrTaskExists = errors.New("task already exists")
+)
+
+// ConversationBundle is the portable history of one conversation, for moving long-lived
+// conversations between deployments
+type ConversationBundle struct {
+	Version    int           `json:"version"`
+	ContextID  string        `json:"contextId"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	Tasks      []BundledTask `json:"tasks"`
+}
+
+// BundledTask is a task with the messages received for it, oldest first
+type BundledTask struct {
+	Task     *models.Task      `json:"task"`
+	Messages []*models.Message `json:"messages,omitempty"`
+}
+
+// ExportConversation bundles every task of the conversation contextID with its messages
+func (s *A2AServer) ExportConversation(ctx context.Context, contextID string) (*ConversationBundle, error) {
+	if contextID == "" {
+		return nil, errors.New("context ID is required")
+	}
+	tasks, err := s.store.ListTasks(ctx, contextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil, ErrConversationNotFound
+	}
+
+	bundle := &ConversationBundle{
+		Version:    ConversationBundleVersion,
+		ContextID:  contextID,
+		ExportedAt: s.clock.Now().UTC(),
+		Tasks:      make([]BundledTask, 0, len(tasks)),
+	}
+	for _, task := range tasks {
+		messages, err := s.store.Messages(ctx, task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read messages of task %s: %w", task.ID, err)
+		}
+		bundle.Tasks = append(bundle.Tasks, BundledTask{Task: task, Messages: messages})
+	}
+	return bundle, nil
+}
+
+// Conversation returns the tasks of the conversation contextID in the order they were created,
+// with their messages, or ErrConversationNotFound when it has none
+func (s *A2AServer) Conversation(ctx context.Context, contextID string) (*models.Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks, err := s.store.ListTasks(ctx, contextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if contextID == "" || len(tasks) == 0 {
+		return nil, ErrConversationNotFound
+	}
+	created := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		statuses, err := s.store.StatusHistory(ctx, task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read statuses of task %s: %w", task.ID, err)
+		}
+		if len(statuses) > 0 {
+			created[task.ID] = statuses[0].Timestamp
+		}
+	}
+	// Timestamps share one format, so they sort as strings; tasks are listed by ID otherwise
+	sort.SliceStable(tasks, func(i, j int) bool { return created[tasks[i].ID] < created[tasks[j].ID] })
+
+	conversation := &models.Conversation{ContextID: contextID, Tasks: make([]models.Task, 0, len(tasks)), Messages: []models.Message{}}
+	for _, task := range tasks {
+		conversation.Tasks = append(conversation.Tasks, *task)
+		conversation.Messages = append(conversation.Messages, task.History...)
+	}
+	return conversation, nil
+}
+
+// handleContextGet handles contexts/get
+func (s *A2AServer) handleContextGet(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	var params models.ContextQueryParams
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+	conversation, err := s.Conversation(r.Context(), params.ContextID)
+	if errors.Is(err, ErrConversationNotFound) {
+		s.sendA2AError(w, req.ID, models.NewA2AError(models.ErrorCodeTaskNotFound, "Context not found"))
+		return
+	}
+	if err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInternalError, err.Error())
+		return
+	}
+	s.sendResponseWithID(w, req.ID, conversation)
+}
+
+// ImportConversation stores the tasks and messages of bundle, keeping their original status
+// timestamps. The import is rejected as a whole if any of its task IDs is already in use.
+func (s *A2AServer) ImportConversation(ctx context.Context, bundle *ConversationBundle) error {
+	if err := validateBundle(bundle); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, bt := range bundle.Tasks {
+		_, err := s.store.Get(ctx, bt.Task.ID)
+		if err == nil {
+			return fmt.Errorf("%w: %s", ErrTaskExists, bt.Task.ID)
+		}
+		if !errors.Is(err, ErrTaskNotFound) {
+			return fmt.Errorf("failed to get task %s: %w", bt.Task.ID, err)
+		}
+	}
+	for _, bt := range bundle.Tasks {
+		if err := s.store.Save(ctx, bt.Task); err != nil {
+			return fmt.Errorf("failed to save task %s: %w", bt.Task.ID, err)
+		}
+		for _, message := range bt.Messages {
+			if err := s.store.AppendMessage(ctx, bt.Task.ID, message); err != nil {
+				return fmt.Errorf("failed to append message to task %s: %w", bt.Task.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateBundle checks that bundle is a supported, self-consistent conversation
+func validateBundle(bundle *ConversationBundle) error {
+	if bundle.Version != ConversationBundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	if bundle.ContextID == "" {
+		return errors.New("bundle has no context ID")
+	}
+	seen := make(map[string]bool)
+	for i, bt := range bundle.Tasks {
+		switch {
+		case bt.Task == nil || bt.Task.ID == "":
+			return fmt.Errorf("bundled task %d has no ID", i)
+		case bt.Task.ContextID != bundle.ContextID:
+			return fmt.Errorf("task %s belongs to context %q, not %q", bt.Task.ID, bt.Task.ContextID, bundle.ContextID)
+		case seen[bt.Task.ID]:
+			return fmt.Errorf("duplicate task %s", bt.Task.ID)
+		}
+		seen[bt.Task.ID] = true
+	}
+	return nil
+}
+
+// ConversationExportHandler serves ExportConversation for the path value contextId, as routed
+// by a pattern such as "GET /admin/conversations/{contextId}", for mounting on admin routes
+func (s *A2AServer) ConversationExportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bundle, err := s.ExportConversation(r.Context(), r.PathValue("contextId"))
+		if errors.Is(err, ErrConversationNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bundle)
+	})
+}
+
+// ConversationImportHandler serves ImportConversation for a bundle of up to 64 MiB posted as the
+// request body, for mounting on admin routes, which must be protected by other means
+func (s *A2AServer) ConversationImportHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var bundle ConversationBundle
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleBytes)).Decode(&bundle)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("Bundle exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid bundle: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := validateBundle(&bundle); err != nil {
+			http.Error(w, fmt.Sprintf("invalid bundle: %v", err), http.StatusBadRequest)
+			return
+		}
+		err = s.ImportConversation(r.Context(), &bundle)
+		if errors.Is(err, ErrTaskExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
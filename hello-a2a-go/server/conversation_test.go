@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestConversationExportImport(t *testing.T) {
+	source := NewA2AServer(mockAgentCard, mockTaskHandler)
+
+	send := func(id, contextID string) models.Task {
+		t.Helper()
+		params := models.MessageSendParams{
+			ID: id,
+			Message: models.Message{
+				Role:      "user",
+				Parts:     []models.Part{models.TextPart{Type: "text", Text: "Hello " + id}},
+				ContextID: contextID,
+			},
+		}
+		response := doRPC(t, source, "message/send", params)
+		if response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+		var task models.Task
+		decodeResult(t, response.Result, &task)
+		return task
+	}
+
+	first := send("c1", "")
+	if first.ContextID == "" {
+		t.Fatal("Expected a new task to start a conversation")
+	}
+	send("c2", first.ContextID)
+	send("other", "")
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /admin/conversations/{contextId}", source.ConversationExportHandler())
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/admin/conversations/"+first.ContextID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body)
+	}
+	exported := w.Body.Bytes()
+
+	var bundle ConversationBundle
+	if err := json.Unmarshal(exported, &bundle); err != nil {
+		t.Fatalf("Failed to decode bundle: %v", err)
+	}
+	if len(bundle.Tasks) != 2 || bundle.Tasks[0].Task.ID != "c1" || bundle.Tasks[1].Task.ID != "c2" {
+		t.Fatalf("Expected tasks c1 and c2, got %+v", bundle.Tasks)
+	}
+	if len(bundle.Tasks[1].Messages) != 1 {
+		t.Errorf("Expected the messages of c2 to be bundled, got %d", len(bundle.Tasks[1].Messages))
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/admin/conversations/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown conversation, got %d", w.Code)
+	}
+
+	target := NewA2AServer(mockAgentCard, mockTaskHandler)
+	importer := target.ConversationImportHandler()
+	w = httptest.NewRecorder()
+	importer.ServeHTTP(w, httptest.NewRequest("POST", "/admin/conversations", bytes.NewReader(exported)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body)
+	}
+
+	imported, err := target.store.Get(context.Background(), "c2")
+	if err != nil {
+		t.Fatalf("Expected c2 to be imported: %v", err)
+	}
+	if imported.ContextID != first.ContextID || imported.Status.Timestamp != bundle.Tasks[1].Task.Status.Timestamp {
+		t.Errorf("Expected the task to keep its context and timestamp, got %+v", imported)
+	}
+
+	// Follow-up tasks naming the context continue the migrated conversation
+	params := models.MessageSendParams{
+		ID:      "c3",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Again"}}, ContextID: first.ContextID},
+	}
+	if response := doRPC(t, target, "message/send", params); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	if tasks, _ := target.store.ListTasks(context.Background(), first.ContextID); len(tasks) != 3 {
+		t.Errorf("Expected 3 tasks in the migrated conversation, got %d", len(tasks))
+	}
+
+	w = httptest.NewRecorder()
+	importer.ServeHTTP(w, httptest.NewRequest("POST", "/admin/conversations", bytes.NewReader(exported)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 when re-importing, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	oversized := `{"contextId":"` + strings.Repeat("x", maxBundleBytes) + `"}`
+	importer.ServeHTTP(w, httptest.NewRequest("POST", "/admin/conversations", strings.NewReader(oversized)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for an oversized bundle, got %d", w.Code)
+	}
+}
+
+func TestImportConversation_Validation(t *testing.T) {
+	task := func(id, contextID string) BundledTask {
+		return BundledTask{Task: &models.Task{ID: id, ContextID: contextID}}
+	}
+	tests := []struct {
+		name   string
+		bundle ConversationBundle
+	}{
+		{"unsupported version", ConversationBundle{Version: 2, ContextID: "ctx"}},
+		{"missing context", ConversationBundle{Version: ConversationBundleVersion}},
+		{"task without ID", ConversationBundle{Version: ConversationBundleVersion, ContextID: "ctx", Tasks: []BundledTask{task("", "ctx")}}},
+		{"foreign task", ConversationBundle{Version: ConversationBundleVersion, ContextID: "ctx", Tasks: []BundledTask{task("a", "other")}}},
+		{"duplicate task", ConversationBundle{Version: ConversationBundleVersion, ContextID: "ctx", Tasks: []BundledTask{task("a", "ctx"), task("a", "ctx")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewA2AServer(mockAgentCard, mockTaskHandler)
+			err := server.ImportConversation(context.Background(), &tt.bundle)
+			if err == nil || errors.Is(err, ErrTaskExists) {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+			if count, _ := server.store.Count(context.Background()); count != 0 {
+				t.Errorf("Expected nothing to be imported, got %d tasks", count)
+			}
+		})
+	}
+}
+
+func TestA2AServer_ContextGet(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake))
+	send := func(contextID string) models.Task {
+		t.Helper()
+		response := doRPC(t, server, "message/send", models.MessageSendParams{Message: models.Message{Role: "user", ContextID: contextID}})
+		var task models.Task
+		decodeResult(t, response.Result, &task)
+		fake.Advance(time.Second)
+		return task
+	}
+
+	first := send("")
+	if first.ID == "" || first.ContextID == "" {
+		t.Fatalf("Expected a new task and conversation, got %+v", first)
+	}
+	second, _ := send(first.ContextID), send("")
+
+	response := doRPC(t, server, ContextGetMethod, models.ContextQueryParams{ContextID: first.ContextID})
+	var conversation models.Conversation
+	decodeResult(t, response.Result, &conversation)
+	if len(conversation.Tasks) != 2 || conversation.Tasks[0].ID != first.ID || conversation.Tasks[1].ID != second.ID {
+		t.Fatalf("Expected the conversation's tasks in creation order, got %+v", conversation.Tasks)
+	}
+	if len(conversation.Messages) != len(first.History)+len(second.History) || conversation.Messages[0].TaskID != first.ID {
+		t.Errorf("Expected the tasks' messages, got %+v", conversation.Messages)
+	}
+
+	if response := doRPC(t, server, ContextGetMethod, models.ContextQueryParams{ContextID: "unknown"}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected an unknown context to be reported, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2a/models"
+)
+
+// DefaultCORSHeaders are the request headers browsers may send to an agent when CORSConfig
+// allows no others: those of JSON-RPC requests, authentication, signing, extensions and
+// stream resumption
+var DefaultCORSHeaders = []string{
+	"Content-Type", "Accept", "Authorization", "X-API-Key", "Last-Event-ID",
+	models.HeaderRequestID, models.HeaderExtensions, models.HeaderNonce, models.HeaderTimestamp, models.HeaderSignature,
+}
+
+// CORSConfig sets which browser origins may call the agent (see WithCORS)
+type CORSConfig struct {
+	// AllowedOrigins are the origins, such as https://app.example.com, whose scripts may call
+	// the agent; "*" allows any
+	AllowedOrigins []string
+	// AllowedMethods are the methods of the requests allowed; empty means GET, POST and PUT
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed; empty means DefaultCORSHeaders, and "*"
+	// allows any
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts may read; empty means X-Request-ID,
+	// X-Task-ID and Retry-After
+	ExposedHeaders []string
+	// AllowCredentials lets requests from the origins listed by name carry cookies and TLS
+	// client certificates, answering with the caller's origin rather than "*". Origins allowed
+	// only by "*" never get credentials.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request; zero leaves it
+	// to the browser
+	MaxAge time.Duration
+}
+
+// WithCORS lets the browser origins of config call the endpoints mounted by RegisterRoutes,
+// answering their preflight OPTIONS requests before any authentication middleware, so that web
+// UIs served elsewhere can use the agent. Without it, only streamed responses allow any origin;
+// see WithoutCORS.
+func WithCORS(config CORSConfig) Option {
+	return func(s *A2AServer) {
+		if len(config.AllowedMethods) == 0 {
+			config.AllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut}
+		}
+		if len(config.AllowedHeaders) == 0 {
+			config.AllowedHeaders = DefaultCORSHeaders
+		}
+		if len(config.ExposedHeaders) == 0 {
+			config.ExposedHeaders = []string{models.HeaderRequestID, models.HeaderTaskID, "Retry-After"}
+		}
+		s.cors, s.noCORS = &config, false
+	}
+}
+
+// WithoutCORS sends no CORS headers at all, not even the any-origin header of streamed
+// responses, so that browsers only let pages of the agent's own origin call it
+func WithoutCORS() Option {
+	return func(s *A2AServer) {
+		s.cors, s.noCORS = nil, true
+	}
+}
+
+// withCORS wraps h with the server's CORS policy, if it has one
+func (s *A2AServer) withCORS(h http.Handler) http.Handler {
+	if s.cors == nil {
+		return h
+	}
+	config := s.cors
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !config.allows(origin) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		config.allowOrigin(w, origin)
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// servePreflight answers a preflight request, allowing it when it comes from an allowed origin
+// and asks for an allowed method
+func (s *A2AServer) servePreflight(w http.ResponseWriter, r *http.Request) {
+	config := s.cors
+	origin, method := r.Header.Get("Origin"), r.Header.Get("Access-Control-Request-Method")
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+	if origin == "" || method == "" || !config.allows(origin) || !slices.Contains(config.AllowedMethods, method) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	config.allowOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+	headers := strings.Join(config.AllowedHeaders, ", ")
+	if slices.Contains(config.AllowedHeaders, "*") {
+		headers = r.Header.Get("Access-Control-Request-Headers")
+	}
+	if headers != "" {
+		w.Header().Set("Access-Control-Allow-Headers", headers)
+	}
+	if config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge/time.Second)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowOrigin sets the headers of a response letting scripts of origin read it. Only origins
+// listed by name are echoed and given credentials, so that "*" never lets any site make
+// credentialed calls.
+func (c *CORSConfig) allowOrigin(w http.ResponseWriter, origin string) {
+	if !c.listed(origin) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allows reports whether scripts of origin may call the agent
+func (c *CORSConfig) allows(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, "*") || c.listed(origin)
+}
+
+// listed reports whether origin is one of the allowed origins by name
+func (c *CORSConfig) listed(origin string) bool {
+	return slices.ContainsFunc(c.AllowedOrigins, func(allowed string) bool {
+		return strings.EqualFold(allowed, origin)
+	})
+}
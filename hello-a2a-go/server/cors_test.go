@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testOrigin = "https://app.example.com"
+	streamBody = `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"streamed","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+)
+
+// corsMux returns a mux with the routes of a server with opts, behind middleware refusing
+// every request
+func corsMux(opts ...Option) *http.ServeMux {
+	deny := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		})
+	}
+	mux := http.NewServeMux()
+	NewA2AServer(mockAgentCard, mockTaskHandler, opts...).RegisterRoutes(mux, deny)
+	return mux
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	mux := corsMux(WithCORS(CORSConfig{AllowedOrigins: []string{testOrigin}, MaxAge: 10 * time.Minute}))
+	tests := []struct {
+		name       string
+		path       string
+		origin     string
+		method     string
+		wantStatus int
+	}{
+		{"allowed", "/a2a", testOrigin, "POST", http.StatusNoContent},
+		{"task", "/v1/tasks/t1", testOrigin, "GET", http.StatusNoContent},
+		{"other origin", "/a2a", "https://evil.example.com", "POST", http.StatusForbidden},
+		{"other method", "/a2a", testOrigin, "DELETE", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+		r.Header.Set("Origin", tt.origin)
+		r.Header.Set("Access-Control-Request-Method", tt.method)
+		r.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+			continue
+		}
+		if tt.wantStatus != http.StatusNoContent {
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("%s: expected no allowed origin, got %q", tt.name, got)
+			}
+			continue
+		}
+		h := w.Header()
+		if h.Get("Access-Control-Allow-Origin") != testOrigin || !strings.Contains(h.Get("Access-Control-Allow-Headers"), "Authorization") ||
+			!strings.Contains(h.Get("Access-Control-Allow-Methods"), tt.method) || h.Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("%s: expected the preflight to be allowed, got %v", tt.name, h)
+		}
+	}
+}
+
+func TestCORS_Requests(t *testing.T) {
+	mux := corsMux(WithCORS(CORSConfig{AllowedOrigins: []string{testOrigin}, AllowCredentials: true}))
+
+	// The agent card is public; the refusal of the middleware is readable by the page too
+	for _, tt := range []struct {
+		method, path string
+		wantStatus   int
+	}{
+		{"GET", "/.well-known/agent-card", http.StatusOK},
+		{"POST", "/a2a", http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		r.Header.Set("Origin", testOrigin)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		h := w.Header()
+		if w.Code != tt.wantStatus || h.Get("Access-Control-Allow-Origin") != testOrigin || h.Get("Access-Control-Allow-Credentials") != "true" ||
+			!strings.Contains(h.Get("Access-Control-Expose-Headers"), "X-Request-ID") {
+			t.Errorf("%s %s: expected status %d readable by the origin, got %d %v", tt.method, tt.path, tt.wantStatus, w.Code, h)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/.well-known/agent-card", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("Expected another origin not to be allowed, got %q", got)
+	}
+}
+
+func TestCORS_AnyOriginWithCredentials(t *testing.T) {
+	mux := corsMux(WithCORS(CORSConfig{AllowedOrigins: []string{"*", testOrigin}, AllowCredentials: true}))
+	for _, tt := range []struct {
+		origin, wantOrigin, wantCredentials string
+	}{
+		{testOrigin, testOrigin, "true"},
+		{"https://evil.example.com", "*", ""},
+	} {
+		r := httptest.NewRequest("GET", "/.well-known/agent-card", nil)
+		r.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		h := w.Header()
+		if h.Get("Access-Control-Allow-Origin") != tt.wantOrigin || h.Get("Access-Control-Allow-Credentials") != tt.wantCredentials {
+			t.Errorf("%s: expected origin %q and credentials %q, got %v", tt.origin, tt.wantOrigin, tt.wantCredentials, h)
+		}
+	}
+}
+
+func TestCORS_Streams(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"default", nil, "*"},
+		{"policy", []Option{WithCORS(CORSConfig{AllowedOrigins: []string{"*"}})}, "*"},
+		{"disabled", []Option{WithoutCORS()}, ""},
+	}
+	for _, tt := range tests {
+		server := NewA2AServer(mockAgentCard, mockTaskHandler, tt.opts...)
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+		r := httptest.NewRequest("POST", "/a2a/stream", strings.NewReader(streamBody))
+		r.Header.Set("Accept", "text/event-stream")
+		r.Header.Set("Origin", testOrigin)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+			t.Errorf("%s: expected allowed origin %q, got %q", tt.name, tt.want, got)
+		}
+		if w.Header().Get("X-Accel-Buffering") != "no" || w.Header().Get("Cache-Control") != "no-cache" {
+			t.Errorf("%s: expected the stream not to be buffered or cached, got %v", tt.name, w.Header())
+		}
+	}
+}
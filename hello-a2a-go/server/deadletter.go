@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// defaultDeadLetterLimit bounds the dead-letter list when the policy sets no limit
+const defaultDeadLetterLimit = 1000
+
+// RetryableError marks a handler error as transient, so that the task is run again under the
+// server's TaskRetryPolicy instead of failing at once
+type RetryableError struct {
+	Err error
+	// RetryAfter is how long to wait before the next attempt; zero uses the policy's backoff
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// Retryable marks err as transient; it returns nil for a nil err
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsRetryable reports whether err, or an error it wraps, is a RetryableError
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	return errors.As(err, &retryable)
+}
+
+// TaskRetryPolicy sets how handlers returning a RetryableError are run again
+type TaskRetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first; values below 1 mean 1
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt; zero means one second
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts; zero leaves it uncapped
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each attempt; values below 1 mean 2
+	Multiplier float64
+	// DeadLetterLimit bounds the dead-letter list, dropping the oldest entries first; zero
+	// means 1000
+	DeadLetterLimit int
+}
+
+// WithTaskRetry runs handlers returning a RetryableError again, up to policy.MaxAttempts times
+// with exponential backoff. Each attempt starts from the task as it was before the first, but
+// artifacts already streamed by a failed attempt are not withdrawn. Tasks whose handler still
+// fails, retryable or not, are recorded in a dead-letter list, returned by DeadLetters and
+// served with the retry of its tasks by the admin API (see DeadLettersHandler).
+func WithTaskRetry(policy TaskRetryPolicy) Option {
+	return func(s *A2AServer) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = time.Second
+		}
+		if policy.Multiplier < 1 {
+			policy.Multiplier = 2
+		}
+		if policy.DeadLetterLimit <= 0 {
+			policy.DeadLetterLimit = defaultDeadLetterLimit
+		}
+		s.retry = &taskRetry{policy: policy}
+	}
+}
+
+// DeadLetter is a task whose handler failed for good
+type DeadLetter struct {
+	TaskID    string `json:"taskId"`
+	ContextID string `json:"contextId,omitempty"`
+	Skill     string `json:"skill,omitempty"`
+	// Attempts is the number of times the handler ran
+	Attempts int `json:"attempts"`
+	// Error is the error the last attempt failed with, as reported to the client; the details
+	// of internal errors are only logged
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failedAt"`
+	// Params are the parameters the task was sent with, sent again when it is retried
+	Params models.TaskSendParams `json:"params"`
+}
+
+// taskRetry is the task retry policy of a server and its dead-letter list, oldest first
+type taskRetry struct {
+	policy TaskRetryPolicy
+
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+// backoff returns the wait after attempt, which failed asking to be retried after retryAfter
+func (t *taskRetry) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	wait := float64(t.policy.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		wait *= t.policy.Multiplier
+	}
+	if t.policy.MaxBackoff > 0 && wait > float64(t.policy.MaxBackoff) {
+		return t.policy.MaxBackoff
+	}
+	return time.Duration(wait)
+}
+
+// add records letter, replacing an earlier letter for its task
+func (t *taskRetry) add(letter DeadLetter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.letters = slices.DeleteFunc(t.letters, func(l DeadLetter) bool { return l.TaskID == letter.TaskID })
+	t.letters = append(t.letters, letter)
+	if over := len(t.letters) - t.policy.DeadLetterLimit; over > 0 {
+		t.letters = slices.Delete(t.letters, 0, over)
+	}
+}
+
+// take removes and returns the letter for taskID
+func (t *taskRetry) take(taskID string) (DeadLetter, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := slices.IndexFunc(t.letters, func(l DeadLetter) bool { return l.TaskID == taskID })
+	if i < 0 {
+		return DeadLetter{}, false
+	}
+	letter := t.letters[i]
+	t.letters = slices.Delete(t.letters, i, i+1)
+	return letter, true
+}
+
+// list returns the letters, oldest first
+func (t *taskRetry) list() []DeadLetter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]DeadLetter{}, t.letters...)
+}
+
+// retryHandler runs invoke on task, and again on a copy of task as it was before the first
+// attempt for as long as it fails with a RetryableError and the retry policy allows. It returns
+// the last attempt's result and error with the number of attempts made.
+func (s *A2AServer) retryHandler(ctx context.Context, task *models.Task, invoke func(*models.Task) (*models.Task, error)) (*models.Task, int, error) {
+	if s.retry == nil {
+		result, err := invoke(task)
+		return result, 1, err
+	}
+	initial := cloneTask(task)
+	for attempt := 1; ; attempt++ {
+		result, err := invoke(task)
+		var retryable *RetryableError
+		if !errors.As(err, &retryable) || attempt >= s.retry.policy.MaxAttempts || ctx.Err() != nil {
+			return result, attempt, err
+		}
+		wait := s.retry.backoff(attempt, retryable.RetryAfter)
+		s.log(ctx, task.ID).Warn("retrying task", slog.Int("attempt", attempt), slog.Duration("backoff", wait), slog.Any("error", err))
+		select {
+		case <-s.clock.After(wait):
+		case <-ctx.Done():
+			return result, attempt, err
+		}
+		task = cloneTask(initial)
+	}
+}
+
+// cloneTask copies task deeply enough for a handler to change the copy without changing task
+func cloneTask(task *models.Task) *models.Task {
+	clone := *task
+	clone.Artifacts = slices.Clone(task.Artifacts)
+	clone.History = slices.Clone(task.History)
+	clone.Metadata = maps.Clone(task.Metadata)
+	if task.Status.Message != nil {
+		message := *task.Status.Message
+		clone.Status.Message = &message
+	}
+	return &clone
+}
+
+// deadLetter records that the handler of task, sent with params, failed for good with err
+func (s *A2AServer) deadLetter(ctx context.Context, params models.TaskSendParams, task *models.Task, attempts int, err error) {
+	skillID, _ := params.Metadata[SkillMetadataKey].(string)
+	s.retry.add(DeadLetter{
+		TaskID:    task.ID,
+		ContextID: task.ContextID,
+		Skill:     skillID,
+		Attempts:  attempts,
+		Error:     handlerError(err).Message,
+		FailedAt:  s.clock.Now().UTC(),
+		Params:    params,
+	})
+	s.log(ctx, task.ID).Error("task dead-lettered", slog.Int("attempts", attempts), slog.Any("error", err))
+}
+
+// DeadLetters returns the tasks whose handler failed for good, oldest first; it is empty
+// without WithTaskRetry
+func (s *A2AServer) DeadLetters() []DeadLetter {
+	if s.retry == nil {
+		return []DeadLetter{}
+	}
+	return s.retry.list()
+}
+
+// errTaskRetryDisabled is returned when retrying a task on a server without WithTaskRetry
+var errTaskRetryDisabled = errors.New("task retry is not enabled")
+
+// retryDeadLetter removes the task taskID from the dead-letter list and runs it again with the
+// parameters it failed with, queued as message/send would be, returning the task once its
+// handler finished, whether it failed again or not. It reports ErrTaskNotFound when the task is
+// not dead-lettered.
+func (s *A2AServer) retryDeadLetter(r *http.Request, taskID string) (*models.Task, error) {
+	if s.retry == nil {
+		return nil, errTaskRetryDisabled
+	}
+	letter, ok := s.retry.take(taskID)
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	params := letter.Params
+	handler, err := s.resolveHandler(params.Metadata)
+	if err != nil {
+		s.retry.add(letter)
+		return nil, err
+	}
+
+	s.log(r.Context(), letter.TaskID).Info("retrying dead-lettered task")
+	done := make(chan struct{})
+	// The task outlives the admin's connection
+	hr := r.WithContext(context.WithoutCancel(r.Context()))
+	if !s.enqueueTask(hr.Context(), &params, func(submitted bool) {
+		defer close(done)
+		s.runQueuedTask(hr, params, handler, submitted)
+	}) {
+		s.retry.add(letter)
+		return nil, errServerBusy()
+	}
+	select {
+	case <-done:
+	case <-r.Context().Done():
+		return nil, context.Cause(r.Context())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.store.Get(r.Context(), letter.TaskID)
+}
+
+// DeadLettersHandler serves the dead-letter list for mounting on admin routes, which must be
+// protected by other means, as letters carry the parameters callers sent; the admin API serves
+// it at the same paths:
+//
+//	GET  /admin/dead-letters             list the dead-lettered tasks, oldest first
+//	POST /admin/dead-letters/{id}/retry  run a task again, answering with the task once it ends
+func (s *A2AServer) DeadLettersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			writeJSON(w, s.DeadLetters())
+			return
+		}
+		task, err := s.retryDeadLetter(r, r.PathValue("id"))
+		var a2aErr *models.A2AError
+		switch {
+		case errors.Is(err, ErrTaskNotFound):
+			http.Error(w, "Task is not dead-lettered", http.StatusNotFound)
+		case errors.Is(err, errTaskRetryDisabled):
+			http.Error(w, "Task retry is not enabled", http.StatusNotFound)
+		case errors.As(err, &a2aErr) && a2aErr.Code == models.ErrorCodeServerBusy:
+			http.Error(w, a2aErr.Message, http.StatusServiceUnavailable)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			writeJSON(w, task)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestTaskRetry_RetriesRetryableErrors(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var attempts atomic.Int32
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Artifacts = append(task.Artifacts, models.Artifact{Parts: []models.Part{models.NewTextPart("partial")}})
+		if attempts.Add(1) < 3 {
+			return nil, Retryable(errors.New("upstream unavailable"))
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithClock(fake),
+		WithTaskRetry(TaskRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second}))
+
+	sent := sendAsync(t, server, "flaky", "Hello")
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(time.Second) // Not yet the doubled backoff
+	if attempts.Load() != 2 {
+		t.Fatalf("Expected the third attempt to wait for 2s, got %d attempts", attempts.Load())
+	}
+	fake.Advance(time.Second)
+
+	response := <-sent
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if task.Status.State != models.TaskStateCompleted || len(task.Artifacts) != 1 {
+		t.Errorf("Expected a completed task with the last attempt's artifact only, got %s with %d artifacts", task.Status.State, len(task.Artifacts))
+	}
+	if letters := server.DeadLetters(); len(letters) != 0 {
+		t.Errorf("Expected no dead letters, got %+v", letters)
+	}
+}
+
+func TestTaskRetry_DeadLettersAndRedrives(t *testing.T) {
+	var healthy atomic.Bool
+	var attempts atomic.Int32
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		attempts.Add(1)
+		switch {
+		case healthy.Load():
+			task.Status.State = models.TaskStateCompleted
+			return task, nil
+		case message.Text() == "retryable":
+			return nil, &RetryableError{Err: errors.New("rate limited"), RetryAfter: time.Millisecond}
+		}
+		return nil, errors.New("bad input")
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithTaskRetry(TaskRetryPolicy{MaxAttempts: 2}),
+		WithAdmin(AdminConfig{Token: testAdminToken}))
+	send := func(id, text string) models.JSONRPCResponse {
+		return doRPC(t, server, "message/send", models.MessageSendParams{
+			ID:       id,
+			Message:  models.Message{Role: "user", Parts: []models.Part{models.NewTextPart(text)}},
+			Metadata: map[string]interface{}{SkillMetadataKey: "test-skill"},
+		})
+	}
+
+	// Errors not marked retryable fail at once
+	if response := send("broken", "plain"); response.Error == nil {
+		t.Fatal("Expected the handler error")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts.Load())
+	}
+	// Retryable errors fail once the attempts are exhausted, honoring RetryAfter
+	if response := send("exhausted", "retryable"); response.Error == nil {
+		t.Fatal("Expected the handler error")
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Expected two more attempts, got %d in total", attempts.Load())
+	}
+
+	var letters []DeadLetter
+	adminRequest(t, server, http.MethodGet, "/admin/dead-letters", &letters)
+	if len(letters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %+v", letters)
+	}
+	// The details of internal errors are only logged
+	if got := letters[0]; got.TaskID != "broken" || got.Attempts != 1 || got.Error != "Internal error" || got.Skill != "test-skill" || got.Params.Message.Text() != "plain" {
+		t.Errorf("Unexpected dead letter %+v", got)
+	}
+	if got := letters[1]; got.TaskID != "exhausted" || got.Attempts != 2 || got.Error != "Internal error" {
+		t.Errorf("Unexpected dead letter %+v", got)
+	}
+
+	healthy.Store(true)
+	var task models.Task
+	if w := adminRequest(t, server, http.MethodPost, "/admin/dead-letters/broken/retry", &task); w.Code != http.StatusOK {
+		t.Fatalf("Expected the retry to succeed, got %d: %s", w.Code, w.Body)
+	}
+	if task.ID != "broken" || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the re-driven task to complete, got %s %s", task.ID, task.Status.State)
+	}
+	if letters := server.DeadLetters(); len(letters) != 1 || letters[0].TaskID != "exhausted" {
+		t.Errorf("Expected only the other task left, got %+v", letters)
+	}
+
+	if w := adminRequest(t, server, http.MethodPost, "/admin/dead-letters/broken/retry", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a task no longer dead-lettered, got %d", w.Code)
+	}
+}
+
+func TestTaskRetry_AdminOnly(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockErrorTaskHandler, WithTaskRetry(TaskRetryPolicy{MaxAttempts: 1}),
+		WithAdmin(AdminConfig{Token: testAdminToken}))
+	doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "failed",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}},
+	})
+
+	// Letters carry what other callers sent, so JSON-RPC clients can neither list nor re-run them
+	for _, method := range []string{"tasks/deadLetters", "tasks/retry"} {
+		response := doRPC(t, server, method, models.TaskIDParams{ID: "failed"})
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeMethodNotFound) {
+			t.Errorf("Expected %s not to be found, got %+v", method, response)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters", nil)
+	w := httptest.NewRecorder()
+	server.AdminHandler().ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the admin token to be required, got %d", w.Code)
+	}
+	if letters := server.DeadLetters(); len(letters) != 1 {
+		t.Errorf("Expected the task to stay dead-lettered, got %+v", letters)
+	}
+}
+
+func TestTaskRetry_Disabled(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockErrorTaskHandler)
+	doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "failed",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}},
+	})
+	if letters := server.DeadLetters(); len(letters) != 0 {
+		t.Errorf("Expected no dead letters without WithTaskRetry, got %+v", letters)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/dead-letters/failed/retry", nil)
+	req.SetPathValue("id", "failed")
+	w := httptest.NewRecorder()
+	server.DeadLettersHandler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without WithTaskRetry, got %d", w.Code)
+	}
+}
+
+func TestTaskRetryPolicy_Backoff(t *testing.T) {
+	retry := &taskRetry{policy: TaskRetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if got := retry.backoff(attempt, 0); got != want {
+			t.Errorf("Expected %v after attempt %d, got %v", want, attempt, got)
+		}
+	}
+	if got := retry.backoff(1, time.Minute); got != time.Minute {
+		t.Errorf("Expected RetryAfter to override the backoff, got %v", got)
+	}
+	if !IsRetryable(Retryable(errors.New("x"))) || IsRetryable(errors.New("x")) || Retryable(nil) != nil {
+		t.Error("Unexpected retryable classification")
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	"a2a/models"
+)
+
+// EventSink publishes the progress of a running task to the clients streaming it
+type EventSink interface {
+	// Status publishes an intermediate status, such as working with a progress message
+	Status(status models.TaskStatus)
+	// Artifact publishes an artifact chunk (see EmitArtifact)
+	Artifact(artifact models.Artifact)
+}
+
+// StreamingTaskHandler is a task handler that publishes status updates and artifact chunks to
+// sink as it produces them, e.g. token by token from a model's stream mode. The returned task
+// is persisted and sent as the final status update, as with TaskHandler.
+type StreamingTaskHandler func(ctx context.Context, task *models.Task, message *models.Message, sink EventSink) (*models.Task, error)
+
+// Streaming adapts handler to a TaskHandler for NewA2AServer, AddSkill or WithSkill. Its events
+// are fanned out to the task's stream subscribers and push notification webhook; without a
+// stream, status updates only reach the webhook and artifact chunks are dropped, so handlers
+// should also return their artifacts in the task.
+func Streaming(handler StreamingTaskHandler) TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		return handler(ctx, task, message, contextSink{ctx})
+	}
+}
+
+// EmitStatus publishes status as an intermediate update of the task executing in ctx, after
+// any artifact chunks emitted before it. It is a no-op outside task handlers.
+func EmitStatus(ctx context.Context, status models.TaskStatus) {
+	if emitter, ok := ctx.Value(artifactEmitterKey{}).(*artifactEmitter); ok {
+		emitter.status(status)
+	}
+}
+
+// contextSink is the EventSink of the task executing in a context
+type contextSink struct {
+	ctx context.Context
+}
+
+func (s contextSink) Status(status models.TaskStatus) {
+	EmitStatus(s.ctx, status)
+}
+
+func (s contextSink) Artifact(artifact models.Artifact) {
+	EmitArtifact(s.ctx, artifact)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// countdown is a streaming handler reporting progress and streaming its output word by word
+func countdown(ctx context.Context, task *models.Task, message *models.Message, sink EventSink) (*models.Task, error) {
+	sink.Status(models.TaskStatus{State: models.TaskStateWorking})
+	words := []string{"three ", "two ", "one"}
+	for i, word := range words {
+		sink.Artifact(textChunk(0, word, i > 0, i == len(words)-1))
+	}
+	task.Status.State = models.TaskStateCompleted
+	return task, nil
+}
+
+func TestStreamingHandler(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, Streaming(countdown))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"countdown","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]}}}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+		var response struct {
+			Result struct {
+				Status   *models.TaskStatus `json:"status"`
+				Final    bool               `json:"final"`
+				Artifact *models.Artifact   `json:"artifact"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Failed to decode event %q: %v", line, err)
+		}
+		switch result := response.Result; {
+		case result.Artifact != nil:
+			got = append(got, "artifact:"+eventText(models.TaskArtifactUpdateEvent{Artifact: *result.Artifact}))
+		case result.Status != nil:
+			got = append(got, fmt.Sprintf("status:%s:%t", result.Status.State, result.Final))
+		}
+	}
+
+	want := []string{
+		"status:working:false",
+		"status:working:false",
+		"artifact:three ",
+		"artifact:two ",
+		"artifact:one",
+		"status:completed:true",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected events %q, got %q", want, got)
+	}
+}
+
+func TestStreamingHandler_PushWithoutStream(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, Streaming(countdown), WithPushNotifications(http.DefaultClient))
+	webhook, events := startWebhook(t, "")
+
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "countdown",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Go"}}},
+		Config:  &models.MessageSendConfiguration{PushNotifications: &models.PushNotificationConfig{URL: webhook.URL}},
+	})
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	// Artifact chunks are dropped without a stream; the status updates reach the webhook
+	for _, want := range []string{"working", "completed"} {
+		event := nextWebhookEvent(t, events)
+		if state := event.Body["status"].(map[string]interface{})["state"]; state != want {
+			t.Errorf("Expected a %s status update, got %+v", want, event.Body)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"a2a/models"
+)
+
+// extensionsContextKey is the context key for the extensions activated for a request
+type extensionsContextKey struct{}
+
+// ActivatedExtensions returns the URIs of the protocol extensions activated for the request or
+// task being handled in ctx: those requested in its X-A2A-Extensions header that the agent card
+// declares, in the order requested
+func ActivatedExtensions(ctx context.Context) []string {
+	activated, _ := ctx.Value(extensionsContextKey{}).([]string)
+	return activated
+}
+
+// ExtensionActive reports whether the extension uri is activated for the request or task being
+// handled in ctx
+func ExtensionActive(ctx context.Context, uri string) bool {
+	return slices.Contains(ActivatedExtensions(ctx), uri)
+}
+
+// requestedExtensions returns the extension URIs of the X-A2A-Extensions headers of r, which
+// may each list several separated by commas
+func requestedExtensions(r *http.Request) []string {
+	var uris []string
+	for _, value := range r.Header.Values(models.HeaderExtensions) {
+		for _, uri := range strings.Split(value, ",") {
+			if uri = strings.TrimSpace(uri); uri != "" && !slices.Contains(uris, uri) {
+				uris = append(uris, uri)
+			}
+		}
+	}
+	return uris
+}
+
+// negotiateExtensions activates the extensions requested by r that the agent card declares,
+// returning r with them in its context, and echoes them in the X-A2A-Extensions response header
+func (s *A2AServer) negotiateExtensions(w http.ResponseWriter, r *http.Request) *http.Request {
+	declared := s.AgentCard().Capabilities.Extensions
+	var activated []string
+	for _, uri := range requestedExtensions(r) {
+		if slices.ContainsFunc(declared, func(extension models.AgentExtension) bool { return extension.URI == uri }) {
+			activated = append(activated, uri)
+		}
+	}
+	if len(activated) == 0 {
+		return r
+	}
+	w.Header().Set(models.HeaderExtensions, strings.Join(activated, ", "))
+	return r.WithContext(context.WithValue(r.Context(), extensionsContextKey{}, activated))
+}
+
+// missingExtension returns the first extension the agent card requires that is not activated
+// in ctx, or "" when every required extension is
+func (s *A2AServer) missingExtension(ctx context.Context) string {
+	for _, extension := range s.AgentCard().Capabilities.Extensions {
+		if extension.Required && !ExtensionActive(ctx, extension.URI) {
+			return extension.URI
+		}
+	}
+	return ""
+}
+
+// requireExtensions answers calls that do not activate every extension the agent card
+// requires with an invalid request error, reporting whether the call may proceed
+func (s *A2AServer) requireExtensions(w http.ResponseWriter, r *http.Request, id interface{}) bool {
+	uri := s.missingExtension(r.Context())
+	if uri == "" {
+		return true
+	}
+	s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, fmt.Sprintf("Extension %s is required; request it in the %s header", uri, models.HeaderExtensions))
+	return false
+}
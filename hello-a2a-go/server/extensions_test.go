@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+const (
+	tracingExtension = "https://example.com/ext/tracing/v1"
+	billingExtension = "https://example.com/ext/billing/v1"
+)
+
+// postExtensions posts a message/send call requesting extensions in the X-A2A-Extensions header
+func postExtensions(server *A2AServer, extensions ...string) *httptest.ResponseRecorder {
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"ext","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	req := httptest.NewRequest(http.MethodPost, "/a2a", strings.NewReader(body))
+	for _, value := range extensions {
+		req.Header.Add(models.HeaderExtensions, value)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestExtensions_Negotiation(t *testing.T) {
+	card := mockAgentCard
+	card.Capabilities.Extensions = []models.AgentExtension{{URI: tracingExtension}, {URI: billingExtension}}
+	var activated []string
+	var tracing bool
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		activated, tracing = ActivatedExtensions(ctx), ExtensionActive(ctx, tracingExtension)
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(card, handler)
+
+	// Undeclared extensions are ignored; headers may repeat and list several URIs
+	w := postExtensions(server, "https://example.com/ext/unknown, "+billingExtension, tracingExtension+","+billingExtension)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if strings.Join(activated, " ") != billingExtension+" "+tracingExtension || !tracing {
+		t.Errorf("Expected the declared extensions activated in request order, got %v", activated)
+	}
+	if got := w.Header().Get(models.HeaderExtensions); got != billingExtension+", "+tracingExtension {
+		t.Errorf("Expected the activated extensions echoed, got %q", got)
+	}
+
+	w = postExtensions(server)
+	if activated != nil || tracing || w.Header().Get(models.HeaderExtensions) != "" {
+		t.Errorf("Expected no extensions without the header, got %v and %q", activated, w.Header().Get(models.HeaderExtensions))
+	}
+}
+
+func TestExtensions_Required(t *testing.T) {
+	card := mockAgentCard
+	card.Capabilities.Extensions = []models.AgentExtension{{URI: tracingExtension}, {URI: billingExtension, Required: true}}
+	server := NewA2AServer(card, mockTaskHandler)
+
+	var response models.JSONRPCResponse
+	json.NewDecoder(postExtensions(server, tracingExtension).Body).Decode(&response)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidRequest) || !strings.Contains(response.Error.Message, billingExtension) {
+		t.Errorf("Expected an error naming the required extension, got %+v", response.Error)
+	}
+
+	response = models.JSONRPCResponse{}
+	json.NewDecoder(postExtensions(server, billingExtension).Body).Decode(&response)
+	if response.Error != nil {
+		t.Errorf("Expected the call to succeed with the required extension, got %v", response.Error)
+	}
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrFetchDenied is returned for file URIs the fetch policy does not allow
+var ErrFetchDenied = errors.New("file URI not allowed")
+
+// ErrFetchTooLarge is returned for file URIs whose content exceeds the fetch policy's MaxBytes
+var ErrFetchTooLarge = errors.New("fetched file too large")
+
+const (
+	// defaultFetchMaxBytes bounds fetched files when neither the policy nor WithMaxFileBytes does
+	defaultFetchMaxBytes = 10 << 20
+	// defaultFetchTimeout bounds fetching a message's files when the policy sets no timeout
+	defaultFetchTimeout = 30 * time.Second
+)
+
+// Fetcher fetches the content of file URIs of one scheme, such as s3
+type Fetcher interface {
+	// Fetch opens the content of uri, returning it with its MIME type, or "" when unknown
+	Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, string, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher
+type FetcherFunc func(ctx context.Context, uri *url.URL) (io.ReadCloser, string, error)
+
+// Fetch implements Fetcher
+func (f FetcherFunc) Fetch(ctx context.Context, uri *url.URL) (io.ReadCloser, string, error) {
+	return f(ctx, uri)
+}
+
+// FetchPolicy sets which file URIs the server fetches for handlers and how
+type FetchPolicy struct {
+	// AllowedHosts lists the hostnames http and https URIs may name; "*.example.com" matches
+	// the subdomains of example.com. Redirects must stay on allowed hosts. Empty allows none.
+	AllowedHosts []string
+	// AllowedDirs lists the directories file URIs may read below; empty allows none
+	AllowedDirs []string
+	// Fetchers fetches URIs of further schemes by scheme, e.g. "s3"; they are trusted to apply
+	// their own access rules
+	Fetchers map[string]Fetcher
+	// MaxBytes bounds each fetched file; zero uses WithMaxFileBytes, or else 10 MiB
+	MaxBytes int64
+	// Timeout bounds fetching the files of a message; zero means 30 seconds
+	Timeout time.Duration
+	// HTTPClient fetches http and https URIs; the default is a client without a timeout of its
+	// own
+	HTTPClient *http.Client
+}
+
+// WithURIFetching resolves the file parts of incoming messages that reference their content by
+// URI, handing handlers the message with the content inline; the task history keeps the URIs.
+// Files of the server's own file store are read from it directly. URIs the policy does not
+// allow, failed fetches and files larger than its MaxBytes fail the task with an invalid
+// parameters error before its handler runs.
+func WithURIFetching(policy FetchPolicy) Option {
+	return func(s *A2AServer) {
+		if policy.Timeout <= 0 {
+			policy.Timeout = defaultFetchTimeout
+		}
+		client := http.Client{}
+		if policy.HTTPClient != nil {
+			client = *policy.HTTPClient
+		}
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !policy.allowsHost(req.URL.Hostname()) {
+				return fmt.Errorf("%w: redirect to %s", ErrFetchDenied, req.URL.Host)
+			}
+			return nil
+		}
+		policy.HTTPClient = &client
+		s.fetch = &policy
+	}
+}
+
+// allowsHost reports whether the policy allows fetching from host
+func (p *FetchPolicy) allowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return host != "" && slices.ContainsFunc(p.AllowedHosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			return strings.HasSuffix(host, suffix) && strings.HasPrefix(suffix, ".")
+		}
+		return host == allowed
+	})
+}
+
+// allowsPath reports whether the policy allows reading the file at path, following symlinks
+func (p *FetchPolicy) allowsPath(path string) bool {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(p.AllowedDirs, func(dir string) bool {
+		dir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return false
+		}
+		rel, err := filepath.Rel(dir, resolved)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	})
+}
+
+// fetchFiles returns message, sent to the task with taskID, with the content of its URI file
+// parts fetched inline, leaving message itself unchanged, or an invalid parameters error
+func (s *A2AServer) fetchFiles(ctx context.Context, taskID string, message models.Message) (models.Message, error) {
+	if s.fetch == nil {
+		return message, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.fetch.Timeout)
+	defer cancel()
+	parts := slices.Clone(message.Parts)
+	for i, part := range parts {
+		filePart, ok := part.(models.FilePart)
+		if !ok {
+			continue
+		}
+		content, ok := filePart.Content.(models.FileContentURI)
+		if !ok {
+			continue
+		}
+		data, mimeType, err := s.fetchURI(ctx, taskID, content.URI)
+		if err != nil {
+			return message, models.NewA2AError(models.ErrorCodeInvalidParams, fmt.Sprintf("failed to fetch %s: %v", content.URI, err))
+		}
+		if filePart.MimeType == "" {
+			filePart.MimeType = mimeType
+		}
+		filePart.Content = models.FileContentBytes{Type: "bytes", Bytes: data}
+		parts[i] = filePart
+	}
+	message.Parts = parts
+	return message, nil
+}
+
+// fetchURI reads the content of the file at rawURI, for the task with taskID, within the
+// policy's limits
+func (s *A2AServer) fetchURI(ctx context.Context, taskID, rawURI string) ([]byte, string, error) {
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, "", err
+	}
+	body, mimeType, err := s.openURI(ctx, taskID, uri)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	limit := s.fetch.MaxBytes
+	if limit <= 0 {
+		limit = s.limits.MaxFileBytes
+	}
+	if limit <= 0 {
+		limit = defaultFetchMaxBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > limit {
+		return nil, "", fmt.Errorf("%w: more than %d bytes", ErrFetchTooLarge, limit)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	// Drop parameters such as "; charset=utf-8", which are not part of the media type
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return data, strings.TrimSpace(mimeType), nil
+}
+
+// openURI opens the content of uri by its scheme. Files of the server's file store are only
+// opened for the task they belong to, so that a message cannot read another task's uploads.
+func (s *A2AServer) openURI(ctx context.Context, taskID string, uri *url.URL) (io.ReadCloser, string, error) {
+	if fileTaskID, fileID, ok := s.storedFile(uri); ok {
+		if fileTaskID != taskID {
+			return nil, "", fmt.Errorf("%w: file of another task", ErrFetchDenied)
+		}
+		file, info, err := s.files.Open(ctx, fileTaskID, fileID)
+		if err != nil {
+			return nil, "", err
+		}
+		return file, info.MimeType, nil
+	}
+
+	switch scheme := strings.ToLower(uri.Scheme); scheme {
+	case "http", "https":
+		if !s.fetch.allowsHost(uri.Hostname()) {
+			return nil, "", fmt.Errorf("%w: host %s", ErrFetchDenied, uri.Hostname())
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+		if err != nil {
+			return nil, "", err
+		}
+		resp, err := s.fetch.HTTPClient.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	case "file":
+		path := filepath.Clean(filepath.FromSlash(uri.Path))
+		if (uri.Host != "" && uri.Host != "localhost") || !filepath.IsAbs(path) || !s.fetch.allowsPath(path) {
+			return nil, "", fmt.Errorf("%w: %s", ErrFetchDenied, uri.String())
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, "", err
+		}
+		return file, mime.TypeByExtension(filepath.Ext(path)), nil
+	default:
+		fetcher, ok := s.fetch.Fetchers[scheme]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: scheme %q", ErrFetchDenied, uri.Scheme)
+		}
+		return fetcher.Fetch(ctx, uri)
+	}
+}
+
+// storedFile returns the task and file IDs of uri when it is the URL of a file of the server's
+// file store (see fileURL)
+func (s *A2AServer) storedFile(uri *url.URL) (string, string, bool) {
+	if s.files == nil {
+		return "", "", false
+	}
+	base, err := url.Parse(s.serverURL("/"))
+	if err != nil || !strings.EqualFold(uri.Scheme, base.Scheme) || !strings.EqualFold(uri.Host, base.Host) {
+		return "", "", false
+	}
+	rest, ok := strings.CutPrefix(uri.EscapedPath(), "/v1/tasks/")
+	if !ok {
+		return "", "", false
+	}
+	escapedTaskID, fileID, ok := strings.Cut(rest, "/files/")
+	if !ok || fileID == "" || strings.Contains(fileID, "/") {
+		return "", "", false
+	}
+	taskID, err := url.PathUnescape(escapedTaskID)
+	if err != nil {
+		return "", "", false
+	}
+	return taskID, fileID, true
+}
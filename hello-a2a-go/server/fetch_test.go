@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// fetchedFiles is a task handler recording the inline content of the message's files
+type fetchedFiles struct {
+	files []models.FilePart
+}
+
+func (f *fetchedFiles) handle(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	f.files = message.Files()
+	task.Status.State = models.TaskStateCompleted
+	return task, nil
+}
+
+// sendFile sends a message with a file part referencing uri and returns the response
+func sendFile(t *testing.T, server *A2AServer, id, uri string) models.JSONRPCResponse {
+	t.Helper()
+	return doRPC(t, server, "message/send", models.MessageSendParams{
+		ID: id,
+		Message: models.Message{Role: "user", Parts: []models.Part{
+			models.FilePart{Type: "file", FileName: "input", Content: models.FileContentURI{Type: "uri", URI: uri}},
+		}},
+	})
+}
+
+func TestURIFetching_HTTP(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/notes.txt":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, "hello")
+		case "/large":
+			w.Write(bytes.Repeat([]byte("x"), 64))
+		case "/escape":
+			http.Redirect(w, r, "http://metadata.internal/secrets", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer origin.Close()
+
+	handler := &fetchedFiles{}
+	server := NewA2AServer(mockAgentCard, handler.handle, WithURIFetching(FetchPolicy{
+		AllowedHosts: []string{"127.0.0.1"},
+		MaxBytes:     32,
+	}))
+
+	response := sendFile(t, server, "fetched", origin.URL+"/notes.txt")
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	if len(handler.files) != 1 || handler.files[0].MimeType != "text/plain" {
+		t.Fatalf("Expected the handler to receive the typed file, got %+v", handler.files)
+	}
+	if content, ok := handler.files[0].Content.(models.FileContentBytes); !ok || string(content.Bytes) != "hello" {
+		t.Errorf("Expected the fetched content inline, got %+v", handler.files[0].Content)
+	}
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if _, ok := task.History[0].Parts[0].(models.FilePart).Content.(models.FileContentURI); !ok {
+		t.Errorf("Expected the history to keep the URI, got %+v", task.History[0].Parts[0])
+	}
+
+	for name, uri := range map[string]string{
+		"unlisted host":       "http://localhost/notes.txt",
+		"redirect off-list":   origin.URL + "/escape",
+		"too large":           origin.URL + "/large",
+		"missing":             origin.URL + "/missing",
+		"unsupported scheme":  "ftp://127.0.0.1/notes.txt",
+		"file without a dir":  "file:///etc/passwd",
+		"unparseable address": "http://[::1",
+	} {
+		handler.files = nil
+		response := sendFile(t, server, "denied", uri)
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+			t.Errorf("%s: expected invalid params, got %v", name, response.Error)
+		}
+		if handler.files != nil {
+			t.Errorf("%s: expected the handler not to run", name)
+		}
+	}
+}
+
+func TestURIFetching_FilesAndSchemes(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "inputs")
+	os.Mkdir(allowed, 0o700)
+	os.WriteFile(filepath.Join(allowed, "data.json"), []byte(`{"a":1}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600)
+	os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(allowed, "link.txt"))
+
+	store, err := NewDirFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := store.Put(context.Background(), "fetched", FileInfo{Name: "scan.png", MimeType: "image/png"}, strings.NewReader("png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.Put(context.Background(), "other", FileInfo{Name: "secret.txt"}, strings.NewReader("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	card := mockAgentCard
+	card.URL = "http://agent.example/a2a"
+	handler := &fetchedFiles{}
+	server := NewA2AServer(card, handler.handle, WithFileStore(store, 0), WithURIFetching(FetchPolicy{
+		AllowedDirs: []string{allowed},
+		Fetchers: map[string]Fetcher{
+			"s3": FetcherFunc(func(ctx context.Context, uri *url.URL) (io.ReadCloser, string, error) {
+				return io.NopCloser(strings.NewReader(uri.Host + uri.Path)), "text/plain", nil
+			}),
+		},
+	}))
+
+	for uri, want := range map[string]string{
+		"file://" + filepath.ToSlash(filepath.Join(allowed, "data.json")): `{"a":1}`,
+		"s3://bucket/key.txt":              "bucket/key.txt",
+		server.fileURL("fetched", info.ID): "png",
+	} {
+		if response := sendFile(t, server, "fetched", uri); response.Error != nil {
+			t.Errorf("%s: expected no error, got %v", uri, response.Error)
+			continue
+		}
+		if content := handler.files[0].Content.(models.FileContentBytes); string(content.Bytes) != want {
+			t.Errorf("%s: expected %q, got %q", uri, want, content.Bytes)
+		}
+	}
+	if handler.files[0].MimeType == "" {
+		t.Error("Expected a MIME type for the fetched file")
+	}
+
+	for _, uri := range []string{
+		"file://" + filepath.ToSlash(filepath.Join(dir, "secret.txt")),
+		"file://" + filepath.ToSlash(allowed) + "/../secret.txt",
+		"file://" + filepath.ToSlash(filepath.Join(allowed, "link.txt")),
+		// Uploads are only read by the task they belong to
+		server.fileURL("other", other.ID),
+	} {
+		if response := sendFile(t, server, "denied", uri); response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+			t.Errorf("%s: expected invalid params, got %v", uri, response.Error)
+		}
+	}
+}
+
+func TestFetchPolicy_AllowsHost(t *testing.T) {
+	policy := &FetchPolicy{AllowedHosts: []string{"files.example.com", "*.cdn.example"}}
+	for host, want := range map[string]bool{
+		"files.example.com":  true,
+		"FILES.example.com.": true,
+		"a.cdn.example":      true,
+		"a.b.cdn.example":    true,
+		"cdn.example":        false,
+		"evilcdn.example":    false,
+		"example.com":        false,
+		"":                   false,
+	} {
+		if got := policy.allowsHost(host); got != want {
+			t.Errorf("allowsHost(%q) = %v, expected %v", host, got, want)
+		}
+	}
+}
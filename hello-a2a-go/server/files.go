@@ -0,0 +1,299 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrFileNotFound is returned by a FileStore for unknown files
+var ErrFileNotFound = errors.New("file not found")
+
+// FileInfo describes a stored file
+type FileInfo struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	MimeType string    `json:"mimeType"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+}
+
+// FileStore keeps the files of tasks, streaming them in and out so large files need not fit in
+// memory. Implementations must be safe for concurrent use.
+type FileStore interface {
+	// Put stores the contents of r as a new file of task taskID, described by info, and returns
+	// info with the file's ID and size
+	Put(ctx context.Context, taskID string, info FileInfo, r io.Reader) (FileInfo, error)
+	// Open returns the contents of a file of task taskID, or ErrFileNotFound
+	Open(ctx context.Context, taskID, fileID string) (io.ReadSeekCloser, FileInfo, error)
+}
+
+// WithFileStore serves task files from store at /v1/tasks/{id}/files (see RegisterRoutes).
+// Clients upload files there to reference them by URI, and file artifacts with more than
+// inlineLimit bytes of inline content are moved there and replaced by their URI; a zero
+// inlineLimit keeps artifacts inline.
+func WithFileStore(store FileStore, inlineLimit int) Option {
+	return func(s *A2AServer) {
+		s.files = store
+		s.inlineLimit = inlineLimit
+	}
+}
+
+// WithMaxFileBytes limits the size of uploaded files; zero means unlimited
+func WithMaxFileBytes(n int64) Option {
+	return func(s *A2AServer) {
+		s.limits.MaxFileBytes = n
+	}
+}
+
+// DirFileStore is a FileStore keeping each task's files in a subdirectory of a directory
+type DirFileStore struct {
+	dir string
+}
+
+// NewDirFileStore creates a file store in dir, creating the directory if needed
+func NewDirFileStore(dir string) (*DirFileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create file store: %w", err)
+	}
+	return &DirFileStore{dir: dir}, nil
+}
+
+// path returns the path of a file of taskID; task IDs are hex encoded so that any ID is a
+// safe directory name
+func (d *DirFileStore) path(taskID, fileID string) string {
+	return filepath.Join(d.dir, hex.EncodeToString([]byte(taskID)), fileID)
+}
+
+// Put implements FileStore
+func (d *DirFileStore) Put(ctx context.Context, taskID string, info FileInfo, r io.Reader) (FileInfo, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	info.ID = hex.EncodeToString(id)
+	path := d.path(taskID, info.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return FileInfo{}, fmt.Errorf("failed to create task directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to create file: %w", err)
+	}
+	info.Size, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return FileInfo{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	meta, _ := json.Marshal(info)
+	if err := os.WriteFile(path+".json", meta, 0o600); err != nil {
+		os.Remove(path)
+		return FileInfo{}, fmt.Errorf("failed to write file info: %w", err)
+	}
+	return info, nil
+}
+
+// Open implements FileStore
+func (d *DirFileStore) Open(ctx context.Context, taskID, fileID string) (io.ReadSeekCloser, FileInfo, error) {
+	// File IDs are hex, which also keeps them from escaping the task directory
+	if _, err := hex.DecodeString(fileID); err != nil || fileID == "" {
+		return nil, FileInfo{}, ErrFileNotFound
+	}
+	path := d.path(taskID, fileID)
+
+	meta, err := os.ReadFile(path + ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, FileInfo{}, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, FileInfo{}, fmt.Errorf("failed to read file info: %w", err)
+	}
+	var info FileInfo
+	if err := json.Unmarshal(meta, &info); err != nil {
+		return nil, FileInfo{}, fmt.Errorf("failed to decode file info: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, FileInfo{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, info, nil
+}
+
+// fileURL returns the URL a file of taskID is downloaded from, on the host of the agent card's URL
+func (s *A2AServer) fileURL(taskID, fileID string) string {
+	return s.serverURL("/v1/tasks/" + url.PathEscape(taskID) + "/files/" + fileID)
+}
+
+// serverURL returns the URL of path, which may carry a query, on the host of the agent card's URL
+func (s *A2AServer) serverURL(path string) string {
+	s.skillsMu.RLock()
+	base, err := url.Parse(s.agentCard.URL)
+	s.skillsMu.RUnlock()
+
+	ref, refErr := url.Parse(path)
+	if err != nil || refErr != nil {
+		return path
+	}
+	base.Path, base.RawPath, base.RawQuery, base.Fragment = ref.Path, ref.RawPath, ref.RawQuery, ""
+	return base.String()
+}
+
+// offloadFiles moves the inline content of task's file artifacts larger than the inline limit to
+// the artifact store, or else the file store, replacing it with the content's URL
+func (s *A2AServer) offloadFiles(ctx context.Context, task *models.Task) error {
+	limit := s.inlineLimit
+	if s.blobs != nil {
+		limit = s.blobInlineLimit
+	} else if s.files == nil {
+		return nil
+	}
+	if limit <= 0 {
+		return nil
+	}
+	for i := range task.Artifacts {
+		for j, part := range task.Artifacts[i].Parts {
+			filePart, ok := part.(models.FilePart)
+			if !ok {
+				continue
+			}
+			content, ok := filePart.Content.(models.FileContentBytes)
+			if !ok || len(content.Bytes) <= limit {
+				continue
+			}
+			if s.blobs != nil {
+				offloaded, err := s.offloadArtifact(ctx, filePart, content.Bytes)
+				if err != nil {
+					return err
+				}
+				task.Artifacts[i].Parts[j] = offloaded
+				continue
+			}
+
+			info, err := s.files.Put(ctx, task.ID, FileInfo{Name: filePart.FileName, MimeType: filePart.MimeType, Created: s.clock.Now().UTC()}, bytes.NewReader(content.Bytes))
+			if err != nil {
+				return fmt.Errorf("failed to offload artifact file: %w", err)
+			}
+			filePart.Content = models.FileContentURI{Type: "uri", URI: s.fileURL(task.ID, info.ID)}
+			task.Artifacts[i].Parts[j] = filePart
+		}
+	}
+	return nil
+}
+
+// serveFiles uploads a file of the task named by the id path parameter on POST and downloads
+// the file named by the file path parameter on GET
+func (s *A2AServer) serveFiles(w http.ResponseWriter, r *http.Request) {
+	if s.files == nil {
+		http.Error(w, "File storage is not enabled", http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodPost {
+		s.uploadFile(w, r)
+		return
+	}
+
+	file, info, err := s.files.Open(r.Context(), r.PathValue("id"), r.PathValue("file"))
+	if errors.Is(err, ErrFileNotFound) {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	setDownloadHeaders(w, info.MimeType, info.Name)
+	// ServeContent answers range and conditional requests, so large downloads can resume
+	http.ServeContent(w, r, info.Name, info.Created, file)
+}
+
+// downloadTypes are the MIME types stored content is served as; others, which a browser could
+// run as a page or script of the server's origin such as text/html or image/svg+xml, are
+// served as application/octet-stream
+var downloadTypes = []string{
+	"text/plain", "text/csv", "application/json", "application/pdf",
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"audio/mpeg", "audio/wav", "audio/ogg", "video/mp4", "video/webm",
+}
+
+// setDownloadHeaders sets the headers of a response serving stored content of mimeType, named
+// name when not empty. Content uploaded by clients is always served as an attachment the
+// browser must not sniff, so that it cannot be rendered in the server's origin.
+func setDownloadHeaders(w http.ResponseWriter, mimeType, name string) {
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err != nil || !slices.Contains(downloadTypes, mediaType) {
+		mimeType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	disposition := "attachment"
+	if name != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	}
+	w.Header().Set("Content-Disposition", disposition)
+}
+
+// uploadFile streams the request body into the file store and answers with a FilePart
+// referencing the stored file by URI. The file is named by the name query parameter and typed
+// by the Content-Type header, and must belong to an existing task.
+func (s *A2AServer) uploadFile(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("id")
+	s.mu.RLock()
+	_, err := s.store.Get(r.Context(), taskID)
+	s.mu.RUnlock()
+	if errors.Is(err, ErrTaskNotFound) {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body := io.Reader(r.Body)
+	if s.limits.MaxFileBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.limits.MaxFileBytes)
+	}
+	mimeType := r.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	info, err := s.files.Put(r.Context(), taskID, FileInfo{Name: r.URL.Query().Get("name"), MimeType: mimeType, Created: s.clock.Now().UTC()}, body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, fmt.Sprintf("File exceeds %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.FilePart{
+		Type:     "file",
+		FileName: info.Name,
+		MimeType: info.MimeType,
+		Content:  models.FileContentURI{Type: "uri", URI: s.fileURL(taskID, info.ID)},
+	})
+}
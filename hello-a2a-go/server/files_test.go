@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// reportHandler completes tasks with a large file artifact and a small one
+func reportHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	task.Artifacts = []models.Artifact{{Parts: []models.Part{
+		models.FilePart{Type: "file", FileName: "report.csv", MimeType: "text/csv", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte(strings.Repeat("a,b\n", 16))}},
+		models.FilePart{Type: "file", FileName: "summary.txt", MimeType: "text/plain", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte("ok")}},
+	}}}
+	task.Status.State = models.TaskStateCompleted
+	return task, nil
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewDirFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := mockAgentCard
+	card.URL = "http://agent.example/a2a"
+	server := NewA2AServer(card, reportHandler, WithFileStore(store, 16), WithMaxFileBytes(1024))
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	do := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	path := func(uri string) string { return strings.TrimPrefix(uri, "http://agent.example") }
+
+	// Large inline artifacts are replaced by the URL of the stored file
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "report",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Report"}}},
+	})
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	parts := task.Artifacts[0].Parts
+	uri, ok := parts[0].(models.FilePart).Content.(models.FileContentURI)
+	if !ok || !strings.HasPrefix(uri.URI, "http://agent.example/v1/tasks/report/files/") {
+		t.Fatalf("Expected the large artifact to be offloaded, got %+v", parts[0])
+	}
+	if _, ok := parts[1].(models.FilePart).Content.(models.FileContentBytes); !ok {
+		t.Errorf("Expected the small artifact to stay inline, got %+v", parts[1])
+	}
+
+	w := do(httptest.NewRequest("GET", path(uri.URI), nil))
+	if w.Code != http.StatusOK || w.Body.String() != strings.Repeat("a,b\n", 16) {
+		t.Errorf("Expected the artifact contents, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/csv" || w.Header().Get("Content-Disposition") != `attachment; filename=report.csv` ||
+		w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected the file's type and name, got %v", w.Header())
+	}
+
+	// Downloads resume with range requests
+	r := httptest.NewRequest("GET", path(uri.URI), nil)
+	r.Header.Set("Range", "bytes=60-")
+	if w := do(r); w.Code != http.StatusPartialContent || w.Body.String() != "a,b\n" {
+		t.Errorf("Expected the requested range, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Uploaded files are referenced by URI
+	r = httptest.NewRequest("POST", "/v1/tasks/report/files?name=notes.txt", strings.NewReader("notes"))
+	r.Header.Set("Content-Type", "text/plain")
+	w = do(r)
+	var uploaded models.FilePart
+	if err := json.NewDecoder(w.Body).Decode(&uploaded); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("Expected the uploaded file, got %d (%v)", w.Code, err)
+	}
+	if uploaded.FileName != "notes.txt" || uploaded.MimeType != "text/plain" {
+		t.Errorf("Expected the file's name and type, got %+v", uploaded)
+	}
+	if w := do(httptest.NewRequest("GET", path(uploaded.Content.(models.FileContentURI).URI), nil)); w.Body.String() != "notes" {
+		t.Errorf("Expected the uploaded contents, got %q", w.Body.String())
+	}
+
+	// Types a browser could render in the server's origin are downloaded as bytes
+	r = httptest.NewRequest("POST", "/v1/tasks/report/files", strings.NewReader("<script>alert(1)</script>"))
+	r.Header.Set("Content-Type", "text/html")
+	w = do(r)
+	if err := json.NewDecoder(w.Body).Decode(&uploaded); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("Expected the uploaded file, got %d (%v)", w.Code, err)
+	}
+	w = do(httptest.NewRequest("GET", path(uploaded.Content.(models.FileContentURI).URI), nil))
+	if w.Header().Get("Content-Type") != "application/octet-stream" || w.Header().Get("Content-Disposition") != "attachment" {
+		t.Errorf("Expected an HTML file served as an attachment of bytes, got %v", w.Header())
+	}
+
+	tests := []struct {
+		name       string
+		r          *http.Request
+		wantStatus int
+	}{
+		{"unknown task", httptest.NewRequest("POST", "/v1/tasks/unknown/files", strings.NewReader("notes")), http.StatusNotFound},
+		{"too large", httptest.NewRequest("POST", "/v1/tasks/report/files", bytes.NewReader(make([]byte, 2048))), http.StatusRequestEntityTooLarge},
+		{"unknown file", httptest.NewRequest("GET", "/v1/tasks/report/files/00ff", nil), http.StatusNotFound},
+		{"other task", httptest.NewRequest("GET", strings.Replace(path(uri.URI), "/report/", "/other/", 1), nil), http.StatusNotFound},
+		{"path traversal", httptest.NewRequest("GET", "/v1/tasks/report/files/..%2F..%2Fsecret", nil), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := do(tt.r); w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+		}
+	}
+
+	// Without a file store the endpoints are not found and artifacts stay inline
+	plain := http.NewServeMux()
+	NewA2AServer(mockAgentCard, reportHandler).RegisterRoutes(plain)
+	w = httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest("POST", "/v1/tasks/report/files", strings.NewReader("notes")))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a file store, got %d", w.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+
+	"a2a/models"
+)
+
+// TaskHistoryMethod is the JSON-RPC method returning a task's messages and status transitions
+const TaskHistoryMethod = "tasks/history"
+
+// handleTaskHistory handles tasks/history, returning the messages exchanged for a task and the
+// statuses it passed through, each cut to the most recent historyLength entries when set
+func (s *A2AServer) handleTaskHistory(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	var params models.TaskQueryParams
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, err := s.store.Get(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, req.ID, err)
+		return
+	}
+	statuses, err := s.store.StatusHistory(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, req.ID, err)
+		return
+	}
+
+	s.sendResponseWithID(w, req.ID, models.TaskHistory{
+		MessageHistory: lastN(task.History, params.HistoryLength),
+		StatusHistory:  lastN(statuses, params.HistoryLength),
+	})
+}
+
+// lastN returns the last n items, or all of them when n is nil or negative
+func lastN[T any](items []T, n *int) []T {
+	if n == nil || *n < 0 || len(items) <= *n {
+		return items
+	}
+	return items[len(items)-*n:]
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestTaskHistory(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		fake.Advance(time.Minute)
+		if message.Parts[0].(models.TextPart).Text == "fail" {
+			return task, fmt.Errorf("model unavailable")
+		}
+		task.Status = models.TaskStatus{
+			State:   models.TaskStateInputRequired,
+			Message: &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Done"}}},
+		}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithClock(fake))
+	send := func(id, text string) {
+		doRPC(t, server, "message/send", models.MessageSendParams{
+			ID:      id,
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}},
+		})
+	}
+	history := func(id string, n *int) models.TaskHistory {
+		t.Helper()
+		response := doRPC(t, server, TaskHistoryMethod, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: id}, HistoryLength: n})
+		if response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+		var history models.TaskHistory
+		decodeResult(t, response.Result, &history)
+		return history
+	}
+	states := func(statuses []models.TaskStatus) string {
+		var s string
+		for _, status := range statuses {
+			s += fmt.Sprintf("%s@%s ", status.State, status.Timestamp)
+		}
+		return s
+	}
+	at := func(d time.Duration) string { return start.Add(d).Format(time.RFC3339Nano) }
+
+	send("audited", "Hello")
+	fake.Advance(time.Hour)
+	doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "audited"})
+
+	got := history("audited", nil)
+	want := fmt.Sprintf("working@%s input-required@%s canceled@%s ", at(0), at(time.Minute), at(time.Minute+time.Hour))
+	if states(got.StatusHistory) != want {
+		t.Errorf("Expected status history %q, got %q", want, states(got.StatusHistory))
+	}
+	if len(got.MessageHistory) != 2 || got.MessageHistory[0].Role != "user" || got.MessageHistory[1].Role != "agent" {
+		t.Errorf("Expected the user message and the agent's status message, got %+v", got.MessageHistory)
+	}
+
+	limit := 1
+	if got := history("audited", &limit); len(got.StatusHistory) != 1 || got.StatusHistory[0].State != models.TaskStateCanceled || len(got.MessageHistory) != 1 {
+		t.Errorf("Expected historyLength to keep the latest entries, got %+v", got)
+	}
+
+	// A failed run is recorded rather than left working
+	send("broken", "fail")
+	if got := history("broken", nil); len(got.StatusHistory) != 2 || got.StatusHistory[1].State != models.TaskStateFailed {
+		t.Errorf("Expected working then failed, got %q", states(got.StatusHistory))
+	}
+
+	if response := doRPC(t, server, TaskHistoryMethod, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "unknown"}}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected task not found, got %v", response.Error)
+	}
+}
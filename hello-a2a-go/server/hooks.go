@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"a2a/models"
+)
+
+// PreHook runs before a skill's handler. It may normalize the incoming message in place,
+// or return an error to reject the message without invoking the handler; a *models.A2AError
+// tells the client why, while other errors are reported as internal errors.
+type PreHook func(ctx context.Context, task *models.Task, message *models.Message) error
+
+// PostHook runs on the task returned by a skill's handler. It may rewrite the task in place
+// (for example to redact its output) or return an error to fail the request.
+type PostHook func(ctx context.Context, task *models.Task) error
+
+// SkillOption configures a skill registered with AddSkill
+type SkillOption func(*skillRoute)
+
+// WithPreHook adds a hook run, in registration order, before the skill's handler
+func WithPreHook(hook PreHook) SkillOption {
+	return func(r *skillRoute) {
+		r.pre = append(r.pre, hook)
+	}
+}
+
+// WithPostHook adds a hook run, in registration order, after the skill's handler
+func WithPostHook(hook PostHook) SkillOption {
+	return func(r *skillRoute) {
+		r.post = append(r.post, hook)
+	}
+}
+
+// withHooks wraps handler with pre and post hooks. Post hooks also run when the handler
+// fails but still returns a task, so failure messages are redacted as well, and on each
+// artifact event streamed with EmitArtifact, which is dropped when a hook fails.
+func withHooks(handler TaskHandler, pre []PreHook, post []PostHook) TaskHandler {
+	if len(pre) == 0 && len(post) == 0 {
+		return handler
+	}
+
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		for _, hook := range pre {
+			if err := hook(ctx, task, message); err != nil {
+				return nil, err
+			}
+		}
+
+		if emitter, ok := ctx.Value(artifactEmitterKey{}).(*artifactEmitter); ok && len(post) > 0 {
+			emitter.setFilter(func(artifact *models.Artifact) bool {
+				chunk := &models.Task{ID: task.ID, ContextID: task.ContextID, Artifacts: []models.Artifact{*artifact}}
+				for _, hook := range post {
+					if err := hook(ctx, chunk); err != nil {
+						return false
+					}
+				}
+				*artifact = chunk.Artifacts[0]
+				return true
+			})
+		}
+
+		updatedTask, err := handler(ctx, task, message)
+		if updatedTask == nil {
+			return updatedTask, err
+		}
+
+		for _, hook := range post {
+			if hookErr := hook(ctx, updatedTask); hookErr != nil && err == nil {
+				err = hookErr
+			}
+		}
+		return updatedTask, err
+	}
+}
+
+// NormalizeWhitespace returns a PreHook that trims text parts and collapses runs of whitespace
+func NormalizeWhitespace() PreHook {
+	return func(ctx context.Context, task *models.Task, message *models.Message) error {
+		for i, part := range message.Parts {
+			if textPart, ok := part.(models.TextPart); ok {
+				textPart.Text = strings.Join(strings.Fields(textPart.Text), " ")
+				message.Parts[i] = textPart
+			}
+		}
+		return nil
+	}
+}
+
+// emailPattern matches common email addresses
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// RedactEmails returns a PostHook replacing email addresses in the task output with "[redacted]"
+func RedactEmails() PostHook {
+	return RedactPatterns("[redacted]", emailPattern)
+}
+
+// RedactPatterns returns a PostHook replacing every match of patterns in the text parts of the
+// task's status message and artifacts with replacement. Applied to streamed chunks, it only
+// sees the output coalesced into one event, so a match split across events is not redacted.
+func RedactPatterns(replacement string, patterns ...*regexp.Regexp) PostHook {
+	redact := func(parts []models.Part) {
+		for i, part := range parts {
+			textPart, ok := part.(models.TextPart)
+			if !ok {
+				continue
+			}
+			for _, pattern := range patterns {
+				textPart.Text = pattern.ReplaceAllString(textPart.Text, replacement)
+			}
+			parts[i] = textPart
+		}
+	}
+
+	return func(ctx context.Context, task *models.Task) error {
+		if task.Status.Message != nil {
+			redact(task.Status.Message.Parts)
+		}
+		for _, artifact := range task.Artifacts {
+			redact(artifact.Parts)
+		}
+		return nil
+	}
+}
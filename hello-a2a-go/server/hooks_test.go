@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_SkillHooks(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+
+	var seen string
+	echo := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		seen = message.Parts[0].(models.TextPart).Text
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{
+			Role:  "agent",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "contact alice@example.com for " + seen}},
+		}
+		return task, nil
+	}
+	rejectEmpty := func(ctx context.Context, task *models.Task, message *models.Message) error {
+		if len(message.Parts) == 0 {
+			return models.NewInvalidParamsError("empty message")
+		}
+		return nil
+	}
+
+	err := server.AddSkill(models.AgentSkill{ID: "echo", Name: "Echo"}, echo,
+		WithPreHook(rejectEmpty),
+		WithPreHook(NormalizeWhitespace()),
+		WithPostHook(RedactEmails()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to add skill: %v", err)
+	}
+
+	params := models.MessageSendParams{
+		ID: "hook-task",
+		Message: models.Message{
+			Role:  "user",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "  more \n details  "}},
+		},
+		Metadata: map[string]interface{}{SkillMetadataKey: "echo"},
+	}
+
+	response := doRPC(t, server, "message/send", params)
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	if seen != "more details" {
+		t.Errorf("Expected normalized input %q, got %q", "more details", seen)
+	}
+
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	text := task.Status.Message.Parts[0].(models.TextPart).Text
+	if text != "contact [redacted] for more details" {
+		t.Errorf("Expected redacted output, got %q", text)
+	}
+
+	params.Message.Parts = nil
+	response = doRPC(t, server, "message/send", params)
+	if response.Error == nil || response.Error.Message != "empty message" {
+		t.Errorf("Expected pre-hook rejection, got %v", response.Error)
+	}
+
+	info := server.Introspect(context.Background())
+	for _, skill := range info.Skills {
+		if skill.ID == "echo" && (skill.PreHooks != 2 || skill.PostHooks != 1) {
+			t.Errorf("Expected 2 pre and 1 post hooks, got %+v", skill)
+		}
+	}
+}
+
+func TestA2AServer_PostHookRedactsArtifacts(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		EmitArtifact(ctx, textChunk(0, "mail bob@example.com", false, true))
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.NewTextPart("mail bob@example.com")}}}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	if err := server.AddSkill(models.AgentSkill{ID: "mail", Name: "Mail"}, handler, WithPostHook(RedactEmails())); err != nil {
+		t.Fatalf("Failed to add skill: %v", err)
+	}
+
+	params := models.MessageSendParams{
+		ID:       "redact-task",
+		Message:  models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Go")}},
+		Metadata: map[string]interface{}{SkillMetadataKey: "mail"},
+	}
+	response := doRPC(t, server, "message/send", params)
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if text := task.Artifacts[0].Parts[0].(models.TextPart).Text; text != "mail [redacted]" {
+		t.Errorf("Expected redacted artifact, got %q", text)
+	}
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"redact-stream","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]},"metadata":{"skillId":"mail"}}}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if strings.Contains(w.Body.String(), "bob@example.com") {
+		t.Fatalf("Expected streamed output to be redacted, got %s", w.Body.String())
+	}
+	var chunks []string
+	for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+		var response struct {
+			Result models.TaskArtifactUpdateEvent `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		if text := eventText(response.Result); text != "" {
+			chunks = append(chunks, text)
+		}
+	}
+	if len(chunks) == 0 || chunks[0] != "mail [redacted]" {
+		t.Errorf("Expected a redacted artifact event, got %q", chunks)
+	}
+}
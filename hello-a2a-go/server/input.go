@@ -0,0 +1,22 @@
+package server
+
+import "a2a/models"
+
+// InputRequired pauses task awaiting user input: the task is returned to the client in the
+// input-required state with prompt as the agent's status message. The client's next message
+// to the task resumes it, running the handler again with that message and the task's history,
+// which ends with the prompt and the answer, artifacts and metadata.
+//
+//	if city == "" {
+//		return server.InputRequired(task, "Which city?"), nil
+//	}
+func InputRequired(task *models.Task, prompt string) *models.Task {
+	task.Status = models.TaskStatus{
+		State: models.TaskStateInputRequired,
+		Message: &models.Message{
+			Role:  "agent",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: prompt}},
+		},
+	}
+	return task
+}
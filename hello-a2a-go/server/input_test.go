@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"a2a/models"
+)
+
+// weatherHandler asks for a city before answering, keeping a draft artifact across the pause
+func weatherHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	text := message.Parts[0].(models.TextPart).Text
+	if text == "weather" {
+		task.Artifacts = append(task.Artifacts, models.Artifact{Parts: []models.Part{models.TextPart{Type: "text", Text: "draft"}}})
+		return InputRequired(task, "Which city?"), nil
+	}
+	task.Status = models.TaskStatus{
+		State:   models.TaskStateCompleted,
+		Message: &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Sunny in " + text}}},
+	}
+	return task, nil
+}
+
+func TestInputRequired(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, weatherHandler)
+	send := func(message models.Message) models.Task {
+		t.Helper()
+		response := doRPC(t, server, "message/send", models.MessageSendParams{Message: message})
+		if response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+		var task models.Task
+		decodeResult(t, response.Result, &task)
+		return task
+	}
+	text := func(s string) []models.Part { return []models.Part{models.TextPart{Type: "text", Text: s}} }
+
+	paused := send(models.Message{Role: "user", Parts: text("weather"), TaskID: "forecast"})
+	if paused.Status.State != models.TaskStateInputRequired || paused.Status.Message == nil || paused.Status.Message.Parts[0].(models.TextPart).Text != "Which city?" {
+		t.Fatalf("Expected the task to await input with the prompt, got %+v", paused.Status)
+	}
+
+	done := send(models.Message{Role: "user", Parts: text("Paris"), TaskID: "forecast"})
+	if done.Status.State != models.TaskStateCompleted || done.ContextID != paused.ContextID {
+		t.Fatalf("Expected the resumed task to complete in the same conversation, got %+v", done)
+	}
+	if len(done.Artifacts) != 1 {
+		t.Errorf("Expected the draft artifact to survive the pause, got %+v", done.Artifacts)
+	}
+	var roles string
+	for _, message := range done.History {
+		roles += message.Role + " "
+	}
+	if roles != "user agent user agent " {
+		t.Errorf("Expected the prompt and answer in the history, got %q", roles)
+	}
+
+	history := doRPC(t, server, TaskHistoryMethod, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "forecast"}})
+	var got models.TaskHistory
+	decodeResult(t, history.Result, &got)
+	var states string
+	for _, status := range got.StatusHistory {
+		states += string(status.State) + " "
+	}
+	if states != "working input-required working completed " {
+		t.Errorf("Expected the pause in the status history, got %q", states)
+	}
+}
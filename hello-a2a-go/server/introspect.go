@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+
+	"a2a/models"
+)
+
+// Limits holds the request limits enforced by the server
+type Limits struct {
+	// MaxRequestBytes is the maximum accepted JSON-RPC request body size (0 means unlimited)
+	MaxRequestBytes int64 `json:"maxRequestBytes"`
+	// MaxEventBytes is the maximum size of a streamed event (0 means unlimited)
+	MaxEventBytes int64 `json:"maxEventBytes"`
+	// MaxFileBytes is the maximum size of an uploaded file (0 means unlimited)
+	MaxFileBytes int64 `json:"maxFileBytes"`
+	// MaxBatchRequests is the maximum number of requests in a JSON-RPC batch (0 means unlimited)
+	MaxBatchRequests int `json:"maxBatchRequests"`
+}
+
+// SkillInfo reports a skill declared on the agent card and the handler serving it
+type SkillInfo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Handler   string `json:"handler"`
+	PreHooks  int    `json:"preHooks,omitempty"`
+	PostHooks int    `json:"postHooks,omitempty"`
+}
+
+// StoreInfo describes the task store backend in use
+type StoreInfo struct {
+	Backend string `json:"backend"`
+	// Tasks is the number of stored tasks, or -1 when the store cannot count them
+	Tasks int `json:"tasks"`
+}
+
+// Introspection describes what a running server actually serves, as opposed to
+// what its static agent card declares
+type Introspection struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Capabilities are the capabilities the server implements
+	Capabilities models.AgentCapabilities `json:"capabilities"`
+	Skills       []SkillInfo              `json:"skills"`
+	Methods      []string                 `json:"methods"`
+	Transports   []string                 `json:"transports"`
+	Store        StoreInfo                `json:"store"`
+	Limits       Limits                   `json:"limits"`
+	// Drift lists mismatches between the agent card and the running server
+	Drift []string `json:"drift,omitempty"`
+	// Cluster is the replica the server runs as, if it is one of a cluster (see WithCluster)
+	Cluster *ClusterInfo `json:"cluster,omitempty"`
+}
+
+// Introspect reports the server's registered skills, handlers, transports, store and limits
+func (s *A2AServer) Introspect(ctx context.Context) Introspection {
+	taskCount, err := s.store.Count(ctx)
+	if err != nil {
+		taskCount = -1
+	}
+
+	card := s.AgentCard()
+	skills := make([]SkillInfo, 0, len(card.Skills))
+	for _, skill := range card.Skills {
+		info := SkillInfo{ID: skill.ID, Name: skill.Name, Handler: handlerName(s.handler)}
+		if route, _ := s.route(skill.ID); route != nil {
+			if route.handler != nil {
+				info.Handler = handlerName(route.handler)
+			}
+			info.PreHooks = len(route.pre)
+			info.PostHooks = len(route.post)
+		}
+		skills = append(skills, info)
+	}
+
+	actual := s.capabilities()
+	return Introspection{
+		Name:         card.Name,
+		Version:      card.Version,
+		Capabilities: actual,
+		Skills:       skills,
+		Methods:      append([]string(nil), supportedMethods...),
+		Transports:   []string{"JSONRPC", "SSE"},
+		Store:        StoreInfo{Backend: s.store.Backend(), Tasks: taskCount},
+		Limits:       s.limits,
+		Drift:        capabilityDrift(card.Capabilities, actual),
+		Cluster:      s.clusterInfo(),
+	}
+}
+
+// IntrospectionHandler serves Introspect as JSON, for mounting on an admin route behind the
+// admin's authentication, as it reports the server's handlers, stored task count and replica;
+// the admin API serves it at /admin/introspect
+func (s *A2AServer) IntrospectionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.Introspect(r.Context()))
+	})
+}
+
+// capabilities returns the capabilities this server implements
+func (s *A2AServer) capabilities() models.AgentCapabilities {
+	return models.AgentCapabilities{
+		Streaming:              boolPtr(true),
+		PushNotifications:      boolPtr(s.push != nil),
+		StateTransitionHistory: boolPtr(true),
+	}
+}
+
+// capabilityDrift describes each capability whose declared value differs from the actual one
+func capabilityDrift(declared, actual models.AgentCapabilities) []string {
+	var drift []string
+	check := func(name string, declared, actual *bool) {
+		d := declared != nil && *declared
+		a := actual != nil && *actual
+		if d != a {
+			drift = append(drift, fmt.Sprintf("capability %s: card declares %t, server implements %t", name, d, a))
+		}
+	}
+	check("streaming", declared.Streaming, actual.Streaming)
+	check("pushNotifications", declared.PushNotifications, actual.PushNotifications)
+	check("stateTransitionHistory", declared.StateTransitionHistory, actual.StateTransitionHistory)
+	return drift
+}
+
+// handlerName returns the fully qualified function name of a handler for diagnostics
+func handlerName(h interface{}) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// doRPC sends a JSON-RPC request to server and decodes the response
+func doRPC(t *testing.T, server *A2AServer, method string, params interface{}) models.JSONRPCResponse {
+	t.Helper()
+
+	reqBody, err := json.Marshal(models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+		},
+		Method: method,
+		Params: params,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.ServeHTTP(w, req)
+
+	var response models.JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+// decodeResult round-trips a generically decoded JSON-RPC result into dst
+func decodeResult(t *testing.T, result interface{}, dst interface{}) {
+	t.Helper()
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Failed to marshal result: %v", err)
+	}
+	if err := json.Unmarshal(resultBytes, dst); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+}
+
+func TestA2AServer_Introspect(t *testing.T) {
+	card := mockAgentCard
+	card.Capabilities.PushNotifications = boolPtr(true)
+	server := NewA2AServer(card, mockTaskHandler, WithMaxRequestBytes(1024), WithAdmin(AdminConfig{Token: testAdminToken}))
+
+	var info Introspection
+	if w := adminRequest(t, server, http.MethodGet, "/admin/introspect", &info); w.Code != http.StatusOK {
+		t.Fatalf("Expected introspection, got %d", w.Code)
+	}
+
+	if info.Name != mockAgentCard.Name {
+		t.Errorf("Expected name %s, got %s", mockAgentCard.Name, info.Name)
+	}
+	if len(info.Skills) != 1 || info.Skills[0].ID != "test-skill" {
+		t.Fatalf("Expected test-skill, got %+v", info.Skills)
+	}
+	if !strings.HasSuffix(info.Skills[0].Handler, "mockTaskHandler") {
+		t.Errorf("Expected handler mockTaskHandler, got %s", info.Skills[0].Handler)
+	}
+	if info.Limits.MaxRequestBytes != 1024 {
+		t.Errorf("Expected max request bytes 1024, got %d", info.Limits.MaxRequestBytes)
+	}
+	if info.Store.Backend != "memory" {
+		t.Errorf("Expected memory store, got %s", info.Store.Backend)
+	}
+
+	// The card declares push notifications, which the server was not configured with
+	if len(info.Drift) != 1 || !strings.Contains(info.Drift[0], "pushNotifications") {
+		t.Errorf("Expected pushNotifications drift, got %v", info.Drift)
+	}
+
+	// Introspection describes the deployment's internals, so clients cannot call it
+	if response := doRPC(t, server, "agent/introspect", nil); response.Error == nil || response.Error.Code != int(models.ErrorCodeMethodNotFound) {
+		t.Errorf("Expected agent/introspect not to be found, got %+v", response)
+	}
+	w := httptest.NewRecorder()
+	server.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/introspect", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the admin token to be required, got %d", w.Code)
+	}
+}
+
+func TestA2AServer_MaxRequestBytes(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithMaxRequestBytes(16))
+
+	response := doRPC(t, server, "tasks/get", models.TaskQueryParams{
+		TaskIDParams: models.TaskIDParams{ID: "a-task-id-that-exceeds-the-limit"},
+	})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidRequest) {
+		t.Errorf("Expected invalid request error, got %v", response.Error)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// Journal event types
+const (
+	// JournalTaskSaved records a snapshot of a task after it was created or updated
+	JournalTaskSaved = "task.saved"
+	// JournalMessageAppended records a message received for a task
+	JournalMessageAppended = "task.message"
+	// JournalTaskDeleted records the deletion of a task, such as by the retention policy
+	JournalTaskDeleted = "task.deleted"
+)
+
+// JournalEvent is one task lifecycle event in a journal
+type JournalEvent struct {
+	Time    time.Time       `json:"time"`
+	Type    string          `json:"type"`
+	TaskID  string          `json:"taskId"`
+	Task    *models.Task    `json:"task,omitempty"`
+	Message *models.Message `json:"message,omitempty"`
+}
+
+// Journal appends task lifecycle events to a JSONL file for audit and replay. When the file
+// exceeds maxBytes it is rotated to path.1, path.1 to path.2 and so on, keeping maxFiles
+// rotated files.
+type Journal struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenJournal opens the journal at path for appending. A maxBytes of zero disables rotation.
+func OpenJournal(path string, maxBytes int64, maxFiles int) (*Journal, error) {
+	j := &Journal{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// WithJournal appends every task lifecycle event persisted by the server to journal
+func WithJournal(journal *Journal) Option {
+	return func(s *A2AServer) {
+		s.journal = journal
+	}
+}
+
+// open opens the journal file for appending. Callers hold j.mu or own j exclusively.
+func (j *Journal) open() error {
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat journal: %w", err)
+	}
+	j.file, j.size = file, info.Size()
+	return nil
+}
+
+// Append writes event as one line, rotating the journal first if it would exceed maxBytes
+func (j *Journal) Append(event JournalEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.maxBytes > 0 && j.size > 0 && j.size+int64(len(line)) > j.maxBytes {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	return err
+}
+
+// rotate shifts the rotated files up by one and starts a new journal. Callers hold j.mu.
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("failed to close journal: %w", err)
+	}
+	if j.maxFiles > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", j.path, j.maxFiles))
+		for i := j.maxFiles - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", j.path, i), fmt.Sprintf("%s.%d", j.path, i+1))
+		}
+		if err := os.Rename(j.path, j.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate journal: %w", err)
+		}
+	} else if err := os.Remove(j.path); err != nil {
+		return fmt.Errorf("failed to rotate journal: %w", err)
+	}
+	return j.open()
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// JournalFiles returns the existing files of the journal at path, oldest first, for replay
+func JournalFiles(path string) []string {
+	var rotated []string
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(name); err != nil {
+			break
+		}
+		rotated = append([]string{name}, rotated...)
+	}
+	if _, err := os.Stat(path); err == nil {
+		rotated = append(rotated, path)
+	}
+	return rotated
+}
+
+// ReplayJournal applies the events read from r to store, reconstructing the tasks and message
+// history they record. It returns the number of events applied.
+func ReplayJournal(ctx context.Context, r io.Reader, store TaskStore) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+
+	applied := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event JournalEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return applied, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var err error
+		switch {
+		case event.Type == JournalTaskSaved && event.Task != nil:
+			err = store.Save(ctx, event.Task)
+		case event.Type == JournalMessageAppended && event.Message != nil:
+			err = store.AppendMessage(ctx, event.TaskID, event.Message)
+		case event.Type == JournalTaskDeleted:
+			err = store.Delete(ctx, event.TaskID)
+		default:
+			continue
+		}
+		if err != nil {
+			return applied, fmt.Errorf("line %d: %w", line, err)
+		}
+		applied++
+	}
+	return applied, scanner.Err()
+}
+
+// journaledStore is a TaskStore that journals every successful write to the wrapped store
+type journaledStore struct {
+	TaskStore
+	journal *Journal
+	clock   clock.Clock
+	logger  *slog.Logger
+}
+
+// Save implements TaskStore
+func (s journaledStore) Save(ctx context.Context, task *models.Task) error {
+	if err := s.TaskStore.Save(ctx, task); err != nil {
+		return err
+	}
+	s.record(ctx, JournalEvent{Type: JournalTaskSaved, TaskID: task.ID, Task: task})
+	return nil
+}
+
+// AppendMessage implements TaskStore
+func (s journaledStore) AppendMessage(ctx context.Context, taskID string, message *models.Message) error {
+	if err := s.TaskStore.AppendMessage(ctx, taskID, message); err != nil {
+		return err
+	}
+	s.record(ctx, JournalEvent{Type: JournalMessageAppended, TaskID: taskID, Message: message})
+	return nil
+}
+
+// Delete implements TaskStore
+func (s journaledStore) Delete(ctx context.Context, id string) error {
+	if err := s.TaskStore.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.record(ctx, JournalEvent{Type: JournalTaskDeleted, TaskID: id})
+	return nil
+}
+
+// record appends event, logging failures rather than failing the request the journal observes
+func (s journaledStore) record(ctx context.Context, event JournalEvent) {
+	event.Time = s.clock.Now().UTC()
+	if err := s.journal.Append(event); err != nil {
+		contextLogger(ctx, s.logger, event.TaskID).Error("failed to journal event",
+			slog.String("event", string(event.Type)), slog.Any("error", err))
+	}
+}
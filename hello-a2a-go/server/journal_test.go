@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenJournal(path, 600, 10)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	server := NewA2AServer(mockAgentCard, mockInputHandler, WithJournal(journal))
+
+	for _, id := range []string{"j1", "j2", "j3"} {
+		params := models.MessageSendParams{
+			ID: id,
+			Message: models.Message{
+				Role:  "user",
+				Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello " + id}},
+			},
+		}
+		if response := doRPC(t, server, "message/send", params); response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+	}
+	if response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "j2"}); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	journal.Close()
+
+	files := JournalFiles(path)
+	if len(files) < 2 {
+		t.Fatalf("Expected the journal to rotate, got files %v", files)
+	}
+
+	store := NewMemoryTaskStore()
+	total := 0
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		applied, err := ReplayJournal(context.Background(), file, store)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to replay %s: %v", name, err)
+		}
+		total += applied
+	}
+
+	// Each task is saved when it starts working and when it awaits input
+	if total != 10 {
+		t.Errorf("Expected 10 events (6 saves, 3 messages, 1 cancel), got %d", total)
+	}
+	if count, _ := store.Count(context.Background()); count != 3 {
+		t.Errorf("Expected 3 tasks, got %d", count)
+	}
+	task, err := store.Get(context.Background(), "j2")
+	if err != nil || task.Status.State != models.TaskStateCanceled {
+		t.Errorf("Expected replayed task j2 to be canceled, got %+v (%v)", task, err)
+	}
+	statuses, _ := store.StatusHistory(context.Background(), "j2")
+	if len(statuses) != 3 || statuses[2].State != models.TaskStateCanceled {
+		t.Errorf("Expected replayed status history working, input-required, canceled for j2, got %+v", statuses)
+	}
+	messages, _ := store.Messages(context.Background(), "j3")
+	if len(messages) != 1 || messages[0].Parts[0].(models.TextPart).Text != "Hello j3" {
+		t.Errorf("Expected replayed message history for j3, got %+v", messages)
+	}
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// jwksRefreshInterval bounds how often the keys of a JWKS URL are refetched for tokens signed
+// with an unknown key ID
+const jwksRefreshInterval = time.Minute
+
+// JWTConfig configures JWTVerifier. Exactly one of Secret and JWKSURL must be set.
+type JWTConfig struct {
+	// Secret verifies HS256, HS384 and HS512 tokens
+	Secret []byte
+	// JWKSURL is fetched for the public keys verifying RS256, RS384, RS512, ES256, ES384,
+	// ES512 and EdDSA tokens, e.g. an identity provider's jwks_uri
+	JWKSURL string
+	// Issuer, when set, must match the iss claim
+	Issuer string
+	// Audience, when set, must be among the aud claim
+	Audience string
+	// Leeway tolerates clock skew when checking the exp and nbf claims
+	Leeway time.Duration
+	// Clock checks expiry; the default is clock.Real
+	Clock clock.Clock
+	// HTTPClient fetches JWKSURL; the default is http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// jwtClaims are the registered claims checked by JWTVerifier and the scope claims it returns
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	// Scope is the OAuth 2.0 space-separated scope claim
+	Scope string `json:"scope"`
+	// Scopes is the scp claim some providers use instead, a list or space-separated string
+	Scopes json.RawMessage `json:"scp"`
+}
+
+// JWTVerifier returns a TokenVerifier accepting JSON Web Tokens signed with cfg's secret or with
+// a key of its JWKS URL, unexpired and, when configured, from the issuer for the audience. The
+// token's scopes are read from its scope or scp claim.
+func JWTVerifier(cfg JWTConfig) TokenVerifier {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	keys := &jwks{url: cfg.JWKSURL, client: cfg.HTTPClient, clock: cfg.Clock}
+
+	return func(ctx context.Context, token string) ([]string, error) {
+		if (cfg.Secret == nil) == (cfg.JWKSURL == "") {
+			return nil, errors.New("jwt verifier needs a secret or a JWKS URL")
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			return nil, errors.New("malformed jwt")
+		}
+		var header struct {
+			Alg string `json:"alg"`
+			Kid string `json:"kid"`
+		}
+		if err := decodeSegment(parts[0], &header); err != nil {
+			return nil, fmt.Errorf("invalid jwt header: %w", err)
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwt signature: %w", err)
+		}
+		signed := []byte(parts[0] + "." + parts[1])
+
+		// The secret and the JWKS keys each verify only their own algorithms, so a token cannot
+		// pass off a public key as an HMAC secret
+		if strings.HasPrefix(header.Alg, "HS") {
+			if cfg.Secret == nil {
+				return nil, fmt.Errorf("unexpected jwt algorithm %q", header.Alg)
+			}
+			err = verifyHMAC(header.Alg, cfg.Secret, signed, signature)
+		} else {
+			if cfg.JWKSURL == "" {
+				return nil, fmt.Errorf("unexpected jwt algorithm %q", header.Alg)
+			}
+			var key crypto.PublicKey
+			if key, err = keys.key(ctx, header.Kid); err == nil {
+				err = models.VerifyJWSSignature(header.Alg, key, signed, signature)
+				if errors.Is(err, models.ErrInvalidSignature) {
+					err = errors.New("invalid jwt signature")
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var claims jwtClaims
+		if err := decodeSegment(parts[1], &claims); err != nil {
+			return nil, fmt.Errorf("invalid jwt claims: %w", err)
+		}
+		if err := cfg.check(claims); err != nil {
+			return nil, err
+		}
+		return claims.scopes(), nil
+	}
+}
+
+// check validates the registered claims of a token
+func (cfg JWTConfig) check(claims jwtClaims) error {
+	now := cfg.Clock.Now()
+	if claims.ExpiresAt != nil && !now.Before(unixTime(*claims.ExpiresAt).Add(cfg.Leeway)) {
+		return errors.New("jwt expired")
+	}
+	if claims.NotBefore != nil && now.Add(cfg.Leeway).Before(unixTime(*claims.NotBefore)) {
+		return errors.New("jwt not yet valid")
+	}
+	if cfg.Issuer != "" && claims.Issuer != cfg.Issuer {
+		return fmt.Errorf("unexpected jwt issuer %q", claims.Issuer)
+	}
+	if cfg.Audience != "" && !containsString(stringOrList(claims.Audience), cfg.Audience) {
+		return errors.New("jwt not issued for this audience")
+	}
+	return nil
+}
+
+// scopes returns the scopes granted by the scope or scp claim
+func (c jwtClaims) scopes() []string {
+	if c.Scope != "" {
+		return strings.Fields(c.Scope)
+	}
+	var scopes []string
+	for _, s := range stringOrList(c.Scopes) {
+		scopes = append(scopes, strings.Fields(s)...)
+	}
+	return scopes
+}
+
+// unixTime converts a NumericDate claim to a time
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// stringOrList decodes a claim that is either a string or a list of strings
+func stringOrList(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []string{s}
+	}
+	var list []string
+	json.Unmarshal(raw, &list)
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON segment of a token into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hashFor returns the hash of an algorithm's size suffix, e.g. SHA-256 for HS256 and ES256
+func hashFor(alg string) (crypto.Hash, func() hash.Hash, error) {
+	switch alg[len(alg)-3:] {
+	case "256":
+		return crypto.SHA256, sha256.New, nil
+	case "384":
+		return crypto.SHA384, sha512.New384, nil
+	case "512":
+		return crypto.SHA512, sha512.New, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported jwt algorithm %q", alg)
+}
+
+// verifyHMAC checks an HS256, HS384 or HS512 signature
+func verifyHMAC(alg string, secret, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported jwt algorithm %q", alg)
+	}
+	_, newHash, err := hashFor(alg)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(newHash, secret)
+	mac.Write(signed)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.New("invalid jwt signature")
+	}
+	return nil
+}
+
+// jwks caches the public keys of a JWKS URL by key ID
+type jwks struct {
+	url    string
+	client *http.Client
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// key returns the key kid, fetching the key set when it is unknown and was not fetched recently.
+// A token without a key ID is verified with the only key of a set holding one.
+func (j *jwks) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+	if j.keys != nil && j.clock.Now().Sub(j.fetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown jwt key %q", kid)
+	}
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	j.keys, j.fetched = keys, j.clock.Now()
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown jwt key %q", kid)
+}
+
+// lookup returns the cached key kid; the caller holds j.mu
+func (j *jwks) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// fetch downloads and decodes the key set, skipping keys of unsupported types
+func (j *jwks) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var set models.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if key, err := k.PublicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+)
+
+// signJWT returns a token of claims signed with key: a []byte secret for HS256, an
+// *rsa.PrivateKey for RS256 or an *ecdsa.PrivateKey for ES256
+func signJWT(t *testing.T, key interface{}, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	alg := "HS256"
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		alg = "ES256"
+	}
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier_HMAC(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	secret := []byte("shared secret")
+	verify := JWTVerifier(JWTConfig{Secret: secret, Issuer: "https://issuer", Audience: "agent", Clock: clock.NewFake(now)})
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"iss": "https://issuer", "aud": []string{"other", "agent"}, "exp": now.Add(time.Minute).Unix(), "scope": "tasks:read tasks:write"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	scopes, err := verify(context.Background(), signJWT(t, secret, "", claims(nil)))
+	if err != nil {
+		t.Fatalf("Expected a valid token, got %v", err)
+	}
+	if strings.Join(scopes, ",") != "tasks:read,tasks:write" {
+		t.Errorf("Expected the scope claim, got %q", scopes)
+	}
+	if scopes, _ := verify(context.Background(), signJWT(t, secret, "", claims(map[string]interface{}{"scope": nil, "scp": []string{"a", "b"}}))); strings.Join(scopes, ",") != "a,b" {
+		t.Errorf("Expected the scp claim, got %q", scopes)
+	}
+
+	tokens := map[string]string{
+		"expired":        signJWT(t, secret, "", claims(map[string]interface{}{"exp": now.Unix()})),
+		"not yet valid":  signJWT(t, secret, "", claims(map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})),
+		"wrong issuer":   signJWT(t, secret, "", claims(map[string]interface{}{"iss": "https://other"})),
+		"wrong audience": signJWT(t, secret, "", claims(map[string]interface{}{"aud": "other"})),
+		"wrong secret":   signJWT(t, []byte("guessed"), "", claims(nil)),
+		"malformed":      "not.a-token",
+		"alg none":       base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + strings.Split(signJWT(t, secret, "", claims(nil)), ".")[1] + ".",
+	}
+	for name, token := range tokens {
+		if _, err := verify(context.Background(), token); err == nil {
+			t.Errorf("Expected the %s token to be rejected", name)
+		}
+	}
+}
+
+func TestJWTVerifier_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+
+	fetches := 0
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+		}})
+	}))
+	defer jwksServer.Close()
+
+	verify := JWTVerifier(JWTConfig{JWKSURL: jwksServer.URL})
+	claims := map[string]interface{}{"scp": "tasks:write"}
+	for name, token := range map[string]string{"RS256": signJWT(t, rsaKey, "rsa", claims), "ES256": signJWT(t, ecKey, "ec", claims)} {
+		if scopes, err := verify(context.Background(), token); err != nil || len(scopes) != 1 || scopes[0] != "tasks:write" {
+			t.Errorf("Expected the %s token to be accepted, got %q, %v", name, scopes, err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the key set to be fetched once, got %d", fetches)
+	}
+
+	// A token signed with a key the set does not hold, or with the wrong key, is rejected
+	if _, err := verify(context.Background(), signJWT(t, rsaKey, "rotated", claims)); err == nil {
+		t.Error("Expected an unknown key ID to be rejected")
+	}
+	if _, err := verify(context.Background(), signJWT(t, rsaKey, "ec", claims)); err == nil {
+		t.Error("Expected a token signed with another key to be rejected")
+	}
+	// HMAC tokens cannot be verified with the public keys
+	if _, err := verify(context.Background(), signJWT(t, []byte("public key"), "rsa", claims)); err == nil {
+		t.Error("Expected an HS256 token to be rejected by a JWKS verifier")
+	}
+}
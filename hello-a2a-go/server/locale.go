@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocaleMetadataKey is the request metadata key carrying an Accept-Language style locale
+const LocaleMetadataKey = "locale"
+
+// localeContextKey is the context key for the negotiated request locale
+type localeContextKey struct{}
+
+// ContextWithLocale returns a copy of ctx carrying the given language tags, most preferred first
+func ContextWithLocale(ctx context.Context, tags []string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, tags)
+}
+
+// LocaleFromContext returns the language tags negotiated for the request, most preferred first.
+// It returns nil when the caller did not express a preference.
+func LocaleFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(localeContextKey{}).([]string)
+	return tags
+}
+
+// PrimaryLanguage returns the base language subtag (e.g. "fr" for "fr-CH") of the
+// most preferred locale in ctx, or fallback when none is set
+func PrimaryLanguage(ctx context.Context, fallback string) string {
+	tags := LocaleFromContext(ctx)
+	if len(tags) == 0 {
+		return fallback
+	}
+	base, _, _ := strings.Cut(tags[0], "-")
+	return strings.ToLower(base)
+}
+
+// ParseAcceptLanguage parses an Accept-Language style value such as
+// "fr-CH, fr;q=0.9, en;q=0.8" into tags ordered by descending quality.
+// Wildcards and tags with q=0 are dropped.
+func ParseAcceptLanguage(value string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var entries []weighted
+	for _, field := range strings.Split(value, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(field), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		entries = append(entries, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// withRequestLocale attaches the locale from request metadata to ctx, falling back
+// to the HTTP Accept-Language header when the metadata does not carry one
+func withRequestLocale(ctx context.Context, r *http.Request, metadata map[string]interface{}) context.Context {
+	var value string
+	if v, ok := metadata[LocaleMetadataKey].(string); ok {
+		value = v
+	} else if r != nil {
+		value = r.Header.Get("Accept-Language")
+	}
+
+	tags := ParseAcceptLanguage(value)
+	if len(tags) == 0 {
+		return ctx
+	}
+	return ContextWithLocale(ctx, tags)
+}
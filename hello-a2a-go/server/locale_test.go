@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("en;q=0.8, fr-CH, *;q=0.5, de;q=0, fr;q=0.9")
+	want := []string{"fr-CH", "fr", "en"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if tags := ParseAcceptLanguage(""); len(tags) != 0 {
+		t.Errorf("Expected no tags for empty value, got %v", tags)
+	}
+}
+
+func TestA2AServer_LocalePropagation(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		header   string
+		want     string
+	}{
+		{name: "metadata", metadata: map[string]interface{}{LocaleMetadataKey: "fr-FR,fr;q=0.9"}, header: "de", want: "fr"},
+		{name: "header fallback", header: "es-MX, en;q=0.5", want: "es"},
+		{name: "default", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+				got = PrimaryLanguage(ctx, "en")
+				task.Status.State = models.TaskStateCompleted
+				return task, nil
+			}
+			server := NewA2AServer(mockAgentCard, handler)
+
+			reqBody, _ := json.Marshal(models.JSONRPCRequest{
+				JSONRPCMessage: models.JSONRPCMessage{
+					JSONRPC:                  "2.0",
+					JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+				},
+				Method: "message/send",
+				Params: models.MessageSendParams{
+					ID: "locale-task",
+					Message: models.Message{
+						Role:  "user",
+						Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+					},
+					Metadata: tt.metadata,
+				},
+			})
+
+			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, req)
+
+			if got != tt.want {
+				t.Errorf("Expected language %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
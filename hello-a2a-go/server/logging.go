@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"a2a/models"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// WithLogger logs the server's events to logger instead of slog.Default. Records about a
+// request carry its request_id and, once known, its task_id.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *A2AServer) {
+		s.logger = logger
+	}
+}
+
+// requestIDKey, taskIDKey and loggerKey are the context keys of a request's ID, its task's ID
+// and the logger of the server serving it
+type (
+	requestIDKey struct{}
+	taskIDKey    struct{}
+	loggerKey    struct{}
+)
+
+// RequestIDFromContext returns the ID of the request a handler's context belongs to, as sent
+// by the client in the X-Request-ID header or generated by the server
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// TaskIDFromContext returns the ID of the task a handler's context belongs to
+func TaskIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(taskIDKey{}).(string)
+	return id
+}
+
+// LoggerFromContext returns the logger of the server serving ctx (see WithLogger), or
+// slog.Default, with the request_id and task_id attributes of ctx, for handlers' own records
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	return contextLogger(ctx, nil, "")
+}
+
+// withTaskID returns a copy of ctx carrying taskID
+func withTaskID(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, taskIDKey{}, taskID)
+}
+
+// withRequestID gives each request to next an ID, taken from its X-Request-ID header when
+// valid and generated otherwise, which is echoed in the response header and carried by the
+// request's context along with the server's logger
+func (s *A2AServer) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(models.HeaderRequestID)
+		if !validRequestID(id) {
+			id = newContextID()
+		}
+		w.Header().Set(models.HeaderRequestID, id)
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		if s.logger != nil {
+			ctx = context.WithValue(ctx, loggerKey{}, s.logger)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID reports whether id is a non-empty request ID of printable ASCII characters,
+// safe to echo in a header and log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// setTaskID records taskID in the X-Task-ID response header and in the context of the
+// returned request
+func setTaskID(w http.ResponseWriter, r *http.Request, taskID string) *http.Request {
+	w.Header().Set(models.HeaderTaskID, taskID)
+	return r.WithContext(withTaskID(r.Context(), taskID))
+}
+
+// taskIDOf returns the ID of the task named by JSON-RPC params, their id or the task ID of
+// their message, or ""
+func taskIDOf(params interface{}) string {
+	p, ok := params.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if id, ok := p["id"].(string); ok && id != "" {
+		return id
+	}
+	message, _ := p["message"].(map[string]interface{})
+	id, _ := message["taskId"].(string)
+	return id
+}
+
+// contextLogger returns logger, or else the server logger carried by ctx or slog.Default,
+// with the request ID carried by ctx and taskID, which defaults to the task ID carried by ctx
+func contextLogger(ctx context.Context, logger *slog.Logger, taskID string) *slog.Logger {
+	if logger == nil {
+		logger, _ = ctx.Value(loggerKey{}).(*slog.Logger)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		logger = logger.With(slog.String("request_id", id))
+	}
+	if taskID == "" {
+		taskID = TaskIDFromContext(ctx)
+	}
+	if taskID != "" {
+		logger = logger.With(slog.String("task_id", taskID))
+	}
+	return logger
+}
+
+// log returns the server's logger for events of ctx concerning taskID, or the task of ctx
+// when taskID is ""
+func (s *A2AServer) log(ctx context.Context, taskID string) *slog.Logger {
+	return contextLogger(ctx, s.logger, taskID)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestRequestID(t *testing.T) {
+	var requestID, taskID string
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		requestID, taskID = RequestIDFromContext(ctx), TaskIDFromContext(ctx)
+		return mockTaskHandler(ctx, task, message)
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+
+	// A valid ID from the client is echoed and reaches the handler with the task ID
+	w := httptest.NewRecorder()
+	r := rpcRequest(t)
+	r.Header.Set(models.HeaderRequestID, "req-42")
+	server.ServeHTTP(w, r)
+	if got := w.Header().Get(models.HeaderRequestID); got != "req-42" || requestID != "req-42" {
+		t.Errorf("Expected request ID req-42 in the response and handler, got %q and %q", got, requestID)
+	}
+	if got := w.Header().Get(models.HeaderTaskID); got != "task-1" || taskID != "task-1" {
+		t.Errorf("Expected task ID task-1 in the response and handler, got %q and %q", got, taskID)
+	}
+
+	// Missing and malformed IDs are replaced with generated ones
+	for _, sent := range []string{"", "bad id\n"} {
+		w = httptest.NewRecorder()
+		r = rpcRequest(t)
+		r.Header.Set(models.HeaderRequestID, sent)
+		server.ServeHTTP(w, r)
+		if got := w.Header().Get(models.HeaderRequestID); len(got) != 32 || got != requestID {
+			t.Errorf("Expected a generated request ID for %q, got %q (handler saw %q)", sent, got, requestID)
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	panicking := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		panic("boom")
+	}
+	server := NewA2AServer(mockAgentCard, panicking, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	server.Use(Recover())
+
+	r := rpcRequest(t)
+	r.Header.Set(models.HeaderRequestID, "req-7")
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log record, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "recovered from panic" || entry["request_id"] != "req-7" || entry["task_id"] != "task-1" || entry["panic"] != "boom" {
+		t.Errorf("Expected the panic logged with the request and task IDs, got %v", entry)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		LoggerFromContext(ctx).Info("handled")
+		return mockTaskHandler(ctx, task, message)
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	r := rpcRequest(t)
+	r.Header.Set(models.HeaderRequestID, "req-9")
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log record, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "handled" || entry["request_id"] != "req-9" || entry["task_id"] != "task-1" {
+		t.Errorf("Expected the handler's record with the request and task IDs, got %v", entry)
+	}
+}
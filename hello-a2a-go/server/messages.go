@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"a2a/models"
+)
+
+// MessageListMethod is the JSON-RPC method listing the messages of stored tasks
+const MessageListMethod = "message/list"
+
+const (
+	// defaultMessagePageSize is the page size of listings not setting one
+	defaultMessagePageSize = 50
+	// maxMessagePageSize caps the page size of listings
+	maxMessagePageSize = 500
+)
+
+// messageCursor is the position a page token continues a listing from: the message at Index
+// in the history of the task TaskID, or the first message of the next task when it is gone
+type messageCursor struct {
+	TaskID string `json:"t"`
+	Index  int    `json:"i"`
+}
+
+// ListMessages returns a page of the messages of the stored tasks matching params, in task ID
+// order and oldest first within a task. Unknown tasks and malformed filters or page tokens are
+// reported as A2A errors.
+func (s *A2AServer) ListMessages(ctx context.Context, params models.MessageListParams) (models.MessageListResult, error) {
+	taskID := params.TaskID
+	if taskID == "" {
+		taskID = params.ID
+	}
+	since, err := parseListTime("since", params.Since)
+	if err != nil {
+		return models.MessageListResult{}, err
+	}
+	until, err := parseListTime("until", params.Until)
+	if err != nil {
+		return models.MessageListResult{}, err
+	}
+	var cursor messageCursor
+	if params.PageToken != "" {
+		data, err := base64.RawURLEncoding.DecodeString(params.PageToken)
+		if err != nil || json.Unmarshal(data, &cursor) != nil {
+			return models.MessageListResult{}, models.NewInvalidParamsError("Invalid page token")
+		}
+	}
+	pageSize := params.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultMessagePageSize
+	}
+	pageSize = min(pageSize, maxMessagePageSize)
+
+	s.mu.RLock()
+	var tasks []*models.Task
+	if taskID != "" {
+		var task *models.Task
+		if task, err = s.store.Get(ctx, taskID); err == nil && (params.ContextID == "" || task.ContextID == params.ContextID) {
+			tasks = []*models.Task{task}
+		}
+	} else {
+		tasks, err = s.store.ListTasks(ctx, params.ContextID)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return models.MessageListResult{}, err
+	}
+
+	result := models.MessageListResult{Messages: []models.Message{}}
+	for _, task := range tasks {
+		if task.ID < cursor.TaskID || !matchesList(task, params.State, since, until) {
+			continue
+		}
+		start := 0
+		if task.ID == cursor.TaskID {
+			start = cursor.Index
+		}
+		for i := start; i < len(task.History); i++ {
+			if len(result.Messages) == pageSize {
+				result.NextPageToken = pageToken(messageCursor{TaskID: task.ID, Index: i})
+				return result, nil
+			}
+			message := task.History[i]
+			stampMessage(&message, task.ID, task.ContextID, i)
+			result.Messages = append(result.Messages, message)
+		}
+	}
+	return result, nil
+}
+
+// parseListTime parses the RFC 3339 time of the listing filter name, if set
+func parseListTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, models.NewInvalidParamsError("Invalid " + name + " time: " + value)
+	}
+	return t, nil
+}
+
+// matchesList reports whether task is in state and last changed status in [since, until),
+// each filter applying when set
+func matchesList(task *models.Task, state models.TaskState, since, until time.Time) bool {
+	if state != "" && task.Status.State != state {
+		return false
+	}
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	changed, err := time.Parse(time.RFC3339Nano, task.Status.Timestamp)
+	if err != nil {
+		return false
+	}
+	return (since.IsZero() || !changed.Before(since)) && (until.IsZero() || changed.Before(until))
+}
+
+// pageToken encodes cursor as an opaque page token
+func pageToken(cursor messageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// handleMessageList handles message/list
+func (s *A2AServer) handleMessageList(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	var params models.MessageListParams
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+	result, err := s.ListMessages(r.Context(), params)
+	var a2aErr *models.A2AError
+	if errors.As(err, &a2aErr) {
+		s.sendA2AError(w, req.ID, a2aErr)
+		return
+	}
+	if err != nil {
+		s.sendStoreError(w, req.ID, err)
+		return
+	}
+	s.sendResponseWithID(w, req.ID, result)
+}
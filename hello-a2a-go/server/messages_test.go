@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_ListMessages(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	for i, task := range []struct {
+		id, contextID string
+		state         models.TaskState
+		timestamp     string
+	}{
+		{"a", "chat-1", models.TaskStateCompleted, "2025-01-01T10:00:00Z"},
+		{"b", "chat-1", models.TaskStateFailed, "2025-01-02T10:00:00Z"},
+		{"c", "chat-2", models.TaskStateCompleted, "2025-01-03T10:00:00Z"},
+	} {
+		var history []models.Message
+		for j := 0; j < 3; j++ {
+			history = append(history, models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: fmt.Sprintf("%s%d", task.id, j)}}})
+		}
+		err := server.store.Save(context.Background(), &models.Task{
+			ID:        task.id,
+			ContextID: task.contextID,
+			Status:    models.TaskStatus{State: task.state, Timestamp: task.timestamp},
+			History:   history,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save task %d: %v", i, err)
+		}
+	}
+
+	// list returns the text of every message listed, following page tokens
+	list := func(params models.MessageListParams) ([]string, int) {
+		t.Helper()
+		var texts []string
+		pages := 0
+		for {
+			response := doRPC(t, server, MessageListMethod, params)
+			if response.Error != nil {
+				t.Fatalf("Failed to list messages: %v", response.Error)
+			}
+			var result models.MessageListResult
+			decodeResult(t, response.Result, &result)
+			pages++
+			for _, message := range result.Messages {
+				if message.TaskID == "" || message.ContextID == "" {
+					t.Errorf("Expected messages naming their task and conversation, got %+v", message)
+				}
+				texts = append(texts, message.Parts[0].(models.TextPart).Text)
+			}
+			if result.NextPageToken == "" {
+				return texts, pages
+			}
+			params.PageToken = result.NextPageToken
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params models.MessageListParams
+		want   string
+		pages  int
+	}{
+		{"all", models.MessageListParams{}, "[a0 a1 a2 b0 b1 b2 c0 c1 c2]", 1},
+		{"paged", models.MessageListParams{PageSize: 2}, "[a0 a1 a2 b0 b1 b2 c0 c1 c2]", 5},
+		{"task", models.MessageListParams{TaskID: "b", PageSize: 2}, "[b0 b1 b2]", 2},
+		{"legacy task ID", models.MessageListParams{ID: "c"}, "[c0 c1 c2]", 1},
+		{"context", models.MessageListParams{ContextID: "chat-1"}, "[a0 a1 a2 b0 b1 b2]", 1},
+		{"state", models.MessageListParams{State: models.TaskStateCompleted, PageSize: 4}, "[a0 a1 a2 c0 c1 c2]", 2},
+		{"time range", models.MessageListParams{Since: "2025-01-02T00:00:00Z", Until: "2025-01-03T10:00:00Z"}, "[b0 b1 b2]", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts, pages := list(tt.params)
+			if fmt.Sprint(texts) != tt.want || pages != tt.pages {
+				t.Errorf("Expected %s in %d pages, got %v in %d", tt.want, tt.pages, texts, pages)
+			}
+		})
+	}
+
+	for _, params := range []models.MessageListParams{{PageToken: "not a token"}, {Since: "yesterday"}} {
+		if response := doRPC(t, server, MessageListMethod, params); response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+			t.Errorf("Expected invalid params for %+v, got %+v", params, response.Error)
+		}
+	}
+	if response := doRPC(t, server, MessageListMethod, models.MessageListParams{TaskID: "unknown"}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected task not found, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"a2a/llm"
+	"a2a/models"
+)
+
+// Histogram buckets in seconds: handlers answer in milliseconds to minutes, model calls take
+// seconds
+var (
+	handlerBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	llmBuckets     = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+)
+
+// WithMetrics collects request, task, streaming and model call metrics, served in the
+// Prometheus text exposition format at GET /metrics (see RegisterRoutes and MetricsHandler).
+// Model calls are observed once ObserveLLMCall is passed to llm.SetObserver.
+func WithMetrics() Option {
+	return func(s *A2AServer) {
+		s.metrics = newMetrics()
+	}
+}
+
+// metrics holds the counters served by MetricsHandler. Its methods do nothing on a nil
+// receiver, so callers need not check whether metrics are enabled.
+type metrics struct {
+	mu sync.Mutex
+	// requests counts JSON-RPC requests by method
+	requests map[string]float64
+	// transitions counts the states tasks entered
+	transitions map[models.TaskState]float64
+	// states holds the last state saved for each unfinished task, so that saving a task again
+	// in the same state counts no transition
+	states map[string]models.TaskState
+	// streamEvents counts events published to streams by type
+	streamEvents map[string]float64
+	// droppedEvents counts stream events slow subscribers missed by backpressure policy
+	droppedEvents map[string]float64
+	// handlers times handlers by skill
+	handlers map[string]*histogram
+	// llmCalls times model calls by provider and model
+	llmCalls map[llmLabels]*histogram
+	// inFlight counts the tasks whose handlers are running
+	inFlight int64
+}
+
+type llmLabels struct {
+	provider, model string
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:      make(map[string]float64),
+		transitions:   make(map[models.TaskState]float64),
+		states:        make(map[string]models.TaskState),
+		streamEvents:  make(map[string]float64),
+		droppedEvents: make(map[string]float64),
+		handlers:      make(map[string]*histogram),
+		llmCalls:      make(map[llmLabels]*histogram),
+	}
+}
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// countRequest counts a JSON-RPC request; unsupported methods are counted as "unknown" so
+// that clients cannot grow the label set
+func (m *metrics) countRequest(method string) {
+	if m == nil {
+		return
+	}
+	if !slices.Contains(supportedMethods, method) {
+		method = "unknown"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[method]++
+}
+
+// countTransition counts task entering its state unless it was last saved in that state
+func (m *metrics) countTransition(task *models.Task) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := task.Status.State
+	if last, ok := m.states[task.ID]; ok && last == state {
+		return
+	}
+	m.transitions[state]++
+	if state.IsTerminal() {
+		delete(m.states, task.ID)
+	} else {
+		m.states[task.ID] = state
+	}
+}
+
+// countStreamEvent counts an event published to a stream
+func (m *metrics) countStreamEvent(event interface{}) {
+	if m == nil {
+		return
+	}
+	eventType := "other"
+	switch event.(type) {
+	case models.TaskStatusUpdateEvent:
+		eventType = "status"
+	case models.TaskArtifactUpdateEvent:
+		eventType = "artifact"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streamEvents[eventType]++
+}
+
+// countDroppedEvents counts n events a slow subscriber missed under policy
+func (m *metrics) countDroppedEvents(policy BackpressurePolicy, n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.droppedEvents[string(policy)] += float64(n)
+}
+
+// handlerStarted counts a running handler of skill, returning a function to call with its
+// duration once it returns
+func (m *metrics) handlerStarted(skill string) func(time.Duration) {
+	if m == nil {
+		return func(time.Duration) {}
+	}
+	if skill == "" {
+		skill = defaultSkillLabel
+	}
+	m.mu.Lock()
+	m.inFlight++
+	m.mu.Unlock()
+
+	return func(d time.Duration) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.inFlight--
+		h, ok := m.handlers[skill]
+		if !ok {
+			h = newHistogram(handlerBuckets)
+			m.handlers[skill] = h
+		}
+		h.observe(d.Seconds())
+	}
+}
+
+// observeLLMCall times a model call
+func (m *metrics) observeLLMCall(call llm.Call) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := llmLabels{provider: call.Provider, model: call.Model}
+	h, ok := m.llmCalls[labels]
+	if !ok {
+		h = newHistogram(llmBuckets)
+		m.llmCalls[labels] = h
+	}
+	h.observe(call.Duration.Seconds())
+}
+
+// ObserveLLMCall records the latency of a model call in the server's metrics; pass it to
+// llm.SetObserver. It does nothing unless the server was created with WithMetrics.
+func (s *A2AServer) ObserveLLMCall(call llm.Call) {
+	s.metrics.observeLLMCall(call)
+}
+
+// MetricsHandler serves the metrics collected with WithMetrics, followed by the usage counters
+// when usage accounting is enabled, in the Prometheus text exposition format
+func (s *A2AServer) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var entries []UsageEntry
+		if s.usage != nil {
+			report, err := s.UsageReport(r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			entries = report.Entries
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.metrics.write(w)
+		if s.usage != nil {
+			writeUsageMetrics(w, entries)
+		}
+	})
+}
+
+// write writes the metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounters(w, "a2a_requests_total", "JSON-RPC requests by method.", "method", m.requests)
+	transitions := make(map[string]float64, len(m.transitions))
+	for state, n := range m.transitions {
+		transitions[string(state)] = n
+	}
+	writeCounters(w, "a2a_task_transitions_total", "Task state transitions by the state entered.", "state", transitions)
+	writeCounters(w, "a2a_stream_events_total", "Events emitted to task streams by type.", "type", m.streamEvents)
+	writeCounters(w, "a2a_stream_events_dropped_total", "Stream events slow subscribers missed by backpressure policy.", "policy", m.droppedEvents)
+
+	fmt.Fprintf(w, "# HELP a2a_tasks_in_flight Tasks whose handlers are running.\n# TYPE a2a_tasks_in_flight gauge\na2a_tasks_in_flight %d\n", m.inFlight)
+
+	fmt.Fprintf(w, "# HELP a2a_handler_duration_seconds Task handler duration by skill.\n# TYPE a2a_handler_duration_seconds histogram\n")
+	for _, skill := range sortedKeys(m.handlers) {
+		writeHistogram(w, "a2a_handler_duration_seconds", fmt.Sprintf("skill=%q", skill), m.handlers[skill])
+	}
+
+	fmt.Fprintf(w, "# HELP a2a_llm_call_duration_seconds Model call latency by provider and model.\n# TYPE a2a_llm_call_duration_seconds histogram\n")
+	labels := make([]llmLabels, 0, len(m.llmCalls))
+	for l := range m.llmCalls {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].provider != labels[j].provider {
+			return labels[i].provider < labels[j].provider
+		}
+		return labels[i].model < labels[j].model
+	})
+	for _, l := range labels {
+		writeHistogram(w, "a2a_llm_call_duration_seconds", fmt.Sprintf("provider=%q,model=%q", l.provider, l.model), m.llmCalls[l])
+	}
+}
+
+// writeCounters writes a counter with one series per label value
+func writeCounters(w io.Writer, name, help, label string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, v := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s=%q} %g\n", name, label, v, values[v])
+	}
+}
+
+// writeHistogram writes the bucket, sum and count series of h labeled by labels
+func writeHistogram(w io.Writer, name, labels string, h *histogram) {
+	for i, bound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", name, labels, bound, h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(w, "%s_sum{%s} %g\n", name, labels, h.sum)
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/llm"
+	"a2a/models"
+)
+
+func TestMetrics(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		fake.Advance(2 * time.Second)
+		switch message.Parts[0].(models.TextPart).Text {
+		case "fail":
+			return task, fmt.Errorf("model unavailable")
+		case "wait":
+			task.Status.State = models.TaskStateInputRequired
+			return task, nil
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithClock(fake), WithMetrics())
+	if err := server.AddSkill(models.AgentSkill{ID: "countdown", Name: "Countdown"}, Streaming(countdown)); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	send := func(id, text string) {
+		doRPC(t, server, "message/send", models.MessageSendParams{
+			ID:      id,
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}},
+		})
+	}
+	send("ok", "wait")
+	send("broken", "fail")
+	doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "ok"})
+	doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "ok"})
+	doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "ok"}})
+	doRPC(t, server, "no/such/method", nil)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"countdown","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]},"metadata":{"skillId":"countdown"}}}`
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	server.ObserveLLMCall(llm.Call{Provider: "ollama", Model: "llama3", Duration: 3 * time.Second})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	got := w.Body.String()
+	for _, want := range []string{
+		"# TYPE a2a_requests_total counter\n",
+		`a2a_requests_total{method="message/send"} 2` + "\n",
+		`a2a_requests_total{method="tasks/cancel"} 2` + "\n",
+		`a2a_requests_total{method="message/stream"} 1` + "\n",
+		`a2a_requests_total{method="unknown"} 1` + "\n",
+		// Canceling twice enters canceled once
+		`a2a_task_transitions_total{state="working"} 3` + "\n",
+		`a2a_task_transitions_total{state="input-required"} 1` + "\n",
+		`a2a_task_transitions_total{state="completed"} 1` + "\n",
+		`a2a_task_transitions_total{state="failed"} 1` + "\n",
+		`a2a_task_transitions_total{state="canceled"} 1` + "\n",
+		`a2a_stream_events_total{type="status"} 3` + "\n",
+		`a2a_stream_events_total{type="artifact"} 3` + "\n",
+		"a2a_tasks_in_flight 0\n",
+		`a2a_handler_duration_seconds_bucket{skill="default",le="1"} 0` + "\n",
+		`a2a_handler_duration_seconds_bucket{skill="default",le="2.5"} 2` + "\n",
+		`a2a_handler_duration_seconds_sum{skill="default"} 4` + "\n",
+		`a2a_handler_duration_seconds_count{skill="countdown"} 1` + "\n",
+		`a2a_llm_call_duration_seconds_bucket{provider="ollama",model="llama3",le="2.5"} 0` + "\n",
+		`a2a_llm_call_duration_seconds_bucket{provider="ollama",model="llama3",le="+Inf"} 1` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected no metrics endpoint without WithMetrics, got %d", w.Code)
+	}
+	// Observing model calls without metrics is harmless
+	server.ObserveLLMCall(llm.Call{Provider: "ollama"})
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"a2a/models"
+)
+
+// maxPeekBytes bounds the request bodies buffered by middleware to read the JSON-RPC method and ID
+const maxPeekBytes = 10 << 20
+
+// Use wraps the server's JSON-RPC, task, file, artifact and extended card endpoints in middleware.
+// The first middleware given is outermost; middleware from later calls runs inside that from
+// earlier calls. Middleware given to RegisterRoutes runs outside it, and the request ID (see
+// RequestIDFromContext) is assigned outside both. Call Use before serving.
+func (s *A2AServer) Use(middleware ...func(http.Handler) http.Handler) {
+	s.middleware = append(s.middleware, middleware...)
+	s.rpcHandler = s.wrap(http.HandlerFunc(s.serveRPC))
+	s.taskHandler = s.wrap(http.HandlerFunc(s.serveTask))
+	s.fileHandler = s.wrap(http.HandlerFunc(s.serveFiles))
+	s.artifactHandler = s.wrap(http.HandlerFunc(s.serveArtifacts))
+	s.extendedCardHandler = s.wrap(http.HandlerFunc(s.serveExtendedCard))
+	s.chatHandler = s.wrap(http.HandlerFunc(s.serveChat))
+}
+
+// wrap applies the middleware added with Use to h, inside the assignment of request IDs and
+// the decompression of request bodies
+func (s *A2AServer) wrap(h http.Handler) http.Handler {
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	return s.withRequestID(s.withCompression(h))
+}
+
+// rpcCall is the JSON-RPC method and ID of a request, as far as middleware can tell
+type rpcCall struct {
+	Method string      `json:"method"`
+	ID     interface{} `json:"id"`
+}
+
+// peekCall reads the JSON-RPC method and ID of r, leaving its body intact for the next handler
+func peekCall(r *http.Request) rpcCall {
+	var call rpcCall
+	if r.Body == nil || r.Method != http.MethodPost {
+		return call
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBytes))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err == nil {
+		json.Unmarshal(body, &call)
+	}
+	return call
+}
+
+// statusWriter records the status and size of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer does
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Logging returns middleware that logs each request to logger, or slog.Default when nil, with
+// its HTTP method, path, JSON-RPC method and ID, request and task IDs, status, response size
+// and duration
+func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			call := peekCall(r)
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("remote", r.RemoteAddr),
+				slog.Int("status", sw.status),
+				slog.Int64("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if call.Method != "" {
+				attrs = append(attrs, slog.String("rpc_method", call.Method), slog.Any("rpc_id", call.ID))
+			}
+			// The IDs are read from the response, as the request ID is assigned inside middleware
+			// given to RegisterRoutes
+			if id := sw.Header().Get(models.HeaderRequestID); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if id := sw.Header().Get(models.HeaderTaskID); id != "" {
+				attrs = append(attrs, slog.String("task_id", id))
+			}
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+		})
+	}
+}
+
+// Recover returns middleware that turns a panicking handler into a JSON-RPC internal error
+// response, logging the panic with its stack to the server's logger (see WithLogger). Once a
+// response has started, as with streams, the connection is aborted instead.
+// http.ErrAbortHandler is passed through.
+func Recover() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			call := peekCall(r)
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+				contextLogger(r.Context(), nil, sw.Header().Get(models.HeaderTaskID)).Error("recovered from panic",
+					slog.String("method", r.Method), slog.String("path", r.URL.Path),
+					slog.Any("panic", v), slog.String("stack", string(debug.Stack())))
+				if sw.status != 0 {
+					panic(http.ErrAbortHandler)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(models.JSONRPCResponse{
+					JSONRPCMessage: models.JSONRPCMessage{
+						JSONRPC:                  "2.0",
+						JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: call.ID},
+					},
+					Error: &models.JSONRPCError{Code: int(models.ErrorCodeInternalError), Message: "Internal error"},
+				})
+			}()
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+// TokenVerifier checks a bearer token, returning the scopes it grants
+type TokenVerifier func(ctx context.Context, token string) (scopes []string, err error)
+
+// RequireBearer returns middleware enforcing the bearer token schemes declared by the served
+// agent card: HTTP security schemes with scheme "bearer" named in its security requirements.
+// Requests must carry an "Authorization: Bearer" token accepted by verify and granting every
+// scope a requirement asks of those schemes. Requests pass unchecked while the card requires
+// no bearer scheme; other scheme types are left to other middleware.
+func (s *A2AServer) RequireBearer(verify TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			required := bearerRequirements(s.AgentCard())
+			if len(required) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				s.auditAuth(r, "bearer", "missing bearer token")
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+			scopes, err := verify(r.Context(), token)
+			if err != nil {
+				s.auditAuth(r, "bearer", "invalid bearer token")
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			granted := make(map[string]bool, len(scopes))
+			for _, scope := range scopes {
+				granted[scope] = true
+			}
+			for _, requirement := range required {
+				if grantsAll(granted, requirement) {
+					s.auditAuth(r, "bearer", "")
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bearerContextKey{}, fingerprint(token))))
+					return
+				}
+			}
+			s.auditAuth(r, "bearer", "insufficient scope")
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			http.Error(w, "insufficient scope", http.StatusForbidden)
+		})
+	}
+}
+
+// bearerRequirements returns, for each security requirement of card naming a bearer scheme,
+// the scopes it asks of its bearer schemes
+func bearerRequirements(card models.AgentCard) [][]string {
+	var required [][]string
+	for _, requirement := range card.Security {
+		var scopes []string
+		bearer := false
+		for name, names := range requirement {
+			scheme, ok := card.SecuritySchemes[name]
+			if !ok || scheme.Type != models.SecuritySchemeHTTP || !strings.EqualFold(scheme.Scheme, "bearer") {
+				continue
+			}
+			bearer = true
+			scopes = append(scopes, names...)
+		}
+		if bearer {
+			required = append(required, scopes)
+		}
+	}
+	return required
+}
+
+// bearerToken returns the token of r's "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// grantsAll reports whether granted holds every scope
+func grantsAll(granted map[string]bool, scopes []string) bool {
+	for _, scope := range scopes {
+		if !granted[scope] {
+			return false
+		}
+	}
+	return true
+}
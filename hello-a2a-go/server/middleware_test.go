@@ -0,0 +1,189 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+// rpcRequest returns a message/send JSON-RPC request with ID "1"
+func rpcRequest(t *testing.T) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+		},
+		Method: "message/send",
+		Params: models.TaskSendParams{
+			ID:      "task-1",
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "hi"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	return httptest.NewRequest(http.MethodPost, "/a2a", bytes.NewReader(body))
+}
+
+func TestUse(t *testing.T) {
+	var order []string
+	trace := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+				order = append(order, "/"+name)
+			})
+		}
+	}
+
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	server.Use(trace("first"), trace("second"))
+	server.Use(trace("third"))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, rpcRequest(t))
+	if got := strings.Join(order, ","); got != "first,second,third,/third,/second,/first" {
+		t.Errorf("Expected middleware from the first call outermost, got %s", got)
+	}
+
+	var resp models.JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("Expected the request to reach the handler, got %+v (%v)", resp, err)
+	}
+
+	order = nil
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux, trace("route"))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/tasks/task-1", nil))
+	if got := strings.Join(order, ","); got != "route,first,second,third,/third,/second,/first,/route" {
+		t.Errorf("Expected route middleware outside Use middleware on the task endpoint, got %s", got)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	server.Use(Logging(logger))
+	r := rpcRequest(t)
+	r.Header.Set(models.HeaderRequestID, "req-1")
+	server.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log entry, got %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"msg":        "request",
+		"method":     "POST",
+		"path":       "/a2a",
+		"status":     float64(http.StatusOK),
+		"rpc_method": "message/send",
+		"rpc_id":     "1",
+		"request_id": "req-1",
+		"task_id":    "task-1",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, entry[key])
+		}
+	}
+	if entry["bytes"].(float64) == 0 {
+		t.Error("Expected the response size to be logged")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	panicking := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		panic("boom")
+	}
+	server := NewA2AServer(mockAgentCard, panicking)
+	server.Use(Recover())
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, rpcRequest(t))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	var resp models.JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != int(models.ErrorCodeInternalError) || resp.ID != "1" {
+		t.Errorf("Expected an internal error for request 1, got %+v", resp)
+	}
+
+	abort := Recover()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to pass through, got %v", v)
+		}
+	}()
+	abort.ServeHTTP(httptest.NewRecorder(), rpcRequest(t))
+}
+
+func TestRequireBearer(t *testing.T) {
+	card := mockAgentCard
+	card.SecuritySchemes = map[string]models.SecurityScheme{
+		"token": {Type: models.SecuritySchemeHTTP, Scheme: "Bearer"},
+		"key":   {Type: models.SecuritySchemeAPIKey, Name: "X-API-Key", In: "header"},
+	}
+	card.Security = []map[string][]string{{"token": {"tasks:write"}}, {"key": nil}}
+
+	verify := func(ctx context.Context, token string) ([]string, error) {
+		switch token {
+		case "writer":
+			return []string{"tasks:read", "tasks:write"}, nil
+		case "reader":
+			return []string{"tasks:read"}, nil
+		}
+		return nil, errors.New("unknown token")
+	}
+
+	tests := []struct {
+		name          string
+		card          models.AgentCard
+		authorization string
+		want          int
+	}{
+		{name: "granted", card: card, authorization: "Bearer writer", want: http.StatusOK},
+		{name: "lowercase scheme", card: card, authorization: "bearer writer", want: http.StatusOK},
+		{name: "missing token", card: card, want: http.StatusUnauthorized},
+		{name: "basic auth", card: card, authorization: "Basic d3JpdGVy", want: http.StatusUnauthorized},
+		{name: "invalid token", card: card, authorization: "Bearer forged", want: http.StatusUnauthorized},
+		{name: "insufficient scope", card: card, authorization: "Bearer reader", want: http.StatusForbidden},
+		{name: "no bearer scheme required", card: mockAgentCard, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewA2AServer(tt.card, mockTaskHandler)
+			server.Use(server.RequireBearer(verify))
+
+			req := rpcRequest(t)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Bearer") {
+				t.Errorf("Expected a Bearer challenge, got %q", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"a2a/models"
+)
+
+// ChatHistoryMetadataKey is the message metadata key carrying the turns of a chat completion
+// request before its last user message, as [{"role": ..., "content": ...}] with text content
+const ChatHistoryMetadataKey = "chatHistory"
+
+// WithChatCompletions serves an OpenAI-compatible chat completion API next to A2A (see
+// ChatCompletionsHandler), so OpenAI client tooling can talk to the agent
+func WithChatCompletions() Option {
+	return func(s *A2AServer) {
+		s.chatCompletions = true
+	}
+}
+
+// ChatCompletionsHandler serves POST /v1/chat/completions by sending the last user message of
+// each chat request to the agent with message/send, or message/stream for "stream": true
+// requests, whose artifacts are streamed as content deltas. The model names the skill to use;
+// models that are not skill IDs use the default handler. GET /v1/models lists the skills.
+// Handlers see the earlier turns under ChatHistoryMetadataKey in the message's metadata.
+func (s *A2AServer) ChatCompletionsHandler() http.Handler {
+	return s.chatHandler
+}
+
+// chatRequest is an OpenAI chat completion request
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// chatMessage is a turn of a chat, whose content is a string or a list of parts
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// chatContentPart is a text or image part of a chat message
+type chatContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+// chatCompletion is a chat completion response, or one chunk of a streamed one
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+}
+
+// chatChoice is the agent's reply, as a message or a streamed delta
+type chatChoice struct {
+	Index        int        `json:"index"`
+	Message      *chatReply `json:"message,omitempty"`
+	Delta        *chatReply `json:"delta,omitempty"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+// chatReply is the content of a chatChoice
+type chatReply struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// serveChat serves the chat completion API
+func (s *A2AServer) serveChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.serveChatModels(w)
+		return
+	}
+	if s.limits.MaxRequestBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.limits.MaxRequestBytes)
+	}
+	var chat chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+		writeChatError(w, http.StatusBadRequest, "invalid_request_error", "Invalid request: "+err.Error())
+		return
+	}
+	params, err := s.chatParams(&chat)
+	if err != nil {
+		writeChatError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	method := "message/send"
+	if chat.Stream {
+		method = "message/stream"
+	}
+	req := models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "chat"},
+		},
+		Method: method,
+		Params: params,
+	}
+	// The agent answers in JSON, or one JSON value per line when streaming
+	r = r.Clone(r.Context())
+	r.Header.Del("Accept")
+	completion := chatCompletion{ID: "chatcmpl-" + params.ID, Created: s.clock.Now().Unix(), Model: chat.Model}
+	if chat.Stream {
+		out := &chatStreamWriter{w: w, header: make(http.Header), completion: completion, server: s, r: r}
+		s.dispatch(out, r, req)
+		out.finish()
+		return
+	}
+
+	out := &batchWriter{header: make(http.Header)}
+	s.dispatch(out, r, req)
+	var response struct {
+		Result models.SendMessageResult `json:"result"`
+		Error  *models.JSONRPCError     `json:"error"`
+	}
+	if err := models.DecodeJSON(out.body.Bytes(), &response); err != nil {
+		writeChatError(w, http.StatusInternalServerError, "server_error", "Invalid agent response")
+		return
+	}
+	if response.Error != nil {
+		writeChatError(w, chatErrorStatus(response.Error.Code), "invalid_request_error", response.Error.Message)
+		return
+	}
+	if task := response.Result.Task; task != nil && task.Status.State == models.TaskStateFailed {
+		writeChatError(w, http.StatusInternalServerError, "server_error", "Task failed: "+replyText(task.Status.Message))
+		return
+	}
+
+	completion.Object = "chat.completion"
+	completion.Choices = []chatChoice{{
+		Message:      &chatReply{Role: "assistant", Content: resultText(response.Result)},
+		FinishReason: stringPtr("stop"),
+	}}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(completion)
+}
+
+// chatParams returns the message/send params of the last user message of chat, which must
+// be its last turn
+func (s *A2AServer) chatParams(chat *chatRequest) (models.MessageSendParams, error) {
+	if len(chat.Messages) == 0 || chat.Messages[len(chat.Messages)-1].Role != "user" {
+		return models.MessageSendParams{}, fmt.Errorf("the last message must be a user message")
+	}
+	last := chat.Messages[len(chat.Messages)-1]
+	parts, err := chatParts(last.Content)
+	if err != nil {
+		return models.MessageSendParams{}, err
+	}
+	message := models.Message{Role: "user", Parts: parts}
+	if len(chat.Messages) > 1 {
+		var history []interface{}
+		for _, turn := range chat.Messages[:len(chat.Messages)-1] {
+			turnParts, err := chatParts(turn.Content)
+			if err != nil {
+				return models.MessageSendParams{}, err
+			}
+			history = append(history, map[string]interface{}{"role": turn.Role, "content": partsText(turnParts)})
+		}
+		message.Metadata = map[string]interface{}{ChatHistoryMetadataKey: history}
+	}
+
+	params := models.MessageSendParams{ID: newTaskID(), Message: message}
+	if _, err := s.route(chat.Model); chat.Model != "" && err == nil {
+		params.Metadata = map[string]interface{}{SkillMetadataKey: chat.Model}
+	}
+	return params, nil
+}
+
+// chatParts converts the content of a chat message, a string or a list of text and image
+// parts, to message parts
+func chatParts(content json.RawMessage) ([]models.Part, error) {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return []models.Part{models.TextPart{Type: "text", Text: text}}, nil
+	}
+	var chatParts []chatContentPart
+	if err := json.Unmarshal(content, &chatParts); err != nil {
+		return nil, fmt.Errorf("invalid message content: %w", err)
+	}
+	var parts []models.Part
+	for _, part := range chatParts {
+		switch {
+		case part.Type == "text":
+			parts = append(parts, models.TextPart{Type: "text", Text: part.Text})
+		case part.Type == "image_url" && part.ImageURL != nil:
+			parts = append(parts, models.FilePart{Type: "file", MimeType: "image/*", Content: models.FileContentURI{Type: "uri", URI: part.ImageURL.URL}})
+		default:
+			return nil, fmt.Errorf("unsupported content part %q", part.Type)
+		}
+	}
+	return parts, nil
+}
+
+// serveChatModels lists the skills as models, or the agent itself when it has none
+func (s *A2AServer) serveChatModels(w http.ResponseWriter) {
+	card := s.AgentCard()
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		OwnedBy string `json:"owned_by"`
+	}
+	list := struct {
+		Object string  `json:"object"`
+		Data   []model `json:"data"`
+	}{Object: "list", Data: []model{}}
+	for _, skill := range card.Skills {
+		list.Data = append(list.Data, model{ID: skill.ID, Object: "model", OwnedBy: card.Name})
+	}
+	if len(list.Data) == 0 {
+		list.Data = append(list.Data, model{ID: card.Name, Object: "model", OwnedBy: card.Name})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// resultText returns the reply of a message/send result: the text of the task's artifacts, or
+// of its status message when it has none, or of the agent's message
+func resultText(result models.SendMessageResult) string {
+	if result.Message != nil {
+		return partsText(result.Message.Parts)
+	}
+	if result.Task == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, artifact := range result.Task.Artifacts {
+		b.WriteString(partsText(artifact.Parts))
+	}
+	if b.Len() == 0 {
+		return replyText(result.Task.Status.Message)
+	}
+	return b.String()
+}
+
+// replyText returns the text of message, which may be nil
+func replyText(message *models.Message) string {
+	if message == nil {
+		return ""
+	}
+	return partsText(message.Parts)
+}
+
+// partsText joins the text parts of parts
+func partsText(parts []models.Part) string {
+	var b strings.Builder
+	for _, part := range parts {
+		if text, ok := part.(models.TextPart); ok {
+			b.WriteString(text.Text)
+		}
+	}
+	return b.String()
+}
+
+// chatErrorStatus maps a JSON-RPC error code to the HTTP status of a chat completion error
+func chatErrorStatus(code int) int {
+	switch models.ErrorCode(code) {
+	case models.ErrorCodeInvalidParams, models.ErrorCodeInvalidRequest, models.ErrorCodeUnsupportedOperation,
+		models.ErrorCodeContentTypeNotSupported:
+		return http.StatusBadRequest
+	case models.ErrorCodeTaskNotFound:
+		return http.StatusNotFound
+	}
+	if code == int(errServerBusy().Code) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// writeChatError writes an OpenAI error response
+func writeChatError(w http.ResponseWriter, status int, kind, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": kind},
+	})
+}
+
+// chatStreamWriter is the http.ResponseWriter of a message/stream request made for a streamed
+// chat completion. It converts the agent's events, one JSON value per line, to completion
+// chunks sent to w as Server-Sent Events.
+type chatStreamWriter struct {
+	w          http.ResponseWriter
+	header     http.Header
+	completion chatCompletion
+	server     *A2AServer
+	r          *http.Request
+
+	pending  bytes.Buffer
+	started  bool
+	streamed bool
+	done     bool
+}
+
+// Header implements http.ResponseWriter
+func (c *chatStreamWriter) Header() http.Header {
+	return c.header
+}
+
+// WriteHeader implements http.ResponseWriter; errors are reported by their JSON-RPC body
+func (c *chatStreamWriter) WriteHeader(int) {}
+
+// Write implements http.ResponseWriter, converting each complete line written
+func (c *chatStreamWriter) Write(p []byte) (int, error) {
+	c.pending.Write(p)
+	for {
+		line, err := c.pending.ReadBytes('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write
+			rest := append([]byte(nil), line...)
+			c.pending.Reset()
+			c.pending.Write(rest)
+			return len(p), nil
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			c.event(line)
+		}
+	}
+}
+
+// Flush implements http.Flusher
+func (c *chatStreamWriter) Flush() {
+	if flusher, ok := c.w.(http.Flusher); ok && c.started {
+		flusher.Flush()
+	}
+}
+
+// event converts one JSON-RPC response of the stream
+func (c *chatStreamWriter) event(line []byte) {
+	var response struct {
+		Result json.RawMessage      `json:"result"`
+		Error  *models.JSONRPCError `json:"error"`
+	}
+	if c.done || json.Unmarshal(line, &response) != nil {
+		return
+	}
+	if response.Error != nil {
+		if !c.started {
+			c.done = true
+			writeChatError(c.w, chatErrorStatus(response.Error.Code), "invalid_request_error", response.Error.Message)
+		}
+		return
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(response.Result, &fields)
+	if _, ok := fields["artifact"]; ok {
+		var update models.TaskArtifactUpdateEvent
+		if models.DecodeJSON(response.Result, &update) == nil {
+			if text := partsText(update.Artifact.Parts); text != "" {
+				c.streamed = true
+				c.chunk(chatReply{Content: text}, nil)
+			}
+		}
+		return
+	}
+	var event models.TaskStatusUpdateEvent
+	if models.DecodeJSON(response.Result, &event) != nil || event.Final == nil || !*event.Final {
+		return
+	}
+	if !c.streamed {
+		// Output the handler did not stream is in the stored task
+		c.server.mu.RLock()
+		task, err := c.server.store.Get(c.r.Context(), event.ID)
+		c.server.mu.RUnlock()
+		if err == nil {
+			c.chunk(chatReply{Content: resultText(models.SendMessageResult{Task: task})}, nil)
+		}
+	}
+	reason := "stop"
+	if event.Status.State == models.TaskStateFailed {
+		reason = "error"
+	}
+	c.chunk(chatReply{}, &reason)
+	c.done = true
+}
+
+// chunk sends delta as a completion chunk, starting the event stream on the first
+func (c *chatStreamWriter) chunk(delta chatReply, finishReason *string) {
+	if !c.started {
+		c.started = true
+		c.w.Header().Set("Content-Type", "text/event-stream")
+		c.w.Header().Set("Cache-Control", "no-cache")
+		delta.Role = "assistant"
+	}
+	completion := c.completion
+	completion.Object = "chat.completion.chunk"
+	completion.Choices = []chatChoice{{Delta: &delta, FinishReason: finishReason}}
+	data, _ := json.Marshal(completion)
+	fmt.Fprintf(c.w, "data: %s\n\n", data)
+	c.Flush()
+}
+
+// finish ends the event stream once the agent's stream has ended
+func (c *chatStreamWriter) finish() {
+	if c.started {
+		fmt.Fprint(c.w, "data: [DONE]\n\n")
+		c.Flush()
+	} else if !c.done {
+		writeChatError(c.w, http.StatusInternalServerError, "server_error", "The agent ended the stream without an answer")
+	}
+}
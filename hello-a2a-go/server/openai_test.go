@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_ChatCompletions(t *testing.T) {
+	var history []interface{}
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		history, _ = message.Metadata[ChatHistoryMetadataKey].([]interface{})
+		text := partsText(message.Parts)
+		if text == "stream" {
+			for i, chunk := range []string{"Hel", "lo"} {
+				EmitArtifact(ctx, textChunk(0, chunk, i > 0, i == 1))
+			}
+		}
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.TextPart{Type: "text", Text: "echo: " + text}}}}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithChatCompletions())
+	if err := server.AddSkill(models.AgentSkill{ID: "shout", Name: "Shout"}, replyWith("HELLO")); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(body string) *http.Response {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	complete := func(body string) string {
+		t.Helper()
+		resp := post(body)
+		defer resp.Body.Close()
+		var completion chatCompletion
+		if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Failed to complete: %d (%v)", resp.StatusCode, err)
+		}
+		if completion.Object != "chat.completion" || len(completion.Choices) != 1 || *completion.Choices[0].FinishReason != "stop" {
+			t.Errorf("Unexpected completion %+v", completion)
+		}
+		return completion.Choices[0].Message.Content
+	}
+
+	content := complete(`{"model":"agent","messages":[{"role":"system","content":"Be brief"},{"role":"user","content":[{"type":"text","text":"Hi"}]}]}`)
+	if content != "echo: Hi" {
+		t.Errorf("Expected the agent's artifact, got %q", content)
+	}
+	if len(history) != 1 || history[0].(map[string]interface{})["content"] != "Be brief" {
+		t.Errorf("Expected the earlier turns in the message metadata, got %v", history)
+	}
+	if content := complete(`{"model":"shout","messages":[{"role":"user","content":"Hi"}]}`); content != "HELLO" {
+		t.Errorf("Expected the model to select the skill, got %q", content)
+	}
+
+	// Streamed completions send artifact chunks, or the final output, as deltas
+	stream := func(text string) string {
+		t.Helper()
+		resp := post(`{"model":"agent","stream":true,"messages":[{"role":"user","content":"` + text + `"}]}`)
+		defer resp.Body.Close()
+		if resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %s", resp.Header.Get("Content-Type"))
+		}
+		var deltas []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			if data == "[DONE]" {
+				return strings.Join(deltas, "|")
+			}
+			var chunk chatCompletion
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				t.Fatalf("Failed to decode chunk: %v", err)
+			}
+			deltas = append(deltas, chunk.Choices[0].Delta.Content)
+		}
+		t.Fatal("Expected the stream to end with [DONE]")
+		return ""
+	}
+	if deltas := stream("stream"); deltas != "Hel|lo|" {
+		t.Errorf("Expected the streamed chunks then the finish, got %q", deltas)
+	}
+	if deltas := stream("Hi"); deltas != "echo: Hi|" {
+		t.Errorf("Expected the final output then the finish, got %q", deltas)
+	}
+
+	resp := post(`{"model":"agent","messages":[{"role":"assistant","content":"Hi"}]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a chat not ending with a user message, got %d", resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/v1/models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	if len(list.Data) != 2 || list.Data[0].ID != "test-skill" || list.Data[1].ID != "shout" {
+		t.Errorf("Expected the skills as models, got %+v", list.Data)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import "a2a/clock"
+
+// Option configures optional A2AServer behavior
+type Option func(*A2AServer)
+
+// WithMaxRequestBytes limits the size of JSON-RPC request bodies; zero means unlimited
+func WithMaxRequestBytes(n int64) Option {
+	return func(s *A2AServer) {
+		s.limits.MaxRequestBytes = n
+	}
+}
+
+// WithMaxEventBytes limits the size of each streamed event; a larger event ends the stream with
+// an error event instead. Zero means unlimited.
+func WithMaxEventBytes(n int64) Option {
+	return func(s *A2AServer) {
+		s.limits.MaxEventBytes = n
+	}
+}
+
+// WithMaxBatchRequests limits the number of requests in a JSON-RPC batch; larger batches are
+// rejected as invalid requests. Zero means unlimited.
+func WithMaxBatchRequests(n int) Option {
+	return func(s *A2AServer) {
+		s.limits.MaxBatchRequests = n
+	}
+}
+
+// WithClock sets the clock used for task timestamps, quota windows, replay windows, usage
+// timing and injected latency; the default is clock.Real. Tests pass a *clock.Fake.
+func WithClock(c clock.Clock) Option {
+	return func(s *A2AServer) {
+		s.clock = c
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"a2a/models"
+)
+
+// outputModeContextKey is the context key for the output mode negotiated for a request
+type outputModeContextKey struct{}
+
+// OutputModeFromContext returns the output MIME type negotiated for the request between the
+// client's accepted output modes and those of the skill it is addressed to, or of the agent
+// card for messages naming no skill, so that a handler can answer with text, JSON or file
+// artifacts accordingly. It returns "" when neither side names a mode.
+func OutputModeFromContext(ctx context.Context) string {
+	mode, _ := ctx.Value(outputModeContextKey{}).(string)
+	return mode
+}
+
+// checkOutputModes answers with a content type not supported error and reports false when
+// none of the output modes the client accepts in params is one the agent produces
+func (s *A2AServer) checkOutputModes(w http.ResponseWriter, id interface{}, params models.TaskSendParams) bool {
+	if _, ok := s.outputMode(params); ok {
+		return true
+	}
+	s.sendA2AError(w, id, models.NewContentTypeNotSupportedError(
+		"None of the accepted output modes "+strings.Join(params.AcceptedOutputModes, ", ")+" is supported"))
+	return false
+}
+
+// outputMode negotiates the output mode of the request params, reporting false when the
+// client accepts none of the agent's modes
+func (s *A2AServer) outputMode(params models.TaskSendParams) (string, bool) {
+	skillID, _ := params.Metadata[SkillMetadataKey].(string)
+	return negotiateOutputMode(s.outputModes(skillID), params.AcceptedOutputModes)
+}
+
+// outputModes returns the output modes of the skill skillID on the served card, falling back
+// to the card's default output modes
+func (s *A2AServer) outputModes(skillID string) []string {
+	s.skillsMu.RLock()
+	defer s.skillsMu.RUnlock()
+	for _, skill := range s.agentCard.Skills {
+		if skill.ID == skillID && skillID != "" && len(skill.OutputModes) > 0 {
+			return skill.OutputModes
+		}
+	}
+	return s.agentCard.DefaultOutputModes
+}
+
+// negotiateOutputMode returns the first mode of accepted, in the client's order of preference,
+// matching one of the agent's modes, as the more specific of the two. An empty side accepts
+// any mode of the other.
+func negotiateOutputMode(modes, accepted []string) (string, bool) {
+	switch {
+	case len(accepted) == 0 && len(modes) == 0:
+		return "", true
+	case len(accepted) == 0:
+		return modes[0], true
+	case len(modes) == 0:
+		return accepted[0], true
+	}
+	for _, want := range accepted {
+		for _, mode := range modes {
+			if match := matchOutputMode(want, mode); match != "" {
+				return match, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchOutputMode returns the more specific of modes a and b when they match, or "". Modes
+// may be MIME types, wildcards such as "image/*" or part kinds such as "text".
+func matchOutputMode(a, b string) string {
+	a, _, _ = strings.Cut(a, ";")
+	b, _, _ = strings.Cut(b, ";")
+	switch {
+	case strings.EqualFold(a, b):
+		return a
+	case a == "*/*" || a == "*":
+		return b
+	case b == "*/*" || b == "*":
+		return a
+	}
+	for _, pair := range [][2]string{{a, b}, {b, a}} {
+		general, specific := pair[0], pair[1]
+		if prefix, wildcard := strings.CutSuffix(general, "*"); wildcard && strings.HasPrefix(specific, prefix) {
+			return specific
+		}
+		if strings.Contains(specific, "/") && general == mimeKind(specific) {
+			return specific
+		}
+	}
+	return ""
+}
+
+// mimeKind returns the kind of the part carrying content of mimeType
+func mimeKind(mimeType string) string {
+	switch mimeType {
+	case "text/plain":
+		return "text"
+	case "application/json":
+		return "data"
+	}
+	return "file"
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestNegotiateOutputMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		modes    []string
+		accepted []string
+		want     string
+		wantOK   bool
+	}{
+		{name: "no preference", modes: []string{"text/plain", "application/json"}, want: "text/plain", wantOK: true},
+		{name: "no agent modes", accepted: []string{"text/csv"}, want: "text/csv", wantOK: true},
+		{name: "client order", modes: []string{"text/plain", "application/json"}, accepted: []string{"application/json", "text/plain"}, want: "application/json", wantOK: true},
+		{name: "client wildcard", modes: []string{"image/png"}, accepted: []string{"image/*"}, want: "image/png", wantOK: true},
+		{name: "agent wildcard", modes: []string{"*/*"}, accepted: []string{"text/markdown"}, want: "text/markdown", wantOK: true},
+		{name: "part kind", modes: []string{"data"}, accepted: []string{"application/json"}, want: "application/json", wantOK: true},
+		{name: "parameters", modes: []string{"text/plain"}, accepted: []string{"text/plain; charset=utf-8"}, want: "text/plain", wantOK: true},
+		{name: "incompatible", modes: []string{"text/plain"}, accepted: []string{"image/png", "application/json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := negotiateOutputMode(tt.modes, tt.accepted)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Expected %q, %v, got %q, %v", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestA2AServer_NegotiatesOutputMode(t *testing.T) {
+	card := mockAgentCard
+	card.DefaultOutputModes = []string{"text/plain"}
+	card.Skills = []models.AgentSkill{{ID: "report", Name: "Report", OutputModes: []string{"text/csv", "application/json"}}}
+	var got string
+	server := NewA2AServer(card, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		got = OutputModeFromContext(ctx)
+		return mockTaskHandler(ctx, task, message)
+	})
+	send := func(method string, skillID string, accepted ...string) models.JSONRPCResponse {
+		params := map[string]interface{}{
+			"id":            "report-1",
+			"message":       models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Report"}}},
+			"configuration": map[string]interface{}{"acceptedOutputModes": accepted},
+		}
+		if skillID != "" {
+			params["metadata"] = map[string]interface{}{SkillMetadataKey: skillID}
+		}
+		return doRPC(t, server, method, params)
+	}
+
+	// The handler sees the skill's mode the client prefers, sent in the spec's configuration
+	if response := send("message/send", "report", "application/json", "text/csv"); response.Error != nil || got != "application/json" {
+		t.Errorf("Expected application/json to be negotiated, got %q, %v", got, response.Error)
+	}
+
+	// Without a preference the skill's first mode is used, and the card's without a skill
+	if send("message/send", "report"); got != "text/csv" {
+		t.Errorf("Expected the skill's first mode, got %q", got)
+	}
+	if send("message/send", "", "text/*"); got != "text/plain" {
+		t.Errorf("Expected the card's default mode, got %q", got)
+	}
+
+	for _, method := range []string{"message/send", "message/stream"} {
+		response := send(method, "report", "image/png")
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeContentTypeNotSupported) {
+			t.Errorf("Expected %s to reject incompatible output modes, got %+v", method, response)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+
+	"a2a/models"
+)
+
+// PriorityMetadataKey is the message or request metadata key holding the integer priority of
+// a task on the worker pool; higher priorities run first, and tasks without one have priority 0
+const PriorityMetadataKey = "priority"
+
+// WithWorkerPool runs task handlers on workers goroutines instead of the requests' own,
+// queueing up to queueSize further tasks. A new task is saved submitted while it waits and
+// becomes working once a worker picks it up; messages to a task with a queued or running
+// message wait behind it, so each continues the task the previous one left. Queued tasks run
+// by priority (see PriorityMetadataKey), then in arrival order. Requests finding the queue full
+// fail with ErrorCodeServerBusy. message/send still answers with the finished task, but the task
+// runs on even if the client disconnects first.
+func WithWorkerPool(workers, queueSize int) Option {
+	return func(s *A2AServer) {
+		s.pool = newWorkerPool(workers, queueSize)
+	}
+}
+
+// WithSkillConcurrency limits the handlers of each skill in limits, by skill ID, that the worker
+// pool runs at once, so that an expensive skill cannot take every worker: tasks of a skill at its
+// limit stay queued while workers run those of other skills. Skills without a limit may use every
+// worker. It has no effect without WithWorkerPool.
+func WithSkillConcurrency(limits map[string]int) Option {
+	return func(s *A2AServer) {
+		s.skillLimits = maps.Clone(limits)
+	}
+}
+
+// workerPool runs jobs on a fixed number of goroutines, one job per task at a time, highest
+// priority first
+type workerPool struct {
+	size    int
+	workers int
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// ready holds the jobs of tasks with no job running, ordered by priority then arrival
+	ready []*poolJob
+	// waiting holds, for each task with a queued or running job, the jobs queued behind it
+	waiting map[string][]*poolJob
+	// queued counts the jobs not yet picked up, ready or waiting behind their task, and
+	// queuedByTask counts them per task
+	queued       int
+	queuedByTask map[string]int
+	// busy counts the workers running a job, and running counts them by skill
+	busy    int
+	limits  map[string]int
+	running map[string]int
+	// seq numbers jobs in arrival order
+	seq uint64
+}
+
+// poolJob is a queued run of a task's handler
+type poolJob struct {
+	taskID   string
+	skill    string
+	priority int64
+	seq      uint64
+	run      func()
+}
+
+// newWorkerPool starts workers goroutines serving a queue of queueSize jobs, each at least 1
+func newWorkerPool(workers, queueSize int) *workerPool {
+	workers, queueSize = max(workers, 1), max(queueSize, 1)
+	p := &workerPool{
+		size:         queueSize,
+		workers:      workers,
+		waiting:      make(map[string][]*poolJob),
+		queuedByTask: make(map[string]int),
+		running:      make(map[string]int),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	for range workers {
+		go p.work()
+	}
+	return p
+}
+
+// setLimits limits the jobs of each skill in limits running at once
+func (p *workerPool) setLimits(limits map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limits = limits
+}
+
+// submit queues run behind the queued and running jobs of taskID, reporting false when the
+// queue is full
+func (p *workerPool) submit(taskID, skill string, priority int64, run func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queued >= p.size {
+		return false
+	}
+	p.queued++
+	p.queuedByTask[taskID]++
+	p.seq++
+	job := &poolJob{taskID: taskID, skill: skill, priority: priority, seq: p.seq, run: run}
+	if waiting, busy := p.waiting[taskID]; busy {
+		p.waiting[taskID] = append(waiting, job)
+		return true
+	}
+	p.waiting[taskID] = nil
+	p.push(job)
+	return true
+}
+
+// push adds job to the ready jobs in order and wakes a worker; the caller holds p.mu
+func (p *workerPool) push(job *poolJob) {
+	i, _ := slices.BinarySearchFunc(p.ready, job, func(queued, job *poolJob) int {
+		if queued.priority != job.priority {
+			return cmp.Compare(job.priority, queued.priority)
+		}
+		return cmp.Compare(queued.seq, job.seq)
+	})
+	p.ready = slices.Insert(p.ready, i, job)
+	p.cond.Signal()
+}
+
+// next removes and returns the first ready job whose skill is below its limit, or nil if there
+// is none; the caller holds p.mu
+func (p *workerPool) next() *poolJob {
+	for i, job := range p.ready {
+		if limit, ok := p.limits[job.skill]; ok && p.running[job.skill] >= limit {
+			continue
+		}
+		p.ready = slices.Delete(p.ready, i, i+1)
+		return job
+	}
+	return nil
+}
+
+// work runs queued jobs, releasing the next job of a task once its previous one returns
+func (p *workerPool) work() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		job := p.next()
+		if job == nil {
+			p.cond.Wait()
+			continue
+		}
+		p.queued--
+		if p.queuedByTask[job.taskID]--; p.queuedByTask[job.taskID] == 0 {
+			delete(p.queuedByTask, job.taskID)
+		}
+		p.busy++
+		p.running[job.skill]++
+		p.mu.Unlock()
+
+		job.run()
+
+		p.mu.Lock()
+		p.busy--
+		if p.running[job.skill]--; p.running[job.skill] == 0 {
+			delete(p.running, job.skill)
+		}
+		if waiting := p.waiting[job.taskID]; len(waiting) > 0 {
+			p.waiting[job.taskID] = waiting[1:]
+			p.push(waiting[0])
+		} else {
+			delete(p.waiting, job.taskID)
+		}
+		// A job of the skill may have been held back by its limit
+		p.cond.Broadcast()
+	}
+}
+
+// PoolStats reports the load of the worker pool
+type PoolStats struct {
+	Workers int `json:"workers"`
+	// Busy is the number of workers running a handler
+	Busy int `json:"busy"`
+	// Queued is the number of messages waiting for a worker, out of at most QueueSize
+	Queued    int `json:"queued"`
+	QueueSize int `json:"queueSize"`
+	// Utilization is the share of busy workers, from 0 to 1
+	Utilization float64 `json:"utilization"`
+	// Skills is the number of workers running each skill named by the tasks' metadata
+	Skills map[string]int `json:"skills,omitempty"`
+}
+
+// stats returns the pool's current load
+func (p *workerPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		Workers:     p.workers,
+		Busy:        p.busy,
+		Queued:      p.queued,
+		QueueSize:   p.size,
+		Utilization: float64(p.busy) / float64(p.workers),
+		Skills:      skillCounts(p.running),
+	}
+}
+
+// skillCounts returns the counts of running by named skill, or nil if there are none
+func skillCounts(running map[string]int) map[string]int {
+	var counts map[string]int
+	for skill, n := range running {
+		if skill == "" {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		counts[skill] = n
+	}
+	return counts
+}
+
+// queuedTasks returns the number of queued messages of each task with any
+func (p *workerPool) queuedTasks() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.queuedByTask)
+}
+
+// errServerBusy reports a request rejected because the worker pool's queue is full
+func errServerBusy() *models.A2AError {
+	return models.NewA2AError(models.ErrorCodeServerBusy, "Server busy: task queue is full")
+}
+
+// enqueueTask queues run on the worker pool for the task of params, stamping its message, or
+// starts it at once on a server without one. A task new to the store is saved submitted, and run
+// is told so in order to check that the task was not canceled while queued. It reports false,
+// saving nothing, when the queue is full.
+func (s *A2AServer) enqueueTask(ctx context.Context, params *models.TaskSendParams, run func(submitted bool)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var submitted *models.Task
+	if _, err := s.store.Get(ctx, params.ID); errors.Is(err, ErrTaskNotFound) {
+		submitted = s.newTask(ctx, params)
+		submitted.Status.State = models.TaskStateSubmitted
+		// The message joins the history once the task starts
+		submitted.History = nil
+	}
+	isNew := submitted != nil
+	job := func() { run(isNew) }
+	if s.pool == nil {
+		go job()
+	} else if !s.pool.submit(params.ID, poolSkill(params), poolPriority(params), job) {
+		return false
+	}
+	// The job waits for s.mu, so it sees the submitted task
+	if isNew {
+		if err := s.saveTask(ctx, submitted); err != nil {
+			s.log(ctx, submitted.ID).Error("failed to store task", slog.Any("error", err))
+		}
+	}
+	return true
+}
+
+// poolSkill returns the skill the task of params is addressed to, as named by its request or
+// message metadata or a routing data part
+func poolSkill(params *models.TaskSendParams) string {
+	if skillID, _ := params.Metadata[SkillMetadataKey].(string); skillID != "" {
+		return skillID
+	}
+	return skillOf(context.Background(), &params.Message)
+}
+
+// poolPriority returns the priority of the task of params, from its message's metadata or else
+// its request's
+func poolPriority(params *models.TaskSendParams) int64 {
+	if priority, ok := models.MetadataInt64(params.Message.Metadata, PriorityMetadataKey); ok {
+		return priority
+	}
+	priority, _ := models.MetadataInt64(params.Metadata, PriorityMetadataKey)
+	return priority
+}
+
+// canceledWhileQueued returns the task saved submitted for taskID if it was canceled before a
+// worker picked it up; the caller holds s.mu
+func (s *A2AServer) canceledWhileQueued(ctx context.Context, taskID string) *models.Task {
+	task, err := s.store.Get(ctx, taskID)
+	if err != nil || task.Status.State != models.TaskStateCanceled {
+		return nil
+	}
+	return task
+}
+
+// sendQueuedTask answers message/send and tasks/send on a server with a worker pool: the task
+// is queued and the request waits for its result
+func (s *A2AServer) sendQueuedTask(w http.ResponseWriter, r *http.Request, id interface{}, params models.TaskSendParams, handler TaskHandler) {
+	type outcome struct {
+		task *models.Task
+		err  *models.A2AError
+	}
+	done := make(chan outcome, 1)
+	// The task outlives the connection, so a client can fetch its result with tasks/get
+	hr := r.WithContext(context.WithoutCancel(r.Context()))
+	if !s.enqueueTask(hr.Context(), &params, func(submitted bool) {
+		task, err := s.runQueuedTask(hr, params, handler, submitted)
+		done <- outcome{task: task, err: err}
+	}) {
+		s.sendA2AError(w, id, errServerBusy())
+		return
+	}
+
+	select {
+	case out := <-done:
+		if out.err != nil {
+			s.sendA2AError(w, id, out.err)
+			return
+		}
+		s.sendResponseWithID(w, id, sendResult(r, out.task, params.HistoryLength))
+	case <-r.Context().Done():
+		// Client disconnected
+	}
+}
+
+// runQueuedTask runs the task of params once a worker picks it up, moving it from submitted to
+// working, and stores its result. Unlike inline tasks, s.mu is held only around store access,
+// so handlers run concurrently.
+func (s *A2AServer) runQueuedTask(r *http.Request, params models.TaskSendParams, handler TaskHandler, submitted bool) (*models.Task, *models.A2AError) {
+	ctx := r.Context()
+	s.mu.Lock()
+	if submitted {
+		if canceled := s.canceledWhileQueued(ctx, params.ID); canceled != nil {
+			s.mu.Unlock()
+			return canceled, nil
+		}
+	}
+	task := s.newTask(ctx, &params)
+	err := s.saveTask(ctx, task)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, models.NewInternalError(err.Error())
+	}
+
+	updatedTask, err := s.runHandler(r, params, handler, task)
+	if errors.Is(err, ErrTaskPurged) {
+		s.notify(task.ID, purgedEvent(task))
+		return nil, handlerError(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failTask(ctx, task, err)
+		return nil, handlerError(err)
+	}
+	if err := s.storeTask(ctx, updatedTask, &params.Message); err != nil {
+		return nil, models.NewInternalError(err.Error())
+	}
+	s.notifyResult(ctx, updatedTask)
+	return updatedTask, nil
+}
+
+// enqueueStreamingTask queues the task of a streaming request on the worker pool, publishing
+// its submitted status to stream while it waits, and reports false when the queue is full
+func (s *A2AServer) enqueueStreamingTask(r *http.Request, params models.TaskSendParams, handler TaskHandler, stream *taskStream) bool {
+	// Published first, as the job may start as soon as it is queued; a rejected stream is
+	// discarded unread
+	s.publishEvent(r.Context(), stream, models.TaskStatusUpdateEvent{
+		ID:     params.ID,
+		Status: models.TaskStatus{State: models.TaskStateSubmitted},
+		Final:  boolPtr(false),
+	})
+	return s.enqueueTask(r.Context(), &params, func(submitted bool) {
+		s.runStreamingTask(r, params, handler, stream, submitted)
+	})
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// gatedHandler reports each message it starts on started and completes its task once release
+// is closed, counting the handlers running at once in peak
+func gatedHandler(started chan<- string, release <-chan struct{}, peak *atomic.Int32) TaskHandler {
+	var running atomic.Int32
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		started <- message.Parts[0].(models.TextPart).Text
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+}
+
+// sendAsync sends text to the task id with message/send, delivering the response on the
+// returned channel
+func sendAsync(t *testing.T, server *A2AServer, id, text string) <-chan models.JSONRPCResponse {
+	sent := make(chan models.JSONRPCResponse, 1)
+	go func() {
+		sent <- doRPC(t, server, "message/send", models.MessageSendParams{
+			ID:      id,
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}},
+		})
+	}()
+	return sent
+}
+
+// waitForState waits for the stored task id to reach state
+func waitForState(t *testing.T, server *A2AServer, id string, state models.TaskState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, err := server.store.Get(context.Background(), id); err == nil && task.Status.State == state {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Task %s never reached %s", id, state)
+}
+
+func TestWorkerPool_BoundsConcurrency(t *testing.T) {
+	started, release := make(chan string, 3), make(chan struct{})
+	var peak atomic.Int32
+	server := NewA2AServer(mockAgentCard, gatedHandler(started, release, &peak), WithWorkerPool(2, 1))
+
+	// Each task starts before the next is sent, as the queue holds one task not yet picked up
+	first := sendAsync(t, server, "a", "a")
+	<-started
+	second := sendAsync(t, server, "b", "b")
+	<-started
+	third := sendAsync(t, server, "c", "c")
+	waitForState(t, server, "c", models.TaskStateSubmitted)
+
+	// Two tasks run and one waits, so the queue is full
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "d",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "d"}}},
+	})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeServerBusy) {
+		t.Errorf("Expected a full queue to reject the task, got %+v", response)
+	}
+	if _, err := server.store.Get(context.Background(), "d"); err == nil {
+		t.Error("Expected the rejected task not to be stored")
+	}
+
+	close(release)
+	for _, sent := range []<-chan models.JSONRPCResponse{first, second, third} {
+		response := <-sent
+		var task models.Task
+		decodeResult(t, response.Result, &task)
+		if response.Error != nil || task.Status.State != models.TaskStateCompleted {
+			t.Errorf("Expected a completed task, got %+v (%v)", task, response.Error)
+		}
+	}
+	if peak.Load() != 2 {
+		t.Errorf("Expected 2 handlers to run at once, got %d", peak.Load())
+	}
+}
+
+func TestWorkerPool_QueuesMessagesPerTask(t *testing.T) {
+	started, release := make(chan string, 2), make(chan struct{})
+	var peak atomic.Int32
+	server := NewA2AServer(mockAgentCard, gatedHandler(started, release, &peak), WithWorkerPool(2, 2))
+
+	first := sendAsync(t, server, "chat", "one")
+	if got := <-started; got != "one" {
+		t.Fatalf("Expected the first message to start, got %q", got)
+	}
+	second := sendAsync(t, server, "chat", "two")
+	select {
+	case got := <-started:
+		t.Fatalf("Expected %q to wait for the task's running message", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-first
+	if got := <-started; got != "two" {
+		t.Fatalf("Expected the second message to start next, got %q", got)
+	}
+	response := <-second
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if len(task.History) != 2 || task.History[1].Parts[0].(models.TextPart).Text != "two" {
+		t.Errorf("Expected the second message to continue the task, got history %+v", task.History)
+	}
+	if peak.Load() != 1 {
+		t.Errorf("Expected the task's messages to run one at a time, got %d at once", peak.Load())
+	}
+}
+
+func TestWorkerPool_CancelQueuedTask(t *testing.T) {
+	started, release := make(chan string, 2), make(chan struct{})
+	var peak atomic.Int32
+	server := NewA2AServer(mockAgentCard, gatedHandler(started, release, &peak), WithWorkerPool(1, 1))
+
+	first := sendAsync(t, server, "running", "running")
+	<-started
+	queued := sendAsync(t, server, "queued", "queued")
+	waitForState(t, server, "queued", models.TaskStateSubmitted)
+
+	if response := doRPC(t, server, "tasks/cancel", models.TaskIDParams{ID: "queued"}); response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	close(release)
+	<-first
+
+	response := <-queued
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if response.Error != nil || task.Status.State != models.TaskStateCanceled {
+		t.Errorf("Expected the task canceled while queued, got %+v (%v)", task, response.Error)
+	}
+	select {
+	case got := <-started:
+		t.Errorf("Expected the canceled task not to run, but %q started", got)
+	default:
+	}
+}
+
+func TestWorkerPool_Streaming(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithWorkerPool(1, 1))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "streamed", "")
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	for _, state := range []string{"submitted", "working", "completed"} {
+		if frame := readFrame(t, reader); !strings.Contains(frame, `"state":"`+state+`"`) {
+			t.Errorf("Expected a %s event, got %q", state, frame)
+		}
+	}
+}
+
+func TestWorkerPool_Priority(t *testing.T) {
+	pool := newWorkerPool(1, 5)
+	release := make(chan struct{})
+	started := make(chan string, 5)
+	job := func(name string) func() {
+		return func() {
+			started <- name
+			<-release
+		}
+	}
+	pool.submit("busy", "", 0, job("busy"))
+	<-started
+	// Queued while the only worker is busy, they run by priority and then in arrival order
+	pool.submit("low", "", 0, job("low"))
+	pool.submit("high", "", 5, job("high"))
+	pool.submit("mid", "", 1, job("mid"))
+	pool.submit("high2", "", 5, job("high2"))
+	close(release)
+
+	var order []string
+	for range 4 {
+		order = append(order, <-started)
+	}
+	if strings.Join(order, ",") != "high,high2,mid,low" {
+		t.Errorf("Expected tasks to run by priority, got %v", order)
+	}
+}
+
+func TestWorkerPool_SkillConcurrency(t *testing.T) {
+	started, release := make(chan string, 3), make(chan struct{})
+	var peak atomic.Int32
+	card := mockAgentCard
+	card.Skills = []models.AgentSkill{{ID: "summarize", Name: "Summarize"}, {ID: "translate", Name: "Translate"}}
+	server := NewA2AServer(card, gatedHandler(started, release, &peak), WithWorkerPool(3, 3),
+		WithSkillConcurrency(map[string]int{"summarize": 1}))
+	send := func(id, skill string) <-chan models.JSONRPCResponse {
+		sent := make(chan models.JSONRPCResponse, 1)
+		go func() {
+			sent <- doRPC(t, server, "message/send", models.MessageSendParams{
+				ID:       id,
+				Message:  models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: id}}},
+				Metadata: map[string]interface{}{SkillMetadataKey: skill},
+			})
+		}()
+		return sent
+	}
+
+	first := send("long", "summarize")
+	<-started
+	second := send("longer", "summarize")
+	waitForState(t, server, "longer", models.TaskStateSubmitted)
+	// A worker is free for other skills while the summaries wait for each other
+	third := send("quick", "translate")
+	if got := <-started; got != "quick" {
+		t.Fatalf("Expected the other skill to start, got %q", got)
+	}
+	if stats := server.pool.stats(); stats.Busy != 2 || stats.Queued != 1 || stats.Skills["summarize"] != 1 {
+		t.Errorf("Expected one summary running and one queued, got %+v", stats)
+	}
+
+	close(release)
+	for _, sent := range []<-chan models.JSONRPCResponse{first, second, third} {
+		if response := <-sent; response.Error != nil {
+			t.Errorf("Expected the task to complete, got %v", response.Error)
+		}
+	}
+	if got := <-started; got != "longer" {
+		t.Errorf("Expected the second summary to run last, got %q", got)
+	}
+}
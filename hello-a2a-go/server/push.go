@@ -0,0 +1,361 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// Push notification JSON-RPC methods
+const (
+	SetPushNotificationMethod = "tasks/pushNotificationConfig/set"
+	GetPushNotificationMethod = "tasks/pushNotificationConfig/get"
+)
+
+// defaultPushTimeout bounds each webhook delivery attempt
+const defaultPushTimeout = 10 * time.Second
+
+// pushAttempts is how many times an event is posted to a failing webhook
+const pushAttempts = 3
+
+// pushBackoff is the wait before the first retry of a failed delivery, doubled on each retry
+const pushBackoff = time.Second
+
+// ErrPushDenied is returned for webhooks on addresses the server does not post to
+var ErrPushDenied = errors.New("push notification URL not allowed")
+
+// maxPendingPushes bounds the events queued for one webhook; the oldest are dropped first
+const maxPendingPushes = 64
+
+// WithPushNotifications enables tasks/pushNotificationConfig/set and /get and posts each
+// task's status and artifact updates to its registered webhook with client. nil uses a client
+// with a 10 second timeout that only connects to public addresses, refusing webhooks on
+// loopback, private and link-local ones such as cloud metadata endpoints; a client of your own
+// is trusted to apply its own rules. With WithURIFetching, webhooks must also be on hosts its
+// policy allows. Events to a webhook registered with a token are signed with it (see
+// models.SignRequest), so the receiver can verify them.
+func WithPushNotifications(client *http.Client) Option {
+	return func(s *A2AServer) {
+		publicOnly := client == nil
+		if publicOnly {
+			client = &http.Client{Timeout: defaultPushTimeout, Transport: publicTransport()}
+		}
+		s.push = &pushDispatcher{client: client, publicOnly: publicOnly, webhooks: make(map[string]*webhook)}
+	}
+}
+
+// publicTransport returns a transport that only connects to public addresses, checked once
+// the host is resolved so that names pointing at internal addresses are refused too. It uses
+// no proxy, which would connect on its behalf.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addr.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPushDenied, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, internal like private ranges
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr reports whether addr is a globally routable unicast address
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// pushDispatcher delivers task updates to the webhooks registered for the tasks
+type pushDispatcher struct {
+	client *http.Client
+	// publicOnly is set when client only connects to public addresses
+	publicOnly bool
+	clock      clock.Clock
+	logger     *slog.Logger
+
+	mu       sync.Mutex
+	webhooks map[string]*webhook
+}
+
+// webhook is the push notification config of a task and its undelivered events
+type webhook struct {
+	config  models.PushNotificationConfig
+	pending []interface{}
+	// sending is set while a goroutine delivers the pending events, in order
+	sending bool
+}
+
+// set registers config as the webhook of taskID, replacing any previous one
+func (d *pushDispatcher) set(taskID string, config models.PushNotificationConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if wh := d.webhooks[taskID]; wh != nil {
+		wh.config = config
+		return
+	}
+	d.webhooks[taskID] = &webhook{config: config}
+}
+
+// get returns the webhook config of taskID
+func (d *pushDispatcher) get(taskID string) (models.PushNotificationConfig, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	wh := d.webhooks[taskID]
+	if wh == nil {
+		return models.PushNotificationConfig{}, false
+	}
+	return wh.config, true
+}
+
+// remove unregisters the webhook of taskID; events already queued are still delivered
+func (d *pushDispatcher) remove(taskID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.webhooks, taskID)
+}
+
+// WebhookInfo describes a registered webhook, withholding its token and credentials
+type WebhookInfo struct {
+	TaskID string `json:"taskId"`
+	URL    string `json:"url"`
+	// Schemes are the authentication schemes the webhook accepts
+	Schemes []string `json:"schemes,omitempty"`
+	// Signed is set when the webhook's events are signed with its token
+	Signed bool `json:"signed"`
+	// Pending is the number of events waiting to be delivered
+	Pending int `json:"pending"`
+}
+
+// list describes the registered webhooks, ordered by task ID
+func (d *pushDispatcher) list() []WebhookInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	webhooks := make([]WebhookInfo, 0, len(d.webhooks))
+	for _, taskID := range sortedKeys(d.webhooks) {
+		wh := d.webhooks[taskID]
+		info := WebhookInfo{TaskID: taskID, URL: wh.config.URL, Signed: wh.config.Token != nil, Pending: len(wh.pending)}
+		if wh.config.Authentication != nil {
+			info.Schemes = wh.config.Authentication.Schemes
+		}
+		webhooks = append(webhooks, info)
+	}
+	return webhooks
+}
+
+// notify queues event for the webhook of taskID, if it has one
+func (d *pushDispatcher) notify(taskID string, event interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	wh := d.webhooks[taskID]
+	if wh == nil {
+		return
+	}
+	if len(wh.pending) == maxPendingPushes {
+		contextLogger(context.Background(), d.logger, taskID).Warn("dropping push notification: webhook queue full")
+		wh.pending = wh.pending[1:]
+	}
+	wh.pending = append(wh.pending, event)
+	if !wh.sending {
+		wh.sending = true
+		go d.drain(taskID, wh)
+	}
+}
+
+// drain delivers the pending events of wh until none are left
+func (d *pushDispatcher) drain(taskID string, wh *webhook) {
+	for {
+		d.mu.Lock()
+		if len(wh.pending) == 0 {
+			wh.sending = false
+			d.mu.Unlock()
+			return
+		}
+		event, config := wh.pending[0], wh.config
+		wh.pending = wh.pending[1:]
+		d.mu.Unlock()
+
+		if err := d.deliver(config, event); err != nil {
+			contextLogger(context.Background(), d.logger, taskID).Error("failed to deliver push notification", slog.Any("error", err))
+		}
+	}
+}
+
+// deliver posts event to the webhook of config, retrying failed attempts with backoff
+func (d *pushDispatcher) deliver(config models.PushNotificationConfig, event interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	backoff := pushBackoff
+	for attempt := 1; ; attempt++ {
+		err = d.post(config, body)
+		if err == nil || attempt == pushAttempts {
+			return err
+		}
+		<-d.clock.After(backoff)
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt of body to the webhook of config
+func (d *pushDispatcher) post(config models.PushNotificationConfig, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Token != nil {
+		timestamp := strconv.FormatInt(d.clock.Now().Unix(), 10)
+		req.Header.Set(models.HeaderTimestamp, timestamp)
+		req.Header.Set(models.HeaderSignature, models.SignRequest([]byte(*config.Token), req.Method, models.SignedPath(req), timestamp, "", body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validatePushConfig checks that config names an absolute HTTP(S) webhook URL the server may
+// post to: on a host the fetch policy allows, if any, and not on an internal IP address when
+// the push client only connects to public ones. Names are resolved when connecting.
+func (s *A2AServer) validatePushConfig(config models.PushNotificationConfig) error {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid push notification URL %q", config.URL)
+	}
+	if s.fetch != nil && !s.fetch.allowsHost(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrPushDenied, u.Hostname())
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && s.push.publicOnly && !isPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrPushDenied, u.Hostname())
+	}
+	return nil
+}
+
+// registerPush registers the push notification config sent with params, if any, replying with
+// an error and returning false when it cannot be registered
+func (s *A2AServer) registerPush(w http.ResponseWriter, id interface{}, params models.TaskSendParams) bool {
+	if params.PushNotification == nil {
+		return true
+	}
+	if s.push == nil {
+		s.sendA2AError(w, id, models.NewPushNotificationNotSupportedError())
+		return false
+	}
+	if err := s.validatePushConfig(*params.PushNotification); err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, err.Error())
+		return false
+	}
+	s.push.set(params.ID, *params.PushNotification)
+	return true
+}
+
+// notify sends event about taskID to the task's webhook, if push notifications are enabled
+func (s *A2AServer) notify(taskID string, event interface{}) {
+	if s.push != nil {
+		s.push.notify(taskID, event)
+	}
+}
+
+// notifyResult publishes the artifacts of a task run without streaming on the event bus, and
+// sends them and its final status to the task's webhook
+func (s *A2AServer) notifyResult(ctx context.Context, task *models.Task) {
+	for _, artifact := range task.Artifacts {
+		s.publishArtifact(ctx, task.ID, artifact)
+	}
+	if s.push == nil {
+		return
+	}
+	for _, artifact := range task.Artifacts {
+		s.push.notify(task.ID, models.TaskArtifactUpdateEvent{ID: task.ID, Artifact: artifact})
+	}
+	s.push.notify(task.ID, models.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: boolPtr(true)})
+}
+
+// handleSetPushNotification handles tasks/pushNotificationConfig/set, registering a webhook for
+// an existing task
+func (s *A2AServer) handleSetPushNotification(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	if s.push == nil {
+		s.sendA2AError(w, req.ID, models.NewPushNotificationNotSupportedError())
+		return
+	}
+	var params models.TaskPushNotificationConfig
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+	if err := s.validatePushConfig(params.PushNotificationConfig); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, err.Error())
+		return
+	}
+
+	s.mu.RLock()
+	_, err := s.store.Get(r.Context(), params.ID)
+	s.mu.RUnlock()
+	if err != nil {
+		s.sendStoreError(w, req.ID, err)
+		return
+	}
+
+	s.push.set(params.ID, params.PushNotificationConfig)
+	s.sendResponseWithID(w, req.ID, params)
+}
+
+// handleGetPushNotification handles tasks/pushNotificationConfig/get
+func (s *A2AServer) handleGetPushNotification(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	if s.push == nil {
+		s.sendA2AError(w, req.ID, models.NewPushNotificationNotSupportedError())
+		return
+	}
+	var params models.TaskIDParams
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+
+	config, ok := s.push.get(params.ID)
+	if !ok {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeTaskNotFound, "No push notification config for task "+params.ID)
+		return
+	}
+	s.sendResponseWithID(w, req.ID, models.TaskPushNotificationConfig{ID: params.ID, PushNotificationConfig: config})
+}
+
+// decodeParams decodes the params of req into dst
+func decodeParams(req *models.JSONRPCRequest, dst interface{}) error {
+	paramsBytes, err := json.Marshal(req.Params)
+	if err != nil {
+		return err
+	}
+	return models.DecodeJSON(paramsBytes, dst)
+}
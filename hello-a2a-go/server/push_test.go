@@ -0,0 +1,194 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+// webhookEvent is a push notification received by a test webhook
+type webhookEvent struct {
+	Body   map[string]interface{}
+	Signed bool
+}
+
+// startWebhook serves a webhook verifying signatures with token, returning received events on
+// the channel
+func startWebhook(t *testing.T, token string) (*httptest.Server, <-chan webhookEvent) {
+	t.Helper()
+	events := make(chan webhookEvent, 16)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event webhookEvent
+		json.Unmarshal(body, &event.Body)
+		event.Signed = models.VerifyRequestSignature([]byte(token), r.Method, models.SignedPath(r), r.Header.Get(models.HeaderTimestamp), "", body, r.Header.Get(models.HeaderSignature))
+		events <- event
+	}))
+	t.Cleanup(ts.Close)
+	return ts, events
+}
+
+// nextWebhookEvent waits for the next push notification
+func nextWebhookEvent(t *testing.T, events <-chan webhookEvent) webhookEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a push notification")
+		return webhookEvent{}
+	}
+}
+
+func TestPushNotifications(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = append(task.Artifacts, models.Artifact{Parts: []models.Part{models.TextPart{Type: "text", Text: "done"}}})
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithPushNotifications(http.DefaultClient))
+	webhook, events := startWebhook(t, "secret")
+	token := "secret"
+
+	message := models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "pushed",
+		Message: message,
+		Config:  &models.MessageSendConfiguration{PushNotifications: &models.PushNotificationConfig{URL: webhook.URL, Token: &token}},
+	})
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+
+	artifact := nextWebhookEvent(t, events)
+	if !artifact.Signed || artifact.Body["artifact"] == nil {
+		t.Errorf("Expected a signed artifact update, got %+v", artifact)
+	}
+	status := nextWebhookEvent(t, events)
+	if !status.Signed || status.Body["final"] != true || status.Body["status"].(map[string]interface{})["state"] != "completed" {
+		t.Errorf("Expected a signed final status update, got %+v", status)
+	}
+
+	response = doRPC(t, server, GetPushNotificationMethod, models.TaskIDParams{ID: "pushed"})
+	var config models.TaskPushNotificationConfig
+	decodeResult(t, response.Result, &config)
+	if response.Error != nil || config.ID != "pushed" || config.PushNotificationConfig.URL != webhook.URL {
+		t.Errorf("Expected the registered config, got %+v (%v)", config, response.Error)
+	}
+
+	// Registering on an existing task redirects the updates of its later messages
+	other, otherEvents := startWebhook(t, "")
+	response = doRPC(t, server, SetPushNotificationMethod, models.TaskPushNotificationConfig{
+		ID:                     "pushed",
+		PushNotificationConfig: models.PushNotificationConfig{URL: other.URL},
+	})
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	doRPC(t, server, "message/send", models.MessageSendParams{ID: "pushed", Message: message})
+	if redirected := nextWebhookEvent(t, otherEvents); redirected.Body["artifact"] == nil {
+		t.Errorf("Expected the artifact update at the new webhook, got %+v", redirected)
+	}
+
+	for name, params := range map[string]models.TaskPushNotificationConfig{
+		"unknown task": {ID: "unknown", PushNotificationConfig: models.PushNotificationConfig{URL: webhook.URL}},
+		"invalid URL":  {ID: "pushed", PushNotificationConfig: models.PushNotificationConfig{URL: "ftp://example.com"}},
+	} {
+		if response := doRPC(t, server, SetPushNotificationMethod, params); response.Error == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestPushNotificationsStreaming(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithPushNotifications(http.DefaultClient))
+	webhook, events := startWebhook(t, "")
+
+	w := httptest.NewRecorder()
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"streamed","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]},"config":{"pushNotifications":{"url":"` + webhook.URL + `"}}}}`
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+	req.Header.Set("Accept", "text/event-stream")
+	server.ServeHTTP(w, req)
+
+	for _, want := range []string{"working", "completed"} {
+		event := nextWebhookEvent(t, events)
+		if state := event.Body["status"].(map[string]interface{})["state"]; state != want {
+			t.Errorf("Expected a %s update, got %v", want, state)
+		}
+	}
+}
+
+func TestPushNotificationsDisabled(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	response := doRPC(t, server, GetPushNotificationMethod, models.TaskIDParams{ID: "any"})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodePushNotificationNotSupported) {
+		t.Errorf("Expected push notifications to be unsupported, got %+v", response)
+	}
+	response = doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "push",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+		Config:  &models.MessageSendConfiguration{PushNotifications: &models.PushNotificationConfig{URL: "http://example.com"}},
+	})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodePushNotificationNotSupported) {
+		t.Errorf("Expected a push config to be refused, got %+v", response)
+	}
+}
+
+func TestPushNotificationsInternalAddresses(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithPushNotifications(nil))
+	doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "pushed",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+	})
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://10.0.0.1/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook"} {
+		response := doRPC(t, server, SetPushNotificationMethod, models.TaskPushNotificationConfig{
+			ID:                     "pushed",
+			PushNotificationConfig: models.PushNotificationConfig{URL: url},
+		})
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+			t.Errorf("Expected webhook %s refused, got %+v", url, response)
+		}
+	}
+
+	// Names are checked once resolved
+	webhook, _ := startWebhook(t, "")
+	_, port, _ := strings.Cut(webhook.URL, "127.0.0.1:")
+	_, err := server.push.client.Post("http://localhost:"+port, "application/json", strings.NewReader("{}"))
+	if !errors.Is(err, ErrPushDenied) {
+		t.Errorf("Expected a name resolving to loopback refused, got %v", err)
+	}
+
+	for addr, public := range map[string]bool{"93.184.216.34": true, "2606:2800::1": true, "192.168.1.1": false, "100.64.0.1": false, "::ffff:10.0.0.1": false, "fd00:ec2::254": false, "0.0.0.0": false} {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != public {
+			t.Errorf("Expected isPublicAddr(%s) = %v", addr, public)
+		}
+	}
+}
+
+func TestPushNotificationsFetchPolicy(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithPushNotifications(nil),
+		WithURIFetching(FetchPolicy{AllowedHosts: []string{"hooks.example.com"}}))
+	doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "pushed",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+	})
+	for url, allowed := range map[string]bool{"https://hooks.example.com/a2a": true, "https://other.example.com/a2a": false} {
+		response := doRPC(t, server, SetPushNotificationMethod, models.TaskPushNotificationConfig{
+			ID:                     "pushed",
+			PushNotificationConfig: models.PushNotificationConfig{URL: url},
+		})
+		if (response.Error == nil) != allowed {
+			t.Errorf("Expected webhook %s allowed=%v, got %+v", url, allowed, response.Error)
+		}
+	}
+}
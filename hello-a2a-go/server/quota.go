@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// Quota bounds a caller's consumption within calendar windows (UTC); zero disables a limit
+type Quota struct {
+	// TasksPerDay is the number of tasks a caller may start per day
+	TasksPerDay int64
+	// TokensPerMonth is the number of LLM tokens a caller may consume per month
+	TokensPerMonth int64
+}
+
+// QuotaError reports that a caller exhausted a quota
+type QuotaError struct {
+	Caller string
+	// Limit names the exhausted quota, "tasksPerDay" or "tokensPerMonth"
+	Limit string
+	// RetryAfter is the time until the quota window resets
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota %s exceeded, retry after %s", e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+// WithQuota enforces quota on every caller without a dedicated quota
+func WithQuota(quota Quota) Option {
+	return func(s *A2AServer) {
+		s.quotaTracker().defaultQuota = quota
+	}
+}
+
+// WithCallerQuota enforces quota on caller, as identified by CallerFromRequest or the function
+// set with WithCallerFunc, overriding the default quota. See CallerForAPIKey.
+func WithCallerQuota(caller string, quota Quota) Option {
+	return func(s *A2AServer) {
+		s.quotaTracker().callerQuotas[caller] = quota
+	}
+}
+
+// CallerForAPIKey returns the caller identity CallerFromRequest assigns to requests
+// authenticated with apiKey, "key:" and the key's ID, for configuring per-key quotas
+func CallerForAPIKey(apiKey string) string {
+	return "key:" + apiKeyID(apiKey)
+}
+
+// quotaSweep is how often the counters of idle callers are dropped
+const quotaSweep = time.Hour
+
+// quotaTracker returns the server's quota tracker, creating it on first use
+func (s *A2AServer) quotaTracker() *quotas {
+	if s.quotas == nil {
+		s.quotas = &quotas{
+			callerQuotas: make(map[string]Quota),
+			counters:     make(map[string]*quotaCounter),
+			now:          time.Now,
+		}
+	}
+	return s.quotas
+}
+
+// quotas tracks per-caller consumption in the current day and month
+type quotas struct {
+	defaultQuota Quota
+	callerQuotas map[string]Quota
+
+	mu        sync.Mutex
+	counters  map[string]*quotaCounter
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// quotaCounter holds a caller's consumption in the current windows
+type quotaCounter struct {
+	day    string
+	tasks  int64
+	month  string
+	tokens int64
+}
+
+// counter returns caller's counter, resetting windows that have elapsed. Callers hold q.mu.
+func (q *quotas) counter(caller string, now time.Time) *quotaCounter {
+	if now.Sub(q.lastSweep) >= quotaSweep {
+		q.sweep(now)
+	}
+	c, ok := q.counters[caller]
+	if !ok {
+		c = &quotaCounter{}
+		q.counters[caller] = c
+	}
+	if day := now.Format("2006-01-02"); c.day != day {
+		c.day, c.tasks = day, 0
+	}
+	if month := now.Format("2006-01"); c.month != month {
+		c.month, c.tokens = month, 0
+	}
+	return c
+}
+
+// sweep drops the counters whose windows have all elapsed, as their callers have been idle
+func (q *quotas) sweep(now time.Time) {
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	for caller, c := range q.counters {
+		if c.day != day && c.month != month {
+			delete(q.counters, caller)
+		}
+	}
+	q.lastSweep = now
+}
+
+// admit counts a new task for caller, or returns a QuotaError when a quota is exhausted
+func (q *quotas) admit(caller string) *QuotaError {
+	quota, ok := q.callerQuotas[caller]
+	if !ok {
+		quota = q.defaultQuota
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	c := q.counter(caller, now)
+	if quota.TokensPerMonth > 0 && c.tokens >= quota.TokensPerMonth {
+		nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		return &QuotaError{Caller: caller, Limit: "tokensPerMonth", RetryAfter: nextMonth.Sub(now)}
+	}
+	if quota.TasksPerDay > 0 && c.tasks >= quota.TasksPerDay {
+		nextDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return &QuotaError{Caller: caller, Limit: "tasksPerDay", RetryAfter: nextDay.Sub(now)}
+	}
+	c.tasks++
+	return nil
+}
+
+// addTokens charges tokens to caller's monthly quota
+func (q *quotas) addTokens(caller string, tokens int64) {
+	if tokens == 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.counter(caller, q.now().UTC()).tokens += tokens
+}
+
+// admitRequest checks the caller's quotas before a task is started. When a quota is exhausted
+// it writes a quota error with Retry-After information and returns false.
+func (s *A2AServer) admitRequest(w http.ResponseWriter, r *http.Request, id interface{}) bool {
+	if s.quotas == nil {
+		return true
+	}
+
+	quotaErr := s.quotas.admit(s.caller(r))
+	if quotaErr == nil {
+		return true
+	}
+
+	retryAfter := int64(math.Ceil(quotaErr.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.JSONRPCResponse{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id},
+		},
+		Error: &models.JSONRPCError{
+			Code:    int(models.ErrorCodeQuotaExceeded),
+			Message: quotaErr.Error(),
+			Data: map[string]interface{}{
+				"limit":             quotaErr.Limit,
+				"retryAfterSeconds": retryAfter,
+			},
+		},
+	})
+	return false
+}
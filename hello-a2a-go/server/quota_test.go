@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+func TestA2AServer_TaskQuota(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithQuota(Quota{TasksPerDay: 2}), WithAPIKeys())
+	basic, _, _ := server.IssueAPIKey(context.Background(), APIKey{Name: "basic"})
+	premium, _, _ := server.IssueAPIKey(context.Background(), APIKey{Name: "premium"})
+	WithCallerQuota(CallerForAPIKey(premium), Quota{TasksPerDay: 5})(server)
+	server.quotas.now = func() time.Time { return time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC) }
+
+	send := func(apiKey string) (models.JSONRPCResponse, string) {
+		reqBody, _ := json.Marshal(models.JSONRPCRequest{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+			},
+			Method: "message/send",
+			Params: models.MessageSendParams{
+				ID: "quota-task",
+				Message: models.Message{
+					Role:  "user",
+					Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+				},
+			},
+		})
+		req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqBody))
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+
+		var response models.JSONRPCResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response, w.Header().Get("Retry-After")
+	}
+
+	for i := 0; i < 2; i++ {
+		if response, _ := send(basic); response.Error != nil {
+			t.Fatalf("Expected task %d to be admitted, got %v", i, response.Error)
+		}
+	}
+
+	response, retryAfter := send(basic)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeQuotaExceeded) {
+		t.Fatalf("Expected quota error, got %+v", response.Error)
+	}
+	if retryAfter != "3600" {
+		t.Errorf("Expected Retry-After 3600, got %q", retryAfter)
+	}
+	data := response.Error.Data.(map[string]interface{})
+	if data["limit"] != "tasksPerDay" || data["retryAfterSeconds"] != float64(3600) {
+		t.Errorf("Unexpected error data: %v", data)
+	}
+
+	if response, _ := send(premium); response.Error != nil {
+		t.Errorf("Expected premium caller to be admitted, got %v", response.Error)
+	}
+
+	// The daily window resets at midnight UTC
+	server.quotas.now = func() time.Time { return time.Date(2025, 3, 11, 0, 0, 1, 0, time.UTC) }
+	if response, _ := send(basic); response.Error != nil {
+		t.Errorf("Expected task to be admitted on the next day, got %v", response.Error)
+	}
+
+	// Counters of callers idle for a whole month are dropped
+	server.quotas.now = func() time.Time { return time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC) }
+	send(premium)
+	if n := len(server.quotas.counters); n != 1 {
+		t.Errorf("Expected only the active caller's counter, got %d", n)
+	}
+}
+
+func TestA2AServer_QuotaUnverifiedCredentials(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithQuota(Quota{TasksPerDay: 2}))
+	server.quotas.now = func() time.Time { return time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC) }
+
+	// Without authentication, made-up keys share the quota of the client's address
+	params := models.MessageSendParams{
+		ID:      "quota-task",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}},
+	}
+	var codes []int
+	for _, key := range []string{"made-up-1", "made-up-2", "made-up-3"} {
+		body, _ := json.Marshal(models.JSONRPCRequest{Method: "message/send", Params: params})
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		var response models.JSONRPCResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		code := 0
+		if response.Error != nil {
+			code = response.Error.Code
+		}
+		codes = append(codes, code)
+	}
+	if codes[0] != 0 || codes[1] != 0 || codes[2] != int(models.ErrorCodeQuotaExceeded) {
+		t.Errorf("Expected a fresh unverified key to stay within the address's quota, got codes %v", codes)
+	}
+	if n := len(server.quotas.counters); n != 1 {
+		t.Errorf("Expected a single counter for the address, got %d", n)
+	}
+}
+
+func TestA2AServer_TokenQuota(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		ReportTokens(ctx, 60)
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithQuota(Quota{TokensPerMonth: 100}))
+	server.quotas.now = func() time.Time { return time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC) }
+
+	params := models.MessageSendParams{
+		ID: "token-task",
+		Message: models.Message{
+			Role:  "user",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if response := doRPC(t, server, "message/send", params); response.Error != nil {
+			t.Fatalf("Expected task %d to be admitted, got %v", i, response.Error)
+		}
+	}
+
+	response := doRPC(t, server, "message/send", params)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeQuotaExceeded) {
+		t.Fatalf("Expected quota error, got %+v", response.Error)
+	}
+	if data := response.Error.Data.(map[string]interface{}); data["limit"] != "tokensPerMonth" || data["retryAfterSeconds"] != float64(12*3600) {
+		t.Errorf("Unexpected error data: %v", data)
+	}
+}
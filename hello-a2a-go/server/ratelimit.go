@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// rateLimitSweep is how often the buckets of idle callers are dropped
+const rateLimitSweep = time.Minute
+
+// RateLimit bounds the JSON-RPC requests of each caller with a token bucket
+type RateLimit struct {
+	// Rate is the number of requests per second a caller's bucket is refilled with
+	Rate float64
+	// Burst is the size of the bucket, the most requests a caller may make at once; values
+	// below 1 mean Rate rounded up
+	Burst int
+}
+
+// WithRateLimit limits the JSON-RPC requests of each caller, as identified by CallerFromRequest or
+// the function set with WithCallerFunc, to limit. Requests over the limit are answered with
+// HTTP 429, a Retry-After header and an ErrorCodeRateLimited error.
+func WithRateLimit(limit RateLimit) Option {
+	return func(s *A2AServer) {
+		if limit.Burst < 1 {
+			limit.Burst = max(1, int(math.Ceil(limit.Rate)))
+		}
+		s.rateLimit = &rateLimiter{limit: limit, buckets: make(map[string]*tokenBucket)}
+	}
+}
+
+// rateLimiter holds the token bucket of each caller
+type rateLimiter struct {
+	limit RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the requests a caller may still make, as of last
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token from the bucket of caller at now, or returns how long until one is
+// available
+func (l *rateLimiter) allow(caller string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweep {
+		l.sweep(now)
+	}
+	b := l.buckets[caller]
+	if b == nil {
+		b = &tokenBucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[caller] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if l.limit.Rate <= 0 {
+		return rateLimitSweep, false
+	}
+	return time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second)), false
+}
+
+// refill returns the tokens of b at now
+func (l *rateLimiter) refill(b *tokenBucket, now time.Time) float64 {
+	return math.Min(float64(l.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+}
+
+// sweep drops the buckets that have refilled completely, as their callers have been idle
+func (l *rateLimiter) sweep(now time.Time) {
+	for caller, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.limit.Burst) {
+			delete(l.buckets, caller)
+		}
+	}
+	l.lastSweep = now
+}
+
+// bearerContextKey is the context key for the fingerprint of the bearer token a request was
+// authenticated with by RequireBearer
+type bearerContextKey struct{}
+
+// allowRequest checks the rate limit of the caller of r, if any. When the caller is over its
+// limit, it writes a rate limit error with Retry-After information and returns false.
+func (s *A2AServer) allowRequest(w http.ResponseWriter, r *http.Request, id interface{}) bool {
+	if s.rateLimit == nil {
+		return true
+	}
+	wait, ok := s.rateLimit.allow(s.caller(r), s.clock.Now())
+	if ok {
+		return true
+	}
+
+	retryAfter := max(1, int64(math.Ceil(wait.Seconds())))
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(models.JSONRPCResponse{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id},
+		},
+		Error: &models.JSONRPCError{
+			Code:    int(models.ErrorCodeRateLimited),
+			Message: fmt.Sprintf("Rate limit exceeded, retry after %ds", retryAfter),
+			Data:    map[string]interface{}{"retryAfterSeconds": retryAfter},
+		},
+	})
+	return false
+}
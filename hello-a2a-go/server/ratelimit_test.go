@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestA2AServer_RateLimit(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake), WithRateLimit(RateLimit{Rate: 0.5, Burst: 2}), WithAPIKeys())
+	alice, _, _ := server.IssueAPIKey(context.Background(), APIKey{Name: "alice"})
+	bob, _, _ := server.IssueAPIKey(context.Background(), APIKey{Name: "bob"})
+
+	get := func(apiKey string) *httptest.ResponseRecorder {
+		return getTaskWithKey(server, apiKey)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get(alice); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst, got %d", i+1, w.Code)
+		}
+	}
+	w := get(alice)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("Expected 429 with Retry-After 2, got %d and %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	var response models.JSONRPCResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeRateLimited) || response.ID != "1" {
+		t.Errorf("Expected a rate limit error for request 1, got %+v", response)
+	}
+
+	// Other callers have buckets of their own, and a caller's bucket refills over time
+	if w := get(bob); w.Code != http.StatusOK {
+		t.Errorf("Expected another caller to be admitted, got %d", w.Code)
+	}
+	fake.Advance(2 * time.Second)
+	if w := get(alice); w.Code != http.StatusOK {
+		t.Errorf("Expected a request after the refill, got %d", w.Code)
+	}
+	if w := get(alice); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a single refilled token, got %d", w.Code)
+	}
+
+	// Buckets of idle callers are dropped
+	fake.Advance(time.Hour)
+	get(alice)
+	if n := len(server.rateLimit.buckets); n != 1 {
+		t.Errorf("Expected only the active caller's bucket, got %d", n)
+	}
+}
+
+// getTaskWithKey calls tasks/get on server with apiKey as its API key
+func getTaskWithKey(server *A2AServer, apiKey string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(models.JSONRPCRequest{
+		JSONRPCMessage: models.JSONRPCMessage{
+			JSONRPC:                  "2.0",
+			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+		},
+		Method: "tasks/get",
+		Params: models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "missing"}},
+	})
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("X-API-Key", apiKey)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestA2AServer_RateLimitUnverifiedCredentials(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake), WithRateLimit(RateLimit{Rate: 0.5, Burst: 2}))
+
+	// Without authentication, made-up keys share the limit of the client's address
+	for i, key := range []string{"made-up-1", "made-up-2"} {
+		if w := getTaskWithKey(server, key); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst, got %d", i+1, w.Code)
+		}
+	}
+	if w := getTaskWithKey(server, "made-up-3"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a fresh unverified key to stay limited, got %d", w.Code)
+	}
+}
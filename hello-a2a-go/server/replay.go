@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// maxNonceLength bounds the nonces accepted, so the replay cache cannot be inflated
+const maxNonceLength = 128
+
+var (
+	// errMissingReplayHeaders is returned when an authenticated request lacks a nonce or timestamp
+	errMissingReplayHeaders = errors.New("missing request nonce or timestamp")
+	// errStaleRequest is returned when a request timestamp is outside the accepted window
+	errStaleRequest = errors.New("request timestamp outside accepted window")
+	// errReplayedRequest is returned when a request nonce was already seen
+	errReplayedRequest = errors.New("request nonce already used")
+)
+
+// WithReplayProtection requires authenticated requests (those carrying an X-API-Key or
+// Authorization header) to include a unique nonce and a timestamp no more than window away
+// from the server clock. Nonces are remembered for window in a cache of at most cacheSize
+// entries; size it for the request rate, since the oldest nonces are evicted when it is full.
+func WithReplayProtection(window time.Duration, cacheSize int) Option {
+	return func(s *A2AServer) {
+		s.replay = &replayGuard{
+			window: window,
+			size:   cacheSize,
+			seen:   make(map[string]time.Time),
+			now:    time.Now,
+		}
+	}
+}
+
+// replayGuard rejects requests whose nonce was seen within the window
+type replayGuard struct {
+	window time.Duration
+	size   int
+
+	mu sync.Mutex
+	// seen maps nonces to their expiry; order lists them oldest first
+	seen  map[string]time.Time
+	order []string
+	now   func() time.Time
+}
+
+// check validates the replay headers of r and records its nonce
+func (g *replayGuard) check(r *http.Request) error {
+	if r.Header.Get("X-API-Key") == "" && r.Header.Get("Authorization") == "" {
+		return nil
+	}
+
+	nonce := r.Header.Get(models.HeaderNonce)
+	timestamp := r.Header.Get(models.HeaderTimestamp)
+	if nonce == "" || len(nonce) > maxNonceLength || timestamp == "" {
+		return errMissingReplayHeaders
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errMissingReplayHeaders
+	}
+
+	now := g.now()
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > g.window || skew < -g.window {
+		return errStaleRequest
+	}
+	return g.record(nonce, now)
+}
+
+// record remembers nonce, seen at now, or returns errReplayedRequest if it was already seen
+func (g *replayGuard) record(nonce string, now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.evictExpired(now)
+	if _, ok := g.seen[nonce]; ok {
+		return errReplayedRequest
+	}
+	if g.size > 0 && len(g.order) >= g.size {
+		delete(g.seen, g.order[0])
+		g.order = g.order[1:]
+	}
+	// A nonce must outlive any timestamp that could still be accepted with it
+	g.seen[nonce] = now.Add(2 * g.window)
+	g.order = append(g.order, nonce)
+	return nil
+}
+
+// evictExpired drops nonces whose timestamps can no longer be accepted. Callers hold g.mu.
+func (g *replayGuard) evictExpired(now time.Time) {
+	expired := 0
+	for _, nonce := range g.order {
+		if g.seen[nonce].After(now) {
+			break
+		}
+		delete(g.seen, nonce)
+		expired++
+	}
+	g.order = g.order[expired:]
+}
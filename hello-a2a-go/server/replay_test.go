@@ -0,0 +1,67 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestA2AServer_ReplayProtection(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	fake := clock.NewFake(now)
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithReplayProtection(time.Minute, 2), WithClock(fake))
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`)
+	send := func(apiKey, nonce string, timestamp time.Time) int {
+		req := httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if nonce != "" {
+			req.Header.Set(models.HeaderNonce, nonce)
+			req.Header.Set(models.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		nonce     string
+		timestamp time.Time
+		want      int
+	}{
+		{"unauthenticated request is not checked", "", "", now, http.StatusOK},
+		{"missing nonce", "k", "", now, http.StatusUnauthorized},
+		{"fresh nonce", "k", "n1", now, http.StatusOK},
+		{"replayed nonce", "k", "n1", now, http.StatusUnauthorized},
+		{"stale timestamp", "k", "n2", now.Add(-2 * time.Minute), http.StatusUnauthorized},
+		{"future timestamp", "k", "n3", now.Add(2 * time.Minute), http.StatusUnauthorized},
+		{"skewed but within window", "k", "n4", now.Add(-30 * time.Second), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := send(tt.apiKey, tt.nonce, tt.timestamp); got != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, got)
+			}
+		})
+	}
+
+	// Nonces are forgotten once no timestamp carrying them can be accepted
+	now = now.Add(3 * time.Minute)
+	fake.Set(now)
+	if got := send("k", "n1", now); got != http.StatusOK {
+		t.Errorf("Expected expired nonce to be reusable, got status %d", got)
+	}
+	if len(server.replay.seen) != 1 {
+		t.Errorf("Expected expired nonces to be evicted, cache holds %d", len(server.replay.seen))
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"a2a/models"
+)
+
+// MessageHandler answers a message with a message, for quick replies that need no task to
+// follow, such as small talk or lookups
+type MessageHandler func(ctx context.Context, message *models.Message) (*models.Message, error)
+
+// Replying adapts handler to a TaskHandler for NewA2AServer, AddSkill or WithSkill. Its reply
+// completes the task as the status message, so the task is stored, streamed and pushed as
+// usual, but message/send answers with the reply itself (kind "message") rather than the task.
+// A nil reply completes the task without a message, answered as a task.
+func Replying(handler MessageHandler) TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		reply, err := handler(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil && reply.Role == "" {
+			reply.Role = "agent"
+		}
+		task.Status = models.TaskStatus{State: models.TaskStateCompleted, Message: reply}
+		if state, ok := ctx.Value(replyKey{}).(*replyState); ok && reply != nil {
+			state.replied = true
+		}
+		return task, nil
+	}
+}
+
+// replyKey is the context key for the replyState of a message/send request
+type replyKey struct{}
+
+// replyState records whether the handler of a message/send request replied with a message
+type replyState struct {
+	replied bool
+}
+
+// acceptReplies returns r with a context letting handlers reply with a message, which only
+// message/send responses can carry
+func acceptReplies(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), replyKey{}, &replyState{}))
+}
+
+// sendResult returns the result answering the request r for task: the handler's reply when it
+// replied with a message and the task completed, else task with its history cut to
+// historyLength
+func sendResult(r *http.Request, task *models.Task, historyLength *int) interface{} {
+	state, ok := r.Context().Value(replyKey{}).(*replyState)
+	if ok && state.replied && task.Status.State == models.TaskStateCompleted && task.Status.Message != nil {
+		return task.Status.Message
+	}
+	return withHistoryLength(task, historyLength)
+}
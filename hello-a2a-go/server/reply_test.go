@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestReplying(t *testing.T) {
+	handler := Replying(func(ctx context.Context, message *models.Message) (*models.Message, error) {
+		if message.Text() == "silent" {
+			return nil, nil
+		}
+		return &models.Message{Parts: []models.Part{models.NewTextPart(strings.ToUpper(message.Text()))}}, nil
+	})
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"inline", nil},
+		{"pool", []Option{WithWorkerPool(1, 1)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewA2AServer(mockAgentCard, handler, tt.opts...)
+
+			resp := doRPC(t, server, "message/send", models.MessageSendParams{ID: "reply", Message: models.Message{
+				Role: "user", Parts: []models.Part{models.NewTextPart("hello")},
+			}})
+			var result models.SendMessageResult
+			decodeResult(t, resp.Result, &result)
+			reply := result.Message
+			if reply == nil || reply.Kind != models.KindMessage || reply.Role != "agent" || reply.Text() != "HELLO" {
+				t.Fatalf("Expected the reply as a message, got %+v", result)
+			}
+			if reply.TaskID != "reply" || reply.ContextID == "" || reply.MessageID == "" {
+				t.Errorf("Expected the reply to carry its task and conversation, got %+v", reply)
+			}
+
+			// The task is stored completed with the reply
+			var task models.Task
+			decodeResult(t, doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "reply"}}).Result, &task)
+			if task.Status.State != models.TaskStateCompleted || task.Status.Message.Text() != "HELLO" || len(task.History) != 2 {
+				t.Errorf("Expected the completed task with the reply, got %+v", task)
+			}
+
+			// tasks/send and handlers without a reply answer with the task
+			for _, method := range []string{"tasks/send", "message/send"} {
+				text := "hi"
+				if method == "message/send" {
+					text = "silent"
+				}
+				resp = doRPC(t, server, method, models.MessageSendParams{ID: method, Message: models.Message{
+					Role: "user", Parts: []models.Part{models.NewTextPart(text)},
+				}})
+				result = models.SendMessageResult{}
+				decodeResult(t, resp.Result, &result)
+				if result.Task == nil || result.Task.Status.State != models.TaskStateCompleted {
+					t.Errorf("%s: expected the completed task, got %+v", method, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"a2a/models"
+)
+
+// ResubscribeMethod is the JSON-RPC method reattaching a client to a task's event stream
+const ResubscribeMethod = "tasks/resubscribe"
+
+// handleResubscribe handles tasks/resubscribe, streaming the events of a task to a client that
+// lost its stream. With a Last-Event-ID header exactly the events after it are replayed, in
+// order (see resume), otherwise every event of the task's running stream; then the stream
+// continues live. A task with no running stream, such as one already finished, gets a single
+// final event with its status.
+func (s *A2AServer) handleResubscribe(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest) {
+	var params models.TaskQueryParams
+	if err := decodeParams(req, &params); err != nil {
+		s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+
+	var resumed *resumption
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		var err error
+		if resumed, err = s.resume(r.Context(), lastEventID, params.ID); err != nil {
+			s.sendResumeError(w, req.ID, err)
+			return
+		}
+	} else if stream := s.runningStream(params.ID); stream != nil {
+		resumed = &resumption{stream: stream}
+	} else {
+		s.mu.RLock()
+		task, err := s.store.Get(r.Context(), params.ID)
+		s.mu.RUnlock()
+		if err != nil {
+			s.sendStoreError(w, req.ID, err)
+			return
+		}
+		// A stream of its own, never registered, as there is nothing to resume
+		stream = newTaskStream(task.ID)
+		stream.publish(models.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: boolPtr(true)})
+		stream.finish()
+		resumed = &resumption{stream: stream}
+	}
+
+	out, ok := s.startEventStream(w, r)
+	if !ok {
+		return
+	}
+	s.writeResumed(out, r, req.ID, resumed)
+}
+
+// runningStream returns the unfinished stream of taskID, or nil when it has none
+func (s *A2AServer) runningStream(taskID string) *taskStream {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+	for _, stream := range s.streams {
+		if stream.taskID != taskID {
+			continue
+		}
+		if _, done, _ := stream.since(0); !done {
+			return stream
+		}
+	}
+	return nil
+}
+
+// startEventStream sets the headers of a streamed response, framed as Server-Sent Events when
+// the client accepts text/event-stream, and returns its event writer, or false after answering
+// with an error when w cannot stream. Streams allow any origin unless the server has a CORS
+// policy or none (see WithCORS and WithoutCORS), and ask proxies not to buffer them.
+func (s *A2AServer) startEventStream(w http.ResponseWriter, r *http.Request) (*eventWriter, bool) {
+	sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	if s.cors == nil && !s.noCORS {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+
+	// Check if response writer supports flushing
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return nil, false
+	}
+	return &eventWriter{w: w, flusher: flusher, sse: sse}, true
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// postResubscribe starts a tasks/resubscribe request accepting SSE, resuming after lastEventID
+// if set
+func postResubscribe(t *testing.T, url, taskID, lastEventID string) *http.Response {
+	t.Helper()
+
+	body := `{"jsonrpc":"2.0","id":2,"method":"tasks/resubscribe","params":{"id":"` + taskID + `"}}`
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+// statusEvents decodes the status update events of an SSE body
+func statusEvents(t *testing.T, resp *http.Response) []models.TaskStatusUpdateEvent {
+	t.Helper()
+
+	var body strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&body)
+	var events []models.TaskStatusUpdateEvent
+	for _, data := range sseData(t, body.String()) {
+		var event struct {
+			Result models.TaskStatusUpdateEvent `json:"result"`
+		}
+		json.Unmarshal([]byte(data), &event)
+		events = append(events, event.Result)
+	}
+	return events
+}
+
+func TestResubscribe_RunningTask(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	ts := httptest.NewServer(NewA2AServer(mockAgentCard, handler))
+	defer ts.Close()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	resp := postStream(t, ctx, ts.URL, "running", "")
+	first := readFrame(t, bufio.NewReader(resp.Body))
+	lastEventID, _ := strings.CutPrefix(strings.Split(first, "\n")[0], "id: ")
+	disconnect()
+	resp.Body.Close()
+
+	// Without a Last-Event-ID the stream is replayed from its start, then continues live
+	replayed := postResubscribe(t, ts.URL, "running", "")
+	defer replayed.Body.Close()
+	if frame := readFrame(t, bufio.NewReader(replayed.Body)); !strings.HasPrefix(frame, "id: "+lastEventID+"\n") {
+		t.Errorf("Expected event %s replayed, got %q", lastEventID, frame)
+	}
+	close(release)
+
+	resumed := postResubscribe(t, ts.URL, "running", lastEventID)
+	defer resumed.Body.Close()
+	events := statusEvents(t, resumed)
+	if len(events) != 1 || events[0].Status.State != models.TaskStateCompleted || !*events[0].Final {
+		t.Errorf("Expected only the missed final event, got %+v", events)
+	}
+}
+
+func TestResubscribe_FinishedTask(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "done", "")
+	bufio.NewReader(resp.Body).WriteTo(&strings.Builder{})
+	resp.Body.Close()
+
+	resp = postResubscribe(t, ts.URL, "done", "")
+	defer resp.Body.Close()
+	events := statusEvents(t, resp)
+	if len(events) != 1 || events[0].ID != "done" || events[0].Status.State != models.TaskStateCompleted || !*events[0].Final {
+		t.Errorf("Expected one final event with the task's status, got %+v", events)
+	}
+
+	response := doRPC(t, server, ResubscribeMethod, models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "unknown"}})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected an unknown task not to be found, got %+v", response)
+	}
+}
+
+func TestResubscribe_ReplaysRecordedEvents(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "recorded", "")
+	streamed := statusEvents(t, resp)
+	resp.Body.Close()
+	if len(streamed) != 2 || streamed[0].Sequence != 1 || streamed[1].Sequence != 2 {
+		t.Fatalf("Expected 2 events numbered in order, got %+v", streamed)
+	}
+
+	// The missed events are replayed from the store once the stream has expired
+	fake.Advance(streamRetention)
+	for lastEventID, want := range map[string][]int64{"0": {1, 2}, "1": {2}} {
+		resp := postResubscribe(t, ts.URL, "recorded", lastEventID)
+		var sequences []int64
+		for _, event := range statusEvents(t, resp) {
+			sequences = append(sequences, event.Sequence)
+		}
+		resp.Body.Close()
+		if !slices.Equal(sequences, want) {
+			t.Errorf("Expected events %v after %s, got %v", want, lastEventID, sequences)
+		}
+	}
+
+	// A second stream of the task continues its numbering
+	resp = postStream(t, context.Background(), ts.URL, "recorded", "")
+	defer resp.Body.Close()
+	if events := statusEvents(t, resp); len(events) != 2 || events[0].Sequence != 3 || events[1].Sequence != 4 {
+		t.Errorf("Expected the next stream numbered from 3, got %+v", events)
+	}
+}
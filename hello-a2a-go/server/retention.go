@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrTaskPurged is the cause a running handler's context is canceled with when its task expires
+// and is purged (see WithRetention)
+var ErrTaskPurged = errors.New("task purged")
+
+// defaultReapInterval is how often expired tasks are purged when the policy sets no interval
+const defaultReapInterval = time.Minute
+
+// RetentionPolicy sets how long tasks are kept after their last status change
+type RetentionPolicy struct {
+	// TTLs maps task states to how long a task may stay in them; tasks in states without a
+	// TTL are kept
+	TTLs map[models.TaskState]time.Duration
+	// Interval is how often expired tasks are purged; zero means once a minute
+	Interval time.Duration
+}
+
+// WithRetention purges tasks that have stayed in a state longer than the policy's TTL for it,
+// checking every policy.Interval. A purged task is deleted from the store with its history;
+// subscribers of its stream and its webhook receive a final status event with the purged
+// metadata flag, and a handler still running for it is canceled with ErrTaskPurged.
+func WithRetention(policy RetentionPolicy) Option {
+	return func(s *A2AServer) {
+		if policy.Interval <= 0 {
+			policy.Interval = defaultReapInterval
+		}
+		s.retention = &retention{policy: policy}
+	}
+}
+
+// retention is the task retention policy of a server and the number of tasks it has purged
+type retention struct {
+	policy RetentionPolicy
+	purged atomic.Int64
+}
+
+// TaskList counts the stored tasks
+type TaskList struct {
+	// Total is the number of stored tasks
+	Total int `json:"total"`
+	// States counts the stored tasks in each state
+	States map[models.TaskState]int `json:"states"`
+	// Purged is the number of expired tasks purged since the server started
+	Purged int64 `json:"purged"`
+}
+
+// startReaper purges expired tasks every interval of the retention policy, on the leader only
+// when the server is one of a cluster's replicas
+func (s *A2AServer) startReaper() {
+	s.clock.AfterFunc(s.retention.policy.Interval, func() {
+		if s.stopped() {
+			return
+		}
+		ctx := context.Background()
+		if !s.leading() {
+			s.startReaper()
+			return
+		}
+		if n := s.reap(ctx); n > 0 {
+			s.log(ctx, "").Info("purged expired tasks", slog.Int("count", n))
+		}
+		s.startReaper()
+	})
+}
+
+// reap purges the tasks that have outlived the TTL of their state and returns how many
+func (s *A2AServer) reap(ctx context.Context) int {
+	s.mu.RLock()
+	tasks, err := s.store.ListTasks(ctx, "")
+	s.mu.RUnlock()
+	if err != nil {
+		s.log(ctx, "").Error("failed to list tasks for retention", slog.Any("error", err))
+		return 0
+	}
+
+	purged := 0
+	for _, task := range tasks {
+		if !s.expired(task) {
+			continue
+		}
+		if err := s.purgeTask(ctx, task.ID); err != nil {
+			s.log(ctx, task.ID).Error("failed to purge task", slog.Any("error", err))
+			continue
+		}
+		purged++
+	}
+	return purged
+}
+
+// expired reports whether task has stayed in its state longer than the state's TTL
+func (s *A2AServer) expired(task *models.Task) bool {
+	ttl, ok := s.retention.policy.TTLs[task.Status.State]
+	if !ok || task.Status.Timestamp == "" {
+		return false
+	}
+	changed, err := time.Parse(time.RFC3339Nano, task.Status.Timestamp)
+	if err != nil {
+		return false
+	}
+	return s.clock.Now().Sub(changed) >= ttl
+}
+
+// purgeTask deletes the task taskID, first canceling its running handler, if any, and waiting
+// for it to return. The handler's caller then reports the purge to the task's stream or
+// webhook; a task with no handler running is reported to its webhook here.
+func (s *A2AServer) purgeTask(ctx context.Context, taskID string) error {
+	done := s.cancelRun(taskID, ErrTaskPurged)
+	if done != nil {
+		<-done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, err := s.store.Get(ctx, taskID)
+	if errors.Is(err, ErrTaskNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// A task updated since it was listed is kept until it expires again
+	if done == nil && !s.expired(task) {
+		return nil
+	}
+	if err := s.store.Delete(ctx, taskID); err != nil {
+		return err
+	}
+	if done == nil {
+		s.notify(taskID, purgedEvent(task))
+	}
+	if s.push != nil {
+		s.push.remove(taskID)
+	}
+	s.retention.purged.Add(1)
+	return nil
+}
+
+// purgedEvent returns the final status event reporting that task was purged
+func purgedEvent(task *models.Task) models.TaskStatusUpdateEvent {
+	return models.TaskStatusUpdateEvent{
+		ID:       task.ID,
+		Status:   task.Status,
+		Final:    boolPtr(true),
+		Metadata: map[string]interface{}{"purged": true},
+	}
+}
+
+// TaskList counts the stored tasks per state
+func (s *A2AServer) TaskList(ctx context.Context) (TaskList, error) {
+	s.mu.RLock()
+	tasks, err := s.store.ListTasks(ctx, "")
+	s.mu.RUnlock()
+	if err != nil {
+		return TaskList{}, err
+	}
+
+	list := TaskList{Total: len(tasks), States: make(map[models.TaskState]int)}
+	for _, task := range tasks {
+		list.States[task.Status.State]++
+	}
+	if s.retention != nil {
+		list.Purged = s.retention.purged.Load()
+	}
+	return list, nil
+}
+
+// TaskListHandler serves the task counts as JSON, for mounting on an admin route behind the
+// admin's authentication; the admin API serves them at /admin/tasks
+func (s *A2AServer) TaskListHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.TaskList(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestRetention_PurgesExpiredTasks(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake), WithRetention(RetentionPolicy{
+		TTLs:     map[models.TaskState]time.Duration{models.TaskStateCompleted: time.Hour},
+		Interval: time.Minute,
+	}))
+	for _, id := range []string{"old", "new"} {
+		if response := doRPC(t, server, "tasks/send", models.TaskSendParams{
+			ID:      id,
+			Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+		}); response.Error != nil {
+			t.Fatalf("Failed to send task %s: %v", id, response.Error)
+		}
+		fake.Advance(40 * time.Minute)
+	}
+
+	// old completed 80 minutes ago, new 40 minutes ago
+	if response := doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "old"}}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected the expired task to be purged, got %+v", response)
+	}
+	if response := doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "new"}}); response.Error != nil {
+		t.Errorf("Expected the recent task to be kept, got %v", response.Error)
+	}
+
+	list, err := server.TaskList(context.Background())
+	if err != nil || list.Total != 1 || list.States[models.TaskStateCompleted] != 1 || list.Purged != 1 {
+		t.Errorf("Expected 1 completed task and 1 purged, got %+v (%v)", list, err)
+	}
+
+	// The counts are not part of the public JSON-RPC API
+	if response := doRPC(t, server, "tasks/list", nil); response.Error == nil || response.Error.Code != int(models.ErrorCodeMethodNotFound) {
+		t.Errorf("Expected tasks/list not to be found, got %+v", response)
+	}
+
+	w := httptest.NewRecorder()
+	server.TaskListHandler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/tasks", nil))
+	var served TaskList
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("Failed to decode task list: %v", err)
+	}
+	if served.Total != 1 || served.Purged != 1 {
+		t.Errorf("Expected the served list to match, got %+v", served)
+	}
+}
+
+func TestRetention_PurgesRunningStream(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	started, cause := make(chan struct{}), make(chan error, 1)
+	server := NewA2AServer(mockAgentCard, blockingHandler(started, cause), WithClock(fake), WithKeepAlive(0),
+		WithRetention(RetentionPolicy{
+			TTLs:     map[models.TaskState]time.Duration{models.TaskStateWorking: 5 * time.Minute},
+			Interval: time.Minute,
+		}))
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "stuck", "")
+	defer resp.Body.Close()
+	readFrame(t, bufio.NewReader(resp.Body))
+	<-started
+
+	fake.Advance(5 * time.Minute)
+	if err := <-cause; !errors.Is(err, ErrTaskPurged) {
+		t.Errorf("Expected the handler's context to be canceled with ErrTaskPurged, got %v", err)
+	}
+
+	events := statusEvents(t, resp)
+	if len(events) != 1 || !*events[0].Final || events[0].Metadata["purged"] != true {
+		t.Errorf("Expected a final purged event, got %+v", events)
+	}
+	if response := doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "stuck"}}); response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected the purged task to stay deleted, got %+v", response)
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"a2a/models"
+)
+
+// skillContextKey is the context key for the skill a request names
+type skillContextKey struct{}
+
+// SkillFromContext returns the skill ID the request's metadata names (see SkillMetadataKey),
+// or "" when it names none
+func SkillFromContext(ctx context.Context) string {
+	skillID, _ := ctx.Value(skillContextKey{}).(string)
+	return skillID
+}
+
+// Router is a TaskHandler dispatching each message to the handler of the skill it is addressed
+// to, so that one server hosts several skills:
+//
+//	router := server.NewRouter()
+//	router.Handle(translate, translateHandler)
+//	router.Handle(summarize, summarizeHandler)
+//	card.Skills = router.Skills()
+//	s := server.NewA2AServer(card, router.ServeTask)
+//
+// The skill is named by SkillMetadataKey in the request's or the message's metadata, or by a
+// data part holding {"skillId": "..."}. Messages naming no skill go to the default handler.
+type Router struct {
+	mu       sync.RWMutex
+	routes   map[string]routerEntry
+	fallback TaskHandler
+}
+
+// routerEntry is a skill served by a Router and its handler
+type routerEntry struct {
+	skill   models.AgentSkill
+	handler TaskHandler
+}
+
+// NewRouter returns a Router without skills or default handler
+func NewRouter() *Router {
+	return &Router{routes: make(map[string]routerEntry)}
+}
+
+// Handle routes messages to skill to handler, replacing any handler of the same skill ID.
+// Messages with parts whose content type is not one of the skill's input modes are rejected.
+func (r *Router) Handle(skill models.AgentSkill, handler TaskHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[skill.ID] = routerEntry{skill: skill, handler: handler}
+}
+
+// Default routes messages naming no skill to handler
+func (r *Router) Default(handler TaskHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = handler
+}
+
+// Skills returns the skills routed, ordered by ID, to declare them on the agent card
+func (r *Router) Skills() []models.AgentSkill {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	skills := make([]models.AgentSkill, 0, len(r.routes))
+	for _, entry := range r.routes {
+		skills = append(skills, entry.skill)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+	return skills
+}
+
+// ServeTask is the Router's TaskHandler. It fails with an unsupported operation error for
+// unknown skills and messages naming no skill without a default handler, and with a content
+// type not supported error for parts the skill does not accept.
+func (r *Router) ServeTask(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	skillID := skillOf(ctx, message)
+
+	r.mu.RLock()
+	entry, ok := r.routes[skillID]
+	fallback := r.fallback
+	r.mu.RUnlock()
+
+	switch {
+	case skillID == "" && fallback != nil:
+		return fallback(ctx, task, message)
+	case skillID == "":
+		return task, models.NewUnsupportedOperationError("Message names no skill")
+	case !ok:
+		return task, models.NewUnsupportedOperationError("Unknown skill: " + skillID)
+	}
+	if mimeType, ok := acceptsParts(entry.skill, message); !ok {
+		return task, models.NewContentTypeNotSupportedError("Skill " + skillID + " does not accept " + mimeType)
+	}
+	return entry.handler(ctx, task, message)
+}
+
+// skillOf returns the skill message is addressed to
+func skillOf(ctx context.Context, message *models.Message) string {
+	if skillID := SkillFromContext(ctx); skillID != "" {
+		return skillID
+	}
+	if skillID, _ := message.Metadata[SkillMetadataKey].(string); skillID != "" {
+		return skillID
+	}
+	for _, part := range message.Parts {
+		if skillID := routingSkill(part); skillID != "" {
+			return skillID
+		}
+	}
+	return ""
+}
+
+// routingSkill returns the skill ID named by part when it is a data part holding one
+func routingSkill(part models.Part) string {
+	data, ok := part.(models.DataPart)
+	if !ok {
+		return ""
+	}
+	fields, _ := data.Data.(map[string]interface{})
+	skillID, _ := fields[SkillMetadataKey].(string)
+	return skillID
+}
+
+// acceptsParts reports whether skill accepts the content type of every part of message, or
+// else returns the first type it does not; skills without input modes accept any part
+func acceptsParts(skill models.AgentSkill, message *models.Message) (string, bool) {
+	if len(skill.InputModes) == 0 {
+		return "", true
+	}
+	for _, part := range message.Parts {
+		var mimeType string
+		switch part := part.(type) {
+		case models.TextPart:
+			mimeType = "text/plain"
+		case models.DataPart:
+			if routingSkill(part) != "" {
+				continue
+			}
+			mimeType = "application/json"
+		case models.FilePart:
+			mimeType = part.MimeType
+		}
+		if !acceptsMode(skill.InputModes, part.GetPartType(), mimeType) {
+			return mimeType, false
+		}
+	}
+	return "", true
+}
+
+// acceptsMode reports whether a part of kind with mimeType matches one of modes, which may be
+// MIME types, wildcards such as "image/*" or part kinds such as "text"
+func acceptsMode(modes []string, kind, mimeType string) bool {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	for _, mode := range modes {
+		prefix, wildcard := strings.CutSuffix(mode, "*")
+		if mode == mimeType || mode == kind || mode == "*/*" || wildcard && strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
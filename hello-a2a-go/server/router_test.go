@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"a2a/models"
+)
+
+// replyWith returns a handler completing tasks with an artifact holding text
+func replyWith(text string) TaskHandler {
+	return func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Artifacts = []models.Artifact{{Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}}
+		return task, nil
+	}
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	router.Handle(models.AgentSkill{ID: "translate", Name: "Translate", InputModes: []string{"text"}}, replyWith("translated"))
+	router.Handle(models.AgentSkill{ID: "describe", Name: "Describe", InputModes: []string{"image/*"}}, replyWith("described"))
+	router.Default(replyWith("default"))
+
+	card := mockAgentCard
+	card.Skills = router.Skills()
+	if len(card.Skills) != 2 || card.Skills[0].ID != "describe" || card.Skills[1].ID != "translate" {
+		t.Fatalf("Expected the routed skills ordered by ID, got %+v", card.Skills)
+	}
+	server := NewA2AServer(card, router.ServeTask)
+
+	text := models.TextPart{Type: "text", Text: "Bonjour"}
+	image := models.FilePart{Type: "file", MimeType: "image/png", Content: models.FileContentBytes{Type: "bytes", Bytes: []byte{0x89}}}
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		message  models.Message
+		want     string
+		code     models.ErrorCode
+	}{
+		{"request metadata", map[string]interface{}{SkillMetadataKey: "translate"}, models.Message{Parts: []models.Part{text}}, "translated", 0},
+		{"message metadata", nil, models.Message{Parts: []models.Part{image}, Metadata: map[string]interface{}{SkillMetadataKey: "describe"}}, "described", 0},
+		{"data part", nil, models.Message{Parts: []models.Part{models.DataPart{Type: "data", Data: map[string]interface{}{SkillMetadataKey: "translate"}}, text}}, "translated", 0},
+		{"default", nil, models.Message{Parts: []models.Part{text}}, "default", 0},
+		{"unknown skill", nil, models.Message{Parts: []models.Part{text}, Metadata: map[string]interface{}{SkillMetadataKey: "unknown"}}, "", models.ErrorCodeUnsupportedOperation},
+		{"unsupported content", nil, models.Message{Parts: []models.Part{image}, Metadata: map[string]interface{}{SkillMetadataKey: "translate"}}, "", models.ErrorCodeContentTypeNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.message.Role = "user"
+			response := doRPC(t, server, "message/send", models.MessageSendParams{ID: tt.name, Message: tt.message, Metadata: tt.metadata})
+			if tt.code != 0 {
+				if response.Error == nil || response.Error.Code != int(tt.code) {
+					t.Errorf("Expected error code %d, got %+v", tt.code, response.Error)
+				}
+				return
+			}
+			var task models.Task
+			decodeResult(t, response.Result, &task)
+			if response.Error != nil || len(task.Artifacts) == 0 || task.Artifacts[0].Parts[0].(models.TextPart).Text != tt.want {
+				t.Errorf("Expected the %s handler to run, got %+v (%v)", tt.want, task, response.Error)
+			}
+		})
+	}
+
+	// Without a default handler, messages must name a skill
+	_, err := NewRouter().ServeTask(context.Background(), &models.Task{}, &models.Message{Parts: []models.Part{text}})
+	if models.ErrorCodeOf(err) != models.ErrorCodeUnsupportedOperation {
+		t.Errorf("Expected an unsupported operation error, got %v", err)
+	}
+}
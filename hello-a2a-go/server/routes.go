@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RegisterRoutes mounts the server's endpoints on mux using method-aware patterns:
+//
+//	POST /a2a                              JSON-RPC requests
+//	POST /a2a/stream                       streaming JSON-RPC requests
+//	GET  /.well-known/agent-card           the agent card
+//	GET  /.well-known/jwks.json            the key verifying the card's signature (see WithCardSigningKey)
+//	GET  /v1/tasks/{id}                    a stored task
+//	POST /v1/tasks/{id}/files              upload a file of a task (see WithFileStore)
+//	GET  /v1/tasks/{id}/files/{file}       download a file of a task
+//	PUT  /artifacts/{id}                   upload an artifact under its SHA-256 digest (see WithArtifactStore)
+//	GET  /artifacts/{id}                   download an artifact
+//	GET  /agent/authenticatedExtendedCard  the extended agent card (see WithExtendedAgentCard)
+//	GET  /metrics                          Prometheus metrics (see WithMetrics)
+//	POST /v1/chat/completions              OpenAI-compatible chat completions (see WithChatCompletions)
+//	GET  /v1/models                        the models of the chat completion API
+//	GET  /ui/                              a web UI for trying the agent (see WithUI)
+//
+// Other methods on these paths are answered with 405 Method Not Allowed and an Allow header.
+// middleware is applied, first outermost, to every endpoint except the public agent card, its
+// key, metrics and the web UI, e.g. RequireSignature, outside any middleware added with Use.
+// With WithCORS, the endpoints apply the CORS policy outside middleware, and OPTIONS requests
+// on their paths are answered as preflight requests.
+func (s *A2AServer) RegisterRoutes(mux *http.ServeMux, middleware ...func(http.Handler) http.Handler) {
+	protect := func(h http.Handler) http.Handler {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			h = middleware[i](h)
+		}
+		return h
+	}
+	preflights := make(map[string]bool)
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, s.withCORS(h))
+		if _, path, _ := strings.Cut(pattern, " "); s.cors != nil && !preflights[path] {
+			preflights[path] = true
+			mux.HandleFunc("OPTIONS "+path, s.servePreflight)
+		}
+	}
+
+	handle("POST /a2a", protect(s))
+	handle("POST /a2a/stream", protect(s))
+	handle("GET /.well-known/agent-card", http.HandlerFunc(s.ServeAgentCard))
+	if s.cardSigner != nil {
+		handle("GET "+JWKSPath, http.HandlerFunc(s.ServeJWKS))
+	}
+	handle("GET /v1/tasks/{id}", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.taskHandler.ServeHTTP(w, r)
+	})))
+	files := protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fileHandler.ServeHTTP(w, r)
+	}))
+	handle("POST /v1/tasks/{id}/files", files)
+	handle("GET /v1/tasks/{id}/files/{file}", files)
+	artifacts := protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.artifactHandler.ServeHTTP(w, r)
+	}))
+	handle("PUT /artifacts/{id}", artifacts)
+	handle("GET /artifacts/{id}", artifacts)
+	handle("GET /agent/authenticatedExtendedCard", protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.extendedCardHandler.ServeHTTP(w, r)
+	})))
+	if s.metrics != nil {
+		handle("GET /metrics", s.MetricsHandler())
+	}
+	if s.chatCompletions {
+		chat := protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.chatHandler.ServeHTTP(w, r)
+		}))
+		handle("POST /v1/chat/completions", chat)
+		handle("GET /v1/models", chat)
+	}
+	if s.ui {
+		mux.Handle("GET /ui/", s.UIHandler())
+		mux.Handle("GET /ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+	}
+}
+
+// serveTask writes the task named by the id path parameter as JSON
+func (s *A2AServer) serveTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	task, err := s.store.Get(r.Context(), r.PathValue("id"))
+	s.mu.RUnlock()
+	if errors.Is(err, ErrTaskNotFound) {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
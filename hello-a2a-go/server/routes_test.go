@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_RegisterRoutes(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	send := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"routed","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantAllow  string
+	}{
+		{"json-rpc", "POST", "/a2a", send, http.StatusOK, ""},
+		{"json-rpc with wrong method", "GET", "/a2a", "", http.StatusMethodNotAllowed, "POST"},
+		{"agent card", "GET", "/.well-known/agent-card", "", http.StatusOK, ""},
+		{"agent card with wrong method", "POST", "/.well-known/agent-card", "", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"stored task", "GET", "/v1/tasks/routed", "", http.StatusOK, ""},
+		{"unknown task", "GET", "/v1/tasks/missing", "", http.StatusNotFound, ""},
+		{"task with wrong method", "DELETE", "/v1/tasks/routed", "", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"unknown path", "GET", "/nowhere", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Expected Allow %q, got %q", tt.wantAllow, allow)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/v1/tasks/routed", nil))
+	var task models.Task
+	if err := json.NewDecoder(w.Body).Decode(&task); err != nil {
+		t.Fatalf("Failed to decode task: %v", err)
+	}
+	if task.ID != "routed" || task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected completed task routed, got %+v", task)
+	}
+}
+
+func TestA2AServer_RegisterRoutesMiddleware(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "denied", http.StatusUnauthorized)
+		})
+	}
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux, deny)
+
+	for _, path := range []string{"/a2a", "/a2a/stream"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader("{}")))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected middleware to guard %s, got status %d", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/agent-card", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected agent card to stay public, got status %d", w.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"a2a/models"
+)
+
+// validateData checks the data parts of the message in params against the input schema of the
+// skill it is addressed to (see requestSkill), answering with an invalid params error listing
+// the violations and reporting false when they do not match. Messages to skills without an
+// input schema pass.
+func (s *A2AServer) validateData(w http.ResponseWriter, r *http.Request, id interface{}, params models.TaskSendParams) bool {
+	schema := s.inputSchema(s.requestSkill(r.Context(), params))
+	if len(schema) == 0 {
+		return true
+	}
+
+	var violations []models.SchemaViolation
+	for i, part := range params.Message.Parts {
+		data, ok := part.(models.DataPart)
+		if !ok {
+			continue
+		}
+		prefix := "/message/parts/" + strconv.Itoa(i) + "/data"
+		for _, v := range schema.Validate(data.Data) {
+			v.Pointer = prefix + v.Pointer
+			violations = append(violations, v)
+		}
+	}
+	if len(violations) == 0 {
+		return true
+	}
+	s.sendA2AError(w, id, models.NewInvalidDataError(violations))
+	return false
+}
+
+// inputSchema returns the input schema of the skill skillID on the served card, or nil
+func (s *A2AServer) inputSchema(skillID string) models.JSONSchema {
+	if skillID == "" {
+		return nil
+	}
+	s.skillsMu.RLock()
+	defer s.skillsMu.RUnlock()
+	for _, skill := range s.agentCard.Skills {
+		if skill.ID == skillID {
+			return skill.InputSchema
+		}
+	}
+	return nil
+}
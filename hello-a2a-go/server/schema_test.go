@@ -0,0 +1,75 @@
+package server
+
+import (
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_ValidatesDataParts(t *testing.T) {
+	card := mockAgentCard
+	card.Skills = []models.AgentSkill{{ID: "chat", Name: "Chat"}, {
+		ID:   "convert",
+		Name: "Convert",
+		InputSchema: models.JSONSchema{
+			"type":       "object",
+			"required":   []string{"amount", "currency"},
+			"properties": map[string]interface{}{"amount": map[string]interface{}{"type": "number", "minimum": 0}},
+		},
+	}}
+	server := NewA2AServer(card, mockTaskHandler)
+	send := func(method string, data interface{}) models.JSONRPCResponse {
+		return doRPC(t, server, method, models.MessageSendParams{
+			ID: "convert-1",
+			Message: models.Message{Role: "user", Parts: []models.Part{
+				models.TextPart{Type: "text", Text: "Convert this"},
+				models.DataPart{Type: "data", Data: data},
+			}},
+			Metadata: map[string]interface{}{SkillMetadataKey: "convert"},
+		})
+	}
+
+	if response := send("message/send", map[string]interface{}{"amount": 12.5, "currency": "EUR"}); response.Error != nil {
+		t.Errorf("Expected matching data to be accepted, got %v", response.Error)
+	}
+
+	for _, method := range []string{"message/send", "message/stream"} {
+		response := send(method, map[string]interface{}{"amount": -1})
+		if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+			t.Fatalf("Expected %s to reject the data with invalid params, got %+v", method, response)
+		}
+		var data struct {
+			Violations []models.SchemaViolation `json:"violations"`
+		}
+		decodeResult(t, response.Error.Data, &data)
+		want := []models.SchemaViolation{
+			{Pointer: "/message/parts/1/data/currency", Message: "is required"},
+			{Pointer: "/message/parts/1/data/amount", Message: "must be at least 0"},
+		}
+		if len(data.Violations) != 2 || data.Violations[0] != want[0] || data.Violations[1] != want[1] {
+			t.Errorf("Expected violations %v, got %v", want, data.Violations)
+		}
+	}
+
+	// Messages are addressed to the skill by their own metadata too
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		ID: "convert-2",
+		Message: models.Message{
+			Role:     "user",
+			Parts:    []models.Part{models.DataPart{Type: "data", Data: map[string]interface{}{"amount": 3}}},
+			Metadata: map[string]interface{}{SkillMetadataKey: "convert"},
+		},
+	})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+		t.Errorf("Expected data addressed by message metadata to be validated, got %+v", response)
+	}
+
+	// Messages to the default skill, which has no schema, are not validated
+	response = doRPC(t, server, "message/send", models.MessageSendParams{
+		ID:      "other",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.DataPart{Type: "data", Data: "free-form"}}},
+	})
+	if response.Error != nil {
+		t.Errorf("Expected data without a skill to be accepted, got %v", response.Error)
+	}
+}
@@ -1,66 +1,327 @@
 package server
 
 import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"sync"
+	"time"
 
+	"a2a/clock"
 	"a2a/models"
+	"a2a/trace"
 )
 
-// TaskHandler is a function type that handles task processing
-type TaskHandler func(task *models.Task, message *models.Message) (*models.Task, error)
+// TaskHandler is a function type that handles task processing.
+// The context carries request-scoped values such as the caller's locale (see LocaleFromContext).
+// Handlers report results as task.Artifacts; the returned task, with its artifacts, history and
+// metadata, is persisted and serialized back to the caller; see Replying for handlers answering
+// with a message instead. The context is canceled with ErrTaskCanceled when the task is canceled
+// with tasks/cancel.
+type TaskHandler func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error)
 
 // A2AServer represents an A2A server instance
 type A2AServer struct {
-	agentCard   models.AgentCard
-	handler     TaskHandler
-	port        int
-	basePath    string
-	taskStore   map[string]*models.Task
-	taskHistory map[string][]*models.Message
-	mu          sync.RWMutex
+	agentCard models.AgentCard
+	handler   TaskHandler
+	port      int
+	basePath  string
+	store     TaskStore
+	limits    Limits
+	clock     clock.Clock
+	mu        sync.RWMutex
+
+	// skillRoutes binds skill IDs to dedicated handlers and hooks; guarded with agentCard by skillsMu
+	skillRoutes map[string]*skillRoute
+	skillsMu    sync.RWMutex
+
+	// usage records per-caller and per-skill consumption; nil disables accounting
+	usage      UsageStore
+	callerFunc func(*http.Request) string
+	// quotas enforces per-caller limits; nil disables enforcement
+	quotas *quotas
+	// rateLimit bounds each caller's request rate; nil disables rate limiting
+	rateLimit *rateLimiter
+	// replay rejects replayed authenticated requests; nil disables the check
+	replay *replayGuard
+	// journal records task lifecycle events written to store; nil disables journaling
+	journal *Journal
+	// audit writes the audit log; nil disables auditing
+	audit *auditor
+	// chaos injects faults into requests; nil disables fault injection
+	chaos *chaosInjector
+	// throttle coalesces streamed artifact chunks of skills without their own throttle
+	throttle StreamThrottle
+	// timeouts bound the handler runs of tasks
+	timeouts timeouts
+	// keepAlive is the interval of SSE keep-alive comments; zero disables them
+	keepAlive time.Duration
+	// backpressure bounds the events each stream subscriber may have unsent
+	backpressure StreamBackpressure
+	// streams buffers the events of streaming tasks for resuming clients; guarded by streamsMu
+	streams   map[string]*taskStream
+	streamsMu sync.Mutex
+	// running holds the tasks whose handlers are running, for tasks/cancel; guarded by runningMu
+	running   map[string]*runningTask
+	runningMu sync.Mutex
+	// push delivers task updates to registered webhooks; nil disables push notifications
+	push *pushDispatcher
+	// middleware wraps rpcHandler, taskHandler, fileHandler, artifactHandler,
+	// extendedCardHandler and chatHandler, the JSON-RPC, task, file, artifact, extended card and
+	// chat completion endpoints (see Use)
+	middleware          []func(http.Handler) http.Handler
+	rpcHandler          http.Handler
+	taskHandler         http.Handler
+	fileHandler         http.Handler
+	artifactHandler     http.Handler
+	extendedCardHandler http.Handler
+	chatHandler         http.Handler
+	// chatCompletions mounts chatHandler in RegisterRoutes
+	chatCompletions bool
+	// ui mounts the embedded web UI at /ui/ in RegisterRoutes
+	ui bool
+	// cors lets browser origins call the routes of RegisterRoutes; nil only lets any origin
+	// read streamed responses, unless noCORS disables that too
+	cors   *CORSConfig
+	noCORS bool
+	// files keeps uploaded files and large file artifacts; nil disables the file endpoints
+	files       FileStore
+	inlineLimit int
+	// fetch resolves the URI file parts of incoming messages for handlers; nil hands them over
+	// as sent
+	fetch *FetchPolicy
+	// blobs keeps content addressed artifacts, taking over large file artifacts from files; nil
+	// disables the artifact endpoints
+	blobs           BlobStore
+	blobInlineLimit int
+	// metrics collects the metrics served at /metrics; nil disables collection
+	metrics *metrics
+	// bearerAuth verifies the bearer tokens of requests; nil leaves them unchecked
+	bearerAuth TokenVerifier
+	// extendedCard is served to authenticated callers; nil disables the extended card
+	extendedCard *models.AgentCard
+	// cardSigner signs the served agent card; nil serves it unsigned
+	cardSigner *cardSigner
+	// tls holds the certificates to serve HTTPS with; nil serves plain HTTP
+	tls *tlsFiles
+	// pool runs task handlers with bounded concurrency; nil runs them on the request goroutine
+	pool *workerPool
+	// skillLimits bounds the handlers of each skill the pool runs at once
+	skillLimits map[string]int
+	// retention purges expired tasks; nil keeps tasks forever
+	retention *retention
+	// retry runs handlers failing with a RetryableError again and keeps the dead-letter list;
+	// nil fails tasks on the first error
+	retry *taskRetry
+	// apiKeys enables RequireAPIKey, with keys kept in keys, the task store when it is a KeyStore
+	apiKeys bool
+	keys    KeyStore
+	// logger receives the server's log records; nil uses slog.Default
+	logger *slog.Logger
+	// compression lists the content encodings responses may be compressed in, by preference
+	compression []string
+	// bus publishes task lifecycle events to in-process subscribers (see Subscribe)
+	bus eventBus
+	// admin configures the admin API; nil disables it
+	admin *AdminConfig
+	// cluster stamps tasks with the replica's instance ID and elects the replica running the
+	// reaper; nil runs as a single server
+	cluster *cluster
+	// closed is closed by Close to stop the background jobs
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-func NewA2AServer(agentCard models.AgentCard, handler func(*models.Task, *models.Message) (*models.Task, error)) *A2AServer {
-	return &A2AServer{
-		agentCard:   agentCard,
-		handler:     handler,
-		taskStore:   make(map[string]*models.Task),
-		taskHistory: make(map[string][]*models.Message),
+// NewA2AServer creates a server for agentCard that processes tasks with handler
+func NewA2AServer(agentCard models.AgentCard, handler TaskHandler, opts ...Option) *A2AServer {
+	s := &A2AServer{
+		agentCard: agentCard,
+		handler:   handler,
+		store:     NewMemoryTaskStore(),
+		clock:     clock.Real,
+
+		skillRoutes: make(map[string]*skillRoute),
+		keepAlive:   defaultKeepAlive,
+		streams:     make(map[string]*taskStream),
+		running:     make(map[string]*runningTask),
+		closed:      make(chan struct{}),
+	}
+	s.rpcHandler = s.wrap(http.HandlerFunc(s.serveRPC))
+	s.taskHandler = s.wrap(http.HandlerFunc(s.serveTask))
+	s.fileHandler = s.wrap(http.HandlerFunc(s.serveFiles))
+	s.artifactHandler = s.wrap(http.HandlerFunc(s.serveArtifacts))
+	s.extendedCardHandler = s.wrap(http.HandlerFunc(s.serveExtendedCard))
+	s.chatHandler = s.wrap(http.HandlerFunc(s.serveChat))
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.quotas != nil {
+		s.quotas.now = s.clock.Now
+	}
+	if s.replay != nil {
+		s.replay.now = s.clock.Now
+	}
+	if s.chaos != nil && s.chaos.config.Clock == nil {
+		s.chaos.clock = s.clock
+	}
+	if s.push != nil {
+		s.push.clock = s.clock
+		s.push.logger = s.logger
+	}
+	// Keys are looked up in the task store before it is wrapped with the journal
+	if s.apiKeys {
+		s.keys, _ = s.store.(KeyStore)
+		s.Use(s.RequireAPIKey())
+	}
+	if s.journal != nil {
+		s.store = journaledStore{TaskStore: s.store, journal: s.journal, clock: s.clock, logger: s.logger}
+	}
+	if s.bearerAuth != nil {
+		s.requireBearerAuth()
+	}
+	if s.pool != nil && s.skillLimits != nil {
+		s.pool.setLimits(s.skillLimits)
+	}
+	if s.cluster != nil && s.cluster.config.Locker != nil {
+		s.startElection()
+	}
+	if s.retention != nil {
+		s.startReaper()
+	}
+	return s
+}
+
+// supportedMethods lists the JSON-RPC methods dispatched by ServeHTTP
+var supportedMethods = []string{
+	"message/send",
+	"message/stream",
+	MessageListMethod,
+	ContextGetMethod,
+	"tasks/send",
+	"tasks/get",
+	"tasks/cancel",
+	ResubscribeMethod,
+	SetPushNotificationMethod,
+	GetPushNotificationMethod,
+	TaskHistoryMethod,
+	ExtendedCardMethod,
+}
+
+// Close stops the server's background jobs, the leader election of WithCluster and the task
+// reaper of WithRetention. A replica gives its leadership up, so that another takes the lease
+// over once it expires. Start and Agent.ListenAndServe close the server when they return.
+func (s *A2AServer) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		if s.cluster != nil {
+			s.cluster.leader.Store(false)
+		}
+	})
+	return nil
+}
+
+// stopped reports whether the server was closed
+func (s *A2AServer) stopped() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
 	}
 }
 
-// Start starts the A2A server
+// Start starts the A2A server, over HTTPS when configured with WithTLS
 func (s *A2AServer) Start() error {
 	mux := http.NewServeMux()
-	mux.Handle(s.basePath, s)
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), mux)
+	s.RegisterRoutes(mux)
+	if s.basePath != "" && s.basePath != "/a2a" {
+		mux.Handle("POST "+s.basePath, s)
+	}
+	return s.serve(fmt.Sprintf(":%d", s.port), mux)
 }
 
 // ServeHTTP implements the http.Handler interface
 func (s *A2AServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.rpcHandler.ServeHTTP(w, r)
+}
+
+// serveRPC serves a JSON-RPC request, injecting faults first when chaos is enabled
+func (s *A2AServer) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if s.chaos != nil {
+		s.chaos.serve(w, r, http.HandlerFunc(s.serveJSONRPC))
+		return
+	}
+	s.serveJSONRPC(w, r)
+}
+
+// serveJSONRPC decodes a JSON-RPC request and dispatches it to the method's handler
+func (s *A2AServer) serveJSONRPC(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	r = s.withAuditCaller(s.negotiateExtensions(w, r))
 
-	var req models.JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		// Return JSON-RPC error response with ErrorCodeInvalidRequest
-		response := models.JSONRPCResponse{
-			JSONRPCMessage: models.JSONRPCMessage{
-				JSONRPC: "2.0",
-			},
-			Error: &models.JSONRPCError{
-				Code:    int(models.ErrorCodeInvalidRequest),
-				Message: "Invalid JSON: " + err.Error(),
-			},
+	if s.replay != nil {
+		if err := s.replay.check(r); err != nil {
+			s.auditAuth(r, "replay", err.Error())
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
 		}
+	}
+
+	if s.limits.MaxRequestBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.limits.MaxRequestBytes)
+	}
+
+	body := bufio.NewReader(r.Body)
+	if isBatch(body) {
+		s.serveBatch(w, r, body)
+		return
+	}
+
+	// Keep numbers as json.Number so large integers in params survive re-encoding
+	var req models.JSONRPCRequest
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		s.sendDecodeError(w, err)
+		return
+	}
+	s.dispatch(w, r, req)
+}
+
+// sendDecodeError reports a request body that could not be decoded: bodies over the size limit
+// are invalid requests, anything else failed to parse
+func (s *A2AServer) sendDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		s.sendA2AError(w, nil, models.NewInvalidRequestError("Invalid JSON: "+err.Error()))
+	} else {
+		s.sendA2AError(w, nil, models.NewParseError(err.Error()))
+	}
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+// dispatch serves the decoded JSON-RPC request req with the method's handler
+func (s *A2AServer) dispatch(w http.ResponseWriter, r *http.Request, req models.JSONRPCRequest) {
+	r, span := traceRPC(r, &req)
+	defer span.End()
+	if taskID := taskIDOf(req.Params); taskID != "" {
+		r = setTaskID(w, r, taskID)
+	}
+	s.metrics.countRequest(req.Method)
+	if !s.allowRequest(w, r, req.ID) {
+		return
+	}
+	if !s.requireExtensions(w, r, req.ID) {
 		return
 	}
 
@@ -70,172 +331,440 @@ func (s *A2AServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		var params models.TaskSendParams
 		paramsBytes, err := json.Marshal(req.Params)
 		if err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
-		if err := json.Unmarshal(paramsBytes, &params); err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
 
 		// Check if client wants streaming response
 		if r.Header.Get("Accept") == "text/event-stream" {
-			s.handleStreamingTask(w, r, params)
+			s.handleStreamingTask(w, r, req.ID, params)
 			return
 		}
 
-		s.handleTaskSendWithID(w, &req, req.ID)
+		s.handleTaskSendWithID(w, r, &req, req.ID)
 	case "tasks/get":
-		s.handleTaskGetWithID(w, &req, req.ID)
+		s.handleTaskGetWithID(w, r, &req, req.ID)
 	case "tasks/cancel":
-		s.handleTaskCancelWithID(w, &req, req.ID)
+		s.handleTaskCancelWithID(w, r, &req, req.ID)
 	// A2A v0.3.0 methods
 	case "message/send":
 		// Convert MessageSendParams to TaskSendParams for compatibility
 		var msgParams models.MessageSendParams
 		paramsBytes, err := json.Marshal(req.Params)
 		if err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
-		if err := json.Unmarshal(paramsBytes, &msgParams); err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		if err := models.DecodeJSON(paramsBytes, &msgParams); err != nil {
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
 
-		// Convert to TaskSendParams
-		taskParams := models.TaskSendParams{
-			ID:      msgParams.ID,
-			Message: msgParams.Message,
-		}
+		taskParams := toTaskSendParams(msgParams)
 
 		// Check if client wants streaming response
 		if r.Header.Get("Accept") == "text/event-stream" {
-			s.handleStreamingTask(w, r, taskParams)
+			s.handleStreamingTask(w, r, req.ID, taskParams)
 			return
 		}
 
 		// Update request params for legacy handler
 		req.Params = taskParams
-		s.handleTaskSendWithID(w, &req, req.ID)
-	case "message/list":
-		s.handleTaskGetWithID(w, &req, req.ID)
+		s.handleTaskSendWithID(w, acceptReplies(r), &req, req.ID)
+	case MessageListMethod:
+		s.handleMessageList(w, r, &req)
+	case ContextGetMethod:
+		s.handleContextGet(w, r, &req)
 	case "message/stream":
 		// Convert MessageSendParams to TaskSendParams for compatibility
 		var msgParams models.MessageSendParams
 		paramsBytes, err := json.Marshal(req.Params)
 		if err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
-		if err := json.Unmarshal(paramsBytes, &msgParams); err != nil {
-			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		if err := models.DecodeJSON(paramsBytes, &msgParams); err != nil {
+			s.sendErrorWithID(w, req.ID, models.ErrorCodeInvalidParams, "Invalid parameters")
 			return
 		}
 
-		// Convert to TaskSendParams
-		taskParams := models.TaskSendParams{
-			ID:      msgParams.ID,
-			Message: msgParams.Message,
-		}
-
-		s.handleStreamingTask(w, r, taskParams)
+		taskParams := toTaskSendParams(msgParams)
+
+		s.handleStreamingTask(w, r, req.ID, taskParams)
+	case SetPushNotificationMethod:
+		s.handleSetPushNotification(w, r, &req)
+	case GetPushNotificationMethod:
+		s.handleGetPushNotification(w, r, &req)
+	case ResubscribeMethod:
+		s.handleResubscribe(w, r, &req)
+	case TaskHistoryMethod:
+		s.handleTaskHistory(w, r, &req)
+	case ExtendedCardMethod:
+		s.handleExtendedCard(w, &req)
 	default:
-		s.sendErrorWithID(w, req.ID, models.ErrorCodeMethodNotFound, "Method not found")
+		s.sendA2AError(w, req.ID, models.NewMethodNotFoundError(req.Method))
+	}
+}
+
+// toTaskSendParams converts MessageSendParams to TaskSendParams for the legacy handlers. The
+// task is named by the legacy id parameter or else by the message's taskId.
+func toTaskSendParams(msgParams models.MessageSendParams) models.TaskSendParams {
+	taskParams := models.TaskSendParams{
+		ID:       msgParams.ID,
+		Message:  msgParams.Message,
+		Metadata: msgParams.Metadata,
+	}
+	if taskParams.ID == "" {
+		taskParams.ID = msgParams.Message.TaskID
+	}
+	if taskParams.ID == "" {
+		// A message naming no task starts a new one
+		taskParams.ID = newTaskID()
 	}
+	if msgParams.Config != nil {
+		taskParams.PushNotification = msgParams.Config.PushNotifications
+		taskParams.AcceptedOutputModes = msgParams.Config.AcceptedOutputModes
+		taskParams.Blocking = msgParams.Config.Blocking
+		taskParams.HistoryLength = msgParams.Config.HistoryLength
+	}
+	return taskParams
 }
 
-// handleTaskSend handles the tasks/send method
-func (s *A2AServer) handleTaskSend(w http.ResponseWriter, req *models.JSONRPCRequest, id string) {
+func (s *A2AServer) handleTaskSend(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id string) {
 	var params models.TaskSendParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+
+	handler, err := s.resolveHandler(params.Metadata)
+	if err != nil {
+		s.sendError(w, id, models.ErrorCodeUnsupportedOperation, err.Error())
+		return
+	}
+	if !s.allowSkill(w, r, id, params) || !s.validateData(w, r, id, params) {
+		return
+	}
+	if !s.checkOutputModes(w, id, params) {
+		return
+	}
+	if !s.admitRequest(w, r, id) {
+		return
+	}
+	if !s.registerPush(w, id, params) {
 		return
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Create new task
-	task := &models.Task{
-		ID: params.ID,
-		Status: models.TaskStatus{
-			State: models.TaskStateWorking,
-		},
+	// Create new task, recording that it is working
+	task := s.newTask(r.Context(), &params)
+	if err := s.saveTask(r.Context(), task); err != nil {
+		s.sendA2AError(w, id, models.NewInternalError(err.Error()))
+		return
 	}
 
 	// Process task
-	updatedTask, err := s.handler(task, &params.Message)
+	updatedTask, err := s.runHandler(r, params, handler, task)
+	if errors.Is(err, ErrTaskPurged) {
+		s.notify(task.ID, purgedEvent(task))
+		s.sendA2AError(w, id, handlerError(err))
+		return
+	}
 	if err != nil {
-		s.sendError(w, id, models.ErrorCodeInternalError, err.Error())
+		s.failTask(r.Context(), task, err)
+		s.sendA2AError(w, id, handlerError(err))
 		return
 	}
 
 	// Store task and history
-	s.taskStore[task.ID] = updatedTask
-	s.taskHistory[task.ID] = append(s.taskHistory[task.ID], &params.Message)
+	if err := s.storeTask(r.Context(), updatedTask, &params.Message); err != nil {
+		s.sendError(w, id, models.ErrorCodeInternalError, err.Error())
+		return
+	}
+	s.notifyResult(r.Context(), updatedTask)
 
 	// Send response
-	s.sendResponse(w, id, updatedTask)
+	s.sendResponse(w, id, withHistoryLength(updatedTask, params.HistoryLength))
 }
 
 // handleTaskGet handles the tasks/get method
-func (s *A2AServer) handleTaskGet(w http.ResponseWriter, req *models.JSONRPCRequest, id string) {
+func (s *A2AServer) handleTaskGet(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id string) {
 	var params models.TaskQueryParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	task, exists := s.taskStore[params.ID]
-	if !exists {
-		s.sendError(w, id, models.ErrorCodeTaskNotFound, "Task not found")
+	task, err := s.store.Get(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, id, err)
 		return
 	}
 
-	s.sendResponse(w, id, task)
+	s.sendResponse(w, id, withHistoryLength(task, params.HistoryLength))
 }
 
 // handleTaskCancel handles the tasks/cancel method
-func (s *A2AServer) handleTaskCancel(w http.ResponseWriter, req *models.JSONRPCRequest, id string) {
+func (s *A2AServer) handleTaskCancel(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id string) {
 	var params models.TaskIDParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendError(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendError(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	task, err := s.cancelTask(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, id, err)
+		return
+	}
+
+	s.sendResponse(w, id, task)
+}
+
+// storeTask saves task along with the message that created or continued it
+func (s *A2AServer) storeTask(ctx context.Context, task *models.Task, message *models.Message) error {
+	if err := s.saveTask(ctx, task); err != nil {
+		return fmt.Errorf("failed to store task: %w", err)
+	}
+	if err := s.store.AppendMessage(ctx, task.ID, message); err != nil {
+		return fmt.Errorf("failed to store message: %w", err)
+	}
+	return nil
+}
 
-	task, exists := s.taskStore[params.ID]
-	if !exists {
-		s.sendError(w, id, models.ErrorCodeTaskNotFound, "Task not found")
+// saveTask stamps task's status with the current time, offloads large file artifacts and saves
+// it, counting the state transition in the server's metrics and publishing it on the event bus
+func (s *A2AServer) saveTask(ctx context.Context, task *models.Task) error {
+	task.Status.Timestamp = s.clock.Now().UTC().Format(time.RFC3339Nano)
+	s.stampInstance(task)
+	if err := s.offloadFiles(ctx, task); err != nil {
+		return err
+	}
+	if err := s.store.Save(ctx, task); err != nil {
+		return err
+	}
+	s.metrics.countTransition(task)
+	s.publishSaved(ctx, task)
+	return nil
+}
+
+// failTask logs err and saves task as failed after its handler returned it, with a status
+// message carrying the error's details next to any message the handler set; the caller holds
+// s.mu
+func (s *A2AServer) failTask(ctx context.Context, task *models.Task, err error) {
+	s.log(ctx, task.ID).Error("task handler failed", slog.Any("error", err))
+	task.Status = models.TaskStatus{State: models.TaskStateFailed, Message: failureMessage(task.Status.Message, err)}
+	stampMessage(task.Status.Message, task.ID, task.ContextID, len(task.History))
+	task.History = append(task.History, *task.Status.Message)
+	if err := s.saveTask(ctx, task); err != nil {
+		s.log(ctx, task.ID).Error("failed to store task", slog.Any("error", err))
+	}
+}
+
+// sendStoreError reports a failed task lookup, distinguishing unknown tasks and A2A errors
+// from store failures
+func (s *A2AServer) sendStoreError(w http.ResponseWriter, id interface{}, err error) {
+	var a2aErr *models.A2AError
+	if errors.As(err, &a2aErr) {
+		s.sendA2AError(w, id, a2aErr)
 		return
 	}
+	if errors.Is(err, ErrTaskNotFound) {
+		s.sendA2AError(w, id, models.NewA2AError(models.ErrorCodeTaskNotFound, "Task not found"))
+		return
+	}
+	s.sendErrorWithID(w, id, models.ErrorCodeInternalError, err.Error())
+}
 
-	// Update task status to canceled
-	task.Status.State = models.TaskStateCanceled
-	s.taskStore[params.ID] = task
+// newTask creates the working task for params. It belongs to the conversation named by the
+// message's contextId or the legacy sessionId, or else keeps the context of the task it
+// replaces; a task with none of these starts a new conversation. The history of a replaced
+// task is continued, as are the artifacts and metadata of one awaiting input. The message is
+// stamped with the task and conversation it belongs to, and audited as received.
+func (s *A2AServer) newTask(ctx context.Context, params *models.TaskSendParams) *models.Task {
+	existing, err := s.store.Get(ctx, params.ID)
+	if err != nil {
+		existing = nil
+	}
 
-	s.sendResponse(w, id, task)
+	contextID := params.Message.ContextID
+	if contextID == "" && params.SessionID != nil {
+		contextID = *params.SessionID
+	}
+	if contextID == "" && existing != nil {
+		contextID = existing.ContextID
+	}
+	if contextID == "" {
+		contextID = newContextID()
+	}
+
+	var history []models.Message
+	if existing != nil {
+		history = append(history, existing.History...)
+	}
+	stampMessage(&params.Message, params.ID, contextID, len(history))
+	task := &models.Task{
+		ID:        params.ID,
+		ContextID: contextID,
+		SessionID: params.SessionID,
+		Status: models.TaskStatus{
+			State: models.TaskStateWorking,
+		},
+		History: append(history, params.Message),
+	}
+	// A task awaiting input resumes with what it has produced so far
+	if existing != nil && existing.Status.State == models.TaskStateInputRequired {
+		task.Artifacts, task.Metadata = existing.Artifacts, existing.Metadata
+	}
+	s.auditMessage(ctx, task, &params.Message)
+	return task
+}
+
+// stampMessage fills in the task, conversation and ID of message, the seq-th message of the
+// task's history, where missing, so that the messages of multi-turn conversations can be
+// correlated
+func stampMessage(message *models.Message, taskID, contextID string, seq int) {
+	if message.TaskID == "" {
+		message.TaskID = taskID
+	}
+	if message.ContextID == "" {
+		message.ContextID = contextID
+	}
+	if message.MessageID == "" {
+		message.MessageID = fmt.Sprintf("%s-%d", taskID, seq)
+	}
+}
+
+// newContextID returns a random conversation ID
+func newContextID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// newTaskID returns a random task ID
+func newTaskID() string {
+	return newContextID()
+}
+
+// runHandler invokes handler for task with a context carrying the request's locale, output
+// mode and trace context, metering its consumption when usage accounting or quotas are
+// enabled. The context is canceled with ErrTaskCanceled when a client cancels the task, which
+// then ends canceled whatever the handler returns.
+func (s *A2AServer) runHandler(r *http.Request, params models.TaskSendParams, handler TaskHandler, task *models.Task) (*models.Task, error) {
+	ctx := withTaskID(withRequestLocale(r.Context(), r, params.Metadata), task.ID)
+	ctx, span := trace.Start(ctx, "a2a.handler", trace.SpanKindInternal, slog.String("a2a.task_id", task.ID))
+	defer span.End()
+	skillID, _ := params.Metadata[SkillMetadataKey].(string)
+	if skillID != "" {
+		span.SetAttributes(slog.String("a2a.skill", skillID))
+		ctx = context.WithValue(ctx, skillContextKey{}, skillID)
+	}
+	if mode, _ := s.outputMode(params); mode != "" {
+		ctx = context.WithValue(ctx, outputModeContextKey{}, mode)
+	}
+	start, handlerDone := s.clock.Now(), s.metrics.handlerStarted(skillID)
+	defer func() { handlerDone(s.clock.Now().Sub(start)) }()
+	ctx, finish := s.startRun(ctx, task.ID)
+	defer finish()
+	_, streaming := ctx.Value(artifactEmitterKey{}).(*artifactEmitter)
+	ctx, stopTimeouts := s.startTimeouts(ctx, streaming)
+	defer stopTimeouts()
+	if !streaming && s.push != nil {
+		// Without a stream, intermediate status updates still reach the task's webhook; its
+		// artifacts are sent with the result
+		emitter := &artifactEmitter{
+			taskID:     task.ID,
+			clock:      s.clock,
+			send:       func(models.TaskArtifactUpdateEvent) {},
+			sendStatus: func(event models.TaskStatusUpdateEvent) { s.notify(task.ID, event) },
+		}
+		defer emitter.close()
+		ctx = context.WithValue(ctx, artifactEmitterKey{}, emitter)
+	}
+	contextID, history := task.ContextID, task.History
+	message, err := s.fetchFiles(ctx, task.ID, params.Message)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	result, attempts, err := s.retryHandler(ctx, task, func(task *models.Task) (*models.Task, error) {
+		if s.usage == nil && s.quotas == nil {
+			return handler(ctx, task, &message)
+		}
+		return s.meterHandler(ctx, r, skillID, handler, task, &message)
+	})
+	span.RecordError(err)
+	if errors.Is(context.Cause(ctx), ErrTaskPurged) {
+		// The task is deleted once the handler returns, so its result is discarded
+		return nil, ErrTaskPurged
+	}
+	if errors.Is(context.Cause(ctx), ErrTaskCanceled) {
+		if result == nil {
+			result = task
+		}
+		result.Status = models.TaskStatus{State: models.TaskStateCanceled}
+		err = nil
+	}
+	if errors.Is(context.Cause(ctx), ErrTaskTimedOut) {
+		if result == nil {
+			result = task
+		}
+		result, err = timedOut(ctx, result), nil
+	}
+	if err != nil && s.retry != nil {
+		s.deadLetter(ctx, params, task, attempts, err)
+	}
+	if result == nil {
+		return result, err
+	}
+	// Handlers returning a fresh task stay in the conversation
+	if result.ContextID == "" {
+		result.ContextID = contextID
+	}
+	if len(result.History) == 0 {
+		result.History = history
+	}
+	if result.Status.Message != nil {
+		stampMessage(result.Status.Message, result.ID, result.ContextID, len(result.History))
+		result.History = append(result.History, *result.Status.Message)
+	}
+	return result, err
+}
+
+// withHistoryLength returns task with its history cut to the most recent n messages, leaving
+// task itself unchanged; a nil n keeps the full history
+func withHistoryLength(task *models.Task, n *int) *models.Task {
+	if n == nil || *n < 0 || len(task.History) <= *n {
+		return task
+	}
+	trimmed := *task
+	trimmed.History = task.History[len(task.History)-*n:]
+	return &trimmed
 }
 
 // sendResponse sends a JSON-RPC response
@@ -256,25 +785,16 @@ func (s *A2AServer) sendResponse(w http.ResponseWriter, id string, result interf
 
 // sendError sends a JSON-RPC error response
 func (s *A2AServer) sendError(w http.ResponseWriter, id string, code models.ErrorCode, message string) {
-	response := models.JSONRPCResponse{
-		JSONRPCMessage: models.JSONRPCMessage{
-			JSONRPC: "2.0",
-			JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{
-				ID: id,
-			},
-		},
-		Error: &models.JSONRPCError{
-			Code:    int(code),
-			Message: message,
-		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	s.sendA2AError(w, id, models.NewA2AError(code, message))
 }
 
 // sendErrorWithID sends a JSON-RPC error response with flexible ID handling
 func (s *A2AServer) sendErrorWithID(w http.ResponseWriter, id interface{}, code models.ErrorCode, message string) {
+	s.sendA2AError(w, id, models.NewA2AError(code, message))
+}
+
+// sendA2AError sends err as a JSON-RPC error response to the request id
+func (s *A2AServer) sendA2AError(w http.ResponseWriter, id interface{}, err *models.A2AError) {
 	response := models.JSONRPCResponse{
 		JSONRPCMessage: models.JSONRPCMessage{
 			JSONRPC: "2.0",
@@ -282,16 +802,77 @@ func (s *A2AServer) sendErrorWithID(w http.ResponseWriter, id interface{}, code
 				ID: id,
 			},
 		},
-		Error: &models.JSONRPCError{
-			Code:    int(code),
-			Message: message,
-		},
+		Error: err.JSONRPC(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// handlerError returns the JSON-RPC error reporting a failed handler: an A2AError it returned,
+// content type not supported for unsupported images and audio, or else an internal error that
+// withholds the error's details, which may describe the server's internals; failTask logs them
+func handlerError(err error) *models.A2AError {
+	var a2aErr *models.A2AError
+	switch {
+	case errors.As(err, &a2aErr):
+	case errors.Is(err, models.ErrNotImage), errors.Is(err, models.ErrNotAudio):
+		a2aErr = models.NewContentTypeNotSupportedError(err.Error())
+	case errors.Is(err, ErrTaskPurged):
+		a2aErr = models.NewA2AError(models.ErrorCodeTaskNotFound, "Task was purged")
+	default:
+		a2aErr = models.NewInternalError("Internal error")
+	}
+	if hint := hintOf(err); hint != "" && a2aErr.Data == nil {
+		withHint := *a2aErr
+		withHint.Data = map[string]interface{}{"hint": hint}
+		return &withHint
+	}
+	return a2aErr
+}
+
+// hintedError is a handler error annotated with a remediation hint
+type hintedError struct {
+	err  error
+	hint string
+}
+
+func (e *hintedError) Error() string { return e.err.Error() }
+
+func (e *hintedError) Unwrap() error { return e.err }
+
+// Hint annotates a handler error with a hint on how the client can fix its request, e.g. "send
+// a PNG or JPEG image". The hint travels with the error to the client, in the failed task's
+// status message and the JSON-RPC error's data. It returns nil for a nil err.
+func Hint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &hintedError{err: err, hint: hint}
+}
+
+// hintOf returns the hint of the first error in err's chain annotated with Hint, or ""
+func hintOf(err error) string {
+	var hinted *hintedError
+	if errors.As(err, &hinted) {
+		return hinted.hint
+	}
+	return ""
+}
+
+// failureMessage returns the status message of a task failed with err: message, as set by the
+// handler, with the error's details added, or else a message describing err
+func failureMessage(message *models.Message, err error) *models.Message {
+	a2aErr := handlerError(err)
+	details := models.NewErrorMessage(models.TaskError{Code: a2aErr.Code, Message: a2aErr.Message, Hint: hintOf(err)})
+	if message == nil {
+		return details
+	}
+	withDetails := *message
+	withDetails.Parts = append(slices.Clip(message.Parts), details.Parts[1])
+	return &withDetails
+}
+
 // sendResponseWithID sends a JSON-RPC response with flexible ID handling
 func (s *A2AServer) sendResponseWithID(w http.ResponseWriter, id interface{}, result interface{}) {
 	response := models.JSONRPCResponse{
@@ -309,202 +890,398 @@ func (s *A2AServer) sendResponseWithID(w http.ResponseWriter, id interface{}, re
 }
 
 // handleTaskSendWithID handles the tasks/send method with flexible ID handling
-func (s *A2AServer) handleTaskSendWithID(w http.ResponseWriter, req *models.JSONRPCRequest, id interface{}) {
+func (s *A2AServer) handleTaskSendWithID(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id interface{}) {
 	var params models.TaskSendParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
+		return
+	}
+
+	handler, err := s.resolveHandler(params.Metadata)
+	if err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeUnsupportedOperation, err.Error())
+		return
+	}
+	if !s.allowSkill(w, r, id, params) || !s.validateData(w, r, id, params) {
+		return
+	}
+	if !s.checkOutputModes(w, id, params) {
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if !s.admitRequest(w, r, id) {
+		return
+	}
+	if !s.registerPush(w, id, params) {
+		return
+	}
+	if params.Blocking != nil && !*params.Blocking {
+		s.sendAcceptedTask(w, r, id, params, handler)
+		return
+	}
+	if s.pool != nil {
+		s.sendQueuedTask(w, r, id, params, handler)
 		return
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Create new task
-	task := &models.Task{
-		ID: params.ID,
-		Status: models.TaskStatus{
-			State: models.TaskStateWorking,
-		},
+	// Create new task, recording that it is working
+	task := s.newTask(r.Context(), &params)
+	if err := s.saveTask(r.Context(), task); err != nil {
+		s.sendA2AError(w, id, models.NewInternalError(err.Error()))
+		return
 	}
 
 	// Process task
-	updatedTask, err := s.handler(task, &params.Message)
+	updatedTask, err := s.runHandler(r, params, handler, task)
+	if errors.Is(err, ErrTaskPurged) {
+		s.notify(task.ID, purgedEvent(task))
+		s.sendA2AError(w, id, handlerError(err))
+		return
+	}
 	if err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInternalError, err.Error())
+		s.failTask(r.Context(), task, err)
+		s.sendA2AError(w, id, handlerError(err))
 		return
 	}
 
 	// Store task and history
-	s.taskStore[task.ID] = updatedTask
-	s.taskHistory[task.ID] = append(s.taskHistory[task.ID], &params.Message)
+	if err := s.storeTask(r.Context(), updatedTask, &params.Message); err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeInternalError, err.Error())
+		return
+	}
+	s.notifyResult(r.Context(), updatedTask)
 
 	// Send response
-	s.sendResponseWithID(w, id, updatedTask)
+	s.sendResponseWithID(w, id, sendResult(r, updatedTask, params.HistoryLength))
+}
+
+// sendAcceptedTask answers a non-blocking message/send or tasks/send with the task as soon as
+// it is accepted, submitted unless a worker already picked it up, and runs it in the background
+// as the worker pool does; the client follows it with tasks/get, tasks/resubscribe or push
+// notifications
+func (s *A2AServer) sendAcceptedTask(w http.ResponseWriter, r *http.Request, id interface{}, params models.TaskSendParams, handler TaskHandler) {
+	// The task outlives the connection
+	hr := r.WithContext(context.WithoutCancel(r.Context()))
+	if !s.enqueueTask(hr.Context(), &params, func(submitted bool) {
+		s.runQueuedTask(hr, params, handler, submitted)
+	}) {
+		s.sendA2AError(w, id, errServerBusy())
+		return
+	}
+
+	s.mu.RLock()
+	task, err := s.store.Get(r.Context(), params.ID)
+	s.mu.RUnlock()
+	if err != nil {
+		s.sendStoreError(w, id, err)
+		return
+	}
+	s.sendResponseWithID(w, id, withHistoryLength(task, params.HistoryLength))
 }
 
 // handleTaskGetWithID handles the tasks/get method with flexible ID handling
-func (s *A2AServer) handleTaskGetWithID(w http.ResponseWriter, req *models.JSONRPCRequest, id interface{}) {
+func (s *A2AServer) handleTaskGetWithID(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id interface{}) {
 	var params models.TaskQueryParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	task, exists := s.taskStore[params.ID]
-	if !exists {
-		s.sendErrorWithID(w, id, models.ErrorCodeTaskNotFound, "Task not found")
+	task, err := s.store.Get(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, id, err)
 		return
 	}
 
-	s.sendResponseWithID(w, id, task)
+	s.sendResponseWithID(w, id, withHistoryLength(task, params.HistoryLength))
 }
 
 // handleTaskCancelWithID handles the tasks/cancel method with flexible ID handling
-func (s *A2AServer) handleTaskCancelWithID(w http.ResponseWriter, req *models.JSONRPCRequest, id interface{}) {
+func (s *A2AServer) handleTaskCancelWithID(w http.ResponseWriter, r *http.Request, req *models.JSONRPCRequest, id interface{}) {
 	var params models.TaskIDParams
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		s.sendErrorWithID(w, id, models.ErrorCodeInvalidRequest, "Invalid parameters")
+	if err := models.DecodeJSON(paramsBytes, &params); err != nil {
+		s.sendErrorWithID(w, id, models.ErrorCodeInvalidParams, "Invalid parameters")
 		return
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	task, exists := s.taskStore[params.ID]
-	if !exists {
-		s.sendErrorWithID(w, id, models.ErrorCodeTaskNotFound, "Task not found")
+	task, err := s.cancelTask(r.Context(), params.ID)
+	if err != nil {
+		s.sendStoreError(w, id, err)
 		return
 	}
 
-	// Update task status to canceled
-	task.Status.State = models.TaskStateCanceled
-	s.taskStore[params.ID] = task
-
 	s.sendResponseWithID(w, id, task)
 }
 
-func (s *A2AServer) handleStreamingTask(w http.ResponseWriter, r *http.Request, params models.TaskSendParams) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// handleStreamingTask runs a task and streams its updates. Clients accepting text/event-stream
+// receive Server-Sent Events identified by their sequence numbers, with keep-alive comments, and
+// may resume a broken stream by repeating the request with a Last-Event-ID header to receive
+// exactly the events they missed (see resume); other clients receive one JSON value per line.
+// The task keeps running when the client disconnects, so that it can resume.
+func (s *A2AServer) handleStreamingTask(w http.ResponseWriter, r *http.Request, id interface{}, params models.TaskSendParams) {
+	var resumed *resumption
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		var err error
+		if resumed, err = s.resume(r.Context(), lastEventID, params.ID); err != nil {
+			s.sendResumeError(w, id, err)
+			return
+		}
+	}
 
-	// Check if response writer supports flushing
-	flusher, ok := w.(http.Flusher)
+	var handler TaskHandler
+	if resumed == nil {
+		var err error
+		if handler, err = s.resolveHandler(params.Metadata); err != nil {
+			s.sendErrorWithID(w, id, models.ErrorCodeUnsupportedOperation, err.Error())
+			return
+		}
+		if !s.allowSkill(w, r, id, params) || !s.validateData(w, r, id, params) {
+			return
+		}
+		if !s.checkOutputModes(w, id, params) {
+			return
+		}
+		if !s.admitRequest(w, r, id) {
+			return
+		}
+		if !s.registerPush(w, id, params) {
+			return
+		}
+	}
+
+	out, ok := s.startEventStream(w, r)
 	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	// Create a channel to receive task updates
-	updates := make(chan interface{})
-
-	// Create a done channel to signal when the goroutine is finished
-	done := make(chan struct{})
-
-	// Start task processing in a goroutine
-	go func() {
-		defer func() {
-			close(updates) // Close updates channel when goroutine exits
-			close(done)    // Signal that goroutine is done
-		}()
-
-		// Recover from any panics to ensure channels are closed
-		defer func() {
-			if r := recover(); r != nil {
-				// Log the panic (you might want to use a proper logger)
-				fmt.Printf("Recovered from panic in streaming task: %v\n", r)
-			}
-		}()
+	if resumed == nil {
+		// The task outlives this handler, so it runs with its own copy of the request, whose
+		// body has already been read
+		tr := r.Clone(context.WithoutCancel(r.Context()))
+		tr.Body = http.NoBody
+		stream := s.openStream(params.ID)
+		if s.pool == nil {
+			go s.runStreamingTask(tr, params, handler, stream, false)
+		} else if !s.enqueueStreamingTask(tr, params, handler, stream) {
+			s.closeStream(stream)
+			s.sendA2AError(w, id, errServerBusy())
+			return
+		}
+		resumed = &resumption{stream: stream}
+	}
+	s.writeResumed(out, r, id, resumed)
+}
 
-		s.mu.Lock()
-		// Create new task
-		task := &models.Task{
-			ID: params.ID,
-			Status: models.TaskStatus{
-				State: models.TaskStateWorking,
-			},
+// runStreamingTask runs the task of a streaming request, publishing its updates to stream. A
+// task saved submitted by the worker pool is not run if it was canceled while queued.
+func (s *A2AServer) runStreamingTask(r *http.Request, params models.TaskSendParams, handler TaskHandler, stream *taskStream, submitted bool) {
+	defer s.closeStream(stream)
+
+	// Every update is numbered and recorded, then goes to the stream's subscribers and the
+	// task's webhook, and artifact chunks to the event bus
+	publish := func(event interface{}) {
+		event = s.publishEvent(context.WithoutCancel(r.Context()), stream, event)
+		s.metrics.countStreamEvent(event)
+		s.notify(params.ID, event)
+		if update, ok := event.(models.TaskArtifactUpdateEvent); ok {
+			s.publishArtifact(r.Context(), params.ID, update.Artifact)
 		}
-		s.taskStore[task.ID] = task
-		s.taskHistory[task.ID] = append(s.taskHistory[task.ID], &params.Message)
-		s.mu.Unlock()
+	}
 
-		// Send initial status update
-		updates <- models.TaskStatusUpdateEvent{
-			ID:     task.ID,
-			Status: task.Status,
-			Final:  boolPtr(false),
+	// Recover from any panics to ensure the stream is finished
+	defer func() {
+		if v := recover(); v != nil {
+			s.log(r.Context(), params.ID).Error("recovered from panic in streaming task", slog.Any("panic", v))
 		}
+	}()
 
-		// Process task using the handler field
-		updatedTask, err := s.handler(task, &params.Message)
-		if err != nil {
-			// Send error status update
-			updates <- models.TaskStatusUpdateEvent{
-				ID: task.ID,
-				Status: models.TaskStatus{
-					State: models.TaskStateFailed,
-				},
-				Final: boolPtr(true),
-			}
+	// The task outlives the connection, so a client can resume its stream
+	ctx := context.WithoutCancel(r.Context())
+
+	s.mu.Lock()
+	if submitted {
+		if canceled := s.canceledWhileQueued(ctx, params.ID); canceled != nil {
+			s.mu.Unlock()
+			publish(models.TaskStatusUpdateEvent{ID: canceled.ID, Status: canceled.Status, Final: boolPtr(true)})
 			return
 		}
+	}
+	// Create new task
+	task := s.newTask(ctx, &params)
+	err := s.storeTask(ctx, task, &params.Message)
+	s.mu.Unlock()
+	if err != nil {
+		publish(models.TaskStatusUpdateEvent{
+			ID:     task.ID,
+			Status: models.TaskStatus{State: models.TaskStateFailed},
+			Final:  boolPtr(true),
+		})
+		return
+	}
 
-		// Update task in store
+	// Send initial status update
+	publish(models.TaskStatusUpdateEvent{
+		ID:     task.ID,
+		Status: task.Status,
+		Final:  boolPtr(false),
+	})
+
+	// Stream artifacts and status updates emitted by the handler, coalescing artifact chunks
+	// per the skill's throttle
+	emitter := &artifactEmitter{
+		taskID:     task.ID,
+		throttle:   s.streamThrottle(params.Metadata),
+		clock:      s.clock,
+		send:       func(event models.TaskArtifactUpdateEvent) { publish(event) },
+		sendStatus: func(event models.TaskStatusUpdateEvent) { publish(event) },
+	}
+	// Stop pending flushes before the stream finishes, even if the handler panics
+	defer emitter.close()
+	hr := r.WithContext(context.WithValue(ctx, artifactEmitterKey{}, emitter))
+
+	// Process task using the handler resolved for the requested skill
+	updatedTask, err := s.runHandler(hr, params, handler, task)
+	emitter.close()
+	if errors.Is(err, ErrTaskPurged) {
+		publish(purgedEvent(task))
+		return
+	}
+	if err != nil {
 		s.mu.Lock()
-		s.taskStore[task.ID] = updatedTask
+		s.failTask(ctx, task, err)
 		s.mu.Unlock()
-
-		// Send final status update
-		updates <- models.TaskStatusUpdateEvent{
-			ID:     updatedTask.ID,
-			Status: updatedTask.Status,
+		// Send error status update
+		publish(models.TaskStatusUpdateEvent{
+			ID:     task.ID,
+			Status: task.Status,
 			Final:  boolPtr(true),
+		})
+		return
+	}
+
+	// Update task in store
+	s.mu.Lock()
+	if err := s.saveTask(ctx, updatedTask); err != nil {
+		s.log(ctx, updatedTask.ID).Error("failed to store task", slog.Any("error", err))
+	}
+	s.mu.Unlock()
+
+	// Send final status update
+	publish(models.TaskStatusUpdateEvent{
+		ID:     updatedTask.ID,
+		Status: updatedTask.Status,
+		Final:  boolPtr(true),
+	})
+}
+
+// writeResumed writes the events a resumed stream replays, then those of its stream
+func (s *A2AServer) writeResumed(out *eventWriter, r *http.Request, id interface{}, resumed *resumption) {
+	for _, update := range resumed.replay {
+		if !s.writeEvent(out, r, id, strconv.FormatInt(eventSequence(update), 10), update) {
+			return
 		}
-	}()
+	}
+	if resumed.stream != nil {
+		s.writeStream(out, r, id, resumed.stream, resumed.next)
+	}
+}
 
-	// Stream updates to the client
-	encoder := json.NewEncoder(w)
+// writeStream writes the events of stream after the first next to out as responses to the
+// request id, until the stream finishes or the client disconnects
+func (s *A2AServer) writeStream(out *eventWriter, r *http.Request, id interface{}, stream *taskStream, next int) {
+	sub := stream.subscribe(next)
+	defer stream.unsubscribe(sub)
 	for {
-		select {
-		case update, ok := <-updates:
-			if !ok {
-				// Channel closed, we're done
+		batch := stream.read(sub)
+		if batch.dropped > 0 || batch.evicted {
+			s.metrics.countDroppedEvents(stream.backpressure.Policy, batch.dropped)
+			s.log(r.Context(), stream.taskID).Warn("slow stream subscriber missed events",
+				slog.Any("rpc_id", id), slog.Int("dropped", batch.dropped),
+				slog.String("policy", string(stream.backpressure.Policy)))
+			marker := stream.droppedEvent(batch)
+			if !s.writeEvent(out, r, id, stream.eventID(batch.start, marker), marker) || batch.evicted {
 				return
 			}
-			resp := models.SendTaskStreamingResponse{
-				Result: update,
-				Error:  nil,
+		}
+		for i, update := range batch.events {
+			if !s.writeEvent(out, r, id, stream.eventID(batch.start+i+1, update), update) {
+				return
 			}
+			stream.sent(sub)
+		}
+		if batch.done {
+			return
+		}
 
-			if err := encoder.Encode(resp); err != nil {
+		var keepAlive <-chan time.Time
+		if out.sse && s.keepAlive > 0 {
+			keepAlive = s.clock.After(s.keepAlive)
+		}
+		select {
+		case <-batch.changed:
+		case <-keepAlive:
+			if err := out.keepAlive(); err != nil {
 				return
 			}
-			flusher.Flush()
 		case <-r.Context().Done():
 			// Client disconnected
 			return
-		case <-done:
-			// Goroutine finished
-			return
 		}
 	}
 }
+
+// writeEvent writes update to out as the event eventID responding to the request id, and
+// reports whether the stream may go on
+func (s *A2AServer) writeEvent(out *eventWriter, r *http.Request, id interface{}, eventID string, update interface{}) bool {
+	resp := models.SendTaskStreamingResponse{
+		JSONRPCResponse: models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id},
+			},
+		},
+		Result: update,
+		Error:  nil,
+	}
+
+	event, err := json.Marshal(resp)
+	if err != nil {
+		return false
+	}
+	if max := s.limits.MaxEventBytes; max > 0 && int64(len(event)) > max {
+		s.log(r.Context(), "").Warn("dropping stream: event exceeds limit",
+			slog.Any("rpc_id", id), slog.Int("bytes", len(event)), slog.Int64("limit", max))
+		resp.Result = nil
+		resp.Error = &models.A2AError{
+			JSONRPCError: models.JSONRPCError{
+				Message: fmt.Sprintf("stream event of %d bytes exceeds limit of %d bytes", len(event), max),
+			},
+			Code: models.ErrorCodeInternalError,
+		}
+		if event, err = json.Marshal(resp); err == nil {
+			out.event(eventID, event)
+		}
+		return false
+	}
+	return out.event(eventID, event) == nil
+}
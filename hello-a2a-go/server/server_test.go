@@ -2,24 +2,34 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"a2a/clock"
 	"a2a/models"
+	"a2a/trace"
 )
 
 // mockTaskHandler is a simple task handler for testing
-func mockTaskHandler(task *models.Task, message *models.Message) (*models.Task, error) {
+func mockTaskHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
 	task.Status.State = models.TaskStateCompleted
 	return task, nil
 }
 
+// mockInputHandler is a task handler leaving tasks awaiting input, so they can still be canceled
+func mockInputHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+	task.Status.State = models.TaskStateInputRequired
+	return task, nil
+}
+
 // mockErrorTaskHandler is a task handler that returns an error for testing
-func mockErrorTaskHandler(task *models.Task, message *models.Message) (*models.Task, error) {
+func mockErrorTaskHandler(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
 	return nil, fmt.Errorf("test error")
 }
 
@@ -73,7 +83,7 @@ func TestA2AServer_HandleTaskSend(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -139,7 +149,7 @@ func TestA2AServer_HandleTaskGet(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -215,7 +225,7 @@ func TestA2AServer_HandleTaskGet(t *testing.T) {
 }
 
 func TestA2AServer_HandleTaskCancel(t *testing.T) {
-	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	server := NewA2AServer(mockAgentCard, mockInputHandler)
 	server.port = 8080
 	server.basePath = "/"
 
@@ -225,7 +235,7 @@ func TestA2AServer_HandleTaskCancel(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -327,8 +337,8 @@ func TestErrorResponse(t *testing.T) {
 		t.Error("Expected error, got nil")
 	}
 
-	if response.Error.Code != int(models.ErrorCodeInvalidRequest) {
-		t.Errorf("Expected error code %d, got %d", models.ErrorCodeInvalidRequest, response.Error.Code)
+	if response.Error.Code != int(models.ErrorCodeParseError) {
+		t.Errorf("Expected error code %d, got %d", models.ErrorCodeParseError, response.Error.Code)
 	}
 }
 
@@ -343,7 +353,7 @@ func TestA2AServer_HandleStreamingTask(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -382,8 +392,8 @@ func TestA2AServer_HandleStreamingTask(t *testing.T) {
 	}
 
 	// Parse the streaming response
-	// The response should contain multiple JSON objects, one per line
-	responseLines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	// The response should contain multiple events, each carrying a JSON object
+	responseLines := sseData(t, w.Body.String())
 	if len(responseLines) < 2 {
 		t.Errorf("Expected at least 2 response lines, got %d", len(responseLines))
 	}
@@ -466,7 +476,7 @@ func TestA2AServer_HandleStreamingTaskError(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -499,8 +509,8 @@ func TestA2AServer_HandleStreamingTaskError(t *testing.T) {
 	}
 
 	// Parse the streaming response
-	// The response should contain multiple JSON objects, one per line
-	responseLines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	// The response should contain multiple events, each carrying a JSON object
+	responseLines := sseData(t, w.Body.String())
 	if len(responseLines) < 2 {
 		t.Errorf("Expected at least 2 response lines, got %d", len(responseLines))
 	}
@@ -583,7 +593,7 @@ func TestA2AServer_HandleStreamingTaskNoFlusher(t *testing.T) {
 		Message: models.Message{
 			Role: "user",
 			Parts: []models.Part{
-				{Text: stringPtr("Hello")},
+				models.TextPart{Type: "text", Text: "Hello"},
 			},
 		},
 	}
@@ -624,3 +634,335 @@ func testStringPtr(s string) *string {
 func testBoolPtr(b bool) *bool {
 	return &b
 }
+
+func TestA2AServer_TaskTimestamps(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	server := NewA2AServer(mockAgentCard, mockInputHandler, WithClock(fake))
+
+	call := func(method string, params interface{}) models.Task {
+		body, _ := json.Marshal(models.JSONRPCRequest{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: "1"},
+			},
+			Method: method,
+			Params: params,
+		})
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBuffer(body)))
+
+		var response struct {
+			Result models.Task `json:"result"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response.Result
+	}
+
+	task := call("tasks/send", models.TaskSendParams{
+		ID:      "timestamped",
+		Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}},
+	})
+	if want := start.Format(time.RFC3339Nano); task.Status.Timestamp != want {
+		t.Errorf("Expected timestamp %s, got %s", want, task.Status.Timestamp)
+	}
+
+	fake.Advance(time.Hour)
+	task = call("tasks/cancel", models.TaskIDParams{ID: "timestamped"})
+	if want := start.Add(time.Hour).Format(time.RFC3339Nano); task.Status.Timestamp != want {
+		t.Errorf("Expected canceled timestamp %s, got %s", want, task.Status.Timestamp)
+	}
+}
+
+func TestA2AServer_PreservesLargeIntegers(t *testing.T) {
+	var got int64
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		if part, ok := message.Parts[0].(models.DataPart); ok {
+			payload, _ := part.Data.(map[string]interface{})
+			got, _ = models.Int64Value(payload["orderId"])
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"big","message":{"role":"user","parts":[{"kind":"data","data":{"orderId":9007199254740993}}]}}}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	if got != 9007199254740993 {
+		t.Errorf("Expected orderId 9007199254740993 to reach the handler, got %d", got)
+	}
+}
+
+func TestA2AServer_StreamingEventLimit(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{
+			Role:  "agent",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: strings.Repeat("x", 4096)}},
+		}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithMaxEventBytes(1024))
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"big","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var last models.SendTaskStreamingResponse
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("Failed to decode last event: %v", err)
+	}
+	if last.Error == nil || !strings.Contains(last.Error.Message, "exceeds limit of 1024 bytes") {
+		t.Errorf("Expected an event size error, got %s", lines[len(lines)-1])
+	}
+	for _, line := range lines {
+		if len(line) > 1024 {
+			t.Errorf("Expected no event above the limit, got %d bytes", len(line))
+		}
+	}
+}
+
+func TestA2AServer_PropagatesTraceContext(t *testing.T) {
+	var got trace.Context
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		got, _ = trace.FromContext(ctx)
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+
+	for _, method := range []string{"message/send", "message/stream"} {
+		got = trace.Context{}
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":{"id":"traced","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req.Header.Set("baggage", "tenant=acme")
+		server.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" || got.Baggage != "tenant=acme" {
+			t.Errorf("%s: expected the handler context to carry the trace context, got %+v", method, got)
+		}
+	}
+}
+
+func TestA2AServer_PersistsArtifactsAndHistory(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Done"}}}
+		task.Artifacts = append(task.Artifacts, models.Artifact{
+			Name:  stringPtr("translation"),
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Bonjour"}},
+		})
+		task.Metadata = map[string]interface{}{"model": "test"}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+
+	send := func(text string) models.Task {
+		t.Helper()
+		params := models.TaskSendParams{
+			ID:        "persisted",
+			SessionID: testStringPtr("session-1"),
+			Message:   models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}},
+		}
+		response := doRPC(t, server, "tasks/send", params)
+		if response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+		var task models.Task
+		decodeResult(t, response.Result, &task)
+		return task
+	}
+
+	task := send("Hello")
+	if len(task.Artifacts) != 1 || task.Artifacts[0].Parts[0].(models.TextPart).Text != "Bonjour" {
+		t.Fatalf("Expected the artifact in the response, got %+v", task.Artifacts)
+	}
+	if task.SessionID == nil || *task.SessionID != "session-1" || task.ContextID != "session-1" || task.Metadata["model"] != "test" {
+		t.Errorf("Unexpected task %+v", task)
+	}
+	if len(task.History) != 2 || task.History[0].Role != "user" || task.History[1].Role != "agent" {
+		t.Errorf("Expected the user and agent messages in the history, got %+v", task.History)
+	}
+
+	// Sending to the same task continues its history
+	send("Again")
+	one := 1
+	response := doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "persisted"}, HistoryLength: &one})
+	decodeResult(t, response.Result, &task)
+	if len(task.Artifacts) != 1 || len(task.History) != 1 || task.History[0].Role != "agent" {
+		t.Errorf("Expected the artifact and the last history message, got %+v", task)
+	}
+	stored, _ := server.store.Get(context.Background(), "persisted")
+	if len(stored.History) != 4 {
+		t.Errorf("Expected the stored history to keep 4 messages, got %d", len(stored.History))
+	}
+}
+
+func TestA2AServer_ErrorCodes(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		switch text := message.Parts[0].(models.TextPart).Text; text {
+		case "image":
+			return task, fmt.Errorf("invalid image %q: %w", "a.bmp", models.ErrNotImage)
+		case "typed":
+			return task, models.NewUnsupportedOperationError("not today")
+		}
+		return task, fmt.Errorf("dial tcp 10.0.0.5:5432: connection refused")
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+	send := func(text string) interface{} {
+		return models.MessageSendParams{ID: text, Message: models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: text}}}}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		params interface{}
+		want   models.ErrorCode
+	}{
+		{"unknown method", "tasks/unknown", nil, models.ErrorCodeMethodNotFound},
+		{"invalid params", "tasks/get", "not an object", models.ErrorCodeInvalidParams},
+		{"unknown task", "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "unknown"}}, models.ErrorCodeTaskNotFound},
+		{"push unsupported", GetPushNotificationMethod, models.TaskIDParams{ID: "unknown"}, models.ErrorCodePushNotificationNotSupported},
+		{"unsupported content", "message/send", send("image"), models.ErrorCodeContentTypeNotSupported},
+		{"handler error code", "message/send", send("typed"), models.ErrorCodeUnsupportedOperation},
+		{"handler failure", "message/send", send("other"), models.ErrorCodeInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := doRPC(t, server, tt.method, tt.params)
+			if response.Error == nil || response.Error.Code != int(tt.want) {
+				t.Errorf("Expected error code %d, got %+v", tt.want, response.Error)
+			}
+		})
+	}
+
+	// The details of internal errors are logged rather than sent
+	if response := doRPC(t, server, "message/send", send("other")); response.Error == nil || strings.Contains(response.Error.Message, "10.0.0.5") {
+		t.Errorf("Expected the handler's error withheld, got %+v", response.Error)
+	}
+}
+
+func TestA2AServer_FailureDetails(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status.Message = &models.Message{Role: "agent", Parts: []models.Part{models.NewTextPart("Could not read the request")}}
+		return task, Hint(models.NewInvalidParamsError("no text"), "send a text part")
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+	want := models.TaskError{Code: models.ErrorCodeInvalidParams, Message: "no text", Hint: "send a text part"}
+
+	response := doRPC(t, server, "message/send", models.MessageSendParams{ID: "failing", Message: models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Hello")}}})
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeInvalidParams) {
+		t.Fatalf("Expected the handler's error code, got %+v", response.Error)
+	}
+	if data, _ := response.Error.Data.(map[string]interface{}); data["hint"] != want.Hint {
+		t.Errorf("Expected the hint in the error data, got %v", response.Error.Data)
+	}
+
+	var task models.Task
+	decodeResult(t, doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "failing"}}).Result, &task)
+	details, ok := task.Status.ErrorDetails()
+	if task.Status.State != models.TaskStateFailed || !ok || details != want {
+		t.Fatalf("Expected the failed task to carry %+v, got %+v", want, details)
+	}
+	if task.Status.Message.Text() != "Could not read the request" {
+		t.Errorf("Expected the handler's message kept, got %q", task.Status.Message.Text())
+	}
+	if last := task.History[len(task.History)-1]; last.MessageID != task.Status.Message.MessageID {
+		t.Error("Expected the failure message in the history")
+	}
+
+	// Streaming clients get the details in the final status update
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"failing-stream","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	events := sseData(t, w.Body.String())
+	var final models.SendTaskStreamingResponse
+	if err := json.Unmarshal([]byte(events[len(events)-1]), &final); err != nil {
+		t.Fatalf("Failed to unmarshal final event: %v", err)
+	}
+	var event models.TaskStatusUpdateEvent
+	decodeResult(t, final.Result, &event)
+	if details, ok := event.Status.ErrorDetails(); event.Status.State != models.TaskStateFailed || !ok || details != want {
+		t.Errorf("Expected the final update to carry %+v, got %+v", want, event.Status)
+	}
+}
+
+func TestA2AServer_CorrelatesMessages(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		task.Status = models.TaskStatus{
+			State:   models.TaskStateCompleted,
+			Message: &models.Message{Role: "agent", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hi"}}},
+		}
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+
+	// The task is named by the message's taskId when the legacy id is absent
+	response := doRPC(t, server, "message/send", models.MessageSendParams{
+		Message: models.Message{
+			Role:             "user",
+			Parts:            []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+			TaskID:           "correlated",
+			MessageID:        "m1",
+			ReferenceTaskIDs: []string{"earlier"},
+		},
+	})
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+
+	if task.ID != "correlated" || task.ContextID == "" || len(task.History) != 2 {
+		t.Fatalf("Expected task correlated with a context and two messages, got %+v", task)
+	}
+	user, agent := task.History[0], task.History[1]
+	if user.MessageID != "m1" || user.TaskID != "correlated" || user.ContextID != task.ContextID || user.ReferenceTaskIDs[0] != "earlier" {
+		t.Errorf("Expected the user message to keep its ID and gain the task and context, got %+v", user)
+	}
+	if agent.MessageID == "" || agent.MessageID == user.MessageID || agent.TaskID != "correlated" || agent.ContextID != task.ContextID || agent.Kind != models.KindMessage {
+		t.Errorf("Expected the agent message to be stamped, got %+v", agent)
+	}
+	if task.Status.Message == nil || task.Status.Message.MessageID != agent.MessageID {
+		t.Errorf("Expected the status message to match the history, got %+v", task.Status.Message)
+	}
+}
+
+func TestA2AServer_NonBlockingSend(t *testing.T) {
+	for name, opts := range map[string][]Option{"inline": nil, "pool": {WithWorkerPool(1, 1)}} {
+		t.Run(name, func(t *testing.T) {
+			release := make(chan struct{})
+			handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+				<-release
+				task.Status.State = models.TaskStateCompleted
+				return task, nil
+			}
+			server := NewA2AServer(mockAgentCard, handler, opts...)
+
+			resp := doRPC(t, server, "message/send", map[string]interface{}{
+				"id":            "background",
+				"message":       models.Message{Role: "user", Parts: []models.Part{models.NewTextPart("Go")}},
+				"configuration": map[string]interface{}{"blocking": false, "historyLength": 0},
+			})
+			if resp.Error != nil {
+				t.Fatalf("Expected no error, got %+v", resp.Error)
+			}
+			var task models.Task
+			decodeResult(t, resp.Result, &task)
+			if task.ID != "background" || task.Status.State.IsTerminal() || len(task.History) != 0 {
+				t.Errorf("Expected the unfinished task at once without history, got %+v", task)
+			}
+
+			close(release)
+			waitForState(t, server, "background", models.TaskStateCompleted)
+		})
+	}
+}
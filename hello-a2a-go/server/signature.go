@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// maxSignedBodyBytes bounds the bodies buffered for signature verification
+const maxSignedBodyBytes = 10 << 20
+
+// RequireSignature returns middleware that only passes requests signed with secret, as
+// produced by models.SignRequest, whose X-A2A-Timestamp is within window of the real clock and
+// whose X-A2A-Nonce was not seen within the window. It is a lightweight alternative to full
+// OAuth between trusted agents sharing a secret. See A2AServer.RequireSignature for a server
+// with its own clock.
+func RequireSignature(secret []byte, window time.Duration) func(http.Handler) http.Handler {
+	return requireSignature(secret, window, clock.Real)
+}
+
+// RequireSignature is like the RequireSignature function, checking timestamps against the
+// server clock (see WithClock)
+func (s *A2AServer) RequireSignature(secret []byte, window time.Duration) func(http.Handler) http.Handler {
+	return requireSignature(secret, window, s.clock)
+}
+
+// requireSignature returns the middleware of RequireSignature, reading the time from c
+func requireSignature(secret []byte, window time.Duration, c clock.Clock) func(http.Handler) http.Handler {
+	// Only the nonces of correctly signed requests are remembered, so callers without the
+	// secret cannot fill the cache; it is not bounded beyond the window
+	nonces := &replayGuard{window: window, seen: make(map[string]time.Time), now: c.Now}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timestamp := r.Header.Get(models.HeaderTimestamp)
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				http.Error(w, "missing or invalid request timestamp", http.StatusUnauthorized)
+				return
+			}
+			now := c.Now()
+			if skew := now.Sub(time.Unix(seconds, 0)); skew > window || skew < -window {
+				http.Error(w, errStaleRequest.Error(), http.StatusUnauthorized)
+				return
+			}
+			nonce := r.Header.Get(models.HeaderNonce)
+			if nonce == "" || len(nonce) > maxNonceLength {
+				http.Error(w, "missing or invalid request nonce", http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if !models.VerifyRequestSignature(secret, r.Method, models.SignedPath(r), timestamp, nonce, body, r.Header.Get(models.HeaderSignature)) {
+				http.Error(w, "invalid request signature", http.StatusUnauthorized)
+				return
+			}
+			if err := nonces.record(nonce, now); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestRequireSignature(t *testing.T) {
+	secret := []byte("shared-secret")
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithClock(fake))
+	handler := server.RequireSignature(secret, time.Minute)(server)
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`)
+	now := strconv.FormatInt(fake.Now().Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		nonce     string
+		signature string
+		want      int
+	}{
+		{"valid signature", now, "n1", models.SignRequest(secret, "POST", "/", now, "n1", body), http.StatusOK},
+		{"replayed nonce", now, "n1", models.SignRequest(secret, "POST", "/", now, "n1", body), http.StatusUnauthorized},
+		{"missing nonce", now, "", models.SignRequest(secret, "POST", "/", now, "", body), http.StatusUnauthorized},
+		{"missing signature", now, "n2", "", http.StatusUnauthorized},
+		{"wrong secret", now, "n3", models.SignRequest([]byte("other"), "POST", "/", now, "n3", body), http.StatusUnauthorized},
+		{"missing timestamp", "", "n4", models.SignRequest(secret, "POST", "/", "", "n4", body), http.StatusUnauthorized},
+		{"stale timestamp", "1700000000", "n5", models.SignRequest(secret, "POST", "/", "1700000000", "n5", body), http.StatusUnauthorized},
+		// A nonce refused for its signature may still be used by the signer
+		{"unused nonce", now, "n2", models.SignRequest(secret, "POST", "/", now, "n2", body), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+			req.Header.Set(models.HeaderTimestamp, tt.timestamp)
+			req.Header.Set(models.HeaderNonce, tt.nonce)
+			req.Header.Set(models.HeaderSignature, tt.signature)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// The window follows the server clock rather than the real one
+	fake.Advance(2 * time.Minute)
+	req := httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	req.Header.Set(models.HeaderTimestamp, now)
+	req.Header.Set(models.HeaderNonce, "n6")
+	req.Header.Set(models.HeaderSignature, models.SignRequest(secret, "POST", "/", now, "n6", body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a request stale by the server clock to be refused, got %d", w.Code)
+	}
+
+	// A signature made for one path is refused on another
+	now = strconv.FormatInt(fake.Now().Unix(), 10)
+	req = httptest.NewRequest("POST", "/admin", bytes.NewBuffer(body))
+	req.Header.Set(models.HeaderTimestamp, now)
+	req.Header.Set(models.HeaderNonce, "n7")
+	req.Header.Set(models.HeaderSignature, models.SignRequest(secret, "POST", "/", now, "n7", body))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a signature for another path to be refused, got %d", w.Code)
+	}
+}
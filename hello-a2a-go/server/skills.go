@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"a2a/models"
+)
+
+// SkillMetadataKey is the request metadata key naming the skill a message is addressed to
+const SkillMetadataKey = "skillId"
+
+// errUnknownSkill is returned when a request names a skill the server does not serve
+var errUnknownSkill = errors.New("unknown skill")
+
+// skillRoute binds a skill to its handler and hooks
+type skillRoute struct {
+	// handler serves the skill; nil means the server's default handler
+	handler TaskHandler
+	pre     []PreHook
+	post    []PostHook
+	// throttle overrides the server's stream throttle when set
+	throttle *StreamThrottle
+}
+
+// AddSkill adds skill to the served agent card and routes requests naming its ID to handler.
+// A nil handler routes the skill to the server's default handler. Adding a skill whose ID is
+// already registered replaces it. The card update is atomic with respect to concurrent readers.
+func (s *A2AServer) AddSkill(skill models.AgentSkill, handler TaskHandler, opts ...SkillOption) error {
+	if skill.ID == "" {
+		return errors.New("skill ID is required")
+	}
+
+	s.skillsMu.Lock()
+	defer s.skillsMu.Unlock()
+
+	skills := make([]models.AgentSkill, 0, len(s.agentCard.Skills)+1)
+	replaced := false
+	for _, existing := range s.agentCard.Skills {
+		if existing.ID == skill.ID {
+			skills = append(skills, skill)
+			replaced = true
+			continue
+		}
+		skills = append(skills, existing)
+	}
+	if !replaced {
+		skills = append(skills, skill)
+	}
+	s.agentCard.Skills = skills
+
+	route := &skillRoute{handler: handler}
+	for _, opt := range opts {
+		opt(route)
+	}
+	if route.handler != nil || len(route.pre) > 0 || len(route.post) > 0 || route.throttle != nil {
+		s.skillRoutes[skill.ID] = route
+	} else {
+		delete(s.skillRoutes, skill.ID)
+	}
+	return nil
+}
+
+// RemoveSkill removes the skill with the given ID from the served agent card along with its
+// handler. It reports whether the skill was registered.
+func (s *A2AServer) RemoveSkill(id string) bool {
+	s.skillsMu.Lock()
+	defer s.skillsMu.Unlock()
+
+	skills := make([]models.AgentSkill, 0, len(s.agentCard.Skills))
+	found := false
+	for _, existing := range s.agentCard.Skills {
+		if existing.ID == id {
+			found = true
+			continue
+		}
+		skills = append(skills, existing)
+	}
+	if !found {
+		return false
+	}
+
+	s.agentCard.Skills = skills
+	delete(s.skillRoutes, id)
+	return true
+}
+
+// AgentCard returns a snapshot of the agent card currently served
+func (s *A2AServer) AgentCard() models.AgentCard {
+	s.skillsMu.RLock()
+	defer s.skillsMu.RUnlock()
+
+	card := s.agentCard
+	card.Skills = append([]models.AgentSkill(nil), s.agentCard.Skills...)
+	if s.extendedCard != nil {
+		card.SupportsAuthenticatedExtendedCard = boolPtr(true)
+	}
+	return card
+}
+
+// ServeAgentCard writes the current agent card as JSON, for mounting at /.well-known/agent-card.
+// The card's digest is sent as its ETag, so clients caching it revalidate with If-None-Match and
+// get 304 Not Modified until a skill is added or removed. A card signed with
+// WithCardSigningKey carries its signature.
+func (s *A2AServer) ServeAgentCard(w http.ResponseWriter, r *http.Request) {
+	card := s.AgentCard()
+	body, err := json.Marshal(card)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.cardSigner != nil {
+		if body, err = s.cardSigner.sign(card, body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// requestSkill returns the skill the message of params is addressed to, as handlers see it:
+// the one the request's metadata names, else the message's metadata or a routing data part,
+// else the card's first skill, which serves messages naming none
+func (s *A2AServer) requestSkill(ctx context.Context, params models.TaskSendParams) string {
+	if skillID, _ := params.Metadata[SkillMetadataKey].(string); skillID != "" {
+		return skillID
+	}
+	if skillID := skillOf(ctx, &params.Message); skillID != "" {
+		return skillID
+	}
+	return s.defaultSkillID()
+}
+
+// resolveHandler returns the handler for the skill named in metadata, wrapped with the
+// skill's hooks. It falls back to the default handler when no skill is named or the named
+// skill has no dedicated handler.
+func (s *A2AServer) resolveHandler(metadata map[string]interface{}) (TaskHandler, error) {
+	skillID, _ := metadata[SkillMetadataKey].(string)
+	if skillID == "" {
+		return s.handler, nil
+	}
+
+	route, err := s.route(skillID)
+	if err != nil {
+		return nil, err
+	}
+	if route == nil {
+		return s.handler, nil
+	}
+
+	handler := route.handler
+	if handler == nil {
+		handler = s.handler
+	}
+	return withHooks(handler, route.pre, route.post), nil
+}
+
+// route returns the route registered for skillID, or nil when the skill is declared on
+// the card without a dedicated route
+func (s *A2AServer) route(skillID string) (*skillRoute, error) {
+	s.skillsMu.RLock()
+	defer s.skillsMu.RUnlock()
+
+	if route, ok := s.skillRoutes[skillID]; ok {
+		return route, nil
+	}
+	for _, skill := range s.agentCard.Skills {
+		if skill.ID == skillID {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", errUnknownSkill, skillID)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_AddRemoveSkill(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+
+	var called bool
+	echo := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		called = true
+		task.Status.State = models.TaskStateInputRequired
+		return task, nil
+	}
+
+	if err := server.AddSkill(models.AgentSkill{ID: "echo", Name: "Echo"}, echo); err != nil {
+		t.Fatalf("Failed to add skill: %v", err)
+	}
+	if err := server.AddSkill(models.AgentSkill{Name: "No ID"}, echo); err == nil {
+		t.Error("Expected error for skill without ID")
+	}
+
+	card := server.AgentCard()
+	if len(card.Skills) != 2 || card.Skills[1].ID != "echo" {
+		t.Fatalf("Expected echo skill on the card, got %+v", card.Skills)
+	}
+	if len(mockAgentCard.Skills) != 1 {
+		t.Fatal("AddSkill must not mutate the caller's agent card")
+	}
+
+	params := models.MessageSendParams{
+		ID: "skill-task",
+		Message: models.Message{
+			Role:  "user",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+		},
+		Metadata: map[string]interface{}{SkillMetadataKey: "echo"},
+	}
+
+	response := doRPC(t, server, "message/send", params)
+	if response.Error != nil {
+		t.Fatalf("Expected no error, got %v", response.Error)
+	}
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if !called || task.Status.State != models.TaskStateInputRequired {
+		t.Errorf("Expected the echo handler to process the task, got state %s", task.Status.State)
+	}
+
+	// Skills declared without a dedicated handler use the default handler
+	params.Metadata[SkillMetadataKey] = "test-skill"
+	response = doRPC(t, server, "message/send", params)
+	decodeResult(t, response.Result, &task)
+	if task.Status.State != models.TaskStateCompleted {
+		t.Errorf("Expected the default handler to complete the task, got %s", task.Status.State)
+	}
+
+	if !server.RemoveSkill("echo") {
+		t.Fatal("Expected RemoveSkill to report the echo skill")
+	}
+	if server.RemoveSkill("echo") {
+		t.Error("Expected second RemoveSkill to report false")
+	}
+
+	params.Metadata[SkillMetadataKey] = "echo"
+	response = doRPC(t, server, "message/send", params)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeUnsupportedOperation) {
+		t.Errorf("Expected unsupported operation error for removed skill, got %v", response.Error)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeAgentCard(w, httptest.NewRequest("GET", "/.well-known/agent-card", nil))
+	var served models.AgentCard
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("Failed to decode agent card: %v", err)
+	}
+	if len(served.Skills) != 1 {
+		t.Errorf("Expected served card to have 1 skill, got %d", len(served.Skills))
+	}
+}
+
+func TestServeAgentCard_ETag(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler)
+	get := func(etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/.well-known/agent-card", nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		server.ServeAgentCard(w, r)
+		return w
+	}
+
+	etag := get("").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected the agent card to have an ETag")
+	}
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 for an unchanged card, got %d", w.Code)
+	}
+
+	// A new skill changes the card and its ETag
+	if err := server.AddSkill(models.AgentSkill{ID: "echo", Name: "Echo"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Expected the changed card with a new ETag, got %d %s", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,630 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2a/models"
+)
+
+// SQL dialects supported by SQLTaskStore
+const (
+	// DialectSQLite is SQLite, e.g. with the modernc.org/sqlite or mattn/go-sqlite3 driver
+	DialectSQLite = "sqlite"
+	// DialectPostgres is PostgreSQL, e.g. with the pgx or lib/pq driver
+	DialectPostgres = "postgres"
+)
+
+// sqlMigrations are the schema versions of SQLTaskStore, applied in order. Append new versions;
+// never edit one that has shipped. Tasks are stored as JSON documents without their artifacts,
+// which are kept in task_artifacts in order.
+var sqlMigrations = []string{
+	`CREATE TABLE tasks (
+		id TEXT PRIMARY KEY,
+		context_id TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL,
+		task TEXT NOT NULL
+	);
+	CREATE INDEX tasks_context_id ON tasks (context_id);
+	CREATE TABLE task_artifacts (
+		task_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		artifact TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	);
+	CREATE TABLE task_messages (
+		task_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		message TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	);
+	CREATE TABLE task_statuses (
+		task_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		state TEXT NOT NULL,
+		status TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	)`,
+	`CREATE TABLE task_events (
+		task_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		event TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	)`,
+	`CREATE TABLE api_keys (
+		id TEXT PRIMARY KEY,
+		hash TEXT NOT NULL UNIQUE,
+		api_key TEXT NOT NULL
+	)`,
+	`CREATE TABLE audit_records (
+		seq BIGINT PRIMARY KEY,
+		time BIGINT NOT NULL,
+		type TEXT NOT NULL,
+		task_id TEXT NOT NULL DEFAULT '',
+		caller TEXT NOT NULL DEFAULT '',
+		record TEXT NOT NULL
+	);
+	CREATE INDEX audit_records_task_id ON audit_records (task_id)`,
+	`CREATE TABLE leases (
+		name TEXT PRIMARY KEY,
+		holder TEXT NOT NULL,
+		expires BIGINT NOT NULL
+	)`,
+}
+
+// SQLTaskStore is a TaskStore in a SQLite or PostgreSQL database, so that tasks survive
+// restarts. The caller registers the database/sql driver and owns the *sql.DB.
+type SQLTaskStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// NewSQLTaskStore returns a store in db, which speaks dialect, after migrating its schema to
+// the latest version
+func NewSQLTaskStore(ctx context.Context, db *sql.DB, dialect string) (*SQLTaskStore, error) {
+	if dialect != DialectSQLite && dialect != DialectPostgres {
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+	s := &SQLTaskStore{db: db, dialect: dialect}
+	if err := s.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DialectOf returns the dialect of a database/sql driver name, such as "sqlite3" or "pgx"
+func DialectOf(driver string) (string, bool) {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return DialectSQLite, true
+	case "postgres", "pgx":
+		return DialectPostgres, true
+	}
+	return "", false
+}
+
+// migrate applies the migrations newer than the database's schema version, each in its own
+// transaction
+func (s *SQLTaskStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	var version int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(sqlMigrations) {
+		return fmt.Errorf("database schema version %d is newer than this server (%d)", version, len(sqlMigrations))
+	}
+
+	for v := version + 1; v <= len(sqlMigrations); v++ {
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			// Not every driver runs several statements in one Exec
+			for _, stmt := range strings.Split(sqlMigrations[v-1], ";") {
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), v)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate to schema version %d: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// rebind rewrites the ? placeholders of query to the dialect's
+func (s *SQLTaskStore) rebind(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// inTx runs fn in a transaction, committing it if fn succeeds
+func (s *SQLTaskStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Backend implements TaskStore
+func (s *SQLTaskStore) Backend() string {
+	return s.dialect
+}
+
+// Get implements TaskStore
+func (s *SQLTaskStore) Get(ctx context.Context, id string) (*models.Task, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT task FROM tasks WHERE id = ?`), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	return s.load(ctx, data)
+}
+
+// load decodes a stored task and attaches its artifacts
+func (s *SQLTaskStore) load(ctx context.Context, data string) (*models.Task, error) {
+	var task models.Task
+	if err := models.DecodeJSON([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT artifact FROM task_artifacts WHERE task_id = ? ORDER BY seq`), task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load artifacts: %w", err)
+	}
+	err = scanJSON(rows, func(data []byte) error {
+		var artifact models.Artifact
+		if err := models.DecodeJSON(data, &artifact); err != nil {
+			return err
+		}
+		task.Artifacts = append(task.Artifacts, artifact)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load artifacts: %w", err)
+	}
+	return &task, nil
+}
+
+// Save implements TaskStore
+func (s *SQLTaskStore) Save(ctx context.Context, task *models.Task) error {
+	document := *task
+	document.Artifacts = nil
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	status, err := json.Marshal(task.Status)
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO tasks (id, context_id, state, task) VALUES (?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET context_id = excluded.context_id, state = excluded.state, task = excluded.task`),
+			task.ID, task.ContextID, string(task.Status.State), string(data))
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM task_artifacts WHERE task_id = ?`), task.ID); err != nil {
+			return err
+		}
+		for i, artifact := range task.Artifacts {
+			data, err := json.Marshal(artifact)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO task_artifacts (task_id, seq, artifact) VALUES (?, ?, ?)`), task.ID, i, string(data)); err != nil {
+				return err
+			}
+		}
+
+		// Record the status when the state changed since the last one recorded
+		var last sql.NullString
+		err = tx.QueryRowContext(ctx, s.rebind(`SELECT state FROM task_statuses WHERE task_id = ? ORDER BY seq DESC LIMIT 1`), task.ID).Scan(&last)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if last.Valid && last.String == string(task.Status.State) {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO task_statuses (task_id, seq, state, status)
+			SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ? FROM task_statuses WHERE task_id = ?`),
+			task.ID, string(task.Status.State), string(status), task.ID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+	return nil
+}
+
+// AppendMessage implements TaskStore
+func (s *SQLTaskStore) AppendMessage(ctx context.Context, taskID string, message *models.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO task_messages (task_id, seq, message)
+		SELECT ?, COALESCE(MAX(seq), 0) + 1, ? FROM task_messages WHERE task_id = ?`),
+		taskID, string(data), taskID)
+	if err != nil {
+		return fmt.Errorf("failed to append message: %w", err)
+	}
+	return nil
+}
+
+// Messages implements TaskStore
+func (s *SQLTaskStore) Messages(ctx context.Context, taskID string) ([]*models.Message, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT message FROM task_messages WHERE task_id = ? ORDER BY seq`), taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	var messages []*models.Message
+	err = scanJSON(rows, func(data []byte) error {
+		var message models.Message
+		if err := models.DecodeJSON(data, &message); err != nil {
+			return err
+		}
+		messages = append(messages, &message)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	return messages, nil
+}
+
+// StatusHistory implements TaskStore
+func (s *SQLTaskStore) StatusHistory(ctx context.Context, taskID string) ([]models.TaskStatus, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT status FROM task_statuses WHERE task_id = ? ORDER BY seq`), taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load status history: %w", err)
+	}
+	var statuses []models.TaskStatus
+	err = scanJSON(rows, func(data []byte) error {
+		var status models.TaskStatus
+		if err := models.DecodeJSON(data, &status); err != nil {
+			return err
+		}
+		statuses = append(statuses, status)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load status history: %w", err)
+	}
+	return statuses, nil
+}
+
+// Event kinds stored in task_events
+const (
+	eventKindStatus   = "status"
+	eventKindArtifact = "artifact"
+)
+
+// AppendEvent implements TaskStore
+func (s *SQLTaskStore) AppendEvent(ctx context.Context, taskID string, event interface{}) (interface{}, error) {
+	kind := eventKindStatus
+	if _, ok := event.(models.TaskArtifactUpdateEvent); ok {
+		kind = eventKindArtifact
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var seq int64
+		err := tx.QueryRowContext(ctx, s.rebind(`SELECT COALESCE(MAX(seq), 0) + 1 FROM task_events WHERE task_id = ?`), taskID).Scan(&seq)
+		if err != nil {
+			return err
+		}
+		if event, err = withSequence(event, seq); err != nil {
+			return err
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO task_events (task_id, seq, kind, event) VALUES (?, ?, ?, ?)`),
+			taskID, seq, kind, string(data))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to append event: %w", err)
+	}
+	return event, nil
+}
+
+// Events implements TaskStore
+func (s *SQLTaskStore) Events(ctx context.Context, taskID string, after int64) ([]interface{}, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT kind, event FROM task_events WHERE task_id = ? AND seq > ? ORDER BY seq`), taskID, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	defer rows.Close()
+	var events []interface{}
+	for rows.Next() {
+		var kind, data string
+		if err := rows.Scan(&kind, &data); err != nil {
+			return nil, fmt.Errorf("failed to load events: %w", err)
+		}
+		var event interface{}
+		if kind == eventKindArtifact {
+			var artifact models.TaskArtifactUpdateEvent
+			err = models.DecodeJSON([]byte(data), &artifact)
+			event = artifact
+		} else {
+			var status models.TaskStatusUpdateEvent
+			err = models.DecodeJSON([]byte(data), &status)
+			event = status
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	return events, nil
+}
+
+// Count implements TaskStore
+func (s *SQLTaskStore) Count(ctx context.Context) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return n, nil
+}
+
+// ListTasks implements TaskStore
+func (s *SQLTaskStore) ListTasks(ctx context.Context, contextID string) ([]*models.Task, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT task FROM tasks WHERE ? = '' OR context_id = ? ORDER BY id`), contextID, contextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	var documents []string
+	err = scanJSON(rows, func(data []byte) error {
+		documents = append(documents, string(data))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	// Artifacts are loaded once the rows are closed, as SQLite may allow a single connection
+	tasks := make([]*models.Task, 0, len(documents))
+	for _, data := range documents {
+		task, err := s.load(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// Delete implements TaskStore
+func (s *SQLTaskStore) Delete(ctx context.Context, id string) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, table := range []string{"task_artifacts", "task_messages", "task_statuses", "task_events"} {
+			if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM `+table+` WHERE task_id = ?`), id); err != nil {
+				return err
+			}
+		}
+		_, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM tasks WHERE id = ?`), id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+// SaveAPIKey implements KeyStore
+func (s *SQLTaskStore) SaveAPIKey(ctx context.Context, key APIKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode API key: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO api_keys (id, hash, api_key) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET hash = excluded.hash, api_key = excluded.api_key`), key.ID, key.Hash, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+	return nil
+}
+
+// APIKeyByHash implements KeyStore
+func (s *SQLTaskStore) APIKeyByHash(ctx context.Context, hash string) (APIKey, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT api_key FROM api_keys WHERE hash = ?`), hash).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return APIKey{}, fmt.Errorf("failed to load API key: %w", err)
+	}
+	var key APIKey
+	if err := json.Unmarshal([]byte(data), &key); err != nil {
+		return APIKey{}, fmt.Errorf("failed to decode API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys implements KeyStore
+func (s *SQLTaskStore) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT api_key FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	keys := []APIKey{}
+	err = scanJSON(rows, func(data []byte) error {
+		var key APIKey
+		if err := json.Unmarshal(data, &key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey implements KeyStore
+func (s *SQLTaskStore) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var data string
+		if err := tx.QueryRowContext(ctx, s.rebind(`SELECT api_key FROM api_keys WHERE id = ?`), id).Scan(&data); err != nil {
+			return err
+		}
+		var key APIKey
+		if err := json.Unmarshal([]byte(data), &key); err != nil {
+			return err
+		}
+		key.RevokedAt = &at
+		revoked, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`UPDATE api_keys SET api_key = ? WHERE id = ?`), string(revoked), id)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+// AcquireLease implements Locker, so that replicas sharing the database elect a leader
+func (s *SQLTaskStore) AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	expires := now.Add(ttl).UnixNano()
+	// Renew the lease, or take it over once it expired; each statement is atomic, so only one
+	// replica takes a lease
+	res, err := s.db.ExecContext(ctx, s.rebind(`UPDATE leases SET holder = ?, expires = ?
+		WHERE name = ? AND (holder = ? OR expires <= ?)`), holder, expires, name, holder, now.UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return n > 0, err
+	}
+	res, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO leases (name, holder, expires) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO NOTHING`), name, holder, expires)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// AppendAudit implements AuditLog
+func (s *SQLTaskStore) AppendAudit(ctx context.Context, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO audit_records (seq, time, type, task_id, caller, record)
+		SELECT COALESCE(MAX(seq), 0) + 1, ?, ?, ?, ?, ? FROM audit_records`),
+		record.Time.UnixNano(), string(record.Type), record.TaskID, record.Caller, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return nil
+}
+
+// QueryAudit implements AuditLog
+func (s *SQLTaskStore) QueryAudit(ctx context.Context, query AuditQuery) ([]AuditRecord, error) {
+	var where []string
+	var args []interface{}
+	for _, filter := range []struct {
+		column string
+		value  string
+	}{{"type", string(query.Type)}, {"task_id", query.TaskID}, {"caller", query.Caller}} {
+		if filter.value != "" {
+			where = append(where, filter.column+" = ?")
+			args = append(args, filter.value)
+		}
+	}
+	if !query.Since.IsZero() {
+		where = append(where, "time >= ?")
+		args = append(args, query.Since.UnixNano())
+	}
+	if !query.Until.IsZero() {
+		where = append(where, "time < ?")
+		args = append(args, query.Until.UnixNano())
+	}
+	stmt := `SELECT record FROM audit_records`
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	stmt += " ORDER BY seq"
+	if query.Limit > 0 {
+		stmt += " LIMIT " + strconv.Itoa(query.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(stmt), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit records: %w", err)
+	}
+	var records []AuditRecord
+	err = scanJSON(rows, func(data []byte) error {
+		var record AuditRecord
+		if err := models.DecodeJSON(data, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit records: %w", err)
+	}
+	return records, nil
+}
+
+// scanJSON calls fn with the single text column of each row, closing rows
+func scanJSON(rows *sql.Rows, fn func(data []byte) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := fn([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,6 @@
+//go:build cgo
+
+package server
+
+// Registers the SQLite driver as "sqlite3", so that TestSQLTaskStore runs against SQLite
+import _ "github.com/mattn/go-sqlite3"
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"a2a/models"
+)
+
+// testTaskStore checks the TaskStore contract against store, which must be empty
+func testTaskStore(t *testing.T, store TaskStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "missing"); err != ErrTaskNotFound {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	task := &models.Task{ID: "b", ContextID: "chat", Status: models.TaskStatus{State: models.TaskStateWorking, Timestamp: "2025-01-01T00:00:00Z"}}
+	if err := store.Save(ctx, task); err != nil {
+		t.Fatalf("Failed to save task: %v", err)
+	}
+	if err := store.AppendMessage(ctx, "b", &models.Message{Role: "user", Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}}}); err != nil {
+		t.Fatalf("Failed to append message: %v", err)
+	}
+
+	task = &models.Task{
+		ID:        "b",
+		ContextID: "chat",
+		Status:    models.TaskStatus{State: models.TaskStateCompleted, Timestamp: "2025-01-01T00:01:00Z"},
+		Artifacts: []models.Artifact{
+			{Parts: []models.Part{models.TextPart{Type: "text", Text: "Bonjour"}}},
+			{Parts: []models.Part{models.TextPart{Type: "text", Text: "Salut"}}},
+		},
+		Metadata: map[string]interface{}{"skill": "translate"},
+	}
+	// Saving the same state twice records it once
+	for range 2 {
+		if err := store.Save(ctx, task); err != nil {
+			t.Fatalf("Failed to save task: %v", err)
+		}
+	}
+	if err := store.Save(ctx, &models.Task{ID: "a", Status: models.TaskStatus{State: models.TaskStateWorking}}); err != nil {
+		t.Fatalf("Failed to save task: %v", err)
+	}
+
+	got, err := store.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if got.Status.State != models.TaskStateCompleted || got.ContextID != "chat" || got.Metadata["skill"] != "translate" {
+		t.Errorf("Expected the completed task, got %+v", got)
+	}
+	if len(got.Artifacts) != 2 || got.Artifacts[1].Parts[0].(models.TextPart).Text != "Salut" {
+		t.Errorf("Expected the artifacts in order, got %+v", got.Artifacts)
+	}
+
+	messages, err := store.Messages(ctx, "b")
+	if err != nil || len(messages) != 1 || messages[0].Parts[0].(models.TextPart).Text != "Hello" {
+		t.Errorf("Expected the user message, got %+v (%v)", messages, err)
+	}
+
+	statuses, err := store.StatusHistory(ctx, "b")
+	var states []string
+	for _, status := range statuses {
+		states = append(states, fmt.Sprintf("%s@%s", status.State, status.Timestamp))
+	}
+	if want := []string{"working@2025-01-01T00:00:00Z", "completed@2025-01-01T00:01:00Z"}; err != nil || !slices.Equal(states, want) {
+		t.Errorf("Expected status history %q, got %q (%v)", want, states, err)
+	}
+
+	if n, err := store.Count(ctx); err != nil || n != 2 {
+		t.Errorf("Expected 2 tasks, got %d (%v)", n, err)
+	}
+	for contextID, want := range map[string][]string{"": {"a", "b"}, "chat": {"b"}, "other": nil} {
+		tasks, err := store.ListTasks(ctx, contextID)
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if err != nil || !slices.Equal(ids, want) {
+			t.Errorf("ListTasks(%q) = %q (%v), want %q", contextID, ids, err, want)
+		}
+	}
+
+	// Events are numbered per task, in the order they are appended
+	appended := []interface{}{
+		models.TaskStatusUpdateEvent{ID: "b", Status: models.TaskStatus{State: models.TaskStateWorking}, Final: boolPtr(false)},
+		models.TaskArtifactUpdateEvent{ID: "b", Artifact: models.Artifact{Parts: []models.Part{models.TextPart{Type: "text", Text: "Bonjour"}}}},
+		models.TaskStatusUpdateEvent{ID: "b", Status: models.TaskStatus{State: models.TaskStateCompleted}, Final: boolPtr(true)},
+	}
+	for i, event := range appended {
+		recorded, err := store.AppendEvent(ctx, "b", event)
+		if err != nil || eventSequence(recorded) != int64(i+1) {
+			t.Fatalf("Expected event %d numbered %d, got %+v (%v)", i, i+1, recorded, err)
+		}
+	}
+	if recorded, err := store.AppendEvent(ctx, "a", appended[0]); err != nil || eventSequence(recorded) != 1 {
+		t.Errorf("Expected another task's events numbered from 1, got %+v (%v)", recorded, err)
+	}
+	events, err := store.Events(ctx, "b", 1)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Expected the 2 events after the first, got %+v (%v)", events, err)
+	}
+	if artifact, ok := events[0].(models.TaskArtifactUpdateEvent); !ok || artifact.Sequence != 2 || artifact.Artifact.Parts[0].(models.TextPart).Text != "Bonjour" {
+		t.Errorf("Expected the artifact event numbered 2, got %+v", events[0])
+	}
+	if status, ok := events[1].(models.TaskStatusUpdateEvent); !ok || status.Sequence != 3 || !*status.Final {
+		t.Errorf("Expected the final status event numbered 3, got %+v", events[1])
+	}
+	if events, err := store.Events(ctx, "b", 3); err != nil || len(events) != 0 {
+		t.Errorf("Expected no events after the last, got %+v (%v)", events, err)
+	}
+
+	if err := store.Delete(ctx, "b"); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if _, err := store.Get(ctx, "b"); err != ErrTaskNotFound {
+		t.Errorf("Expected the deleted task not to be found, got %v", err)
+	}
+	messages, _ = store.Messages(ctx, "b")
+	statuses, _ = store.StatusHistory(ctx, "b")
+	events, _ = store.Events(ctx, "b", 0)
+	if len(messages) != 0 || len(statuses) != 0 || len(events) != 0 {
+		t.Errorf("Expected the deleted task's messages, statuses and events gone, got %d, %d and %d", len(messages), len(statuses), len(events))
+	}
+	if n, err := store.Count(ctx); err != nil || n != 1 {
+		t.Errorf("Expected 1 task left, got %d (%v)", n, err)
+	}
+	if err := store.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Expected deleting an unknown task to succeed, got %v", err)
+	}
+}
+
+func TestMemoryTaskStore(t *testing.T) {
+	testTaskStore(t, NewMemoryTaskStore())
+}
+
+func TestMemoryTaskStore_EventCap(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTaskStore()
+	for i := 1; i <= maxStoredEvents+10; i++ {
+		recorded, err := store.AppendEvent(ctx, "long", models.TaskStatusUpdateEvent{ID: "long"})
+		if err != nil || eventSequence(recorded) != int64(i) {
+			t.Fatalf("Expected event numbered %d, got %+v (%v)", i, recorded, err)
+		}
+	}
+
+	events, err := store.Events(ctx, "long", 0)
+	if err != nil || len(events) != maxStoredEvents || eventSequence(events[0]) != 11 {
+		t.Fatalf("Expected the last %d events from 11, got %d (%v)", maxStoredEvents, len(events), err)
+	}
+	events, err = store.Events(ctx, "long", maxStoredEvents+8)
+	if err != nil || len(events) != 2 || eventSequence(events[0]) != maxStoredEvents+9 {
+		t.Errorf("Expected the 2 events after %d, got %+v (%v)", maxStoredEvents+8, events, err)
+	}
+	if events, _ := store.Events(ctx, "long", maxStoredEvents+10); len(events) != 0 {
+		t.Errorf("Expected no events after the last, got %d", len(events))
+	}
+}
+
+// TestSQLTaskStore runs against each SQL driver compiled into the test binary: SQLite whenever
+// cgo is enabled. Set A2A_TEST_POSTGRES_DSN to test against a PostgreSQL database.
+func TestSQLTaskStore(t *testing.T) {
+	targets := map[string]string{
+		"sqlite":   "file:" + filepath.Join(t.TempDir(), "tasks.db"),
+		"sqlite3":  "file:" + filepath.Join(t.TempDir(), "tasks.db"),
+		"pgx":      os.Getenv("A2A_TEST_POSTGRES_DSN"),
+		"postgres": os.Getenv("A2A_TEST_POSTGRES_DSN"),
+	}
+	tested := false
+	for driver, dsn := range targets {
+		if dsn == "" || !slices.Contains(sql.Drivers(), driver) {
+			continue
+		}
+		tested = true
+		t.Run(driver, func(t *testing.T) {
+			db, err := sql.Open(driver, dsn)
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer db.Close()
+			dialect, _ := DialectOf(driver)
+			if dialect == DialectPostgres {
+				for _, table := range []string{"schema_migrations", "tasks", "task_artifacts", "task_messages", "task_statuses", "task_events", "api_keys", "audit_records", "leases"} {
+					db.Exec("DROP TABLE IF EXISTS " + table)
+				}
+			}
+
+			store, err := NewSQLTaskStore(context.Background(), db, dialect)
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			testTaskStore(t, store)
+			testKeyStore(t, store)
+			testAuditLog(t, store)
+			testLocker(t, store)
+
+			// Reopening finds the schema migrated and the tasks in place
+			reopened, err := NewSQLTaskStore(context.Background(), db, dialect)
+			if err != nil {
+				t.Fatalf("Failed to reopen store: %v", err)
+			}
+			if n, err := reopened.Count(context.Background()); err != nil || n != 1 {
+				t.Errorf("Expected the remaining task to survive, got %d (%v)", n, err)
+			}
+		})
+	}
+	if !tested {
+		t.Skip("no SQL driver compiled in; run with cgo enabled")
+	}
+}
+
+func TestSQLTaskStore_Rebind(t *testing.T) {
+	query := `SELECT task FROM tasks WHERE ? = '' OR context_id = ?`
+	if got := (&SQLTaskStore{dialect: DialectSQLite}).rebind(query); got != query {
+		t.Errorf("Expected SQLite placeholders unchanged, got %q", got)
+	}
+	if got, want := (&SQLTaskStore{dialect: DialectPostgres}).rebind(query), `SELECT task FROM tasks WHERE $1 = '' OR context_id = $2`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if _, err := NewSQLTaskStore(context.Background(), nil, "oracle"); err == nil {
+		t.Error("Expected an unsupported dialect to be rejected")
+	}
+	for driver, want := range map[string]string{"sqlite3": DialectSQLite, "pgx": DialectPostgres, "mysql": ""} {
+		if got, _ := DialectOf(driver); got != want {
+			t.Errorf("DialectOf(%q) = %q, want %q", driver, got, want)
+		}
+	}
+}
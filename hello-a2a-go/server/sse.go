@@ -0,0 +1,294 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// defaultKeepAlive is how often an idle SSE stream sends a keep-alive comment
+const defaultKeepAlive = 15 * time.Second
+
+// streamRetention is how long the events of a finished stream are kept for resuming clients
+const streamRetention = time.Minute
+
+// WithKeepAlive sets how often an idle SSE stream sends a keep-alive comment, so proxies do
+// not close it while a handler works; zero disables keep-alives
+func WithKeepAlive(d time.Duration) Option {
+	return func(s *A2AServer) {
+		s.keepAlive = d
+	}
+}
+
+// taskStream buffers the events of one streaming task, so a client that loses its connection
+// can resume the stream from the last event it received
+type taskStream struct {
+	id     string
+	taskID string
+
+	// recordMu keeps events published in the order the task store numbered them
+	recordMu sync.Mutex
+
+	// backpressure bounds the events its subscribers may have unsent
+	backpressure StreamBackpressure
+	clock        clock.Clock
+
+	mu     sync.Mutex
+	events []interface{}
+	done   bool
+	// changed is closed and replaced whenever an event is published or the stream finishes
+	changed     chan struct{}
+	subscribers map[*streamSubscriber]struct{}
+	// drained is closed and replaced whenever a subscriber is sent an event or leaves
+	drained chan struct{}
+}
+
+// newTaskStream returns an empty stream of taskID whose subscribers are unbounded
+func newTaskStream(taskID string) *taskStream {
+	return &taskStream{
+		id:          newContextID(),
+		taskID:      taskID,
+		clock:       clock.Real,
+		changed:     make(chan struct{}),
+		subscribers: make(map[*streamSubscriber]struct{}),
+		drained:     make(chan struct{}),
+	}
+}
+
+// publish appends event to the stream, first waiting for slow subscribers under
+// BackpressureBlock
+func (t *taskStream) publish(event interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.awaitSubscribers()
+	t.events = append(t.events, event)
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// finish marks the stream complete
+func (t *taskStream) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done = true
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// since returns the events after the first n, whether the stream is complete, and a channel
+// closed on the next change
+func (t *taskStream) since(n int) ([]interface{}, bool, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > len(t.events) {
+		n = len(t.events)
+	}
+	return t.events[n:], t.done, t.changed
+}
+
+// after returns the number of events of the stream up to the one with sequence number seq
+func (t *taskStream) after(seq int64) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for i, event := range t.events {
+		if s := eventSequence(event); s > 0 && s <= seq {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+// eventID returns the SSE event ID of event, the n-th of the stream counting from 1: its
+// sequence number, or else the stream ID and n, for events the task store failed to number
+func (t *taskStream) eventID(n int, event interface{}) string {
+	if seq := eventSequence(event); seq > 0 {
+		return strconv.FormatInt(seq, 10)
+	}
+	return t.id + "/" + strconv.Itoa(n)
+}
+
+// parseEventID splits an SSE event ID into its stream ID and event number
+func parseEventID(id string) (string, int, bool) {
+	i := strings.LastIndexByte(id, '/')
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(id[i+1:])
+	if err != nil || n < 0 {
+		return "", 0, false
+	}
+	return id[:i], n, true
+}
+
+// openStream registers a new stream for taskID
+func (s *A2AServer) openStream(taskID string) *taskStream {
+	stream := newTaskStream(taskID)
+	stream.backpressure = s.backpressure
+	stream.clock = s.clock
+	s.streamsMu.Lock()
+	s.streams[stream.id] = stream
+	s.streamsMu.Unlock()
+	return stream
+}
+
+// closeStream finishes stream and forgets it once resuming clients have had time to catch up
+func (s *A2AServer) closeStream(stream *taskStream) {
+	stream.finish()
+	s.clock.AfterFunc(streamRetention, func() {
+		s.streamsMu.Lock()
+		delete(s.streams, stream.id)
+		s.streamsMu.Unlock()
+	})
+}
+
+// eventSequence returns the sequence number of a stream event, zero when it has none
+func eventSequence(event interface{}) int64 {
+	switch event := event.(type) {
+	case models.TaskStatusUpdateEvent:
+		return event.Sequence
+	case models.TaskArtifactUpdateEvent:
+		return event.Sequence
+	}
+	return 0
+}
+
+// withSequence returns a status or artifact update event numbered seq, for TaskStore
+// implementations
+func withSequence(event interface{}, seq int64) (interface{}, error) {
+	switch event := event.(type) {
+	case models.TaskStatusUpdateEvent:
+		event.Sequence = seq
+		return event, nil
+	case models.TaskArtifactUpdateEvent:
+		event.Sequence = seq
+		return event, nil
+	}
+	return nil, fmt.Errorf("unsupported event %T", event)
+}
+
+// publishEvent records event in the task store, numbered with the next sequence number of
+// stream's task, and publishes it to stream. An event the store fails to record is published
+// unnumbered. It returns the event published.
+func (s *A2AServer) publishEvent(ctx context.Context, stream *taskStream, event interface{}) interface{} {
+	stream.recordMu.Lock()
+	defer stream.recordMu.Unlock()
+	recorded, err := s.store.AppendEvent(ctx, stream.taskID, event)
+	if err != nil {
+		s.log(ctx, stream.taskID).Error("failed to record stream event", slog.Any("error", err))
+		recorded = event
+	}
+	stream.publish(recorded)
+	return recorded
+}
+
+// resumption is where a stream resumes for a client: the recorded events it missed, then the
+// events of stream, if any, after its first next
+type resumption struct {
+	replay []interface{}
+	stream *taskStream
+	next   int
+}
+
+// resume returns where the stream of taskID resumes after the event lastEventID. For a sequence
+// number the missed events are replayed from the task store in order, even once the stream
+// that published them has expired, then those of the task's running stream follow live. An
+// event ID naming a stream, for events the store failed to number, resumes that stream while
+// it is retained. An error wrapping ErrTaskNotFound is returned when there is nothing to resume.
+func (s *A2AServer) resume(ctx context.Context, lastEventID, taskID string) (*resumption, error) {
+	seq, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil || seq < 0 {
+		stream, next := s.lookupStream(lastEventID, taskID)
+		if stream == nil {
+			return nil, fmt.Errorf("%w: stream not found or expired: %s", ErrTaskNotFound, lastEventID)
+		}
+		return &resumption{stream: stream, next: next}, nil
+	}
+
+	// The stream is looked up first, so that every event published after the store is read
+	// is in it
+	stream := s.runningStream(taskID)
+	replay, err := s.store.Events(ctx, taskID, seq)
+	if err != nil {
+		return nil, err
+	}
+	if len(replay) == 0 && stream == nil {
+		if _, err := s.store.Get(ctx, taskID); err != nil {
+			return nil, err
+		}
+	}
+	res := &resumption{replay: replay, stream: stream}
+	if stream != nil {
+		if n := len(replay); n > 0 {
+			seq = eventSequence(replay[n-1])
+		}
+		res.next = stream.after(seq)
+	}
+	return res, nil
+}
+
+// sendResumeError answers a request whose stream cannot resume
+func (s *A2AServer) sendResumeError(w http.ResponseWriter, id interface{}, err error) {
+	if errors.Is(err, ErrTaskNotFound) {
+		s.sendErrorWithID(w, id, models.ErrorCodeTaskNotFound, err.Error())
+		return
+	}
+	s.sendErrorWithID(w, id, models.ErrorCodeInternalError, err.Error())
+}
+
+// lookupStream returns the stream named by a Last-Event-ID for taskID and the number of
+// events the client has received, or nil when it is unknown or has expired
+func (s *A2AServer) lookupStream(lastEventID, taskID string) (*taskStream, int) {
+	streamID, n, ok := parseEventID(lastEventID)
+	if !ok {
+		return nil, 0
+	}
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+	stream := s.streams[streamID]
+	if stream == nil || stream.taskID != taskID {
+		return nil, 0
+	}
+	return stream, n
+}
+
+// eventWriter frames streamed events as Server-Sent Events, or as one JSON value per line for
+// clients that did not ask for text/event-stream, such as the stdio transport
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	sse     bool
+}
+
+// event writes data as one event, in a single write so middleware sees whole events
+func (e *eventWriter) event(id string, data []byte) error {
+	var frame []byte
+	if e.sse {
+		frame = fmt.Appendf(nil, "id: %s\ndata: %s\n\n", id, data)
+	} else {
+		frame = append(data, '\n')
+	}
+	if _, err := e.w.Write(frame); err != nil {
+		return err
+	}
+	e.flusher.Flush()
+	return nil
+}
+
+// keepAlive writes an SSE comment, which clients ignore
+func (e *eventWriter) keepAlive() error {
+	if _, err := e.w.Write([]byte(": keep-alive\n\n")); err != nil {
+		return err
+	}
+	e.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// sseData returns the data of each event in an SSE body, checking that every event has an ID
+func sseData(t *testing.T, body string) []string {
+	t.Helper()
+
+	var data []string
+	for _, frame := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		if strings.HasPrefix(frame, ":") {
+			continue
+		}
+		var id, payload string
+		for _, line := range strings.Split(frame, "\n") {
+			if v, ok := strings.CutPrefix(line, "id: "); ok {
+				id = v
+			}
+			if v, ok := strings.CutPrefix(line, "data: "); ok {
+				payload = v
+			}
+		}
+		if id == "" || payload == "" {
+			t.Fatalf("Expected an event with an ID and data, got %q", frame)
+		}
+		data = append(data, payload)
+	}
+	return data
+}
+
+// readFrame reads one SSE frame, without its trailing blank line
+func readFrame(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read frame: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// postStream starts a message/stream request accepting SSE, resuming after lastEventID if set
+func postStream(t *testing.T, ctx context.Context, url, taskID, lastEventID string) *http.Response {
+	t.Helper()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"` + taskID + `","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+func TestStreamResume(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		calls.Add(1)
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	ts := httptest.NewServer(NewA2AServer(mockAgentCard, handler))
+	defer ts.Close()
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	resp := postStream(t, ctx, ts.URL, "resumable", "")
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got %s", ct)
+	}
+	first := readFrame(t, bufio.NewReader(resp.Body))
+	lastEventID, _ := strings.CutPrefix(strings.Split(first, "\n")[0], "id: ")
+	disconnect()
+	resp.Body.Close()
+
+	// The task completes while the client is away
+	close(release)
+
+	resp = postStream(t, context.Background(), ts.URL, "resumable", lastEventID)
+	defer resp.Body.Close()
+	var body strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&body)
+	data := sseData(t, body.String())
+	if len(data) != 1 {
+		t.Fatalf("Expected only the missed event, got %q", data)
+	}
+	var final struct {
+		Result models.TaskStatusUpdateEvent `json:"result"`
+	}
+	json.Unmarshal([]byte(data[0]), &final)
+	if final.Result.Status.State != models.TaskStateCompleted || final.Result.Final == nil || !*final.Result.Final {
+		t.Errorf("Expected the final event, got %s", data[0])
+	}
+	if !strings.Contains(body.String(), "id: "+strings.TrimSuffix(lastEventID, "1")+"2\n") {
+		t.Errorf("Expected the resumed event to continue the numbering after %s, got %q", lastEventID, body.String())
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected resuming not to rerun the task, got %d calls", calls.Load())
+	}
+
+	resp = postStream(t, context.Background(), ts.URL, "other-task", lastEventID)
+	defer resp.Body.Close()
+	var response models.JSONRPCResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	if response.Error == nil || response.Error.Code != int(models.ErrorCodeTaskNotFound) {
+		t.Errorf("Expected a stream of another task not to be found, got %+v", response)
+	}
+}
+
+func TestStreamKeepAlive(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		<-release
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	fake := clock.NewFake(time.Unix(0, 0))
+	ts := httptest.NewServer(NewA2AServer(mockAgentCard, handler, WithClock(fake), WithKeepAlive(10*time.Second)))
+	defer ts.Close()
+
+	resp := postStream(t, context.Background(), ts.URL, "idle", "")
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	readFrame(t, reader)
+
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+	if frame := readFrame(t, reader); frame != ": keep-alive" {
+		t.Errorf("Expected a keep-alive comment, got %q", frame)
+	}
+	close(release)
+	if frame := readFrame(t, reader); !strings.Contains(frame, `"state":"completed"`) {
+		t.Errorf("Expected the final event, got %q", frame)
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"a2a/models"
+)
+
+// maxStdioLineBytes bounds a single JSON-RPC request read from stdin
+const maxStdioLineBytes = 16 << 20
+
+// ServeStdio serves A2A over a pair of streams, as a subprocess agent does with its stdin and
+// stdout: each line read from in is a JSON-RPC request, and each line written to out is a
+// JSON-RPC response or streaming event carrying the request's ID. Requests are handled
+// concurrently with a context derived from ctx. ServeStdio returns once in is exhausted and
+// in-flight requests have finished.
+func ServeStdio(ctx context.Context, handler http.Handler, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxStdioLineBytes)
+	output := &lineWriter{w: out}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		body := append([]byte(nil), line...)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveStdioRequest(ctx, handler, body, output)
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// serveStdioRequest dispatches one request line to handler as an HTTP POST
+func serveStdioRequest(ctx context.Context, handler http.Handler, body []byte, output *lineWriter) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.RemoteAddr = "stdio"
+	req.Header.Set("Content-Type", "application/json")
+
+	w := &stdioResponseWriter{header: make(http.Header), output: output}
+	handler.ServeHTTP(w, req)
+
+	if w.status != 0 && w.status != http.StatusOK {
+		// Errors reported through HTTP status codes become JSON-RPC errors, since there is no
+		// status line on stdio
+		var id struct {
+			ID interface{} `json:"id"`
+		}
+		json.Unmarshal(body, &id)
+		output.writeJSON(models.JSONRPCResponse{
+			JSONRPCMessage: models.JSONRPCMessage{
+				JSONRPC:                  "2.0",
+				JSONRPCMessageIdentifier: models.JSONRPCMessageIdentifier{ID: id.ID},
+			},
+			Error: &models.JSONRPCError{
+				Code:    int(models.ErrorCodeInternalError),
+				Message: strings.TrimSpace(w.errBody.String()),
+				Data:    map[string]interface{}{"status": w.status},
+			},
+		})
+	}
+}
+
+// lineWriter serializes writes from concurrent requests onto a single stream
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// writeJSON writes v as a single line
+func (l *lineWriter) writeJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	l.Write(append(data, '\n'))
+}
+
+// stdioResponseWriter adapts http.ResponseWriter to a shared line-oriented output. Successful
+// responses are passed through; error bodies are buffered so they can be wrapped in JSON-RPC.
+type stdioResponseWriter struct {
+	header  http.Header
+	status  int
+	output  *lineWriter
+	errBody bytes.Buffer
+}
+
+func (w *stdioResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *stdioResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *stdioResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.status != http.StatusOK {
+		return w.errBody.Write(p)
+	}
+	return w.output.Write(p)
+}
+
+// Flush implements http.Flusher; output is written through on every Write
+func (w *stdioResponseWriter) Flush() {}
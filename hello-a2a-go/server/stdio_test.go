@@ -0,0 +1,67 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/models"
+)
+
+func TestServeStdio(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithMaxRequestBytes(256))
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":"send","method":"message/send","params":{"id":"t1","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`,
+		``,
+		`{"jsonrpc":"2.0","id":7,"method":"message/stream","params":{"id":"t2","message":{"role":"user","parts":[{"kind":"text","text":"Hello"}]}}}`,
+		`{"jsonrpc":"2.0","id":"big","method":"message/send","params":{"id":"` + strings.Repeat("x", 300) + `"}}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := ServeStdio(context.Background(), server, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	byID := map[string][]models.JSONRPCResponse{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var response models.JSONRPCResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("Output line is not JSON-RPC: %q", scanner.Text())
+		}
+		id, _ := json.Marshal(response.ID)
+		byID[string(id)] = append(byID[string(id)], response)
+	}
+
+	if got := byID[`"send"`]; len(got) != 1 || got[0].Error != nil {
+		t.Errorf("Expected one successful send response, got %+v", got)
+	}
+	if got := byID[`7`]; len(got) != 2 {
+		t.Errorf("Expected two streaming events, got %+v", got)
+	}
+	if got := byID[`null`]; len(got) != 1 || got[0].Error == nil {
+		t.Errorf("Expected oversized request to be reported as a JSON-RPC error, got %+v", got)
+	}
+}
+
+func TestServeStdio_HTTPErrors(t *testing.T) {
+	handler := RequireSignature([]byte("secret"), time.Minute)(NewA2AServer(mockAgentCard, mockTaskHandler))
+
+	in := `{"jsonrpc":"2.0","id":"unsigned","method":"tasks/get","params":{"id":"t1"}}`
+	var out bytes.Buffer
+	if err := ServeStdio(context.Background(), handler, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	var response models.JSONRPCResponse
+	if err := json.Unmarshal(out.Bytes(), &response); err != nil {
+		t.Fatalf("Output is not JSON-RPC: %q", out.String())
+	}
+	if response.ID != "unsigned" || response.Error == nil || response.Error.Message != "missing or invalid request timestamp" {
+		t.Errorf("Expected HTTP error to be wrapped in a JSON-RPC error, got %+v", response)
+	}
+}
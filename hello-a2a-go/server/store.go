@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"a2a/models"
+)
+
+// ErrTaskNotFound is returned by a TaskStore for unknown task IDs
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskStore persists tasks and the messages received for them. Implementations must be safe
+// for concurrent use.
+type TaskStore interface {
+	// Backend names the storage backend, as reported by introspection
+	Backend() string
+	// Get returns the task with id, or ErrTaskNotFound
+	Get(ctx context.Context, id string) (*models.Task, error)
+	// Save creates or replaces a task, recording its status when its state changed
+	Save(ctx context.Context, task *models.Task) error
+	// AppendMessage records a message received for a task
+	AppendMessage(ctx context.Context, taskID string, message *models.Message) error
+	// Messages returns the messages received for a task, oldest first
+	Messages(ctx context.Context, taskID string) ([]*models.Message, error)
+	// StatusHistory returns the statuses recorded for a task, oldest first
+	StatusHistory(ctx context.Context, taskID string) ([]models.TaskStatus, error)
+	// AppendEvent records a models.TaskStatusUpdateEvent or models.TaskArtifactUpdateEvent
+	// streamed for a task, returning it numbered with the task's next sequence number
+	AppendEvent(ctx context.Context, taskID string, event interface{}) (interface{}, error)
+	// Events returns the events recorded for a task with a sequence number above after, in
+	// order
+	Events(ctx context.Context, taskID string, after int64) ([]interface{}, error)
+	// Count returns the number of stored tasks
+	Count(ctx context.Context) (int, error)
+	// ListTasks returns the tasks of the conversation contextID ordered by ID, or every task
+	// when contextID is empty
+	ListTasks(ctx context.Context, contextID string) ([]*models.Task, error)
+	// Delete removes a task with its messages, statuses and events; deleting an unknown task is not
+	// an error
+	Delete(ctx context.Context, id string) error
+}
+
+// WithTaskStore sets the store tasks are persisted in; the default is a MemoryTaskStore
+func WithTaskStore(store TaskStore) Option {
+	return func(s *A2AServer) {
+		s.store = store
+	}
+}
+
+// maxStoredEvents bounds the stream events a MemoryTaskStore keeps per task. Older events are
+// dropped, so a client resuming a long stream from before the kept ones misses those events.
+const maxStoredEvents = 1024
+
+// MemoryTaskStore is an in-process TaskStore whose contents are lost when the process exits.
+// It keeps the last maxStoredEvents stream events of each task.
+type MemoryTaskStore struct {
+	mu       sync.RWMutex
+	tasks    map[string]*models.Task
+	messages map[string][]*models.Message
+	statuses map[string][]models.TaskStatus
+	events   map[string]*eventLog
+	// keys holds the API keys of WithAPIKeys by ID
+	keys map[string]APIKey
+}
+
+// NewMemoryTaskStore creates an empty in-memory task store
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{
+		tasks:    make(map[string]*models.Task),
+		messages: make(map[string][]*models.Message),
+		statuses: make(map[string][]models.TaskStatus),
+		events:   make(map[string]*eventLog),
+		keys:     make(map[string]APIKey),
+	}
+}
+
+// Backend implements TaskStore
+func (m *MemoryTaskStore) Backend() string {
+	return "memory"
+}
+
+// Get implements TaskStore
+func (m *MemoryTaskStore) Get(ctx context.Context, id string) (*models.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// Save implements TaskStore
+func (m *MemoryTaskStore) Save(ctx context.Context, task *models.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tasks[task.ID] = task
+	statuses := m.statuses[task.ID]
+	if n := len(statuses); n == 0 || statuses[n-1].State != task.Status.State {
+		m.statuses[task.ID] = append(statuses, task.Status)
+	}
+	return nil
+}
+
+// AppendMessage implements TaskStore
+func (m *MemoryTaskStore) AppendMessage(ctx context.Context, taskID string, message *models.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages[taskID] = append(m.messages[taskID], message)
+	return nil
+}
+
+// Messages implements TaskStore
+func (m *MemoryTaskStore) Messages(ctx context.Context, taskID string) ([]*models.Message, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]*models.Message(nil), m.messages[taskID]...), nil
+}
+
+// StatusHistory implements TaskStore
+func (m *MemoryTaskStore) StatusHistory(ctx context.Context, taskID string) ([]models.TaskStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]models.TaskStatus(nil), m.statuses[taskID]...), nil
+}
+
+// eventLog holds the most recent stream events of a task
+type eventLog struct {
+	// dropped counts the events discarded ahead of events, which are numbered from dropped+1
+	dropped int64
+	events  []interface{}
+}
+
+// AppendEvent implements TaskStore, dropping the task's oldest event beyond maxStoredEvents
+func (m *MemoryTaskStore) AppendEvent(ctx context.Context, taskID string, event interface{}) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := m.events[taskID]
+	if history == nil {
+		history = &eventLog{}
+		m.events[taskID] = history
+	}
+	event, err := withSequence(event, history.dropped+int64(len(history.events))+1)
+	if err != nil {
+		return nil, err
+	}
+	history.events = append(history.events, event)
+	if len(history.events) > maxStoredEvents {
+		history.events[0] = nil
+		history.events = history.events[1:]
+		history.dropped++
+	}
+	return event, nil
+}
+
+// Events implements TaskStore
+func (m *MemoryTaskStore) Events(ctx context.Context, taskID string, after int64) ([]interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history := m.events[taskID]
+	if history == nil {
+		return nil, nil
+	}
+	// Events are numbered by their position after those dropped
+	start := max(after-history.dropped, 0)
+	if start > int64(len(history.events)) {
+		return nil, nil
+	}
+	return append([]interface{}(nil), history.events[start:]...), nil
+}
+
+// Count implements TaskStore
+func (m *MemoryTaskStore) Count(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.tasks), nil
+}
+
+// ListTasks implements TaskStore
+func (m *MemoryTaskStore) ListTasks(ctx context.Context, contextID string) ([]*models.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tasks []*models.Task
+	for _, task := range m.tasks {
+		if contextID == "" || task.ContextID == contextID {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+// Delete implements TaskStore
+func (m *MemoryTaskStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tasks, id)
+	delete(m.messages, id)
+	delete(m.statuses, id)
+	delete(m.events, id)
+	return nil
+}
+
+// Tasks returns every stored task ordered by ID
+func (m *MemoryTaskStore) Tasks() []*models.Task {
+	tasks, _ := m.ListTasks(context.Background(), "")
+	return tasks
+}
+
+// SaveAPIKey implements KeyStore
+func (m *MemoryTaskStore) SaveAPIKey(ctx context.Context, key APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys[key.ID] = key
+	return nil
+}
+
+// APIKeyByHash implements KeyStore
+func (m *MemoryTaskStore) APIKeyByHash(ctx context.Context, hash string) (APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+	return APIKey{}, ErrAPIKeyNotFound
+}
+
+// ListAPIKeys implements KeyStore
+func (m *MemoryTaskStore) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// RevokeAPIKey implements KeyStore
+func (m *MemoryTaskStore) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	key.RevokedAt = &at
+	m.keys[id] = key
+	return nil
+}
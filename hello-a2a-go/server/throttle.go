@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// StreamThrottle limits how often artifact chunks emitted with EmitArtifact are streamed.
+// Consecutive appended chunks of the same artifact are coalesced until both limits allow an
+// event; the last chunk of an artifact, a chunk of a different artifact and the end of the
+// task always flush pending output. The zero value streams every chunk as it is emitted.
+type StreamThrottle struct {
+	// MaxEventsPerSecond caps the artifact events per stream (0 means unlimited)
+	MaxEventsPerSecond float64
+	// MinChunkBytes is the output an event accumulates before it is sent (0 means any)
+	MinChunkBytes int
+}
+
+// interval returns the minimum time between events
+func (t StreamThrottle) interval() time.Duration {
+	if t.MaxEventsPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / t.MaxEventsPerSecond)
+}
+
+// WithStreamThrottle sets the throttle of skills without their own (see WithThrottle)
+func WithStreamThrottle(throttle StreamThrottle) Option {
+	return func(s *A2AServer) {
+		s.throttle = throttle
+	}
+}
+
+// WithThrottle sets the stream throttle of a skill, e.g. to coalesce token-by-token output
+func WithThrottle(throttle StreamThrottle) SkillOption {
+	return func(r *skillRoute) {
+		r.throttle = &throttle
+	}
+}
+
+// streamThrottle returns the throttle of the skill named in metadata
+func (s *A2AServer) streamThrottle(metadata map[string]interface{}) StreamThrottle {
+	skillID, _ := metadata[SkillMetadataKey].(string)
+	if route, err := s.route(skillID); err == nil && route != nil && route.throttle != nil {
+		return *route.throttle
+	}
+	return s.throttle
+}
+
+// artifactEmitterKey is the context key for the current artifactEmitter
+type artifactEmitterKey struct{}
+
+// EmitArtifact streams artifact as an update of the task executing in ctx, letting handlers
+// send output such as model tokens as it is produced. Chunks continuing an artifact set Append
+// and an Index matching the first chunk. It is a no-op outside message/stream requests.
+func EmitArtifact(ctx context.Context, artifact models.Artifact) {
+	if emitter, ok := ctx.Value(artifactEmitterKey{}).(*artifactEmitter); ok {
+		emitter.emit(artifact)
+	}
+}
+
+// artifactEmitter coalesces the artifact chunks of one task according to a StreamThrottle and
+// interleaves them with the task's intermediate status updates
+type artifactEmitter struct {
+	taskID     string
+	throttle   StreamThrottle
+	clock      clock.Clock
+	send       func(models.TaskArtifactUpdateEvent)
+	sendStatus func(models.TaskStatusUpdateEvent)
+
+	mu       sync.Mutex
+	activity func()
+	filter   func(*models.Artifact) bool
+	pending  *models.Artifact
+	lastSent time.Time
+	timer    clock.Timer
+	closed   bool
+}
+
+// emit queues artifact, merging it into pending output when it continues the same artifact
+func (e *artifactEmitter) emit(artifact models.Artifact) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.touchLocked()
+
+	if e.pending != nil && continues(e.pending, &artifact) {
+		mergeArtifact(e.pending, &artifact)
+	} else {
+		e.flushLocked()
+		// Copy the parts and metadata later chunks are merged into
+		artifact.Parts = append([]models.Part(nil), artifact.Parts...)
+		if artifact.Metadata != nil {
+			metadata := make(map[string]interface{}, len(artifact.Metadata))
+			for k, v := range artifact.Metadata {
+				metadata[k] = v
+			}
+			artifact.Metadata = metadata
+		}
+		e.pending = &artifact
+	}
+	if e.pending.LastChunk != nil && *e.pending.LastChunk {
+		e.flushLocked()
+		return
+	}
+	if chunkBytes(e.pending.Parts) < e.throttle.MinChunkBytes {
+		return
+	}
+	if wait := e.lastSent.Add(e.throttle.interval()).Sub(e.clock.Now()); wait > 0 {
+		if e.timer == nil {
+			e.timer = e.clock.AfterFunc(wait, e.flushDue)
+		}
+		return
+	}
+	e.flushLocked()
+}
+
+// status sends status as an intermediate update after any pending output
+func (e *artifactEmitter) status(status models.TaskStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.touchLocked()
+	e.flushLocked()
+	e.sendStatus(models.TaskStatusUpdateEvent{ID: e.taskID, Status: status, Final: boolPtr(false)})
+}
+
+// setActivity sets a function called on each artifact or status the handler emits
+func (e *artifactEmitter) setActivity(activity func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.activity = activity
+}
+
+// setFilter sets a function rewriting each artifact event before it is sent; events it
+// rejects are dropped
+func (e *artifactEmitter) setFilter(filter func(*models.Artifact) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filter = filter
+}
+
+// touchLocked reports handler activity
+func (e *artifactEmitter) touchLocked() {
+	if e.activity != nil {
+		e.activity()
+	}
+}
+
+// flushDue sends output held back by the event rate
+func (e *artifactEmitter) flushDue() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timer = nil
+	if !e.closed {
+		e.flushLocked()
+	}
+}
+
+// close flushes pending output; later chunks are dropped
+func (e *artifactEmitter) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushLocked()
+	e.closed = true
+}
+
+// flushLocked sends pending output, if any
+func (e *artifactEmitter) flushLocked() {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if e.pending == nil {
+		return
+	}
+	artifact := e.pending
+	e.pending = nil
+	if e.filter != nil && !e.filter(artifact) {
+		return
+	}
+	e.send(models.TaskArtifactUpdateEvent{ID: e.taskID, Artifact: *artifact})
+	e.lastSent = e.clock.Now()
+}
+
+// continues reports whether next appends to the artifact of pending
+func continues(pending, next *models.Artifact) bool {
+	if next.Append == nil || !*next.Append {
+		return false
+	}
+	if (pending.Index == nil) != (next.Index == nil) {
+		return false
+	}
+	return pending.Index == nil || *pending.Index == *next.Index
+}
+
+// mergeArtifact appends the parts of next to pending, joining adjacent text parts
+func mergeArtifact(pending, next *models.Artifact) {
+	for _, part := range next.Parts {
+		text, ok := part.(models.TextPart)
+		if n := len(pending.Parts); ok && n > 0 {
+			if last, ok := pending.Parts[n-1].(models.TextPart); ok {
+				last.Text += text.Text
+				pending.Parts[n-1] = last
+				continue
+			}
+		}
+		pending.Parts = append(pending.Parts, part)
+	}
+	pending.LastChunk = next.LastChunk
+	for k, v := range next.Metadata {
+		if pending.Metadata == nil {
+			pending.Metadata = make(map[string]interface{})
+		}
+		pending.Metadata[k] = v
+	}
+}
+
+// chunkBytes returns the size of parts: the length of text, or the encoded size of other parts
+func chunkBytes(parts []models.Part) int {
+	n := 0
+	for _, part := range parts {
+		if text, ok := part.(models.TextPart); ok {
+			n += len(text.Text)
+			continue
+		}
+		if data, err := json.Marshal(part); err == nil {
+			n += len(data)
+		}
+	}
+	return n
+}
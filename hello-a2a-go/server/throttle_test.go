@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// textChunk returns a chunk of the artifact with index
+func textChunk(index int, text string, appendChunk, lastChunk bool) models.Artifact {
+	return models.Artifact{
+		Parts:     []models.Part{models.TextPart{Type: "text", Text: text}},
+		Index:     &index,
+		Append:    &appendChunk,
+		LastChunk: &lastChunk,
+	}
+}
+
+// eventText joins the text parts of an artifact event
+func eventText(event models.TaskArtifactUpdateEvent) string {
+	var b strings.Builder
+	for _, part := range event.Artifact.Parts {
+		if text, ok := part.(models.TextPart); ok {
+			b.WriteString(text.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestArtifactEmitter(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	var events []models.TaskArtifactUpdateEvent
+	emitter := &artifactEmitter{
+		taskID:   "t",
+		throttle: StreamThrottle{MaxEventsPerSecond: 10, MinChunkBytes: 5},
+		clock:    fake,
+		send:     func(event models.TaskArtifactUpdateEvent) { events = append(events, event) },
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		var got []string
+		for _, event := range events {
+			got = append(got, eventText(event))
+		}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Fatalf("Expected events %q, got %q", want, got)
+		}
+	}
+
+	emitter.emit(textChunk(0, "He", false, false))
+	expect()
+	emitter.emit(textChunk(0, "llo", true, false))
+	expect("Hello")
+
+	// Enough output, but the event rate holds it back until the interval passes
+	emitter.emit(textChunk(0, " wor", true, false))
+	emitter.emit(textChunk(0, "ld", true, false))
+	expect("Hello")
+	fake.Advance(100 * time.Millisecond)
+	expect("Hello", " world")
+
+	// The last chunk flushes regardless of size and rate
+	emitter.emit(textChunk(0, "!", true, true))
+	expect("Hello", " world", "!")
+
+	// A different artifact is never merged, and close flushes the rest
+	emitter.emit(textChunk(1, "a", false, false))
+	emitter.emit(textChunk(2, "b", true, false))
+	expect("Hello", " world", "!", "a")
+	emitter.close()
+	expect("Hello", " world", "!", "a", "b")
+	if *events[4].Artifact.Index != 2 || events[4].ID != "t" {
+		t.Errorf("Unexpected event %+v", events[4])
+	}
+
+	emitter.emit(textChunk(2, "late", true, true))
+	expect("Hello", " world", "!", "a", "b")
+}
+
+func TestA2AServer_StreamThrottle(t *testing.T) {
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		for i := 0; i < 100; i++ {
+			EmitArtifact(ctx, textChunk(0, "x", i > 0, i == 99))
+		}
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	}
+	server := NewA2AServer(mockAgentCard, handler)
+	if err := server.AddSkill(models.AgentSkill{ID: "tokens", Name: "Tokens"}, nil, WithThrottle(StreamThrottle{MinChunkBytes: 25})); err != nil {
+		t.Fatal(err)
+	}
+
+	stream := func(metadata string) []string {
+		body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"tokens","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]},"metadata":` + metadata + `}}`
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+		var chunks []string
+		for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+			var response struct {
+				Result models.TaskArtifactUpdateEvent `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(line), &response); err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+			if text := eventText(response.Result); text != "" {
+				chunks = append(chunks, text)
+			}
+		}
+		return chunks
+	}
+
+	if chunks := stream(`{}`); len(chunks) != 100 {
+		t.Errorf("Expected every chunk without a throttle, got %d events", len(chunks))
+	}
+	chunks := stream(`{"skillId":"tokens"}`)
+	if len(chunks) != 4 || strings.Join(chunks, "") != strings.Repeat("x", 100) {
+		t.Errorf("Expected 4 coalesced events with all output, got %q", chunks)
+	}
+
+	if response := doRPC(t, server, "message/send", models.MessageSendParams{ID: "sync", Message: models.Message{Role: "user"}}); response.Error != nil {
+		t.Errorf("Expected EmitArtifact to be ignored outside streams, got %v", response.Error)
+	}
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+// ErrTaskTimedOut is the cause of a handler's context when the task runs into a timeout set with
+// WithRequestTimeout, WithTaskTimeout or WithStreamIdleTimeout; handlers should stop work and
+// return promptly once the context is done. The task then fails, with the timeout recorded in
+// its metadata under ErrorMetadataKey.
+var ErrTaskTimedOut = errors.New("task timed out")
+
+// ErrorMetadataKey is the task metadata key describing why a task failed. A task failed by a
+// timeout has {"type": "TimedOut", "timeout": "request", "task" or "idle", "seconds": n}.
+const ErrorMetadataKey = "error"
+
+// WithRequestTimeout bounds the handler run of a task started by a request without streaming,
+// so that the client gets a response within d
+func WithRequestTimeout(d time.Duration) Option {
+	return func(s *A2AServer) {
+		s.timeouts.request = d
+	}
+}
+
+// WithTaskTimeout bounds the handler run of every task, streaming or not, to d
+func WithTaskTimeout(d time.Duration) Option {
+	return func(s *A2AServer) {
+		s.timeouts.task = d
+	}
+}
+
+// WithStreamIdleTimeout fails a streaming task whose handler publishes no event (see EmitArtifact
+// and EmitStatus) for d
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(s *A2AServer) {
+		s.timeouts.idle = d
+	}
+}
+
+// timeouts are the timeouts of task handler runs; zero means none
+type timeouts struct {
+	request time.Duration
+	task    time.Duration
+	idle    time.Duration
+}
+
+// timeoutError is the cause of a handler's context when a timeout passes
+type timeoutError struct {
+	// kind is "request", "task" or "idle"
+	kind  string
+	after time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s timeout of %s exceeded", e.kind, e.after)
+}
+
+func (e *timeoutError) Unwrap() error {
+	return ErrTaskTimedOut
+}
+
+// deadlineContext reports the deadline of the timeouts of a handler run, which are timed on the
+// server's clock instead of by the context itself
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c deadlineContext) Deadline() (time.Time, bool) {
+	if parent, ok := c.Context.Deadline(); ok && parent.Before(c.deadline) {
+		return parent, true
+	}
+	return c.deadline, true
+}
+
+// startTimeouts returns ctx canceled with a timeoutError when a timeout of the handler run
+// passes, and a function to call once the handler returns. Streaming runs have no request
+// timeout, but an idle timeout restarted by each event their emitter publishes.
+func (s *A2AServer) startTimeouts(ctx context.Context, streaming bool) (context.Context, func()) {
+	t := s.timeouts
+	if !streaming {
+		t.idle = 0
+	} else {
+		t.request = 0
+	}
+	if t.request == 0 && t.task == 0 && t.idle == 0 {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	var timers []clock.Timer
+	var deadline time.Time
+	for _, timeout := range []*timeoutError{{"request", t.request}, {"task", t.task}} {
+		if timeout.after == 0 {
+			continue
+		}
+		timers = append(timers, s.clock.AfterFunc(timeout.after, func() { cancel(timeout) }))
+		if at := s.clock.Now().Add(timeout.after); deadline.IsZero() || at.Before(deadline) {
+			deadline = at
+		}
+	}
+	var idle *idleTimer
+	if emitter, ok := ctx.Value(artifactEmitterKey{}).(*artifactEmitter); ok && t.idle > 0 {
+		idle = &idleTimer{clock: s.clock, after: t.idle, fire: func() { cancel(&timeoutError{"idle", t.idle}) }}
+		idle.reset()
+		emitter.setActivity(idle.reset)
+	}
+	if !deadline.IsZero() {
+		ctx = deadlineContext{Context: ctx, deadline: deadline}
+	}
+
+	return ctx, func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+		if idle != nil {
+			idle.stop()
+		}
+		cancel(nil)
+	}
+}
+
+// idleTimer fires once it has not been reset for a while
+type idleTimer struct {
+	clock clock.Clock
+	after time.Duration
+	fire  func()
+
+	mu      sync.Mutex
+	timer   clock.Timer
+	stopped bool
+}
+
+// reset restarts the timer
+func (t *idleTimer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = t.clock.AfterFunc(t.after, t.fire)
+}
+
+// stop stops the timer for good
+func (t *idleTimer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// timedOut returns result failed by the timeout that canceled ctx, or nil when none did
+func timedOut(ctx context.Context, result *models.Task) *models.Task {
+	var timeout *timeoutError
+	if !errors.As(context.Cause(ctx), &timeout) {
+		return nil
+	}
+	result.Status = models.TaskStatus{
+		State: models.TaskStateFailed,
+		Message: &models.Message{
+			Role:  "agent",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Task timed out: " + timeout.Error()}},
+		},
+	}
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata[ErrorMetadataKey] = map[string]interface{}{
+		"type":    "TimedOut",
+		"timeout": timeout.kind,
+		"seconds": timeout.after.Seconds(),
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a/clock"
+	"a2a/models"
+)
+
+func TestA2AServer_RequestTimeout(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	started := make(chan time.Time, 1)
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		deadline, _ := ctx.Deadline()
+		started <- deadline
+		<-ctx.Done()
+		if !errors.Is(context.Cause(ctx), ErrTaskTimedOut) {
+			t.Errorf("Expected the context canceled by a timeout, got %v", context.Cause(ctx))
+		}
+		return task, ctx.Err()
+	}
+	server := NewA2AServer(mockAgentCard, handler, WithClock(fake), WithRequestTimeout(time.Second), WithTaskTimeout(time.Minute))
+
+	responses := make(chan models.JSONRPCResponse, 1)
+	go func() {
+		responses <- doRPC(t, server, "message/send", models.MessageSendParams{ID: "slow", Message: models.Message{Role: "user"}})
+	}()
+	if deadline := <-started; !deadline.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected the request deadline to propagate, got %v", deadline)
+	}
+	fake.Advance(time.Second)
+
+	response := <-responses
+	var task models.Task
+	decodeResult(t, response.Result, &task)
+	if response.Error != nil || task.Status.State != models.TaskStateFailed {
+		t.Fatalf("Expected the task to fail, got %+v (%v)", task, response.Error)
+	}
+	detail, _ := task.Metadata[ErrorMetadataKey].(map[string]interface{})
+	if detail["type"] != "TimedOut" || detail["timeout"] != "request" || detail["seconds"] != 1.0 {
+		t.Errorf("Expected the timeout in the task metadata, got %v", task.Metadata)
+	}
+	stored := doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "slow"}})
+	decodeResult(t, stored.Result, &task)
+	if task.Status.State != models.TaskStateFailed {
+		t.Errorf("Expected the failed task to be stored, got %s", task.Status.State)
+	}
+}
+
+func TestA2AServer_StreamIdleTimeout(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	tick, ack := make(chan struct{}), make(chan error)
+	handler := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		for {
+			select {
+			case <-tick:
+				EmitStatus(ctx, models.TaskStatus{State: models.TaskStateWorking})
+				ack <- ctx.Err()
+			case <-ctx.Done():
+				return task, ctx.Err()
+			}
+		}
+	}
+	// The request timeout does not apply to streams
+	server := NewA2AServer(mockAgentCard, handler, WithClock(fake), WithRequestTimeout(time.Millisecond), WithStreamIdleTimeout(time.Second))
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		body := `{"jsonrpc":"2.0","id":1,"method":"message/stream","params":{"id":"idle","message":{"role":"user","parts":[{"kind":"text","text":"Go"}]}}}`
+		server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	}()
+
+	// Each event restarts the idle timeout
+	for i := 0; i < 3; i++ {
+		tick <- struct{}{}
+		if err := <-ack; err != nil {
+			t.Fatalf("Expected the stream to stay alive while emitting, got %v", err)
+		}
+		fake.Advance(600 * time.Millisecond)
+	}
+	fake.Advance(400 * time.Millisecond)
+	<-done
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var final struct {
+		Result models.TaskStatusUpdateEvent `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &final); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	if final.Result.Status.State != models.TaskStateFailed || final.Result.Final == nil || !*final.Result.Final {
+		t.Errorf("Expected a failed final event, got %+v", final.Result)
+	}
+
+	var task models.Task
+	decodeResult(t, doRPC(t, server, "tasks/get", models.TaskQueryParams{TaskIDParams: models.TaskIDParams{ID: "idle"}}).Result, &task)
+	if detail, _ := task.Metadata[ErrorMetadataKey].(map[string]interface{}); detail["timeout"] != "idle" {
+		t.Errorf("Expected the idle timeout in the task metadata, got %v", task.Metadata)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// tlsFiles are the PEM files configured with WithTLS
+type tlsFiles struct {
+	certFile, keyFile, clientCAs string
+}
+
+// WithTLS makes Start and Agent.ListenAndServe serve HTTPS with the certificate chain and key in
+// the PEM files certFile and keyFile. When clientCAs names a PEM file of CA certificates, clients
+// must present a certificate issued by one of them (mutual TLS).
+func WithTLS(certFile, keyFile, clientCAs string) Option {
+	return func(s *A2AServer) {
+		s.tls = &tlsFiles{certFile: certFile, keyFile: keyFile, clientCAs: clientCAs}
+	}
+}
+
+// TLSConfig returns the TLS configuration of WithTLS, for serving with an http.Server of your
+// own, or nil when the server speaks plain HTTP
+func (s *A2AServer) TLSConfig() (*tls.Config, error) {
+	if s.tls == nil {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(s.tls.certFile, s.tls.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if s.tls.clientCAs == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(s.tls.clientCAs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CAs: %w", err)
+	}
+	cfg.ClientCAs = x509.NewCertPool()
+	if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, errors.New("failed to read client CAs: no certificates found")
+	}
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return cfg, nil
+}
+
+// listenAndServe serves handler on addr, over TLS when configured with WithTLS
+func (s *A2AServer) listenAndServe(addr string, handler http.Handler) error {
+	cfg, err := s.TLSConfig()
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: addr, Handler: handler, TLSConfig: cfg}
+	if cfg == nil {
+		return srv.ListenAndServe()
+	}
+	return srv.ListenAndServeTLS("", "")
+}
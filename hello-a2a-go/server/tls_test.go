@@ -0,0 +1,137 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issueCert writes a certificate for name and its key to dir as name.pem and name-key.pem,
+// signed by parent and parentKey or self-signed as a CA when parent is nil
+func issueCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTLS_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := issueCert(t, dir, "ca", nil, nil)
+	issueCert(t, dir, "server", ca, caKey)
+	issueCert(t, dir, "client", ca, caKey)
+
+	server := NewA2AServer(mockAgentCard, mockTaskHandler,
+		WithTLS(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem")))
+	cfg, err := server.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(server)
+	ts.TLS = cfg
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := client.Get(ts.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+	if err := get(clientCert); err != nil {
+		t.Errorf("Expected a client certificate issued by the CA to be accepted, got %v", err)
+	}
+}
+
+func TestWithTLS_ServerOnly(t *testing.T) {
+	dir := t.TempDir()
+	issueCert(t, dir, "server", nil, nil)
+
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithTLS(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), ""))
+	cfg, err := server.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.NoClientCert || len(cfg.Certificates) != 1 {
+		t.Errorf("Expected one certificate and no client authentication, got %+v", cfg)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	if cfg, err := NewA2AServer(mockAgentCard, mockTaskHandler).TLSConfig(); cfg != nil || err != nil {
+		t.Errorf("Expected no TLS configuration without WithTLS, got %v, %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	issueCert(t, dir, "server", nil, nil)
+	os.WriteFile(filepath.Join(dir, "empty.pem"), nil, 0o600)
+	for name, server := range map[string]*A2AServer{
+		"missing certificate": NewA2AServer(mockAgentCard, mockTaskHandler, WithTLS(filepath.Join(dir, "nope.pem"), filepath.Join(dir, "server-key.pem"), "")),
+		"missing client CAs":  NewA2AServer(mockAgentCard, mockTaskHandler, WithTLS(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "nope.pem"))),
+		"empty client CAs":    NewA2AServer(mockAgentCard, mockTaskHandler, WithTLS(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "empty.pem"))),
+	} {
+		if _, err := server.TLSConfig(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
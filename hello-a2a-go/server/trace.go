@@ -0,0 +1,32 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"a2a/models"
+	"a2a/trace"
+)
+
+// traceRPC continues the trace of a JSON-RPC request in a server span for the call. The trace
+// context comes from the request headers or, for transports without them, the metadata of the
+// params. The returned request's context carries the span's trace context.
+func traceRPC(r *http.Request, req *models.JSONRPCRequest) (*http.Request, trace.Span) {
+	c := trace.FromHeader(r.Header)
+	if !c.IsValid() {
+		if params, ok := req.Params.(map[string]interface{}); ok {
+			metadata, _ := params["metadata"].(map[string]interface{})
+			if fromMetadata := trace.FromMetadata(metadata); fromMetadata.IsValid() {
+				if fromMetadata.Baggage == "" {
+					fromMetadata.Baggage = c.Baggage
+				}
+				c = fromMetadata
+			}
+		}
+	}
+
+	ctx := trace.NewContext(r.Context(), c)
+	ctx, span := trace.Start(ctx, "a2a.server "+req.Method, trace.SpanKindServer,
+		slog.String("rpc.method", req.Method), slog.Any("rpc.id", req.ID))
+	return r.WithContext(ctx), span
+}
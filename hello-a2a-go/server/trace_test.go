@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+	"a2a/trace"
+)
+
+func TestTraceRPC(t *testing.T) {
+	rec := &trace.Recorder{}
+	trace.SetTracer(trace.NewTracer(rec.Export))
+	defer trace.SetTracer(nil)
+
+	var handlerTrace trace.Context
+	server := NewA2AServer(mockAgentCard, func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		handlerTrace, _ = trace.FromContext(ctx)
+		task.Status.State = models.TaskStateCompleted
+		return task, nil
+	})
+
+	// The trace context arrives in the metadata, as over stdio
+	body := `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"id":"traced","message":{"role":"user","parts":[{"kind":"text","text":"Hi"}]},` +
+		`"metadata":{"traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}}`
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	spans := rec.Spans()
+	if len(spans) != 2 || spans[0].Name != "a2a.handler" || spans[1].Name != "a2a.server message/send" {
+		t.Fatalf("Expected handler and server spans, got %+v", spans)
+	}
+	handler, rpc := spans[0], spans[1]
+	if rpc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rpc.ParentID != "00f067aa0ba902b7" || rpc.Kind != trace.SpanKindServer {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", rpc)
+	}
+	if handler.ParentID != rpc.SpanID {
+		t.Errorf("Expected the handler span to be the server span's child, got %+v", handler)
+	}
+	if want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + handler.SpanID + "-01"; handlerTrace.TraceParent != want {
+		t.Errorf("Expected the handler's calls to name its span as parent %s, got %s", want, handlerTrace.TraceParent)
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// uiFiles holds the web UI: a page that loads the agent card, sends messages and shows the
+// streamed events and artifacts of their tasks
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// WithUI serves a web UI for trying the agent at /ui/ (see UIHandler). The page talks to the
+// agent's own endpoints, sending the bearer token or API key typed into it, so the UI itself is
+// public like the agent card.
+func WithUI() Option {
+	return func(s *A2AServer) {
+		s.ui = true
+	}
+}
+
+// UIHandler serves the embedded web UI under /ui/, for mounting on a custom mux next to the
+// agent card and the JSON-RPC endpoints, which the page addresses relative to /ui/
+func (s *A2AServer) UIHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(files)))
+}
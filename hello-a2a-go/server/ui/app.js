@@ -0,0 +1,304 @@
+// A page for trying an A2A agent: it loads the agent card, sends messages with message/send or
+// message/stream, shows every streamed event and renders the task's artifacts. It is served at
+// /ui/ next to the agent's endpoints, which it addresses relative to itself.
+'use strict';
+
+const cardURL = new URL('../.well-known/agent-card', location.href);
+const rpcURL = new URL('../a2a', location.href);
+const streamURL = new URL('../a2a/stream', location.href);
+
+const $ = (id) => document.getElementById(id);
+
+// The conversation the next message continues, and the task awaiting input, if any
+let contextId = '';
+let pendingTaskId = '';
+let requests = 0;
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs)) {
+    if (name === 'class') node.className = value;
+    else node.setAttribute(name, value);
+  }
+  for (const child of children) {
+    node.append(child);
+  }
+  return node;
+}
+
+function headers(extra = {}) {
+  const h = { 'Content-Type': 'application/json', ...extra };
+  const token = $('token').value.trim();
+  if (token) {
+    if ($('auth-scheme').value === 'api-key') h['X-API-Key'] = token;
+    else h['Authorization'] = 'Bearer ' + token;
+  }
+  sessionStorage.setItem('a2a-auth-scheme', $('auth-scheme').value);
+  sessionStorage.setItem('a2a-token', token);
+  return h;
+}
+
+async function loadCard() {
+  try {
+    const resp = await fetch(cardURL);
+    if (!resp.ok) throw new Error(resp.status + ' ' + resp.statusText);
+    const card = await resp.json();
+    document.title = card.name + ' - A2A';
+    $('agent-name').textContent = card.name;
+    $('agent-description').textContent = card.description || '';
+    const streaming = card.capabilities && card.capabilities.streaming;
+    $('agent-meta').textContent = ['v' + card.version, card.protocolVersion && 'A2A ' + card.protocolVersion,
+      streaming ? 'streaming' : 'no streaming'].filter(Boolean).join(' · ');
+    $('streaming').checked = !!streaming;
+    $('streaming').disabled = !streaming;
+    for (const skill of card.skills || []) {
+      $('skill').append(el('option', { value: skill.id, title: skill.description || '' }, skill.name));
+    }
+  } catch (err) {
+    $('agent-meta').textContent = 'Failed to load the agent card: ' + err.message;
+    $('agent-meta').className = 'error';
+  }
+}
+
+function logEvent(label, value) {
+  const text = JSON.stringify(value, null, 2);
+  $('event-log').append(el('li', {}, el('strong', {}, label), el('pre', {}, text.length > 4000 ? text.slice(0, 4000) + '…' : text)));
+  $('events').scrollTop = $('events').scrollHeight;
+}
+
+function bubble(role, label) {
+  const node = el('div', { class: 'bubble ' + role }, el('span', { class: 'label' }, label));
+  $('conversation').append(node);
+  $('conversation').scrollTop = $('conversation').scrollHeight;
+  return node;
+}
+
+// fileOf returns the name, MIME type and bytes or URI of a file part, in this package's form
+// ({fileName, mimeType, content: {bytes | uri}}) or the A2A spec's ({file: {name, mimeType, bytes | uri}})
+function fileOf(part) {
+  const file = part.file || part.content || {};
+  return {
+    name: part.fileName || file.name || 'file',
+    mimeType: part.mimeType || file.mimeType || 'application/octet-stream',
+    bytes: file.bytes,
+    uri: file.uri,
+  };
+}
+
+function renderPart(part) {
+  switch (part.kind || part.type) {
+    case 'text':
+      return el('div', {}, part.text);
+    case 'data':
+      return el('pre', {}, JSON.stringify(part.data, null, 2));
+    case 'file': {
+      const file = fileOf(part);
+      const href = file.bytes ? 'data:' + file.mimeType + ';base64,' + file.bytes : file.uri;
+      if (!href) return el('div', { class: 'muted' }, file.name + ' (empty)');
+      const link = el('a', { href, download: file.name, target: '_blank', rel: 'noopener' }, file.name + ' (' + file.mimeType + ')');
+      if (file.mimeType.startsWith('image/')) {
+        return el('div', {}, el('img', { src: href, alt: file.name }), link);
+      }
+      return el('div', {}, link);
+    }
+  }
+  return el('pre', {}, JSON.stringify(part, null, 2));
+}
+
+// Answer renders an agent's answer to one message: its status and its artifacts, which streamed
+// chunks update in place
+class Answer {
+  constructor() {
+    this.node = bubble('agent', 'agent');
+    this.status = el('div', { class: 'muted' }, 'sending…');
+    this.node.append(this.status);
+    this.artifacts = new Map();
+  }
+
+  setStatus(status) {
+    this.status.replaceChildren(el('span', { class: 'label' }, status.state));
+    this.status.className = status.state === 'failed' || status.state === 'rejected' ? 'error' : '';
+    for (const part of (status.message && status.message.parts) || []) {
+      this.status.append(renderPart(part));
+    }
+  }
+
+  addArtifact(artifact, append) {
+    const key = artifact.artifactId || String(artifact.index ?? this.artifacts.size);
+    let entry = this.artifacts.get(key);
+    if (!entry || !append) {
+      const node = el('div', { class: 'artifact' }, el('span', { class: 'label' }, artifact.name || 'artifact ' + key));
+      if (entry) entry.node.replaceWith(node);
+      else this.node.append(node);
+      entry = { node, text: null };
+      this.artifacts.set(key, entry);
+    }
+    for (const part of artifact.parts || []) {
+      if ((part.kind || part.type) === 'text' && append && entry.text) {
+        entry.text.textContent += part.text;
+        continue;
+      }
+      const rendered = renderPart(part);
+      if ((part.kind || part.type) === 'text') entry.text = rendered;
+      entry.node.append(rendered);
+    }
+    $('conversation').scrollTop = $('conversation').scrollHeight;
+  }
+
+  // task renders a task answered by message/send or tasks/get
+  task(task) {
+    this.track(task);
+    this.setStatus(task.status);
+    for (const artifact of task.artifacts || []) this.addArtifact(artifact, false);
+  }
+
+  // message renders a message the agent answered with instead of a task
+  message(message) {
+    this.status.replaceChildren(...message.parts.map(renderPart));
+    this.status.className = '';
+  }
+
+  track(task) {
+    if (task.id) this.taskId = task.id;
+    if (task.contextId) contextId = task.contextId;
+    pendingTaskId = task.status && task.status.state === 'input-required' ? task.id : '';
+  }
+
+  error(err) {
+    this.status.textContent = err.message + (err.hint ? ' (' + err.hint + ')' : '');
+    this.status.className = 'error';
+  }
+}
+
+function rpcError(error) {
+  const err = new Error(error.message + ' [' + error.code + ']');
+  err.hint = error.data && error.data.hint;
+  return err;
+}
+
+// handleResult renders one result of a stream or of message/send
+function handleResult(answer, result) {
+  if (result.artifact) {
+    answer.addArtifact(result.artifact, !!result.append || !!result.artifact.append);
+  } else if (result.status && result.final !== undefined) {
+    // A slow page may miss events, which the stored task makes up for
+    if (result.metadata && result.metadata.eventsDropped) answer.dropped = true;
+    answer.track({ id: result.id || result.taskId, contextId: result.contextId, status: result.status });
+    answer.setStatus(result.status);
+  } else if (result.kind === 'message' || (result.role && result.parts)) {
+    answer.message(result);
+  } else if (result.status) {
+    answer.task(result);
+  }
+}
+
+// readStream renders the Server-Sent Events of a streamed response as they arrive
+async function readStream(resp, answer) {
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) break;
+    buffer += decoder.decode(value, { stream: true }).replace(/\r\n/g, '\n');
+    let end;
+    while ((end = buffer.indexOf('\n\n')) >= 0) {
+      const frame = buffer.slice(0, end);
+      buffer = buffer.slice(end + 2);
+      const data = frame.split('\n').filter((line) => line.startsWith('data:')).map((line) => line.slice(5).trimStart()).join('\n');
+      if (!data) continue;
+      const event = JSON.parse(data);
+      if (event.error) throw rpcError(event.error);
+      logEvent(event.result.artifact ? 'artifact-update' : 'status-update', event.result);
+      handleResult(answer, event.result);
+    }
+  }
+}
+
+// newID returns a random message ID; crypto.randomUUID is only available to secure pages
+function newID() {
+  return crypto.randomUUID ? crypto.randomUUID() : Date.now().toString(16) + '-' + Math.random().toString(16).slice(2);
+}
+
+// call sends a JSON-RPC request and returns its result
+async function call(method, params) {
+  const resp = await fetch(rpcURL, {
+    method: 'POST',
+    headers: headers(),
+    body: JSON.stringify({ jsonrpc: '2.0', id: 'ui-' + ++requests, method, params }),
+  });
+  if (!resp.ok) throw new Error(resp.status + ' ' + (await resp.text()).trim());
+  const body = await resp.json();
+  if (body.error) throw rpcError(body.error);
+  return body.result;
+}
+
+async function send(text) {
+  const streaming = $('streaming').checked;
+  const message = { role: 'user', parts: [{ kind: 'text', text }], messageId: newID() };
+  if (contextId) message.contextId = contextId;
+  if (pendingTaskId) message.taskId = pendingTaskId;
+  const params = { message };
+  if ($('skill').value) params.metadata = { skillId: $('skill').value };
+  const request = { jsonrpc: '2.0', id: 'ui-' + ++requests, method: streaming ? 'message/stream' : 'message/send', params };
+
+  bubble('user', 'you').append(text);
+  logEvent(request.method, params);
+  const answer = new Answer();
+  try {
+    const resp = await fetch(streaming ? streamURL : rpcURL, {
+      method: 'POST',
+      headers: headers(streaming ? { Accept: 'text/event-stream' } : {}),
+      body: JSON.stringify(request),
+    });
+    if (!resp.ok) throw new Error(resp.status + ' ' + (await resp.text()).trim());
+    if (streaming && (resp.headers.get('Content-Type') || '').startsWith('text/event-stream')) {
+      await readStream(resp, answer);
+      // Status events do not carry the conversation, which the stored task does
+      if (answer.taskId) {
+        const task = await call('tasks/get', { id: answer.taskId, historyLength: 0 });
+        if (answer.dropped) answer.task(task);
+        else answer.track(task);
+      }
+      return;
+    }
+    const body = await resp.json();
+    logEvent('response', body);
+    if (body.error) throw rpcError(body.error);
+    handleResult(answer, body.result);
+  } catch (err) {
+    answer.error(err);
+  }
+}
+
+$('composer').addEventListener('submit', async (event) => {
+  event.preventDefault();
+  const text = $('message').value.trim();
+  if (!text) return;
+  $('message').value = '';
+  $('send').disabled = true;
+  try {
+    await send(text);
+  } finally {
+    $('send').disabled = false;
+    $('message').focus();
+  }
+});
+
+$('message').addEventListener('keydown', (event) => {
+  if (event.key === 'Enter' && !event.shiftKey) {
+    event.preventDefault();
+    $('composer').requestSubmit();
+  }
+});
+
+$('new-task').addEventListener('click', () => {
+  contextId = '';
+  pendingTaskId = '';
+  $('conversation').replaceChildren();
+  $('event-log').replaceChildren();
+});
+
+$('auth-scheme').value = sessionStorage.getItem('a2a-auth-scheme') || 'bearer';
+$('token').value = sessionStorage.getItem('a2a-token') || '';
+loadCard();
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestA2AServer_UI(t *testing.T) {
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithUI())
+	mux := http.NewServeMux()
+	// The page is public: the credential typed into it protects the agent's endpoints
+	server.RegisterRoutes(mux, func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	})
+
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/ui/", "text/html", `<script src="app.js">`},
+		{"/ui/app.js", "javascript", "message/stream"},
+		{"/ui/style.css", "text/css", ".bubble"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected %s to be served, got %d", tt.path, w.Code)
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tt.contentType) {
+			t.Errorf("Expected %s to be %s, got %q", tt.path, tt.contentType, ct)
+		}
+		if !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("Expected %s to contain %q", tt.path, tt.contains)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/ui", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/ui/" {
+		t.Errorf("Expected /ui to redirect to /ui/, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/ui/missing.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown files to be missing, got %d", w.Code)
+	}
+}
+
+func TestA2AServer_UIDisabled(t *testing.T) {
+	mux := http.NewServeMux()
+	NewA2AServer(mockAgentCard, mockTaskHandler).RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/ui/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected no UI without WithUI, got %d", w.Code)
+	}
+}
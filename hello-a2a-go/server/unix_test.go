@@ -0,0 +1,47 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "a2a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "agent.sock")
+
+	listener, err := ListenUnix(path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	if _, err := ListenUnix(path); err == nil {
+		t.Error("Expected listening on a socket in use to fail")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o007 != 0 {
+		t.Errorf("Expected socket to be inaccessible to others, got %v", perm)
+	}
+
+	// A socket left behind by a crashed process is replaced
+	listener.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	listener.Close()
+	listener, err = ListenUnix(path)
+	if err != nil {
+		t.Fatalf("Expected stale socket to be replaced, got %v", err)
+	}
+	listener.Close()
+
+	regular := filepath.Join(dir, "regular")
+	os.WriteFile(regular, nil, 0o600)
+	if _, err := ListenUnix(regular); err == nil {
+		t.Error("Expected listening over a regular file to fail")
+	}
+}
@@ -0,0 +1,329 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"a2a/models"
+)
+
+// defaultSkillLabel names usage of the default handler when no skill is requested
+const defaultSkillLabel = "default"
+
+// UsageRecord is the resource consumption of a single task execution
+type UsageRecord struct {
+	Caller        string
+	Skill         string
+	Tokens        int64
+	ArtifactBytes int64
+	WallTime      time.Duration
+	Failed        bool
+}
+
+// Usage aggregates consumption over many task executions
+type Usage struct {
+	Tasks         int64 `json:"tasks"`
+	FailedTasks   int64 `json:"failedTasks"`
+	Tokens        int64 `json:"tokens"`
+	ArtifactBytes int64 `json:"artifactBytes"`
+	WallTimeMs    int64 `json:"wallTimeMs"`
+}
+
+// add folds rec into u
+func (u *Usage) add(rec UsageRecord) {
+	u.Tasks++
+	if rec.Failed {
+		u.FailedTasks++
+	}
+	u.Tokens += rec.Tokens
+	u.ArtifactBytes += rec.ArtifactBytes
+	u.WallTimeMs += rec.WallTime.Milliseconds()
+}
+
+// merge adds other into u
+func (u *Usage) merge(other Usage) {
+	u.Tasks += other.Tasks
+	u.FailedTasks += other.FailedTasks
+	u.Tokens += other.Tokens
+	u.ArtifactBytes += other.ArtifactBytes
+	u.WallTimeMs += other.WallTimeMs
+}
+
+// UsageEntry is the usage attributed to one caller for one skill
+type UsageEntry struct {
+	Caller string `json:"caller"`
+	Skill  string `json:"skill"`
+	Usage
+}
+
+// UsageReport summarizes usage per caller, per skill and in total
+type UsageReport struct {
+	Entries  []UsageEntry     `json:"entries"`
+	ByCaller map[string]Usage `json:"byCaller"`
+	BySkill  map[string]Usage `json:"bySkill"`
+	Total    Usage            `json:"total"`
+}
+
+// UsageStore persists usage records. Implementations must be safe for concurrent use.
+type UsageStore interface {
+	// Record adds the consumption of one task execution
+	Record(ctx context.Context, rec UsageRecord) error
+	// Entries returns the accumulated usage per caller and skill
+	Entries(ctx context.Context) ([]UsageEntry, error)
+}
+
+// MemoryUsageStore is an in-process UsageStore that is reset when the server restarts
+type MemoryUsageStore struct {
+	mu      sync.Mutex
+	entries map[[2]string]*Usage
+}
+
+// NewMemoryUsageStore creates an empty in-memory usage store
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{entries: make(map[[2]string]*Usage)}
+}
+
+// Record adds rec to the caller and skill totals
+func (m *MemoryUsageStore) Record(ctx context.Context, rec UsageRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{rec.Caller, rec.Skill}
+	usage, ok := m.entries[key]
+	if !ok {
+		usage = &Usage{}
+		m.entries[key] = usage
+	}
+	usage.add(rec)
+	return nil
+}
+
+// Entries returns the accumulated usage sorted by caller and skill
+func (m *MemoryUsageStore) Entries(ctx context.Context) ([]UsageEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make([]UsageEntry, 0, len(m.entries))
+	for key, usage := range m.entries {
+		entries = append(entries, UsageEntry{Caller: key[0], Skill: key[1], Usage: *usage})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Caller != entries[j].Caller {
+			return entries[i].Caller < entries[j].Caller
+		}
+		return entries[i].Skill < entries[j].Skill
+	})
+	return entries, nil
+}
+
+// WithUsageStore enables usage accounting, recording every task execution in store
+func WithUsageStore(store UsageStore) Option {
+	return func(s *A2AServer) {
+		s.usage = store
+	}
+}
+
+// WithCallerFunc sets how requests are attributed to callers for accounting and quotas.
+// The default is CallerFromRequest.
+func WithCallerFunc(fn func(*http.Request) string) Option {
+	return func(s *A2AServer) {
+		s.callerFunc = fn
+	}
+}
+
+// CallerFromRequest identifies the caller of r by the credential it was authenticated with: the
+// ID of its API key (see RequireAPIKey) or a fingerprint of its bearer token (see
+// RequireBearer), falling back to the client IP address. Credentials no middleware verified are
+// ignored, so clients cannot get fresh quotas by sending made-up ones, and credentials are
+// hashed so they never appear in reports.
+func CallerFromRequest(r *http.Request) string {
+	if key, ok := APIKeyFromContext(r.Context()); ok {
+		return "key:" + key.ID
+	}
+	if token, ok := r.Context().Value(bearerContextKey{}).(string); ok {
+		return "bearer:" + token
+	}
+	return ipCaller(r)
+}
+
+// ipCaller identifies the caller of r by its client IP address
+func ipCaller(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// fingerprint returns a short, non-reversible identifier for a credential
+func fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:6])
+}
+
+// usageMeter collects handler-reported consumption for one task execution
+type usageMeter struct {
+	tokens atomic.Int64
+}
+
+// usageMeterKey is the context key for the current usageMeter
+type usageMeterKey struct{}
+
+// ReportTokens attributes n LLM tokens to the task executing in ctx. It is a no-op when
+// usage accounting is disabled, so handlers can call it unconditionally.
+func ReportTokens(ctx context.Context, n int64) {
+	if meter, ok := ctx.Value(usageMeterKey{}).(*usageMeter); ok {
+		meter.tokens.Add(n)
+	}
+}
+
+// caller identifies the principal making r for accounting and quotas
+func (s *A2AServer) caller(r *http.Request) string {
+	if s.callerFunc != nil {
+		return s.callerFunc(r)
+	}
+	return CallerFromRequest(r)
+}
+
+// meterHandler runs handler and records its consumption in the usage store and quotas
+func (s *A2AServer) meterHandler(ctx context.Context, r *http.Request, skillID string, handler TaskHandler, task *models.Task, message *models.Message) (*models.Task, error) {
+	meter := &usageMeter{}
+	ctx = context.WithValue(ctx, usageMeterKey{}, meter)
+
+	start := s.clock.Now()
+	updatedTask, err := handler(ctx, task, message)
+
+	if skillID == "" {
+		skillID = defaultSkillLabel
+	}
+
+	rec := UsageRecord{
+		Caller:        s.caller(r),
+		Skill:         skillID,
+		Tokens:        meter.tokens.Load(),
+		ArtifactBytes: outputBytes(updatedTask),
+		WallTime:      s.clock.Now().Sub(start),
+		Failed:        err != nil || updatedTask == nil || updatedTask.Status.State == models.TaskStateFailed,
+	}
+	if s.quotas != nil {
+		s.quotas.addTokens(rec.Caller, rec.Tokens)
+	}
+	if s.usage != nil {
+		if recordErr := s.usage.Record(ctx, rec); recordErr != nil {
+			s.log(ctx, task.ID).Error("failed to record usage", slog.Any("error", recordErr))
+		}
+	}
+
+	return updatedTask, err
+}
+
+// outputBytes returns the encoded size of the output parts a task carries in its artifacts
+// and status message
+func outputBytes(task *models.Task) int64 {
+	if task == nil {
+		return 0
+	}
+	var parts []models.Part
+	for _, artifact := range task.Artifacts {
+		parts = append(parts, artifact.Parts...)
+	}
+	if task.Status.Message != nil {
+		parts = append(parts, task.Status.Message.Parts...)
+	}
+	if len(parts) == 0 {
+		return 0
+	}
+	data, err := json.Marshal(parts)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// UsageReport returns accumulated usage per caller and skill. It returns an empty report
+// when accounting is disabled.
+func (s *A2AServer) UsageReport(ctx context.Context) (UsageReport, error) {
+	report := UsageReport{ByCaller: map[string]Usage{}, BySkill: map[string]Usage{}}
+	if s.usage == nil {
+		return report, nil
+	}
+
+	entries, err := s.usage.Entries(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Entries = entries
+	for _, e := range entries {
+		byCaller := report.ByCaller[e.Caller]
+		byCaller.merge(e.Usage)
+		report.ByCaller[e.Caller] = byCaller
+
+		bySkill := report.BySkill[e.Skill]
+		bySkill.merge(e.Usage)
+		report.BySkill[e.Skill] = bySkill
+
+		report.Total.merge(e.Usage)
+	}
+	return report, nil
+}
+
+// UsageHandler serves the usage report as JSON, for mounting on an admin route behind the
+// admin's authentication, as the report identifies every caller
+func (s *A2AServer) UsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := s.UsageReport(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// UsageMetricsHandler serves usage counters in the Prometheus text exposition format
+func (s *A2AServer) UsageMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := s.UsageReport(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeUsageMetrics(w, report.Entries)
+	})
+}
+
+// writeUsageMetrics writes entries as Prometheus counters labeled by caller and skill
+func writeUsageMetrics(w io.Writer, entries []UsageEntry) {
+	metrics := []struct {
+		name  string
+		help  string
+		value func(Usage) float64
+	}{
+		{"a2a_usage_tasks_total", "Tasks executed.", func(u Usage) float64 { return float64(u.Tasks) }},
+		{"a2a_usage_failed_tasks_total", "Tasks that failed.", func(u Usage) float64 { return float64(u.FailedTasks) }},
+		{"a2a_usage_tokens_total", "LLM tokens reported by handlers.", func(u Usage) float64 { return float64(u.Tokens) }},
+		{"a2a_usage_artifact_bytes_total", "Bytes of task output produced.", func(u Usage) float64 { return float64(u.ArtifactBytes) }},
+		{"a2a_usage_wall_time_seconds_total", "Handler wall time.", func(u Usage) float64 { return float64(u.WallTimeMs) / 1000 }},
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s{caller=%q,skill=%q} %g\n", m.name, e.Caller, e.Skill, m.value(e.Usage))
+		}
+	}
+}
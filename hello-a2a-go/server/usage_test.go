@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a/models"
+)
+
+func TestA2AServer_UsageAccounting(t *testing.T) {
+	store := NewMemoryUsageStore()
+	server := NewA2AServer(mockAgentCard, mockTaskHandler, WithUsageStore(store))
+
+	reply := func(ctx context.Context, task *models.Task, message *models.Message) (*models.Task, error) {
+		ReportTokens(ctx, 40)
+		ReportTokens(ctx, 2)
+		task.Status.State = models.TaskStateCompleted
+		task.Status.Message = &models.Message{
+			Role:  "agent",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "done"}},
+		}
+		return task, nil
+	}
+	if err := server.AddSkill(models.AgentSkill{ID: "reply", Name: "Reply"}, reply); err != nil {
+		t.Fatalf("Failed to add skill: %v", err)
+	}
+
+	message := models.Message{
+		Role:  "user",
+		Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+	}
+	for _, params := range []models.MessageSendParams{
+		{ID: "usage-1", Message: message, Metadata: map[string]interface{}{SkillMetadataKey: "reply"}},
+		{ID: "usage-2", Message: message, Metadata: map[string]interface{}{SkillMetadataKey: "reply"}},
+		{ID: "usage-3", Message: message},
+	} {
+		if response := doRPC(t, server, "message/send", params); response.Error != nil {
+			t.Fatalf("Expected no error, got %v", response.Error)
+		}
+	}
+
+	report, err := server.UsageReport(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to build usage report: %v", err)
+	}
+
+	replyUsage := report.BySkill["reply"]
+	if replyUsage.Tasks != 2 || replyUsage.Tokens != 84 || replyUsage.ArtifactBytes == 0 {
+		t.Errorf("Unexpected reply skill usage: %+v", replyUsage)
+	}
+	if got := report.BySkill[defaultSkillLabel].Tasks; got != 1 {
+		t.Errorf("Expected 1 default task, got %d", got)
+	}
+	if report.Total.Tasks != 3 || len(report.ByCaller) != 1 {
+		t.Errorf("Unexpected totals: %+v by caller %v", report.Total, report.ByCaller)
+	}
+
+	w := httptest.NewRecorder()
+	server.UsageMetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `a2a_usage_tokens_total{caller="ip:192.0.2.1",skill="reply"} 84`) {
+		t.Errorf("Expected token counter in metrics, got:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	server.UsageHandler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/usage", nil))
+	var served UsageReport
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("Failed to decode usage report: %v", err)
+	}
+	if served.Total.Tasks != 3 {
+		t.Errorf("Expected 3 tasks in served report, got %d", served.Total.Tasks)
+	}
+}
+
+func TestA2AServer_UsageFailures(t *testing.T) {
+	store := NewMemoryUsageStore()
+	server := NewA2AServer(mockAgentCard, mockErrorTaskHandler, WithUsageStore(store))
+
+	params := models.MessageSendParams{
+		ID: "usage-fail",
+		Message: models.Message{
+			Role:  "user",
+			Parts: []models.Part{models.TextPart{Type: "text", Text: "Hello"}},
+		},
+	}
+	doRPC(t, server, "message/send", params)
+
+	entries, _ := store.Entries(context.Background())
+	if len(entries) != 1 || entries[0].FailedTasks != 1 {
+		t.Errorf("Expected one failed task, got %+v", entries)
+	}
+}
+
+func TestCallerFromRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	if got := CallerFromRequest(req); got != "ip:192.0.2.1" {
+		t.Errorf("Expected IP caller, got %q", got)
+	}
+
+	// Credentials no middleware verified are ignored
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-API-Key", "k1")
+	if got := CallerFromRequest(req); got != "ip:192.0.2.1" {
+		t.Errorf("Expected unverified credentials to be ignored, got %q", got)
+	}
+
+	// As verified by RequireBearer and RequireAPIKey
+	req = req.WithContext(context.WithValue(req.Context(), bearerContextKey{}, fingerprint("secret-token")))
+	if got := CallerFromRequest(req); !strings.HasPrefix(got, "bearer:") || strings.Contains(got, "secret-token") {
+		t.Errorf("Expected hashed bearer caller, got %q", got)
+	}
+	key := APIKey{ID: apiKeyID("k1")}
+	req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, key))
+	if got := CallerFromRequest(req); got != CallerForAPIKey("k1") || got != "key:"+key.ID {
+		t.Errorf("Expected API key caller %q, got %q", CallerForAPIKey("k1"), got)
+	}
+}
@@ -0,0 +1,88 @@
+//go:build otel
+
+package trace
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// otelPropagator converts between this package's trace context and OpenTelemetry's
+var otelPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewOTelTracer returns a Tracer recording spans with the global OpenTelemetry tracer provider
+// under the instrumentation name name. Build with -tags otel.
+func NewOTelTracer(name string) Tracer {
+	return &otelTracer{tracer: otel.Tracer(name)}
+}
+
+type otelTracer struct {
+	tracer oteltrace.Tracer
+}
+
+// Start implements Tracer
+func (t *otelTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	parent := http.Header{}
+	InjectContext(ctx, parent)
+	ctx = otelPropagator.Extract(ctx, propagation.HeaderCarrier(parent))
+
+	ctx, span := t.tracer.Start(ctx, name, oteltrace.WithSpanKind(otelSpanKind(kind)))
+	child := http.Header{}
+	otelPropagator.Inject(ctx, propagation.HeaderCarrier(child))
+	return NewContext(ctx, FromHeader(child)), &otelSpan{span: span}
+}
+
+func otelSpanKind(kind SpanKind) oteltrace.SpanKind {
+	switch kind {
+	case SpanKindServer:
+		return oteltrace.SpanKindServer
+	case SpanKindClient:
+		return oteltrace.SpanKindClient
+	}
+	return oteltrace.SpanKindInternal
+}
+
+type otelSpan struct {
+	span oteltrace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...slog.Attr) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		switch value.Kind() {
+		case slog.KindString:
+			kvs = append(kvs, attribute.String(attr.Key, value.String()))
+		case slog.KindInt64:
+			kvs = append(kvs, attribute.Int64(attr.Key, value.Int64()))
+		case slog.KindBool:
+			kvs = append(kvs, attribute.Bool(attr.Key, value.Bool()))
+		case slog.KindFloat64:
+			kvs = append(kvs, attribute.Float64(attr.Key, value.Float64()))
+		case slog.KindDuration:
+			kvs = append(kvs, attribute.Int64(attr.Key+"_ms", value.Duration().Milliseconds()))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, value.String()))
+		}
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
@@ -0,0 +1,57 @@
+//go:build otel
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestNewOTelTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	defer provider.Shutdown(context.Background())
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+	SetTracer(NewOTelTracer("a2a"))
+	defer SetTracer(nil)
+
+	parent := Context{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx, server := Start(NewContext(context.Background(), parent), "server", SpanKindServer, slog.String("rpc.method", "message/send"))
+	childCtx, child := Start(ctx, "handler", SpanKindInternal, slog.Int("attempt", 2))
+	child.RecordError(errors.New("model unavailable"))
+	child.End()
+	server.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	handler, rpc := spans[0], spans[1]
+	if rpc.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		rpc.Parent().SpanID().String() != "00f067aa0ba902b7" || rpc.SpanKind() != oteltrace.SpanKindServer {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", rpc)
+	}
+	if handler.Parent().SpanID() != rpc.SpanContext().SpanID() || handler.Status().Code != codes.Error {
+		t.Errorf("Expected the failed handler span to be the server span's child, got %+v", handler)
+	}
+	if attrs := rpc.Attributes(); len(attrs) != 1 || attrs[0].Value.AsString() != "message/send" {
+		t.Errorf("Expected the span's attributes, got %v", attrs)
+	}
+
+	// Outgoing requests name the innermost span as parent
+	c, _ := FromContext(childCtx)
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + handler.SpanContext().SpanID().String() + "-01"
+	if c.TraceParent != want {
+		t.Errorf("Expected traceparent %s, got %+v", want, c)
+	}
+}
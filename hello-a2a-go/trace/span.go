@@ -0,0 +1,213 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind is the role of a span in a trace, as in OpenTelemetry
+type SpanKind int
+
+// Span kinds
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	}
+	return "internal"
+}
+
+// Span is an operation being timed
+type Span interface {
+	// SetAttributes annotates the span
+	SetAttributes(attrs ...slog.Attr)
+	// RecordError marks the span failed with err; a nil err is ignored
+	RecordError(err error)
+	// End finishes the span
+	End()
+}
+
+// Tracer starts spans. Start returns a context carrying the trace context of the new span, so
+// outgoing requests made with it name the span as their parent.
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// tracer holds the Tracer set with SetTracer
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer sets the Tracer spans are started with; nil, the default, records no spans and
+// forwards trace contexts unchanged
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&t)
+}
+
+// Start starts a span named name with attrs using the Tracer set with SetTracer. The caller
+// ends the span.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...slog.Attr) (context.Context, Span) {
+	t := tracer.Load()
+	if t == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := (*t).Start(ctx, name, kind)
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...slog.Attr) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// SpanData is a finished span
+type SpanData struct {
+	Name     string
+	Kind     SpanKind
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Start    time.Time
+	End      time.Time
+	Attrs    []slog.Attr
+	Err      error
+}
+
+// Duration returns how long the span took
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// NewTracer returns a Tracer passing each finished span to export. Spans continue the trace
+// carried by the context, or start a new sampled one, and replace its parent ID with their own.
+func NewTracer(export func(SpanData)) Tracer {
+	return &exportTracer{export: export}
+}
+
+type exportTracer struct {
+	export func(SpanData)
+}
+
+// Start implements Tracer
+func (t *exportTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	parent, _ := FromContext(ctx)
+	data := SpanData{Name: name, Kind: kind, SpanID: randomHex(8), Start: time.Now()}
+
+	child := parent
+	flags := "01"
+	if parent.IsValid() {
+		data.TraceID, data.ParentID = parent.TraceID(), parent.TraceParent[36:52]
+		flags = parent.TraceParent[53:55]
+	} else {
+		data.TraceID = randomHex(16)
+		child.TraceState = ""
+	}
+	child.TraceParent = "00-" + data.TraceID + "-" + data.SpanID + "-" + flags
+	return NewContext(ctx, child), &exportSpan{data: data, export: t.export}
+}
+
+type exportSpan struct {
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+	export func(SpanData)
+}
+
+func (s *exportSpan) SetAttributes(attrs ...slog.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attrs = append(s.data.Attrs, attrs...)
+}
+
+func (s *exportSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+func (s *exportSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.export(data)
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Recorder keeps finished spans in memory, e.g. for tests: SetTracer(NewTracer(rec.Export))
+type Recorder struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// Export records span
+func (r *Recorder) Export(span SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// Spans returns the recorded spans in the order they ended
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SpanData(nil), r.spans...)
+}
+
+// LogSpans returns an export function logging each finished span with its trace, parent and
+// duration to logger, or slog.Default() if nil
+func LogSpans(logger *slog.Logger) func(SpanData) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(span SpanData) {
+		args := []any{
+			slog.String("name", span.Name),
+			slog.String("kind", span.Kind.String()),
+			slog.String("trace_id", span.TraceID),
+			slog.String("span_id", span.SpanID),
+			slog.String("parent_id", span.ParentID),
+			slog.Duration("duration", span.Duration()),
+		}
+		for _, attr := range span.Attrs {
+			args = append(args, attr)
+		}
+		if span.Err != nil {
+			args = append(args, slog.String("error", span.Err.Error()))
+		}
+		logger.Info("span", args...)
+	}
+}
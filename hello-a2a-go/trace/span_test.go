@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewTracer(t *testing.T) {
+	rec := &Recorder{}
+	SetTracer(NewTracer(rec.Export))
+	defer SetTracer(nil)
+
+	parent := Context{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		TraceState:  "vendor=1",
+		Baggage:     "tenant=acme",
+	}
+	ctx, server := Start(NewContext(context.Background(), parent), "server", SpanKindServer, slog.String("rpc.method", "message/send"))
+	childCtx, child := Start(ctx, "handler", SpanKindInternal)
+	child.RecordError(errors.New("model unavailable"))
+	child.End()
+	server.End()
+	server.End()
+
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	handler, rpc := spans[0], spans[1]
+	if rpc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || rpc.ParentID != "00f067aa0ba902b7" || rpc.Kind != SpanKindServer {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", rpc)
+	}
+	if handler.TraceID != rpc.TraceID || handler.ParentID != rpc.SpanID || handler.Err == nil {
+		t.Errorf("Expected the failed handler span to be the server span's child, got %+v", handler)
+	}
+	if len(rpc.Attrs) != 1 || rpc.Attrs[0].Value.String() != "message/send" {
+		t.Errorf("Expected the span's attributes, got %v", rpc.Attrs)
+	}
+
+	// Outgoing requests name the innermost span as parent, keeping the flags, state and baggage
+	c, _ := FromContext(childCtx)
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + handler.SpanID + "-00"
+	if c.TraceParent != want || c.TraceState != "vendor=1" || c.Baggage != "tenant=acme" {
+		t.Errorf("Expected traceparent %s with state and baggage, got %+v", want, c)
+	}
+
+	// Without a trace, a new sampled one starts
+	ctx, root := Start(context.Background(), "client", SpanKindClient)
+	root.End()
+	if c, _ := FromContext(ctx); !c.IsValid() || !strings.HasSuffix(c.TraceParent, "-01") || rec.Spans()[2].ParentID != "" {
+		t.Errorf("Expected a new sampled trace, got %+v", c)
+	}
+}
+
+func TestStart_WithoutTracer(t *testing.T) {
+	parent := NewContext(context.Background(), Context{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	ctx, span := Start(parent, "noop", SpanKindInternal)
+	span.End()
+	if ctx != parent {
+		t.Error("Expected the trace context to be forwarded unchanged without a tracer")
+	}
+}
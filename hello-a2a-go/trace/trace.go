@@ -0,0 +1,154 @@
+// Package trace propagates W3C Trace Context (traceparent, tracestate) and W3C Baggage headers
+// from incoming requests through handler contexts into outgoing HTTP calls, so a single trace
+// covers client, agent and model backend. By default it forwards the context unchanged and
+// records no spans; SetTracer records spans with NewTracer, or with OpenTelemetry through
+// NewOTelTracer when built with -tags otel.
+package trace
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+// Propagated headers
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+	HeaderBaggage     = "baggage"
+)
+
+// traceParentPattern matches a version 00 traceparent, or a future version with extra fields,
+// with a non-zero trace ID and parent ID
+var traceParentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})(-.*)?$`)
+
+// Context is the trace context of a request
+type Context struct {
+	TraceParent string
+	TraceState  string
+	Baggage     string
+}
+
+// IsValid reports whether the traceparent is well formed. Invalid trace contexts are dropped
+// along with their tracestate, as the specification requires; baggage is independent.
+func (c Context) IsValid() bool {
+	m := traceParentPattern.FindStringSubmatch(c.TraceParent)
+	if m == nil || m[1] == "ff" || (m[1] == "00" && m[5] != "") {
+		return false
+	}
+	return m[2] != "00000000000000000000000000000000" && m[3] != "0000000000000000"
+}
+
+// TraceID returns the trace ID of a valid traceparent, or ""
+func (c Context) TraceID() string {
+	if !c.IsValid() {
+		return ""
+	}
+	return c.TraceParent[3:35]
+}
+
+// FromHeader extracts the trace context from h
+func FromHeader(h http.Header) Context {
+	c := Context{
+		TraceParent: h.Get(HeaderTraceParent),
+		TraceState:  h.Get(HeaderTraceState),
+		Baggage:     h.Get(HeaderBaggage),
+	}
+	if !c.IsValid() {
+		c.TraceParent, c.TraceState = "", ""
+	}
+	return c
+}
+
+// Inject sets the trace context headers on h, leaving headers for empty fields untouched
+func (c Context) Inject(h http.Header) {
+	if c.IsValid() {
+		h.Set(HeaderTraceParent, c.TraceParent)
+		if c.TraceState != "" {
+			h.Set(HeaderTraceState, c.TraceState)
+		}
+	}
+	if c.Baggage != "" {
+		h.Set(HeaderBaggage, c.Baggage)
+	}
+}
+
+// contextKey is the context key of the trace context
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying c
+func NewContext(ctx context.Context, c Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the trace context carried by ctx
+func FromContext(ctx context.Context) (Context, bool) {
+	c, ok := ctx.Value(contextKey{}).(Context)
+	return c, ok
+}
+
+// InjectContext sets the headers of the trace context carried by ctx on h
+func InjectContext(ctx context.Context, h http.Header) {
+	if c, ok := FromContext(ctx); ok {
+		c.Inject(h)
+	}
+}
+
+// Transport is an http.RoundTripper that adds the trace context carried by each request's
+// context to its headers. A nil Base uses http.DefaultTransport.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c, ok := FromContext(req.Context())
+	if !ok {
+		return base.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	c.Inject(req.Header)
+	return base.RoundTrip(req)
+}
+
+// FromMetadata extracts the trace context from JSON-RPC metadata, which carries it under the
+// header names for transports without headers, such as stdio
+func FromMetadata(metadata map[string]interface{}) Context {
+	get := func(key string) string {
+		s, _ := metadata[key].(string)
+		return s
+	}
+	c := Context{TraceParent: get(HeaderTraceParent), TraceState: get(HeaderTraceState), Baggage: get(HeaderBaggage)}
+	if !c.IsValid() {
+		c.TraceParent, c.TraceState = "", ""
+	}
+	return c
+}
+
+// InjectMetadata returns a copy of metadata carrying the trace context of ctx, or metadata
+// itself if ctx carries none
+func InjectMetadata(ctx context.Context, metadata map[string]interface{}) map[string]interface{} {
+	c, ok := FromContext(ctx)
+	if !ok || (!c.IsValid() && c.Baggage == "") {
+		return metadata
+	}
+	injected := make(map[string]interface{}, len(metadata)+3)
+	for k, v := range metadata {
+		injected[k] = v
+	}
+	if c.IsValid() {
+		injected[HeaderTraceParent] = c.TraceParent
+		if c.TraceState != "" {
+			injected[HeaderTraceState] = c.TraceState
+		}
+	}
+	if c.Baggage != "" {
+		injected[HeaderBaggage] = c.Baggage
+	}
+	return injected
+}
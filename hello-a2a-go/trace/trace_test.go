@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const validParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestFromHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		want        string
+	}{
+		{"valid", validParent, validParent},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", ""},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"zero parent ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", ""},
+		{"forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz"},
+		{"version 00 with extra fields", validParent + "-xyz", ""},
+		{"missing", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set(HeaderTraceParent, tt.traceParent)
+			h.Set(HeaderTraceState, "vendor=1")
+			h.Set(HeaderBaggage, "tenant=acme")
+
+			c := FromHeader(h)
+			if c.TraceParent != tt.want {
+				t.Errorf("Expected traceparent %q, got %q", tt.want, c.TraceParent)
+			}
+			if tt.want == "" && c.TraceState != "" {
+				t.Error("Expected tracestate to be dropped with an invalid traceparent")
+			}
+			if c.Baggage != "tenant=acme" {
+				t.Errorf("Expected baggage to be kept, got %q", c.Baggage)
+			}
+		})
+	}
+
+	if got := (Context{TraceParent: validParent}).TraceID(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Unexpected trace ID %q", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer backend.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	ctx := NewContext(context.Background(), Context{TraceParent: validParent, TraceState: "vendor=1", Baggage: "tenant=acme"})
+	req, _ := http.NewRequestWithContext(ctx, "GET", backend.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got.Get(HeaderTraceParent) != validParent || got.Get(HeaderTraceState) != "vendor=1" || got.Get(HeaderBaggage) != "tenant=acme" {
+		t.Errorf("Expected trace headers to be propagated, got %v", got)
+	}
+	if req.Header.Get(HeaderTraceParent) != "" {
+		t.Error("Expected the caller's request to be left unmodified")
+	}
+
+	req, _ = http.NewRequest("GET", backend.URL, nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got.Get(HeaderTraceParent) != "" {
+		t.Errorf("Expected no trace headers without a trace context, got %v", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	ctx := NewContext(context.Background(), Context{
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Baggage:     "tenant=acme",
+	})
+	metadata := map[string]interface{}{"skill": "translate"}
+	injected := InjectMetadata(ctx, metadata)
+	if len(metadata) != 1 || injected["skill"] != "translate" {
+		t.Errorf("Expected a copy keeping the metadata, got %v", injected)
+	}
+	if c := FromMetadata(injected); c.TraceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" || c.Baggage != "tenant=acme" {
+		t.Errorf("Expected the trace context back, got %+v", c)
+	}
+	if got := InjectMetadata(context.Background(), metadata); len(got) != 1 {
+		t.Errorf("Expected metadata unchanged without a trace context, got %v", got)
+	}
+	if c := FromMetadata(map[string]interface{}{HeaderTraceParent: "bogus", HeaderTraceState: "a=1"}); c.TraceParent != "" || c.TraceState != "" {
+		t.Errorf("Expected an invalid traceparent to be dropped, got %+v", c)
+	}
+}